@@ -0,0 +1,292 @@
+//go:build integration
+
+// Package integration 通过dockertest启动Kafka、Redis、InfluxDB及anvil本地链，
+// 按main.go的方式组装完整流水线并检查各输出端的数据。
+//
+// 运行：go test -tags integration -v ./integration/...（需要本机可用的Docker）
+package integration
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+	"github.com/segmentio/kafka-go"
+)
+
+// InfluxDB容器初始化参数
+const (
+	influxOrg    = "web3org"
+	influxBucket = "web3bucket"
+	influxToken  = "integration-token"
+)
+
+// anvilChainID anvil本地链的链ID
+const anvilChainID = 31337
+
+// containerExpiry 容器的最长存活时间（秒），测试进程异常退出时由Docker清理
+const containerExpiry = 900
+
+// environment 容器中各服务的连接地址
+type environment struct {
+	redisHost   string
+	redisPort   int
+	influxURL   string
+	kafkaBroker string
+	rpcURL      string
+	wsURL       string
+}
+
+var env environment
+
+func TestMain(m *testing.M) {
+	os.Exit(run(m))
+}
+
+// run 启动容器并运行测试，结束后清理容器
+func run(m *testing.M) int {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		log.Printf("Failed to connect to docker: %v", err)
+		return 1
+	}
+	pool.MaxWait = 2 * time.Minute
+	if err := pool.Client.Ping(); err != nil {
+		log.Printf("Docker is not available: %v", err)
+		return 1
+	}
+
+	var resources []*dockertest.Resource
+	defer func() {
+		for _, resource := range resources {
+			if err := pool.Purge(resource); err != nil {
+				log.Printf("Failed to remove container %s: %v", resource.Container.Name, err)
+			}
+		}
+	}()
+
+	start := func(options *dockertest.RunOptions) (*dockertest.Resource, error) {
+		resource, err := pool.RunWithOptions(options, func(hostConfig *docker.HostConfig) {
+			hostConfig.AutoRemove = true
+			hostConfig.RestartPolicy = docker.RestartPolicy{Name: "no"}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to start %s: %w", options.Repository, err)
+		}
+		resource.Expire(containerExpiry)
+		resources = append(resources, resource)
+		return resource, nil
+	}
+
+	for _, setup := range []func(*dockertest.Pool, func(*dockertest.RunOptions) (*dockertest.Resource, error)) error{
+		startRedis,
+		startInfluxDB,
+		startKafka,
+		startAnvil,
+	} {
+		if err := setup(pool, start); err != nil {
+			log.Print(err)
+			return 1
+		}
+	}
+
+	return m.Run()
+}
+
+func startRedis(pool *dockertest.Pool, start func(*dockertest.RunOptions) (*dockertest.Resource, error)) error {
+	resource, err := start(&dockertest.RunOptions{Repository: "redis", Tag: "7-alpine"})
+	if err != nil {
+		return err
+	}
+
+	host, port, err := splitHostPort(resource.GetHostPort("6379/tcp"))
+	if err != nil {
+		return err
+	}
+	env.redisHost, env.redisPort = host, port
+
+	return pool.Retry(func() error {
+		client, err := database.NewRedisClient(config.RedisConfig{Host: host, Port: port})
+		if err != nil {
+			return err
+		}
+		return client.Close()
+	})
+}
+
+func startInfluxDB(pool *dockertest.Pool, start func(*dockertest.RunOptions) (*dockertest.Resource, error)) error {
+	resource, err := start(&dockertest.RunOptions{
+		Repository: "influxdb",
+		Tag:        "2.7-alpine",
+		Env: []string{
+			"DOCKER_INFLUXDB_INIT_MODE=setup",
+			"DOCKER_INFLUXDB_INIT_USERNAME=admin",
+			"DOCKER_INFLUXDB_INIT_PASSWORD=adminpassword",
+			"DOCKER_INFLUXDB_INIT_ORG=" + influxOrg,
+			"DOCKER_INFLUXDB_INIT_BUCKET=" + influxBucket,
+			"DOCKER_INFLUXDB_INIT_ADMIN_TOKEN=" + influxToken,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	env.influxURL = "http://" + resource.GetHostPort("8086/tcp")
+
+	// 健康检查在初始化完成前即可通过，以查询bucket确认组织与令牌已创建
+	return pool.Retry(func() error {
+		client, err := database.NewInfluxDBClient(influxConfig())
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+		_, err = client.Query(fmt.Sprintf(`buckets() |> filter(fn: (r) => r.name == "%s")`, influxBucket))
+		return err
+	})
+}
+
+// startKafka 启动单节点KRaft模式的Kafka，对外地址须为宿主机映射的端口，因此预先选定端口
+func startKafka(pool *dockertest.Pool, start func(*dockertest.RunOptions) (*dockertest.Resource, error)) error {
+	port, err := freePort()
+	if err != nil {
+		return err
+	}
+	env.kafkaBroker = fmt.Sprintf("localhost:%d", port)
+
+	_, err = start(&dockertest.RunOptions{
+		Repository: "apache/kafka",
+		Tag:        "3.7.0",
+		Env: []string{
+			"KAFKA_NODE_ID=1",
+			"KAFKA_PROCESS_ROLES=broker,controller",
+			"KAFKA_LISTENERS=PLAINTEXT://:9092,CONTROLLER://:9093",
+			"KAFKA_ADVERTISED_LISTENERS=PLAINTEXT://" + env.kafkaBroker,
+			"KAFKA_CONTROLLER_LISTENER_NAMES=CONTROLLER",
+			"KAFKA_LISTENER_SECURITY_PROTOCOL_MAP=CONTROLLER:PLAINTEXT,PLAINTEXT:PLAINTEXT",
+			"KAFKA_CONTROLLER_QUORUM_VOTERS=1@localhost:9093",
+			"KAFKA_OFFSETS_TOPIC_REPLICATION_FACTOR=1",
+			"KAFKA_TRANSACTION_STATE_LOG_REPLICATION_FACTOR=1",
+			"KAFKA_TRANSACTION_STATE_LOG_MIN_ISR=1",
+			"KAFKA_NUM_PARTITIONS=1",
+		},
+		PortBindings: map[docker.Port][]docker.PortBinding{
+			"9092/tcp": {{HostIP: "127.0.0.1", HostPort: strconv.Itoa(port)}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return pool.Retry(func() error {
+		conn, err := kafka.Dial("tcp", env.kafkaBroker)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		_, err = conn.Brokers()
+		return err
+	})
+}
+
+// startAnvil 启动anvil本地链作为模拟链，每秒出块，默认账户已注资
+func startAnvil(pool *dockertest.Pool, start func(*dockertest.RunOptions) (*dockertest.Resource, error)) error {
+	resource, err := start(&dockertest.RunOptions{
+		Repository:   "ghcr.io/foundry-rs/foundry",
+		Tag:          "latest",
+		Entrypoint:   []string{"anvil"},
+		Cmd:          []string{"--host", "0.0.0.0", "--chain-id", strconv.Itoa(anvilChainID), "--block-time", "1"},
+		ExposedPorts: []string{"8545/tcp"},
+	})
+	if err != nil {
+		return err
+	}
+	hostPort := resource.GetHostPort("8545/tcp")
+	env.rpcURL = "http://" + hostPort
+	env.wsURL = "ws://" + hostPort
+
+	return pool.Retry(func() error {
+		client, err := ethclient.Dial(env.rpcURL)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err = client.ChainID(ctx)
+		return err
+	})
+}
+
+// createTopics 通过控制器创建主题，发布器不自动创建主题
+func createTopics(topics ...string) error {
+	conn, err := kafka.Dial("tcp", env.kafkaBroker)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	controller, err := conn.Controller()
+	if err != nil {
+		return err
+	}
+	controllerConn, err := kafka.Dial("tcp", net.JoinHostPort(controller.Host, strconv.Itoa(controller.Port)))
+	if err != nil {
+		return err
+	}
+	defer controllerConn.Close()
+
+	configs := make([]kafka.TopicConfig, 0, len(topics))
+	for _, topic := range topics {
+		configs = append(configs, kafka.TopicConfig{Topic: topic, NumPartitions: 1, ReplicationFactor: 1})
+	}
+	return controllerConn.CreateTopics(configs...)
+}
+
+func influxConfig() config.InfluxDBConfig {
+	return config.InfluxDBConfig{
+		URL:    env.influxURL,
+		Token:  influxToken,
+		Org:    influxOrg,
+		Bucket: influxBucket,
+		Write: config.InfluxWriteConfig{
+			BatchSize:        100,
+			FlushInterval:    "100ms",
+			MaxRetries:       1,
+			RetryInterval:    "100ms",
+			MaxRetryInterval: "1s",
+		},
+	}
+}
+
+func splitHostPort(hostPort string) (string, int, error) {
+	host, portText, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return "", 0, err
+	}
+	port, err := strconv.Atoi(portText)
+	if err != nil {
+		return "", 0, err
+	}
+	return host, port, nil
+}
+
+// freePort 选取宿主机上的空闲端口
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
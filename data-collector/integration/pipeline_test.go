@@ -0,0 +1,417 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"web3-data-collector/internal/collector"
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/metrics"
+	"web3-data-collector/internal/processor"
+	"web3-data-collector/internal/publisher"
+	"web3-data-collector/internal/stream"
+	"web3-data-collector/internal/warehouse"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/segmentio/kafka-go"
+)
+
+// anvil默认助记词的前两个账户，仅用于本地链
+const (
+	senderKey = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+	recipient = "0x70997970C51812dc3A010C7d01b50e0d17dc79C8"
+)
+
+const network = "anvil"
+
+// 测试使用的主题
+const (
+	transactionsTopic = "it-transactions"
+	blocksTopic       = "it-blocks"
+	alertsTopic       = "it-alerts"
+)
+
+// waitTimeout 等待数据到达各输出端的最长时间
+const waitTimeout = time.Minute
+
+// pipelineConfig 测试配置，高价值阈值为5 ETH
+const pipelineConfig = `
+blockchain:
+  run_mode: "realtime"
+  networks:
+    anvil:
+      rpc_url: "%s"
+      ws_url: "%s"
+      chain_id: %d
+      enabled: true
+      block_time: "1s"
+      native_currency:
+        symbol: "ETH"
+        decimals: 18
+        high_value_threshold: "5"
+kafka:
+  brokers: ["%s"]
+  topics:
+    transactions: "%s"
+    blocks: "%s"
+    alerts: "%s"
+  producer:
+    batch_size: 1
+    batch_timeout: "10ms"
+influxdb:
+  url: "%s"
+  token: "%s"
+  org: "%s"
+  bucket: "%s"
+redis:
+  host: "%s"
+  port: %d
+`
+
+// TestPipeline 在anvil上发送普通转账及大额转账，检查Kafka、Redis及InfluxDB中的输出
+func TestPipeline(t *testing.T) {
+	cfg := loadConfig(t)
+
+	if err := createTopics(transactionsTopic, blocksTopic, alertsTopic); err != nil {
+		t.Fatalf("Failed to create topics: %v", err)
+	}
+
+	redisClient, influxClient := startPipeline(t, cfg)
+
+	from, hashes := sendTransfers(t, []*big.Int{ether(1), ether(10)})
+	normalTx, highValueTx := hashes[0], hashes[1]
+
+	t.Run("kafka transactions", func(t *testing.T) {
+		message := findMessage(t, transactionsTopic, func(message kafka.Message) bool {
+			return string(message.Key) == normalTx
+		})
+		if header(message, "message_type") != "transaction" {
+			t.Errorf("message_type header = %q, want transaction", header(message, "message_type"))
+		}
+		if header(message, "network") != network {
+			t.Errorf("network header = %q, want %s", header(message, "network"), network)
+		}
+	})
+
+	t.Run("kafka alerts", func(t *testing.T) {
+		message := findMessage(t, alertsTopic, func(message kafka.Message) bool {
+			var alert struct {
+				TransactionHash string `json:"transaction_hash"`
+			}
+			return json.Unmarshal(message.Value, &alert) == nil && strings.EqualFold(alert.TransactionHash, highValueTx)
+		})
+		if header(message, "alert_type") != "HIGH_VALUE" {
+			t.Errorf("alert_type header = %q, want HIGH_VALUE", header(message, "alert_type"))
+		}
+	})
+
+	t.Run("kafka no alert for normal transfer", func(t *testing.T) {
+		reader := newReader(alertsTopic)
+		defer reader.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		for {
+			message, err := reader.ReadMessage(ctx)
+			if err != nil {
+				return
+			}
+			if strings.Contains(strings.ToLower(string(message.Value)), strings.ToLower(normalTx)) {
+				t.Fatalf("Unexpected alert for %s: %s", normalTx, message.Value)
+			}
+		}
+	})
+
+	t.Run("redis address stats", func(t *testing.T) {
+		key := warehouse.AddressStatsKey(network, from)
+		eventually(t, func() error {
+			stats, err := redisClient.HGetAll(key)
+			if err != nil {
+				return err
+			}
+			count, _ := strconv.Atoi(stats["tx_count"])
+			if count < len(hashes) {
+				return fmt.Errorf("%s tx_count = %q, want at least %d", key, stats["tx_count"], len(hashes))
+			}
+			return nil
+		})
+
+		eventually(t, func() error {
+			exists, err := redisClient.Exists("latest_block:" + network)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return fmt.Errorf("latest_block:%s not found", network)
+			}
+			return nil
+		})
+	})
+
+	t.Run("influxdb transactions", func(t *testing.T) {
+		start := time.Now().Add(-time.Hour)
+		for _, hash := range hashes {
+			eventually(t, func() error {
+				record, err := influxClient.GetTransactionByHash(network, hash, start, time.Now().Add(time.Minute))
+				if err != nil {
+					return err
+				}
+				if record == nil {
+					return fmt.Errorf("transaction %s not found", hash)
+				}
+				return nil
+			})
+		}
+	})
+}
+
+// TestRedisMigrations 空库启动时记录各布局的当前版本，之后不再有待升级的布局
+func TestRedisMigrations(t *testing.T) {
+	redisClient, err := database.NewRedisClient(config.RedisConfig{Host: env.redisHost, Port: env.redisPort, DB: 1})
+	if err != nil {
+		t.Fatalf("Failed to connect to Redis: %v", err)
+	}
+	defer redisClient.Close()
+
+	migrator, err := database.NewSchemaMigrator(redisClient, processor.RedisLayouts()...)
+	if err != nil {
+		t.Fatalf("Failed to create migrator: %v", err)
+	}
+	if err := migrator.Migrate(context.Background()); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+
+	pending, err := migrator.Pending()
+	if err != nil {
+		t.Fatalf("Pending failed: %v", err)
+	}
+	if len(pending) > 0 {
+		t.Errorf("%d layouts still pending after migration", len(pending))
+	}
+}
+
+// loadConfig 写出测试配置并按正常启动流程加载及校验
+func loadConfig(t *testing.T) *config.Config {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	content := fmt.Sprintf(pipelineConfig,
+		env.rpcURL, env.wsURL, anvilChainID,
+		env.kafkaBroker, transactionsTopic, blocksTopic, alertsTopic,
+		env.influxURL, influxToken, influxOrg, influxBucket,
+		env.redisHost, env.redisPort,
+	)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if errs := cfg.Validate(); len(errs) > 0 {
+		t.Fatalf("Invalid config: %v", errs)
+	}
+	return cfg
+}
+
+// startPipeline 按main.go的顺序组装并启动流水线，测试结束时按优雅关闭的顺序停止
+func startPipeline(t *testing.T, cfg *config.Config) (*database.RedisClient, *database.InfluxDBClient) {
+	t.Helper()
+
+	metricsManager := metrics.NewManager()
+
+	influxClient, err := database.NewInfluxDBClient(cfg.InfluxDB)
+	if err != nil {
+		t.Fatalf("Failed to connect to InfluxDB: %v", err)
+	}
+
+	redisClient, err := database.NewRedisClient(cfg.Redis)
+	if err != nil {
+		t.Fatalf("Failed to connect to Redis: %v", err)
+	}
+
+	kafkaPublisher, err := publisher.NewKafkaPublisher(cfg.Kafka)
+	if err != nil {
+		t.Fatalf("Failed to create Kafka publisher: %v", err)
+	}
+
+	streamHub := stream.NewHub(cfg.GRPC.StreamBufferSize)
+
+	dataProcessor, err := processor.NewDataProcessor(
+		cfg.DataProcessing,
+		cfg.Blockchain.Networks,
+		kafkaPublisher,
+		influxClient,
+		redisClient,
+		metricsManager,
+		streamHub,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create data processor: %v", err)
+	}
+
+	blockchainCollector := collector.NewBlockchainCollector(cfg.Blockchain, dataProcessor, metricsManager, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		if err := blockchainCollector.Start(ctx); err != nil {
+			t.Errorf("Blockchain collector error: %v", err)
+		}
+	}()
+
+	t.Cleanup(func() {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer shutdownCancel()
+
+		if err := blockchainCollector.Stop(shutdownCtx); err != nil {
+			t.Errorf("Blockchain collector did not drain: %v", err)
+		}
+		cancel()
+		if err := kafkaPublisher.Close(); err != nil {
+			t.Errorf("Failed to flush Kafka writers: %v", err)
+		}
+		influxClient.Close()
+		redisClient.Close()
+	})
+
+	return redisClient, influxClient
+}
+
+// sendTransfers 从anvil默认账户依次发送转账并等待上链，返回发送方地址及交易哈希
+func sendTransfers(t *testing.T, values []*big.Int) (string, []string) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), waitTimeout)
+	defer cancel()
+
+	client, err := ethclient.DialContext(ctx, env.rpcURL)
+	if err != nil {
+		t.Fatalf("Failed to connect to anvil: %v", err)
+	}
+	defer client.Close()
+
+	privateKey, err := crypto.HexToECDSA(senderKey)
+	if err != nil {
+		t.Fatalf("Invalid sender key: %v", err)
+	}
+	from := crypto.PubkeyToAddress(privateKey.PublicKey)
+	to := common.HexToAddress(recipient)
+	signer := types.LatestSignerForChainID(big.NewInt(anvilChainID))
+
+	nonce, err := client.PendingNonceAt(ctx, from)
+	if err != nil {
+		t.Fatalf("Failed to get nonce: %v", err)
+	}
+	gasPrice, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get gas price: %v", err)
+	}
+
+	hashes := make([]string, 0, len(values))
+	for i, value := range values {
+		tx, err := types.SignTx(types.NewTx(&types.LegacyTx{
+			Nonce:    nonce + uint64(i),
+			To:       &to,
+			Value:    value,
+			Gas:      21000,
+			GasPrice: gasPrice,
+		}), signer, privateKey)
+		if err != nil {
+			t.Fatalf("Failed to sign transaction: %v", err)
+		}
+		if err := client.SendTransaction(ctx, tx); err != nil {
+			t.Fatalf("Failed to send transaction: %v", err)
+		}
+		hashes = append(hashes, tx.Hash().Hex())
+	}
+
+	for _, hash := range hashes {
+		eventually(t, func() error {
+			_, err := client.TransactionReceipt(ctx, common.HexToHash(hash))
+			return err
+		})
+	}
+
+	return strings.ToLower(from.Hex()), hashes
+}
+
+// findMessage 从头读取主题，返回第一条满足match的消息
+func findMessage(t *testing.T, topic string, match func(kafka.Message) bool) kafka.Message {
+	t.Helper()
+
+	reader := newReader(topic)
+	defer reader.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), waitTimeout)
+	defer cancel()
+	for {
+		message, err := reader.ReadMessage(ctx)
+		if err != nil {
+			t.Fatalf("No matching message in %s: %v", topic, err)
+		}
+		if match(message) {
+			return message
+		}
+	}
+}
+
+func newReader(topic string) *kafka.Reader {
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     []string{env.kafkaBroker},
+		Topic:       topic,
+		StartOffset: kafka.FirstOffset,
+		MaxWait:     500 * time.Millisecond,
+	})
+}
+
+func header(message kafka.Message, key string) string {
+	for _, h := range message.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// eventually 重试check直到成功或超时
+func eventually(t *testing.T, check func() error) {
+	t.Helper()
+
+	deadline := time.Now().Add(waitTimeout)
+	for {
+		err := check()
+		if err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal(err)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// ether 以wei表示的n个ETH
+func ether(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18))
+}
@@ -0,0 +1,286 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"web3-data-collector/internal/models"
+	"web3-data-collector/internal/processor"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AlertActionRequest 确认/解决告警请求
+type AlertActionRequest struct {
+	Note     string `json:"note"`
+	Operator string `json:"operator"` // 未启用认证时记录的处理人
+}
+
+// AlertSnoozeRequest 按地址静默告警请求，duration与until二选一
+type AlertSnoozeRequest struct {
+	Network  string   `json:"network" binding:"required"`
+	Address  string   `json:"address" binding:"required"`
+	Duration string   `json:"duration"`
+	Until    string   `json:"until"`
+	Types    []string `json:"types"`
+	Reason   string   `json:"reason"`
+	Operator string   `json:"operator"`
+}
+
+// listAlerts 按网络、类型、级别、状态、地址及时间范围查询告警，按时间从新到旧
+func listAlerts(alerts *processor.AlertStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !alertStoreEnabled(c, alerts) {
+			return
+		}
+
+		start, end, err := parseTimeRange(parseQueryParams(c))
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		limit := 100
+		if value := c.Query("limit"); value != "" {
+			limit, err = strconv.Atoi(value)
+			if err != nil || limit <= 0 {
+				respondBadRequest(c, "Invalid limit")
+				return
+			}
+		}
+
+		records, err := alerts.List(models.AlertQuery{
+			Network: c.Query("network"),
+			Type:    c.Query("type"),
+			Level:   strings.ToUpper(c.Query("level")),
+			Status:  strings.ToUpper(c.Query("status")),
+			Address: c.Query("address"),
+			Since:   start,
+			Until:   end,
+			Limit:   limit,
+		})
+		if err != nil {
+			logger.Errorf("Failed to list alerts: %v", err)
+			respondInternalError(c)
+			return
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      records,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// getAlert 获取告警及处理记录
+func getAlert(alerts *processor.AlertStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !alertStoreEnabled(c, alerts) {
+			return
+		}
+
+		record, exists, err := alerts.Get(c.Param("id"))
+		if err != nil {
+			logger.Errorf("Failed to get alert %s: %v", c.Param("id"), err)
+			respondInternalError(c)
+			return
+		}
+		if !exists {
+			respondNotFound(c, "Alert not found")
+			return
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      record,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// acknowledgeAlert 确认告警
+func acknowledgeAlert(alerts *processor.AlertStore) gin.HandlerFunc {
+	return updateAlert(alerts, "acknowledged", alerts.Acknowledge)
+}
+
+// resolveAlert 解决告警
+func resolveAlert(alerts *processor.AlertStore) gin.HandlerFunc {
+	return updateAlert(alerts, "resolved", alerts.Resolve)
+}
+
+// updateAlert 记录告警处理操作，处理人为调用方
+func updateAlert(alerts *processor.AlertStore, action string, apply func(id, actor, note string) (*models.AlertRecord, bool, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !alertStoreEnabled(c, alerts) {
+			return
+		}
+
+		var req AlertActionRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				respondBadRequest(c, err.Error())
+				return
+			}
+		}
+
+		id := c.Param("id")
+		actor := requestActor(c, req.Operator)
+		record, exists, err := apply(id, actor, req.Note)
+		if errors.Is(err, processor.ErrAlertResolved) {
+			c.JSON(http.StatusConflict, APIResponse{
+				Success:   false,
+				Message:   err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+		if err != nil {
+			logger.Errorf("Failed to update alert %s: %v", id, err)
+			respondInternalError(c)
+			return
+		}
+		if !exists {
+			respondNotFound(c, "Alert not found")
+			return
+		}
+
+		logger.Infof("Alert %s %s by %s", id, action, actor)
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      record,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// listAlertSnoozes 获取未过期的地址静默
+func listAlertSnoozes(alerts *processor.AlertStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !alertStoreEnabled(c, alerts) {
+			return
+		}
+
+		snoozes, err := alerts.Snoozes()
+		if err != nil {
+			logger.Errorf("Failed to list alert snoozes: %v", err)
+			respondInternalError(c)
+			return
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      snoozes,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// createAlertSnooze 静默地址的告警，同一地址已有的静默被替换
+func createAlertSnooze(alerts *processor.AlertStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !alertStoreEnabled(c, alerts) {
+			return
+		}
+
+		var req AlertSnoozeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+
+		var until time.Time
+		switch {
+		case req.Duration != "" && req.Until != "":
+			respondBadRequest(c, "Only one of duration and until may be set")
+			return
+		case req.Duration != "":
+			duration, err := time.ParseDuration(req.Duration)
+			if err != nil || duration <= 0 {
+				respondBadRequest(c, "Invalid duration")
+				return
+			}
+			until = time.Now().Add(duration)
+		case req.Until != "":
+			parsed, err := parseTimeParam(req.Until)
+			if err != nil {
+				respondBadRequest(c, "Invalid until")
+				return
+			}
+			until = parsed
+		default:
+			respondBadRequest(c, "duration or until is required")
+			return
+		}
+
+		snooze, snoozed, err := alerts.Snooze(&models.AlertSnooze{
+			Network:   req.Network,
+			Address:   req.Address,
+			Types:     req.Types,
+			Reason:    req.Reason,
+			Until:     until,
+			CreatedBy: requestActor(c, req.Operator),
+		})
+		if err != nil && snooze == nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		if err != nil {
+			logger.Errorf("Failed to snooze open alerts for %s: %v", snooze.ID, err)
+			respondInternalError(c)
+			return
+		}
+
+		logger.Infof("Alerts for %s snoozed until %s by %s (%d open alerts snoozed)", snooze.ID, snooze.Until.Format(time.RFC3339), snooze.CreatedBy, snoozed)
+
+		c.JSON(http.StatusCreated, APIResponse{
+			Success: true,
+			Data: gin.H{
+				"snooze":         snooze,
+				"alerts_snoozed": snoozed,
+			},
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// deleteAlertSnooze 取消地址静默
+func deleteAlertSnooze(alerts *processor.AlertStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !alertStoreEnabled(c, alerts) {
+			return
+		}
+
+		network, address := c.Param("network"), c.Param("address")
+		removed, err := alerts.Unsnooze(network, address)
+		if err != nil {
+			logger.Errorf("Failed to remove alert snooze for %s:%s: %v", network, address, err)
+			respondInternalError(c)
+			return
+		}
+		if !removed {
+			respondNotFound(c, "Alert snooze not found")
+			return
+		}
+
+		logger.Infof("Alert snooze for %s:%s removed by %s", network, address, principalName(c))
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Message:   "Alert snooze removed",
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// alertStoreEnabled 未启用告警存储时返回404
+func alertStoreEnabled(c *gin.Context, alerts *processor.AlertStore) bool {
+	if alerts == nil {
+		respondNotFound(c, "Alert store is disabled")
+		return false
+	}
+	return true
+}
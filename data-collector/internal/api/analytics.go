@@ -0,0 +1,196 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"web3-data-collector/internal/processor"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+)
+
+// 代币流向查询的默认及最大日期范围
+const (
+	defaultTokenFlowRange = 7 * 24 * time.Hour
+	maxTokenFlowRange     = 90 * 24 * time.Hour
+)
+
+// 供应量每日统计的默认及最大日期范围
+const (
+	defaultSupplyRange = 30 * 24 * time.Hour
+	maxSupplyRange     = 365 * 24 * time.Hour
+)
+
+// getTokenFlows 查询代币每日在各实体类别之间的流量及净流量
+func getTokenFlows(dataProcessor *processor.DataProcessor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		flows := dataProcessor.TokenFlows()
+		if flows == nil {
+			respondNotFound(c, "token flow aggregation is disabled")
+			return
+		}
+
+		network := c.Param("network")
+		if !networkNamePattern.MatchString(network) {
+			respondBadRequest(c, "invalid network")
+			return
+		}
+		if !common.IsHexAddress(c.Param("token")) {
+			respondBadRequest(c, "invalid token")
+			return
+		}
+		token := common.HexToAddress(c.Param("token")).Hex()
+
+		start, end, err := parseTimeRange(parseQueryParams(c))
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		if end.IsZero() {
+			end = time.Now()
+		}
+		if start.IsZero() {
+			start = end.Add(-defaultTokenFlowRange)
+		}
+		if end.Sub(start) > maxTokenFlowRange {
+			respondBadRequest(c, "time range must not exceed 90 days")
+			return
+		}
+
+		days, err := flows.Query(network, token, start, end)
+		if err != nil {
+			logger.Errorf("Failed to query token flows of %s for %s: %v", token, network, err)
+			respondInternalError(c)
+			return
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"network":    network,
+				"token":      token,
+				"start_date": start.UTC().Format("2006-01-02"),
+				"end_date":   end.UTC().Format("2006-01-02"),
+				"days":       days,
+			},
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// getSupply 查询网络原生币的累计发行、销毁及小费，以及每日变化
+func getSupply(dataProcessor *processor.DataProcessor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		supply := dataProcessor.Supply()
+		if supply == nil {
+			respondNotFound(c, "supply tracking is disabled")
+			return
+		}
+
+		network := c.Param("network")
+		if !networkNamePattern.MatchString(network) {
+			respondBadRequest(c, "invalid network")
+			return
+		}
+
+		start, end, err := parseTimeRange(parseQueryParams(c))
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		if end.IsZero() {
+			end = time.Now()
+		}
+		if start.IsZero() {
+			start = end.Add(-defaultSupplyRange)
+		}
+		if end.Sub(start) > maxSupplyRange {
+			respondBadRequest(c, "time range must not exceed 365 days")
+			return
+		}
+
+		totals, err := supply.Totals(network)
+		if err != nil {
+			logger.Errorf("Failed to query supply totals for %s: %v", network, err)
+			respondInternalError(c)
+			return
+		}
+		days, err := supply.Daily(network, start, end)
+		if err != nil {
+			logger.Errorf("Failed to query daily supply for %s: %v", network, err)
+			respondInternalError(c)
+			return
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"network":    network,
+				"totals":     totals,
+				"start_date": start.UTC().Format("2006-01-02"),
+				"end_date":   end.UTC().Format("2006-01-02"),
+				"days":       days,
+			},
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// getStablecoinSupply 查询网络上监控的稳定币的累计铸造、销毁及净变化，以及每日变化
+func getStablecoinSupply(dataProcessor *processor.DataProcessor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stablecoins := dataProcessor.Stablecoins()
+		if stablecoins == nil {
+			respondNotFound(c, "stablecoin monitoring is disabled")
+			return
+		}
+
+		network := c.Param("network")
+		if !networkNamePattern.MatchString(network) {
+			respondBadRequest(c, "invalid network")
+			return
+		}
+
+		start, end, err := parseTimeRange(parseQueryParams(c))
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		if end.IsZero() {
+			end = time.Now()
+		}
+		if start.IsZero() {
+			start = end.Add(-defaultSupplyRange)
+		}
+		if end.Sub(start) > maxSupplyRange {
+			respondBadRequest(c, "time range must not exceed 365 days")
+			return
+		}
+
+		totals, err := stablecoins.Totals(network)
+		if err != nil {
+			logger.Errorf("Failed to query stablecoin supply totals for %s: %v", network, err)
+			respondInternalError(c)
+			return
+		}
+		days, err := stablecoins.Daily(network, start, end)
+		if err != nil {
+			logger.Errorf("Failed to query daily stablecoin supply for %s: %v", network, err)
+			respondInternalError(c)
+			return
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"network":    network,
+				"totals":     totals,
+				"start_date": start.UTC().Format("2006-01-02"),
+				"end_date":   end.UTC().Format("2006-01-02"),
+				"days":       days,
+			},
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyCreateRequest 创建API key请求
+type APIKeyCreateRequest struct {
+	Name string `json:"name" binding:"required"`
+	Role string `json:"role" binding:"required"`
+}
+
+// listAPIKeys 获取动态API key
+func listAPIKeys(auth *Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !authEnabled(c, auth) {
+			return
+		}
+
+		keys, err := auth.ListKeys()
+		if err != nil {
+			logger.Errorf("Failed to list api keys: %v", err)
+			respondInternalError(c)
+			return
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      keys,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// createAPIKey 创建API key，明文key只在响应中返回一次
+func createAPIKey(auth *Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !authEnabled(c, auth) {
+			return
+		}
+
+		var req APIKeyCreateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		if req.Role != RoleRead && req.Role != RoleAdmin {
+			respondBadRequest(c, "role must be read or admin")
+			return
+		}
+
+		key, record, err := auth.CreateKey(req.Name, req.Role, principalName(c))
+		if err != nil {
+			logger.Errorf("Failed to create api key %s: %v", req.Name, err)
+			respondInternalError(c)
+			return
+		}
+
+		logger.Infof("API key %s (%s, role %s) created by %s", record.ID, record.Name, record.Role, record.CreatedBy)
+
+		c.JSON(http.StatusCreated, APIResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"key":    key,
+				"record": record,
+			},
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// revokeAPIKey 吊销API key
+func revokeAPIKey(auth *Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !authEnabled(c, auth) {
+			return
+		}
+
+		id := c.Param("id")
+		revoked, err := auth.RevokeKey(id)
+		if err != nil {
+			logger.Errorf("Failed to revoke api key %s: %v", id, err)
+			respondInternalError(c)
+			return
+		}
+		if !revoked {
+			respondNotFound(c, "API key not found")
+			return
+		}
+
+		logger.Infof("API key %s revoked by %s", id, principalName(c))
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Message:   "API key revoked",
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// authEnabled 未启用认证时API key管理无意义，返回404
+func authEnabled(c *gin.Context, auth *Authenticator) bool {
+	if auth == nil {
+		respondNotFound(c, "API authentication is disabled")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,382 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 访问角色，admin包含read的全部权限
+const (
+	RoleRead  = "read"
+	RoleAdmin = "admin"
+)
+
+// apiKeysKey 动态API key在Redis中的哈希，字段为key ID，值为APIKeyRecord的JSON
+const apiKeysKey = "api_keys"
+
+// RedisLayouts API写入的Redis键布局，修改APIKeyRecord的存储格式时提升版本并注册迁移
+func RedisLayouts() []database.RedisLayout {
+	return []database.RedisLayout{
+		{
+			Name:        "api_keys",
+			Pattern:     apiKeysKey,
+			Version:     1,
+			Description: "动态API key哈希，字段为key ID，值为APIKeyRecord的JSON",
+		},
+		{
+			Name:        "query_cache",
+			Pattern:     queryCacheKeyPrefix + ":{network}:{block_hash}:{query_hash}",
+			Version:     1,
+			Description: "查询接口的JSON响应，按配置的TTL过期，网络有新区块后不再命中",
+		},
+		{
+			Name:        "rate_limit",
+			Pattern:     rateLimitKeyPrefix + ":{scope}:{key|ip}:{caller}",
+			Version:     1,
+			Description: "API限流令牌桶哈希，字段为tokens及ts（毫秒），补满后过期",
+		},
+	}
+}
+
+// apiKeyCacheTTL 动态API key的本地缓存时间，其他实例的变更最迟在该时间后生效
+const apiKeyCacheTTL = 10 * time.Second
+
+// principalContextKey 认证通过的调用方在gin.Context中的键
+const principalContextKey = "principal"
+
+// Principal 认证通过的调用方
+type Principal struct {
+	Name   string `json:"name"`
+	Role   string `json:"role"`
+	Method string `json:"method"` // api_key / jwt
+}
+
+// APIKeyRecord 动态API key，只保存key的SHA-256
+type APIKeyRecord struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Role      string `json:"role"`
+	Hash      string `json:"hash,omitempty"`
+	CreatedBy string `json:"created_by"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// Authenticator API key与JWT认证
+type Authenticator struct {
+	client     *database.RedisClient
+	jwtSecret  []byte
+	jwtIssuer  string
+	staticKeys map[string]Principal // key的SHA-256 -> 调用方
+
+	mu       sync.Mutex
+	keys     map[string]APIKeyRecord // key的SHA-256 -> 动态key
+	loadedAt time.Time
+}
+
+// NewAuthenticator 根据配置创建认证器，未启用时返回nil
+func NewAuthenticator(cfg config.AuthConfig, redisClient *database.RedisClient) *Authenticator {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	auth := &Authenticator{
+		client:     redisClient,
+		jwtSecret:  []byte(cfg.JWTSecret),
+		jwtIssuer:  cfg.JWTIssuer,
+		staticKeys: make(map[string]Principal, len(cfg.APIKeys)),
+	}
+	for _, key := range cfg.APIKeys {
+		auth.staticKeys[hashAPIKey(key.Key)] = Principal{Name: key.Name, Role: key.Role, Method: "api_key"}
+	}
+
+	logger.Infof("API authentication enabled (%d static keys, jwt: %v)", len(cfg.APIKeys), cfg.JWTSecret != "")
+	return auth
+}
+
+// Require 要求调用方具有指定角色，认证器为nil时不做检查
+func (a *Authenticator) Require(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if a == nil {
+			c.Next()
+			return
+		}
+
+		principal, err := a.authenticate(c.Request)
+		if err != nil {
+			logger.Warnf("Rejected %s %s from %s: %v", c.Request.Method, c.Request.URL.Path, c.ClientIP(), err)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, APIResponse{
+				Success:   false,
+				Message:   "Unauthorized",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if role == RoleAdmin && principal.Role != RoleAdmin {
+			logger.Warnf("Rejected %s %s for %s: admin role required", c.Request.Method, c.Request.URL.Path, principal.Name)
+			c.AbortWithStatusJSON(http.StatusForbidden, APIResponse{
+				Success:   false,
+				Message:   "Admin role required",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.Set(principalContextKey, principal)
+		c.Next()
+	}
+}
+
+// authenticate 依次尝试X-API-Key和Bearer JWT
+func (a *Authenticator) authenticate(r *http.Request) (*Principal, error) {
+	return a.Authenticate(r.Header.Get("X-API-Key"), r.Header.Get("Authorization"))
+}
+
+// Authenticate 按API key或Authorization头（Bearer JWT）认证调用方，apiKey非空时优先；供gRPC等非HTTP接口复用
+func (a *Authenticator) Authenticate(apiKey, authorization string) (*Principal, error) {
+	if apiKey != "" {
+		return a.authenticateAPIKey(apiKey)
+	}
+
+	if token, ok := strings.CutPrefix(authorization, "Bearer "); ok {
+		return a.authenticateJWT(strings.TrimSpace(token))
+	}
+
+	return nil, fmt.Errorf("missing credentials")
+}
+
+func (a *Authenticator) authenticateAPIKey(key string) (*Principal, error) {
+	hash := hashAPIKey(key)
+	if principal, exists := a.staticKeys[hash]; exists {
+		return &principal, nil
+	}
+
+	record, exists, err := a.lookupKey(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load api keys: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("unknown api key")
+	}
+	return &Principal{Name: record.Name, Role: record.Role, Method: "api_key"}, nil
+}
+
+// jwtClaims 使用的JWT声明
+type jwtClaims struct {
+	Subject   string `json:"sub"`
+	Role      string `json:"role"`
+	Issuer    string `json:"iss"`
+	ExpiresAt int64  `json:"exp"`
+	NotBefore int64  `json:"nbf"`
+}
+
+// authenticateJWT 校验HS256签名、有效期及签发方，角色取role声明
+func (a *Authenticator) authenticateJWT(token string) (*Principal, error) {
+	if len(a.jwtSecret) == 0 {
+		return nil, fmt.Errorf("jwt not accepted")
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed jwt")
+	}
+
+	var header struct {
+		Algorithm string `json:"alg"`
+	}
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("invalid jwt header: %w", err)
+	}
+	if header.Algorithm != "HS256" {
+		return nil, fmt.Errorf("unsupported jwt algorithm %q", header.Algorithm)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid jwt signature: %w", err)
+	}
+	mac := hmac.New(sha256.New, a.jwtSecret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, fmt.Errorf("jwt signature mismatch")
+	}
+
+	var claims jwtClaims
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("invalid jwt claims: %w", err)
+	}
+
+	now := time.Now().Unix()
+	if claims.ExpiresAt == 0 || now >= claims.ExpiresAt {
+		return nil, fmt.Errorf("jwt expired")
+	}
+	if claims.NotBefore != 0 && now < claims.NotBefore {
+		return nil, fmt.Errorf("jwt not yet valid")
+	}
+	if a.jwtIssuer != "" && claims.Issuer != a.jwtIssuer {
+		return nil, fmt.Errorf("unexpected jwt issuer %q", claims.Issuer)
+	}
+	if claims.Role != RoleRead && claims.Role != RoleAdmin {
+		return nil, fmt.Errorf("invalid jwt role %q", claims.Role)
+	}
+
+	return &Principal{Name: claims.Subject, Role: claims.Role, Method: "jwt"}, nil
+}
+
+func decodeJWTSegment(segment string, out interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// lookupKey 在动态API key中查找，缓存过期时从Redis重新加载
+func (a *Authenticator) lookupKey(hash string) (APIKeyRecord, bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.keys == nil || time.Since(a.loadedAt) > apiKeyCacheTTL {
+		if err := a.loadKeys(); err != nil {
+			return APIKeyRecord{}, false, err
+		}
+	}
+
+	for storedHash, record := range a.keys {
+		if subtle.ConstantTimeCompare([]byte(storedHash), []byte(hash)) == 1 {
+			return record, true, nil
+		}
+	}
+	return APIKeyRecord{}, false, nil
+}
+
+// loadKeys 从Redis加载全部动态API key，调用方需持有锁
+func (a *Authenticator) loadKeys() error {
+	values, err := a.client.HGetAll(apiKeysKey)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]APIKeyRecord, len(values))
+	for id, value := range values {
+		var record APIKeyRecord
+		if err := json.Unmarshal([]byte(value), &record); err != nil {
+			logger.Warnf("Skipping invalid api key %s: %v", id, err)
+			continue
+		}
+		keys[record.Hash] = record
+	}
+
+	a.keys = keys
+	a.loadedAt = time.Now()
+	return nil
+}
+
+// ListKeys 获取动态API key（不含哈希）
+func (a *Authenticator) ListKeys() ([]APIKeyRecord, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.loadKeys(); err != nil {
+		return nil, err
+	}
+
+	records := make([]APIKeyRecord, 0, len(a.keys))
+	for _, record := range a.keys {
+		record.Hash = ""
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// CreateKey 生成新的API key，明文只在创建时返回
+func (a *Authenticator) CreateKey(name, role, createdBy string) (string, *APIKeyRecord, error) {
+	if role != RoleRead && role != RoleAdmin {
+		return "", nil, fmt.Errorf("role must be %s or %s", RoleRead, RoleAdmin)
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, err
+	}
+	key := "wdc_" + hex.EncodeToString(secret)
+	hash := hashAPIKey(key)
+
+	record := &APIKeyRecord{
+		ID:        hash[:12],
+		Name:      name,
+		Role:      role,
+		Hash:      hash,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now().Unix(),
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := a.client.HSet(apiKeysKey, record.ID, data); err != nil {
+		return "", nil, err
+	}
+	a.invalidate()
+
+	record.Hash = ""
+	return key, record, nil
+}
+
+// RevokeKey 吊销动态API key
+func (a *Authenticator) RevokeKey(id string) (bool, error) {
+	value, err := a.client.HGetAll(apiKeysKey)
+	if err != nil {
+		return false, err
+	}
+	if _, exists := value[id]; !exists {
+		return false, nil
+	}
+	if err := a.client.HDel(apiKeysKey, id); err != nil {
+		return false, err
+	}
+	a.invalidate()
+	return true, nil
+}
+
+func (a *Authenticator) invalidate() {
+	a.mu.Lock()
+	a.keys = nil
+	a.mu.Unlock()
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// principalName 获取调用方名称，未启用认证时为空
+func principalName(c *gin.Context) string {
+	if value, exists := c.Get(principalContextKey); exists {
+		return value.(*Principal).Name
+	}
+	return ""
+}
+
+// requestActor 操作人，启用认证时为调用方，否则为请求中声明的名称
+func requestActor(c *gin.Context, declared string) string {
+	if name := principalName(c); name != "" {
+		return name
+	}
+	return declared
+}
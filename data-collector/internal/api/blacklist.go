@@ -0,0 +1,189 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"web3-data-collector/internal/processor"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BlacklistProposalRequest 黑名单提议请求
+type BlacklistProposalRequest struct {
+	Address       string    `json:"address" binding:"required"`
+	Reason        string    `json:"reason"`
+	ProposedBy    string    `json:"proposed_by"` // 未启用认证时记录的提议人，启用认证时为调用方
+	EffectiveFrom time.Time `json:"effective_from"`
+}
+
+// BlacklistReviewRequest 黑名单审核/停用请求
+type BlacklistReviewRequest struct {
+	Operator string `json:"operator"` // 未启用认证时记录的操作人，启用认证时为调用方
+	Comment  string `json:"comment"`
+}
+
+// listBlacklist 获取黑名单条目
+func listBlacklist(blacklist *processor.Blacklist) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		data := map[string]interface{}{
+			"version": blacklist.Version(),
+			"entries": blacklist.Entries(c.Query("status")),
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      data,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// proposeBlacklistEntry 提议新增黑名单条目
+func proposeBlacklistEntry(blacklist *processor.Blacklist) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req BlacklistProposalRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, APIResponse{
+				Success:   false,
+				Message:   err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		proposer := requestActor(c, req.ProposedBy)
+		if proposer == "" {
+			respondBadRequest(c, "proposed_by is required")
+			return
+		}
+
+		entry, err := blacklist.Propose(req.Address, req.Reason, proposer, req.EffectiveFrom)
+		if err != nil {
+			respondBlacklistError(c, req.Address, err)
+			return
+		}
+
+		logger.Infof("Blacklist entry %s proposed by %s", entry.Address, entry.ProposedBy)
+
+		c.JSON(http.StatusCreated, APIResponse{
+			Success:   true,
+			Data:      entry,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// approveBlacklistEntry 审核通过黑名单条目
+func approveBlacklistEntry(blacklist *processor.Blacklist) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req BlacklistReviewRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, APIResponse{
+				Success:   false,
+				Message:   err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		operator := requestActor(c, req.Operator)
+		if operator == "" {
+			respondBadRequest(c, "operator is required")
+			return
+		}
+
+		entry, err := blacklist.Approve(c.Param("address"), operator)
+		if err != nil {
+			respondBlacklistError(c, c.Param("address"), err)
+			return
+		}
+
+		logger.Infof("Blacklist entry %s approved by %s (version %d)", entry.Address, entry.ReviewedBy, entry.Version)
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      entry,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// retireBlacklistEntry 停用黑名单条目
+func retireBlacklistEntry(blacklist *processor.Blacklist) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req BlacklistReviewRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, APIResponse{
+				Success:   false,
+				Message:   err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		operator := requestActor(c, req.Operator)
+		if operator == "" {
+			respondBadRequest(c, "operator is required")
+			return
+		}
+
+		entry, err := blacklist.Retire(c.Param("address"), operator, req.Comment)
+		if err != nil {
+			respondBlacklistError(c, c.Param("address"), err)
+			return
+		}
+
+		logger.Infof("Blacklist entry %s retired by %s (version %d)", entry.Address, entry.RetiredBy, entry.Version)
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      entry,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// getBlacklistHistory 获取黑名单条目变更历史
+func getBlacklistHistory(blacklist *processor.Blacklist) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		address := c.Param("address")
+
+		entry, exists := blacklist.Get(address)
+		if !exists {
+			c.JSON(http.StatusNotFound, APIResponse{
+				Success:   false,
+				Message:   "Blacklist entry not found",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		data := map[string]interface{}{
+			"entry":   entry,
+			"history": blacklist.History(address),
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      data,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// respondBlacklistError 黑名单存储失败返回500，其余（状态不允许、重复提议等）返回409
+func respondBlacklistError(c *gin.Context, address string, err error) {
+	if errors.Is(err, processor.ErrBlacklistStorage) {
+		logger.Errorf("Failed to update blacklist entry %s: %v", address, err)
+		respondInternalError(c)
+		return
+	}
+
+	c.JSON(http.StatusConflict, APIResponse{
+		Success:   false,
+		Message:   err.Error(),
+		Timestamp: time.Now().Unix(),
+	})
+}
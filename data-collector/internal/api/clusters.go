@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"web3-data-collector/internal/models"
+	"web3-data-collector/internal/processor"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+)
+
+// getAddressCluster 查询地址所在的实体聚类、各启发式的关联次数及聚类中当前生效的黑名单地址
+func getAddressCluster(dataProcessor *processor.DataProcessor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clusters := dataProcessor.Clusters()
+		if clusters == nil {
+			respondNotFound(c, "address clustering is disabled")
+			return
+		}
+
+		network := c.Param("network")
+		if !networkNamePattern.MatchString(network) {
+			respondBadRequest(c, "invalid network")
+			return
+		}
+		if !common.IsHexAddress(c.Param("address")) {
+			respondBadRequest(c, "invalid address")
+			return
+		}
+		address := common.HexToAddress(c.Param("address")).Hex()
+
+		cluster, err := clusters.Get(network, address)
+		if err != nil {
+			logger.Errorf("Failed to get cluster of %s on %s: %v", address, network, err)
+			respondInternalError(c)
+			return
+		}
+		if cluster == nil {
+			respondNotFound(c, "address does not belong to any cluster")
+			return
+		}
+
+		blacklist := dataProcessor.RiskDetector().Blacklist()
+		blacklisted := []models.BlacklistEntry{}
+		now := time.Now()
+		for _, member := range cluster.Members {
+			if entry, ok := blacklist.Match(member, now); ok {
+				blacklisted = append(blacklisted, *entry)
+			}
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"address":     address,
+				"cluster":     cluster,
+				"blacklisted": blacklisted,
+			},
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/models"
+	"web3-data-collector/internal/processor"
+)
+
+// dependencyCheckTimeout 依赖连通性检查的总超时
+const dependencyCheckTimeout = 5 * time.Second
+
+// ComponentStatus 依赖或网络的健康状况
+type ComponentStatus struct {
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// NetworkCheckpoint 网络最后处理的区块及距今时长
+type NetworkCheckpoint struct {
+	LastBlock       uint64     `json:"last_block"`
+	LastProcessedAt *time.Time `json:"last_processed_at,omitempty"`
+	AgeSeconds      *float64   `json:"age_seconds,omitempty"` // 尚未处理区块时为空
+}
+
+// checkDependencies 检查启用的输出端（kafka/influxdb/redis）及查询使用的Redis、InfluxDB，同一依赖只检查一次
+func checkDependencies(ctx context.Context, dataProcessor *processor.DataProcessor, influxClient *database.InfluxDBClient, redisClient *database.RedisClient) map[string]*ComponentStatus {
+	ctx, cancel := context.WithTimeout(ctx, dependencyCheckTimeout)
+	defer cancel()
+
+	components := make(map[string]*ComponentStatus)
+	for sink, err := range dataProcessor.CheckSinks(ctx) {
+		components[sink] = componentStatus(err)
+	}
+	if _, checked := components["redis"]; !checked {
+		components["redis"] = componentStatus(redisClient.Ping())
+	}
+	if _, checked := components["influxdb"]; !checked && influxClient != nil {
+		components["influxdb"] = componentStatus(influxClient.Ping(ctx))
+	}
+	return components
+}
+
+// networkComponents 各网络的健康状况，停用或节点连接断开的网络不健康
+func networkComponents(networkStats map[string]*models.NetworkStats) map[string]*ComponentStatus {
+	components := make(map[string]*ComponentStatus, len(networkStats))
+	for name, stats := range networkStats {
+		status := &ComponentStatus{Healthy: true}
+		switch {
+		case stats.Status == models.NetworkStatusDisabled:
+			status = &ComponentStatus{Error: "disabled: " + stats.DisabledReason}
+		case !stats.IsHealthy:
+			status = &ComponentStatus{Error: "node not connected"}
+		}
+		components[name] = status
+	}
+	return components
+}
+
+// networkCheckpoints 各网络最后处理的区块及距今时长，停用的网络不包含在内
+func networkCheckpoints(networkStats map[string]*models.NetworkStats) map[string]*NetworkCheckpoint {
+	checkpoints := make(map[string]*NetworkCheckpoint, len(networkStats))
+	for name, stats := range networkStats {
+		if stats.Status == models.NetworkStatusDisabled {
+			continue
+		}
+		checkpoint := &NetworkCheckpoint{
+			LastBlock:       stats.LatestBlock,
+			LastProcessedAt: stats.LastProcessedAt,
+		}
+		if stats.LastProcessedAt != nil {
+			age := time.Since(*stats.LastProcessedAt).Seconds()
+			checkpoint.AgeSeconds = &age
+		}
+		checkpoints[name] = checkpoint
+	}
+	return checkpoints
+}
+
+func componentStatus(err error) *ComponentStatus {
+	if err != nil {
+		return &ComponentStatus{Error: err.Error()}
+	}
+	return &ComponentStatus{Healthy: true}
+}
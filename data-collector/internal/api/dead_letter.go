@@ -0,0 +1,46 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"web3-data-collector/internal/processor"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxReplayLimit 单次重放的最大死信条数
+const maxReplayLimit = 10000
+
+// replayDeadLetters 重放死信队列中的数据
+func replayDeadLetters(dataProcessor *processor.DataProcessor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+		if err != nil || limit <= 0 || limit > maxReplayLimit {
+			c.JSON(http.StatusBadRequest, APIResponse{
+				Success:   false,
+				Message:   "limit must be between 1 and 10000",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		result, err := dataProcessor.ReplayDeadLetters(limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, APIResponse{
+				Success:   false,
+				Message:   err.Error(),
+				Data:      result,
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      result,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"strings"
+	"time"
+
+	"web3-data-collector/internal/collector"
+	"web3-data-collector/internal/models"
+	"web3-data-collector/internal/processor"
+
+	"github.com/gin-gonic/gin"
+)
+
+// servePprof 按路径分发到net/http/pprof的处理器；pprof.Index只识别/debug/pprof/前缀，挂载在其他路径下需自行分发
+func servePprof() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch name := strings.TrimPrefix(c.Param("profile"), "/"); name {
+		case "":
+			pprof.Index(c.Writer, c.Request)
+		case "cmdline":
+			pprof.Cmdline(c.Writer, c.Request)
+		case "profile":
+			pprof.Profile(c.Writer, c.Request)
+		case "symbol":
+			pprof.Symbol(c.Writer, c.Request)
+		case "trace":
+			pprof.Trace(c.Writer, c.Request)
+		default:
+			pprof.Handler(name).ServeHTTP(c.Writer, c.Request)
+		}
+	}
+}
+
+// getRuntimeDiagnostics 获取协程数量、输出端队列、内存及GC统计和各网络订阅状态，用于在线排查卡死及泄漏
+func getRuntimeDiagnostics(collector *collector.BlockchainCollector, dataProcessor *processor.DataProcessor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+
+		diagnostics := &models.RuntimeDiagnostics{
+			Goroutines:          runtime.NumGoroutine(),
+			CollectorGoroutines: collector.GoroutineCounts(),
+			QueueDepths:         dataProcessor.SinkQueueDepths(),
+			Memory: models.RuntimeMemory{
+				HeapAllocBytes:  stats.HeapAlloc,
+				HeapInuseBytes:  stats.HeapInuse,
+				HeapObjects:     stats.HeapObjects,
+				StackInuseBytes: stats.StackInuse,
+				SysBytes:        stats.Sys,
+			},
+			GC: models.RuntimeGC{
+				NumGC:             stats.NumGC,
+				PauseTotalSeconds: time.Duration(stats.PauseTotalNs).Seconds(),
+				CPUFraction:       stats.GCCPUFraction,
+				NextGCBytes:       stats.NextGC,
+			},
+			Networks: collector.NetworkDiagnostics(),
+		}
+		if stats.NumGC > 0 {
+			lastGC := time.Unix(0, int64(stats.LastGC))
+			diagnostics.GC.LastGC = &lastGC
+			diagnostics.GC.LastPauseSeconds = time.Duration(stats.PauseNs[(stats.NumGC+255)%256]).Seconds()
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      diagnostics,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"web3-data-collector/internal/ens"
+	"web3-data-collector/internal/processor"
+
+	"github.com/gin-gonic/gin"
+)
+
+// resolveENS 正向解析ENS名称
+func resolveENS(dataProcessor *processor.DataProcessor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		resolver := dataProcessor.ENS()
+		if resolver == nil {
+			respondNotFound(c, "ENS resolution is disabled")
+			return
+		}
+
+		name := ens.Normalize(c.Param("name"))
+		if name == "" {
+			respondBadRequest(c, "invalid name")
+			return
+		}
+
+		address, ok := resolver.ResolveName(name)
+		if !ok {
+			respondNotFound(c, "name not resolved")
+			return
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"name":    name,
+				"address": address,
+			},
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/processor"
+
+	"github.com/gin-gonic/gin"
+)
+
+// gas价格历史的查询窗口限制及聚合粒度
+const (
+	maxGasHistoryWindow = 30 * 24 * time.Hour
+	gasHistoryInterval  = time.Hour
+)
+
+// GasPricePoint 一个小时内交易gas价格的分位数，单位gwei
+type GasPricePoint struct {
+	Timestamp int64   `json:"timestamp"` // 窗口结束时间
+	P25       float64 `json:"p25"`
+	P50       float64 `json:"p50"`
+	P90       float64 `json:"p90"`
+}
+
+// getGasEstimate 获取网络最新区块的gas统计及基于最近区块的费用估算，价格单位为wei
+func getGasEstimate(dataProcessor *processor.DataProcessor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		oracle := dataProcessor.GasOracle()
+		if oracle == nil {
+			respondNotFound(c, "gas oracle is disabled")
+			return
+		}
+
+		network := c.Param("network")
+		if !networkNamePattern.MatchString(network) {
+			respondBadRequest(c, "invalid network")
+			return
+		}
+
+		estimate := oracle.Estimate(network)
+		if estimate == nil {
+			respondNotFound(c, "no gas data for network")
+			return
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"estimate": estimate,
+				"latest":   oracle.Latest(network),
+			},
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// getGasHistory 获取网络按小时统计的历史gas价格分位数
+func getGasHistory(influxClient *database.InfluxDBClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		network := c.Param("network")
+		if !networkNamePattern.MatchString(network) {
+			respondBadRequest(c, "invalid network")
+			return
+		}
+
+		window, err := parseWindow(c.DefaultQuery("window", "7d"))
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+
+		stop := time.Now()
+		records, err := influxClient.GetGasPriceHistory(network, stop.Add(-window), stop, gasHistoryInterval)
+		if err != nil {
+			logger.Errorf("Failed to query gas price history for %s: %v", network, err)
+			respondInternalError(c)
+			return
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"network":  network,
+				"window":   window.String(),
+				"interval": gasHistoryInterval.String(),
+				"unit":     "gwei",
+				"points":   gasPricePoints(records),
+			},
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// gasPricePoints 将各分位数的查询结果按窗口时间合并
+func gasPricePoints(records []map[string]interface{}) []*GasPricePoint {
+	points := make(map[int64]*GasPricePoint)
+	for _, record := range records {
+		windowEnd, ok := record["_time"].(time.Time)
+		if !ok {
+			continue
+		}
+		value, ok := record["_value"].(float64)
+		if !ok {
+			continue
+		}
+
+		point, exists := points[windowEnd.Unix()]
+		if !exists {
+			point = &GasPricePoint{Timestamp: windowEnd.Unix()}
+			points[windowEnd.Unix()] = point
+		}
+
+		gwei := value / 1e9
+		switch record["percentile"] {
+		case "p25":
+			point.P25 = gwei
+		case "p50":
+			point.P50 = gwei
+		case "p90":
+			point.P90 = gwei
+		}
+	}
+
+	result := make([]*GasPricePoint, 0, len(points))
+	for _, point := range points {
+		result = append(result, point)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Timestamp < result[j].Timestamp
+	})
+	return result
+}
+
+// parseWindow 解析查询窗口，支持按天（如7d）或Go时长格式（如12h）
+func parseWindow(value string) (time.Duration, error) {
+	var window time.Duration
+	if days, found := strings.CutSuffix(value, "d"); found {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid window %q", value)
+		}
+		window = time.Duration(n) * 24 * time.Hour
+	} else {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid window %q", value)
+		}
+		window = parsed
+	}
+
+	if window < gasHistoryInterval || window > maxGasHistoryWindow {
+		return 0, fmt.Errorf("window must be between 1h and 30d")
+	}
+	return window, nil
+}
@@ -0,0 +1,535 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"web3-data-collector/internal/collector"
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/models"
+	"web3-data-collector/internal/processor"
+	"web3-data-collector/internal/warehouse"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+// graphqlSchema /graphql的查询结构，列表字段以first/offset分页，since/until为区块时间范围
+const graphqlSchema = `
+scalar Long
+scalar Time
+
+schema {
+	query: Query
+}
+
+type Query {
+	block(network: String!, number: Long!, since: Time, until: Time): Block
+	blocks(network: String!, since: Time, until: Time, first: Int = 20, offset: Int = 0): [Block!]!
+	transaction(network: String!, hash: String!, since: Time, until: Time): Transaction
+	transactions(network: String!, address: String!, since: Time, until: Time, first: Int = 20, offset: Int = 0): [Transaction!]!
+	transfers(network: String!, address: String, since: Time, until: Time, first: Int = 20, offset: Int = 0): [Transfer!]!
+	alerts(network: String, type: String, level: String, status: String, address: String, since: Time, until: Time, first: Int = 20): [Alert!]!
+	addressStats(network: String!, address: String!): AddressStats
+}
+
+type Block {
+	network: String!
+	number: Long!
+	timestamp: Time!
+	miner: String!
+	txCount: Int!
+	gasUsed: Long!
+	gasLimit: Long!
+	size: Long!
+	baseFee: String
+	transactions(first: Int = 100, offset: Int = 0): [Transaction!]!
+}
+
+type Transaction {
+	network: String!
+	hash: String!
+	blockNumber: Long!
+	timestamp: Time!
+	from: String!
+	to: String
+	value: String!
+	valueNative: Float
+	valueUsd: Float
+	gas: Long!
+	gasPrice: String!
+	gasUsed: Long!
+	isContractCall: Boolean!
+	isTokenTransfer: Boolean!
+	transactionType: Int!
+	block: Block
+	logs: [Log!]!
+}
+
+type Log {
+	address: String!
+	logIndex: Int!
+	topics: [String!]!
+	data: String!
+	eventSignature: String
+	removed: Boolean!
+}
+
+type Transfer {
+	network: String!
+	hash: String!
+	blockNumber: Long!
+	timestamp: Time!
+	from: String!
+	to: String!
+	value: String!
+	valueNative: Float
+	valueUsd: Float
+	transaction: Transaction
+}
+
+type Alert {
+	id: String!
+	type: String!
+	level: String!
+	title: String!
+	description: String!
+	network: String!
+	address: String
+	transactionHash: String
+	riskScore: Float!
+	riskFactors: [String!]!
+	status: String!
+	timestamp: Time!
+	acknowledgedBy: String
+	acknowledgedAt: Time
+	resolvedBy: String
+	resolvedAt: Time
+	transaction: Transaction
+}
+
+type AddressStats {
+	network: String!
+	address: String!
+	sentCount: Long!
+	sentVolume: String!
+	receivedCount: Long!
+	receivedVolume: String!
+	firstSeen: Time
+	lastActivity: Time
+	transactions(since: Time, until: Time, first: Int = 20, offset: Int = 0): [Transaction!]!
+	transfers(since: Time, until: Time, first: Int = 20, offset: Int = 0): [Transfer!]!
+}
+`
+
+var (
+	errHistoryUnavailable    = errors.New("historical data is not stored by this deployment")
+	errAlertStoreUnavailable = errors.New("alert store is disabled")
+)
+
+// alertTransactionWindow 告警在交易所在区块处理时产生，按告警时间之前的这段时间查找交易
+const alertTransactionWindow = 24 * time.Hour
+
+// NewGraphQLHandler 创建/graphql处理函数，未启用时返回nil。
+// influxClient为nil时（告警专用部署）区块、交易及转账查询返回错误
+func NewGraphQLHandler(
+	cfg config.GraphQLConfig,
+	collector *collector.BlockchainCollector,
+	dataProcessor *processor.DataProcessor,
+	influxClient *database.InfluxDBClient,
+	redisClient *database.RedisClient,
+) gin.HandlerFunc {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	resolver := &graphqlResolver{
+		collector:   collector,
+		influx:      influxClient,
+		redis:       redisClient,
+		alerts:      dataProcessor.AlertStore(),
+		maxPageSize: cfg.MaxPageSize,
+	}
+	schema := graphql.MustParseSchema(graphqlSchema, resolver, graphql.MaxDepth(cfg.MaxDepth))
+
+	logger.Infof("GraphQL API enabled (max depth %d)", cfg.MaxDepth)
+	return gin.WrapH(&relay.Handler{Schema: schema})
+}
+
+// graphqlResolver 根查询
+type graphqlResolver struct {
+	collector   *collector.BlockchainCollector
+	influx      *database.InfluxDBClient
+	redis       *database.RedisClient
+	alerts      *processor.AlertStore
+	maxPageSize int
+}
+
+// rangeArgs 时间范围及分页参数
+type rangeArgs struct {
+	Since  *graphql.Time
+	Until  *graphql.Time
+	First  int32
+	Offset int32
+}
+
+func (r *graphqlResolver) Block(args struct {
+	Network string
+	Number  Long
+	Since   *graphql.Time
+	Until   *graphql.Time
+}) (*blockResolver, error) {
+	if err := r.checkHistory(args.Network); err != nil {
+		return nil, err
+	}
+	start, stop, err := timeRange(args.Since, args.Until)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := r.influx.GetBlockByNumber(args.Network, uint64(args.Number), start, stop)
+	if err != nil {
+		return nil, r.queryFailed("block", args.Network, err)
+	}
+	if record == nil {
+		return nil, nil
+	}
+	return &blockResolver{root: r, record: record}, nil
+}
+
+func (r *graphqlResolver) Blocks(args struct {
+	Network string
+	rangeArgs
+}) ([]*blockResolver, error) {
+	if err := r.checkHistory(args.Network); err != nil {
+		return nil, err
+	}
+	start, stop, err := timeRange(args.Since, args.Until)
+	if err != nil {
+		return nil, err
+	}
+	limit, offset, err := r.page(args.First, args.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := r.influx.GetBlocks(args.Network, start, stop, limit, offset)
+	if err != nil {
+		return nil, r.queryFailed("blocks", args.Network, err)
+	}
+	return r.blocks(records), nil
+}
+
+func (r *graphqlResolver) Transaction(args struct {
+	Network string
+	Hash    string
+	Since   *graphql.Time
+	Until   *graphql.Time
+}) (*transactionResolver, error) {
+	if err := r.checkHistory(args.Network); err != nil {
+		return nil, err
+	}
+	if !txHashPattern.MatchString(args.Hash) {
+		return nil, errors.New("invalid transaction hash")
+	}
+	start, stop, err := timeRange(args.Since, args.Until)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.transaction(args.Network, args.Hash, start, stop)
+}
+
+func (r *graphqlResolver) Transactions(args struct {
+	Network string
+	Address string
+	rangeArgs
+}) ([]*transactionResolver, error) {
+	address, err := checksumAddress(args.Address)
+	if err != nil {
+		return nil, err
+	}
+	return r.addressTransactions(args.Network, address, args.rangeArgs)
+}
+
+func (r *graphqlResolver) Transfers(args struct {
+	Network string
+	Address *string
+	rangeArgs
+}) ([]*transferResolver, error) {
+	address := ""
+	if args.Address != nil {
+		checksummed, err := checksumAddress(*args.Address)
+		if err != nil {
+			return nil, err
+		}
+		address = checksummed
+	}
+	return r.transfers(args.Network, address, args.rangeArgs)
+}
+
+func (r *graphqlResolver) Alerts(args struct {
+	Network *string
+	Type    *string
+	Level   *string
+	Status  *string
+	Address *string
+	Since   *graphql.Time
+	Until   *graphql.Time
+	First   int32
+}) ([]*alertResolver, error) {
+	if r.alerts == nil {
+		return nil, errAlertStoreUnavailable
+	}
+	start, stop, err := timeRange(args.Since, args.Until)
+	if err != nil {
+		return nil, err
+	}
+	limit, _, err := r.page(args.First, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := r.alerts.List(models.AlertQuery{
+		Network: stringValue(args.Network),
+		Type:    stringValue(args.Type),
+		Level:   strings.ToUpper(stringValue(args.Level)),
+		Status:  strings.ToUpper(stringValue(args.Status)),
+		Address: stringValue(args.Address),
+		Since:   start,
+		Until:   stop,
+		Limit:   limit,
+	})
+	if err != nil {
+		logger.Errorf("GraphQL: failed to list alerts: %v", err)
+		return nil, errors.New("failed to query alerts")
+	}
+
+	alerts := make([]*alertResolver, 0, len(records))
+	for _, record := range records {
+		alerts = append(alerts, &alertResolver{root: r, record: record})
+	}
+	return alerts, nil
+}
+
+func (r *graphqlResolver) AddressStats(args struct {
+	Network string
+	Address string
+}) (*addressStatsResolver, error) {
+	if !networkNamePattern.MatchString(args.Network) {
+		return nil, errors.New("invalid network")
+	}
+	address, err := checksumAddress(args.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := r.redis.HGetAll(warehouse.AddressStatsKey(args.Network, address))
+	if err != nil {
+		logger.Errorf("GraphQL: failed to get address stats of %s for %s: %v", address, args.Network, err)
+		return nil, errors.New("failed to query address stats")
+	}
+	if len(stats) == 0 {
+		return nil, nil
+	}
+	return &addressStatsResolver{root: r, network: args.Network, address: address, stats: stats}, nil
+}
+
+// transaction 按哈希查询交易，未找到时返回nil
+func (r *graphqlResolver) transaction(network, hash string, start, stop time.Time) (*transactionResolver, error) {
+	record, err := r.influx.GetTransactionByHash(network, common.HexToHash(hash).Hex(), start, stop)
+	if err != nil {
+		return nil, r.queryFailed("transaction", network, err)
+	}
+	if record == nil {
+		return nil, nil
+	}
+	return &transactionResolver{root: r, record: record}, nil
+}
+
+// addressTransactions 分页查询地址相关交易
+func (r *graphqlResolver) addressTransactions(network, address string, args rangeArgs) ([]*transactionResolver, error) {
+	if err := r.checkHistory(network); err != nil {
+		return nil, err
+	}
+	start, stop, err := timeRange(args.Since, args.Until)
+	if err != nil {
+		return nil, err
+	}
+	limit, offset, err := r.page(args.First, args.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := r.influx.GetAddressTransactions(network, address, start, stop, limit, offset)
+	if err != nil {
+		return nil, r.queryFailed("transactions", network, err)
+	}
+	return r.transactions(records), nil
+}
+
+// transfers 分页查询原生币转账，address为空时查询全部地址
+func (r *graphqlResolver) transfers(network, address string, args rangeArgs) ([]*transferResolver, error) {
+	if err := r.checkHistory(network); err != nil {
+		return nil, err
+	}
+	start, stop, err := timeRange(args.Since, args.Until)
+	if err != nil {
+		return nil, err
+	}
+	limit, offset, err := r.page(args.First, args.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := r.influx.GetTransfers(network, address, start, stop, limit, offset)
+	if err != nil {
+		return nil, r.queryFailed("transfers", network, err)
+	}
+
+	transfers := make([]*transferResolver, 0, len(records))
+	for _, record := range records {
+		transfers = append(transfers, &transferResolver{root: r, record: record})
+	}
+	return transfers, nil
+}
+
+func (r *graphqlResolver) blocks(records []map[string]interface{}) []*blockResolver {
+	blocks := make([]*blockResolver, 0, len(records))
+	for _, record := range records {
+		blocks = append(blocks, &blockResolver{root: r, record: record})
+	}
+	return blocks
+}
+
+func (r *graphqlResolver) transactions(records []map[string]interface{}) []*transactionResolver {
+	transactions := make([]*transactionResolver, 0, len(records))
+	for _, record := range records {
+		transactions = append(transactions, &transactionResolver{root: r, record: record})
+	}
+	return transactions
+}
+
+// checkHistory 检查历史数据是否可查询及网络名称是否合法
+func (r *graphqlResolver) checkHistory(network string) error {
+	if r.influx == nil {
+		return errHistoryUnavailable
+	}
+	if !networkNamePattern.MatchString(network) {
+		return errors.New("invalid network")
+	}
+	return nil
+}
+
+// page 校验分页参数，单次返回条数不超过max_page_size
+func (r *graphqlResolver) page(first, offset int32) (int, int, error) {
+	if first <= 0 || int(first) > r.maxPageSize {
+		return 0, 0, fmt.Errorf("first must be between 1 and %d", r.maxPageSize)
+	}
+	if offset < 0 {
+		return 0, 0, errors.New("offset must not be negative")
+	}
+	return int(first), int(offset), nil
+}
+
+// queryFailed 记录查询错误，返回给调用方的错误不包含内部细节
+func (r *graphqlResolver) queryFailed(field, network string, err error) error {
+	logger.Errorf("GraphQL: failed to query %s for %s: %v", field, network, err)
+	return fmt.Errorf("failed to query %s", field)
+}
+
+// logsTimeout 从节点获取交易回执的超时时间
+const logsTimeout = 10 * time.Second
+
+// transactionLogs 从节点获取交易回执中的日志
+func (r *graphqlResolver) transactionLogs(ctx context.Context, network, hash string, timestamp time.Time) ([]*models.Event, error) {
+	ctx, cancel := context.WithTimeout(ctx, logsTimeout)
+	defer cancel()
+
+	events, err := r.collector.GetTransactionLogs(ctx, network, hash, timestamp)
+	if err != nil {
+		logger.Warnf("GraphQL: failed to get logs of %s for %s: %v", hash, network, err)
+		return nil, errors.New("failed to fetch transaction logs")
+	}
+	return events, nil
+}
+
+// timeRange 转换时间范围参数
+func timeRange(since, until *graphql.Time) (time.Time, time.Time, error) {
+	var start, stop time.Time
+	if since != nil {
+		start = since.Time
+	}
+	if until != nil {
+		stop = until.Time
+	}
+	if !start.IsZero() && !stop.IsZero() && !stop.After(start) {
+		return time.Time{}, time.Time{}, errors.New("until must be after since")
+	}
+	return start, stop, nil
+}
+
+// checksumAddress 校验地址并转换为存储使用的校验和格式
+func checksumAddress(address string) (string, error) {
+	if !common.IsHexAddress(address) {
+		return "", errors.New("invalid address")
+	}
+	return common.HexToAddress(address).Hex(), nil
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// Long 64位无符号整数标量，GraphQL的Int只有32位，区块号等字段使用Long
+type Long uint64
+
+// ImplementsGraphQLType 对应schema中的Long标量
+func (Long) ImplementsGraphQLType(name string) bool {
+	return name == "Long"
+}
+
+// UnmarshalGraphQL 解析查询中的数字或十进制字符串
+func (l *Long) UnmarshalGraphQL(input interface{}) error {
+	switch value := input.(type) {
+	case int32:
+		if value < 0 {
+			return fmt.Errorf("Long must not be negative, got %d", value)
+		}
+		*l = Long(value)
+	case int64:
+		if value < 0 {
+			return fmt.Errorf("Long must not be negative, got %d", value)
+		}
+		*l = Long(value)
+	case float64:
+		if value < 0 || value != float64(uint64(value)) {
+			return fmt.Errorf("Long must be a non-negative integer, got %v", value)
+		}
+		*l = Long(value)
+	case string:
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid Long %q", value)
+		}
+		*l = Long(parsed)
+	default:
+		return fmt.Errorf("wrong type for Long: %T", input)
+	}
+	return nil
+}
+
+// MarshalJSON 以JSON数字输出
+func (l Long) MarshalJSON() ([]byte, error) {
+	return strconv.AppendUint(nil, uint64(l), 10), nil
+}
@@ -0,0 +1,288 @@
+package api
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"web3-data-collector/internal/models"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// blockResolver 区块，数据来自InfluxDB的blocks measurement
+type blockResolver struct {
+	root   *graphqlResolver
+	record map[string]interface{}
+}
+
+func (b *blockResolver) Network() string         { return recordString(b.record, "network") }
+func (b *blockResolver) Number() Long            { return Long(recordUint(b.record, "number")) }
+func (b *blockResolver) Timestamp() graphql.Time { return recordTime(b.record) }
+func (b *blockResolver) Miner() string           { return recordString(b.record, "miner") }
+func (b *blockResolver) TxCount() int32          { return int32(recordUint(b.record, "tx_count")) }
+func (b *blockResolver) GasUsed() Long           { return Long(recordUint(b.record, "gas_used")) }
+func (b *blockResolver) GasLimit() Long          { return Long(recordUint(b.record, "gas_limit")) }
+func (b *blockResolver) Size() Long              { return Long(recordUint(b.record, "size")) }
+func (b *blockResolver) BaseFee() *string        { return recordOptionalString(b.record, "base_fee") }
+
+// Transactions 区块内的交易，交易与区块的时间戳相同，只查询该时刻的数据
+func (b *blockResolver) Transactions(args struct {
+	First  int32
+	Offset int32
+}) ([]*transactionResolver, error) {
+	limit, offset, err := b.root.page(args.First, args.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	network := b.Network()
+	at := b.Timestamp().Time
+	records, err := b.root.influx.GetBlockTransactions(network, uint64(b.Number()), at, at.Add(time.Second), limit, offset)
+	if err != nil {
+		return nil, b.root.queryFailed("block transactions", network, err)
+	}
+	return b.root.transactions(records), nil
+}
+
+// transactionResolver 交易，数据来自InfluxDB的transactions measurement
+type transactionResolver struct {
+	root   *graphqlResolver
+	record map[string]interface{}
+}
+
+func (t *transactionResolver) Network() string         { return recordString(t.record, "network") }
+func (t *transactionResolver) Hash() string            { return recordString(t.record, "hash") }
+func (t *transactionResolver) BlockNumber() Long       { return Long(recordUint(t.record, "block_number")) }
+func (t *transactionResolver) Timestamp() graphql.Time { return recordTime(t.record) }
+func (t *transactionResolver) From() string            { return recordString(t.record, "from_address") }
+func (t *transactionResolver) To() *string             { return recordOptionalString(t.record, "to_address") }
+func (t *transactionResolver) Value() string           { return recordString(t.record, "value") }
+func (t *transactionResolver) ValueNative() *float64 {
+	return recordOptionalFloat(t.record, "value_native")
+}
+func (t *transactionResolver) ValueUSD() *float64    { return recordOptionalFloat(t.record, "value_usd") }
+func (t *transactionResolver) Gas() Long             { return Long(recordUint(t.record, "gas")) }
+func (t *transactionResolver) GasPrice() string      { return recordString(t.record, "gas_price") }
+func (t *transactionResolver) GasUsed() Long         { return Long(recordUint(t.record, "gas_used")) }
+func (t *transactionResolver) IsContractCall() bool  { return recordBool(t.record, "is_contract") }
+func (t *transactionResolver) IsTokenTransfer() bool { return recordBool(t.record, "is_token") }
+func (t *transactionResolver) TransactionType() int32 {
+	return int32(recordUint(t.record, "transaction_type"))
+}
+
+// Block 交易所在区块
+func (t *transactionResolver) Block() (*blockResolver, error) {
+	network := t.Network()
+	at := t.Timestamp().Time
+	record, err := t.root.influx.GetBlockByNumber(network, uint64(t.BlockNumber()), at, at.Add(time.Second))
+	if err != nil {
+		return nil, t.root.queryFailed("block", network, err)
+	}
+	if record == nil {
+		return nil, nil
+	}
+	return &blockResolver{root: t.root, record: record}, nil
+}
+
+// Logs 交易回执中的日志，查询时从节点获取
+func (t *transactionResolver) Logs(ctx context.Context) ([]*logResolver, error) {
+	events, err := t.root.transactionLogs(ctx, t.Network(), t.Hash(), t.Timestamp().Time)
+	if err != nil {
+		return nil, err
+	}
+
+	logs := make([]*logResolver, 0, len(events))
+	for _, event := range events {
+		logs = append(logs, &logResolver{event: event})
+	}
+	return logs, nil
+}
+
+// logResolver 交易回执中的日志
+type logResolver struct {
+	event *models.Event
+}
+
+func (l *logResolver) Address() string  { return l.event.ContractAddress }
+func (l *logResolver) LogIndex() int32  { return int32(l.event.LogIndex) }
+func (l *logResolver) Topics() []string { return l.event.Topics }
+func (l *logResolver) Data() string     { return l.event.Data }
+func (l *logResolver) Removed() bool    { return l.event.Removed }
+func (l *logResolver) EventSignature() *string {
+	if l.event.EventSignature == "" {
+		return nil
+	}
+	return &l.event.EventSignature
+}
+
+// transferResolver 非零原生币转账
+type transferResolver struct {
+	root   *graphqlResolver
+	record map[string]interface{}
+}
+
+func (t *transferResolver) Network() string         { return recordString(t.record, "network") }
+func (t *transferResolver) Hash() string            { return recordString(t.record, "hash") }
+func (t *transferResolver) BlockNumber() Long       { return Long(recordUint(t.record, "block_number")) }
+func (t *transferResolver) Timestamp() graphql.Time { return recordTime(t.record) }
+func (t *transferResolver) From() string            { return recordString(t.record, "from_address") }
+func (t *transferResolver) To() string              { return recordString(t.record, "to_address") }
+func (t *transferResolver) Value() string           { return recordString(t.record, "value") }
+func (t *transferResolver) ValueNative() *float64 {
+	return recordOptionalFloat(t.record, "value_native")
+}
+func (t *transferResolver) ValueUSD() *float64 { return recordOptionalFloat(t.record, "value_usd") }
+
+// Transaction 转账所在的交易
+func (t *transferResolver) Transaction() (*transactionResolver, error) {
+	at := t.Timestamp().Time
+	return t.root.transaction(t.Network(), t.Hash(), at, at.Add(time.Second))
+}
+
+// alertResolver 告警及处理记录
+type alertResolver struct {
+	root   *graphqlResolver
+	record *models.AlertRecord
+}
+
+func (a *alertResolver) ID() string                    { return a.record.ID }
+func (a *alertResolver) Type() string                  { return a.record.Type }
+func (a *alertResolver) Level() string                 { return a.record.Level }
+func (a *alertResolver) Title() string                 { return a.record.Title }
+func (a *alertResolver) Description() string           { return a.record.Description }
+func (a *alertResolver) Network() string               { return a.record.Network }
+func (a *alertResolver) Address() *string              { return optionalString(a.record.Address) }
+func (a *alertResolver) TransactionHash() *string      { return optionalString(a.record.TransactionHash) }
+func (a *alertResolver) RiskScore() float64            { return a.record.RiskScore }
+func (a *alertResolver) Status() string                { return a.record.Status }
+func (a *alertResolver) Timestamp() graphql.Time       { return graphql.Time{Time: a.record.Timestamp} }
+func (a *alertResolver) AcknowledgedBy() *string       { return optionalString(a.record.AcknowledgedBy) }
+func (a *alertResolver) AcknowledgedAt() *graphql.Time { return optionalTime(a.record.AcknowledgedAt) }
+func (a *alertResolver) ResolvedBy() *string           { return optionalString(a.record.ResolvedBy) }
+func (a *alertResolver) ResolvedAt() *graphql.Time     { return optionalTime(a.record.ResolvedAt) }
+
+func (a *alertResolver) RiskFactors() []string {
+	if a.record.RiskFactors == nil {
+		return []string{}
+	}
+	return a.record.RiskFactors
+}
+
+// Transaction 触发告警的交易，在告警时间之前的alertTransactionWindow内查找
+func (a *alertResolver) Transaction() (*transactionResolver, error) {
+	hash := a.record.TransactionHash
+	if !txHashPattern.MatchString(hash) {
+		return nil, nil
+	}
+	if err := a.root.checkHistory(a.record.Network); err != nil {
+		return nil, err
+	}
+
+	stop := a.record.Timestamp.Add(time.Minute)
+	return a.root.transaction(a.record.Network, hash, stop.Add(-alertTransactionWindow), stop)
+}
+
+// addressStatsResolver 地址统计，数据来自Redis，字段结构见 processor.RedisLayouts
+type addressStatsResolver struct {
+	root    *graphqlResolver
+	network string
+	address string
+	stats   map[string]string
+}
+
+func (s *addressStatsResolver) Network() string             { return s.network }
+func (s *addressStatsResolver) Address() string             { return s.address }
+func (s *addressStatsResolver) SentCount() Long             { return s.count("sent_count") }
+func (s *addressStatsResolver) SentVolume() string          { return s.volume("sent_volume") }
+func (s *addressStatsResolver) ReceivedCount() Long         { return s.count("received_count") }
+func (s *addressStatsResolver) ReceivedVolume() string      { return s.volume("received_volume") }
+func (s *addressStatsResolver) FirstSeen() *graphql.Time    { return s.time("first_seen") }
+func (s *addressStatsResolver) LastActivity() *graphql.Time { return s.time("last_activity") }
+
+// Transactions 地址相关的交易
+func (s *addressStatsResolver) Transactions(args rangeArgs) ([]*transactionResolver, error) {
+	return s.root.addressTransactions(s.network, s.address, args)
+}
+
+// Transfers 地址转出或转入的原生币转账
+func (s *addressStatsResolver) Transfers(args rangeArgs) ([]*transferResolver, error) {
+	return s.root.transfers(s.network, s.address, args)
+}
+
+func (s *addressStatsResolver) count(field string) Long {
+	count, _ := strconv.ParseUint(s.stats[field], 10, 64)
+	return Long(count)
+}
+
+func (s *addressStatsResolver) volume(field string) string {
+	if s.stats[field] == "" {
+		return "0"
+	}
+	return s.stats[field]
+}
+
+func (s *addressStatsResolver) time(field string) *graphql.Time {
+	seconds, err := strconv.ParseInt(s.stats[field], 10, 64)
+	if err != nil || seconds == 0 {
+		return nil
+	}
+	return &graphql.Time{Time: time.Unix(seconds, 0)}
+}
+
+// recordOptionalString 读取字符串字段，不存在或为空时返回nil
+func recordOptionalString(record map[string]interface{}, key string) *string {
+	return optionalString(recordString(record, key))
+}
+
+// recordUint 读取整数字段，InfluxDB按写入类型返回int64或uint64
+func recordUint(record map[string]interface{}, key string) uint64 {
+	switch value := record[key].(type) {
+	case uint64:
+		return value
+	case int64:
+		if value > 0 {
+			return uint64(value)
+		}
+	case float64:
+		if value > 0 && value < math.MaxUint64 {
+			return uint64(value)
+		}
+	}
+	return 0
+}
+
+// recordOptionalFloat 读取浮点字段，不存在时返回nil
+func recordOptionalFloat(record map[string]interface{}, key string) *float64 {
+	value, ok := record[key].(float64)
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
+func recordBool(record map[string]interface{}, key string) bool {
+	value, _ := record[key].(bool)
+	return value
+}
+
+// recordTime 数据点时间，cleanRecord将_time列改为timestamp
+func recordTime(record map[string]interface{}) graphql.Time {
+	value, _ := record["timestamp"].(time.Time)
+	return graphql.Time{Time: value}
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func optionalTime(value *time.Time) *graphql.Time {
+	if value == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *value}
+}
@@ -0,0 +1,215 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/warehouse"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	networkNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+	txHashPattern      = regexp.MustCompile(`^0x[0-9a-fA-F]{64}$`)
+)
+
+// getBlock 按区块号查询历史区块
+func getBlock(influxClient *database.InfluxDBClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		network := c.Param("network")
+		if !networkNamePattern.MatchString(network) {
+			respondBadRequest(c, "invalid network")
+			return
+		}
+
+		number, err := strconv.ParseUint(c.Param("number"), 10, 64)
+		if err != nil {
+			respondBadRequest(c, "invalid block number")
+			return
+		}
+
+		start, stop, err := parseTimeRange(parseQueryParams(c))
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+
+		block, err := influxClient.GetBlockByNumber(network, number, start, stop)
+		if err != nil {
+			logger.Errorf("Failed to query block %d for %s: %v", number, network, err)
+			respondInternalError(c)
+			return
+		}
+
+		if block == nil {
+			respondNotFound(c, "Block not found")
+			return
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      block,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// getTransaction 按哈希查询历史交易
+func getTransaction(influxClient *database.InfluxDBClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		network := c.Param("network")
+		if !networkNamePattern.MatchString(network) {
+			respondBadRequest(c, "invalid network")
+			return
+		}
+
+		hash := c.Param("hash")
+		if !txHashPattern.MatchString(hash) {
+			respondBadRequest(c, "invalid transaction hash")
+			return
+		}
+
+		start, stop, err := parseTimeRange(parseQueryParams(c))
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+
+		tx, err := influxClient.GetTransactionByHash(network, common.HexToHash(hash).Hex(), start, stop)
+		if err != nil {
+			logger.Errorf("Failed to query transaction %s for %s: %v", hash, network, err)
+			respondInternalError(c)
+			return
+		}
+
+		if tx == nil {
+			respondNotFound(c, "Transaction not found")
+			return
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      tx,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// getAddressTransactions 分页查询地址相关的历史交易及地址统计
+func getAddressTransactions(influxClient *database.InfluxDBClient, redisClient *database.RedisClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		network := c.Param("network")
+		if !networkNamePattern.MatchString(network) {
+			respondBadRequest(c, "invalid network")
+			return
+		}
+
+		if !common.IsHexAddress(c.Param("address")) {
+			respondBadRequest(c, "invalid address")
+			return
+		}
+		// 存储时使用校验和格式的地址
+		address := common.HexToAddress(c.Param("address")).Hex()
+
+		params := parseQueryParams(c)
+		start, stop, err := parseTimeRange(params)
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+
+		offset := (params.Page - 1) * params.PageSize
+		transactions, err := influxClient.GetAddressTransactions(network, address, start, stop, params.PageSize, offset)
+		if err != nil {
+			logger.Errorf("Failed to query transactions of %s for %s: %v", address, network, err)
+			respondInternalError(c)
+			return
+		}
+
+		stats, err := redisClient.HGetAll(warehouse.AddressStatsKey(network, address))
+		if err != nil {
+			logger.Warnf("Failed to get address stats of %s for %s: %v", address, network, err)
+		}
+
+		data := map[string]interface{}{
+			"network":      network,
+			"address":      address,
+			"stats":        stats,
+			"transactions": transactions,
+			"page":         params.Page,
+			"page_size":    params.PageSize,
+			"has_more":     len(transactions) == params.PageSize,
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      data,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// parseTimeRange 解析时间范围参数，支持RFC3339或Unix秒
+func parseTimeRange(params *QueryParams) (time.Time, time.Time, error) {
+	start, err := parseTimeParam(params.StartTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start_time: %w", err)
+	}
+
+	stop, err := parseTimeParam(params.EndTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid end_time: %w", err)
+	}
+
+	if !start.IsZero() && !stop.IsZero() && !stop.After(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end_time must be after start_time")
+	}
+
+	return start, stop, nil
+}
+
+// parseTimeParam 解析单个时间参数，为空时返回零值
+func parseTimeParam(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+// respondBadRequest 返回请求参数错误
+func respondBadRequest(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, APIResponse{
+		Success:   false,
+		Message:   message,
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// respondNotFound 返回资源不存在
+func respondNotFound(c *gin.Context, message string) {
+	c.JSON(http.StatusNotFound, APIResponse{
+		Success:   false,
+		Message:   message,
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// respondInternalError 返回内部错误
+func respondInternalError(c *gin.Context) {
+	c.JSON(http.StatusInternalServerError, APIResponse{
+		Success:   false,
+		Message:   "Internal server error",
+		Timestamp: time.Now().Unix(),
+	})
+}
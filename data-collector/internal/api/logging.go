@@ -0,0 +1,94 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"web3-data-collector/internal/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// LoggingUpdateRequest 运行时修改日志级别，模块级别为空时恢复为默认级别。
+// 修改在配置文件的logging部分变化并热加载后被覆盖
+type LoggingUpdateRequest struct {
+	Level   string            `json:"level,omitempty"`
+	Modules map[string]string `json:"modules,omitempty"`
+}
+
+// getLogging 获取默认级别及各模块的生效级别
+func getLogging() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      logging.Current(),
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// updateLogging 修改默认或模块的日志级别，请求中任一级别无效时不做修改
+func updateLogging() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req LoggingUpdateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		if req.Level == "" && len(req.Modules) == 0 {
+			respondBadRequest(c, "level or modules is required")
+			return
+		}
+
+		var level logrus.Level
+		if req.Level != "" {
+			parsed, err := logrus.ParseLevel(req.Level)
+			if err != nil {
+				respondBadRequest(c, err.Error())
+				return
+			}
+			level = parsed
+		}
+		moduleLevels := make(map[string]*logrus.Level, len(req.Modules))
+		for module, value := range req.Modules {
+			if !logging.Known(module) {
+				respondBadRequest(c, fmt.Sprintf("unknown module %q, must be one of %s", module, strings.Join(logging.Names(), ", ")))
+				return
+			}
+			if value == "" {
+				moduleLevels[module] = nil
+				continue
+			}
+			parsed, err := logrus.ParseLevel(value)
+			if err != nil {
+				respondBadRequest(c, fmt.Sprintf("modules.%s: %v", module, err))
+				return
+			}
+			moduleLevels[module] = &parsed
+		}
+
+		if req.Level != "" {
+			logging.SetDefaultLevel(level)
+		}
+		for module, moduleLevel := range moduleLevels {
+			if moduleLevel == nil {
+				logging.ResetModuleLevel(module)
+			} else {
+				logging.SetModuleLevel(module, *moduleLevel)
+			}
+		}
+
+		current := logging.Current()
+		logger.Infof("Log levels changed by %s: level=%s modules=%v", principalName(c), current.Level, current.Modules)
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Message:   "Log levels updated",
+			Data:      current,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"web3-data-collector/internal/collector"
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getInitStatus 获取各网络初始化状态
+func getInitStatus(collector *collector.BlockchainCollector) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      collector.GetInitStatus(),
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// getRPCCosts 获取当日RPC调用成本统计
+func getRPCCosts(collector *collector.BlockchainCollector) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report, err := collector.GetRPCCosts()
+		if err != nil {
+			c.JSON(http.StatusNotFound, APIResponse{
+				Success:   false,
+				Message:   err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      report,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// enableNetwork 重新启用被自动停用的网络
+func enableNetwork(collector *collector.BlockchainCollector) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		networkName := c.Param("network")
+
+		if err := collector.EnableNetwork(networkName); err != nil {
+			c.JSON(http.StatusConflict, APIResponse{
+				Success:   false,
+				Message:   err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		logger.Infof("Network %s re-enabled via admin API", networkName)
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Message:   "Network enabled",
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// getBlockGaps 获取各网络的区块连续性报告，支持network参数过滤
+func getBlockGaps(collector *collector.BlockchainCollector) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reports := collector.GetBlockGaps()
+		if reports == nil {
+			respondNotFound(c, "continuity check is disabled")
+			return
+		}
+
+		if network := c.Query("network"); network != "" {
+			var filtered []*models.NetworkContinuity
+			for _, report := range reports {
+				if report.Network == network {
+					filtered = append(filtered, report)
+				}
+			}
+			if len(filtered) == 0 {
+				respondNotFound(c, "Network not found")
+				return
+			}
+			reports = filtered
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      reports,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// LogFilterRequest 运行时设置网络的日志过滤条件，替代配置文件中的条件
+type LogFilterRequest struct {
+	Addresses []string `json:"addresses"`
+	Topics    []string `json:"topics"` // 事件签名(topic0)
+}
+
+// getLogFilter 获取网络生效的日志过滤条件
+func getLogFilter(collector *collector.BlockchainCollector) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter, err := collector.GetLogFilter(c.Param("network"))
+		if err != nil {
+			respondNotFound(c, err.Error())
+			return
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      filter,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// updateLogFilter 设置网络的日志过滤条件，网络重新建立日志订阅后生效
+func updateLogFilter(collector *collector.BlockchainCollector) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req LogFilterRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+
+		networkName := c.Param("network")
+		filter, err := collector.SetLogFilter(networkName, config.NetworkLogFilterConfig{Addresses: req.Addresses, Topics: req.Topics})
+		if err != nil {
+			respondLogFilterError(c, err)
+			return
+		}
+
+		logger.Infof("Log filter for %s changed by %s: %d addresses, %d topics", networkName, principalName(c), len(req.Addresses), len(req.Topics))
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Message:   "Log filter updated",
+			Data:      filter,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// resetLogFilter 清除运行时设置的日志过滤条件，恢复为配置文件中的条件
+func resetLogFilter(collector *collector.BlockchainCollector) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		networkName := c.Param("network")
+		filter, err := collector.ResetLogFilter(networkName)
+		if err != nil {
+			respondLogFilterError(c, err)
+			return
+		}
+
+		logger.Infof("Log filter for %s reset by %s", networkName, principalName(c))
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Message:   "Log filter reset to config",
+			Data:      filter,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// respondLogFilterError 未配置的网络返回404，其余为请求参数错误
+func respondLogFilterError(c *gin.Context, err error) {
+	if errors.Is(err, collector.ErrNetworkNotConfigured) {
+		respondNotFound(c, err.Error())
+		return
+	}
+	respondBadRequest(c, err.Error())
+}
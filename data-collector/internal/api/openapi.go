@@ -0,0 +1,375 @@
+package api
+
+import (
+	_ "embed"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/buildinfo"
+	"web3-data-collector/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed swagger_ui.html
+var swaggerUIPage string
+
+// routeDoc 路由的文档注解，见 routeDocs。未注解的路由仍会出现在文档中，只缺少说明及数据结构
+type routeDoc struct {
+	Summary  string
+	Tag      string
+	Public   bool         // 不需要认证
+	Query    []queryParam // 查询参数
+	Request  interface{}  // 请求体结构的零值
+	Response interface{}  // APIResponse.data结构的零值，为nil时不描述data
+}
+
+// queryParam 查询参数，Type为OpenAPI的基本类型，为空时为string
+type queryParam struct {
+	Name        string
+	Type        string
+	Description string
+}
+
+// OpenAPI 由Gin路由及路由注解生成OpenAPI 3文档，首次请求时生成，之后复用
+type OpenAPI struct {
+	engine      *gin.Engine
+	basePath    string
+	authEnabled bool
+	assetsURL   string
+
+	once sync.Once
+	spec []byte
+}
+
+// SetupOpenAPI 在group下提供 /openapi.json 及 /docs（Swagger UI），文档覆盖group下的全部路由，不需要认证。
+// 须在注册其余路由的同一engine上调用
+func SetupOpenAPI(engine *gin.Engine, group *gin.RouterGroup, cfg config.OpenAPIConfig, authEnabled bool) {
+	if !cfg.Enabled {
+		return
+	}
+
+	doc := &OpenAPI{
+		engine:      engine,
+		basePath:    group.BasePath(),
+		authEnabled: authEnabled,
+		assetsURL:   strings.TrimSuffix(cfg.UIAssetsURL, "/"),
+	}
+	group.GET("/openapi.json", doc.serveSpec)
+	group.GET("/docs", doc.serveUI)
+}
+
+func (o *OpenAPI) serveSpec(c *gin.Context) {
+	o.once.Do(func() {
+		spec, err := json.Marshal(o.build())
+		if err != nil {
+			logger.Errorf("Failed to generate OpenAPI spec: %v", err)
+			return
+		}
+		o.spec = spec
+	})
+	if o.spec == nil {
+		respondInternalError(c)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", o.spec)
+}
+
+func (o *OpenAPI) serveUI(c *gin.Context) {
+	page := strings.ReplaceAll(swaggerUIPage, "{{ASSETS_URL}}", o.assetsURL)
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+}
+
+// build 生成文档，路径相对于basePath
+func (o *OpenAPI) build() map[string]interface{} {
+	schemas := newSchemaBuilder()
+	envelope := schemas.schemaOf(reflect.TypeOf(APIResponse{}))
+
+	paths := make(map[string]map[string]interface{})
+	routes := o.engine.Routes()
+	sort.Slice(routes, func(i, j int) bool {
+		return routes[i].Path < routes[j].Path
+	})
+
+	for _, route := range routes {
+		if !strings.HasPrefix(route.Path, o.basePath+"/") {
+			continue
+		}
+		relative := strings.TrimPrefix(route.Path, o.basePath)
+		doc := routeDocs[route.Method+" "+relative]
+
+		operation := map[string]interface{}{
+			"operationId": strings.ToLower(route.Method) + operationName(relative),
+			"responses":   o.responses(schemas, envelope, doc),
+		}
+		if doc.Summary != "" {
+			operation["summary"] = doc.Summary
+		}
+		if doc.Tag != "" {
+			operation["tags"] = []string{doc.Tag}
+		}
+		if parameters := parameters(relative, doc.Query); len(parameters) > 0 {
+			operation["parameters"] = parameters
+		}
+		if doc.Request != nil {
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{
+						"schema": schemas.schemaOf(reflect.TypeOf(doc.Request)),
+					},
+				},
+			}
+		}
+		if o.authEnabled && doc.Public {
+			operation["security"] = []interface{}{}
+		}
+
+		openAPIPath := openAPIPath(relative)
+		if paths[openAPIPath] == nil {
+			paths[openAPIPath] = make(map[string]interface{})
+		}
+		paths[openAPIPath][strings.ToLower(route.Method)] = operation
+	}
+
+	components := map[string]interface{}{
+		"schemas": schemas.components,
+	}
+	spec := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "web3-data-collector API",
+			"version": buildinfo.Version,
+		},
+		"servers":    []interface{}{map[string]interface{}{"url": o.basePath}},
+		"paths":      paths,
+		"components": components,
+	}
+
+	// 启用认证时，/admin/*需要admin角色，其余接口需要read角色
+	if o.authEnabled {
+		components["securitySchemes"] = map[string]interface{}{
+			"apiKey": map[string]interface{}{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+			"bearer": map[string]interface{}{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+		}
+		spec["security"] = []interface{}{
+			map[string]interface{}{"apiKey": []string{}},
+			map[string]interface{}{"bearer": []string{}},
+		}
+	}
+
+	return spec
+}
+
+// responses 成功响应为APIResponse，data为路由注解的结构
+func (o *OpenAPI) responses(schemas *schemaBuilder, envelope map[string]interface{}, doc routeDoc) map[string]interface{} {
+	success := envelope
+	if doc.Response != nil {
+		success = map[string]interface{}{
+			"allOf": []interface{}{
+				envelope,
+				map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"data": schemas.schemaOf(reflect.TypeOf(doc.Response)),
+					},
+				},
+			},
+		}
+	}
+
+	responses := map[string]interface{}{
+		"200":     jsonResponse("OK", success),
+		"default": jsonResponse("Error", envelope),
+	}
+	if o.authEnabled && !doc.Public {
+		responses["401"] = jsonResponse("Missing or invalid credentials", envelope)
+		responses["403"] = jsonResponse("Insufficient role", envelope)
+	}
+	return responses
+}
+
+func jsonResponse(description string, schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": schema},
+		},
+	}
+}
+
+// parameters 由Gin路径生成路径参数，并附加注解的查询参数
+func parameters(routePath string, query []queryParam) []interface{} {
+	var parameters []interface{}
+	for _, segment := range strings.Split(routePath, "/") {
+		if len(segment) < 2 || (segment[0] != ':' && segment[0] != '*') {
+			continue
+		}
+		parameters = append(parameters, map[string]interface{}{
+			"name":     segment[1:],
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]interface{}{"type": "string"},
+		})
+	}
+
+	for _, param := range query {
+		paramType := param.Type
+		if paramType == "" {
+			paramType = "string"
+		}
+		parameter := map[string]interface{}{
+			"name":   param.Name,
+			"in":     "query",
+			"schema": map[string]interface{}{"type": paramType},
+		}
+		if param.Description != "" {
+			parameter["description"] = param.Description
+		}
+		parameters = append(parameters, parameter)
+	}
+	return parameters
+}
+
+// openAPIPath 将Gin的 :name 及 *name 参数转换为 {name}
+func openAPIPath(routePath string) string {
+	segments := strings.Split(routePath, "/")
+	for i, segment := range segments {
+		if len(segment) > 1 && (segment[0] == ':' || segment[0] == '*') {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// operationName 由路径生成驼峰形式的操作名，如 /alerts/:id/resolve -> AlertsIdResolve
+func operationName(routePath string) string {
+	var name strings.Builder
+	for _, part := range strings.FieldsFunc(routePath, func(r rune) bool {
+		return r == '/' || r == ':' || r == '*' || r == '-' || r == '_'
+	}) {
+		name.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return name.String()
+}
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	bigIntType = reflect.TypeOf(big.Int{})
+	rawType    = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaBuilder 由Go类型及json标签生成JSON Schema，命名结构体放入components
+type schemaBuilder struct {
+	components map[string]interface{}
+	names      map[reflect.Type]string
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{
+		components: make(map[string]interface{}),
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+func (b *schemaBuilder) schemaOf(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case bigIntType:
+		return map[string]interface{}{"type": "integer"}
+	case rawType:
+		return map[string]interface{}{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": b.schemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": b.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + b.component(t)}
+	}
+	return map[string]interface{}{}
+}
+
+// component 注册命名结构体，同名的不同类型以包名区分
+func (b *schemaBuilder) component(t reflect.Type) string {
+	if name, exists := b.names[t]; exists {
+		return name
+	}
+
+	name := t.Name()
+	if _, taken := b.components[name]; taken {
+		pkg := path.Base(t.PkgPath())
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	b.names[t] = name
+	// 先占位，自引用的结构体引用同一组件
+	b.components[name] = map[string]interface{}{}
+	b.components[name] = b.object(t)
+	return name
+}
+
+// object 按json标签生成对象属性，匿名嵌入的结构体展开
+func (b *schemaBuilder) object(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	b.addFields(t, properties)
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
+
+func (b *schemaBuilder) addFields(t reflect.Type, properties map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				b.addFields(embedded, properties)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = b.schemaOf(field.Type)
+	}
+}
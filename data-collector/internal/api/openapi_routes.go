@@ -0,0 +1,172 @@
+package api
+
+import (
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/logging"
+	"web3-data-collector/internal/metrics"
+	"web3-data-collector/internal/models"
+)
+
+// 常用查询参数
+var (
+	timeRangeQuery = []queryParam{
+		{Name: "start_time", Description: "RFC3339或Unix秒"},
+		{Name: "end_time", Description: "RFC3339或Unix秒"},
+	}
+	pageQuery = []queryParam{
+		{Name: "page", Type: "integer", Description: "从1开始"},
+		{Name: "page_size", Type: "integer", Description: "1-100，默认20"},
+	}
+)
+
+// jsonObject 未定义结构体的data，按JSON对象描述
+type jsonObject = map[string]interface{}
+
+// routeDocs OpenAPI文档的路由注解，键为 方法 + 相对/api/v1的Gin路径。
+// 新增或修改路由及其请求、响应结构时同步修改此处
+var routeDocs = map[string]routeDoc{
+	// 状态
+	"GET /health":       {Summary: "健康检查，备实例视为健康", Tag: "status", Public: true, Response: map[string]*models.NetworkStats{}},
+	"GET /livez":        {Summary: "存活探针，不检查依赖", Tag: "status", Public: true, Response: jsonObject{}},
+	"GET /readyz":       {Summary: "就绪探针，依赖不可达或没有已同步的网络时返回503", Tag: "status", Public: true, Response: &Readiness{}},
+	"GET /openapi.json": {Summary: "OpenAPI文档", Tag: "status", Public: true},
+	"GET /docs":         {Summary: "Swagger UI", Tag: "status", Public: true},
+	"GET /status":       {Summary: "服务状态", Tag: "status", Response: jsonObject{}},
+	"GET /status/init":  {Summary: "各网络初始化状态", Tag: "status", Response: map[string]*models.NetworkInitStatus{}},
+	"GET /pipeline": {Summary: "各网络处理流水线状态", Tag: "status",
+		Query: []queryParam{{Name: "network"}}, Response: []*models.NetworkPipeline{}},
+
+	// 网络
+	"GET /networks":                {Summary: "全部网络", Tag: "networks", Response: []jsonObject{}},
+	"GET /networks/:network/stats": {Summary: "网络统计", Tag: "networks", Response: jsonObject{}},
+	"GET /networks/:network/gas": {Summary: "gas统计及费用估算，价格为wei", Tag: "networks", Response: struct {
+		Estimate *models.GasEstimate `json:"estimate"`
+		Latest   *models.GasStats    `json:"latest"`
+	}{}},
+	"GET /networks/:network/gas/history": {Summary: "每小时gas价格分位数，单位gwei", Tag: "history",
+		Query: []queryParam{{Name: "window", Description: "如 24h、7d，最长30d，默认7d"}}, Response: struct {
+			Network  string           `json:"network"`
+			Window   string           `json:"window"`
+			Interval string           `json:"interval"`
+			Unit     string           `json:"unit"`
+			Points   []*GasPricePoint `json:"points"`
+		}{}},
+	"GET /networks/:network/volume": {Summary: "每小时交易笔数及交易额", Tag: "history",
+		Query: []queryParam{{Name: "window", Description: "如 24h、7d，默认24h"}}, Response: struct {
+			Network  string         `json:"network"`
+			Window   string         `json:"window"`
+			Interval string         `json:"interval"`
+			Total    *VolumePoint   `json:"total"`
+			Points   []*VolumePoint `json:"points"`
+		}{}},
+	"GET /costs/rpc": {Summary: "当日RPC调用成本", Tag: "networks", Response: &models.RPCCostReport{}},
+
+	// 历史数据
+	"GET /blocks/:network/:number":     {Summary: "按区块号查询区块", Tag: "history", Query: timeRangeQuery, Response: jsonObject{}},
+	"GET /transactions/:network/:hash": {Summary: "按哈希查询交易", Tag: "history", Query: timeRangeQuery, Response: jsonObject{}},
+	"GET /addresses/:network/:address/transactions": {Summary: "地址相关交易及地址统计", Tag: "history",
+		Query: append(append([]queryParam{}, pageQuery...), timeRangeQuery...), Response: jsonObject{}},
+	"GET /trace/flow": {Summary: "资金流向追踪", Tag: "history", Query: append([]queryParam{
+		{Name: "network"},
+		{Name: "from", Description: "起始地址"},
+		{Name: "depth", Type: "integer"},
+		{Name: "limit", Type: "integer", Description: "每个地址最多展开的转账数"},
+	}, timeRangeQuery...), Response: &models.FlowGraph{}},
+	"POST /graphql": {Summary: "GraphQL查询", Tag: "history", Request: struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName,omitempty"`
+		Variables     map[string]interface{} `json:"variables,omitempty"`
+	}{}},
+
+	// 分析
+	"GET /analytics/token-flows/:network/:token": {Summary: "代币每日净流入流出", Tag: "analytics", Query: timeRangeQuery, Response: jsonObject{}},
+	"GET /analytics/supply/:network":             {Summary: "原生币每日供应量变化", Tag: "analytics", Query: timeRangeQuery, Response: jsonObject{}},
+	"GET /analytics/stablecoins/:network":        {Summary: "稳定币每日铸造及销毁", Tag: "analytics", Query: timeRangeQuery, Response: jsonObject{}},
+	"GET /analytics/clusters/:network/:address":  {Summary: "地址所属实体", Tag: "analytics", Response: jsonObject{}},
+	"GET /tokens/:network/:address/risk":         {Summary: "代币跑路/貔貅分析结果", Tag: "risk", Response: &models.TokenRiskProfile{}},
+	"GET /risk/check/:address": {Summary: "地址信誉", Tag: "risk",
+		Query: []queryParam{{Name: "network"}}, Response: jsonObject{}},
+	"GET /ens/:name": {Summary: "解析ENS名称", Tag: "analytics", Response: struct {
+		Name    string `json:"name"`
+		Address string `json:"address"`
+	}{}},
+
+	// 关注列表
+	"GET /watchlists":        {Summary: "调用方的关注列表，admin可获取全部", Tag: "watchlists", Response: []models.Watchlist{}},
+	"POST /watchlists":       {Summary: "创建关注列表", Tag: "watchlists", Request: WatchlistRequest{}, Response: &models.Watchlist{}},
+	"GET /watchlists/:id":    {Summary: "获取关注列表", Tag: "watchlists", Response: &models.Watchlist{}},
+	"PUT /watchlists/:id":    {Summary: "修改关注列表", Tag: "watchlists", Request: WatchlistRequest{}, Response: &models.Watchlist{}},
+	"DELETE /watchlists/:id": {Summary: "删除关注列表", Tag: "watchlists"},
+
+	// 告警
+	"GET /alerts": {Summary: "查询告警，按时间从新到旧", Tag: "alerts", Query: append([]queryParam{
+		{Name: "network"},
+		{Name: "type"},
+		{Name: "level"},
+		{Name: "status"},
+		{Name: "address"},
+		{Name: "limit", Type: "integer", Description: "默认100"},
+	}, timeRangeQuery...), Response: []*models.AlertRecord{}},
+	"GET /alerts/snoozes": {Summary: "生效中的告警静默", Tag: "alerts", Response: []*models.AlertSnooze{}},
+	"POST /alerts/snoozes": {Summary: "按地址静默告警", Tag: "alerts", Request: AlertSnoozeRequest{}, Response: struct {
+		Snooze        *models.AlertSnooze `json:"snooze"`
+		AlertsSnoozed int                 `json:"alerts_snoozed"`
+	}{}},
+	"DELETE /alerts/snoozes/:network/:address": {Summary: "取消告警静默", Tag: "alerts"},
+	"GET /alerts/:id":                          {Summary: "获取告警及处理记录", Tag: "alerts", Response: &models.AlertRecord{}},
+	"POST /alerts/:id/acknowledge":             {Summary: "确认告警", Tag: "alerts", Request: AlertActionRequest{}, Response: &models.AlertRecord{}},
+	"POST /alerts/:id/resolve":                 {Summary: "解决告警", Tag: "alerts", Request: AlertActionRequest{}, Response: &models.AlertRecord{}},
+
+	// 函数签名
+	"GET /signatures/:selector": {Summary: "选择器对应的签名，按解析优先级排列", Tag: "signatures", Response: []models.MethodSignature{}},
+
+	// 指标
+	"GET /metrics/stats": {Summary: "指标统计", Tag: "metrics", Response: jsonObject{}},
+	"GET /metrics/performance": {Summary: "性能指标", Tag: "metrics",
+		Query: []queryParam{{Name: "window", Description: "默认1h"}}, Response: &metrics.PerformanceMetrics{}},
+	"GET /filters/stats": {Summary: "过滤规则及命中次数", Tag: "metrics", Response: jsonObject{}},
+
+	// 管理
+	"POST /admin/reload":                          {Summary: "重新加载配置", Tag: "admin", Response: &config.ReloadResult{}},
+	"GET /admin/config":                           {Summary: "当前配置", Tag: "admin", Response: jsonObject{}},
+	"GET /admin/logging":                          {Summary: "默认及各模块的日志级别", Tag: "admin", Response: &logging.Levels{}},
+	"PUT /admin/logging":                          {Summary: "运行时修改日志级别，模块级别为空时恢复默认", Tag: "admin", Request: LoggingUpdateRequest{}, Response: &logging.Levels{}},
+	"POST /admin/networks/:network/enable":        {Summary: "重新启用被停用的网络", Tag: "admin"},
+	"GET /admin/networks/:network/log-filters":    {Summary: "网络生效的日志过滤条件", Tag: "admin", Response: &models.NetworkLogFilter{}},
+	"PUT /admin/networks/:network/log-filters":    {Summary: "运行时设置网络的日志订阅及回填条件，网络重新建立监控后生效", Tag: "admin", Request: LogFilterRequest{}, Response: &models.NetworkLogFilter{}},
+	"DELETE /admin/networks/:network/log-filters": {Summary: "恢复为配置文件中的日志过滤条件", Tag: "admin", Response: &models.NetworkLogFilter{}},
+	"GET /admin/gaps": {Summary: "缺失及乱序区块", Tag: "admin",
+		Query: []queryParam{{Name: "network"}}, Response: []*models.NetworkContinuity{}},
+	"POST /admin/dlq/replay": {Summary: "重放死信队列", Tag: "admin",
+		Query: []queryParam{{Name: "limit", Type: "integer", Description: "默认100"}}, Response: &models.DeadLetterReplayResult{}},
+	"GET /admin/debug/pprof/*profile":  {Summary: "pprof，需启用server.pprof", Tag: "admin"},
+	"POST /admin/debug/pprof/*profile": {Summary: "pprof符号查询，需启用server.pprof", Tag: "admin"},
+	"GET /admin/debug/runtime":         {Summary: "运行时诊断", Tag: "admin", Response: &models.RuntimeDiagnostics{}},
+	"POST /admin/siem/export": {Summary: "将历史告警导出到SIEM", Tag: "admin", Query: []queryParam{
+		{Name: "network"},
+		{Name: "window", Description: "默认1d"},
+		{Name: "limit", Type: "integer", Description: "默认1000"},
+	}, Response: &SIEMExportResult{}},
+	"GET /admin/apikeys": {Summary: "动态API key", Tag: "admin", Response: []APIKeyRecord{}},
+	"POST /admin/apikeys": {Summary: "创建API key，key只在创建时返回", Tag: "admin", Request: APIKeyCreateRequest{}, Response: struct {
+		Key    string        `json:"key"`
+		Record *APIKeyRecord `json:"record"`
+	}{}},
+	"DELETE /admin/apikeys/:id":           {Summary: "吊销API key", Tag: "admin"},
+	"GET /admin/signatures":               {Summary: "全部函数签名", Tag: "admin", Response: []models.MethodSignature{}},
+	"POST /admin/signatures":              {Summary: "添加函数签名", Tag: "admin", Request: SignatureRequest{}, Response: &models.MethodSignature{}},
+	"DELETE /admin/signatures/:signature": {Summary: "删除函数签名，内置及种子文件签名不可删除", Tag: "admin"},
+	"GET /admin/quarantine": {Summary: "隔离数据", Tag: "admin",
+		Query: []queryParam{{Name: "limit", Type: "integer", Description: "默认100"}}, Response: []*models.QuarantineEntry{}},
+	"POST /admin/quarantine/:id/resubmit": {Summary: "重新提交隔离数据", Tag: "admin", Response: &models.QuarantineEntry{}},
+	"DELETE /admin/quarantine/:id":        {Summary: "删除隔离数据", Tag: "admin"},
+	"GET /admin/blacklist": {Summary: "黑名单条目", Tag: "admin",
+		Query: []queryParam{{Name: "status"}}, Response: struct {
+			Version uint64                  `json:"version"`
+			Entries []models.BlacklistEntry `json:"entries"`
+		}{}},
+	"POST /admin/blacklist":                  {Summary: "提议新增黑名单条目", Tag: "admin", Request: BlacklistProposalRequest{}, Response: &models.BlacklistEntry{}},
+	"POST /admin/blacklist/:address/approve": {Summary: "审核通过黑名单条目", Tag: "admin", Request: BlacklistReviewRequest{}, Response: &models.BlacklistEntry{}},
+	"POST /admin/blacklist/:address/retire":  {Summary: "停用黑名单条目", Tag: "admin", Request: BlacklistReviewRequest{}, Response: &models.BlacklistEntry{}},
+	"GET /admin/blacklist/:address/history":  {Summary: "黑名单条目变更历史", Tag: "admin", Response: jsonObject{}},
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"web3-data-collector/internal/collector"
+	"web3-data-collector/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getPipeline 获取各网络的处理流水线阶段图及运行统计，可通过network参数只查询单个网络
+func getPipeline(collector *collector.BlockchainCollector) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		pipelines := collector.GetPipeline()
+
+		if network := c.Query("network"); network != "" {
+			var filtered []*models.NetworkPipeline
+			for _, pipeline := range pipelines {
+				if pipeline.Network == network {
+					filtered = append(filtered, pipeline)
+				}
+			}
+			if len(filtered) == 0 {
+				respondNotFound(c, "Network not found")
+				return
+			}
+			pipelines = filtered
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      pipelines,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"web3-data-collector/internal/buildinfo"
+	"web3-data-collector/internal/collector"
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/leader"
+	"web3-data-collector/internal/models"
+	"web3-data-collector/internal/processor"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NetworkReadiness 网络是否已同步到链头附近
+type NetworkReadiness struct {
+	Synced   bool   `json:"synced"`
+	BlockLag uint64 `json:"block_lag"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Readiness 就绪检查结果
+type Readiness struct {
+	Ready        bool                         `json:"ready"`
+	Standby      bool                         `json:"standby"`
+	MaxBlockLag  int                          `json:"max_block_lag"`
+	Dependencies map[string]*ComponentStatus  `json:"dependencies"`
+	Networks     map[string]*NetworkReadiness `json:"networks"`
+}
+
+// getLiveness 存活探针，只要进程能处理请求即返回200，不检查依赖，依赖故障时不应重启进程
+func getLiveness() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, APIResponse{
+			Success: true,
+			Message: "Service is alive",
+			Data: gin.H{
+				"uptime":  buildinfo.Uptime().Round(time.Second).String(),
+				"version": buildinfo.Version,
+			},
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// getReadiness 就绪探针，启用的输出端及Redis、InfluxDB均可连接，且至少一个网络落后链头不超过max_block_lag时就绪。
+// 备实例及未分配到网络的分片实例不处理区块，只检查依赖
+func getReadiness(
+	collector *collector.BlockchainCollector,
+	dataProcessor *processor.DataProcessor,
+	influxClient *database.InfluxDBClient,
+	redisClient *database.RedisClient,
+	reloader *config.Reloader,
+	elector *leader.Elector,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		maxBlockLag := reloader.Current().Server.Readiness.MaxBlockLag
+		readiness := &Readiness{
+			Ready:        true,
+			Standby:      elector != nil && !elector.IsLeader(),
+			MaxBlockLag:  maxBlockLag,
+			Dependencies: checkDependencies(c.Request.Context(), dataProcessor, influxClient, redisClient),
+			Networks:     make(map[string]*NetworkReadiness),
+		}
+		if shards := collector.ShardStatus(); shards != nil && len(shards.Networks) == 0 {
+			readiness.Standby = true
+		}
+
+		var message string
+		var unreachable []string
+		for name, dependency := range readiness.Dependencies {
+			if !dependency.Healthy {
+				unreachable = append(unreachable, name)
+			}
+		}
+		if len(unreachable) > 0 {
+			sort.Strings(unreachable)
+			readiness.Ready = false
+			message = "Unreachable dependencies: " + strings.Join(unreachable, ", ")
+		}
+
+		synced := false
+		for name, stats := range collector.GetNetworkStats() {
+			network := &NetworkReadiness{BlockLag: stats.BlockLag}
+			switch {
+			case stats.Status == models.NetworkStatusDisabled:
+				network.Error = "disabled: " + stats.DisabledReason
+			case !stats.IsHealthy:
+				network.Error = "node not connected"
+			case stats.BlockLag > uint64(maxBlockLag):
+				network.Error = fmt.Sprintf("%d blocks behind head", stats.BlockLag)
+			default:
+				network.Synced = true
+				synced = true
+			}
+			readiness.Networks[name] = network
+		}
+		if !synced && !readiness.Standby && readiness.Ready {
+			readiness.Ready = false
+			message = "No network is synced"
+		}
+
+		status := http.StatusOK
+		switch {
+		case !readiness.Ready:
+			status = http.StatusServiceUnavailable
+		case readiness.Standby:
+			message = "Service is on standby"
+		default:
+			message = "Service is ready"
+		}
+
+		c.JSON(status, APIResponse{
+			Success:   readiness.Ready,
+			Message:   message,
+			Data:      readiness,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
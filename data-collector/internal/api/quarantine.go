@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"web3-data-collector/internal/processor"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxQuarantineListLimit 单次查询的最大隔离条数
+const maxQuarantineListLimit = 1000
+
+// listQuarantine 按隔离时间顺序列出隔离数据
+func listQuarantine(supervisor *processor.Supervisor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+		if err != nil || limit <= 0 || limit > maxQuarantineListLimit {
+			c.JSON(http.StatusBadRequest, APIResponse{
+				Success:   false,
+				Message:   "limit must be between 1 and 1000",
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		entries, err := supervisor.List(limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, APIResponse{
+				Success:   false,
+				Message:   err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      entries,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// resubmitQuarantined 重新处理隔离数据，成功后移出隔离存储
+func resubmitQuarantined(supervisor *processor.Supervisor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		entry, err := supervisor.Resubmit(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusUnprocessableEntity, APIResponse{
+				Success:   false,
+				Message:   err.Error(),
+				Data:      entry,
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Message:   "Quarantined record resubmitted",
+			Data:      entry,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// deleteQuarantined 丢弃隔离数据
+func deleteQuarantined(supervisor *processor.Supervisor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := supervisor.Delete(c.Param("id")); err != nil {
+			c.JSON(http.StatusBadRequest, APIResponse{
+				Success:   false,
+				Message:   err.Error(),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Message:   "Quarantined record deleted",
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// queryCacheKeyPrefix 查询结果在Redis中的键前缀
+const queryCacheKeyPrefix = "query_cache"
+
+// QueryCache 在Redis中缓存查询接口的成功响应，多实例共享。
+// 缓存键包含网络最新区块的哈希，新区块写入后旧结果不再命中，最长保留ttl
+type QueryCache struct {
+	client         *database.RedisClient
+	ttl            time.Duration
+	metricsManager *metrics.Manager
+}
+
+// NewQueryCache 创建查询缓存，未启用时返回nil
+func NewQueryCache(cfg config.QueryCacheConfig, redisClient *database.RedisClient, metricsManager *metrics.Manager) *QueryCache {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	ttl, err := time.ParseDuration(cfg.TTL)
+	if err != nil || ttl <= 0 {
+		ttl = 10 * time.Second
+	}
+
+	logger.Infof("API query cache enabled (ttl %v)", ttl)
+	return &QueryCache{
+		client:         redisClient,
+		ttl:            ttl,
+		metricsManager: metricsManager,
+	}
+}
+
+// Cache 缓存路由的成功响应，缓存为nil时不做缓存。
+// Redis不可用时直接查询，响应头X-Cache标明是否命中
+func (qc *QueryCache) Cache() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if qc == nil {
+			c.Next()
+			return
+		}
+
+		key := qc.key(c)
+		route := c.FullPath()
+		if body, found, err := qc.client.GetIfExists(key); err != nil {
+			logger.Warnf("Failed to read query cache for %s: %v", route, err)
+		} else if found {
+			qc.metricsManager.RecordQueryCache(route, "hit")
+			c.Header("X-Cache", "HIT")
+			c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(body))
+			c.Abort()
+			return
+		}
+
+		qc.metricsManager.RecordQueryCache(route, "miss")
+		c.Header("X-Cache", "MISS")
+		writer := &cachingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		if writer.Status() != http.StatusOK {
+			return
+		}
+		if err := qc.client.Set(key, writer.body.String(), qc.ttl); err != nil {
+			logger.Warnf("Failed to store query cache for %s: %v", route, err)
+		}
+	}
+}
+
+// key 由网络最新区块、路由及查询参数生成缓存键，查询参数按名称排序。
+// 未找到最新区块时只按ttl过期
+func (qc *QueryCache) key(c *gin.Context) string {
+	network := c.Param("network")
+	head, err := qc.client.HGet(fmt.Sprintf("latest_block:%s", network), "hash")
+	if err != nil {
+		head = ""
+	}
+
+	sum := sha256.Sum256([]byte(c.Request.URL.Path + "?" + c.Request.URL.Query().Encode()))
+	return fmt.Sprintf("%s:%s:%s:%s", queryCacheKeyPrefix, network, head, hex.EncodeToString(sum[:16]))
+}
+
+// cachingWriter 在写出响应的同时保留响应体
+type cachingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *cachingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *cachingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitKeyPrefix 令牌桶在Redis中的键前缀
+const rateLimitKeyPrefix = "rate_limit"
+
+// 限流范围，/admin/*与其余接口分别计数，查询流量不占用管理接口的配额
+const (
+	rateLimitScopeRead  = "read"
+	rateLimitScopeAdmin = "admin"
+)
+
+// RateLimiter 按调用方及客户端IP限流，令牌桶保存在Redis中，多实例共享
+type RateLimiter struct {
+	client         *database.RedisClient
+	perKey         config.RateLimitBucket
+	perIP          config.RateLimitBucket
+	metricsManager *metrics.Manager
+}
+
+// NewRateLimiter 创建限流器，未启用时返回nil
+func NewRateLimiter(cfg config.APIRateLimitConfig, redisClient *database.RedisClient, metricsManager *metrics.Manager) *RateLimiter {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logger.Infof("API rate limiting enabled (per key %v/s burst %d, per ip %v/s burst %d)",
+		cfg.PerKey.Rate, cfg.PerKey.Burst, cfg.PerIP.Rate, cfg.PerIP.Burst)
+	return &RateLimiter{
+		client:         redisClient,
+		perKey:         cfg.PerKey,
+		perIP:          cfg.PerIP,
+		metricsManager: metricsManager,
+	}
+}
+
+// PerIP 按客户端IP限流，在认证之前检查，无效凭证的请求同样计数。限流器为nil时不做检查。
+// 客户端IP仅在请求来自server.trusted_proxies时取自转发头，否则为连接的对端地址
+func (rl *RateLimiter) PerIP(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rl == nil || rl.take(c, scope, "ip", c.ClientIP(), rl.perIP) {
+			c.Next()
+		}
+	}
+}
+
+// PerKey 按认证调用方限流，须在认证之后检查，未启用认证时不做检查
+func (rl *RateLimiter) PerKey(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get(principalContextKey)
+		if rl == nil || !exists {
+			c.Next()
+			return
+		}
+
+		principal := value.(*Principal)
+		if rl.take(c, scope, "key", principal.Method+":"+principal.Name, rl.perKey) {
+			c.Next()
+		}
+	}
+}
+
+// take 取一个令牌，超限时返回429及Retry-After并中止请求。Redis不可用时放行
+func (rl *RateLimiter) take(c *gin.Context, scope, limit, id string, bucket config.RateLimitBucket) bool {
+	key := rateLimitKeyPrefix + ":" + scope + ":" + limit + ":" + id
+	allowed, wait, err := rl.client.TakeToken(key, bucket.Rate, bucket.Burst)
+	if err != nil {
+		logger.Warnf("Rate limiter unavailable for %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		rl.metricsManager.RecordAPIRateLimit(scope, limit, "error")
+		return true
+	}
+	if allowed {
+		rl.metricsManager.RecordAPIRateLimit(scope, limit, "allowed")
+		return true
+	}
+
+	rl.metricsManager.RecordAPIRateLimit(scope, limit, "limited")
+	logger.Debugf("Rate limited %s %s for %s %s", c.Request.Method, c.Request.URL.Path, limit, id)
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, APIResponse{
+		Success:   false,
+		Message:   "Rate limit exceeded",
+		Timestamp: time.Now().Unix(),
+	})
+	return false
+}
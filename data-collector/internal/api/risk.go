@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"web3-data-collector/internal/processor"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+)
+
+// checkAddressRisk 按需查询地址信誉：黑名单、可疑合约及诈骗地址库标记，指定network时还查询聚类及混币暴露，
+// 启用代币风险检测且已分析过该地址时附带代币分析结果
+func checkAddressRisk(dataProcessor *processor.DataProcessor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		network := c.Query("network")
+		if network != "" && !networkNamePattern.MatchString(network) {
+			respondBadRequest(c, "invalid network")
+			return
+		}
+		if !common.IsHexAddress(c.Param("address")) {
+			respondBadRequest(c, "invalid address")
+			return
+		}
+		address := common.HexToAddress(c.Param("address")).Hex()
+
+		reputation, err := dataProcessor.RiskDetector().CheckAddress(network, address)
+		if err != nil {
+			logger.Errorf("Failed to check reputation of %s on %s: %v", address, network, err)
+			respondInternalError(c)
+			return
+		}
+
+		data := map[string]interface{}{
+			"reputation": reputation,
+		}
+		if tokenRisk := dataProcessor.TokenRisk(); tokenRisk != nil && network != "" {
+			profile, exists, err := tokenRisk.Profile(network, address)
+			if err != nil {
+				logger.Warnf("Failed to get token risk of %s on %s: %v", address, network, err)
+			} else if exists {
+				data["token_risk"] = profile
+			}
+		}
+		if scamFeeds := dataProcessor.ScamFeeds(); scamFeeds != nil {
+			data["feeds"] = scamFeeds.Status()
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      data,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
@@ -1,17 +1,27 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
+	"web3-data-collector/internal/buildinfo"
 	"web3-data-collector/internal/collector"
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/leader"
+	"web3-data-collector/internal/logging"
 	"web3-data-collector/internal/metrics"
+	"web3-data-collector/internal/models"
+	"web3-data-collector/internal/processor"
 
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
 )
 
+// logger api模块日志，级别可通过logging.modules.api单独设置
+var logger = logging.Module("api")
+
 // APIResponse 标准API响应结构
 type APIResponse struct {
 	Success   bool        `json:"success"`
@@ -20,37 +30,203 @@ type APIResponse struct {
 	Timestamp int64       `json:"timestamp"`
 }
 
-// SetupRoutes 设置API路由
-func SetupRoutes(router *gin.RouterGroup, collector *collector.BlockchainCollector, metricsManager *metrics.Manager) {
+// SetupRoutes 设置API路由，auth为nil时不做认证
+func SetupRoutes(
+	router *gin.RouterGroup,
+	collector *collector.BlockchainCollector,
+	dataProcessor *processor.DataProcessor,
+	metricsManager *metrics.Manager,
+	influxClient *database.InfluxDBClient,
+	redisClient *database.RedisClient,
+	reloader *config.Reloader,
+	elector *leader.Elector,
+	auth *Authenticator,
+) {
+	// 健康检查及探针不需要认证，供负载均衡及Kubernetes探测
+	router.GET("/health", getHealth(collector, elector))
+	router.GET("/livez", getLiveness())
+	router.GET("/readyz", getReadiness(collector, dataProcessor, influxClient, redisClient, reloader, elector))
+
+	// 限流在认证前按IP、认证后按调用方检查，管理接口使用独立的令牌桶
+	limiter := NewRateLimiter(reloader.Current().Server.RateLimit, redisClient, metricsManager)
+	read := router.Group("", limiter.PerIP(rateLimitScopeRead), auth.Require(RoleRead), limiter.PerKey(rateLimitScopeRead))
+
+	// 查询结果缓存，网络有新区块时失效
+	cache := NewQueryCache(reloader.Current().Server.QueryCache, redisClient, metricsManager)
+
 	// 状态相关接口
-	router.GET("/status", getStatus(collector, metricsManager))
-	router.GET("/health", getHealth(collector))
+	read.GET("/status", getStatus(collector, dataProcessor, metricsManager, influxClient, redisClient, elector))
+	read.GET("/status/init", getInitStatus(collector))
+	read.GET("/pipeline", getPipeline(collector))
 	
 	// 网络统计接口
-	router.GET("/networks", getNetworks(collector))
-	router.GET("/networks/:network/stats", getNetworkStats(collector))
+	read.GET("/networks", getNetworks(collector))
+	read.GET("/networks/:network/stats", getNetworkStats(collector))
+	read.GET("/networks/:network/gas", getGasEstimate(dataProcessor))
+	read.GET("/costs/rpc", getRPCCosts(collector))
 	
+	// 历史数据查询接口，告警专用部署不写入InfluxDB，不提供
+	if influxClient != nil {
+		read.GET("/networks/:network/gas/history", cache.Cache(), getGasHistory(influxClient))
+		read.GET("/networks/:network/volume", cache.Cache(), getVolumeHistory(influxClient))
+		read.GET("/blocks/:network/:number", getBlock(influxClient))
+		read.GET("/transactions/:network/:hash", getTransaction(influxClient))
+		read.GET("/addresses/:network/:address/transactions", getAddressTransactions(influxClient, redisClient))
+		read.GET("/trace/flow", traceFlow(influxClient))
+	}
+
+	// GraphQL查询接口，按需获取区块、交易、日志、转账、告警及地址统计
+	if graphqlHandler := NewGraphQLHandler(reloader.Current().Server.GraphQL, collector, dataProcessor, influxClient, redisClient); graphqlHandler != nil {
+		read.POST("/graphql", graphqlHandler)
+	}
+
+	// 分析接口
+	read.GET("/analytics/token-flows/:network/:token", getTokenFlows(dataProcessor))
+	read.GET("/analytics/supply/:network", getSupply(dataProcessor))
+	read.GET("/analytics/stablecoins/:network", getStablecoinSupply(dataProcessor))
+	read.GET("/analytics/clusters/:network/:address", getAddressCluster(dataProcessor))
+
+	// 代币跑路/貔貅分析结果
+	read.GET("/tokens/:network/:address/risk", getTokenRisk(dataProcessor))
+
+	// 地址信誉查询
+	read.GET("/risk/check/:address", checkAddressRisk(dataProcessor))
+
+	// ENS解析接口
+	read.GET("/ens/:name", resolveENS(dataProcessor))
+
+	// 地址关注列表接口，列表属于创建者，read角色即可管理自己的列表
+	watchlists := dataProcessor.Watchlists()
+	read.GET("/watchlists", listWatchlists(watchlists))
+	read.POST("/watchlists", createWatchlist(watchlists))
+	read.GET("/watchlists/:id", getWatchlist(watchlists))
+	read.PUT("/watchlists/:id", updateWatchlist(watchlists))
+	read.DELETE("/watchlists/:id", deleteWatchlist(watchlists))
+
+	// 告警处理接口，记录确认、解决及静默的处理人和时间
+	alerts := dataProcessor.AlertStore()
+	read.GET("/alerts", listAlerts(alerts))
+	read.GET("/alerts/snoozes", listAlertSnoozes(alerts))
+	read.POST("/alerts/snoozes", createAlertSnooze(alerts))
+	read.DELETE("/alerts/snoozes/:network/:address", deleteAlertSnooze(alerts))
+	read.GET("/alerts/:id", getAlert(alerts))
+	read.POST("/alerts/:id/acknowledge", acknowledgeAlert(alerts))
+	read.POST("/alerts/:id/resolve", resolveAlert(alerts))
+
+	// 函数签名查询接口
+	signatures := dataProcessor.Signatures()
+	read.GET("/signatures/:selector", lookupSignature(signatures))
+
 	// 指标接口
-	router.GET("/metrics/stats", getMetricsStats(metricsManager))
-	router.GET("/metrics/performance", getPerformanceMetrics(metricsManager))
+	read.GET("/metrics/stats", getMetricsStats(metricsManager))
+	read.GET("/metrics/performance", getPerformanceMetrics(metricsManager))
+	read.GET("/filters/stats", getFilterStats(dataProcessor))
 	
 	// 管理接口
-	router.POST("/admin/reload", adminReload())
-	router.GET("/admin/config", getConfig())
+	admin := router.Group("/admin", limiter.PerIP(rateLimitScopeAdmin), auth.Require(RoleAdmin), limiter.PerKey(rateLimitScopeAdmin))
+	admin.POST("/reload", adminReload(reloader))
+	admin.GET("/config", getConfig())
+	admin.GET("/logging", getLogging())
+	admin.PUT("/logging", updateLogging())
+	admin.POST("/networks/:network/enable", enableNetwork(collector))
+	admin.GET("/networks/:network/log-filters", getLogFilter(collector))
+	admin.PUT("/networks/:network/log-filters", updateLogFilter(collector))
+	admin.DELETE("/networks/:network/log-filters", resetLogFilter(collector))
+	admin.GET("/gaps", getBlockGaps(collector))
+	admin.POST("/dlq/replay", replayDeadLetters(dataProcessor))
+
+	// 诊断接口，go tool pprof 通过POST查询符号；pprof可读取堆内容并占用CPU，需显式启用
+	if reloader.Current().Server.Pprof.Enabled {
+		if auth == nil {
+			logger.Warn("pprof endpoints are enabled without API authentication")
+		}
+		admin.GET("/debug/pprof/*profile", servePprof())
+		admin.POST("/debug/pprof/*profile", servePprof())
+	}
+	admin.GET("/debug/runtime", getRuntimeDiagnostics(collector, dataProcessor))
+	if influxClient != nil {
+		admin.POST("/siem/export", exportAlertsToSIEM(dataProcessor, influxClient))
+	}
+
+	// API key管理接口
+	admin.GET("/apikeys", listAPIKeys(auth))
+	admin.POST("/apikeys", createAPIKey(auth))
+	admin.DELETE("/apikeys/:id", revokeAPIKey(auth))
+
+	// 函数签名管理接口，内置及种子文件签名不可修改
+	admin.GET("/signatures", listSignatures(signatures))
+	admin.POST("/signatures", addSignature(signatures))
+	admin.DELETE("/signatures/:signature", deleteSignature(signatures))
+
+	// 隔离数据接口
+	supervisor := dataProcessor.Supervisor()
+	admin.GET("/quarantine", listQuarantine(supervisor))
+	admin.POST("/quarantine/:id/resubmit", resubmitQuarantined(supervisor))
+	admin.DELETE("/quarantine/:id", deleteQuarantined(supervisor))
+
+	// 黑名单审核接口
+	blacklist := dataProcessor.RiskDetector().Blacklist()
+	admin.GET("/blacklist", listBlacklist(blacklist))
+	admin.POST("/blacklist", proposeBlacklistEntry(blacklist))
+	admin.POST("/blacklist/:address/approve", approveBlacklistEntry(blacklist))
+	admin.POST("/blacklist/:address/retire", retireBlacklistEntry(blacklist))
+	admin.GET("/blacklist/:address/history", getBlacklistHistory(blacklist))
 }
 
 // getStatus 获取服务状态
-func getStatus(collector *collector.BlockchainCollector, metricsManager *metrics.Manager) gin.HandlerFunc {
+func getStatus(
+	collector *collector.BlockchainCollector,
+	dataProcessor *processor.DataProcessor,
+	metricsManager *metrics.Manager,
+	influxClient *database.InfluxDBClient,
+	redisClient *database.RedisClient,
+	elector *leader.Elector,
+) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		networkStats := collector.GetNetworkStats()
-		
+		build := buildinfo.Get()
+
+		dependencies := checkDependencies(c.Request.Context(), dataProcessor, influxClient, redisClient)
+		healthy := isHealthy(networkStats)
+		for _, dependency := range dependencies {
+			healthy = healthy && dependency.Healthy
+		}
+
+		// 待处理区块数即落后链头的区块数
+		pendingBlocks := make(map[string]uint64, len(networkStats))
+		for name, stats := range networkStats {
+			pendingBlocks[name] = stats.BlockLag
+		}
+
 		status := map[string]interface{}{
 			"service":    "web3-data-collector",
-			"version":    "1.0.0",
-			"uptime":     time.Since(time.Now().Add(-time.Hour)).String(), // 示例运行时间
+			"version":    build.Version,
+			"build":      build,
+			"uptime":     build.Uptime,
+			"started_at": build.StartedAt,
 			"networks":   networkStats,
+			"init":       collector.GetInitStatus(),
 			"metrics":    metricsManager.GetStats(),
-			"healthy":    isHealthy(networkStats),
+			"errors":     metricsManager.ErrorSummary(),
+			"healthy":    healthy,
+			"components": map[string]interface{}{
+				"dependencies": dependencies,
+				"networks":     networkComponents(networkStats),
+			},
+			"queues": map[string]interface{}{
+				"sinks":          dataProcessor.SinkQueueDepths(),
+				"pending_blocks": pendingBlocks,
+			},
+			"checkpoints": networkCheckpoints(networkStats),
+		}
+		if memory := dataProcessor.MemoryWatchdog(); memory != nil {
+			status["memory"] = memory.Status()
+		}
+		if elector != nil {
+			status["leadership"] = elector.Status()
+		}
+		if shards := collector.ShardStatus(); shards != nil {
+			status["sharding"] = shards
 		}
 
 		response := APIResponse{
@@ -63,16 +239,24 @@ func getStatus(collector *collector.BlockchainCollector, metricsManager *metrics
 	}
 }
 
-// getHealth 健康检查
-func getHealth(collector *collector.BlockchainCollector) gin.HandlerFunc {
+// getHealth 健康检查，备实例及未分配到网络的分片实例不处理区块，视为健康
+func getHealth(collector *collector.BlockchainCollector, elector *leader.Elector) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		networkStats := collector.GetNetworkStats()
-		healthy := isHealthy(networkStats)
+		standby := elector != nil && !elector.IsLeader()
+		// 分片模式下实例数多于网络数时，未分配到网络的实例同样视为备用
+		if shards := collector.ShardStatus(); shards != nil && len(shards.Networks) == 0 {
+			standby = true
+		}
+		healthy := standby || isHealthy(networkStats)
 
 		var status int
 		var message string
 
-		if healthy {
+		if standby {
+			status = http.StatusOK
+			message = "Service is on standby"
+		} else if healthy {
 			status = http.StatusOK
 			message = "Service is healthy"
 		} else {
@@ -101,9 +285,13 @@ func getNetworks(collector *collector.BlockchainCollector) gin.HandlerFunc {
 			network := map[string]interface{}{
 				"name":            name,
 				"latest_block":    stats.LatestBlock,
+				"chain_head":      stats.ChainHead,
+				"block_lag":       stats.BlockLag,
 				"is_healthy":      stats.IsHealthy,
 				"error_count":     stats.ErrorCount,
 				"last_update":     stats.LastUpdateTime,
+				"status":          stats.Status,
+				"disabled_reason": stats.DisabledReason,
 			}
 			networks = append(networks, network)
 		}
@@ -140,9 +328,14 @@ func getNetworkStats(collector *collector.BlockchainCollector) gin.HandlerFunc {
 		detailedStats := map[string]interface{}{
 			"network":         stats.Network,
 			"latest_block":    stats.LatestBlock,
+			"chain_head":      stats.ChainHead,
+			"block_lag":       stats.BlockLag,
 			"is_healthy":      stats.IsHealthy,
 			"error_count":     stats.ErrorCount,
 			"last_update":     stats.LastUpdateTime,
+			"status":          stats.Status,
+			"disabled_reason": stats.DisabledReason,
+			"disabled_at":     stats.DisabledAt,
 			"total_tx_processed": stats.TotalTxProcessed,
 			"tx_per_second":      stats.TxPerSecond,
 			"avg_block_time":     stats.AvgBlockTime,
@@ -195,15 +388,37 @@ func getPerformanceMetrics(metricsManager *metrics.Manager) gin.HandlerFunc {
 	}
 }
 
-// adminReload 重新加载配置
-func adminReload() gin.HandlerFunc {
+// getFilterStats 获取过滤规则及各表达式规则的命中次数
+func getFilterStats(dataProcessor *processor.DataProcessor) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 这里应该实现配置重新加载逻辑
-		logrus.Info("Admin reload requested")
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      dataProcessor.FilterEngine().GetFilterStats(),
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// adminReload 重新加载配置，校验失败时返回错误及配置差异
+func adminReload(reloader *config.Reloader) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logger.Info("Admin reload requested")
+
+		result := reloader.Reload()
+		if !result.Applied {
+			c.JSON(http.StatusBadRequest, APIResponse{
+				Success:   false,
+				Message:   "Configuration rejected",
+				Data:      result,
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
 
 		response := APIResponse{
 			Success:   true,
 			Message:   "Configuration reloaded successfully",
+			Data:      result,
 			Timestamp: time.Now().Unix(),
 		}
 
@@ -238,7 +453,7 @@ func getConfig() gin.HandlerFunc {
 }
 
 // isHealthy 检查服务是否健康
-func isHealthy(networkStats map[string]*collector.NetworkStats) bool {
+func isHealthy(networkStats map[string]*models.NetworkStats) bool {
 	if len(networkStats) == 0 {
 		return false
 	}
@@ -296,7 +511,7 @@ func ErrorHandler() gin.HandlerFunc {
 		// 处理错误
 		if len(c.Errors) > 0 {
 			err := c.Errors.Last()
-			logrus.Errorf("API Error: %v", err.Err)
+			logger.Errorf("API Error: %v", err.Err)
 
 			response := APIResponse{
 				Success:   false,
@@ -329,7 +544,7 @@ func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-API-Key")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusNoContent)
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/models"
+	"web3-data-collector/internal/processor"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxSIEMExportLimit 单次导出的最大告警数
+const maxSIEMExportLimit = 10000
+
+// SIEMExportResult 历史告警导出结果
+type SIEMExportResult struct {
+	Found    int `json:"found"`
+	Exported int `json:"exported"`
+	Failed   int `json:"failed"`
+}
+
+// exportAlertsToSIEM 从InfluxDB读取历史告警并转发到已配置的SIEM系统，
+// 早于告警ID写入的记录按类型、网络、交易哈希及时间生成ID
+func exportAlertsToSIEM(dataProcessor *processor.DataProcessor, influxClient *database.InfluxDBClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		forwarder := dataProcessor.SIEMForwarder()
+		if forwarder == nil {
+			respondNotFound(c, "siem export is disabled")
+			return
+		}
+
+		network := c.Query("network")
+		if network != "" && !networkNamePattern.MatchString(network) {
+			respondBadRequest(c, "invalid network")
+			return
+		}
+
+		window, err := parseWindow(c.DefaultQuery("window", "1d"))
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "1000"))
+		if err != nil || limit <= 0 || limit > maxSIEMExportLimit {
+			respondBadRequest(c, "limit must be between 1 and 10000")
+			return
+		}
+
+		stop := time.Now()
+		records, err := influxClient.GetAlerts(network, stop.Add(-window), stop, limit)
+		if err != nil {
+			logger.Errorf("Failed to query alerts for siem export: %v", err)
+			respondInternalError(c)
+			return
+		}
+
+		result := &SIEMExportResult{Found: len(records)}
+		for _, record := range records {
+			alert := historicalAlert(record)
+			if err := forwarder.Export(alert); err != nil {
+				logger.Warnf("Failed to export alert %s to siem: %v", alert.ID, err)
+				result.Failed++
+				continue
+			}
+			result.Exported++
+		}
+
+		status := http.StatusOK
+		if result.Failed > 0 {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, APIResponse{
+			Success:   result.Failed == 0,
+			Data:      result,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// historicalAlert 由alerts measurement记录还原告警，时序库只保存部分字段
+func historicalAlert(record map[string]interface{}) *models.RiskAlert {
+	alert := &models.RiskAlert{
+		ID:              recordString(record, "id"),
+		Type:            recordString(record, "type"),
+		Level:           recordString(record, "level"),
+		Title:           recordString(record, "title"),
+		TransactionHash: recordString(record, "transaction_hash"),
+		Address:         recordString(record, "address"),
+		Network:         recordString(record, "network"),
+		Metadata:        map[string]interface{}{"historical": true},
+	}
+	if score, ok := record["risk_score"].(float64); ok {
+		alert.RiskScore = score
+	}
+	if timestamp, ok := record["timestamp"].(time.Time); ok {
+		alert.Timestamp = timestamp
+	}
+	if alert.ID == "" {
+		alert.ID = models.OpsAlertID(alert.Type, alert.Timestamp, alert.Network, alert.TransactionHash)
+	}
+	return alert
+}
+
+// recordString 读取查询结果中的字符串字段
+func recordString(record map[string]interface{}, key string) string {
+	value, _ := record[key].(string)
+	return value
+}
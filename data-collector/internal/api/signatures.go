@@ -0,0 +1,130 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"web3-data-collector/internal/processor"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SignatureRequest 添加函数签名请求
+type SignatureRequest struct {
+	Signature string `json:"signature" binding:"required"` // 如 transferFrom(address,address,uint256)
+}
+
+// lookupSignature 获取选择器对应的全部签名，按解析优先级排列
+func lookupSignature(signatures *processor.SignatureRegistry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !signaturesEnabled(c, signatures) {
+			return
+		}
+
+		selector := c.Param("selector")
+		candidates, err := signatures.Lookup(selector)
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		if len(candidates) == 0 {
+			respondNotFound(c, "Selector not found")
+			return
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      candidates,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// listSignatures 获取通过API添加的签名
+func listSignatures(signatures *processor.SignatureRegistry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !signaturesEnabled(c, signatures) {
+			return
+		}
+
+		list, err := signatures.List()
+		if err != nil {
+			logger.Errorf("Failed to list method signatures: %v", err)
+			respondInternalError(c)
+			return
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      list,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// addSignature 添加函数签名，选择器由签名计算
+func addSignature(signatures *processor.SignatureRegistry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !signaturesEnabled(c, signatures) {
+			return
+		}
+
+		var req SignatureRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+
+		signature, err := signatures.Add(req.Signature, principalName(c))
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+
+		logger.Infof("Method signature %s (%s) added by %s", signature.Signature, signature.Selector, signature.AddedBy)
+
+		c.JSON(http.StatusCreated, APIResponse{
+			Success:   true,
+			Data:      signature,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// deleteSignature 删除通过API添加的签名
+func deleteSignature(signatures *processor.SignatureRegistry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !signaturesEnabled(c, signatures) {
+			return
+		}
+
+		signature := c.Param("signature")
+		deleted, err := signatures.Delete(signature)
+		if err != nil {
+			logger.Errorf("Failed to delete method signature %s: %v", signature, err)
+			respondInternalError(c)
+			return
+		}
+		if !deleted {
+			respondNotFound(c, "Signature not found")
+			return
+		}
+
+		logger.Infof("Method signature %s deleted by %s", signature, principalName(c))
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Message:   "Signature deleted",
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// signaturesEnabled 未启用函数签名库时返回404
+func signaturesEnabled(c *gin.Context, signatures *processor.SignatureRegistry) bool {
+	if signatures == nil {
+		respondNotFound(c, "Method signatures are disabled")
+		return false
+	}
+	return true
+}
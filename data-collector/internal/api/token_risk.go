@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"web3-data-collector/internal/processor"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+)
+
+// getTokenRisk 获取代币合约的跑路/貔貅分析结果
+func getTokenRisk(dataProcessor *processor.DataProcessor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenRisk := dataProcessor.TokenRisk()
+		if tokenRisk == nil {
+			respondNotFound(c, "token risk detection is disabled")
+			return
+		}
+
+		network := c.Param("network")
+		if !networkNamePattern.MatchString(network) {
+			respondBadRequest(c, "invalid network")
+			return
+		}
+		if !common.IsHexAddress(c.Param("address")) {
+			respondBadRequest(c, "invalid address")
+			return
+		}
+		token := common.HexToAddress(c.Param("address")).Hex()
+
+		profile, exists, err := tokenRisk.Profile(network, token)
+		if err != nil {
+			logger.Errorf("Failed to get token risk of %s on %s: %v", token, network, err)
+			respondInternalError(c)
+			return
+		}
+		if !exists {
+			respondNotFound(c, "token has not been analyzed")
+			return
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      profile,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
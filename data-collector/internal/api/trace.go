@@ -0,0 +1,178 @@
+package api
+
+import (
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+)
+
+// 资金流向追踪的跳数、单地址转出数及节点数上限
+const (
+	defaultTraceDepth  = 3
+	maxTraceDepth      = 5
+	defaultTraceFanout = 25
+	maxTraceFanout     = 100
+	maxTraceNodes      = 500
+)
+
+// traceFlow 从源地址出发，沿已存储的原生币转账逐跳追踪资金去向，返回节点、边及各节点的流入流出金额
+func traceFlow(influxClient *database.InfluxDBClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		params := parseQueryParams(c)
+		if !networkNamePattern.MatchString(params.Network) {
+			respondBadRequest(c, "invalid network")
+			return
+		}
+		if !common.IsHexAddress(c.Query("from")) {
+			respondBadRequest(c, "invalid from address")
+			return
+		}
+		source := common.HexToAddress(c.Query("from")).Hex()
+
+		depth, err := strconv.Atoi(c.DefaultQuery("depth", strconv.Itoa(defaultTraceDepth)))
+		if err != nil || depth <= 0 || depth > maxTraceDepth {
+			respondBadRequest(c, "depth must be between 1 and 5")
+			return
+		}
+		fanout, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultTraceFanout)))
+		if err != nil || fanout <= 0 || fanout > maxTraceFanout {
+			respondBadRequest(c, "limit must be between 1 and 100")
+			return
+		}
+
+		start, stop, err := parseTimeRange(params)
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+
+		graph, err := buildFlowGraph(influxClient, params.Network, source, depth, fanout, start, stop)
+		if err != nil {
+			logger.Errorf("Failed to trace fund flow from %s on %s: %v", source, params.Network, err)
+			respondInternalError(c)
+			return
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      graph,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// buildFlowGraph 按跳数广度优先展开，每个地址只追踪资金首次流入之后的转出（源地址从start开始），
+// 每个地址最多查询fanout笔转出
+func buildFlowGraph(influxClient *database.InfluxDBClient, network, source string, depth, fanout int, start, stop time.Time) (*models.FlowGraph, error) {
+	type flowNode struct {
+		node     models.FlowNode
+		received *big.Int
+		sent     *big.Int
+		since    time.Time // 资金首次流入的时间
+	}
+
+	nodes := map[string]*flowNode{
+		source: {node: models.FlowNode{Address: source}, received: new(big.Int), sent: new(big.Int), since: start},
+	}
+	order := []string{source}
+	graph := &models.FlowGraph{
+		Network: network,
+		Source:  source,
+		Depth:   depth,
+		Edges:   []models.FlowEdge{},
+	}
+
+	frontier := []string{source}
+	for hop := 1; hop <= depth && len(frontier) > 0; hop++ {
+		var next []string
+		for _, address := range frontier {
+			current := nodes[address]
+			transfers, err := influxClient.GetOutgoingTransfers(network, address, current.since, stop, fanout+1)
+			if err != nil {
+				return nil, err
+			}
+			current.node.Expanded = true
+			if len(transfers) > fanout {
+				transfers = transfers[:fanout]
+				graph.Truncated = true
+			}
+
+			for _, transfer := range transfers {
+				edge, ok := flowEdge(address, hop, transfer)
+				if !ok {
+					continue
+				}
+				value, _ := new(big.Int).SetString(edge.Value, 10)
+
+				target, exists := nodes[edge.To]
+				if !exists {
+					if len(nodes) >= maxTraceNodes {
+						graph.Truncated = true
+						continue
+					}
+					target = &flowNode{
+						node:     models.FlowNode{Address: edge.To, Hop: hop},
+						received: new(big.Int),
+						sent:     new(big.Int),
+						since:    edge.Timestamp,
+					}
+					nodes[edge.To] = target
+					order = append(order, edge.To)
+					next = append(next, edge.To)
+				} else if !target.node.Expanded && edge.Timestamp.Before(target.since) {
+					target.since = edge.Timestamp
+				}
+				target.received.Add(target.received, value)
+				current.sent.Add(current.sent, value)
+				graph.Edges = append(graph.Edges, edge)
+			}
+		}
+		frontier = next
+	}
+
+	graph.Nodes = make([]models.FlowNode, 0, len(order))
+	for _, address := range order {
+		node := nodes[address]
+		node.node.Received = node.received.String()
+		node.node.Sent = node.sent.String()
+		graph.Nodes = append(graph.Nodes, node.node)
+	}
+	return graph, nil
+}
+
+// flowEdge 将查询到的转账记录转换为流向图的边，记录不完整时返回false
+func flowEdge(from string, hop int, record map[string]interface{}) (models.FlowEdge, bool) {
+	to, _ := record["to_address"].(string)
+	value, _ := record["value"].(string)
+	timestamp, _ := record["timestamp"].(time.Time)
+	if to == "" || to == from || timestamp.IsZero() {
+		return models.FlowEdge{}, false
+	}
+	if amount, ok := new(big.Int).SetString(value, 10); !ok || amount.Sign() <= 0 {
+		return models.FlowEdge{}, false
+	}
+
+	edge := models.FlowEdge{
+		From:      from,
+		To:        to,
+		Value:     value,
+		Hop:       hop,
+		Timestamp: timestamp,
+	}
+	edge.TransactionHash, _ = record["hash"].(string)
+	edge.ValueNative, _ = record["value_native"].(float64)
+	switch number := record["block_number"].(type) {
+	case uint64:
+		edge.BlockNumber = number
+	case int64:
+		edge.BlockNumber = uint64(number)
+	}
+	return edge, true
+}
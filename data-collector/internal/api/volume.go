@@ -0,0 +1,108 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"web3-data-collector/internal/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// volumeHistoryInterval 交易量图表的聚合粒度
+const volumeHistoryInterval = time.Hour
+
+// VolumePoint 一个小时内的交易笔数及交易额
+type VolumePoint struct {
+	Timestamp    int64   `json:"timestamp"` // 窗口结束时间
+	TxCount      int64   `json:"tx_count"`
+	VolumeNative float64 `json:"volume_native"`
+	VolumeUSD    float64 `json:"volume_usd"`
+}
+
+// getVolumeHistory 获取网络按小时统计的交易笔数及交易额，并汇总整个窗口
+func getVolumeHistory(influxClient *database.InfluxDBClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		network := c.Param("network")
+		if !networkNamePattern.MatchString(network) {
+			respondBadRequest(c, "invalid network")
+			return
+		}
+
+		window, err := parseWindow(c.DefaultQuery("window", "24h"))
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+
+		stop := time.Now()
+		records, err := influxClient.GetTransactionVolumeHistory(network, stop.Add(-window), stop, volumeHistoryInterval)
+		if err != nil {
+			logger.Errorf("Failed to query transaction volume for %s: %v", network, err)
+			respondInternalError(c)
+			return
+		}
+
+		points := volumePoints(records)
+		total := VolumePoint{Timestamp: stop.Unix()}
+		for _, point := range points {
+			total.TxCount += point.TxCount
+			total.VolumeNative += point.VolumeNative
+			total.VolumeUSD += point.VolumeUSD
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success: true,
+			Data: map[string]interface{}{
+				"network":  network,
+				"window":   window.String(),
+				"interval": volumeHistoryInterval.String(),
+				"total":    total,
+				"points":   points,
+			},
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// volumePoints 将各字段的查询结果按窗口时间合并
+func volumePoints(records []map[string]interface{}) []*VolumePoint {
+	points := make(map[int64]*VolumePoint)
+	for _, record := range records {
+		windowEnd, ok := record["_time"].(time.Time)
+		if !ok {
+			continue
+		}
+
+		point, exists := points[windowEnd.Unix()]
+		if !exists {
+			point = &VolumePoint{Timestamp: windowEnd.Unix()}
+			points[windowEnd.Unix()] = point
+		}
+
+		switch record["_field"] {
+		case "tx_count":
+			if value, ok := record["_value"].(int64); ok {
+				point.TxCount = value
+			}
+		case "value_native":
+			if value, ok := record["_value"].(float64); ok {
+				point.VolumeNative = value
+			}
+		case "value_usd":
+			if value, ok := record["_value"].(float64); ok {
+				point.VolumeUSD = value
+			}
+		}
+	}
+
+	result := make([]*VolumePoint, 0, len(points))
+	for _, point := range points {
+		result = append(result, point)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Timestamp < result[j].Timestamp
+	})
+	return result
+}
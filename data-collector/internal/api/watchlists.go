@@ -0,0 +1,196 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"web3-data-collector/internal/models"
+	"web3-data-collector/internal/processor"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WatchlistRequest 创建/更新关注列表请求
+type WatchlistRequest struct {
+	Name      string                  `json:"name" binding:"required"`
+	Networks  []string                `json:"networks"`
+	Addresses []models.WatchedAddress `json:"addresses" binding:"required"`
+}
+
+// listWatchlists 获取调用方的关注列表，admin可获取全部
+func listWatchlists(watchlists *processor.Watchlists) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !watchlistsEnabled(c, watchlists) {
+			return
+		}
+
+		owner := principalName(c)
+		if isAdmin(c) {
+			owner = ""
+		}
+		lists, err := watchlists.List(owner)
+		if err != nil {
+			logger.Errorf("Failed to list watchlists: %v", err)
+			respondInternalError(c)
+			return
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      lists,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// getWatchlist 获取关注列表
+func getWatchlist(watchlists *processor.Watchlists) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		list, ok := ownedWatchlist(c, watchlists)
+		if !ok {
+			return
+		}
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      list,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// createWatchlist 创建关注列表，创建者为调用方
+func createWatchlist(watchlists *processor.Watchlists) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !watchlistsEnabled(c, watchlists) {
+			return
+		}
+
+		var req WatchlistRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+
+		list, err := watchlists.Create(&models.Watchlist{
+			Name:      req.Name,
+			Owner:     principalName(c),
+			Networks:  req.Networks,
+			Addresses: req.Addresses,
+		})
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+
+		logger.Infof("Watchlist %s (%s, %d addresses) created by %s", list.ID, list.Name, len(list.Addresses), list.Owner)
+
+		c.JSON(http.StatusCreated, APIResponse{
+			Success:   true,
+			Data:      list,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// updateWatchlist 替换关注列表的名称、网络及地址
+func updateWatchlist(watchlists *processor.Watchlists) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := ownedWatchlist(c, watchlists); !ok {
+			return
+		}
+
+		var req WatchlistRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+
+		id := c.Param("id")
+		list, exists, err := watchlists.Update(id, &models.Watchlist{
+			Name:      req.Name,
+			Networks:  req.Networks,
+			Addresses: req.Addresses,
+		})
+		if !exists {
+			respondNotFound(c, "Watchlist not found")
+			return
+		}
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+
+		logger.Infof("Watchlist %s updated by %s", id, principalName(c))
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Data:      list,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// deleteWatchlist 删除关注列表
+func deleteWatchlist(watchlists *processor.Watchlists) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := ownedWatchlist(c, watchlists); !ok {
+			return
+		}
+
+		id := c.Param("id")
+		deleted, err := watchlists.Delete(id)
+		if err != nil {
+			logger.Errorf("Failed to delete watchlist %s: %v", id, err)
+			respondInternalError(c)
+			return
+		}
+		if !deleted {
+			respondNotFound(c, "Watchlist not found")
+			return
+		}
+
+		logger.Infof("Watchlist %s deleted by %s", id, principalName(c))
+
+		c.JSON(http.StatusOK, APIResponse{
+			Success:   true,
+			Message:   "Watchlist deleted",
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// ownedWatchlist 获取路径中的关注列表，只有创建者和admin可访问，其他调用方视为不存在
+func ownedWatchlist(c *gin.Context, watchlists *processor.Watchlists) (*models.Watchlist, bool) {
+	if !watchlistsEnabled(c, watchlists) {
+		return nil, false
+	}
+
+	id := c.Param("id")
+	list, exists, err := watchlists.Get(id)
+	if err != nil {
+		logger.Errorf("Failed to get watchlist %s: %v", id, err)
+		respondInternalError(c)
+		return nil, false
+	}
+	if !exists || (!isAdmin(c) && list.Owner != principalName(c)) {
+		respondNotFound(c, "Watchlist not found")
+		return nil, false
+	}
+	return list, true
+}
+
+// watchlistsEnabled 未启用关注列表时返回404
+func watchlistsEnabled(c *gin.Context, watchlists *processor.Watchlists) bool {
+	if watchlists == nil {
+		respondNotFound(c, "Watchlists are disabled")
+		return false
+	}
+	return true
+}
+
+// isAdmin 调用方是否为admin，未启用认证时视为admin
+func isAdmin(c *gin.Context) bool {
+	value, exists := c.Get(principalContextKey)
+	return !exists || value.(*Principal).Role == RoleAdmin
+}
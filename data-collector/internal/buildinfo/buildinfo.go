@@ -0,0 +1,86 @@
+// Package buildinfo 版本及构建信息，构建时通过 -ldflags 注入：
+//
+//	go build -ldflags "-X web3-data-collector/internal/buildinfo.Version=v1.2.0 \
+//	  -X web3-data-collector/internal/buildinfo.Commit=$(git rev-parse --short HEAD) \
+//	  -X web3-data-collector/internal/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// 构建时注入，未注入时Commit取Go工具链记录的VCS信息
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// startTime 进程启动时间，以本包初始化时间为准
+var startTime = time.Now()
+
+// Info 版本、构建及运行时间
+type Info struct {
+	Version       string    `json:"version"`
+	Commit        string    `json:"commit,omitempty"`
+	BuildTime     string    `json:"build_time,omitempty"`
+	GoVersion     string    `json:"go_version"`
+	StartedAt     time.Time `json:"started_at"`
+	Uptime        string    `json:"uptime"`
+	UptimeSeconds float64   `json:"uptime_seconds"`
+}
+
+// Get 获取当前进程的构建信息
+func Get() Info {
+	uptime := Uptime()
+	return Info{
+		Version:       Version,
+		Commit:        commit(),
+		BuildTime:     BuildTime,
+		GoVersion:     runtime.Version(),
+		StartedAt:     startTime,
+		Uptime:        uptime.Round(time.Second).String(),
+		UptimeSeconds: uptime.Seconds(),
+	}
+}
+
+// StartTime 进程启动时间
+func StartTime() time.Time {
+	return startTime
+}
+
+// Uptime 进程已运行时长
+func Uptime() time.Duration {
+	return time.Since(startTime)
+}
+
+// commit 优先使用注入的提交，否则读取go build记录的vcs.revision，工作区有未提交修改时加-dirty
+func commit() string {
+	if Commit != "" {
+		return Commit
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	var revision string
+	var modified bool
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if len(revision) > 12 {
+		revision = revision[:12]
+	}
+	if revision != "" && modified {
+		revision += "-dirty"
+	}
+	return revision
+}
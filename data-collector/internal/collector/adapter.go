@@ -0,0 +1,191 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/faults"
+	"web3-data-collector/internal/models"
+)
+
+// headReconnectInterval 新区块订阅断开后的重连间隔
+const headReconnectInterval = 5 * time.Second
+
+// errHeadsUnsupported 网络不支持订阅新区块（如未配置WebSocket），只通过轮询推进
+var errHeadsUnsupported = errors.New("head subscription not supported")
+
+// ChainAdapter 链族适配器，封装与节点的交互及交易解码。
+// 网络的启动、轮询推进、处理进度、健康检查及自动停用由收集器统一负责，新的链族实现该接口并注册即可接入
+type ChainAdapter interface {
+	// Connect 连接节点并校验可用性，客户端保存在连接器上
+	Connect(connector *NetworkConnector) error
+	// LatestBlock 节点当前的最新区块号（Solana为确认级别下的slot）
+	LatestBlock(ctx context.Context, connector *NetworkConnector) (uint64, error)
+	// SubscribeHeads 订阅新区块，每次通知向heads写入最新区块号（未知时为0，由收集器查询）。
+	// 阻塞直到订阅断开或ctx结束，不支持订阅时返回errHeadsUnsupported
+	SubscribeHeads(ctx context.Context, connector *NetworkConnector, heads chan<- uint64) error
+	// FetchBlock 获取区块并转换为不含交易的通用区块模型，区块不存在（如被跳过的slot）时返回nil
+	FetchBlock(ctx context.Context, connector *NetworkConnector, number uint64) (*ChainBlock, error)
+	// FetchReceipts 按需获取交易回执并填充实际gas用量、执行状态等字段，获取后将Receipts置为true
+	FetchReceipts(ctx context.Context, connector *NetworkConnector, block *ChainBlock) error
+	// DecodeTx 解码区块中第index笔原始交易，不进入处理流程的交易（如Solana投票交易）返回nil
+	DecodeTx(connector *NetworkConnector, block *ChainBlock, index int) (*models.Transaction, error)
+}
+
+// ChainBlock 适配器获取的区块
+type ChainBlock struct {
+	Model    *models.Block
+	Raw      interface{} // 链族原始区块，供适配器解码交易及链族专属的处理阶段使用
+	RawTxs   int         // 原始交易数，收集器依次调用DecodeTx解码
+	Receipts bool        // 交易已按回执填充
+	Selected []int       // 仅关注模式下筛选出的原始交易序号，为nil时解码全部交易
+	SkipLogs bool        // 仅关注模式下区块不可能包含关注地址或事件的日志，跳过依赖区块日志的处理阶段
+}
+
+// transactionSelector 适配器可选实现：仅关注模式下在解码前筛选可能涉及关注地址或事件的原始交易，
+// 结果写入Selected及SkipLogs
+type transactionSelector interface {
+	SelectTransactions(ctx context.Context, connector *NetworkConnector, block *ChainBlock) error
+}
+
+// blockEnricher 适配器可选实现：通用处理之后、推送之前执行的链族专属处理阶段，告警追加到enriched
+type blockEnricher interface {
+	EnrichBlock(ctx context.Context, connector *NetworkConnector, block *ChainBlock, enriched *models.EnrichedBlock)
+}
+
+// networkServices 适配器可选实现：随网络监控启动的链族专属后台任务
+type networkServices interface {
+	// StartServices 实时处理开始前调用，start为处理起点
+	StartServices(ctx context.Context, connector *NetworkConnector, start uint64)
+	// Backfill 仅回填模式下在区块回填之前调用，回填start及之前的历史数据后返回
+	Backfill(ctx context.Context, connector *NetworkConnector, start uint64)
+}
+
+// ChainAdapterFactory 为收集器创建链族适配器
+type ChainAdapterFactory func(bc *BlockchainCollector) ChainAdapter
+
+var (
+	chainAdapters = map[string]ChainAdapterFactory{
+		models.ChainEVM:    newEVMAdapter,
+		models.ChainSolana: newSolanaAdapter,
+	}
+	chainAdaptersMu sync.RWMutex
+)
+
+// RegisterChainAdapter 注册链族适配器，chain对应网络配置的chain字段，已注册的链族被替换。需在创建收集器前调用
+func RegisterChainAdapter(chain string, factory ChainAdapterFactory) {
+	chainAdaptersMu.Lock()
+	defer chainAdaptersMu.Unlock()
+	chainAdapters[chain] = factory
+}
+
+// newChainAdapters 为收集器创建全部已注册链族的适配器
+func newChainAdapters(bc *BlockchainCollector) map[string]ChainAdapter {
+	chainAdaptersMu.RLock()
+	defer chainAdaptersMu.RUnlock()
+
+	adapters := make(map[string]ChainAdapter, len(chainAdapters))
+	for chain, factory := range chainAdapters {
+		adapters[chain] = factory(bc)
+	}
+	return adapters
+}
+
+// chainAdapter 获取网络所属链族的适配器，未配置chain时为EVM
+func (bc *BlockchainCollector) chainAdapter(chain string) (ChainAdapter, error) {
+	if chain == "" {
+		chain = models.ChainEVM
+	}
+	adapter, exists := bc.adapters[chain]
+	if !exists {
+		return nil, fmt.Errorf("unsupported chain %q", chain)
+	}
+	return adapter, nil
+}
+
+// subscribeHeads 订阅新区块并通知监控循环处理，订阅断开后按间隔重连
+func (bc *BlockchainCollector) subscribeHeads(ctx context.Context, connector *NetworkConnector, heads chan<- uint64) {
+	defer bc.wg.Done()
+	defer bc.track(goroutineHeadSubscription)()
+	defer bc.supervisor.Recover(goroutineHeadSubscription, connector.name)
+
+	// 收集器停止时结束订阅
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-bc.stopChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		err := connector.adapter.SubscribeHeads(ctx, connector, heads)
+		if ctx.Err() != nil || errors.Is(err, errHeadsUnsupported) {
+			return
+		}
+
+		bc.reportError(err, connector.name, 0, "Head subscription error")
+		bc.metricsManager.IncrementError(connector.name, "websocket_error")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(headReconnectInterval):
+		}
+	}
+}
+
+// notifyHead 通知监控循环有新区块，循环忙碌时丢弃，由下一次通知或轮询补上
+func notifyHead(heads chan<- uint64, number uint64) {
+	select {
+	case heads <- number:
+	default:
+	}
+}
+
+// decodeTransactions 依次解码区块的原始交易，仅关注模式下只解码筛选出的交易
+func (bc *BlockchainCollector) decodeTransactions(connector *NetworkConnector, block *ChainBlock) error {
+	indexes := block.Selected
+	if indexes == nil {
+		indexes = make([]int, block.RawTxs)
+		for i := range indexes {
+			indexes[i] = i
+		}
+	}
+
+	transactions := make([]models.Transaction, 0, len(indexes))
+	for _, i := range indexes {
+		tx, err := connector.adapter.DecodeTx(connector, block, i)
+		if err != nil {
+			return &faults.DecodeError{Network: connector.name, Kind: "transaction", Block: block.Model.Number, Ref: fmt.Sprint(i), Err: err}
+		}
+		if tx != nil {
+			transactions = append(transactions, *tx)
+		}
+	}
+
+	block.Model.Transactions = transactions
+	block.Model.TxCount = len(transactions)
+	return nil
+}
+
+// blockSummary 区块头摘要
+func blockSummary(block *models.Block, observedAt time.Time) *models.BlockHeader {
+	return &models.BlockHeader{
+		Network:       block.Network,
+		Number:        block.Number,
+		Hash:          block.Hash,
+		ParentHash:    block.ParentHash,
+		Timestamp:     block.Timestamp,
+		GasUsed:       block.GasUsed,
+		GasLimit:      block.GasLimit,
+		Miner:         block.Miner,
+		BaseFeePerGas: block.BaseFeePerGas,
+		ObservedAt:    observedAt,
+	}
+}
@@ -0,0 +1,275 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"web3-data-collector/internal/models"
+	"web3-data-collector/internal/processor"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ERC-20授权与转账事件签名
+var (
+	erc20ApprovalTopic = crypto.Keccak256Hash([]byte("Approval(address,address,uint256)"))
+	erc20TransferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+)
+
+// USDT发行/赎回事件签名，TetherToken的issue/redeem不产生Transfer事件
+var (
+	usdtIssueTopic  = crypto.Keccak256Hash([]byte("Issue(uint256)"))
+	usdtRedeemTopic = crypto.Keccak256Hash([]byte("Redeem(uint256)"))
+)
+
+// balanceOfSelector ERC-20 balanceOf(address)
+var balanceOfSelector = crypto.Keccak256([]byte("balanceOf(address)"))[:4]
+
+// processTokenLogs 按区块内的顺序处理授权与转账事件：记录对未知合约的授权，
+// 转账由被授权合约发起且持有人余额被转空时发送告警，返回生成的告警；
+// 启用流向汇总时，区块内的全部转账按实体类别累加；转账涉及关注地址时发送关注告警；
+// 启用稳定币监控时，记录监控的稳定币的铸造/销毁，单笔超过阈值时发送告警；
+// 启用地址聚类时，按转账关联充值地址及同一交易中共同转出的地址
+func (bc *BlockchainCollector) processTokenLogs(ctx context.Context, connector *NetworkConnector, block *types.Block, blockModel *models.Block) []*models.RiskAlert {
+	detector := bc.dataProcessor.ApprovalDrains()
+	flows := bc.dataProcessor.TokenFlows()
+	watchlists := bc.dataProcessor.Watchlists()
+	stablecoins := bc.dataProcessor.Stablecoins()
+	clusters := bc.dataProcessor.Clusters()
+
+	topics := []common.Hash{erc20ApprovalTopic, erc20TransferTopic}
+	if stablecoins != nil {
+		topics = append(topics, usdtIssueTopic, usdtRedeemTopic)
+	}
+	blockHash := block.Hash()
+	logs, err := connector.filterLogs(ctx, ethereum.FilterQuery{
+		BlockHash: &blockHash,
+		Topics:    [][]common.Hash{topics},
+	})
+	if err != nil {
+		logger.Errorf("Failed to get token logs of block %d for %s: %v", block.NumberU64(), connector.name, err)
+		bc.metricsManager.IncrementError(connector.name, "approval_drain_error")
+		return nil
+	}
+
+	txs := make(map[string]*models.Transaction, len(blockModel.Transactions))
+	for i := range blockModel.Transactions {
+		txs[blockModel.Transactions[i].Hash] = &blockModel.Transactions[i]
+	}
+	contracts := make(map[common.Address]bool)
+
+	var alerts []*models.RiskAlert
+	var transfers []*processor.TokenTransfer
+	var supplyChanges []*processor.StablecoinSupplyChange
+	var clustered []*processor.TokenTransfer
+	for i := range logs {
+		log := &logs[i]
+		if log.Topics[0] == usdtIssueTopic || log.Topics[0] == usdtRedeemTopic {
+			if change := bc.usdtSupplyChange(connector, log, txs, blockModel); change != nil {
+				supplyChanges = append(supplyChanges, change)
+			}
+			continue
+		}
+		// ERC-721的同名事件tokenId为indexed参数（4个topic），只处理ERC-20
+		if len(log.Topics) != 3 || len(log.Data) < 32 {
+			continue
+		}
+
+		switch log.Topics[0] {
+		case erc20ApprovalTopic:
+			if detector == nil {
+				continue
+			}
+			approval := &processor.TokenApproval{
+				Network:         connector.name,
+				Token:           log.Address.Hex(),
+				Owner:           topicAddress(log.Topics[1]),
+				Spender:         topicAddress(log.Topics[2]),
+				Amount:          dataWord(log.Data, 0),
+				TransactionHash: log.TxHash.Hex(),
+				BlockNumber:     log.BlockNumber,
+			}
+			if approval.Owner == approval.Spender || detector.IsTrusted(approval.Spender) {
+				continue
+			}
+			// 撤销授权无需判断被授权方
+			if approval.Amount.Sign() > 0 && !bc.isContract(ctx, connector, common.HexToAddress(approval.Spender), contracts) {
+				continue
+			}
+			if err := detector.RecordApproval(approval); err != nil {
+				logger.Errorf("Failed to record approval %s:%d for %s: %v", log.TxHash.Hex(), log.Index, connector.name, err)
+			}
+
+		case erc20TransferTopic:
+			tx, exists := txs[log.TxHash.Hex()]
+			if !exists {
+				continue
+			}
+			transfer := &processor.TokenTransfer{
+				ID:              models.TransferID(connector.name, log.BlockHash.Hex(), log.TxIndex, log.Index),
+				Network:         connector.name,
+				Token:           log.Address.Hex(),
+				From:            topicAddress(log.Topics[1]),
+				To:              topicAddress(log.Topics[2]),
+				Amount:          dataWord(log.Data, 0),
+				TxFrom:          tx.FromAddress,
+				TxTo:            tx.ToAddress,
+				TransactionHash: tx.Hash,
+				BlockNumber:     blockModel.Number,
+				Timestamp:       blockModel.Timestamp,
+			}
+			if flows != nil {
+				transfers = append(transfers, transfer)
+			}
+			if stablecoins != nil {
+				if change := stablecoins.FromTransfer(transfer); change != nil {
+					supplyChanges = append(supplyChanges, change)
+				}
+			}
+			if watchlists != nil {
+				alerts = append(alerts, bc.dataProcessor.ProcessWatchedTransfer(transfer)...)
+			}
+			if clusters != nil {
+				clusters.ObserveTokenTransfer(transfer)
+				clustered = append(clustered, transfer)
+			}
+			if detector == nil {
+				continue
+			}
+
+			drain, err := detector.CheckTransfer(transfer)
+			if err != nil {
+				logger.Errorf("Failed to check transfer %s:%d for %s: %v", log.TxHash.Hex(), log.Index, connector.name, err)
+				continue
+			}
+			if drain == nil {
+				continue
+			}
+
+			// 只有余额被转空才视为盗取，余额查询失败时仍告警
+			balance, err := connector.tokenBalance(ctx, log.Address, common.HexToAddress(transfer.From), block.Number())
+			if err != nil {
+				logger.Warnf("Failed to get %s balance of %s: %v", transfer.Token, transfer.From, err)
+			} else if balance.Sign() > 0 {
+				continue
+			} else {
+				drain.RemainingBalance = balance
+			}
+
+			alert, err := bc.dataProcessor.ProcessApprovalDrain(drain)
+			if err != nil {
+				logger.Errorf("Failed to publish approval drain alert for %s: %v", transfer.TransactionHash, err)
+				continue
+			}
+			if err := detector.Resolve(drain); err != nil {
+				logger.Errorf("Failed to clear approval of %s to %s: %v", transfer.From, drain.Drainer, err)
+			}
+			alerts = append(alerts, alert)
+		}
+	}
+
+	if flows != nil && len(transfers) > 0 {
+		if err := flows.RecordBlock(transfers); err != nil {
+			logger.Errorf("Failed to record token flows of block %d for %s: %v", block.NumberU64(), connector.name, err)
+			bc.metricsManager.IncrementError(connector.name, "token_flow_error")
+		}
+	}
+	if len(supplyChanges) > 0 {
+		alerts = append(alerts, bc.dataProcessor.ProcessStablecoinSupplyChanges(supplyChanges)...)
+	}
+	if len(clustered) > 0 {
+		bc.linkCoSpending(ctx, connector, clustered, contracts)
+	}
+
+	return alerts
+}
+
+// usdtSupplyChange 将监控的稳定币合约的Issue/Redeem事件转换为供应量变化，账户为交易发起方
+func (bc *BlockchainCollector) usdtSupplyChange(connector *NetworkConnector, log *types.Log, txs map[string]*models.Transaction, blockModel *models.Block) *processor.StablecoinSupplyChange {
+	stablecoins := bc.dataProcessor.Stablecoins()
+	if stablecoins == nil || len(log.Topics) != 1 || len(log.Data) < 32 {
+		return nil
+	}
+	tx, exists := txs[log.TxHash.Hex()]
+	if !exists {
+		return nil
+	}
+
+	change := &processor.StablecoinSupplyChange{
+		ID:              models.TransferID(connector.name, log.BlockHash.Hex(), log.TxIndex, log.Index),
+		Network:         connector.name,
+		Token:           log.Address.Hex(),
+		Kind:            processor.StablecoinMint,
+		Account:         tx.FromAddress,
+		Amount:          dataWord(log.Data, 0),
+		TransactionHash: tx.Hash,
+		BlockNumber:     blockModel.Number,
+		Timestamp:       blockModel.Timestamp,
+	}
+	if log.Topics[0] == usdtRedeemTopic {
+		change.Kind = processor.StablecoinBurn
+	}
+	if change.Amount.Sign() == 0 || !stablecoins.Resolve(change) {
+		return nil
+	}
+	return change
+}
+
+// tokenLogsEnabled 是否有功能需要处理区块内的代币事件
+func (bc *BlockchainCollector) tokenLogsEnabled() bool {
+	return bc.dataProcessor.ApprovalDrains() != nil ||
+		bc.dataProcessor.TokenFlows() != nil ||
+		bc.dataProcessor.Watchlists() != nil ||
+		bc.dataProcessor.Stablecoins() != nil ||
+		bc.dataProcessor.Clusters() != nil
+}
+
+// isContract 判断地址是否部署了合约，结果在区块内缓存
+func (bc *BlockchainCollector) isContract(ctx context.Context, connector *NetworkConnector, address common.Address, cache map[common.Address]bool) bool {
+	if isContract, cached := cache[address]; cached {
+		return isContract
+	}
+
+	code, err := connector.codeAt(ctx, address)
+	if err != nil {
+		// 无法判断时按合约处理，宁可多跟踪
+		logger.Warnf("Failed to get code of %s on %s: %v", address.Hex(), connector.name, err)
+		return true
+	}
+
+	cache[address] = len(code) > 0
+	return cache[address]
+}
+
+// codeAt 获取地址在最新区块的合约代码
+func (nc *NetworkConnector) codeAt(ctx context.Context, address common.Address) ([]byte, error) {
+	if nc.rpcClient == nil {
+		return nil, fmt.Errorf("no RPC client available")
+	}
+
+	nc.recordCall("eth_getCode")
+	return nc.rpcClient.CodeAt(ctx, address, nil)
+}
+
+// tokenBalance 通过balanceOf查询持有人在指定区块的代币余额
+func (nc *NetworkConnector) tokenBalance(ctx context.Context, token, owner common.Address, blockNumber *big.Int) (*big.Int, error) {
+	if nc.rpcClient == nil {
+		return nil, fmt.Errorf("no RPC client available")
+	}
+
+	data := append(append([]byte{}, balanceOfSelector...), common.LeftPadBytes(owner.Bytes(), 32)...)
+
+	nc.recordCall("eth_call")
+	result, err := nc.rpcClient.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) < 32 {
+		return nil, fmt.Errorf("invalid balanceOf result")
+	}
+	return new(big.Int).SetBytes(result[:32]), nil
+}
@@ -0,0 +1,52 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"web3-data-collector/internal/models"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// drainedBalanceRatio 剩余余额不超过窗口内转出金额的该比例（仅余少量gas）时视为已转空
+const drainedBalanceRatio = 100
+
+// processBalanceDrains 查询区块中转出金额达到阈值的地址在该区块后的余额，余额被转空时发送告警
+func (bc *BlockchainCollector) processBalanceDrains(ctx context.Context, connector *NetworkConnector, blockNumber *big.Int) []*models.RiskAlert {
+	velocity := bc.dataProcessor.Velocity()
+
+	var alerts []*models.RiskAlert
+	for _, candidate := range velocity.TakeDrainCandidates(connector.name) {
+		balance, err := connector.balanceAt(ctx, common.HexToAddress(candidate.Address), blockNumber)
+		if err != nil {
+			logger.Warnf("Failed to get balance of %s for %s: %v", candidate.Address, connector.name, err)
+			continue
+		}
+
+		remaining := new(big.Int).Mul(balance, big.NewInt(drainedBalanceRatio))
+		if remaining.Cmp(candidate.Volume) > 0 {
+			continue
+		}
+
+		alert, err := bc.dataProcessor.ProcessBalanceDrain(candidate, balance)
+		if err != nil {
+			logger.Errorf("Failed to publish balance drain alert for %s: %v", candidate.Address, err)
+			continue
+		}
+		alerts = append(alerts, alert)
+	}
+
+	return alerts
+}
+
+// balanceAt 获取地址在指定区块的原生币余额
+func (nc *NetworkConnector) balanceAt(ctx context.Context, address common.Address, blockNumber *big.Int) (*big.Int, error) {
+	if nc.rpcClient == nil {
+		return nil, fmt.Errorf("no RPC client available")
+	}
+
+	nc.recordCall("eth_getBalance")
+	return nc.rpcClient.BalanceAt(ctx, address, blockNumber)
+}
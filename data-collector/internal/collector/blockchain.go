@@ -2,44 +2,121 @@ package collector
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
+	"reflect"
 	"strings"
 	"sync"
 	"time"
 
 	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/faults"
+	"web3-data-collector/internal/logging"
 	"web3-data-collector/internal/metrics"
 	"web3-data-collector/internal/models"
 	"web3-data-collector/internal/processor"
+	"web3-data-collector/internal/sharding"
+	"web3-data-collector/internal/watchdog"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/sirupsen/logrus"
 )
 
+// logger collector模块日志，级别可通过logging.modules.collector单独设置
+var logger = logging.Module("collector")
+
 // BlockchainCollector 区块链数据收集器
 type BlockchainCollector struct {
 	config           config.BlockchainConfig
 	dataProcessor    *processor.DataProcessor
 	metricsManager   *metrics.Manager
 	connectors       map[string]*NetworkConnector
+	disabled         map[string]*disabledNetwork
+	initStatus       map[string]*models.NetworkInitStatus
+	initCancels      map[string]context.CancelFunc
+	autoDisable      autoDisablePolicy
+	logFilterOverrides map[string]config.NetworkLogFilterConfig // 通过管理接口设置的网络日志过滤条件，首次设置时创建
+	watchGroups      *watchGroups
+	logBackfill      *logBackfill
+	rpcCosts         *rpcCostTracker
+	flashLoans       *flashLoanDetector
+	stallPolicy      *stallPolicy
+	continuity       *continuityChecker // 区块连续性检查，未启用时为nil
+	supervisor       *processor.Supervisor
+	memory           *watchdog.MemoryWatchdog
+	shards           *sharding.Coordinator // 网络分片，未启用时为nil
+	adapters         map[string]ChainAdapter // 各链族的适配器
+	assigned         map[string]bool       // 分片模式下分配给本实例的网络
+	cursors          map[string]uint64     // 已停止网络最后处理的区块，重新启动时从此继续
+	goroutines       map[string]int64      // 各类协程的运行数量
+	goroutinesMu     sync.Mutex
+	ctx              context.Context
 	mu               sync.RWMutex
 	stopChan         chan struct{}
+	stopOnce         sync.Once
 	wg               sync.WaitGroup
 }
 
+// 网络协程名称，用于panic指标及隔离记录
+const (
+	goroutineInit             = "init"
+	goroutineMonitor          = "monitor"
+	goroutineHeadSubscription = "head_subscription"
+	goroutineLogSubscription  = "log_subscription"
+	goroutineBackfill         = "log_backfill"
+	goroutineWatchBalances    = "watch_balances"
+)
+
+// 运行模式
+const (
+	runModeHybrid   = "hybrid"
+	runModeRealtime = "realtime"
+	runModeBackfill = "backfill"
+)
+
+// autoDisablePolicy 故障网络自动停用策略
+type autoDisablePolicy struct {
+	enabled              bool
+	maxStartupFailures   int
+	startupRetryInterval time.Duration
+	downTimeout          time.Duration
+}
+
+// disabledNetwork 已停用网络记录
+type disabledNetwork struct {
+	reason     string
+	disabledAt time.Time
+}
+
 // NetworkConnector 网络连接器
 type NetworkConnector struct {
 	name          string
 	config        config.NetworkConfig
 	rpcClient     *ethclient.Client
 	wsClient      *ethclient.Client
+	solana        *solanaClient
+	adapter       ChainAdapter
 	isConnected   bool
 	lastBlock     uint64
+	lastBlockAt   time.Time // 最后处理区块最近一次前进的时间
+	chainHead     uint64
+	headSince     time.Time // 链头最近一次前进的时间
+	stalled       bool
 	errorCount    uint64
+	downSince     time.Time
+	lastHeader    uint64
+	provider      string
+	costs         *rpcCostTracker
+	pacer         *syncPacer // 历史同步的自适应节奏，未启用时为nil
+	logFilter     *logFilter // 合约日志过滤器，创建连接时按生效的过滤条件生成，未启用时为nil
+	subscriptions map[string]*models.SubscriptionHealth
+	cancel        context.CancelFunc
 	mu            sync.RWMutex
 }
 
@@ -48,40 +125,100 @@ func NewBlockchainCollector(
 	config config.BlockchainConfig,
 	dataProcessor *processor.DataProcessor,
 	metricsManager *metrics.Manager,
+	shards *sharding.Coordinator,
 ) *BlockchainCollector {
-	return &BlockchainCollector{
+	// 预计花费超出预算时通过运维告警通知
+	publishCostAlert := func(alert *models.RiskAlert) {
+		if err := dataProcessor.PublishOpsAlert(alert); err != nil {
+			logger.Errorf("Failed to publish RPC budget alert: %v", err)
+		}
+	}
+
+	watchGroups := newWatchGroups(config.WatchGroups)
+
+	bc := &BlockchainCollector{
 		config:         config,
 		dataProcessor:  dataProcessor,
 		metricsManager: metricsManager,
 		connectors:     make(map[string]*NetworkConnector),
+		disabled:       make(map[string]*disabledNetwork),
+		initStatus:     make(map[string]*models.NetworkInitStatus),
+		initCancels:    make(map[string]context.CancelFunc),
+		autoDisable:    newAutoDisablePolicy(config.AutoDisable),
+		watchGroups:    watchGroups,
+		logBackfill:    newLogBackfill(config.LogFilter.Backfill),
+		rpcCosts:       newRPCCostTracker(config.RPCCost, metricsManager, publishCostAlert),
+		flashLoans:     newFlashLoanDetector(config.FlashLoan),
+		stallPolicy:    newStallPolicy(config.StallDetection),
+		continuity:     newContinuityChecker(config.Continuity, metricsManager),
+		supervisor:     dataProcessor.Supervisor(),
+		memory:         dataProcessor.MemoryWatchdog(),
+		shards:         shards,
+		assigned:       make(map[string]bool),
+		cursors:        make(map[string]uint64),
+		goroutines:     make(map[string]int64),
 		stopChan:       make(chan struct{}),
 	}
+	bc.adapters = newChainAdapters(bc)
+	bc.supervisor.RegisterResubmitter(processor.StageBlock, bc.resubmitBlock)
+	bc.supervisor.RegisterResubmitter(processor.StageEvent, bc.resubmitEvent)
+
+	return bc
 }
 
-// Start 启动收集器
-func (bc *BlockchainCollector) Start(ctx context.Context) error {
-	logrus.Info("Starting blockchain collector...")
+// newAutoDisablePolicy 解析自动停用配置，非法值回退为默认值
+func newAutoDisablePolicy(cfg config.AutoDisableConfig) autoDisablePolicy {
+	policy := autoDisablePolicy{
+		enabled:              cfg.Enabled,
+		maxStartupFailures:   cfg.MaxStartupFailures,
+		startupRetryInterval: 10 * time.Second,
+		downTimeout:          10 * time.Minute,
+	}
 
-	// 初始化网络连接器
-	for name, networkConfig := range bc.config.Networks {
-		if !networkConfig.Enabled {
-			logrus.Infof("Network %s is disabled, skipping", name)
-			continue
+	if policy.maxStartupFailures <= 0 {
+		policy.maxStartupFailures = 1
+	}
+
+	if cfg.StartupRetryInterval != "" {
+		if interval, err := time.ParseDuration(cfg.StartupRetryInterval); err == nil {
+			policy.startupRetryInterval = interval
+		} else {
+			logger.Warnf("Invalid startup_retry_interval %q, using %v", cfg.StartupRetryInterval, policy.startupRetryInterval)
 		}
+	}
 
-		connector, err := bc.createNetworkConnector(name, networkConfig)
-		if err != nil {
-			logrus.Errorf("Failed to create connector for %s: %v", name, err)
-			continue
+	if cfg.DownTimeout != "" {
+		if timeout, err := time.ParseDuration(cfg.DownTimeout); err == nil {
+			policy.downTimeout = timeout
+		} else {
+			logger.Warnf("Invalid down_timeout %q, using %v", cfg.DownTimeout, policy.downTimeout)
 		}
+	}
 
-		bc.mu.Lock()
-		bc.connectors[name] = connector
-		bc.mu.Unlock()
+	return policy
+}
 
-		// 启动网络监控
-		bc.wg.Add(1)
-		go bc.monitorNetwork(ctx, connector)
+// Start 启动收集器
+func (bc *BlockchainCollector) Start(ctx context.Context) error {
+	logger.Infof("Starting blockchain collector in %s mode...", bc.runMode())
+
+	bc.mu.Lock()
+	bc.ctx = ctx
+	bc.mu.Unlock()
+
+	// 分片模式下由协调器分配网络
+	if bc.shards != nil {
+		go bc.shards.Run(ctx, bc)
+	} else {
+		// 并行初始化各网络，成功的网络立即启动，失败的在后台重试
+		for name, networkConfig := range bc.config.Networks {
+			if !networkConfig.Enabled {
+				logger.Infof("Network %s is disabled, skipping", name)
+				continue
+			}
+
+			bc.launchNetwork(ctx, name, networkConfig)
+		}
 	}
 
 	// 等待停止信号
@@ -93,119 +230,496 @@ func (bc *BlockchainCollector) Start(ctx context.Context) error {
 	}
 }
 
-// Stop 停止收集器
-func (bc *BlockchainCollector) Stop() {
-	logrus.Info("Stopping blockchain collector...")
-	close(bc.stopChan)
-	bc.wg.Wait()
+// Stop 停止收集器：不再接收新区块，等待处理中的区块完成后关闭连接，ctx结束时放弃等待
+func (bc *BlockchainCollector) Stop(ctx context.Context) error {
+	logger.Info("Stopping blockchain collector, draining in-flight blocks...")
+	bc.stopOnce.Do(func() { close(bc.stopChan) })
+
+	// 不再接收新区块，等待处理中的区块完成（含Kafka事务模式下的检查点提交）
+	drained := make(chan struct{})
+	go func() {
+		bc.wg.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for in-flight blocks: %w", ctx.Err())
+	}
 
 	bc.mu.Lock()
 	defer bc.mu.Unlock()
 
 	for name, connector := range bc.connectors {
 		if err := connector.Close(); err != nil {
-			logrus.Errorf("Error closing connector %s: %v", name, err)
+			logger.Errorf("Error closing connector %s: %v", name, err)
 		}
 	}
 
-	logrus.Info("Blockchain collector stopped")
+	// 网络已停止，释放分片锁，其他实例可立即接管
+	if bc.shards != nil {
+		bc.shards.Release()
+	}
+
+	logger.Info("Blockchain collector stopped")
+	return nil
 }
 
-// createNetworkConnector 创建网络连接器
-func (bc *BlockchainCollector) createNetworkConnector(name string, config config.NetworkConfig) (*NetworkConnector, error) {
-	connector := &NetworkConnector{
-		name:   name,
-		config: config,
+// stopping 判断是否已开始停止，补块循环在区块之间检查以尽快退出
+func (bc *BlockchainCollector) stopping() bool {
+	select {
+	case <-bc.stopChan:
+		return true
+	default:
+		return false
+	}
+}
+
+// launchNetwork 在后台初始化并启动网络，可通过stopNetwork取消
+func (bc *BlockchainCollector) launchNetwork(ctx context.Context, name string, networkConfig config.NetworkConfig) {
+	networkCtx, cancel := context.WithCancel(ctx)
+
+	bc.mu.Lock()
+	bc.initCancels[name] = cancel
+	bc.mu.Unlock()
+
+	bc.updateInitStatus(name, models.NetworkInitPending, 0, nil)
+
+	bc.wg.Add(1)
+	go bc.initializeNetwork(networkCtx, name, networkConfig)
+}
+
+// stopNetwork 停止网络（含仍在初始化重试中的网络）
+func (bc *BlockchainCollector) stopNetwork(name string) {
+	bc.mu.Lock()
+	cancel := bc.initCancels[name]
+	delete(bc.initCancels, name)
+	connector, exists := bc.connectors[name]
+	delete(bc.connectors, name)
+	delete(bc.disabled, name)
+	delete(bc.initStatus, name)
+	if exists {
+		bc.saveCursor(connector)
 	}
+	bc.mu.Unlock()
 
-	// 连接RPC客户端
-	if config.RPCURL != "" {
-		rpcClient, err := ethclient.Dial(config.RPCURL)
-		if err != nil {
-			return nil, fmt.Errorf("failed to connect to RPC: %w", err)
+	if cancel != nil {
+		cancel()
+	}
+	if exists {
+		if connector.cancel != nil {
+			connector.cancel()
+		}
+		if err := connector.Close(); err != nil {
+			logger.Errorf("Error closing connector %s: %v", name, err)
 		}
-		connector.rpcClient = rpcClient
 	}
 
-	// 连接WebSocket客户端
-	if config.WSURL != "" {
-		wsClient, err := ethclient.Dial(config.WSURL)
-		if err != nil {
-			logrus.Warnf("Failed to connect to WebSocket for %s: %v", name, err)
-		} else {
-			connector.wsClient = wsClient
+	bc.metricsManager.SetConnectionStatus(name, "rpc", false)
+	logger.Infof("Network %s stopped", name)
+}
+
+// ApplyNetworks 应用热加载的网络配置：启动新启用的网络，停止被禁用或移除的网络，连接参数变化的网络重新连接
+func (bc *BlockchainCollector) ApplyNetworks(networks map[string]config.NetworkConfig) error {
+	bc.mu.Lock()
+	previous := bc.config.Networks
+	bc.config.Networks = networks
+	ctx := bc.ctx
+	// 配置文件中的过滤条件变化或网络被移除时，运行时设置的过滤条件失效
+	for name := range bc.logFilterOverrides {
+		newConfig, exists := networks[name]
+		if !exists || !reflect.DeepEqual(previous[name].LogFilter, newConfig.LogFilter) {
+			delete(bc.logFilterOverrides, name)
+		}
+	}
+	bc.mu.Unlock()
+
+	if ctx == nil {
+		return fmt.Errorf("collector is not running")
+	}
+
+	// 分片模式下只处理本实例的网络，新增的网络由协调器分配
+	for name, oldConfig := range previous {
+		newConfig, exists := networks[name]
+		if oldConfig.Enabled && bc.ownsNetwork(name) && (!exists || !newConfig.Enabled || connectionChanged(oldConfig, newConfig)) {
+			bc.stopNetwork(name)
+		}
+	}
+
+	for name, newConfig := range networks {
+		oldConfig, existed := previous[name]
+		if newConfig.Enabled && bc.ownsNetwork(name) && (!existed || !oldConfig.Enabled || connectionChanged(oldConfig, newConfig)) {
+			logger.Infof("Starting network %s after config reload", name)
+			bc.launchNetwork(ctx, name, newConfig)
 		}
 	}
 
-	// 验证连接
-	if err := connector.validateConnection(); err != nil {
-		return nil, fmt.Errorf("connection validation failed: %w", err)
+	return nil
+}
+
+// connectionChanged 判断网络连接参数、区块标签或日志过滤条件是否变化，变化时需重新建立网络监控
+func connectionChanged(previous, next config.NetworkConfig) bool {
+	return previous.RPCURL != next.RPCURL || previous.WSURL != next.WSURL || previous.ChainID != next.ChainID ||
+		previous.Chain != next.Chain || previous.Commitment != next.Commitment || previous.RateLimit != next.RateLimit ||
+		previous.BlockTag != next.BlockTag || !reflect.DeepEqual(previous.LogFilter, next.LogFilter)
+}
+
+// restartNetwork 重新建立运行中网络的监控，从最后处理的区块继续；未启用、已停用或不属于本实例的网络不处理
+func (bc *BlockchainCollector) restartNetwork(name string) {
+	bc.mu.RLock()
+	ctx := bc.ctx
+	networkConfig, exists := bc.config.Networks[name]
+	_, isDisabled := bc.disabled[name]
+	bc.mu.RUnlock()
+
+	if ctx == nil || !exists || !networkConfig.Enabled || isDisabled || !bc.ownsNetwork(name) {
+		return
+	}
+
+	bc.stopNetwork(name)
+	logger.Infof("Restarting network %s", name)
+	bc.launchNetwork(ctx, name, networkConfig)
+}
+
+// initializeNetwork 初始化单个网络，失败时在后台持续重试
+func (bc *BlockchainCollector) initializeNetwork(ctx context.Context, name string, networkConfig config.NetworkConfig) {
+	defer bc.wg.Done()
+	defer bc.track(goroutineInit)()
+	defer bc.supervisor.Recover(goroutineInit, name)
+
+	for attempt := 1; ; attempt++ {
+		connector, err := bc.createNetworkConnector(name, networkConfig)
+		if err == nil && ctx.Err() != nil {
+			// 初始化期间网络已被配置重载停止
+			connector.Close()
+			return
+		}
+		if err == nil {
+			bc.updateInitStatus(name, models.NetworkInitReady, attempt, nil)
+			bc.startNetwork(ctx, connector)
+			return
+		}
+
+		logger.Warnf("Failed to initialize network %s (attempt %d): %v", name, attempt, err)
+
+		// 启用自动停用时，连续失败达到上限后停用网络
+		if bc.autoDisable.enabled && attempt >= bc.autoDisable.maxStartupFailures {
+			bc.updateInitStatus(name, models.NetworkInitFailed, attempt, err)
+			bc.disableNetwork(name, fmt.Sprintf("startup validation failed %d times: %v", attempt, err))
+			return
+		}
+
+		bc.updateInitStatus(name, models.NetworkInitRetrying, attempt, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-bc.stopChan:
+			return
+		case <-time.After(bc.autoDisable.startupRetryInterval):
+		}
+	}
+}
+
+// updateInitStatus 更新网络初始化状态
+func (bc *BlockchainCollector) updateInitStatus(name, state string, attempts int, err error) {
+	now := time.Now()
+
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	status, exists := bc.initStatus[name]
+	if !exists {
+		status = &models.NetworkInitStatus{Network: name}
+		bc.initStatus[name] = status
+	}
+
+	status.State = state
+	status.Attempts = attempts
+	status.LastError = ""
+	if err != nil {
+		status.LastError = err.Error()
+	}
+	if attempts > 0 {
+		status.LastAttempt = &now
+	}
+	if state == models.NetworkInitReady {
+		status.ReadyAt = &now
+	}
+}
+
+// GetInitStatus 获取各网络初始化状态
+func (bc *BlockchainCollector) GetInitStatus() map[string]*models.NetworkInitStatus {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+
+	result := make(map[string]*models.NetworkInitStatus, len(bc.initStatus))
+	for name, status := range bc.initStatus {
+		statusCopy := *status
+		result[name] = &statusCopy
+	}
+
+	return result
+}
+
+// startNetwork 注册连接器并启动网络监控
+func (bc *BlockchainCollector) startNetwork(ctx context.Context, connector *NetworkConnector) {
+	networkCtx, cancel := context.WithCancel(logging.WithFields(ctx, logrus.Fields{"network": connector.name}))
+	connector.cancel = cancel
+
+	bc.mu.Lock()
+	bc.connectors[connector.name] = connector
+	bc.mu.Unlock()
+
+	bc.metricsManager.SetConnectionStatus(connector.name, "rpc", true)
+
+	bc.wg.Add(1)
+	go bc.monitorNetwork(networkCtx, connector)
+}
+
+// disableNetwork 停用网络并发送运维告警
+func (bc *BlockchainCollector) disableNetwork(name, reason string) {
+	now := time.Now()
+
+	bc.mu.Lock()
+	connector, exists := bc.connectors[name]
+	delete(bc.connectors, name)
+	bc.disabled[name] = &disabledNetwork{
+		reason:     reason,
+		disabledAt: now,
+	}
+	if exists {
+		bc.saveCursor(connector)
+	}
+	bc.mu.Unlock()
+
+	if exists {
+		if connector.cancel != nil {
+			connector.cancel()
+		}
+		if err := connector.Close(); err != nil {
+			logger.Errorf("Error closing connector %s: %v", name, err)
+		}
+	}
+
+	logger.Errorf("Network %s has been disabled: %s", name, reason)
+	bc.metricsManager.SetConnectionStatus(name, "rpc", false)
+	bc.metricsManager.IncrementError(name, "network_disabled")
+
+	alert := &models.RiskAlert{
+		ID:          models.OpsAlertID("NETWORK_DISABLED", now, name),
+		Type:        "NETWORK_DISABLED",
+		Level:       "HIGH",
+		Title:       fmt.Sprintf("Network %s auto-disabled", name),
+		Description: reason,
+		Network:     name,
+		RiskFactors: []string{"network_unavailable"},
+		Metadata: map[string]interface{}{
+			"disabled_at": now.Unix(),
+		},
+		Timestamp: now,
+		Status:    "ACTIVE",
+	}
+	if err := bc.dataProcessor.PublishOpsAlert(alert); err != nil {
+		logger.Errorf("Failed to publish ops alert for %s: %v", name, err)
+	}
+}
+
+// EnableNetwork 重新启用被停用的网络
+func (bc *BlockchainCollector) EnableNetwork(name string) error {
+	bc.mu.RLock()
+	_, isDisabled := bc.disabled[name]
+	networkConfig, exists := bc.config.Networks[name]
+	ctx := bc.ctx
+	bc.mu.RUnlock()
+
+	if !isDisabled {
+		return fmt.Errorf("network %s is not disabled", name)
+	}
+	if ctx == nil {
+		return fmt.Errorf("collector is not running")
+	}
+
+	if !exists {
+		return fmt.Errorf("network %s is not configured", name)
+	}
+
+	connector, err := bc.createNetworkConnector(name, networkConfig)
+	if err != nil {
+		return fmt.Errorf("failed to reconnect network %s: %w", name, err)
+	}
+
+	bc.mu.Lock()
+	delete(bc.disabled, name)
+	bc.mu.Unlock()
+
+	bc.updateInitStatus(name, models.NetworkInitReady, 1, nil)
+	bc.startNetwork(ctx, connector)
+	logger.Infof("Network %s re-enabled", name)
+
+	return nil
+}
+
+// saveCursor 保存停止的网络最后处理的区块，调用方需持有锁
+func (bc *BlockchainCollector) saveCursor(connector *NetworkConnector) {
+	if lastBlock := connector.getLastBlock(); lastBlock > 0 {
+		bc.cursors[connector.name] = lastBlock
+	}
+}
+
+// createNetworkConnector 创建网络连接器，由所属链族的适配器连接节点
+func (bc *BlockchainCollector) createNetworkConnector(name string, config config.NetworkConfig) (*NetworkConnector, error) {
+	adapter, err := bc.chainAdapter(config.Chain)
+	if err != nil {
+		return nil, err
+	}
+
+	connector := &NetworkConnector{
+		name:      name,
+		config:    config,
+		adapter:   adapter,
+		provider:  rpcProviderName(config),
+		costs:     bc.rpcCosts,
+		logFilter: bc.networkLogFilter(name, config.LogFilter),
+	}
+	if err := adapter.Connect(connector); err != nil {
+		return nil, err
 	}
 
 	connector.isConnected = true
-	logrus.Infof("Successfully connected to network: %s", name)
+	logger.Infof("Successfully connected to network: %s", name)
 
 	return connector, nil
 }
 
-// monitorNetwork 监控单个网络
+// runMode 获取运行模式，未配置时为hybrid
+func (bc *BlockchainCollector) runMode() string {
+	if bc.config.RunMode == "" {
+		return runModeHybrid
+	}
+	return bc.config.RunMode
+}
+
+// monitorNetwork 监控单个网络：新区块订阅触发处理，定期轮询作为备用
 func (bc *BlockchainCollector) monitorNetwork(ctx context.Context, connector *NetworkConnector) {
 	defer bc.wg.Done()
+	defer bc.track(goroutineMonitor)()
+	defer bc.supervisor.Recover(goroutineMonitor, connector.name)
 
-	logrus.Infof("Starting monitoring for network: %s", connector.name)
+	logger.Infof("Starting monitoring for network: %s", connector.name)
 
 	// 获取当前最新区块号
-	latestBlock, err := connector.getLatestBlockNumber(ctx)
+	latestBlock, err := connector.adapter.LatestBlock(ctx, connector)
 	if err != nil {
-		logrus.Errorf("Failed to get latest block for %s: %v", connector.name, err)
+		bc.reportError(err, connector.name, 0, "Failed to get latest block")
 		return
 	}
 
-	connector.setLastBlock(latestBlock)
-	logrus.Infof("Starting from block %d for network %s", latestBlock, connector.name)
+	// 只处理safe/finalized区块时从对应标签的区块开始
+	startBlock, err := connector.getTargetBlockNumber(ctx, latestBlock)
+	if err != nil {
+		bc.reportError(err, connector.name, 0, "Failed to get start block")
+		return
+	}
+
+	bc.mu.Lock()
+	cursor := bc.cursors[connector.name]
+	delete(bc.cursors, connector.name)
+	bc.mu.Unlock()
+
+	connector.setLastBlock(connector.startBlock(startBlock, cursor))
+	connector.setChainHead(latestBlock)
+	if bc.continuity != nil {
+		bc.continuity.reset(connector.name)
+	}
+	bc.updateBlockLag(connector)
+	if connector.config.StartBlock > 0 && cursor > 0 {
+		logger.Infof("Resuming network %s after block %d", connector.name, cursor)
+	} else if connector.config.StartBlock > 0 {
+		logger.Infof("Starting from configured block %d for network %s (%s block %d)", connector.config.StartBlock, connector.name, connector.finality(), startBlock)
+	} else {
+		logger.Infof("Starting from %s block %d for network %s", connector.finality(), startBlock, connector.name)
+	}
+
+	// 仅回填模式下不订阅也不轮询：先回填处理起点之前的历史日志，再从处理起点逐块处理到启动时的链头后结束
+	services, hasServices := connector.adapter.(networkServices)
+	if bc.runMode() == runModeBackfill {
+		if hasServices {
+			services.Backfill(ctx, connector, connector.getLastBlock())
+		}
+		bc.backfillBlocks(ctx, connector, startBlock)
+		logger.Infof("Backfill finished for %s, monitoring stopped", connector.name)
+		return
+	}
+
+	if hasServices {
+		services.StartServices(ctx, connector, connector.getLastBlock())
+	}
 
 	// 启动实时监控
-	if connector.wsClient != nil {
+	heads := make(chan uint64, 1)
+	if connector.realtime() {
 		bc.wg.Add(1)
-		go bc.subscribeToNewBlocks(ctx, connector)
+		go bc.subscribeHeads(ctx, connector, heads)
 	}
 
 	// 启动定期轮询作为备用
-	ticker := time.NewTicker(5 * time.Second)
+	ticker := time.NewTicker(connector.pollInterval())
 	defer ticker.Stop()
 
 	for {
+		var head uint64
 		select {
 		case <-ctx.Done():
 			return
 		case <-bc.stopChan:
 			return
 		case <-ticker.C:
-			if err := bc.pollLatestBlocks(ctx, connector); err != nil {
-				logrus.Errorf("Error polling latest blocks for %s: %v", connector.name, err)
-				bc.metricsManager.IncrementError(connector.name, "polling_error")
+		case head = <-heads:
+		}
+
+		if err := bc.pollLatestBlocks(ctx, connector, head); err != nil {
+			bc.reportError(err, connector.name, 0, "Error polling latest blocks")
+			if bc.handlePollError(connector, err) {
+				return
 			}
+			continue
 		}
+		connector.markUp()
 	}
 }
 
-// subscribeToNewBlocks 订阅新区块
-func (bc *BlockchainCollector) subscribeToNewBlocks(ctx context.Context, connector *NetworkConnector) {
-	defer bc.wg.Done()
+// handlePollError 记录轮询失败，持续不可用超过阈值时自动停用网络并返回true
+func (bc *BlockchainCollector) handlePollError(connector *NetworkConnector, err error) bool {
+	bc.metricsManager.IncrementError(connector.name, "polling_error")
 
-	if connector.wsClient == nil {
-		return
+	downFor := connector.markDown()
+	if bc.autoDisable.enabled && bc.autoDisable.downTimeout > 0 && downFor >= bc.autoDisable.downTimeout {
+		go bc.disableNetwork(connector.name, fmt.Sprintf("network down for %v: %v", downFor.Round(time.Second), err))
+		return true
 	}
+	return false
+}
 
-	logrus.Infof("Subscribing to new blocks for network: %s", connector.name)
+// subscribeToLogs 订阅符合过滤条件的合约日志
+func (bc *BlockchainCollector) subscribeToLogs(ctx context.Context, connector *NetworkConnector) {
+	defer bc.wg.Done()
+	defer bc.track(goroutineLogSubscription)()
+	defer bc.supervisor.Recover(goroutineLogSubscription, connector.name)
+
+	logger.Infof("Subscribing to filtered logs for network: %s", connector.name)
 
-	headers := make(chan *types.Header)
-	sub, err := connector.wsClient.SubscribeNewHead(ctx, headers)
+	logs := make(chan types.Log)
+	connector.recordCall("eth_subscribe")
+	sub, err := connector.wsClient.SubscribeFilterLogs(ctx, connector.logFilter.query(), logs)
 	if err != nil {
-		logrus.Errorf("Failed to subscribe to new heads for %s: %v", connector.name, err)
+		bc.reportError(&faults.RPCError{Network: connector.name, Method: "eth_subscribe", Err: err}, connector.name, 0, "Failed to subscribe to logs")
+		connector.subscriptionEnded(subscriptionLogs, err)
 		return
 	}
 	defer sub.Unsubscribe()
+	connector.subscriptionStarted(subscriptionLogs)
+	defer connector.subscriptionEnded(subscriptionLogs, nil)
 
 	for {
 		select {
@@ -214,82 +728,382 @@ func (bc *BlockchainCollector) subscribeToNewBlocks(ctx context.Context, connect
 		case <-bc.stopChan:
 			return
 		case err := <-sub.Err():
-			logrus.Errorf("WebSocket subscription error for %s: %v", connector.name, err)
+			bc.reportError(&faults.RPCError{Network: connector.name, Method: "eth_subscribe", Err: err}, connector.name, 0, "Logs subscription error")
 			bc.metricsManager.IncrementError(connector.name, "websocket_error")
+			connector.subscriptionEnded(subscriptionLogs, err)
 			return
-		case header := <-headers:
-			if header != nil {
-				bc.processNewBlock(ctx, connector, header.Number.Uint64())
+		case log := <-logs:
+			connector.subscriptionMessage(subscriptionLogs)
+			// 订阅条件为各组条件的并集，需再次过滤
+			if !connector.logFilter.matches(&log) {
+				continue
+			}
+			// 与回执路径发布的事件由去重窗口合并
+			if _, err := bc.processEvent(connector, &log, time.Now()); err != nil {
+				bc.reportError(err, connector.name, log.BlockNumber, fmt.Sprintf("Failed to process event %s:%d", log.TxHash.Hex(), log.Index))
 			}
 		}
 	}
 }
 
-// pollLatestBlocks 轮询最新区块
-func (bc *BlockchainCollector) pollLatestBlocks(ctx context.Context, connector *NetworkConnector) error {
-	latestBlock, err := connector.getLatestBlockNumber(ctx)
+// pollLatestBlocks 处理上次处理之后到可处理的最新区块之间的区块，head为订阅通知的链头区块号，为0时向节点查询
+func (bc *BlockchainCollector) pollLatestBlocks(ctx context.Context, connector *NetworkConnector, head uint64) error {
+	latestBlock := head
+	if latestBlock == 0 || latestBlock < connector.getChainHead() {
+		var err error
+		latestBlock, err = connector.adapter.LatestBlock(ctx, connector)
+		if err != nil {
+			return err
+		}
+	}
+
+	connector.setChainHead(latestBlock)
+	bc.checkChainStall(ctx, connector)
+	lastProcessed := connector.getLastBlock()
+
+	// 只处理safe/finalized区块时，链头仍按最新区块统计，区块落后包含确认所需的区块数
+	targetBlock, err := connector.getTargetBlockNumber(ctx, latestBlock)
 	if err != nil {
 		return err
 	}
 
-	lastProcessed := connector.getLastBlock()
-	
-	// 处理遗漏的区块
-	for blockNum := lastProcessed + 1; blockNum <= latestBlock; blockNum++ {
-		if err := bc.processNewBlock(ctx, connector, blockNum); err != nil {
-			logrus.Errorf("Error processing block %d for %s: %v", blockNum, connector.name, err)
-			continue
+	bc.processPending(ctx, connector, lastProcessed, targetBlock)
+	return nil
+}
+
+// backfillBlocks 仅回填模式下从上次处理的区块之后逐批处理到target，与轮询使用相同的处理路径
+func (bc *BlockchainCollector) backfillBlocks(ctx context.Context, connector *NetworkConnector, target uint64) {
+	if connector.getLastBlock() >= target {
+		return
+	}
+	logger.Infof("Starting block backfill for %s from block %d to %d", connector.name, connector.getLastBlock()+1, target)
+
+	for lastProcessed := connector.getLastBlock(); lastProcessed < target; lastProcessed = connector.getLastBlock() {
+		bc.processPending(ctx, connector, lastProcessed, target)
+
+		// 未能推进（区块处理失败或正在停止）时等待一个轮询间隔再重试
+		if connector.getLastBlock() == lastProcessed {
+			select {
+			case <-ctx.Done():
+				return
+			case <-bc.stopChan:
+				return
+			case <-time.After(connector.pollInterval()):
+			}
+		}
+	}
+
+	logger.Infof("Block backfill completed for %s up to block %d", connector.name, target)
+}
+
+// processPending 处理lastProcessed之后到targetBlock之间的区块
+func (bc *BlockchainCollector) processPending(ctx context.Context, connector *NetworkConnector, lastProcessed, targetBlock uint64) {
+	// 先重新获取连续性检查发现的遗漏区块
+	if bc.continuity != nil {
+		bc.refetchMissing(ctx, connector)
+	}
+
+	// 处理遗漏的区块，单次最多处理max_batch_size个，其余留到下次轮询
+	batchEnd := connector.batchEnd(lastProcessed, targetBlock)
+	for blockNum := lastProcessed + 1; blockNum <= batchEnd && !bc.stopping(); blockNum++ {
+		// 落后较多时按节点状态自适应控制补处理速度
+		if !bc.paceSync(ctx, connector, targetBlock-blockNum) {
+			break
+		}
+		if err := bc.processBlockSupervised(ctx, connector, blockNum); err != nil {
+			connector.reportRateLimit(err)
+			bc.reportError(err, connector.name, blockNum, "Error processing block")
+			// 已隔离的区块不再重试，避免阻塞后续区块；其余失败的区块由连续性检查排队重新获取
+			if !processor.IsQuarantined(err) {
+				continue
+			}
+			if bc.continuity != nil {
+				bc.continuity.skip(connector.name, blockNum, false)
+			}
 		}
 		connector.setLastBlock(blockNum)
 	}
 
-	return nil
+	bc.updateBlockLag(connector)
+}
+
+// updateBlockLag 根据链头与最后处理区块更新落后指标
+func (bc *BlockchainCollector) updateBlockLag(connector *NetworkConnector) {
+	lastBlock := connector.getLastBlock()
+	bc.metricsManager.SetCurrentBlockNumber(connector.name, lastBlock)
+	bc.metricsManager.SetBlockLag(connector.name, connector.getChainHead(), lastBlock)
+}
+
+// processBlockSupervised 处理新区块，处理过程panic或反复失败时隔离该区块号并返回错误
+func (bc *BlockchainCollector) processBlockSupervised(ctx context.Context, connector *NetworkConnector, blockNumber uint64) error {
+	payload := map[string]interface{}{"block_number": blockNumber}
+	return bc.supervisor.Guard(processor.StageBlock, connector.name, fmt.Sprint(blockNumber), payload, func() error {
+		return bc.processBlock(ctx, connector, blockNumber, false)
+	})
 }
 
-// processNewBlock 处理新区块
-func (bc *BlockchainCollector) processNewBlock(ctx context.Context, connector *NetworkConnector, blockNumber uint64) error {
+// processEvent 转换并处理日志事件，解码或处理过程panic、反复失败时隔离原始日志
+func (bc *BlockchainCollector) processEvent(connector *NetworkConnector, log *types.Log, timestamp time.Time) (*models.Event, error) {
+	var event *models.Event
+	key := fmt.Sprintf("%s:%d", log.TxHash.Hex(), log.Index)
+	err := bc.supervisor.Attempt(processor.StageEvent, connector.name, key, log, func() error {
+		event = bc.convertToEventModel(log, timestamp, connector.name)
+		event.Finality = connector.finality()
+		return bc.publishEvent(connector.name, log, event)
+	})
+	return event, err
+}
+
+// publishEvent 发布事件，关注组匹配的事件解码后按规则告警
+func (bc *BlockchainCollector) publishEvent(network string, log *types.Log, event *models.Event) error {
+	if bc.watchGroups != nil {
+		if rule := bc.watchGroups.match(network, log); rule != nil {
+			return bc.watchEvent(rule, log, event)
+		}
+	}
+	return bc.dataProcessor.ProcessEvent(event)
+}
+
+// resubmitBlock 重新处理被隔离的区块（早期隔离的Solana区块记录为slot）
+func (bc *BlockchainCollector) resubmitBlock(network string, payload json.RawMessage) error {
+	var target struct {
+		BlockNumber *uint64 `json:"block_number"`
+		Slot        *uint64 `json:"slot"`
+	}
+	if err := json.Unmarshal(payload, &target); err != nil {
+		return &faults.DecodeError{Network: network, Kind: "payload", Ref: "block", Err: err}
+	}
+
+	bc.mu.RLock()
+	ctx := bc.ctx
+	connector, exists := bc.connectors[network]
+	bc.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("network %s is not running", network)
+	}
+
+	switch {
+	case target.Slot != nil:
+		return bc.processBlock(ctx, connector, *target.Slot, true)
+	case target.BlockNumber != nil:
+		return bc.processBlock(ctx, connector, *target.BlockNumber, true)
+	default:
+		return fmt.Errorf("block payload has no block number")
+	}
+}
+
+// resubmitEvent 重新处理被隔离的日志事件
+func (bc *BlockchainCollector) resubmitEvent(network string, payload json.RawMessage) error {
+	var log types.Log
+	if err := json.Unmarshal(payload, &log); err != nil {
+		return &faults.DecodeError{Network: network, Kind: "payload", Ref: "log", Err: err}
+	}
+
+	event := bc.convertToEventModel(&log, time.Now(), network)
+	bc.mu.RLock()
+	if connector, exists := bc.connectors[network]; exists {
+		event.Finality = connector.finality()
+	}
+	bc.mu.RUnlock()
+	return bc.publishEvent(network, &log, event)
+}
+
+// processBlock 获取并处理单个区块，区块不存在（如被跳过的slot）时直接返回。
+// resubmit为从隔离区重新处理的旧区块，不计入连续性检查，避免被记为乱序或再次触发重新获取
+func (bc *BlockchainCollector) processBlock(ctx context.Context, connector *NetworkConnector, blockNumber uint64, resubmit bool) error {
 	startTime := time.Now()
+	trackContinuity := bc.continuity != nil && !resubmit
+	ctx = logging.WithFields(ctx, logrus.Fields{"network": connector.name, "block": blockNumber})
 
 	// 获取区块详细信息
-	block, err := connector.getBlockByNumber(ctx, blockNumber)
+	block, err := connector.adapter.FetchBlock(ctx, connector, blockNumber)
+	bc.recordStage(connector.name, processor.PipelineStageFetch, startTime, err)
 	if err != nil {
 		return fmt.Errorf("failed to get block %d: %w", blockNumber, err)
 	}
+	if block == nil {
+		if trackContinuity {
+			bc.continuity.skip(connector.name, blockNumber, true)
+		}
+		return nil
+	}
+
+	// 仅关注模式下筛选交易，筛选失败时解码全部交易，避免漏掉关注的交易
+	if selector, ok := connector.adapter.(transactionSelector); ok && bc.config.WatchlistOnly {
+		if err := selector.SelectTransactions(ctx, connector, block); err != nil {
+			logger.WithContext(ctx).Warnf("Failed to prefilter block %d for %s, decoding all transactions: %v", blockNumber, connector.name, err)
+			bc.metricsManager.IncrementError(connector.name, "prefilter_error")
+			block.Selected = nil
+			block.SkipLogs = false
+		}
+	}
 
 	// 转换为内部模型
-	blockModel := bc.convertToBlockModel(block, connector.name)
+	if err := bc.decodeTransactions(connector, block); err != nil {
+		return err
+	}
+	blockModel := block.Model
+	blockModel.ReceivedAt = startTime
+	labelFinality(blockModel, connector.finality())
+
+	// 轮询发现的区块在此推送摘要（已通过订阅推送的会被跳过）
+	bc.publishHeader(connector, blockSummary(blockModel, startTime))
+
+	// 依赖回执的统计在获取失败时跳过该区块
+	stageStart := time.Now()
+	if err := connector.adapter.FetchReceipts(ctx, connector, block); err != nil {
+		bc.recordStage(connector.name, processor.PipelineStageReceipts, stageStart, err)
+		logger.WithContext(ctx).Warnf("Failed to get receipts of block %d for %s: %v", blockNumber, connector.name, err)
+		bc.metricsManager.IncrementError(connector.name, "receipt_error")
+		block.Receipts = false
+	} else if block.Receipts {
+		bc.recordStage(connector.name, processor.PipelineStageReceipts, stageStart, nil)
+	}
 
 	// 处理区块数据
-	if err := bc.dataProcessor.ProcessBlock(blockModel); err != nil {
-		logrus.Errorf("Failed to process block %d: %v", blockNumber, err)
+	stageStart = time.Now()
+	enriched, err := bc.dataProcessor.ProcessBlock(ctx, blockModel)
+	bc.recordStage(connector.name, processor.PipelineStageProcess, stageStart, err)
+	if err != nil {
+		faults.Log(err, connector.name, blockNumber, "Failed to process block")
 		return err
 	}
+	if trackContinuity {
+		bc.continuity.observe(connector.name, blockModel)
+	}
+
+	// 链族专属的处理阶段
+	if enricher, ok := connector.adapter.(blockEnricher); ok {
+		enricher.EnrichBlock(ctx, connector, block, enriched)
+	}
+
+	// 推送完整区块
+	enriched.ObservedAt = startTime
+	enriched.ProcessedAt = time.Now()
+	enriched.LatencyMs = enriched.ProcessedAt.Sub(startTime).Milliseconds()
+	stageStart = time.Now()
+	err = bc.dataProcessor.PublishEnrichedBlock(enriched)
+	bc.recordStage(connector.name, processor.PipelineStagePublish, stageStart, err)
+	if err != nil {
+		faults.Log(err, connector.name, blockNumber, "Failed to publish enriched block")
+	}
 
 	// 更新指标
 	processingTime := time.Since(startTime)
 	bc.metricsManager.RecordBlockProcessingTime(connector.name, processingTime)
 	bc.metricsManager.IncrementBlocksProcessed(connector.name)
 
-	logrus.Debugf("Processed block %d for %s in %v", blockNumber, connector.name, processingTime)
+	logger.WithContext(ctx).Debugf("Processed block %d for %s in %v", blockNumber, connector.name, processingTime)
 
 	return nil
 }
 
-// convertToBlockModel 转换区块为内部模型
+// shedding 判断是否已达到指定的内存降载级别
+func (bc *BlockchainCollector) shedding(level int) bool {
+	return bc.memory != nil && bc.memory.Shedding(level)
+}
+
+// processFilteredLogs 获取区块回执并处理符合过滤条件的日志，返回已处理的事件。receipts为已获取的区块全部回执，为nil时获取
+func (bc *BlockchainCollector) processFilteredLogs(ctx context.Context, connector *NetworkConnector, block *types.Block, receipts []*types.Receipt) ([]*models.Event, error) {
+	// 区块logsBloom不可能包含关注的地址/事件时，跳过回执获取
+	if !connector.logFilter.mayContain(block.Bloom()) {
+		bc.metricsManager.RecordLogFilterBlock(connector.name, true)
+		return nil, nil
+	}
+	bc.metricsManager.RecordLogFilterBlock(connector.name, false)
+
+	timestamp := time.Unix(int64(block.Time()), 0)
+
+	if receipts == nil {
+		hashes := make([]common.Hash, 0, len(block.Transactions()))
+		for _, tx := range block.Transactions() {
+			hashes = append(hashes, tx.Hash())
+		}
+		var err error
+		receipts, err = connector.receipts(ctx, block.NumberU64(), hashes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var events []*models.Event
+	for _, receipt := range receipts {
+		// 单笔交易的回执bloom同样可用于快速排除
+		if !connector.logFilter.mayContain(receipt.Bloom) {
+			continue
+		}
+
+		for _, log := range receipt.Logs {
+			if !connector.logFilter.matches(log) {
+				continue
+			}
+
+			event, err := bc.processEvent(connector, log, timestamp)
+			if err != nil {
+				bc.reportError(err, connector.name, log.BlockNumber, fmt.Sprintf("Failed to process event %s:%d", log.TxHash.Hex(), log.Index))
+				continue
+			}
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+// publishHeader 推送区块头摘要，每个区块号只推送一次
+func (bc *BlockchainCollector) publishHeader(connector *NetworkConnector, summary *models.BlockHeader) {
+	if !connector.markHeaderPublished(summary.Number) {
+		return
+	}
+
+	if err := bc.dataProcessor.PublishHeader(summary); err != nil {
+		faults.Log(err, connector.name, summary.Number, "Failed to publish header")
+	}
+}
+
+// convertToEventModel 转换日志为内部事件模型
+func (bc *BlockchainCollector) convertToEventModel(log *types.Log, timestamp time.Time, network string) *models.Event {
+	topics := make([]string, 0, len(log.Topics))
+	for _, topic := range log.Topics {
+		topics = append(topics, topic.Hex())
+	}
+
+	event := &models.Event{
+		ID:               models.EventID(network, log.BlockHash.Hex(), log.TxIndex, log.Index),
+		TransactionHash:  log.TxHash.Hex(),
+		BlockNumber:      log.BlockNumber,
+		BlockHash:        log.BlockHash.Hex(),
+		TransactionIndex: log.TxIndex,
+		LogIndex:         log.Index,
+		ContractAddress:  log.Address.Hex(),
+		Topics:           topics,
+		Data:             fmt.Sprintf("0x%x", log.Data),
+		Timestamp:        timestamp,
+		Network:          network,
+		Removed:          log.Removed,
+	}
+
+	if len(topics) > 0 {
+		event.EventSignature = topics[0]
+	}
+
+	return event
+}
+
+// convertToBlockModel 转换区块为内部模型，交易由DecodeTx逐笔解码
 func (bc *BlockchainCollector) convertToBlockModel(block *types.Block, network string) *models.Block {
 	blockModel := &models.Block{
-		Number:       block.NumberU64(),
-		Hash:         block.Hash().Hex(),
-		ParentHash:   block.ParentHash().Hex(),
-		Timestamp:    time.Unix(int64(block.Time()), 0),
-		Difficulty:   block.Difficulty(),
-		GasLimit:     block.GasLimit(),
-		GasUsed:      block.GasUsed(),
-		Miner:        block.Coinbase().Hex(),
-		Network:      network,
-		Transactions: make([]models.Transaction, 0, len(block.Transactions())),
-		TxCount:      len(block.Transactions()),
-		Size:         block.Size(),
+		ID:         models.BlockID(network, block.Hash().Hex()),
+		Number:     block.NumberU64(),
+		Hash:       block.Hash().Hex(),
+		ParentHash: block.ParentHash().Hex(),
+		Timestamp:  time.Unix(int64(block.Time()), 0),
+		Difficulty: block.Difficulty(),
+		GasLimit:   block.GasLimit(),
+		GasUsed:    block.GasUsed(),
+		Miner:      block.Coinbase().Hex(),
+		Network:    network,
+		Size:       block.Size(),
 	}
 
 	// 处理EIP-1559
@@ -297,10 +1111,19 @@ func (bc *BlockchainCollector) convertToBlockModel(block *types.Block, network s
 		blockModel.BaseFeePerGas = block.BaseFee()
 	}
 
-	// 转换交易
-	for i, tx := range block.Transactions() {
-		txModel := bc.convertToTransactionModel(tx, block, uint(i), network)
-		blockModel.Transactions = append(blockModel.Transactions, *txModel)
+	// 上海升级后的信标链提款，金额单位为Gwei
+	for _, w := range block.Withdrawals() {
+		blockModel.Withdrawals = append(blockModel.Withdrawals, models.Withdrawal{
+			ID:             models.WithdrawalID(network, blockModel.Hash, w.Index),
+			Index:          w.Index,
+			ValidatorIndex: w.Validator,
+			Address:        w.Address.Hex(),
+			Amount:         new(big.Int).Mul(new(big.Int).SetUint64(w.Amount), big.NewInt(params.GWei)),
+			BlockNumber:    blockModel.Number,
+			BlockHash:      blockModel.Hash,
+			Timestamp:      blockModel.Timestamp,
+			Network:        network,
+		})
 	}
 
 	return blockModel
@@ -323,6 +1146,7 @@ func (bc *BlockchainCollector) convertToTransactionModel(
 	fromAddress, _ := types.Sender(signer, tx)
 
 	txModel := &models.Transaction{
+		ID:               models.TransactionID(network, block.Hash().Hex(), txIndex),
 		Hash:             tx.Hash().Hex(),
 		BlockNumber:      block.NumberU64(),
 		BlockHash:        block.Hash().Hex(),
@@ -373,6 +1197,40 @@ func (bc *BlockchainCollector) isTokenTransfer(tx *types.Transaction) bool {
 	return strings.EqualFold(inputData, transferMethodID)
 }
 
+// GetRPCCosts 获取当日RPC调用成本统计
+func (bc *BlockchainCollector) GetRPCCosts() (*models.RPCCostReport, error) {
+	if bc.rpcCosts == nil {
+		return nil, fmt.Errorf("rpc cost accounting is not enabled")
+	}
+	return bc.rpcCosts.Report(), nil
+}
+
+// GetTransactionLogs 从节点获取交易回执中的日志，timestamp为所在区块时间；交易不存在时返回nil
+func (bc *BlockchainCollector) GetTransactionLogs(ctx context.Context, network, hash string, timestamp time.Time) ([]*models.Event, error) {
+	bc.mu.RLock()
+	connector, exists := bc.connectors[network]
+	bc.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("network %s is not running", network)
+	}
+
+	receipt, err := connector.getTransactionReceipt(ctx, common.HexToHash(hash))
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*models.Event, 0, len(receipt.Logs))
+	for _, log := range receipt.Logs {
+		events = append(events, bc.convertToEventModel(log, timestamp, network))
+	}
+
+	return events, nil
+}
+
 // GetNetworkStats 获取网络统计信息
 func (bc *BlockchainCollector) GetNetworkStats() map[string]*models.NetworkStats {
 	bc.mu.RLock()
@@ -384,9 +1242,26 @@ func (bc *BlockchainCollector) GetNetworkStats() map[string]*models.NetworkStats
 		stats[name] = &models.NetworkStats{
 			Network:        name,
 			LatestBlock:    connector.getLastBlock(),
+			ChainHead:      connector.getChainHead(),
+			BlockLag:       connector.getBlockLag(),
 			IsHealthy:      connector.isConnected,
 			ErrorCount:     connector.getErrorCount(),
 			LastUpdateTime: time.Now(),
+			Status:         models.NetworkStatusActive,
+		}
+		if lastBlockAt := connector.getLastBlockAt(); !lastBlockAt.IsZero() {
+			stats[name].LastProcessedAt = &lastBlockAt
+		}
+	}
+
+	for name, disabled := range bc.disabled {
+		disabledAt := disabled.disabledAt
+		stats[name] = &models.NetworkStats{
+			Network:        name,
+			LastUpdateTime: time.Now(),
+			Status:         models.NetworkStatusDisabled,
+			DisabledReason: disabled.reason,
+			DisabledAt:     &disabledAt,
 		}
 	}
 
@@ -404,38 +1279,123 @@ func (nc *NetworkConnector) validateConnection() error {
 	defer cancel()
 
 	// 测试连接
+	nc.recordCall("eth_chainId")
 	_, err := nc.rpcClient.ChainID(ctx)
 	return err
 }
 
+// recordCall 记录RPC调用用于成本核算
+func (nc *NetworkConnector) recordCall(method string) {
+	if nc.costs != nil {
+		nc.costs.Record(nc.name, nc.provider, method)
+	}
+}
+
 func (nc *NetworkConnector) getLatestBlockNumber(ctx context.Context) (uint64, error) {
 	if nc.rpcClient == nil {
 		return 0, fmt.Errorf("no RPC client available")
 	}
 
-	return nc.rpcClient.BlockNumber(ctx)
+	nc.recordCall("eth_blockNumber")
+	number, err := nc.rpcClient.BlockNumber(ctx)
+	return number, nc.rpcError("eth_blockNumber", 0, err)
+}
+
+// getBlockByNumber 获取区块，L2网络另外返回go-ethereum无法解码的存款交易及系统交易
+func (nc *NetworkConnector) getBlockByNumber(ctx context.Context, number uint64) (*types.Block, []*l2Transaction, error) {
+	if nc.rpcClient == nil {
+		return nil, nil, fmt.Errorf("no RPC client available")
+	}
+	if l2Type(nc.config) != "" {
+		return nc.getL2Block(ctx, number)
+	}
+
+	nc.recordCall("eth_getBlockByNumber")
+	block, err := nc.rpcClient.BlockByNumber(ctx, big.NewInt(int64(number)))
+	return block, nil, nc.rpcError("eth_getBlockByNumber", number, err)
 }
 
-func (nc *NetworkConnector) getBlockByNumber(ctx context.Context, number uint64) (*types.Block, error) {
+func (nc *NetworkConnector) getTransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
 	if nc.rpcClient == nil {
 		return nil, fmt.Errorf("no RPC client available")
 	}
 
-	return nc.rpcClient.BlockByNumber(ctx, big.NewInt(int64(number)))
+	nc.recordCall("eth_getTransactionReceipt")
+	receipt, err := nc.rpcClient.TransactionReceipt(ctx, txHash)
+	return receipt, nc.rpcError("eth_getTransactionReceipt", 0, err)
+}
+
+func (nc *NetworkConnector) getHeaderByNumber(ctx context.Context, number uint64) (*types.Header, error) {
+	if nc.rpcClient == nil {
+		return nil, fmt.Errorf("no RPC client available")
+	}
+
+	nc.recordCall("eth_getBlockByNumber")
+	header, err := nc.rpcClient.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	return header, nc.rpcError("eth_getBlockByNumber", number, err)
+}
+
+func (nc *NetworkConnector) filterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	if nc.rpcClient == nil {
+		return nil, fmt.Errorf("no RPC client available")
+	}
+
+	nc.recordCall("eth_getLogs")
+	logs, err := nc.rpcClient.FilterLogs(ctx, query)
+	var block uint64
+	if query.FromBlock != nil {
+		block = query.FromBlock.Uint64()
+	}
+	return logs, nc.rpcError("eth_getLogs", block, err)
 }
 
 func (nc *NetworkConnector) setLastBlock(blockNumber uint64) {
 	nc.mu.Lock()
 	defer nc.mu.Unlock()
+	if blockNumber != nc.lastBlock || nc.lastBlockAt.IsZero() {
+		nc.lastBlockAt = time.Now()
+	}
 	nc.lastBlock = blockNumber
 }
 
+// getLastBlockAt 最后处理区块最近一次前进的时间，尚未开始处理时为零值
+func (nc *NetworkConnector) getLastBlockAt() time.Time {
+	nc.mu.RLock()
+	defer nc.mu.RUnlock()
+	return nc.lastBlockAt
+}
+
 func (nc *NetworkConnector) getLastBlock() uint64 {
 	nc.mu.RLock()
 	defer nc.mu.RUnlock()
 	return nc.lastBlock
 }
 
+func (nc *NetworkConnector) setChainHead(blockNumber uint64) {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+	if blockNumber > nc.chainHead {
+		nc.chainHead = blockNumber
+		nc.headSince = time.Now()
+	}
+}
+
+func (nc *NetworkConnector) getChainHead() uint64 {
+	nc.mu.RLock()
+	defer nc.mu.RUnlock()
+	return nc.chainHead
+}
+
+// getBlockLag 获取落后链头的区块数
+func (nc *NetworkConnector) getBlockLag() uint64 {
+	nc.mu.RLock()
+	defer nc.mu.RUnlock()
+	if nc.chainHead > nc.lastBlock {
+		return nc.chainHead - nc.lastBlock
+	}
+	return 0
+}
+
 func (nc *NetworkConnector) getErrorCount() uint64 {
 	nc.mu.RLock()
 	defer nc.mu.RUnlock()
@@ -448,6 +1408,35 @@ func (nc *NetworkConnector) incrementErrorCount() {
 	nc.errorCount++
 }
 
+// markDown 标记网络不可用，返回已持续不可用的时长
+func (nc *NetworkConnector) markDown() time.Duration {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+	nc.errorCount++
+	if nc.downSince.IsZero() {
+		nc.downSince = time.Now()
+	}
+	return time.Since(nc.downSince)
+}
+
+// markHeaderPublished 记录已推送摘要的区块号，已推送过时返回false
+func (nc *NetworkConnector) markHeaderPublished(blockNumber uint64) bool {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+	if blockNumber <= nc.lastHeader {
+		return false
+	}
+	nc.lastHeader = blockNumber
+	return true
+}
+
+// markUp 标记网络恢复可用
+func (nc *NetworkConnector) markUp() {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+	nc.downSince = time.Time{}
+}
+
 func (nc *NetworkConnector) Close() error {
 	var err error
 	
@@ -458,6 +1447,10 @@ func (nc *NetworkConnector) Close() error {
 	if nc.wsClient != nil {
 		nc.wsClient.Close()
 	}
+
+	if nc.solana != nil {
+		nc.solana.Close()
+	}
 	
 	nc.isConnected = false
 	return err
@@ -0,0 +1,337 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/models"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Wormhole事件签名：存入由核心合约发出消息，到账由代币桥发出
+var (
+	wormholeMessageTopic  = crypto.Keccak256Hash([]byte("LogMessagePublished(address,uint64,uint32,bytes,uint8)"))
+	wormholeRedeemedTopic = crypto.Keccak256Hash([]byte("TransferRedeemed(uint16,bytes32,uint64)"))
+)
+
+// LayerZero V2端点事件签名
+var (
+	layerZeroSentTopic      = crypto.Keccak256Hash([]byte("PacketSent(bytes,bytes,address)"))
+	layerZeroDeliveredTopic = crypto.Keccak256Hash([]byte("PacketDelivered((uint32,bytes32,uint64),address)"))
+)
+
+// OP Stack标准桥事件签名（L1StandardBridge/L2StandardBridge的兼容事件）
+var (
+	opETHDepositInitiatedTopic      = crypto.Keccak256Hash([]byte("ETHDepositInitiated(address,address,uint256,bytes)"))
+	opERC20DepositInitiatedTopic    = crypto.Keccak256Hash([]byte("ERC20DepositInitiated(address,address,address,address,uint256,bytes)"))
+	opDepositFinalizedTopic         = crypto.Keccak256Hash([]byte("DepositFinalized(address,address,address,address,uint256,bytes)"))
+	opWithdrawalInitiatedTopic      = crypto.Keccak256Hash([]byte("WithdrawalInitiated(address,address,address,address,uint256,bytes)"))
+	opETHWithdrawalFinalizedTopic   = crypto.Keccak256Hash([]byte("ETHWithdrawalFinalized(address,address,uint256,bytes)"))
+	opERC20WithdrawalFinalizedTopic = crypto.Keccak256Hash([]byte("ERC20WithdrawalFinalized(address,address,address,address,uint256,bytes)"))
+)
+
+// Wormhole代币桥转账消息（payload ID 1为Transfer，3为TransferWithPayload）的最短长度
+const wormholeTransferPayloadLength = 133
+
+// LayerZero V2数据包头部加guid的长度
+const layerZeroPacketHeaderLength = 113
+
+// bridgesEnabled 网络上是否配置了监控的跨链桥合约
+func (bc *BlockchainCollector) bridgesEnabled(connector *NetworkConnector) bool {
+	bridges := bc.dataProcessor.Bridges()
+	return bridges != nil && connector.solana == nil && len(bridges.Contracts(connector.name)) > 0
+}
+
+// processBridgeLogs 解码区块内监控的桥合约的存入与到账事件，交给数据处理器关联，返回生成的告警
+func (bc *BlockchainCollector) processBridgeLogs(ctx context.Context, connector *NetworkConnector, block *types.Block, blockModel *models.Block) []*models.RiskAlert {
+	bridges := bc.dataProcessor.Bridges()
+
+	contracts := make(map[common.Address]config.BridgeContractConfig)
+	cores := make(map[common.Address]config.BridgeContractConfig)
+	var addresses []common.Address
+	for _, contract := range bridges.Contracts(connector.name) {
+		address := common.HexToAddress(contract.Address)
+		contracts[address] = contract
+		addresses = append(addresses, address)
+		if contract.Protocol == models.BridgeWormhole {
+			core := common.HexToAddress(contract.Core)
+			cores[core] = contract
+			addresses = append(addresses, core)
+		}
+	}
+
+	blockHash := block.Hash()
+	logs, err := connector.filterLogs(ctx, ethereum.FilterQuery{
+		BlockHash: &blockHash,
+		Addresses: addresses,
+		Topics: [][]common.Hash{{
+			wormholeMessageTopic, wormholeRedeemedTopic,
+			layerZeroSentTopic, layerZeroDeliveredTopic,
+			opETHDepositInitiatedTopic, opERC20DepositInitiatedTopic, opDepositFinalizedTopic,
+			opWithdrawalInitiatedTopic, opETHWithdrawalFinalizedTopic, opERC20WithdrawalFinalizedTopic,
+		}},
+	})
+	if err != nil {
+		logger.Errorf("Failed to get bridge logs of block %d for %s: %v", block.NumberU64(), connector.name, err)
+		bc.metricsManager.IncrementError(connector.name, "bridge_error")
+		return nil
+	}
+
+	txs := make(map[string]*models.Transaction, len(blockModel.Transactions))
+	for i := range blockModel.Transactions {
+		txs[blockModel.Transactions[i].Hash] = &blockModel.Transactions[i]
+	}
+
+	var transfers []*models.BridgeTransfer
+	for i := range logs {
+		log := &logs[i]
+		var transfer *models.BridgeTransfer
+		contract, isCore := cores[log.Address]
+		if isCore {
+			transfer = decodeWormholeMessage(bridges.NetworkOf, contract, log)
+		} else {
+			contract = contracts[log.Address]
+			switch contract.Protocol {
+			case models.BridgeWormhole:
+				transfer = decodeWormholeRedeemed(bridges.NetworkOf, contract, log)
+			case models.BridgeLayerZero:
+				transfer = decodeLayerZeroPacket(bridges.NetworkOf, contract, log)
+			case models.BridgeOPStandard:
+				transfer = decodeOPStandardBridge(bridges.Monitors, contract, log)
+			}
+		}
+		if transfer == nil {
+			continue
+		}
+
+		transfer.ID = models.BridgeTransferID(connector.name, log.BlockHash.Hex(), log.TxIndex, log.Index)
+		transfer.Network = connector.name
+		transfer.Protocol = contract.Protocol
+		transfer.TransactionHash = log.TxHash.Hex()
+		transfer.BlockNumber = blockModel.Number
+		transfer.Timestamp = blockModel.Timestamp
+		if tx, exists := txs[transfer.TransactionHash]; exists && transfer.Direction == models.BridgeDeposit && transfer.Sender == "" {
+			transfer.Sender = tx.FromAddress
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	if len(transfers) == 0 {
+		return nil
+	}
+	return bc.dataProcessor.ProcessBridgeTransfers(transfers)
+}
+
+// decodeWormholeMessage 解码核心合约发出的代币桥转账消息，其他发送方的消息及非转账消息返回nil
+func decodeWormholeMessage(networkOf func(string, uint32) string, contract config.BridgeContractConfig, log *types.Log) *models.BridgeTransfer {
+	if log.Topics[0] != wormholeMessageTopic || len(log.Topics) != 2 {
+		return nil
+	}
+	emitter := common.BytesToAddress(log.Topics[1].Bytes())
+	if emitter != common.HexToAddress(contract.Address) {
+		return nil
+	}
+
+	payload := dataBytes(log.Data, 2)
+	if len(payload) < wormholeTransferPayloadLength || (payload[0] != 1 && payload[0] != 3) {
+		return nil
+	}
+	sequence := dataWord(log.Data, 0).Uint64()
+	tokenChain := uint32(new(big.Int).SetBytes(payload[65:67]).Uint64())
+	toChain := uint32(new(big.Int).SetBytes(payload[99:101]).Uint64())
+
+	transfer := &models.BridgeTransfer{
+		Direction:          models.BridgeDeposit,
+		Bridge:             emitter.Hex(),
+		SourceNetwork:      contract.Network,
+		SourceChain:        fmt.Sprint(contract.ChainID),
+		DestinationNetwork: networkOf(models.BridgeWormhole, toChain),
+		DestinationChain:   fmt.Sprint(toChain),
+		Recipient:          bytes32Address(payload[67:99]),
+		Token:              bytes32Address(payload[33:65]),
+		Amount:             new(big.Int).SetBytes(payload[1:33]),
+		MessageID:          fmt.Sprintf("%s:%d:%s:%d", models.BridgeWormhole, contract.ChainID, log.Topics[1].Hex(), sequence),
+		Sequence:           sequence,
+	}
+	// 跨回来的包装代币以原链代币标识
+	if tokenChain != contract.ChainID {
+		transfer.Token = fmt.Sprintf("%d:%s", tokenChain, transfer.Token)
+	}
+	return transfer
+}
+
+// decodeWormholeRedeemed 解码代币桥的到账事件，源链代币及金额由对应的存入补全
+func decodeWormholeRedeemed(networkOf func(string, uint32) string, contract config.BridgeContractConfig, log *types.Log) *models.BridgeTransfer {
+	if log.Topics[0] != wormholeRedeemedTopic || len(log.Topics) != 4 {
+		return nil
+	}
+	emitterChain := uint32(new(big.Int).SetBytes(log.Topics[1].Bytes()).Uint64())
+	sequence := new(big.Int).SetBytes(log.Topics[3].Bytes()).Uint64()
+
+	return &models.BridgeTransfer{
+		Direction:          models.BridgeWithdrawal,
+		Bridge:             log.Address.Hex(),
+		SourceNetwork:      networkOf(models.BridgeWormhole, emitterChain),
+		SourceChain:        fmt.Sprint(emitterChain),
+		DestinationNetwork: contract.Network,
+		DestinationChain:   fmt.Sprint(contract.ChainID),
+		MessageID:          fmt.Sprintf("%s:%d:%s:%d", models.BridgeWormhole, emitterChain, log.Topics[2].Hex(), sequence),
+		Sequence:           sequence,
+	}
+}
+
+// decodeLayerZeroPacket 解码端点的发出与送达事件，以 源端点:发送方:目标端点:接收方 路径内递增的nonce为序号
+func decodeLayerZeroPacket(networkOf func(string, uint32) string, contract config.BridgeContractConfig, log *types.Log) *models.BridgeTransfer {
+	switch log.Topics[0] {
+	case layerZeroSentTopic:
+		packet := dataBytes(log.Data, 0)
+		if len(packet) < layerZeroPacketHeaderLength {
+			return nil
+		}
+		nonce := new(big.Int).SetBytes(packet[1:9]).Uint64()
+		srcEid := uint32(new(big.Int).SetBytes(packet[9:13]).Uint64())
+		dstEid := uint32(new(big.Int).SetBytes(packet[45:49]).Uint64())
+		sender := common.BytesToHash(packet[13:45])
+		receiver := common.BytesToHash(packet[49:81])
+
+		return &models.BridgeTransfer{
+			Direction:          models.BridgeDeposit,
+			Bridge:             log.Address.Hex(),
+			SourceNetwork:      contract.Network,
+			SourceChain:        fmt.Sprint(srcEid),
+			DestinationNetwork: networkOf(models.BridgeLayerZero, dstEid),
+			DestinationChain:   fmt.Sprint(dstEid),
+			Sender:             bytes32Address(sender.Bytes()),
+			Recipient:          bytes32Address(receiver.Bytes()),
+			MessageID:          layerZeroMessageID(srcEid, sender, dstEid, receiver, nonce),
+			Sequence:           nonce,
+		}
+
+	case layerZeroDeliveredTopic:
+		if len(log.Data) < 4*32 {
+			return nil
+		}
+		srcEid := uint32(dataWord(log.Data, 0).Uint64())
+		sender := common.BytesToHash(log.Data[32:64])
+		nonce := dataWord(log.Data, 2).Uint64()
+		receiver := common.BytesToHash(log.Data[96:128])
+
+		return &models.BridgeTransfer{
+			Direction:          models.BridgeWithdrawal,
+			Bridge:             log.Address.Hex(),
+			SourceNetwork:      networkOf(models.BridgeLayerZero, srcEid),
+			SourceChain:        fmt.Sprint(srcEid),
+			DestinationNetwork: contract.Network,
+			DestinationChain:   fmt.Sprint(contract.ChainID),
+			Sender:             bytes32Address(sender.Bytes()),
+			Recipient:          bytes32Address(receiver.Bytes()),
+			MessageID:          layerZeroMessageID(srcEid, sender, contract.ChainID, receiver, nonce),
+			Sequence:           nonce,
+		}
+	}
+	return nil
+}
+
+func layerZeroMessageID(srcEid uint32, sender common.Hash, dstEid uint32, receiver common.Hash, nonce uint64) string {
+	return fmt.Sprintf("%s:%d:%s:%d:%s:%d", models.BridgeLayerZero, srcEid, sender.Hex(), dstEid, receiver.Hex(), nonce)
+}
+
+// decodeOPStandardBridge 解码OP Stack标准桥的兼容事件。L1桥发起存入、完成提现，L2桥发起提现、完成存入；
+// 消息ID由 源网络:目标网络:L1代币:发送方:接收方:金额 组成，L1代币为零地址表示ETH
+func decodeOPStandardBridge(monitors func(string) bool, contract config.BridgeContractConfig, log *types.Log) *models.BridgeTransfer {
+	var direction, token, from, to string
+	var amount *big.Int
+	switch log.Topics[0] {
+	case opETHDepositInitiatedTopic, opETHWithdrawalFinalizedTopic:
+		if len(log.Topics) != 3 || len(log.Data) < 32 {
+			return nil
+		}
+		from, to = topicAddress(log.Topics[1]), topicAddress(log.Topics[2])
+		amount = dataWord(log.Data, 0)
+		direction = models.BridgeDeposit
+		if log.Topics[0] == opETHWithdrawalFinalizedTopic {
+			direction = models.BridgeWithdrawal
+		}
+
+	case opERC20DepositInitiatedTopic, opWithdrawalInitiatedTopic, opDepositFinalizedTopic, opERC20WithdrawalFinalizedTopic:
+		if len(log.Topics) != 4 || len(log.Data) < 2*32 {
+			return nil
+		}
+		if l1Token := common.BytesToAddress(log.Topics[1].Bytes()); l1Token != (common.Address{}) {
+			token = l1Token.Hex()
+		}
+		from = topicAddress(log.Topics[3])
+		to = common.BytesToAddress(log.Data[:32]).Hex()
+		amount = dataWord(log.Data, 1)
+		direction = models.BridgeDeposit
+		if log.Topics[0] == opDepositFinalizedTopic || log.Topics[0] == opERC20WithdrawalFinalizedTopic {
+			direction = models.BridgeWithdrawal
+		}
+
+	default:
+		return nil
+	}
+
+	transfer := &models.BridgeTransfer{
+		Direction: direction,
+		Bridge:    log.Address.Hex(),
+		Sender:    from,
+		Recipient: to,
+		Token:     token,
+		Amount:    amount,
+	}
+	peerNetwork := ""
+	if monitors(contract.Peer) {
+		peerNetwork = contract.Peer
+	}
+	if direction == models.BridgeDeposit {
+		transfer.SourceNetwork, transfer.SourceChain = contract.Network, contract.Network
+		transfer.DestinationNetwork, transfer.DestinationChain = peerNetwork, contract.Peer
+	} else {
+		transfer.SourceNetwork, transfer.SourceChain = peerNetwork, contract.Peer
+		transfer.DestinationNetwork, transfer.DestinationChain = contract.Network, contract.Network
+	}
+
+	tokenID := token
+	if tokenID == "" {
+		tokenID = "eth"
+	}
+	transfer.MessageID = strings.ToLower(fmt.Sprintf("%s:%s:%s:%s:%s:%s:%s",
+		models.BridgeOPStandard, transfer.SourceChain, transfer.DestinationChain, tokenID, from, to, amount.String()))
+	return transfer
+}
+
+// dataBytes 读取第index个32字节数据为偏移量的动态bytes参数，格式不正确时返回nil
+func dataBytes(data []byte, index int) []byte {
+	offset := dataWord(data, index)
+	if !offset.IsUint64() || offset.Uint64() > uint64(len(data)) {
+		return nil
+	}
+	start := int(offset.Uint64())
+	if len(data)-start < 32 {
+		return nil
+	}
+	length := dataWord(data[start:], 0)
+	if !length.IsUint64() || length.Uint64() > uint64(len(data)-start-32) {
+		return nil
+	}
+	return data[start+32 : start+32+int(length.Uint64())]
+}
+
+// bytes32Address 跨链协议中32字节的地址，EVM地址（高12字节为0）转为校验和格式，其他链保留十六进制
+func bytes32Address(b []byte) string {
+	hash := common.BytesToHash(b)
+	for _, v := range hash[:12] {
+		if v != 0 {
+			return hash.Hex()
+		}
+	}
+	return common.BytesToAddress(hash[12:]).Hex()
+}
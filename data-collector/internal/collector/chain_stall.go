@@ -0,0 +1,175 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/models"
+)
+
+// solanaSlotTime Solana的slot间隔
+const solanaSlotTime = 400 * time.Millisecond
+
+// knownBlockTimes 未配置block_time时按chain_id选择的出块间隔
+var knownBlockTimes = map[int64]time.Duration{
+	1:        12 * time.Second,       // Ethereum
+	17000:    12 * time.Second,       // Holesky
+	11155111: 12 * time.Second,       // Sepolia
+	5:        12 * time.Second,       // Goerli
+	10:       2 * time.Second,        // OP Mainnet
+	8453:     2 * time.Second,        // Base
+	137:      2 * time.Second,        // Polygon
+	43114:    2 * time.Second,        // Avalanche C-Chain
+	56:       3 * time.Second,        // BNB Chain
+	42161:    250 * time.Millisecond, // Arbitrum One
+}
+
+// stallPolicy 链头停滞判定策略
+type stallPolicy struct {
+	multiplier  float64
+	minDuration time.Duration
+}
+
+// newStallPolicy 解析停滞检测配置，未启用时返回nil
+func newStallPolicy(cfg config.StallDetectionConfig) *stallPolicy {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	policy := &stallPolicy{multiplier: cfg.Multiplier, minDuration: time.Minute}
+	if policy.multiplier <= 0 {
+		policy.multiplier = 10
+	}
+	if cfg.MinDuration != "" {
+		if duration, err := time.ParseDuration(cfg.MinDuration); err == nil {
+			policy.minDuration = duration
+		} else {
+			logger.Warnf("Invalid stall_detection min_duration %q, using %v", cfg.MinDuration, policy.minDuration)
+		}
+	}
+	return policy
+}
+
+// threshold 链头未前进超过该时长视为停滞
+func (p *stallPolicy) threshold(blockTime time.Duration) time.Duration {
+	threshold := time.Duration(float64(blockTime) * p.multiplier)
+	if threshold < p.minDuration {
+		threshold = p.minDuration
+	}
+	return threshold
+}
+
+// expectedBlockTime 获取网络的预期出块间隔，未知时返回0
+func expectedBlockTime(cfg config.NetworkConfig) time.Duration {
+	if cfg.BlockTime != "" {
+		if blockTime, err := time.ParseDuration(cfg.BlockTime); err == nil && blockTime > 0 {
+			return blockTime
+		}
+	}
+	if cfg.Chain == "solana" {
+		return solanaSlotTime
+	}
+	return knownBlockTimes[cfg.ChainID]
+}
+
+// checkChainStall 在链头查询成功后检查链头是否停滞。RPC正常响应但链头长时间不前进，
+// 说明链本身停止出块或服务商节点卡住；查询失败属于连接故障，由handlePollError处理；
+// 链头前进而处理落后属于收集器自身问题，由区块落后指标反映
+func (bc *BlockchainCollector) checkChainStall(ctx context.Context, connector *NetworkConnector) {
+	if bc.stallPolicy == nil {
+		return
+	}
+	blockTime := expectedBlockTime(connector.config)
+	if blockTime <= 0 {
+		return
+	}
+
+	head, stalledFor := connector.headStall()
+	bc.metricsManager.SetHeadStalled(connector.name, stalledFor)
+
+	threshold := bc.stallPolicy.threshold(blockTime)
+	if stalledFor < threshold {
+		if connector.setStalled(false) {
+			logger.Infof("Chain head of %s advanced to %d, stall resolved", connector.name, head)
+		}
+		return
+	}
+
+	// 同一次停滞只告警一次
+	if !connector.setStalled(true) {
+		return
+	}
+
+	logger.Errorf("Chain head of %s stuck at %d for %v (expected block time %v)", connector.name, head, stalledFor.Round(time.Second), blockTime)
+	bc.metricsManager.IncrementError(connector.name, "chain_stalled")
+
+	alert := bc.newChainStallAlert(ctx, connector, head, stalledFor, blockTime, threshold)
+	if err := bc.dataProcessor.PublishOpsAlert(alert); err != nil {
+		logger.Errorf("Failed to publish chain stall alert for %s: %v", connector.name, err)
+	}
+}
+
+// newChainStallAlert 创建链头停滞运维告警，附带链头区块时间等证据
+func (bc *BlockchainCollector) newChainStallAlert(ctx context.Context, connector *NetworkConnector, head uint64, stalledFor, blockTime, threshold time.Duration) *models.RiskAlert {
+	now := time.Now()
+	lastProcessed := connector.getLastBlock()
+
+	alert := &models.RiskAlert{
+		ID:    models.OpsAlertID("CHAIN_STALLED", now, connector.name),
+		Type:  "CHAIN_STALLED",
+		Level: "HIGH",
+		Title: fmt.Sprintf("Chain head stalled on %s", connector.name),
+		Description: fmt.Sprintf("RPC node keeps reporting head %d for %v, more than %.0fx the expected block time %v; the chain has halted or the provider is stuck",
+			head, stalledFor.Round(time.Second), bc.stallPolicy.multiplier, blockTime),
+		Network:     connector.name,
+		RiskFactors: []string{"chain_head_stalled"},
+		Metadata: map[string]interface{}{
+			"head_block":          head,
+			"last_processed":      lastProcessed,
+			"block_lag":           connector.getBlockLag(),
+			"stalled_for_seconds": stalledFor.Seconds(),
+			"expected_block_time": blockTime.String(),
+			"threshold":           threshold.String(),
+			"provider":            connector.provider,
+		},
+		Timestamp: now,
+		Status:    "ACTIVE",
+	}
+
+	// 链头区块时间：与停滞时长一致说明节点确实没有更新的区块
+	if connector.solana == nil {
+		if header, err := connector.getHeaderByNumber(ctx, head); err == nil {
+			headTime := time.Unix(int64(header.Time), 0)
+			alert.Metadata["head_hash"] = header.Hash().Hex()
+			alert.Metadata["head_timestamp"] = headTime.Unix()
+			alert.Metadata["head_age_seconds"] = now.Sub(headTime).Seconds()
+		} else {
+			logger.Warnf("Failed to get head header %d of %s: %v", head, connector.name, err)
+		}
+	}
+
+	return alert
+}
+
+// headStall 获取链头区块号及其未前进的时长
+func (nc *NetworkConnector) headStall() (uint64, time.Duration) {
+	nc.mu.RLock()
+	defer nc.mu.RUnlock()
+	if nc.headSince.IsZero() {
+		return nc.chainHead, 0
+	}
+	return nc.chainHead, time.Since(nc.headSince)
+}
+
+// setStalled 更新停滞状态，状态变化时返回true
+func (nc *NetworkConnector) setStalled(stalled bool) bool {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+	if nc.stalled == stalled {
+		return false
+	}
+	nc.stalled = stalled
+	return true
+}
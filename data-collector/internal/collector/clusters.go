@@ -0,0 +1,53 @@
+package collector
+
+import (
+	"context"
+
+	"web3-data-collector/internal/processor"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// linkCoSpending 同一交易中发起方转出代币的同时，其他外部账户也转出代币时（批量归集、代付gas等），
+// 将这些账户与发起方关联；合约账户（交易池、路由等）不参与关联
+func (bc *BlockchainCollector) linkCoSpending(ctx context.Context, connector *NetworkConnector, transfers []*processor.TokenTransfer, contracts map[common.Address]bool) {
+	clusters := bc.dataProcessor.Clusters()
+
+	type spending struct {
+		signer     string
+		signerSent bool
+		owners     map[string]bool
+	}
+	byTransaction := make(map[string]*spending)
+	var order []string
+	for _, transfer := range transfers {
+		from := common.HexToAddress(transfer.From)
+		if from == (common.Address{}) || transfer.Amount == nil || transfer.Amount.Sign() <= 0 {
+			continue
+		}
+		tx, exists := byTransaction[transfer.TransactionHash]
+		if !exists {
+			tx = &spending{signer: common.HexToAddress(transfer.TxFrom).Hex(), owners: make(map[string]bool)}
+			byTransaction[transfer.TransactionHash] = tx
+			order = append(order, transfer.TransactionHash)
+		}
+		if from.Hex() == tx.signer {
+			tx.signerSent = true
+		} else {
+			tx.owners[from.Hex()] = true
+		}
+	}
+
+	for _, hash := range order {
+		tx := byTransaction[hash]
+		if !tx.signerSent {
+			continue
+		}
+		for owner := range tx.owners {
+			if bc.isContract(ctx, connector, common.HexToAddress(owner), contracts) {
+				continue
+			}
+			clusters.Link(connector.name, tx.signer, owner, processor.ClusterCoSpending)
+		}
+	}
+}
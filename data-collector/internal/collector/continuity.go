@@ -0,0 +1,298 @@
+package collector
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/metrics"
+	"web3-data-collector/internal/models"
+	"web3-data-collector/internal/processor"
+)
+
+const (
+	// refetchPerPoll 每次轮询最多重新获取的排队区块数
+	refetchPerPoll = 20
+	// continuityWindow 保留哈希用于父哈希校验的最近区块数
+	continuityWindow = 256
+)
+
+// continuityChecker 按网络校验已处理区块的区块号连续及父哈希，遗漏或被链重组替换的区块排队重新获取
+type continuityChecker struct {
+	maxQueued      int
+	maxRetries     int
+	history        int
+	metricsManager *metrics.Manager
+	networks       map[string]*networkContinuity
+	mu             sync.Mutex
+}
+
+// networkContinuity 单个网络的连续性状态
+type networkContinuity struct {
+	highest    uint64
+	started    bool
+	recent     map[uint64]continuityEntry
+	queue      map[uint64]int // 排队的区块号及已重新获取的次数
+	missing    uint64
+	refetched  uint64
+	abandoned  uint64
+	outOfOrder uint64
+	gaps       []models.BlockGap
+}
+
+// continuityEntry 已处理的区块，hash为空且非empty时为未知（如被隔离的区块），不做父哈希校验
+type continuityEntry struct {
+	hash  string
+	empty bool // 被跳过的slot，没有区块
+}
+
+// newContinuityChecker 根据配置创建连续性检查，未启用时返回nil
+func newContinuityChecker(cfg config.ContinuityConfig, metricsManager *metrics.Manager) *continuityChecker {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	checker := &continuityChecker{
+		maxQueued:      cfg.MaxQueued,
+		maxRetries:     cfg.MaxRetries,
+		history:        cfg.History,
+		metricsManager: metricsManager,
+		networks:       make(map[string]*networkContinuity),
+	}
+	if checker.maxQueued <= 0 {
+		checker.maxQueued = 1000
+	}
+	if checker.maxRetries <= 0 {
+		checker.maxRetries = 3
+	}
+	if checker.history <= 0 {
+		checker.history = 100
+	}
+	return checker
+}
+
+// state 获取网络状态，调用方需持有锁
+func (c *continuityChecker) state(network string) *networkContinuity {
+	state, exists := c.networks[network]
+	if !exists {
+		state = &networkContinuity{
+			recent: make(map[uint64]continuityEntry),
+			queue:  make(map[uint64]int),
+		}
+		c.networks[network] = state
+	}
+	return state
+}
+
+// reset 网络（重新）启动时从新的起点开始检查，停用期间未处理的区块不视为遗漏
+func (c *continuityChecker) reset(network string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state := c.state(network)
+	state.started = false
+	state.highest = 0
+	state.recent = make(map[uint64]continuityEntry)
+	state.queue = make(map[uint64]int)
+}
+
+// observe 记录处理完成的区块，检查父哈希及与已处理最高区块之间的缺口
+func (c *continuityChecker) observe(network string, block *models.Block) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state := c.state(network)
+	number := block.Number
+
+	if _, queued := state.queue[number]; queued {
+		delete(state.queue, number)
+		state.refetched++
+	} else if state.started && number <= state.highest {
+		if entry, exists := state.recent[number]; !exists || entry.hash != block.Hash {
+			state.outOfOrder++
+			c.record(state, models.BlockGap{Network: network, Kind: models.BlockGapOutOfOrder, FromBlock: number, ToBlock: number})
+		}
+	}
+
+	// 前一区块已被链重组替换时重新获取，重新获取的区块继续向前校验
+	if parent, expected, ok := state.parent(number); ok && expected != block.ParentHash {
+		queued := c.enqueue(state, parent, parent)
+		state.missing++
+		c.metricsManager.RecordMissingBlocks(network, models.BlockGapParentMismatch, 1)
+		c.record(state, models.BlockGap{
+			Network:        network,
+			Kind:           models.BlockGapParentMismatch,
+			FromBlock:      parent,
+			ToBlock:        parent,
+			Queued:         queued,
+			ExpectedParent: expected,
+			ActualParent:   block.ParentHash,
+		})
+		logger.Warnf("Parent hash mismatch at block %d for %s: expected %s, got %s", number, network, expected, block.ParentHash)
+	}
+
+	if state.started && number > state.highest+1 {
+		from, to := state.highest+1, number-1
+		queued := c.enqueue(state, from, to)
+		state.missing += to - from + 1
+		c.metricsManager.RecordMissingBlocks(network, models.BlockGapMissing, int(to-from+1))
+		c.record(state, models.BlockGap{Network: network, Kind: models.BlockGapMissing, FromBlock: from, ToBlock: to, Queued: queued})
+		logger.Warnf("Missing blocks %d-%d for %s, %d queued for refetch", from, to, network, queued)
+	}
+
+	c.remember(state, number, continuityEntry{hash: block.Hash})
+}
+
+// skip 记录没有区块的slot（empty）或被隔离的区块，二者都不视为遗漏
+func (c *continuityChecker) skip(network string, number uint64, empty bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state := c.state(network)
+	if _, queued := state.queue[number]; queued {
+		delete(state.queue, number)
+		if !empty {
+			state.abandoned++
+		}
+	}
+	c.remember(state, number, continuityEntry{empty: empty})
+}
+
+// take 取出待重新获取的区块，重试次数用尽的区块放弃
+func (c *continuityChecker) take(network string, limit int) []uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state := c.state(network)
+	numbers := make([]uint64, 0, len(state.queue))
+	for number, attempts := range state.queue {
+		if attempts >= c.maxRetries {
+			delete(state.queue, number)
+			state.abandoned++
+			logger.Warnf("Giving up refetching block %d for %s after %d attempts", number, network, attempts)
+			continue
+		}
+		numbers = append(numbers, number)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+
+	if len(numbers) > limit {
+		numbers = numbers[:limit]
+	}
+	for _, number := range numbers {
+		state.queue[number]++
+	}
+	return numbers
+}
+
+// report 获取各网络的连续性报告
+func (c *continuityChecker) report() []*models.NetworkContinuity {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	reports := make([]*models.NetworkContinuity, 0, len(c.networks))
+	for network, state := range c.networks {
+		queued := make([]uint64, 0, len(state.queue))
+		for number := range state.queue {
+			queued = append(queued, number)
+		}
+		sort.Slice(queued, func(i, j int) bool { return queued[i] < queued[j] })
+
+		reports = append(reports, &models.NetworkContinuity{
+			Network:      network,
+			HighestBlock: state.highest,
+			Queued:       queued,
+			Missing:      state.missing,
+			Refetched:    state.refetched,
+			Abandoned:    state.abandoned,
+			OutOfOrder:   state.outOfOrder,
+			RecentGaps:   append([]models.BlockGap{}, state.gaps...),
+		})
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Network < reports[j].Network
+	})
+	return reports
+}
+
+// parent 最近一个有区块的前序区块号及哈希，跳过没有区块的slot；未知时返回false
+func (s *networkContinuity) parent(number uint64) (uint64, string, bool) {
+	for n := number; n > 0 && number-n < continuityWindow; n-- {
+		entry, exists := s.recent[n-1]
+		if !exists || (!entry.empty && entry.hash == "") {
+			return 0, "", false
+		}
+		if !entry.empty {
+			return n - 1, entry.hash, true
+		}
+	}
+	return 0, "", false
+}
+
+// enqueue 排队重新获取区块，超出排队上限的放弃，返回排队的数量。调用方需持有锁
+func (c *continuityChecker) enqueue(state *networkContinuity, from, to uint64) int {
+	queued := 0
+	for number := from; number <= to; number++ {
+		if _, exists := state.queue[number]; exists {
+			continue
+		}
+		if len(state.queue) >= c.maxQueued {
+			state.abandoned += to - number + 1
+			break
+		}
+		state.queue[number] = 0
+		queued++
+	}
+	return queued
+}
+
+// remember 保存区块并推进已处理最高区块，只保留最近的区块。调用方需持有锁
+func (c *continuityChecker) remember(state *networkContinuity, number uint64, entry continuityEntry) {
+	state.recent[number] = entry
+	if !state.started || number > state.highest {
+		state.highest = number
+		state.started = true
+	}
+
+	if len(state.recent) > 2*continuityWindow {
+		for n := range state.recent {
+			if n+continuityWindow < state.highest {
+				delete(state.recent, n)
+			}
+		}
+	}
+}
+
+// record 保存最近的连续性问题。调用方需持有锁
+func (c *continuityChecker) record(state *networkContinuity, gap models.BlockGap) {
+	gap.DetectedAt = time.Now()
+	state.gaps = append(state.gaps, gap)
+	if len(state.gaps) > c.history {
+		state.gaps = state.gaps[len(state.gaps)-c.history:]
+	}
+}
+
+// refetchMissing 重新获取连续性检查排队的区块，被隔离的区块交由隔离区处理
+func (bc *BlockchainCollector) refetchMissing(ctx context.Context, connector *NetworkConnector) {
+	for _, number := range bc.continuity.take(connector.name, refetchPerPoll) {
+		if bc.stopping() {
+			return
+		}
+		if err := bc.processBlockSupervised(ctx, connector, number); err != nil {
+			bc.reportError(err, connector.name, number, "Error refetching missing block")
+			if processor.IsQuarantined(err) {
+				bc.continuity.skip(connector.name, number, false)
+			}
+		}
+	}
+}
+
+// GetBlockGaps 获取各网络的区块连续性报告，未启用连续性检查时返回nil
+func (bc *BlockchainCollector) GetBlockGaps() []*models.NetworkContinuity {
+	if bc.continuity == nil {
+		return nil
+	}
+	return bc.continuity.report()
+}
@@ -0,0 +1,257 @@
+package collector
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/metrics"
+	"web3-data-collector/internal/models"
+)
+
+const testNetwork = "ethereum"
+
+func newTestContinuity(t *testing.T, cfg config.ContinuityConfig) *continuityChecker {
+	t.Helper()
+	cfg.Enabled = true
+	return newContinuityChecker(cfg, metrics.NewManager())
+}
+
+// testBlock 构造哈希与区块号对应的区块，父哈希指向前一区块
+func testBlock(number uint64) *models.Block {
+	return &models.Block{
+		Number:     number,
+		Hash:       blockHash(number),
+		ParentHash: blockHash(number - 1),
+	}
+}
+
+func blockHash(number uint64) string {
+	return fmt.Sprintf("0x%064x", number)
+}
+
+func networkReport(t *testing.T, c *continuityChecker) *models.NetworkContinuity {
+	t.Helper()
+	for _, report := range c.report() {
+		if report.Network == testNetwork {
+			return report
+		}
+	}
+	t.Fatalf("no continuity report for %s", testNetwork)
+	return nil
+}
+
+func gapKinds(report *models.NetworkContinuity) []string {
+	kinds := make([]string, 0, len(report.RecentGaps))
+	for _, gap := range report.RecentGaps {
+		kinds = append(kinds, gap.Kind)
+	}
+	return kinds
+}
+
+func TestContinuitySequentialBlocks(t *testing.T) {
+	c := newTestContinuity(t, config.ContinuityConfig{})
+	for number := uint64(100); number <= 105; number++ {
+		c.observe(testNetwork, testBlock(number))
+	}
+
+	report := networkReport(t, c)
+	if report.HighestBlock != 105 {
+		t.Errorf("highest block = %d, want 105", report.HighestBlock)
+	}
+	if report.Missing != 0 || report.OutOfOrder != 0 || len(report.RecentGaps) != 0 {
+		t.Errorf("unexpected gaps: %+v", report)
+	}
+}
+
+func TestContinuityMissingBlocksQueued(t *testing.T) {
+	c := newTestContinuity(t, config.ContinuityConfig{})
+	c.observe(testNetwork, testBlock(100))
+	c.observe(testNetwork, testBlock(104))
+
+	report := networkReport(t, c)
+	if report.Missing != 3 {
+		t.Errorf("missing = %d, want 3", report.Missing)
+	}
+	if want := []uint64{101, 102, 103}; !reflect.DeepEqual(report.Queued, want) {
+		t.Errorf("queued = %v, want %v", report.Queued, want)
+	}
+	if want := []string{models.BlockGapMissing}; !reflect.DeepEqual(gapKinds(report), want) {
+		t.Errorf("gap kinds = %v, want %v", gapKinds(report), want)
+	}
+	gap := report.RecentGaps[0]
+	if gap.FromBlock != 101 || gap.ToBlock != 103 || gap.Queued != 3 {
+		t.Errorf("gap = %+v, want 101-103 with 3 queued", gap)
+	}
+}
+
+func TestContinuityMissingBlocksRespectQueueLimit(t *testing.T) {
+	c := newTestContinuity(t, config.ContinuityConfig{MaxQueued: 2})
+	c.observe(testNetwork, testBlock(100))
+	c.observe(testNetwork, testBlock(105))
+
+	report := networkReport(t, c)
+	if want := []uint64{101, 102}; !reflect.DeepEqual(report.Queued, want) {
+		t.Errorf("queued = %v, want %v", report.Queued, want)
+	}
+	if report.Abandoned != 2 {
+		t.Errorf("abandoned = %d, want 2", report.Abandoned)
+	}
+}
+
+func TestContinuityRefetchedBlockIsNotOutOfOrder(t *testing.T) {
+	c := newTestContinuity(t, config.ContinuityConfig{})
+	c.observe(testNetwork, testBlock(100))
+	c.observe(testNetwork, testBlock(102))
+
+	if got := c.take(testNetwork, refetchPerPoll); !reflect.DeepEqual(got, []uint64{101}) {
+		t.Fatalf("take = %v, want [101]", got)
+	}
+	c.observe(testNetwork, testBlock(101))
+
+	report := networkReport(t, c)
+	if report.Refetched != 1 {
+		t.Errorf("refetched = %d, want 1", report.Refetched)
+	}
+	if report.OutOfOrder != 0 {
+		t.Errorf("out of order = %d, want 0", report.OutOfOrder)
+	}
+	if len(report.Queued) != 0 {
+		t.Errorf("queued = %v, want none", report.Queued)
+	}
+	if report.HighestBlock != 102 {
+		t.Errorf("highest block = %d, want 102", report.HighestBlock)
+	}
+}
+
+func TestContinuityOutOfOrderBlock(t *testing.T) {
+	c := newTestContinuity(t, config.ContinuityConfig{})
+	c.observe(testNetwork, testBlock(100))
+	c.observe(testNetwork, testBlock(101))
+	c.observe(testNetwork, testBlock(102))
+
+	// 101已处理，但以不同哈希再次出现且不在重新获取队列中
+	replaced := testBlock(101)
+	replaced.Hash = "0xreplaced"
+	c.observe(testNetwork, replaced)
+
+	report := networkReport(t, c)
+	if report.OutOfOrder != 1 {
+		t.Errorf("out of order = %d, want 1", report.OutOfOrder)
+	}
+	if report.HighestBlock != 102 {
+		t.Errorf("highest block = %d, want 102", report.HighestBlock)
+	}
+}
+
+func TestContinuityDuplicateBlockIgnored(t *testing.T) {
+	c := newTestContinuity(t, config.ContinuityConfig{})
+	c.observe(testNetwork, testBlock(100))
+	c.observe(testNetwork, testBlock(101))
+	c.observe(testNetwork, testBlock(101))
+
+	report := networkReport(t, c)
+	if report.OutOfOrder != 0 || report.Missing != 0 || len(report.RecentGaps) != 0 {
+		t.Errorf("duplicate block reported as gap: %+v", report)
+	}
+}
+
+func TestContinuityParentMismatchQueuesParent(t *testing.T) {
+	c := newTestContinuity(t, config.ContinuityConfig{})
+	c.observe(testNetwork, testBlock(100))
+	c.observe(testNetwork, testBlock(101))
+
+	reorged := testBlock(102)
+	reorged.ParentHash = "0xother"
+	c.observe(testNetwork, reorged)
+
+	report := networkReport(t, c)
+	if want := []uint64{101}; !reflect.DeepEqual(report.Queued, want) {
+		t.Errorf("queued = %v, want %v", report.Queued, want)
+	}
+	if want := []string{models.BlockGapParentMismatch}; !reflect.DeepEqual(gapKinds(report), want) {
+		t.Fatalf("gap kinds = %v, want %v", gapKinds(report), want)
+	}
+	gap := report.RecentGaps[0]
+	if gap.ExpectedParent != blockHash(101) || gap.ActualParent != "0xother" {
+		t.Errorf("gap parents = %s/%s, want %s/0xother", gap.ExpectedParent, gap.ActualParent, blockHash(101))
+	}
+}
+
+func TestContinuitySkippedSlotIsNotMissing(t *testing.T) {
+	c := newTestContinuity(t, config.ContinuityConfig{})
+	c.observe(testNetwork, testBlock(100))
+	c.skip(testNetwork, 101, true)
+
+	// 跳过的slot之后的区块以最近一个有区块的slot为父
+	next := testBlock(102)
+	next.ParentHash = blockHash(100)
+	c.observe(testNetwork, next)
+
+	report := networkReport(t, c)
+	if report.Missing != 0 || len(report.RecentGaps) != 0 {
+		t.Errorf("skipped slot reported as gap: %+v", report)
+	}
+}
+
+func TestContinuitySkipQuarantinedAbandonsQueuedBlock(t *testing.T) {
+	c := newTestContinuity(t, config.ContinuityConfig{})
+	c.observe(testNetwork, testBlock(100))
+	c.observe(testNetwork, testBlock(102))
+
+	c.skip(testNetwork, 101, false)
+
+	report := networkReport(t, c)
+	if len(report.Queued) != 0 {
+		t.Errorf("queued = %v, want none", report.Queued)
+	}
+	if report.Abandoned != 1 {
+		t.Errorf("abandoned = %d, want 1", report.Abandoned)
+	}
+
+	// 被隔离的区块哈希未知，其后的区块不做父哈希校验
+	next := testBlock(102)
+	next.ParentHash = "0xunknown"
+	c.observe(testNetwork, next)
+	if got := networkReport(t, c).Queued; len(got) != 0 {
+		t.Errorf("queued after quarantined parent = %v, want none", got)
+	}
+}
+
+func TestContinuityTakeLimitAndRetries(t *testing.T) {
+	c := newTestContinuity(t, config.ContinuityConfig{MaxRetries: 2})
+	c.observe(testNetwork, testBlock(100))
+	c.observe(testNetwork, testBlock(105))
+
+	if got, want := c.take(testNetwork, 2), []uint64{101, 102}; !reflect.DeepEqual(got, want) {
+		t.Errorf("first take = %v, want %v", got, want)
+	}
+	if got, want := c.take(testNetwork, 10), []uint64{101, 102, 103, 104}; !reflect.DeepEqual(got, want) {
+		t.Errorf("second take = %v, want %v", got, want)
+	}
+	// 101和102已重新获取两次，达到重试上限后放弃
+	if got, want := c.take(testNetwork, 10), []uint64{103, 104}; !reflect.DeepEqual(got, want) {
+		t.Errorf("third take = %v, want %v", got, want)
+	}
+
+	report := networkReport(t, c)
+	if report.Abandoned != 2 {
+		t.Errorf("abandoned = %d, want 2", report.Abandoned)
+	}
+}
+
+func TestContinuityResetStartsFresh(t *testing.T) {
+	c := newTestContinuity(t, config.ContinuityConfig{})
+	c.observe(testNetwork, testBlock(100))
+	c.reset(testNetwork)
+	c.observe(testNetwork, testBlock(200))
+
+	report := networkReport(t, c)
+	if report.Missing != 0 || len(report.Queued) != 0 {
+		t.Errorf("blocks skipped while stopped reported as missing: %+v", report)
+	}
+	if report.HighestBlock != 200 {
+		t.Errorf("highest block = %d, want 200", report.HighestBlock)
+	}
+}
@@ -0,0 +1,142 @@
+package collector
+
+import (
+	"sort"
+	"time"
+
+	"web3-data-collector/internal/models"
+)
+
+// 订阅类型
+const (
+	subscriptionHeads = "heads"
+	subscriptionLogs  = "logs"
+	subscriptionSlots = "slots"
+)
+
+// track 记录一个收集器协程开始运行，返回的函数在协程退出时调用
+func (bc *BlockchainCollector) track(goroutine string) func() {
+	bc.goroutinesMu.Lock()
+	bc.goroutines[goroutine]++
+	bc.goroutinesMu.Unlock()
+
+	return func() {
+		bc.goroutinesMu.Lock()
+		bc.goroutines[goroutine]--
+		bc.goroutinesMu.Unlock()
+	}
+}
+
+// GoroutineCounts 获取收集器各类协程当前的运行数量
+func (bc *BlockchainCollector) GoroutineCounts() map[string]int64 {
+	bc.goroutinesMu.Lock()
+	defer bc.goroutinesMu.Unlock()
+
+	counts := make(map[string]int64, len(bc.goroutines))
+	for name, count := range bc.goroutines {
+		counts[name] = count
+	}
+	return counts
+}
+
+// NetworkDiagnostics 获取各运行中网络的连接及订阅状态
+func (bc *BlockchainCollector) NetworkDiagnostics() []*models.NetworkDiagnostics {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+
+	diagnostics := make([]*models.NetworkDiagnostics, 0, len(bc.connectors))
+	for _, connector := range bc.connectors {
+		diagnostics = append(diagnostics, connector.diagnostics())
+	}
+
+	sort.Slice(diagnostics, func(i, j int) bool {
+		return diagnostics[i].Network < diagnostics[j].Network
+	})
+	return diagnostics
+}
+
+// diagnostics 网络连接及订阅状态的快照
+func (nc *NetworkConnector) diagnostics() *models.NetworkDiagnostics {
+	nc.mu.RLock()
+	defer nc.mu.RUnlock()
+
+	diagnostics := &models.NetworkDiagnostics{
+		Network:       nc.name,
+		Connected:     nc.isConnected,
+		LastBlock:     nc.lastBlock,
+		ChainHead:     nc.chainHead,
+		Stalled:       nc.stalled,
+		ErrorCount:    nc.errorCount,
+		Subscriptions: make([]*models.SubscriptionHealth, 0, len(nc.subscriptions)),
+	}
+	if nc.chainHead > nc.lastBlock {
+		diagnostics.BlockLag = nc.chainHead - nc.lastBlock
+	}
+	if !nc.headSince.IsZero() {
+		diagnostics.HeadAgeSeconds = time.Since(nc.headSince).Seconds()
+	}
+	if !nc.downSince.IsZero() {
+		downSince := nc.downSince
+		diagnostics.DownSince = &downSince
+	}
+
+	for _, subscription := range nc.subscriptions {
+		health := *subscription
+		diagnostics.Subscriptions = append(diagnostics.Subscriptions, &health)
+	}
+	sort.Slice(diagnostics.Subscriptions, func(i, j int) bool {
+		return diagnostics.Subscriptions[i].Kind < diagnostics.Subscriptions[j].Kind
+	})
+	return diagnostics
+}
+
+// subscription 获取订阅状态，不存在时创建，调用方需持有写锁
+func (nc *NetworkConnector) subscription(kind string) *models.SubscriptionHealth {
+	if nc.subscriptions == nil {
+		nc.subscriptions = make(map[string]*models.SubscriptionHealth)
+	}
+	subscription, exists := nc.subscriptions[kind]
+	if !exists {
+		subscription = &models.SubscriptionHealth{Kind: kind}
+		nc.subscriptions[kind] = subscription
+	}
+	return subscription
+}
+
+// subscriptionStarted 记录订阅已建立
+func (nc *NetworkConnector) subscriptionStarted(kind string) {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+
+	subscription := nc.subscription(kind)
+	subscription.Active = true
+	subscription.Since = time.Now()
+}
+
+// subscriptionMessage 记录收到一条订阅通知
+func (nc *NetworkConnector) subscriptionMessage(kind string) {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+
+	now := time.Now()
+	subscription := nc.subscription(kind)
+	subscription.Messages++
+	subscription.LastMessageAt = &now
+}
+
+// subscriptionEnded 记录订阅断开或建立失败，err为nil表示正常停止；已断开时不再更新
+func (nc *NetworkConnector) subscriptionEnded(kind string, err error) {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+
+	subscription := nc.subscription(kind)
+	if err == nil && !subscription.Active {
+		return
+	}
+	subscription.Active = false
+	subscription.Since = time.Now()
+	if err != nil {
+		subscription.Failures++
+		subscription.LastError = err.Error()
+	}
+}
@@ -0,0 +1,37 @@
+package collector
+
+import (
+	"encoding/json"
+	"errors"
+
+	"web3-data-collector/internal/faults"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// rpcError 将RPC调用错误包装为带网络及区块上下文的分类错误，响应无法解码时为DecodeError；
+// 保留原始错误链，限流等判断仍可通过errors.As获取
+func (nc *NetworkConnector) rpcError(method string, block uint64, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.Is(err, types.ErrTxTypeNotSupported) || errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+		return &faults.DecodeError{Network: nc.name, Kind: "rpc_response", Block: block, Ref: method, Err: err}
+	}
+	return &faults.RPCError{Network: nc.name, Method: method, Block: block, Err: err}
+}
+
+// reportError 按错误类别输出日志并计入指标，错误未携带网络及区块号时使用调用方提供的值
+func (bc *BlockchainCollector) reportError(err error, network string, block uint64, message string) {
+	faults.Log(err, network, block, message)
+	bc.metricsManager.RecordError(err, network, block)
+}
+
+// reportWarning 同reportError，用于不影响区块处理的降级路径，以警告级别输出
+func (bc *BlockchainCollector) reportWarning(err error, network string, block uint64, message string) {
+	faults.Warn(err, network, block, message)
+	bc.metricsManager.RecordError(err, network, block)
+}
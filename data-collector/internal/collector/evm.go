@@ -0,0 +1,316 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"web3-data-collector/internal/faults"
+	"web3-data-collector/internal/models"
+	"web3-data-collector/internal/processor"
+	"web3-data-collector/internal/watchdog"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// evmAdapter EVM网络适配器
+type evmAdapter struct {
+	bc *BlockchainCollector
+}
+
+// evmBlock EVM原始区块，交易按transactionIndex排列，L2系统交易插回原位置
+type evmBlock struct {
+	block    *types.Block
+	txs      []evmTransaction
+	receipts []*types.Receipt // 仅关注模式筛选交易时获取的全部回执，未获取时为nil
+}
+
+// evmTransaction go-ethereum可解码的交易或L2系统交易，二者之一非空
+type evmTransaction struct {
+	tx     *types.Transaction
+	system *l2Transaction
+}
+
+func newEVMAdapter(bc *BlockchainCollector) ChainAdapter {
+	return &evmAdapter{bc: bc}
+}
+
+// Connect 连接RPC及WebSocket客户端并校验连接
+func (a *evmAdapter) Connect(connector *NetworkConnector) error {
+	bc := a.bc
+	cfg := connector.config
+
+	connector.pacer = newSyncPacer(bc.config.SyncPacing)
+	if cfg.RPCURL != "" {
+		rpcClient, err := bc.dialRPC(connector.name, connector.provider, cfg, connector.pacer)
+		if err != nil {
+			return fmt.Errorf("failed to connect to RPC: %w", err)
+		}
+		connector.rpcClient = ethclient.NewClient(rpcClient)
+	}
+
+	// 回放模式下不连接WebSocket，新区块通过轮询获取
+	if cfg.WSURL != "" && bc.config.RPCRecording.Mode != rpcRecordingReplay {
+		wsClient, err := ethclient.Dial(cfg.WSURL)
+		if err != nil {
+			logger.Warnf("Failed to connect to WebSocket for %s: %v", connector.name, err)
+		} else {
+			connector.wsClient = wsClient
+		}
+	}
+
+	if err := connector.validateConnection(); err != nil {
+		return fmt.Errorf("connection validation failed: %w", err)
+	}
+	return nil
+}
+
+// LatestBlock 获取最新区块号
+func (a *evmAdapter) LatestBlock(ctx context.Context, connector *NetworkConnector) (uint64, error) {
+	return connector.getLatestBlockNumber(ctx)
+}
+
+// SubscribeHeads 订阅新区块头，收到后立即推送区块头摘要，完整区块在处理完成后推送
+func (a *evmAdapter) SubscribeHeads(ctx context.Context, connector *NetworkConnector, heads chan<- uint64) error {
+	if connector.wsClient == nil {
+		return errHeadsUnsupported
+	}
+
+	logger.Infof("Subscribing to new blocks for network: %s", connector.name)
+
+	headers := make(chan *types.Header)
+	connector.recordCall("eth_subscribe")
+	sub, err := connector.wsClient.SubscribeNewHead(ctx, headers)
+	if err != nil {
+		err = &faults.RPCError{Network: connector.name, Method: "eth_subscribe", Err: err}
+		connector.subscriptionEnded(subscriptionHeads, err)
+		return err
+	}
+	defer sub.Unsubscribe()
+	connector.subscriptionStarted(subscriptionHeads)
+
+	for {
+		select {
+		case <-ctx.Done():
+			connector.subscriptionEnded(subscriptionHeads, nil)
+			return ctx.Err()
+		case err := <-sub.Err():
+			err = &faults.RPCError{Network: connector.name, Method: "eth_subscribe", Err: err}
+			connector.subscriptionEnded(subscriptionHeads, err)
+			return err
+		case header := <-headers:
+			if header == nil {
+				continue
+			}
+			connector.subscriptionMessage(subscriptionHeads)
+			a.bc.publishHeader(connector, headerSummary(connector.name, header, time.Now()))
+			notifyHead(heads, header.Number.Uint64())
+		}
+	}
+}
+
+// FetchBlock 获取区块，L2网络同时解析L1起源并更新定序器时间偏移
+func (a *evmAdapter) FetchBlock(ctx context.Context, connector *NetworkConnector, number uint64) (*ChainBlock, error) {
+	block, systemTxs, err := connector.getBlockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := &evmBlock{block: block, txs: make([]evmTransaction, 0, len(block.Transactions())+len(systemTxs))}
+	txs := block.Transactions()
+	for i, next := 0, 0; i < cap(raw.txs); i++ {
+		if len(systemTxs) > 0 && uint(systemTxs[0].Index) == uint(i) {
+			raw.txs = append(raw.txs, evmTransaction{system: systemTxs[0]})
+			systemTxs = systemTxs[1:]
+			continue
+		}
+		if next >= len(txs) {
+			break
+		}
+		raw.txs = append(raw.txs, evmTransaction{tx: txs[next]})
+		next++
+	}
+
+	blockModel := a.bc.convertToBlockModel(block, connector.name)
+	blockModel.ExtraData = decodeExtraData(extraDataFormat(connector.config), block.Header())
+
+	// L2网络的L1起源，用于定序器监控
+	if l2 := l2Type(connector.config); l2 != "" {
+		var system []*l2Transaction
+		for _, tx := range raw.txs {
+			if tx.system != nil {
+				system = append(system, tx.system)
+			}
+		}
+		blockModel.L2 = decodeL2Block(l2, block.Header(), system)
+		if blockModel.L2 != nil && blockModel.L2.L1Timestamp != nil {
+			a.bc.metricsManager.SetL2SequencerDrift(connector.name, blockModel.L2.SequencerDrift)
+		}
+	}
+
+	return &ChainBlock{Model: blockModel, Raw: raw, RawTxs: len(raw.txs)}, nil
+}
+
+// FetchReceipts 供应量统计需要交易回执中的实际gas用量，L2网络按配置获取回执中的L1数据费用，
+// 启用回执补全时总会获取
+func (a *evmAdapter) FetchReceipts(ctx context.Context, connector *NetworkConnector, block *ChainBlock) error {
+	l2 := l2Type(connector.config)
+	if a.bc.dataProcessor.Supply() == nil && !connector.config.Receipts.Enabled && (l2 == "" || !connector.config.L1Fees) {
+		return nil
+	}
+	// 仅关注模式下未选中任何交易
+	if block.Selected != nil && len(block.Model.Transactions) == 0 {
+		return nil
+	}
+
+	var err error
+	if l2 != "" {
+		err = a.bc.applyL2Receipts(ctx, connector, block.Model)
+	} else {
+		err = a.bc.applyReceipts(ctx, connector, block.Model)
+	}
+	if err != nil {
+		return err
+	}
+	block.Receipts = true
+	return nil
+}
+
+// DecodeTx 转换交易，存款交易及Arbitrum系统交易的发起方取节点返回的from
+func (a *evmAdapter) DecodeTx(connector *NetworkConnector, block *ChainBlock, index int) (*models.Transaction, error) {
+	raw := block.Raw.(*evmBlock)
+	entry := raw.txs[index]
+	if entry.system != nil {
+		return convertL2Transaction(entry.system, raw.block, connector.name, l2Type(connector.config)), nil
+	}
+	return a.bc.convertToTransactionModel(entry.tx, raw.block, uint(index), connector.name), nil
+}
+
+// EnrichBlock 供应量、gas统计、日志过滤及各类链上行为检测
+func (a *evmAdapter) EnrichBlock(ctx context.Context, connector *NetworkConnector, block *ChainBlock, enriched *models.EnrichedBlock) {
+	bc := a.bc
+	blockModel := block.Model
+	evm := block.Raw.(*evmBlock)
+	raw := evm.block
+
+	if block.Receipts && bc.dataProcessor.Supply() != nil {
+		supply, err := bc.dataProcessor.RecordBlockSupply(blockModel)
+		if err != nil {
+			bc.reportError(err, connector.name, blockModel.Number, "Failed to record supply")
+			bc.metricsManager.IncrementError(connector.name, "supply_error")
+		}
+		enriched.Supply = supply
+	}
+
+	if bc.dataProcessor.GasOracle() != nil {
+		gas, err := bc.dataProcessor.RecordGasStats(blockModel)
+		if err != nil {
+			faults.Log(err, connector.name, blockModel.Number, "Failed to publish gas stats")
+		}
+		enriched.Gas = gas
+	}
+
+	// 日志过滤模式下处理关注的合约日志
+	if connector.logFilter != nil {
+		stageStart := time.Now()
+		events, err := bc.processFilteredLogs(ctx, connector, raw, evm.receipts)
+		bc.recordStage(connector.name, processor.PipelineStageLogFilter, stageStart, err)
+		if err != nil {
+			bc.reportError(err, connector.name, blockModel.Number, "Failed to process logs")
+			bc.metricsManager.IncrementError(connector.name, "log_filter_error")
+		}
+		enriched.Events = events
+	}
+
+	// 检测闪电贷，内存降载时跳过；仅关注模式下区块不涉及关注地址或事件时跳过，下同
+	if bc.flashLoans != nil && !block.SkipLogs && !bc.shedding(watchdog.LevelShedEnrichment) {
+		stageStart := time.Now()
+		enriched.Alerts = append(enriched.Alerts, bc.processFlashLoans(ctx, connector, raw)...)
+		bc.recordStage(connector.name, processor.PipelineStageFlashLoans, stageStart, nil)
+	}
+
+	// 授权盗取检测、代币流向汇总、关注地址转账告警、稳定币铸造/销毁监控及地址聚类，内存降载时跳过
+	if bc.tokenLogsEnabled() && !block.SkipLogs && !bc.shedding(watchdog.LevelShedEnrichment) {
+		stageStart := time.Now()
+		enriched.Alerts = append(enriched.Alerts, bc.processTokenLogs(ctx, connector, raw, blockModel)...)
+		bc.recordStage(connector.name, processor.PipelineStageTokenLogs, stageStart, nil)
+	}
+
+	// 代币跑路/貔貅检测及关注地址与可疑代币的交互，内存降载时跳过
+	if bc.tokenRiskEnabled(connector) && !block.SkipLogs && !bc.shedding(watchdog.LevelShedEnrichment) {
+		stageStart := time.Now()
+		enriched.Alerts = append(enriched.Alerts, bc.processTokenRisk(ctx, connector, raw, blockModel)...)
+		bc.recordStage(connector.name, processor.PipelineStageTokenRisk, stageStart, nil)
+	}
+
+	// 跨链桥存入/到账解码及关联，漏掉存入会使对应的到账被误判为盗取，内存降载时也不跳过
+	if bc.bridgesEnabled(connector) {
+		stageStart := time.Now()
+		enriched.Alerts = append(enriched.Alerts, bc.processBridgeLogs(ctx, connector, raw, blockModel)...)
+		bc.recordStage(connector.name, processor.PipelineStageBridges, stageStart, nil)
+	}
+
+	// 余额清空检测，内存降载时跳过并丢弃待检查的地址
+	if bc.dataProcessor.Velocity() != nil {
+		if bc.shedding(watchdog.LevelShedEnrichment) {
+			bc.dataProcessor.Velocity().TakeDrainCandidates(connector.name)
+		} else {
+			stageStart := time.Now()
+			enriched.Alerts = append(enriched.Alerts, bc.processBalanceDrains(ctx, connector, raw.Number())...)
+			bc.recordStage(connector.name, processor.PipelineStageBalanceDrains, stageStart, nil)
+		}
+	}
+
+	// 关注地址余额跟踪，内存降载时跳过，由定期查询补齐
+	if bc.watchBalancesEnabled(connector) && !bc.shedding(watchdog.LevelShedEnrichment) {
+		stageStart := time.Now()
+		enriched.Alerts = append(enriched.Alerts, bc.processWatchedBalances(ctx, connector, raw, blockModel)...)
+		bc.recordStage(connector.name, processor.PipelineStageWatchBalances, stageStart, nil)
+	}
+}
+
+// StartServices 关注日志回填、日志订阅及关注地址余额的定期查询
+func (a *evmAdapter) StartServices(ctx context.Context, connector *NetworkConnector, start uint64) {
+	bc := a.bc
+
+	// 实时处理从最新区块（或start_block）开始，之前的关注日志通过eth_getLogs回填（仅实时模式下跳过）
+	if connector.logFilter != nil && bc.logBackfill != nil && bc.runMode() != runModeRealtime {
+		bc.wg.Add(1)
+		go bc.backfillLogs(ctx, connector, start)
+	}
+
+	// 日志订阅会推送链重组撤回的日志(Removed=true)
+	if connector.wsClient != nil && connector.realtime() && connector.logFilter != nil {
+		bc.wg.Add(1)
+		go bc.subscribeToLogs(ctx, connector)
+	}
+
+	if bc.watchBalancesEnabled(connector) {
+		bc.wg.Add(1)
+		go bc.pollWatchedBalances(ctx, connector)
+	}
+}
+
+// Backfill 回填start及之前关注的合约日志
+func (a *evmAdapter) Backfill(ctx context.Context, connector *NetworkConnector, start uint64) {
+	if connector.logFilter != nil && a.bc.logBackfill != nil {
+		a.bc.runLogBackfill(ctx, connector, start)
+	}
+}
+
+// headerSummary 由订阅推送的区块头构造摘要
+func headerSummary(network string, header *types.Header, observedAt time.Time) *models.BlockHeader {
+	return &models.BlockHeader{
+		Network:       network,
+		Number:        header.Number.Uint64(),
+		Hash:          header.Hash().Hex(),
+		ParentHash:    header.ParentHash.Hex(),
+		Timestamp:     time.Unix(int64(header.Time), 0),
+		GasUsed:       header.GasUsed,
+		GasLimit:      header.GasLimit,
+		Miner:         header.Coinbase.Hex(),
+		BaseFeePerGas: header.BaseFee,
+		ObservedAt:    observedAt,
+	}
+}
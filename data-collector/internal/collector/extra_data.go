@@ -0,0 +1,134 @@
+package collector
+
+import (
+	"encoding/binary"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/clique"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Clique extraData布局：32字节vanity + 检查点签名者列表 + 65字节签名
+const (
+	cliqueVanityLength = 32
+	cliqueSealLength   = crypto.SignatureLength
+)
+
+// knownExtraDataFormats 未配置extra_data_format时按chain_id选择的解码格式
+var knownExtraDataFormats = map[int64]string{
+	1:        models.ExtraDataBuilder,  // Ethereum
+	17000:    models.ExtraDataBuilder,  // Holesky
+	11155111: models.ExtraDataBuilder,  // Sepolia
+	5:        models.ExtraDataClique,   // Goerli
+	42161:    models.ExtraDataArbitrum, // Arbitrum One
+	42170:    models.ExtraDataArbitrum, // Arbitrum Nova
+	10:       models.ExtraDataOptimism, // OP Mainnet
+	8453:     models.ExtraDataOptimism, // Base
+}
+
+// extraDataFormat 获取网络的extraData解码格式
+func extraDataFormat(cfg config.NetworkConfig) string {
+	if cfg.ExtraDataFormat != "" {
+		return cfg.ExtraDataFormat
+	}
+	if format, known := knownExtraDataFormats[cfg.ChainID]; known {
+		return format
+	}
+	return models.ExtraDataNone
+}
+
+// decodeExtraData 按格式解码区块头extraData，格式为none时返回nil
+func decodeExtraData(format string, header *types.Header) *models.BlockExtraData {
+	extra := header.Extra
+
+	switch format {
+	case models.ExtraDataBuilder:
+		if len(extra) == 0 {
+			return nil
+		}
+		if tag, ok := printableText(extra); ok {
+			return &models.BlockExtraData{Format: format, BuilderTag: tag}
+		}
+	case models.ExtraDataClique:
+		if cliqueExtra := decodeCliqueExtra(header); cliqueExtra != nil {
+			return &models.BlockExtraData{Format: format, Clique: cliqueExtra}
+		}
+	case models.ExtraDataArbitrum:
+		// mixHash前24字节依次为sendCount、L1区块号、ArbOS版本
+		if len(extra) == common.HashLength {
+			mix := header.MixDigest.Bytes()
+			return &models.BlockExtraData{Format: format, Arbitrum: &models.ArbitrumExtra{
+				SendRoot:      common.BytesToHash(extra).Hex(),
+				SendCount:     binary.BigEndian.Uint64(mix[0:8]),
+				L1BlockNumber: binary.BigEndian.Uint64(mix[8:16]),
+				ArbOSVersion:  binary.BigEndian.Uint64(mix[16:24]),
+			}}
+		}
+	case models.ExtraDataOptimism:
+		// Holocene之前extraData为空
+		if len(extra) == 0 {
+			return nil
+		}
+		if len(extra) == 9 && extra[0] == 0 {
+			return &models.BlockExtraData{Format: format, Optimism: &models.OptimismExtra{
+				Version:            extra[0],
+				EIP1559Denominator: binary.BigEndian.Uint32(extra[1:5]),
+				EIP1559Elasticity:  binary.BigEndian.Uint32(extra[5:9]),
+			}}
+		}
+	default:
+		return nil
+	}
+
+	return &models.BlockExtraData{Format: format, Raw: hexutil.Encode(extra)}
+}
+
+// decodeCliqueExtra 解析vanity与签名者列表，并由签名恢复出块者
+func decodeCliqueExtra(header *types.Header) *models.CliqueExtra {
+	extra := header.Extra
+	if len(extra) < cliqueVanityLength+cliqueSealLength {
+		return nil
+	}
+
+	signersBytes := extra[cliqueVanityLength : len(extra)-cliqueSealLength]
+	if len(signersBytes)%common.AddressLength != 0 {
+		return nil
+	}
+
+	result := &models.CliqueExtra{}
+	if vanity, ok := printableText(extra[:cliqueVanityLength]); ok {
+		result.Vanity = vanity
+	}
+	for i := 0; i < len(signersBytes); i += common.AddressLength {
+		result.Signers = append(result.Signers, common.BytesToAddress(signersBytes[i:i+common.AddressLength]).Hex())
+	}
+
+	signature := extra[len(extra)-cliqueSealLength:]
+	if pubkey, err := crypto.SigToPub(clique.SealHash(header).Bytes(), signature); err == nil {
+		result.Signer = crypto.PubkeyToAddress(*pubkey).Hex()
+	}
+
+	return result
+}
+
+// printableText 去除填充后为可打印UTF-8文本时返回文本
+func printableText(data []byte) (string, bool) {
+	text := strings.TrimRight(string(data), "\x00")
+	if text == "" || !utf8.ValidString(text) {
+		return "", false
+	}
+	for _, r := range text {
+		if !unicode.IsPrint(r) {
+			return "", false
+		}
+	}
+	return strings.TrimSpace(text), true
+}
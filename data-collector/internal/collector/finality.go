@@ -0,0 +1,69 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"web3-data-collector/internal/models"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// blockTag 网络配置的区块标签，未配置时为latest
+func (nc *NetworkConnector) blockTag() string {
+	if nc.config.BlockTag == "" {
+		return models.FinalityLatest
+	}
+	return nc.config.BlockTag
+}
+
+// tagged 是否只处理safe/finalized区块。此时不订阅新区块头及日志（推送的是未确认数据），
+// 由轮询按标签区块号推进
+func (nc *NetworkConnector) tagged() bool {
+	return nc.solana == nil && nc.blockTag() != models.FinalityLatest
+}
+
+// finality 发布数据标注的确认状态，Solana网络为确认级别
+func (nc *NetworkConnector) finality() string {
+	if nc.solana != nil {
+		return nc.solana.commitment
+	}
+	return nc.blockTag()
+}
+
+// getTargetBlockNumber 获取可处理到的区块号：latest标签为链头减去要求的确认数，safe/finalized以标签查询
+func (nc *NetworkConnector) getTargetBlockNumber(ctx context.Context, latest uint64) (uint64, error) {
+	if !nc.tagged() {
+		if latest < nc.config.Confirmations {
+			return 0, nil
+		}
+		return latest - nc.config.Confirmations, nil
+	}
+	if nc.rpcClient == nil {
+		return 0, fmt.Errorf("no RPC client available")
+	}
+
+	number := big.NewInt(int64(rpc.FinalizedBlockNumber))
+	if nc.blockTag() == models.FinalitySafe {
+		number = big.NewInt(int64(rpc.SafeBlockNumber))
+	}
+
+	nc.recordCall("eth_getBlockByNumber")
+	header, err := nc.rpcClient.HeaderByNumber(ctx, number)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get %s block: %w", nc.blockTag(), err)
+	}
+	return header.Number.Uint64(), nil
+}
+
+// labelFinality 为区块及其交易、提款标注确认状态
+func labelFinality(block *models.Block, finality string) {
+	block.Finality = finality
+	for i := range block.Transactions {
+		block.Transactions[i].Finality = finality
+	}
+	for i := range block.Withdrawals {
+		block.Withdrawals[i].Finality = finality
+	}
+}
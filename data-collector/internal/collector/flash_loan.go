@@ -0,0 +1,275 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// 闪电贷事件签名
+var (
+	aaveV2FlashLoanTopic   = crypto.Keccak256Hash([]byte("FlashLoan(address,address,address,uint256,uint256,uint16)"))
+	aaveV3FlashLoanTopic   = crypto.Keccak256Hash([]byte("FlashLoan(address,address,address,uint256,uint8,uint256,uint16)"))
+	balancerFlashLoanTopic = crypto.Keccak256Hash([]byte("FlashLoan(address,address,uint256,uint256)"))
+	uniswapV3FlashTopic    = crypto.Keccak256Hash([]byte("Flash(address,address,uint256,uint256,uint256,uint256)"))
+	// Uniswap V2闪电兑换没有专门的事件，只能通过交易追踪中的回调识别
+	uniswapV2SwapTopic = crypto.Keccak256Hash([]byte("Swap(address,uint256,uint256,uint256,uint256,address)"))
+)
+
+// uniswapV2CallSelector Uniswap V2池在闪电兑换中回调借款合约的函数
+var uniswapV2CallSelector = hexutil.Encode(crypto.Keccak256([]byte("uniswapV2Call(address,uint256,uint256,bytes)"))[:4])
+
+// flashLoanDetector 通过回执事件及交易追踪识别闪电贷
+type flashLoanDetector struct {
+	useTraces bool
+}
+
+// newFlashLoanDetector 根据配置创建闪电贷检测器，未启用时返回nil
+func newFlashLoanDetector(cfg config.FlashLoanConfig) *flashLoanDetector {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &flashLoanDetector{useTraces: cfg.UseTraces}
+}
+
+// mayContain 根据logsBloom判断是否可能包含闪电贷
+func (fd *flashLoanDetector) mayContain(bloom types.Bloom) bool {
+	topics := []common.Hash{aaveV2FlashLoanTopic, aaveV3FlashLoanTopic, balancerFlashLoanTopic, uniswapV3FlashTopic}
+	if fd.useTraces {
+		topics = append(topics, uniswapV2SwapTopic)
+	}
+
+	for _, topic := range topics {
+		if types.BloomLookup(bloom, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectFlashLoans 检测区块中的闪电贷，按交易哈希分组返回
+func (bc *BlockchainCollector) detectFlashLoans(ctx context.Context, connector *NetworkConnector, block *types.Block) (map[string][]*models.FlashLoan, error) {
+	if !bc.flashLoans.mayContain(block.Bloom()) {
+		return nil, nil
+	}
+
+	loans := make(map[string][]*models.FlashLoan)
+	for _, tx := range block.Transactions() {
+		receipt, err := connector.getTransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			return loans, fmt.Errorf("failed to get receipt for %s: %w", tx.Hash().Hex(), err)
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful || !bc.flashLoans.mayContain(receipt.Bloom) {
+			continue
+		}
+
+		found, hasV2Swap := decodeFlashLoanLogs(receipt.Logs)
+
+		// 含Uniswap V2兑换的交易通过追踪确认是否为闪电兑换
+		if bc.flashLoans.useTraces && hasV2Swap {
+			traced, err := bc.traceFlashSwaps(ctx, connector, tx.Hash())
+			if err != nil {
+				logger.Warnf("Failed to trace %s on %s: %v", tx.Hash().Hex(), connector.name, err)
+				bc.metricsManager.IncrementError(connector.name, "trace_error")
+			}
+			found = append(found, traced...)
+		}
+
+		// L2网络的系统及存款交易不在block.Transactions()中，交易ID按回执中区块内的位置生成，与交易记录一致
+		for _, loan := range found {
+			loan.TransactionHash = tx.Hash().Hex()
+			loan.TransactionID = models.TransactionID(connector.name, block.Hash().Hex(), receipt.TransactionIndex)
+			loan.BlockNumber = block.NumberU64()
+			loan.Network = connector.name
+		}
+		if len(found) > 0 {
+			loans[tx.Hash().Hex()] = found
+		}
+	}
+
+	return loans, nil
+}
+
+// processFlashLoans 检测区块中的闪电贷并按交易发送告警，返回生成的告警
+func (bc *BlockchainCollector) processFlashLoans(ctx context.Context, connector *NetworkConnector, block *types.Block) []*models.RiskAlert {
+	loans, err := bc.detectFlashLoans(ctx, connector, block)
+	if err != nil {
+		logger.Errorf("Failed to detect flash loans in block %d for %s: %v", block.NumberU64(), connector.name, err)
+		bc.metricsManager.IncrementError(connector.name, "flash_loan_error")
+	}
+
+	var alerts []*models.RiskAlert
+	for txHash, txLoans := range loans {
+		alert, err := bc.dataProcessor.ProcessFlashLoans(txLoans)
+		if err != nil {
+			logger.Errorf("Failed to publish flash loan alert for %s: %v", txHash, err)
+			continue
+		}
+		alerts = append(alerts, alert)
+	}
+
+	return alerts
+}
+
+// decodeFlashLoanLogs 解码回执中的闪电贷事件，同时返回是否包含Uniswap V2兑换
+func decodeFlashLoanLogs(logs []*types.Log) ([]*models.FlashLoan, bool) {
+	var loans []*models.FlashLoan
+	hasV2Swap := false
+
+	for _, log := range logs {
+		if len(log.Topics) == 0 {
+			continue
+		}
+
+		switch log.Topics[0] {
+		case aaveV2FlashLoanTopic:
+			// topics: target, initiator, asset; data: amount, premium, referralCode
+			if len(log.Topics) < 4 || len(log.Data) < 64 {
+				continue
+			}
+			loans = append(loans, &models.FlashLoan{
+				Protocol: models.FlashLoanAaveV2,
+				Lender:   log.Address.Hex(),
+				Receiver: topicAddress(log.Topics[1]),
+				Asset:    topicAddress(log.Topics[3]),
+				Amount:   dataWord(log.Data, 0),
+				Premium:  dataWord(log.Data, 1),
+				Source:   "event",
+			})
+		case aaveV3FlashLoanTopic:
+			// topics: target, asset, referralCode; data: initiator, amount, interestRateMode, premium
+			if len(log.Topics) < 3 || len(log.Data) < 128 {
+				continue
+			}
+			loans = append(loans, &models.FlashLoan{
+				Protocol: models.FlashLoanAaveV3,
+				Lender:   log.Address.Hex(),
+				Receiver: topicAddress(log.Topics[1]),
+				Asset:    topicAddress(log.Topics[2]),
+				Amount:   dataWord(log.Data, 1),
+				Premium:  dataWord(log.Data, 3),
+				Source:   "event",
+			})
+		case balancerFlashLoanTopic:
+			// topics: recipient, token; data: amount, feeAmount
+			if len(log.Topics) < 3 || len(log.Data) < 64 {
+				continue
+			}
+			loans = append(loans, &models.FlashLoan{
+				Protocol: models.FlashLoanBalancer,
+				Lender:   log.Address.Hex(),
+				Receiver: topicAddress(log.Topics[1]),
+				Asset:    topicAddress(log.Topics[2]),
+				Amount:   dataWord(log.Data, 0),
+				Premium:  dataWord(log.Data, 1),
+				Source:   "event",
+			})
+		case uniswapV3FlashTopic:
+			// topics: sender, recipient; data: amount0, amount1, paid0, paid1
+			if len(log.Topics) < 3 || len(log.Data) < 128 {
+				continue
+			}
+			for i, asset := range []string{"token0", "token1"} {
+				amount := dataWord(log.Data, i)
+				if amount.Sign() == 0 {
+					continue
+				}
+				paid := dataWord(log.Data, i+2)
+				loans = append(loans, &models.FlashLoan{
+					Protocol: models.FlashLoanUniswapV3,
+					Lender:   log.Address.Hex(),
+					Receiver: topicAddress(log.Topics[2]),
+					Asset:    asset,
+					Amount:   amount,
+					Premium:  paid,
+					Source:   "event",
+				})
+			}
+		case uniswapV2SwapTopic:
+			hasV2Swap = true
+		}
+	}
+
+	return loans, hasV2Swap
+}
+
+// callFrame callTracer返回的调用帧
+type callFrame struct {
+	From  string      `json:"from"`
+	To    string      `json:"to"`
+	Input string      `json:"input"`
+	Calls []callFrame `json:"calls"`
+}
+
+// traceFlashSwaps 追踪交易调用树，识别Uniswap V2池对借款合约的uniswapV2Call回调
+func (bc *BlockchainCollector) traceFlashSwaps(ctx context.Context, connector *NetworkConnector, txHash common.Hash) ([]*models.FlashLoan, error) {
+	var root callFrame
+	if err := connector.traceTransaction(ctx, txHash, &root); err != nil {
+		return nil, err
+	}
+
+	var loans []*models.FlashLoan
+	var walk func(frame *callFrame)
+	walk = func(frame *callFrame) {
+		if len(frame.Input) >= 10 && frame.Input[:10] == uniswapV2CallSelector {
+			// uniswapV2Call(sender, amount0, amount1, data)
+			if data, err := hexutil.Decode(frame.Input); err == nil && len(data) >= 4+96 {
+				args := data[4:]
+				for i, asset := range []string{"token0", "token1"} {
+					amount := dataWord(args, i+1)
+					if amount.Sign() == 0 {
+						continue
+					}
+					loans = append(loans, &models.FlashLoan{
+						Protocol: models.FlashLoanUniswapV2,
+						Lender:   common.HexToAddress(frame.From).Hex(),
+						Receiver: common.HexToAddress(frame.To).Hex(),
+						Asset:    asset,
+						Amount:   amount,
+						Source:   "trace",
+					})
+				}
+			}
+		}
+		for i := range frame.Calls {
+			walk(&frame.Calls[i])
+		}
+	}
+	walk(&root)
+
+	return loans, nil
+}
+
+// topicAddress 从indexed参数中取地址
+func topicAddress(topic common.Hash) string {
+	return common.BytesToAddress(topic.Bytes()).Hex()
+}
+
+// dataWord 读取第index个32字节数据
+func dataWord(data []byte, index int) *big.Int {
+	start := index * 32
+	if start+32 > len(data) {
+		return big.NewInt(0)
+	}
+	return new(big.Int).SetBytes(data[start : start+32])
+}
+
+// traceTransaction 通过debug_traceTransaction获取交易调用树
+func (nc *NetworkConnector) traceTransaction(ctx context.Context, txHash common.Hash, result interface{}) error {
+	if nc.rpcClient == nil {
+		return fmt.Errorf("no RPC client available")
+	}
+
+	nc.recordCall("debug_traceTransaction")
+	return nc.rpcClient.Client().CallContext(ctx, result, "debug_traceTransaction", txHash, map[string]interface{}{
+		"tracer": "callTracer",
+	})
+}
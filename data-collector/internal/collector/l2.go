@@ -0,0 +1,437 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// OP Stack存款交易及Arbitrum系统交易的类型，go-ethereum无法解码
+const (
+	optimismDepositTxType       = 0x7e
+	arbitrumDepositTxType       = 0x64
+	arbitrumUnsignedTxType      = 0x65
+	arbitrumContractTxType      = 0x66
+	arbitrumRetryTxType         = 0x68
+	arbitrumSubmitRetryableType = 0x69
+	arbitrumInternalTxType      = 0x6a
+)
+
+// OP Stack L1属性交易（每个区块第一笔存款交易）的函数选择器
+var (
+	setL1BlockValuesBedrock = []byte{0x01, 0x5d, 0x8e, 0xb9} // setL1BlockValues(uint64,uint64,uint256,bytes32,uint64,bytes32,uint256,uint256)
+	setL1BlockValuesEcotone = []byte{0x44, 0x0a, 0x5e, 0x20} // setL1BlockValuesEcotone()，参数紧凑编码
+	setL1BlockValuesIsthmus = []byte{0x09, 0x89, 0x99, 0xbe} // setL1BlockValuesIsthmus()，在Ecotone布局后追加operator fee参数
+)
+
+// arbitrumTxKinds Arbitrum系统交易类型名
+var arbitrumTxKinds = map[uint64]string{
+	arbitrumDepositTxType:       models.ArbitrumDepositTx,
+	arbitrumUnsignedTxType:      models.ArbitrumUnsignedTx,
+	arbitrumContractTxType:      models.ArbitrumContractTx,
+	arbitrumRetryTxType:         models.ArbitrumRetryTx,
+	arbitrumSubmitRetryableType: models.ArbitrumSubmitTx,
+	arbitrumInternalTxType:      models.ArbitrumInternalTx,
+}
+
+// l2Type 网络的L2类型，沿用extraData解码格式（按chain_id选择或由extra_data_format指定），非L2网络为空
+func l2Type(cfg config.NetworkConfig) string {
+	switch extraDataFormat(cfg) {
+	case models.ExtraDataArbitrum:
+		return models.L2Arbitrum
+	case models.ExtraDataOptimism:
+		return models.L2Optimism
+	}
+	return ""
+}
+
+// l2Transaction 节点返回的L2系统交易，包含OP Stack存款交易及Arbitrum系统交易的扩展字段
+type l2Transaction struct {
+	Hash     common.Hash     `json:"hash"`
+	Type     hexutil.Uint64  `json:"type"`
+	From     common.Address  `json:"from"`
+	To       *common.Address `json:"to"`
+	Value    *hexutil.Big    `json:"value"`
+	Gas      hexutil.Uint64  `json:"gas"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+	Nonce    hexutil.Uint64  `json:"nonce"`
+	Input    hexutil.Bytes   `json:"input"`
+	Index    hexutil.Uint    `json:"transactionIndex"`
+	// OP Stack存款交易
+	SourceHash *common.Hash `json:"sourceHash"`
+	Mint       *hexutil.Big `json:"mint"`
+	IsSystemTx bool         `json:"isSystemTx"`
+	// Arbitrum系统交易
+	RequestID           *common.Hash    `json:"requestId"`
+	TicketID            *common.Hash    `json:"ticketId"`
+	RetryTo             *common.Address `json:"retryTo"`
+	RetryValue          *hexutil.Big    `json:"retryValue"`
+	DepositValue        *hexutil.Big    `json:"depositValue"`
+	MaxSubmissionFee    *hexutil.Big    `json:"maxSubmissionFee"`
+	SubmissionFeeRefund *hexutil.Big    `json:"submissionFeeRefund"`
+	MaxRefund           *hexutil.Big    `json:"maxRefund"`
+	L1BaseFee           *hexutil.Big    `json:"l1BaseFee"`
+	Beneficiary         *common.Address `json:"beneficiary"`
+	RefundTo            *common.Address `json:"refundTo"`
+}
+
+// l2Receipt 节点返回的回执中L2特有的字段
+type l2Receipt struct {
+	TransactionHash   common.Hash    `json:"transactionHash"`
+	GasUsed           hexutil.Uint64 `json:"gasUsed"`
+	EffectiveGasPrice *hexutil.Big   `json:"effectiveGasPrice"`
+	Status            hexutil.Uint64 `json:"status"`
+	Logs              []*types.Log   `json:"logs"`
+	// OP Stack
+	L1GasUsed           *hexutil.Big    `json:"l1GasUsed"`
+	L1GasPrice          *hexutil.Big    `json:"l1GasPrice"`
+	L1Fee               *hexutil.Big    `json:"l1Fee"`
+	L1FeeScalar         string          `json:"l1FeeScalar"`
+	L1BlobBaseFee       *hexutil.Big    `json:"l1BlobBaseFee"`
+	L1BaseFeeScalar     *hexutil.Uint64 `json:"l1BaseFeeScalar"`
+	L1BlobBaseFeeScalar *hexutil.Uint64 `json:"l1BlobBaseFeeScalar"`
+	// Arbitrum
+	GasUsedForL1  *hexutil.Uint64 `json:"gasUsedForL1"`
+	L1BlockNumber *hexutil.Uint64 `json:"l1BlockNumber"`
+}
+
+// getL2Block 以原始JSON获取L2区块：go-ethereum可解码的交易组成区块，
+// 存款交易及Arbitrum系统交易单独返回，交易位置由transactionIndex给出
+func (nc *NetworkConnector) getL2Block(ctx context.Context, number uint64) (*types.Block, []*l2Transaction, error) {
+	nc.recordCall("eth_getBlockByNumber")
+	var raw json.RawMessage
+	err := nc.rpcClient.Client().CallContext(ctx, &raw, "eth_getBlockByNumber", hexutil.EncodeUint64(number), true)
+	if err != nil {
+		return nil, nil, nc.rpcError("eth_getBlockByNumber", number, err)
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil, nc.rpcError("eth_getBlockByNumber", number, fmt.Errorf("block not found"))
+	}
+
+	header := new(types.Header)
+	if err := json.Unmarshal(raw, header); err != nil {
+		return nil, nil, nc.rpcError("eth_getBlockByNumber", number, err)
+	}
+	var body struct {
+		Transactions []json.RawMessage   `json:"transactions"`
+		Withdrawals  []*types.Withdrawal `json:"withdrawals"`
+	}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return nil, nil, nc.rpcError("eth_getBlockByNumber", number, err)
+	}
+
+	var txs []*types.Transaction
+	var systemTxs []*l2Transaction
+	for _, rawTx := range body.Transactions {
+		tx := new(types.Transaction)
+		err := tx.UnmarshalJSON(rawTx)
+		if err == nil {
+			txs = append(txs, tx)
+			continue
+		}
+		if !errors.Is(err, types.ErrTxTypeNotSupported) {
+			return nil, nil, nc.rpcError("eth_getBlockByNumber", number, err)
+		}
+		systemTx := new(l2Transaction)
+		if err := json.Unmarshal(rawTx, systemTx); err != nil {
+			return nil, nil, nc.rpcError("eth_getBlockByNumber", number, err)
+		}
+		systemTxs = append(systemTxs, systemTx)
+	}
+
+	block := types.NewBlockWithHeader(header).WithBody(txs, nil)
+	if body.Withdrawals != nil {
+		block = block.WithWithdrawals(body.Withdrawals)
+	}
+	return block, systemTxs, nil
+}
+
+// l2Receipts 以原始JSON获取区块内交易的回执，保留L1费用字段
+func (nc *NetworkConnector) l2Receipts(ctx context.Context, blockNumber uint64, txHashes []common.Hash) ([]*l2Receipt, error) {
+	if nc.rpcClient == nil {
+		return nil, fmt.Errorf("no RPC client available")
+	}
+
+	if nc.receiptStrategy() == receiptStrategyBlock {
+		nc.recordCall("eth_getBlockReceipts")
+		var receipts []*l2Receipt
+		err := nc.rpcClient.Client().CallContext(ctx, &receipts, "eth_getBlockReceipts", hexutil.EncodeUint64(blockNumber))
+		return receipts, nc.rpcError("eth_getBlockReceipts", blockNumber, err)
+	}
+
+	receipts := make([]*l2Receipt, len(txHashes))
+	results := make([]interface{}, len(txHashes))
+	for i := range receipts {
+		results[i] = &receipts[i]
+	}
+	if err := nc.batchReceipts(ctx, blockNumber, txHashes, results); err != nil {
+		return nil, err
+	}
+	for i, receipt := range receipts {
+		if receipt == nil {
+			return nil, fmt.Errorf("receipt for %s not found", txHashes[i].Hex())
+		}
+	}
+	return receipts, nil
+}
+
+// convertL2Transaction 转换存款交易及Arbitrum系统交易，这些交易没有签名，发起方取节点返回的from
+func convertL2Transaction(raw *l2Transaction, block *types.Block, network, l2 string) *models.Transaction {
+	txModel := &models.Transaction{
+		ID:               models.TransactionID(network, block.Hash().Hex(), uint(raw.Index)),
+		Hash:             raw.Hash.Hex(),
+		BlockNumber:      block.NumberU64(),
+		BlockHash:        block.Hash().Hex(),
+		TransactionIndex: uint(raw.Index),
+		FromAddress:      raw.From.Hex(),
+		Value:            bigOrZero(raw.Value),
+		Gas:              uint64(raw.Gas),
+		GasPrice:         bigOrZero(raw.GasPrice),
+		Nonce:            uint64(raw.Nonce),
+		Timestamp:        time.Unix(int64(block.Time()), 0),
+		Network:          network,
+		TransactionType:  uint8(raw.Type),
+		L2:               &models.L2TransactionInfo{Type: l2},
+	}
+	if raw.To != nil {
+		txModel.ToAddress = raw.To.Hex()
+	}
+	if len(raw.Input) > 0 {
+		txModel.InputData = hexutil.Encode(raw.Input)
+		txModel.IsContractCall = txModel.ToAddress != ""
+	}
+
+	info := txModel.L2
+	if uint64(raw.Type) == optimismDepositTxType {
+		info.Deposit = &models.OptimismDeposit{
+			IsSystemTx: raw.IsSystemTx,
+			Mint:       bigOrNil(raw.Mint),
+		}
+		if raw.SourceHash != nil {
+			info.Deposit.SourceHash = raw.SourceHash.Hex()
+		}
+		return txModel
+	}
+
+	info.ArbitrumKind = arbitrumTxKinds[uint64(raw.Type)]
+	if raw.RequestID != nil {
+		info.RequestID = raw.RequestID.Hex()
+	}
+	switch uint64(raw.Type) {
+	case arbitrumSubmitRetryableType:
+		// 提交交易本身不执行调用，票据在同一区块或之后由兑付交易执行
+		info.Retryable = &models.ArbitrumRetryable{
+			TicketID:         raw.Hash.Hex(),
+			Value:            bigOrNil(raw.RetryValue),
+			DepositValue:     bigOrNil(raw.DepositValue),
+			MaxSubmissionFee: bigOrNil(raw.MaxSubmissionFee),
+			L1BaseFee:        bigOrNil(raw.L1BaseFee),
+			Beneficiary:      addressOrEmpty(raw.Beneficiary),
+			RefundTo:         addressOrEmpty(raw.RefundTo),
+			To:               addressOrEmpty(raw.RetryTo),
+		}
+	case arbitrumRetryTxType:
+		info.Retryable = &models.ArbitrumRetryable{
+			To:                  txModel.ToAddress,
+			Value:               txModel.Value,
+			MaxRefund:           bigOrNil(raw.MaxRefund),
+			SubmissionFeeRefund: bigOrNil(raw.SubmissionFeeRefund),
+			RefundTo:            addressOrEmpty(raw.RefundTo),
+		}
+		if raw.TicketID != nil {
+			info.Retryable.TicketID = raw.TicketID.Hex()
+		}
+	}
+	return txModel
+}
+
+// decodeL2Block 解析L2区块对应的L1起源：OP Stack取自区块第一笔L1属性交易，Arbitrum取自区块头mixHash
+func decodeL2Block(l2 string, header *types.Header, systemTxs []*l2Transaction) *models.L2BlockInfo {
+	switch l2 {
+	case models.L2Arbitrum:
+		// mixHash前24字节依次为sendCount、L1区块号、ArbOS版本
+		return &models.L2BlockInfo{
+			Type:          l2,
+			L1BlockNumber: binary.BigEndian.Uint64(header.MixDigest.Bytes()[8:16]),
+		}
+	case models.L2Optimism:
+		for _, tx := range systemTxs {
+			if uint64(tx.Type) != optimismDepositTxType || tx.Index != 0 {
+				continue
+			}
+			info := decodeL1Attributes(tx.Input)
+			if info == nil {
+				return nil
+			}
+			info.Type = l2
+			if info.L1Timestamp != nil {
+				info.SequencerDrift = float64(header.Time) - float64(info.L1Timestamp.Unix())
+			}
+			return info
+		}
+	}
+	return nil
+}
+
+// decodeL1Attributes 解码OP Stack L1属性交易的调用数据，格式未知时返回nil
+func decodeL1Attributes(input []byte) *models.L2BlockInfo {
+	if len(input) < 4 {
+		return nil
+	}
+
+	selector := input[:4]
+	switch {
+	case (bytes.Equal(selector, setL1BlockValuesEcotone) || bytes.Equal(selector, setL1BlockValuesIsthmus)) && len(input) >= 164:
+		// 紧凑编码：baseFeeScalar(4) blobBaseFeeScalar(4) sequenceNumber(8) timestamp(8) number(8)
+		// basefee(32) blobBaseFee(32) hash(32) batcherHash(32)
+		timestamp := time.Unix(int64(binary.BigEndian.Uint64(input[20:28])), 0)
+		return &models.L2BlockInfo{
+			SequenceNumber: binary.BigEndian.Uint64(input[12:20]),
+			L1Timestamp:    &timestamp,
+			L1BlockNumber:  binary.BigEndian.Uint64(input[28:36]),
+			L1BaseFee:      new(big.Int).SetBytes(input[36:68]),
+			L1BlobBaseFee:  new(big.Int).SetBytes(input[68:100]),
+			L1BlockHash:    common.BytesToHash(input[100:132]).Hex(),
+			BatcherHash:    common.BytesToHash(input[132:164]).Hex(),
+		}
+	case bytes.Equal(selector, setL1BlockValuesBedrock) && len(input) >= 4+6*32:
+		// ABI编码：number, timestamp, basefee, hash, sequenceNumber, batcherHash, l1FeeOverhead, l1FeeScalar
+		args := input[4:]
+		word := func(i int) []byte { return args[i*32 : (i+1)*32] }
+		timestamp := time.Unix(new(big.Int).SetBytes(word(1)).Int64(), 0)
+		return &models.L2BlockInfo{
+			L1BlockNumber:  new(big.Int).SetBytes(word(0)).Uint64(),
+			L1Timestamp:    &timestamp,
+			L1BaseFee:      new(big.Int).SetBytes(word(2)),
+			L1BlockHash:    common.BytesToHash(word(3)).Hex(),
+			SequenceNumber: new(big.Int).SetBytes(word(4)).Uint64(),
+			BatcherHash:    common.BytesToHash(word(5)).Hex(),
+		}
+	}
+	return nil
+}
+
+// applyL2Receipts 获取L2回执，填充实际gas用量、执行状态、实际gas单价及L1数据费用，并汇总区块的L1数据费用
+func (bc *BlockchainCollector) applyL2Receipts(ctx context.Context, connector *NetworkConnector, blockModel *models.Block) error {
+	hashes := make([]common.Hash, 0, len(blockModel.Transactions))
+	for i := range blockModel.Transactions {
+		hashes = append(hashes, common.HexToHash(blockModel.Transactions[i].Hash))
+	}
+	receipts, err := connector.l2Receipts(ctx, blockModel.Number, hashes)
+	if err != nil {
+		return err
+	}
+
+	byHash := make(map[string]*l2Receipt, len(receipts))
+	for _, receipt := range receipts {
+		byHash[receipt.TransactionHash.Hex()] = receipt
+	}
+
+	l2 := l2Type(connector.config)
+	l1Fees := new(big.Int)
+	for i := range blockModel.Transactions {
+		tx := &blockModel.Transactions[i]
+		receipt, exists := byHash[tx.Hash]
+		if !exists {
+			return fmt.Errorf("missing receipt for transaction %s", tx.Hash)
+		}
+		tx.GasUsed = uint64(receipt.GasUsed)
+		tx.Status = uint64(receipt.Status)
+		tx.EffectiveGasPrice = bigOrNil(receipt.EffectiveGasPrice)
+		tx.HasReceipt = true
+		if connector.config.Receipts.Logs {
+			tx.Logs = transactionLogs(receipt.Logs)
+		}
+
+		if tx.L2 == nil {
+			tx.L2 = &models.L2TransactionInfo{Type: l2}
+		}
+		applyL2Receipt(tx, receipt)
+		if l2 == models.L2Optimism && tx.L2.L1Fee != nil {
+			l1Fees.Add(l1Fees, tx.L2.L1Fee)
+		}
+	}
+
+	if blockModel.L2 != nil && l2 == models.L2Optimism {
+		blockModel.L2.L1Fees = l1Fees
+	}
+	return nil
+}
+
+// applyL2Receipt 按回执填充交易的L1数据费用及总费用
+func applyL2Receipt(tx *models.Transaction, receipt *l2Receipt) {
+	info := tx.L2
+	executionFee := new(big.Int)
+	if tx.EffectiveGasPrice != nil {
+		executionFee.Mul(tx.EffectiveGasPrice, new(big.Int).SetUint64(tx.GasUsed))
+	}
+
+	switch info.Type {
+	case models.L2Optimism:
+		info.L1Fee = bigOrNil(receipt.L1Fee)
+		info.L1GasPrice = bigOrNil(receipt.L1GasPrice)
+		info.L1BlobBaseFee = bigOrNil(receipt.L1BlobBaseFee)
+		info.L1FeeScalar = receipt.L1FeeScalar
+		if receipt.L1GasUsed != nil {
+			info.L1GasUsed = receipt.L1GasUsed.ToInt().Uint64()
+		}
+		if receipt.L1BaseFeeScalar != nil {
+			info.L1BaseFeeScalar = uint64(*receipt.L1BaseFeeScalar)
+		}
+		if receipt.L1BlobBaseFeeScalar != nil {
+			info.L1BlobBaseFeeScalar = uint64(*receipt.L1BlobBaseFeeScalar)
+		}
+		info.TotalFee = executionFee
+		if info.L1Fee != nil {
+			info.TotalFee.Add(info.TotalFee, info.L1Fee)
+		}
+	case models.L2Arbitrum:
+		// gasUsedForL1已包含在gasUsed中，按实际gas单价折算为L1数据费用
+		if receipt.GasUsedForL1 != nil {
+			info.GasUsedForL1 = uint64(*receipt.GasUsedForL1)
+			if tx.EffectiveGasPrice != nil {
+				info.L1Fee = new(big.Int).Mul(tx.EffectiveGasPrice, new(big.Int).SetUint64(info.GasUsedForL1))
+			}
+		}
+		if receipt.L1BlockNumber != nil {
+			info.L1BlockNumber = uint64(*receipt.L1BlockNumber)
+		}
+		info.TotalFee = executionFee
+	}
+}
+
+// bigOrNil 转换可选的hex数值
+func bigOrNil(value *hexutil.Big) *big.Int {
+	if value == nil {
+		return nil
+	}
+	return value.ToInt()
+}
+
+// bigOrZero 转换hex数值，缺失时为0，避免下游计算遇到nil
+func bigOrZero(value *hexutil.Big) *big.Int {
+	if value == nil {
+		return new(big.Int)
+	}
+	return value.ToInt()
+}
+
+// addressOrEmpty 转换可选地址
+func addressOrEmpty(address *common.Address) string {
+	if address == nil {
+		return ""
+	}
+	return address.Hex()
+}
@@ -0,0 +1,233 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/watchdog"
+)
+
+const (
+	// maxBackfillFailures 单个分段连续失败次数上限
+	maxBackfillFailures = 5
+	// backfillRetryInterval 分段失败后的重试间隔
+	backfillRetryInterval = 5 * time.Second
+)
+
+// providerLimitPatterns RPC节点拒绝过大eth_getLogs查询时的常见错误信息
+var providerLimitPatterns = []string{
+	"query returned more than",
+	"block range",
+	"range is too large",
+	"range too large",
+	"limit exceeded",
+	"response size",
+	"too many results",
+	"timeout",
+}
+
+// logBackfill 历史日志回填参数
+type logBackfill struct {
+	lookbackBlocks uint64
+	initialRange   uint64
+	minRange       uint64
+	maxRange       uint64
+}
+
+// newLogBackfill 根据配置创建回填参数，未启用时返回nil
+func newLogBackfill(cfg config.LogBackfillConfig) *logBackfill {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	backfill := &logBackfill{
+		lookbackBlocks: cfg.LookbackBlocks,
+		initialRange:   cfg.InitialRange,
+		minRange:       cfg.MinRange,
+		maxRange:       cfg.MaxRange,
+	}
+
+	if backfill.minRange == 0 {
+		backfill.minRange = 1
+	}
+	if backfill.maxRange < backfill.minRange {
+		backfill.maxRange = backfill.minRange
+	}
+	if backfill.initialRange < backfill.minRange || backfill.initialRange > backfill.maxRange {
+		backfill.initialRange = backfill.maxRange
+	}
+
+	return backfill
+}
+
+// startBlock 计算回填起始区块
+func (lb *logBackfill) startBlock(networkConfig config.NetworkConfig, head uint64) uint64 {
+	if networkConfig.LogBackfillFrom > 0 {
+		return networkConfig.LogBackfillFrom
+	}
+	if head < lb.lookbackBlocks {
+		return 0
+	}
+	return head - lb.lookbackBlocks
+}
+
+// isProviderLimitError 判断错误是否由节点的查询范围/结果数限制引起
+func isProviderLimitError(err error) bool {
+	message := strings.ToLower(err.Error())
+	for _, pattern := range providerLimitPatterns {
+		if strings.Contains(message, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// backfillLogs 在后台回填历史日志
+func (bc *BlockchainCollector) backfillLogs(ctx context.Context, connector *NetworkConnector, toBlock uint64) {
+	defer bc.wg.Done()
+	defer bc.track(goroutineBackfill)()
+	defer bc.supervisor.Recover(goroutineBackfill, connector.name)
+
+	bc.runLogBackfill(ctx, connector, toBlock)
+}
+
+// runLogBackfill 通过eth_getLogs分段回填历史日志，遇到节点限制时自动缩小分段，回填完成或停止时返回
+func (bc *BlockchainCollector) runLogBackfill(ctx context.Context, connector *NetworkConnector, toBlock uint64) {
+	fromBlock := bc.logBackfill.startBlock(connector.config, toBlock)
+	if fromBlock > toBlock {
+		return
+	}
+
+	logger.Infof("Starting log backfill for %s from block %d to %d", connector.name, fromBlock, toBlock)
+
+	chunk := bc.logBackfill.initialRange
+	failures := 0
+	total := 0
+
+	for fromBlock <= toBlock {
+		select {
+		case <-ctx.Done():
+			return
+		case <-bc.stopChan:
+			return
+		default:
+		}
+
+		// 内存降载时暂停回填，等待内存回落
+		if bc.shedding(watchdog.LevelPauseIngest) {
+			select {
+			case <-ctx.Done():
+				return
+			case <-bc.stopChan:
+				return
+			case <-time.After(backfillRetryInterval):
+			}
+			continue
+		}
+
+		endBlock := fromBlock + chunk - 1
+		if endBlock > toBlock {
+			endBlock = toBlock
+		}
+
+		if !bc.paceSync(ctx, connector, toBlock-fromBlock) {
+			return
+		}
+
+		count, err := bc.backfillRange(ctx, connector, fromBlock, endBlock)
+		if err != nil {
+			// 服务商限流时由同步节奏退避，不缩小分段也不计入失败
+			if connector.reportRateLimit(err) {
+				logger.Debugf("Log backfill for %s rate limited at block %d: %v", connector.name, fromBlock, err)
+				continue
+			}
+
+			// 节点限制导致的失败先缩小分段，已到最小分段时按普通失败重试
+			if isProviderLimitError(err) && chunk > bc.logBackfill.minRange {
+				chunk /= 2
+				if chunk < bc.logBackfill.minRange {
+					chunk = bc.logBackfill.minRange
+				}
+				logger.Debugf("Shrinking log backfill range for %s to %d blocks: %v", connector.name, chunk, err)
+				continue
+			}
+
+			failures++
+			bc.metricsManager.IncrementError(connector.name, "log_backfill_error")
+			if failures >= maxBackfillFailures {
+				logger.Errorf("Log backfill for %s aborted at block %d after %d failures: %v", connector.name, fromBlock, failures, err)
+				return
+			}
+
+			logger.Warnf("Failed to backfill logs %d-%d for %s: %v", fromBlock, endBlock, connector.name, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-bc.stopChan:
+				return
+			case <-time.After(backfillRetryInterval):
+			}
+			continue
+		}
+
+		failures = 0
+		total += count
+		fromBlock = endBlock + 1
+
+		// 分段成功后逐步放大，尽量减少请求数
+		if chunk < bc.logBackfill.maxRange {
+			chunk *= 2
+			if chunk > bc.logBackfill.maxRange {
+				chunk = bc.logBackfill.maxRange
+			}
+		}
+	}
+
+	logger.Infof("Log backfill completed for %s: %d events up to block %d", connector.name, total, toBlock)
+}
+
+// backfillRange 拉取并处理指定区块区间的日志，返回处理的事件数
+func (bc *BlockchainCollector) backfillRange(ctx context.Context, connector *NetworkConnector, fromBlock, toBlock uint64) (int, error) {
+	query := connector.logFilter.query()
+	query.FromBlock = new(big.Int).SetUint64(fromBlock)
+	query.ToBlock = new(big.Int).SetUint64(toBlock)
+
+	logs, err := connector.filterLogs(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	// eth_getLogs不返回区块时间，按区块缓存区块头时间戳
+	timestamps := make(map[uint64]time.Time)
+	count := 0
+
+	for i := range logs {
+		log := &logs[i]
+		if !connector.logFilter.matches(log) {
+			continue
+		}
+
+		timestamp, exists := timestamps[log.BlockNumber]
+		if !exists {
+			header, err := connector.getHeaderByNumber(ctx, log.BlockNumber)
+			if err != nil {
+				return count, fmt.Errorf("failed to get header %d: %w", log.BlockNumber, err)
+			}
+			timestamp = time.Unix(int64(header.Time), 0)
+			timestamps[log.BlockNumber] = timestamp
+		}
+
+		// 与实时路径共用事件处理流程，重复事件由去重窗口合并
+		if _, err := bc.processEvent(connector, log, timestamp); err != nil {
+			logger.Errorf("Failed to process event %s:%d for %s: %v", log.TxHash.Hex(), log.Index, connector.name, err)
+			continue
+		}
+		count++
+	}
+
+	return count, nil
+}
@@ -0,0 +1,283 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/models"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// logCriteria 一组过滤条件，地址与事件同时满足时匹配
+type logCriteria struct {
+	addresses []common.Address
+	topics    []common.Hash
+}
+
+// logFilter 合约日志过滤器，符合任一组条件的日志视为关注的日志
+type logFilter struct {
+	criteria []logCriteria
+}
+
+// ErrNetworkNotConfigured 网络不在配置中
+var ErrNetworkNotConfigured = errors.New("network is not configured")
+
+// 网络日志过滤条件的来源
+const (
+	logFilterSourceGlobal  = "global"  // blockchain.log_filter
+	logFilterSourceNetwork = "network" // 网络配置的log_filter
+	logFilterSourceRuntime = "runtime" // 通过管理接口设置
+)
+
+// newLogFilter 创建网络的日志过滤器，网络配置了地址或事件时替代全局的过滤条件，关注组的条件一并加入；
+// 未启用全局过滤、网络未配置且没有关注组时返回nil
+func newLogFilter(network string, cfg config.LogFilterConfig, networkCfg config.NetworkLogFilterConfig, groups *watchGroups) *logFilter {
+	addresses, topics := cfg.Addresses, cfg.Topics
+	enabled := cfg.Enabled
+	if logFilterConfigured(networkCfg) {
+		addresses, topics = networkCfg.Addresses, networkCfg.Topics
+		enabled = true
+	}
+	if !enabled && groups == nil {
+		return nil
+	}
+
+	filter := &logFilter{}
+
+	if enabled {
+		criteria := logCriteria{}
+		for _, address := range addresses {
+			if !common.IsHexAddress(address) {
+				logger.Warnf("Ignoring invalid log filter address for %s: %s", network, address)
+				continue
+			}
+			criteria.addresses = append(criteria.addresses, common.HexToAddress(address))
+		}
+
+		for _, topic := range topics {
+			criteria.topics = append(criteria.topics, common.HexToHash(topic))
+		}
+
+		filter.criteria = append(filter.criteria, criteria)
+		logger.Infof("Log filter enabled for %s with %d addresses and %d topics", network, len(criteria.addresses), len(criteria.topics))
+	}
+
+	if groups != nil {
+		filter.criteria = append(filter.criteria, groups.criteria...)
+	}
+
+	return filter
+}
+
+// query 生成日志订阅查询条件，多组条件时取并集，返回的日志需再经matches过滤
+func (lf *logFilter) query() ethereum.FilterQuery {
+	var addresses []common.Address
+	var topics []common.Hash
+	anyAddress, anyTopic := false, false
+	for _, criteria := range lf.criteria {
+		anyAddress = anyAddress || len(criteria.addresses) == 0
+		anyTopic = anyTopic || len(criteria.topics) == 0
+		addresses = append(addresses, criteria.addresses...)
+		topics = append(topics, criteria.topics...)
+	}
+
+	query := ethereum.FilterQuery{}
+	if !anyAddress {
+		query.Addresses = addresses
+	}
+	if !anyTopic {
+		query.Topics = [][]common.Hash{topics}
+	}
+
+	return query
+}
+
+// mayContain 根据区块logsBloom判断区块是否可能包含关注的日志
+// 布隆过滤器无假阴性，返回false时可安全跳过回执获取
+func (lf *logFilter) mayContain(bloom types.Bloom) bool {
+	for _, criteria := range lf.criteria {
+		if criteria.mayContain(bloom) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches 判断日志是否符合过滤条件
+func (lf *logFilter) matches(log *types.Log) bool {
+	for _, criteria := range lf.criteria {
+		if criteria.matches(log) {
+			return true
+		}
+	}
+	return false
+}
+
+// mayContain 根据logsBloom判断是否可能包含符合本组条件的日志
+func (lc *logCriteria) mayContain(bloom types.Bloom) bool {
+	if len(lc.addresses) > 0 {
+		found := false
+		for _, address := range lc.addresses {
+			if types.BloomLookup(bloom, address) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(lc.topics) > 0 {
+		found := false
+		for _, topic := range lc.topics {
+			if types.BloomLookup(bloom, topic) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matches 判断日志是否符合本组条件
+func (lc *logCriteria) matches(log *types.Log) bool {
+	if len(lc.addresses) > 0 {
+		found := false
+		for _, address := range lc.addresses {
+			if log.Address == address {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(lc.topics) > 0 {
+		if len(log.Topics) == 0 {
+			return false
+		}
+		for _, topic := range lc.topics {
+			if log.Topics[0] == topic {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// logFilterConfigured 是否配置了网络级的过滤条件
+func logFilterConfigured(cfg config.NetworkLogFilterConfig) bool {
+	return len(cfg.Addresses) > 0 || len(cfg.Topics) > 0
+}
+
+// effectiveLogFilter 网络生效的过滤条件及来源：运行时设置优先，其次为网络配置，均未设置时使用全局配置
+func (bc *BlockchainCollector) effectiveLogFilter(name string, networkCfg config.NetworkLogFilterConfig) (config.NetworkLogFilterConfig, string) {
+	bc.mu.RLock()
+	override, exists := bc.logFilterOverrides[name]
+	bc.mu.RUnlock()
+
+	switch {
+	case exists:
+		return override, logFilterSourceRuntime
+	case logFilterConfigured(networkCfg):
+		return networkCfg, logFilterSourceNetwork
+	default:
+		return config.NetworkLogFilterConfig{Addresses: bc.config.LogFilter.Addresses, Topics: bc.config.LogFilter.Topics}, logFilterSourceGlobal
+	}
+}
+
+// networkLogFilter 按网络生效的过滤条件创建日志过滤器，未设置网络级条件时按全局配置创建
+func (bc *BlockchainCollector) networkLogFilter(name string, networkCfg config.NetworkLogFilterConfig) *logFilter {
+	filterCfg, source := bc.effectiveLogFilter(name, networkCfg)
+	if source == logFilterSourceGlobal {
+		filterCfg = config.NetworkLogFilterConfig{}
+	}
+	return newLogFilter(name, bc.config.LogFilter, filterCfg, bc.watchGroups)
+}
+
+// GetLogFilter 获取网络生效的日志过滤条件
+func (bc *BlockchainCollector) GetLogFilter(name string) (*models.NetworkLogFilter, error) {
+	bc.mu.RLock()
+	networkConfig, exists := bc.config.Networks[name]
+	bc.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrNetworkNotConfigured, name)
+	}
+
+	filterCfg, source := bc.effectiveLogFilter(name, networkConfig.LogFilter)
+	return &models.NetworkLogFilter{
+		Network:     name,
+		Enabled:     source != logFilterSourceGlobal || bc.config.LogFilter.Enabled,
+		Source:      source,
+		Addresses:   filterCfg.Addresses,
+		Topics:      filterCfg.Topics,
+		WatchGroups: bc.watchGroups != nil,
+	}, nil
+}
+
+// SetLogFilter 运行时设置网络的日志过滤条件，替代网络配置及全局配置的条件，网络重新建立监控后生效
+// （从最后处理的区块继续，启用回填时按新条件回填）。配置文件中该网络的log_filter变化并热加载后恢复为配置
+func (bc *BlockchainCollector) SetLogFilter(name string, filter config.NetworkLogFilterConfig) (*models.NetworkLogFilter, error) {
+	bc.mu.RLock()
+	networkConfig, exists := bc.config.Networks[name]
+	bc.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrNetworkNotConfigured, name)
+	}
+	if networkConfig.Chain == models.ChainSolana {
+		return nil, fmt.Errorf("log filters are not supported for solana network %s", name)
+	}
+	if !logFilterConfigured(filter) {
+		return nil, fmt.Errorf("addresses or topics is required")
+	}
+	if errs := config.ValidateLogFilter("log_filter", filter); len(errs) > 0 {
+		messages := make([]string, 0, len(errs))
+		for _, err := range errs {
+			messages = append(messages, err.Error())
+		}
+		return nil, errors.New(strings.Join(messages, "; "))
+	}
+
+	bc.mu.Lock()
+	if bc.logFilterOverrides == nil {
+		bc.logFilterOverrides = make(map[string]config.NetworkLogFilterConfig)
+	}
+	bc.logFilterOverrides[name] = filter
+	bc.mu.Unlock()
+
+	logger.Infof("Log filter for %s set to %d addresses and %d topics", name, len(filter.Addresses), len(filter.Topics))
+	bc.restartNetwork(name)
+	return bc.GetLogFilter(name)
+}
+
+// ResetLogFilter 清除运行时设置的日志过滤条件，恢复为配置
+func (bc *BlockchainCollector) ResetLogFilter(name string) (*models.NetworkLogFilter, error) {
+	bc.mu.Lock()
+	_, exists := bc.config.Networks[name]
+	_, overridden := bc.logFilterOverrides[name]
+	delete(bc.logFilterOverrides, name)
+	bc.mu.Unlock()
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrNetworkNotConfigured, name)
+	}
+
+	if overridden {
+		logger.Infof("Log filter for %s reset to config", name)
+		bc.restartNetwork(name)
+	}
+	return bc.GetLogFilter(name)
+}
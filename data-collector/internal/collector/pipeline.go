@@ -0,0 +1,122 @@
+package collector
+
+import (
+	"sort"
+	"time"
+
+	"web3-data-collector/internal/models"
+	"web3-data-collector/internal/processor"
+)
+
+// 流水线阶段类型
+const (
+	stageKindCollector = "collector"
+	stageKindProcessor = "processor"
+	stageKindSink      = "sink"
+)
+
+// recordStage 记录流水线阶段的一次执行
+func (bc *BlockchainCollector) recordStage(network, stage string, start time.Time, err error) {
+	bc.dataProcessor.PipelineStats().Record(network, stage, time.Since(start), err)
+}
+
+// GetPipeline 获取各网络当前的处理流水线及各阶段运行统计，停用的网络不含阶段
+func (bc *BlockchainCollector) GetPipeline() []*models.NetworkPipeline {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+
+	var pipelines []*models.NetworkPipeline
+	for name, connector := range bc.connectors {
+		chain := connector.config.Chain
+		if chain == "" {
+			chain = models.ChainEVM
+		}
+		pipelines = append(pipelines, &models.NetworkPipeline{
+			Network: name,
+			Chain:   chain,
+			Status:  models.NetworkStatusActive,
+			Stages:  bc.pipelineStages(connector),
+		})
+	}
+	for name := range bc.disabled {
+		chain := bc.config.Networks[name].Chain
+		if chain == "" {
+			chain = models.ChainEVM
+		}
+		pipelines = append(pipelines, &models.NetworkPipeline{
+			Network: name,
+			Chain:   chain,
+			Status:  models.NetworkStatusDisabled,
+			Stages:  []*models.PipelineStage{},
+		})
+	}
+
+	sort.Slice(pipelines, func(i, j int) bool {
+		return pipelines[i].Network < pipelines[j].Network
+	})
+	return pipelines
+}
+
+// pipelineStages 按执行顺序构造网络的阶段图，已启用的阶段依次相连，推送阶段扇出到各输出端
+func (bc *BlockchainCollector) pipelineStages(connector *NetworkConnector) []*models.PipelineStage {
+	stats := bc.dataProcessor.PipelineStats()
+
+	stages := []*models.PipelineStage{
+		{Name: processor.PipelineStageFetch, Kind: stageKindCollector, Enabled: true, QueueDepth: int64(connector.getBlockLag())},
+	}
+	if connector.solana == nil {
+		stages = append(stages,
+			&models.PipelineStage{Name: processor.PipelineStageReceipts, Kind: stageKindCollector, Enabled: bc.dataProcessor.Supply() != nil},
+		)
+	}
+	stages = append(stages, &models.PipelineStage{Name: processor.PipelineStageProcess, Kind: stageKindProcessor, Enabled: true})
+	if connector.solana == nil {
+		stages = append(stages,
+			&models.PipelineStage{Name: processor.PipelineStageLogFilter, Kind: stageKindCollector, Enabled: connector.logFilter != nil},
+			&models.PipelineStage{Name: processor.PipelineStageFlashLoans, Kind: stageKindCollector, Enabled: bc.flashLoans != nil},
+			&models.PipelineStage{
+				Name:    processor.PipelineStageTokenLogs,
+				Kind:    stageKindCollector,
+				Enabled: bc.tokenLogsEnabled(),
+			},
+			&models.PipelineStage{Name: processor.PipelineStageTokenRisk, Kind: stageKindCollector, Enabled: bc.tokenRiskEnabled(connector)},
+			&models.PipelineStage{Name: processor.PipelineStageBridges, Kind: stageKindCollector, Enabled: bc.bridgesEnabled(connector)},
+			&models.PipelineStage{Name: processor.PipelineStageBalanceDrains, Kind: stageKindCollector, Enabled: bc.dataProcessor.Velocity() != nil},
+			&models.PipelineStage{Name: processor.PipelineStageWatchBalances, Kind: stageKindCollector, Enabled: bc.watchBalancesEnabled(connector)},
+		)
+	}
+	publish := &models.PipelineStage{Name: processor.PipelineStagePublish, Kind: stageKindProcessor, Enabled: true}
+	stages = append(stages, publish)
+
+	// 流水线阶段之间没有队列，仅区块获取（待处理区块数）及输出端报告等待数量
+	var previous *models.PipelineStage
+	for _, stage := range stages {
+		if stage.Name != processor.PipelineStageFetch {
+			stage.QueueDepth = -1
+		}
+		if !stage.Enabled {
+			continue
+		}
+		if previous != nil {
+			previous.Next = []string{stage.Name}
+		}
+		previous = stage
+	}
+
+	depths := bc.dataProcessor.SinkQueueDepths()
+	for _, sink := range bc.dataProcessor.SinkNames() {
+		name := processor.SinkStageName(sink)
+		publish.Next = append(publish.Next, name)
+		stages = append(stages, &models.PipelineStage{Name: name, Kind: stageKindSink, Enabled: true, QueueDepth: depths[sink]})
+	}
+
+	for _, stage := range stages {
+		stageStats := stats.Get(connector.name, stage.Name)
+		stage.Processed = stageStats.Processed
+		stage.Errors = stageStats.Errors
+		stage.Throughput = stageStats.Throughput
+		stage.AvgLatencyMs = stageStats.AvgLatencyMs
+		stage.LastRun = stageStats.LastRun
+	}
+	return stages
+}
@@ -0,0 +1,166 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"web3-data-collector/internal/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// defaultPollInterval EVM网络轮询新区块的默认间隔
+const defaultPollInterval = 5 * time.Second
+
+// 交易回执获取方式
+const (
+	receiptStrategyBlock       = "block"       // 每个区块一次eth_getBlockReceipts，需节点支持
+	receiptStrategyTransaction = "transaction" // 逐笔eth_getTransactionReceipt（默认），以批量请求发送
+)
+
+// defaultReceiptBatchSize 逐笔获取回执时每个批量请求默认包含的回执数
+const defaultReceiptBatchSize = 100
+
+// pollInterval 轮询间隔，未配置时EVM网络为5秒，Solana网络为2秒
+func (nc *NetworkConnector) pollInterval() time.Duration {
+	if nc.config.PollInterval != "" {
+		if interval, err := time.ParseDuration(nc.config.PollInterval); err == nil && interval > 0 {
+			return interval
+		}
+	}
+	if nc.solana != nil {
+		return solanaPollInterval
+	}
+	return defaultPollInterval
+}
+
+// realtime 是否订阅新区块头及日志。只处理safe/finalized区块或要求确认数时，
+// 订阅推送的是未确认数据，由轮询按确认后的区块号推进
+func (nc *NetworkConnector) realtime() bool {
+	return !nc.tagged() && nc.config.Confirmations == 0
+}
+
+// startBlock 启动时的处理起点（返回其前一个区块）：配置了start_block时首次启动从该区块开始处理，
+// 网络重新启用或热加载重启时从上次处理到的区块cursor继续，不再回退；未配置时从可处理到的最新区块之后开始
+func (nc *NetworkConnector) startBlock(target, cursor uint64) uint64 {
+	if nc.config.StartBlock > 0 {
+		if cursor > 0 {
+			return cursor
+		}
+		return nc.config.StartBlock - 1
+	}
+	return target
+}
+
+// batchEnd 单次轮询最多处理到的区块，max_batch_size为0时EVM网络不限，Solana网络为100个slot
+func (nc *NetworkConnector) batchEnd(lastProcessed, target uint64) uint64 {
+	size := uint64(nc.config.MaxBatchSize)
+	if size == 0 && nc.solana != nil {
+		size = maxSlotsPerPoll
+	}
+	if size > 0 && target > lastProcessed+size {
+		return lastProcessed + size
+	}
+	return target
+}
+
+// receiptStrategy 交易回执获取方式，默认transaction
+func (nc *NetworkConnector) receiptStrategy() string {
+	if nc.config.ReceiptStrategy == "" {
+		return receiptStrategyTransaction
+	}
+	return nc.config.ReceiptStrategy
+}
+
+// receiptBatchSize 逐笔获取回执时每个批量请求包含的回执数
+func (nc *NetworkConnector) receiptBatchSize() int {
+	if nc.config.Receipts.BatchSize > 0 {
+		return nc.config.Receipts.BatchSize
+	}
+	return defaultReceiptBatchSize
+}
+
+// receipts 按配置的方式获取区块内交易的回执，顺序与交易一致
+func (nc *NetworkConnector) receipts(ctx context.Context, blockNumber uint64, txHashes []common.Hash) ([]*types.Receipt, error) {
+	if nc.receiptStrategy() == receiptStrategyBlock {
+		return nc.blockReceipts(ctx, blockNumber)
+	}
+
+	receipts := make([]*types.Receipt, len(txHashes))
+	results := make([]interface{}, len(txHashes))
+	for i := range receipts {
+		results[i] = &receipts[i]
+	}
+	if err := nc.batchReceipts(ctx, blockNumber, txHashes, results); err != nil {
+		return nil, err
+	}
+	for i, receipt := range receipts {
+		if receipt == nil {
+			return nil, fmt.Errorf("receipt for %s not found", txHashes[i].Hex())
+		}
+	}
+	return receipts, nil
+}
+
+// batchReceipts 以JSON-RPC批量请求逐笔获取回执，results[i]为第i笔交易回执的解码目标。
+// 批量请求计为一次HTTP请求限流，成本核算按回执笔数计
+func (nc *NetworkConnector) batchReceipts(ctx context.Context, blockNumber uint64, txHashes []common.Hash, results []interface{}) error {
+	if nc.rpcClient == nil {
+		return fmt.Errorf("no RPC client available")
+	}
+
+	size := nc.receiptBatchSize()
+	for start := 0; start < len(txHashes); start += size {
+		end := start + size
+		if end > len(txHashes) {
+			end = len(txHashes)
+		}
+
+		batch := make([]rpc.BatchElem, 0, end-start)
+		for i := start; i < end; i++ {
+			nc.recordCall("eth_getTransactionReceipt")
+			batch = append(batch, rpc.BatchElem{
+				Method: "eth_getTransactionReceipt",
+				Args:   []interface{}{txHashes[i]},
+				Result: results[i],
+			})
+		}
+		if err := nc.rpcClient.Client().BatchCallContext(ctx, batch); err != nil {
+			return nc.rpcError("eth_getTransactionReceipt", blockNumber, err)
+		}
+		for i, elem := range batch {
+			if elem.Error != nil {
+				return nc.rpcError("eth_getTransactionReceipt", blockNumber, fmt.Errorf("receipt for %s: %w", txHashes[start+i].Hex(), elem.Error))
+			}
+		}
+	}
+	return nil
+}
+
+// transactionLogs 转换回执中的日志
+func transactionLogs(logs []*types.Log) []models.TransactionLog {
+	if len(logs) == 0 {
+		return nil
+	}
+	converted := make([]models.TransactionLog, 0, len(logs))
+	for _, log := range logs {
+		topics := make([]string, 0, len(log.Topics))
+		for _, topic := range log.Topics {
+			topics = append(topics, topic.Hex())
+		}
+		entry := models.TransactionLog{
+			Address:  log.Address.Hex(),
+			Topics:   topics,
+			LogIndex: log.Index,
+		}
+		if len(log.Data) > 0 {
+			entry.Data = hexutil.Encode(log.Data)
+		}
+		converted = append(converted, entry)
+	}
+	return converted
+}
@@ -0,0 +1,101 @@
+package collector
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/metrics"
+)
+
+// rateLimiter 令牌桶限流器，令牌不足时预占并返回需要等待的时长，等待期间按到达顺序排队
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // 每秒补充的令牌数
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter 根据配置创建限流器，未配置速率时返回nil
+func newRateLimiter(cfg config.RateLimitConfig) *rateLimiter {
+	if cfg.RequestsPerSecond <= 0 {
+		return nil
+	}
+
+	burst := float64(cfg.Burst)
+	if burst <= 0 {
+		burst = math.Max(1, math.Ceil(cfg.RequestsPerSecond))
+	}
+	return &rateLimiter{
+		rate:   cfg.RequestsPerSecond,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// reserve 取出一个令牌，返回取得令牌前需要等待的时长
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// release 归还未使用的令牌
+func (l *rateLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens = math.Min(l.burst, l.tokens+1)
+}
+
+// Wait 等待取得令牌，返回等待的时长；ctx结束时归还令牌并返回错误
+func (l *rateLimiter) Wait(ctx context.Context) (time.Duration, error) {
+	delay := l.reserve()
+	if delay == 0 {
+		return 0, nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		l.release()
+		return 0, ctx.Err()
+	case <-timer.C:
+		return delay, nil
+	}
+}
+
+// rateLimitedTransport 发送HTTP JSON-RPC请求前按网络限流，批量请求计为一次
+type rateLimitedTransport struct {
+	base           http.RoundTripper
+	limiter        *rateLimiter
+	network        string
+	provider       string
+	metricsManager *metrics.Manager
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	wait, err := t.limiter.Wait(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	if wait > 0 {
+		t.metricsManager.RecordRPCThrottled(t.network, t.provider, wait)
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,232 @@
+package collector
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/metrics"
+	"web3-data-collector/internal/models"
+)
+
+// minProjectionElapsed 当日统计时长不足时预测值波动过大，不据此告警
+const minProjectionElapsed = time.Hour
+
+// totalBudgetKey 全部网络预算告警的记录键
+const totalBudgetKey = "*"
+
+// rpcCostTracker 按网络/服务商/方法统计RPC调用并估算每日花费
+type rpcCostTracker struct {
+	providers      map[string]config.RPCProviderPricing
+	dailyBudget    float64
+	networkBudgets map[string]float64
+	day            string
+	since          time.Time // 当日统计起点，进程中途启动时为启动时间
+	networks       map[string]*rpcCostState
+	alerted        map[string]bool
+	metricsManager *metrics.Manager
+	alert          func(alert *models.RiskAlert)
+	mu             sync.Mutex
+}
+
+// rpcCostState 单个网络当日的调用统计
+type rpcCostState struct {
+	provider string
+	calls    map[string]uint64
+	spent    float64
+}
+
+// newRPCCostTracker 根据配置创建成本统计器，未启用时返回nil
+func newRPCCostTracker(cfg config.RPCCostConfig, metricsManager *metrics.Manager, alert func(alert *models.RiskAlert)) *rpcCostTracker {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	tracker := &rpcCostTracker{
+		providers:      cfg.Providers,
+		dailyBudget:    cfg.DailyBudgetUSD,
+		networkBudgets: cfg.NetworkBudgets,
+		networks:       make(map[string]*rpcCostState),
+		alerted:        make(map[string]bool),
+		metricsManager: metricsManager,
+		alert:          alert,
+	}
+	now := time.Now().UTC()
+	tracker.rollover(now)
+	tracker.since = now
+
+	logger.Infof("RPC cost accounting enabled for %d providers", len(cfg.Providers))
+	return tracker
+}
+
+// rpcProviderName 获取网络的RPC服务商名称
+func rpcProviderName(networkConfig config.NetworkConfig) string {
+	if networkConfig.RPCProvider != "" {
+		return networkConfig.RPCProvider
+	}
+	if parsed, err := url.Parse(networkConfig.RPCURL); err == nil && parsed.Hostname() != "" {
+		return parsed.Hostname()
+	}
+	return "unknown"
+}
+
+// Record 记录一次RPC调用
+func (t *rpcCostTracker) Record(network, provider, method string) {
+	cost := t.price(provider, method)
+	now := time.Now().UTC()
+
+	t.mu.Lock()
+
+	if day := now.Format("2006-01-02"); day != t.day {
+		t.rollover(now)
+	}
+
+	state, exists := t.networks[network]
+	if !exists {
+		state = &rpcCostState{provider: provider, calls: make(map[string]uint64)}
+		t.networks[network] = state
+	}
+	state.provider = provider
+	state.calls[method]++
+	state.spent += cost
+	spent := state.spent
+
+	elapsed := now.Sub(t.since)
+	projected := t.project(spent, elapsed)
+
+	var alerts []*models.RiskAlert
+	if elapsed >= minProjectionElapsed {
+		if budget := t.networkBudgets[network]; budget > 0 && projected > budget && !t.alerted[network] {
+			t.alerted[network] = true
+			alerts = append(alerts, t.budgetAlert(network, spent, projected, budget, now))
+		}
+
+		if t.dailyBudget > 0 && !t.alerted[totalBudgetKey] {
+			totalProjected := t.project(t.totalSpent(), elapsed)
+			if totalProjected > t.dailyBudget {
+				t.alerted[totalBudgetKey] = true
+				alerts = append(alerts, t.budgetAlert("", t.totalSpent(), totalProjected, t.dailyBudget, now))
+			}
+		}
+	}
+
+	t.mu.Unlock()
+
+	t.metricsManager.RecordRPCCall(network, provider, method, cost)
+	t.metricsManager.SetRPCDailySpend(network, spent, projected)
+
+	for _, alert := range alerts {
+		logger.Warnf("%s: %s", alert.Title, alert.Description)
+		if t.alert != nil {
+			t.alert(alert)
+		}
+	}
+}
+
+// Report 获取当日的成本统计
+func (t *rpcCostTracker) Report() *models.RPCCostReport {
+	now := time.Now().UTC()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if day := now.Format("2006-01-02"); day != t.day {
+		t.rollover(now)
+	}
+	elapsed := now.Sub(t.since)
+
+	report := &models.RPCCostReport{
+		Date:      t.day,
+		Networks:  make(map[string]*models.RPCCostSummary, len(t.networks)),
+		BudgetUSD: t.dailyBudget,
+	}
+
+	for network, state := range t.networks {
+		calls := make(map[string]uint64, len(state.calls))
+		for method, count := range state.calls {
+			calls[method] = count
+		}
+
+		summary := &models.RPCCostSummary{
+			Network:      network,
+			Provider:     state.provider,
+			Calls:        calls,
+			SpentUSD:     state.spent,
+			ProjectedUSD: t.project(state.spent, elapsed),
+			BudgetUSD:    t.networkBudgets[network],
+		}
+		summary.OverBudget = summary.BudgetUSD > 0 && summary.ProjectedUSD > summary.BudgetUSD
+		report.Networks[network] = summary
+		report.SpentUSD += state.spent
+	}
+
+	report.ProjectedUSD = t.project(report.SpentUSD, elapsed)
+	report.OverBudget = report.BudgetUSD > 0 && report.ProjectedUSD > report.BudgetUSD
+
+	return report
+}
+
+// price 获取服务商方法的单价
+func (t *rpcCostTracker) price(provider, method string) float64 {
+	pricing, exists := t.providers[provider]
+	if !exists {
+		return 0
+	}
+	if cost, exists := pricing.Methods[method]; exists {
+		return cost
+	}
+	return pricing.DefaultCostUSD
+}
+
+// project 按当日已统计时长线性外推全天花费
+func (t *rpcCostTracker) project(spent float64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return spent
+	}
+	return spent * float64(24*time.Hour) / float64(elapsed)
+}
+
+// totalSpent 当日全部网络的花费
+func (t *rpcCostTracker) totalSpent() float64 {
+	total := 0.0
+	for _, state := range t.networks {
+		total += state.spent
+	}
+	return total
+}
+
+// rollover 切换到新的一天（UTC）并清空统计
+func (t *rpcCostTracker) rollover(now time.Time) {
+	t.day = now.Format("2006-01-02")
+	t.since = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	t.networks = make(map[string]*rpcCostState)
+	t.alerted = make(map[string]bool)
+}
+
+// budgetAlert 创建预计花费超出预算的运维告警，network为空表示全部网络
+func (t *rpcCostTracker) budgetAlert(network string, spent, projected, budget float64, now time.Time) *models.RiskAlert {
+	scope := network
+	if scope == "" {
+		scope = "all networks"
+	}
+
+	return &models.RiskAlert{
+		ID:          models.OpsAlertID("RPC_BUDGET_EXCEEDED", now, network),
+		Type:        "RPC_BUDGET_EXCEEDED",
+		Level:       "MEDIUM",
+		Title:       fmt.Sprintf("Projected RPC spend exceeds budget for %s", scope),
+		Description: fmt.Sprintf("Projected daily spend $%.2f exceeds budget $%.2f ($%.2f spent so far on %s)", projected, budget, spent, t.day),
+		Network:     network,
+		RiskFactors: []string{"rpc_cost"},
+		Metadata: map[string]interface{}{
+			"date":          t.day,
+			"spent_usd":     spent,
+			"projected_usd": projected,
+			"budget_usd":    budget,
+		},
+		Timestamp: now,
+		Status:    "ACTIVE",
+	}
+}
@@ -0,0 +1,364 @@
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// RPC录制/回放模式
+const (
+	rpcRecordingRecord = "record"
+	rpcRecordingReplay = "replay"
+)
+
+// rpcExchange 一次录制的HTTP JSON-RPC请求与响应（原始报文）
+type rpcExchange struct {
+	Request    json.RawMessage `json:"request"`
+	Response   json.RawMessage `json:"response"`
+	Status     int             `json:"status"`
+	RecordedAt time.Time       `json:"recorded_at"`
+}
+
+// dialRPC 连接RPC节点，按配置录制HTTP响应或从录制文件回放，并对HTTP请求限流，pacer不为nil时记录响应供同步节奏调整
+// WebSocket订阅推送不录制：回放时不建立订阅，由轮询按录制的响应重现处理流程
+func (bc *BlockchainCollector) dialRPC(network, provider string, cfg config.NetworkConfig, pacer *syncPacer) (*rpc.Client, error) {
+	ctx := context.Background()
+
+	var transport http.RoundTripper
+	switch bc.config.RPCRecording.Mode {
+	case "":
+	case rpcRecordingRecord:
+		recording, err := newRecordingTransport(bc.config.RPCRecording, network)
+		if err != nil {
+			return nil, err
+		}
+		logger.Infof("Recording RPC responses for %s to %s", network, recording.path)
+		transport = recording
+	case rpcRecordingReplay:
+		replay, err := newReplayTransport(bc.config.RPCRecording, network)
+		if err != nil {
+			return nil, err
+		}
+		logger.Infof("Replaying recorded RPC responses for %s from %s", network, replay.path)
+		transport = replay
+	default:
+		return nil, fmt.Errorf("unknown rpc_recording mode: %s", bc.config.RPCRecording.Mode)
+	}
+
+	// 同步节奏按服务商的实际响应调整，不含本地限流的等待；回放时不调整
+	if pacer != nil && bc.config.RPCRecording.Mode != rpcRecordingReplay {
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		transport = &pacingTransport{base: transport, pacer: pacer}
+	}
+
+	// 回放不访问服务商，无需限流
+	if limiter := newRateLimiter(cfg.RateLimit); limiter != nil && bc.config.RPCRecording.Mode != rpcRecordingReplay {
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		logger.Infof("Rate limiting RPC requests for %s to %.1f/s (burst %d)", network, limiter.rate, int(limiter.burst))
+		transport = &rateLimitedTransport{
+			base:           transport,
+			limiter:        limiter,
+			network:        network,
+			provider:       provider,
+			metricsManager: bc.metricsManager,
+		}
+	}
+
+	if transport == nil {
+		return rpc.DialContext(ctx, cfg.RPCURL)
+	}
+	return rpc.DialOptions(ctx, cfg.RPCURL, rpc.WithHTTPClient(&http.Client{Transport: transport}))
+}
+
+// recordingPath 网络录制文件路径
+func recordingPath(cfg config.RPCRecordingConfig, network string) string {
+	return filepath.Join(cfg.Dir, network+".jsonl")
+}
+
+// recordingTransport 转发请求并将原始请求/响应追加写入录制文件
+type recordingTransport struct {
+	base http.RoundTripper
+	path string
+	file *os.File
+	mu   sync.Mutex
+}
+
+func newRecordingTransport(cfg config.RPCRecordingConfig, network string) (*recordingTransport, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create recording dir: %w", err)
+	}
+
+	path := recordingPath(cfg, network)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording file: %w", err)
+	}
+
+	return &recordingTransport{base: http.DefaultTransport, path: path, file: file}, nil
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	responseBody, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// 只录制合法的JSON报文，网关错误页等无法回放
+	if json.Valid(requestBody) && json.Valid(responseBody) {
+		t.write(&rpcExchange{
+			Request:    requestBody,
+			Response:   responseBody,
+			Status:     resp.StatusCode,
+			RecordedAt: time.Now(),
+		})
+	}
+
+	return resp, nil
+}
+
+func (t *recordingTransport) write(exchange *rpcExchange) {
+	data, err := json.Marshal(exchange)
+	if err != nil {
+		logger.Errorf("Failed to encode RPC recording: %v", err)
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, err := t.file.Write(append(data, '\n')); err != nil {
+		logger.Errorf("Failed to write RPC recording to %s: %v", t.path, err)
+	}
+}
+
+// readBody 读取并还原报文，使其可以被再次读取
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+// replayTransport 按请求内容（忽略id）返回录制的响应，相同请求按录制顺序依次返回，用尽后重复最后一条
+type replayTransport struct {
+	path      string
+	responses map[string][]*rpcExchange
+	served    map[string]int
+	mu        sync.Mutex
+}
+
+func newReplayTransport(cfg config.RPCRecordingConfig, network string) (*replayTransport, error) {
+	path := recordingPath(cfg, network)
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording file: %w", err)
+	}
+	defer file.Close()
+
+	transport := &replayTransport{
+		path:      path,
+		responses: make(map[string][]*rpcExchange),
+		served:    make(map[string]int),
+	}
+
+	scanner := bufio.NewScanner(file)
+	// 完整区块的响应可能有数MB
+	scanner.Buffer(make([]byte, 0, 1024*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var exchange rpcExchange
+		if err := json.Unmarshal(scanner.Bytes(), &exchange); err != nil {
+			return nil, fmt.Errorf("invalid recording in %s: %w", path, err)
+		}
+		key, err := requestKey(exchange.Request)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recorded request in %s: %w", path, err)
+		}
+		transport.responses[key] = append(transport.responses[key], &exchange)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recording %s: %w", path, err)
+	}
+
+	return transport, nil
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := requestKey(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON-RPC request: %w", err)
+	}
+
+	t.mu.Lock()
+	recorded := t.responses[key]
+	index := t.served[key]
+	if index < len(recorded)-1 {
+		t.served[key] = index + 1
+	}
+	t.mu.Unlock()
+
+	status := http.StatusOK
+	var body []byte
+	if len(recorded) == 0 {
+		body, err = missingResponse(requestBody)
+	} else {
+		exchange := recorded[index]
+		status = exchange.Status
+		body, err = rewriteIDs(exchange.Request, exchange.Response, requestBody)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		StatusCode:    status,
+		Status:        http.StatusText(status),
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// requestKey 去掉id后的请求内容，作为匹配录制响应的键
+func requestKey(body []byte) (string, error) {
+	var batch []map[string]interface{}
+	if err := json.Unmarshal(body, &batch); err == nil {
+		for _, message := range batch {
+			delete(message, "id")
+		}
+		data, err := json.Marshal(batch)
+		return string(data), err
+	}
+
+	var message map[string]interface{}
+	if err := json.Unmarshal(body, &message); err != nil {
+		return "", err
+	}
+	delete(message, "id")
+	// json.Marshal按键排序，键顺序不同的相同请求得到相同的键
+	data, err := json.Marshal(message)
+	return string(data), err
+}
+
+// rewriteIDs 将录制响应中的id替换为本次请求对应位置的id
+func rewriteIDs(recordedRequest, recordedResponse, request []byte) ([]byte, error) {
+	oldIDs, err := messageIDs(recordedRequest)
+	if err != nil {
+		return nil, err
+	}
+	newIDs, err := messageIDs(request)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping := make(map[string]json.RawMessage, len(oldIDs))
+	for i := range oldIDs {
+		if i < len(newIDs) {
+			mapping[string(oldIDs[i])] = newIDs[i]
+		}
+	}
+
+	var batch []map[string]json.RawMessage
+	if err := json.Unmarshal(recordedResponse, &batch); err == nil {
+		for _, message := range batch {
+			if id, exists := mapping[string(message["id"])]; exists {
+				message["id"] = id
+			}
+		}
+		return json.Marshal(batch)
+	}
+
+	var message map[string]json.RawMessage
+	if err := json.Unmarshal(recordedResponse, &message); err != nil {
+		return nil, err
+	}
+	if id, exists := mapping[string(message["id"])]; exists {
+		message["id"] = id
+	}
+	return json.Marshal(message)
+}
+
+// messageIDs 按顺序获取请求中的id（支持批量请求）
+func messageIDs(body []byte) ([]json.RawMessage, error) {
+	var batch []struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(body, &batch); err == nil {
+		ids := make([]json.RawMessage, 0, len(batch))
+		for _, message := range batch {
+			ids = append(ids, message.ID)
+		}
+		return ids, nil
+	}
+
+	var message struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(body, &message); err != nil {
+		return nil, err
+	}
+	return []json.RawMessage{message.ID}, nil
+}
+
+// missingResponse 请求未被录制时返回JSON-RPC错误
+func missingResponse(request []byte) ([]byte, error) {
+	ids, err := messageIDs(request)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]map[string]interface{}, 0, len(ids))
+	for _, id := range ids {
+		responses = append(responses, map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      id,
+			"error": map[string]interface{}{
+				"code":    -32000,
+				"message": "no recorded response for request",
+			},
+		})
+	}
+
+	if request = bytes.TrimSpace(request); len(request) > 0 && request[0] == '[' {
+		return json.Marshal(responses)
+	}
+	return json.Marshal(responses[0])
+}
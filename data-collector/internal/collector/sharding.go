@@ -0,0 +1,80 @@
+package collector
+
+import (
+	"sort"
+
+	"web3-data-collector/internal/sharding"
+)
+
+// ShardNetworks 参与分片的网络，即已启用的网络
+func (bc *BlockchainCollector) ShardNetworks() []string {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+
+	networks := make([]string, 0, len(bc.config.Networks))
+	for name, networkConfig := range bc.config.Networks {
+		if networkConfig.Enabled {
+			networks = append(networks, name)
+		}
+	}
+	sort.Strings(networks)
+	return networks
+}
+
+// AssignNetworks 按分片结果启动新分配的网络，停止已分配给其他实例的网络
+func (bc *BlockchainCollector) AssignNetworks(networks []string) {
+	if bc.stopping() {
+		return
+	}
+
+	assigned := make(map[string]bool, len(networks))
+	for _, name := range networks {
+		assigned[name] = true
+	}
+
+	bc.mu.Lock()
+	previous := bc.assigned
+	bc.assigned = assigned
+	configs := bc.config.Networks
+	ctx := bc.ctx
+	bc.mu.Unlock()
+
+	for name := range previous {
+		if !assigned[name] {
+			logger.Infof("Network %s is no longer assigned to this instance", name)
+			bc.stopNetwork(name)
+		}
+	}
+
+	for name := range assigned {
+		if previous[name] {
+			continue
+		}
+		networkConfig, exists := configs[name]
+		if !exists || !networkConfig.Enabled {
+			continue
+		}
+		logger.Infof("Network %s assigned to this instance", name)
+		bc.launchNetwork(ctx, name, networkConfig)
+	}
+}
+
+// ownsNetwork 判断网络是否由本实例处理，未启用分片时处理全部网络
+func (bc *BlockchainCollector) ownsNetwork(name string) bool {
+	if bc.shards == nil {
+		return true
+	}
+
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+
+	return bc.assigned[name]
+}
+
+// ShardStatus 获取分片状态，未启用时为nil
+func (bc *BlockchainCollector) ShardStatus() *sharding.Status {
+	if bc.shards == nil {
+		return nil
+	}
+	return bc.shards.Status()
+}
@@ -0,0 +1,520 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"web3-data-collector/internal/faults"
+	"web3-data-collector/internal/models"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// solanaPollInterval 轮询确认slot的间隔（slot约400ms）
+	solanaPollInterval = 2 * time.Second
+	// maxSlotsPerPoll 未配置max_batch_size时单次轮询最多处理的slot数，避免长时间落后时阻塞订阅
+	maxSlotsPerPoll = 100
+)
+
+// Solana节点对无区块slot返回的错误码
+const (
+	solanaSlotSkipped         = -32007
+	solanaLongTermStorageSlot = -32009
+	solanaBlockNotAvailable   = -32004
+)
+
+// Solana内置程序，调用它们不视为合约调用
+const (
+	solanaSystemProgram        = "11111111111111111111111111111111"
+	solanaComputeBudgetProgram = "ComputeBudget111111111111111111111111111111"
+	solanaVoteProgram          = "Vote111111111111111111111111111111111111111"
+)
+
+// solanaClient Solana JSON-RPC客户端
+type solanaClient struct {
+	rpc        *rpc.Client
+	wsURL      string
+	commitment string
+}
+
+// solanaBlock getBlock返回的区块
+type solanaBlock struct {
+	Blockhash         string              `json:"blockhash"`
+	PreviousBlockhash string              `json:"previousBlockhash"`
+	ParentSlot        uint64              `json:"parentSlot"`
+	BlockTime         *int64              `json:"blockTime"`
+	Transactions      []solanaTransaction `json:"transactions"`
+	Rewards           []solanaReward      `json:"rewards"`
+}
+
+// solanaReward 区块奖励，Fee类型的领取者为出块节点
+type solanaReward struct {
+	Pubkey     string `json:"pubkey"`
+	Lamports   int64  `json:"lamports"`
+	RewardType string `json:"rewardType"`
+}
+
+// solanaTransaction 区块内的交易（json编码）
+type solanaTransaction struct {
+	Transaction struct {
+		Signatures []string `json:"signatures"`
+		Message    struct {
+			AccountKeys  []string `json:"accountKeys"`
+			Instructions []struct {
+				ProgramIDIndex int `json:"programIdIndex"`
+			} `json:"instructions"`
+		} `json:"message"`
+	} `json:"transaction"`
+	Meta *solanaTransactionMeta `json:"meta"`
+}
+
+// solanaTransactionMeta 交易执行结果
+type solanaTransactionMeta struct {
+	Err                  interface{}          `json:"err"`
+	Fee                  uint64               `json:"fee"`
+	PreBalances          []uint64             `json:"preBalances"`
+	PostBalances         []uint64             `json:"postBalances"`
+	PreTokenBalances     []solanaTokenBalance `json:"preTokenBalances"`
+	PostTokenBalances    []solanaTokenBalance `json:"postTokenBalances"`
+	ComputeUnitsConsumed *uint64              `json:"computeUnitsConsumed"`
+	LoadedAddresses      *struct {
+		Writable []string `json:"writable"`
+		Readonly []string `json:"readonly"`
+	} `json:"loadedAddresses"`
+}
+
+// solanaTokenBalance SPL代币账户余额
+type solanaTokenBalance struct {
+	AccountIndex  int    `json:"accountIndex"`
+	Mint          string `json:"mint"`
+	Owner         string `json:"owner"`
+	UITokenAmount struct {
+		Amount   string `json:"amount"`
+		Decimals uint8  `json:"decimals"`
+	} `json:"uiTokenAmount"`
+}
+
+// newSolanaClient 创建Solana客户端
+func newSolanaClient(client *rpc.Client, wsURL, commitment string) *solanaClient {
+	if commitment == "" {
+		commitment = "confirmed"
+	}
+
+	return &solanaClient{rpc: client, wsURL: wsURL, commitment: commitment}
+}
+
+// Close 关闭连接
+func (sc *solanaClient) Close() {
+	sc.rpc.Close()
+}
+
+// isSolanaEmptySlot 判断错误是否表示该slot没有区块（跳过的slot）
+func isSolanaEmptySlot(err error) bool {
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	switch rpcErr.ErrorCode() {
+	case solanaSlotSkipped, solanaLongTermStorageSlot, solanaBlockNotAvailable:
+		return true
+	}
+	return false
+}
+
+// solanaAdapter Solana网络适配器，区块号使用slot
+type solanaAdapter struct {
+	bc *BlockchainCollector
+}
+
+func newSolanaAdapter(bc *BlockchainCollector) ChainAdapter {
+	return &solanaAdapter{bc: bc}
+}
+
+// Connect 连接RPC并通过getHealth校验
+func (a *solanaAdapter) Connect(connector *NetworkConnector) error {
+	rpcClient, err := a.bc.dialRPC(connector.name, connector.provider, connector.config, nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect to RPC: %w", err)
+	}
+
+	// 回放模式下不订阅slot，新区块通过轮询获取
+	wsURL := connector.config.WSURL
+	if a.bc.config.RPCRecording.Mode == rpcRecordingReplay {
+		wsURL = ""
+	}
+
+	client := newSolanaClient(rpcClient, wsURL, connector.config.Commitment)
+	connector.solana = client
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var health string
+	connector.recordCall("getHealth")
+	if err := client.rpc.CallContext(ctx, &health, "getHealth"); err != nil {
+		client.Close()
+		return fmt.Errorf("connection validation failed: %w", err)
+	}
+	return nil
+}
+
+// LatestBlock 获取当前确认级别下的最新slot
+func (a *solanaAdapter) LatestBlock(ctx context.Context, connector *NetworkConnector) (uint64, error) {
+	return connector.getSlot(ctx)
+}
+
+// getSlot 获取当前确认级别下的最新slot
+func (nc *NetworkConnector) getSlot(ctx context.Context) (uint64, error) {
+	var slot uint64
+	nc.recordCall("getSlot")
+	err := nc.solana.rpc.CallContext(ctx, &slot, "getSlot", map[string]interface{}{
+		"commitment": nc.solana.commitment,
+	})
+	return slot, nc.rpcError("getSlot", 0, err)
+}
+
+// getSolanaBlock 获取slot对应的区块（含完整交易），无区块时返回nil
+func (nc *NetworkConnector) getSolanaBlock(ctx context.Context, slot uint64) (*solanaBlock, error) {
+	var block *solanaBlock
+	nc.recordCall("getBlock")
+	err := nc.solana.rpc.CallContext(ctx, &block, "getBlock", slot, map[string]interface{}{
+		"commitment":                     nc.solana.commitment,
+		"encoding":                       "json",
+		"transactionDetails":             "full",
+		"rewards":                        true,
+		"maxSupportedTransactionVersion": 0,
+	})
+	if err != nil {
+		if isSolanaEmptySlot(err) {
+			return nil, nil
+		}
+		return nil, nc.rpcError("getBlock", slot, err)
+	}
+	return block, nil
+}
+
+// SubscribeHeads 通过WebSocket订阅slot变化，直到连接出错或ctx结束
+func (a *solanaAdapter) SubscribeHeads(ctx context.Context, connector *NetworkConnector, heads chan<- uint64) error {
+	if connector.solana.wsURL == "" {
+		return errHeadsUnsupported
+	}
+
+	err := a.readSlotSubscription(ctx, connector, heads)
+	if ctx.Err() != nil {
+		connector.subscriptionEnded(subscriptionSlots, nil)
+		return ctx.Err()
+	}
+	err = &faults.RPCError{Network: connector.name, Method: "slotSubscribe", Err: err}
+	connector.subscriptionEnded(subscriptionSlots, err)
+	return err
+}
+
+// readSlotSubscription 建立slot订阅并持续读取通知
+func (a *solanaAdapter) readSlotSubscription(ctx context.Context, connector *NetworkConnector, heads chan<- uint64) error {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, connector.solana.wsURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to connect to WebSocket: %w", err)
+	}
+	defer conn.Close()
+
+	// ctx结束时关闭连接以中断阻塞的读取
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+			return
+		}
+		conn.Close()
+	}()
+
+	connector.recordCall("slotSubscribe")
+	if err := conn.WriteJSON(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "slotSubscribe",
+	}); err != nil {
+		return fmt.Errorf("failed to subscribe to slots: %w", err)
+	}
+
+	logger.Infof("Subscribing to slots for network: %s", connector.name)
+	connector.subscriptionStarted(subscriptionSlots)
+
+	for {
+		var message struct {
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+			Method string `json:"method"`
+			Params struct {
+				Result struct {
+					Slot uint64 `json:"slot"`
+				} `json:"result"`
+			} `json:"params"`
+		}
+		if err := conn.ReadJSON(&message); err != nil {
+			return err
+		}
+		if message.Error != nil {
+			return fmt.Errorf("slotSubscribe rejected: %s", message.Error.Message)
+		}
+		if message.Method != "slotNotification" {
+			continue
+		}
+		connector.subscriptionMessage(subscriptionSlots)
+
+		// 通知的是processed级别的slot，实际处理仍以确认级别的slot为准
+		notifyHead(heads, 0)
+	}
+}
+
+// FetchBlock 获取slot对应的区块，跳过的slot返回nil
+func (a *solanaAdapter) FetchBlock(ctx context.Context, connector *NetworkConnector, slot uint64) (*ChainBlock, error) {
+	block, err := connector.getSolanaBlock(ctx, slot)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	return &ChainBlock{
+		Model:  convertSolanaBlock(block, slot, connector.name),
+		Raw:    block,
+		RawTxs: len(block.Transactions),
+	}, nil
+}
+
+// FetchReceipts 交易执行结果随区块返回，无需另外获取
+func (a *solanaAdapter) FetchReceipts(ctx context.Context, connector *NetworkConnector, block *ChainBlock) error {
+	return nil
+}
+
+// DecodeTx 转换交易，投票交易占据大部分交易量且无业务含义，不进入处理流程
+func (a *solanaAdapter) DecodeTx(connector *NetworkConnector, block *ChainBlock, index int) (*models.Transaction, error) {
+	tx := &block.Raw.(*solanaBlock).Transactions[index]
+	if !isSolanaProcessable(tx) {
+		return nil, nil
+	}
+	return convertSolanaTransaction(tx, uint(index), block.Model), nil
+}
+
+// EnrichBlock 暂不支持查询Solana账户余额，丢弃待检查余额清空的地址
+func (a *solanaAdapter) EnrichBlock(ctx context.Context, connector *NetworkConnector, block *ChainBlock, enriched *models.EnrichedBlock) {
+	if velocity := a.bc.dataProcessor.Velocity(); velocity != nil {
+		velocity.TakeDrainCandidates(connector.name)
+	}
+}
+
+// convertSolanaBlock 将Solana区块转换为通用区块模型，区块号使用slot，gas用量为处理的交易消耗的计算单元之和
+func convertSolanaBlock(block *solanaBlock, slot uint64, network string) *models.Block {
+	timestamp := time.Now()
+	if block.BlockTime != nil {
+		timestamp = time.Unix(*block.BlockTime, 0)
+	}
+
+	blockModel := &models.Block{
+		ID:         models.BlockID(network, block.Blockhash),
+		Number:     slot,
+		Hash:       block.Blockhash,
+		ParentHash: block.PreviousBlockhash,
+		Timestamp:  timestamp,
+		Difficulty: big.NewInt(0),
+		Network:    network,
+		Chain:      models.ChainSolana,
+	}
+
+	for _, reward := range block.Rewards {
+		if reward.RewardType == "Fee" {
+			blockModel.Miner = reward.Pubkey
+			break
+		}
+	}
+
+	for i := range block.Transactions {
+		tx := &block.Transactions[i]
+		if isSolanaProcessable(tx) && tx.Meta.ComputeUnitsConsumed != nil {
+			blockModel.GasUsed += *tx.Meta.ComputeUnitsConsumed
+		}
+	}
+
+	return blockModel
+}
+
+// isSolanaProcessable 是否为进入处理流程的交易：排除缺少执行结果的交易及投票交易
+func isSolanaProcessable(tx *solanaTransaction) bool {
+	return tx.Meta != nil && len(tx.Transaction.Signatures) > 0 && !isSolanaVoteTransaction(tx)
+}
+
+// convertSolanaTransaction 将Solana交易转换为通用交易模型
+// 发送方为手续费支付账户；有SPL代币余额变化时接收方为代币mint，否则为收到SOL最多的账户或首个调用的程序
+func convertSolanaTransaction(tx *solanaTransaction, index uint, block *models.Block) *models.Transaction {
+	meta := tx.Meta
+	accounts := solanaAccountKeys(tx)
+
+	txModel := &models.Transaction{
+		ID:               models.TransactionID(block.Network, block.Hash, index),
+		Hash:             tx.Transaction.Signatures[0],
+		BlockNumber:      block.Number,
+		BlockHash:        block.Hash,
+		TransactionIndex: index,
+		Value:            big.NewInt(0),
+		// Solana按笔收取手续费，Gas固定为1使GasPrice*Gas等于手续费(lamports)
+		Gas:        1,
+		GasPrice:   new(big.Int).SetUint64(meta.Fee),
+		Timestamp:  block.Timestamp,
+		Network:    block.Network,
+		Chain:      models.ChainSolana,
+		Status:     1,
+		HasReceipt: true,
+	}
+
+	if meta.Err != nil {
+		txModel.Status = 0
+	}
+	if meta.ComputeUnitsConsumed != nil {
+		txModel.GasUsed = *meta.ComputeUnitsConsumed
+	}
+	if len(accounts) > 0 {
+		txModel.FromAddress = accounts[0]
+	}
+
+	// 收到SOL最多的账户（手续费支付账户除外）
+	for i := 1; i < len(accounts) && i < len(meta.PreBalances) && i < len(meta.PostBalances); i++ {
+		if meta.PostBalances[i] <= meta.PreBalances[i] {
+			continue
+		}
+		received := new(big.Int).SetUint64(meta.PostBalances[i] - meta.PreBalances[i])
+		if received.Cmp(txModel.Value) > 0 {
+			txModel.Value = received
+			txModel.ToAddress = accounts[i]
+		}
+	}
+
+	var program string
+	for _, instruction := range tx.Transaction.Message.Instructions {
+		if instruction.ProgramIDIndex >= len(accounts) {
+			continue
+		}
+		id := accounts[instruction.ProgramIDIndex]
+		if id != solanaSystemProgram && id != solanaComputeBudgetProgram {
+			txModel.IsContractCall = true
+			if program == "" {
+				program = id
+			}
+		}
+	}
+	if txModel.ToAddress == "" {
+		txModel.ToAddress = program
+	}
+
+	changes := solanaTokenBalanceChanges(meta, accounts)
+	if len(changes) > 0 {
+		txModel.TokenBalanceChanges = changes
+		txModel.IsTokenTransfer = true
+
+		// 以增加最多的代币账户作为本笔转账的代币与数量
+		for _, change := range changes {
+			if change.Delta.Sign() > 0 && (txModel.TokenAmount == nil || change.Delta.Cmp(txModel.TokenAmount) > 0) {
+				txModel.TokenAmount = new(big.Int).Set(change.Delta)
+				txModel.TokenDecimals = change.Decimals
+				txModel.ToAddress = change.Mint
+			}
+		}
+	}
+
+	return txModel
+}
+
+// solanaAccountKeys 获取交易的完整账户列表（含v0交易通过地址查找表加载的账户）
+func solanaAccountKeys(tx *solanaTransaction) []string {
+	accounts := append([]string{}, tx.Transaction.Message.AccountKeys...)
+	if loaded := tx.Meta.LoadedAddresses; loaded != nil {
+		accounts = append(accounts, loaded.Writable...)
+		accounts = append(accounts, loaded.Readonly...)
+	}
+	return accounts
+}
+
+// isSolanaVoteTransaction 判断是否为验证者投票交易
+func isSolanaVoteTransaction(tx *solanaTransaction) bool {
+	accounts := tx.Transaction.Message.AccountKeys
+	for _, instruction := range tx.Transaction.Message.Instructions {
+		if instruction.ProgramIDIndex < len(accounts) && accounts[instruction.ProgramIDIndex] == solanaVoteProgram {
+			return true
+		}
+	}
+	return false
+}
+
+// solanaTokenBalanceChanges 比较交易前后的SPL代币余额，返回有变化的代币账户
+func solanaTokenBalanceChanges(meta *solanaTransactionMeta, accounts []string) []models.TokenBalanceChange {
+	type balancePair struct {
+		pre  *solanaTokenBalance
+		post *solanaTokenBalance
+	}
+
+	pairs := make(map[int]*balancePair)
+	order := []int{}
+	for i := range meta.PreTokenBalances {
+		balance := &meta.PreTokenBalances[i]
+		if _, exists := pairs[balance.AccountIndex]; !exists {
+			order = append(order, balance.AccountIndex)
+			pairs[balance.AccountIndex] = &balancePair{}
+		}
+		pairs[balance.AccountIndex].pre = balance
+	}
+	for i := range meta.PostTokenBalances {
+		balance := &meta.PostTokenBalances[i]
+		if _, exists := pairs[balance.AccountIndex]; !exists {
+			order = append(order, balance.AccountIndex)
+			pairs[balance.AccountIndex] = &balancePair{}
+		}
+		pairs[balance.AccountIndex].post = balance
+	}
+
+	var changes []models.TokenBalanceChange
+	for _, index := range order {
+		pair := pairs[index]
+		// 账户在交易中创建或关闭时只有一侧余额
+		known := pair.post
+		if known == nil {
+			known = pair.pre
+		}
+
+		pre := parseTokenAmount(pair.pre)
+		post := parseTokenAmount(pair.post)
+		delta := new(big.Int).Sub(post, pre)
+		if delta.Sign() == 0 {
+			continue
+		}
+
+		change := models.TokenBalanceChange{
+			Owner:      known.Owner,
+			Mint:       known.Mint,
+			Decimals:   known.UITokenAmount.Decimals,
+			PreAmount:  pre,
+			PostAmount: post,
+			Delta:      delta,
+		}
+		if index < len(accounts) {
+			change.Account = accounts[index]
+		}
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+// parseTokenAmount 解析代币余额，缺失时为0
+func parseTokenAmount(balance *solanaTokenBalance) *big.Int {
+	amount := big.NewInt(0)
+	if balance != nil {
+		if _, ok := amount.SetString(balance.UITokenAmount.Amount, 10); !ok {
+			amount.SetInt64(0)
+		}
+	}
+	return amount
+}
@@ -0,0 +1,62 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+
+	"web3-data-collector/internal/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// applyReceipts 获取区块全部交易回执，填充实际gas用量、执行状态及实际gas单价。
+// 需要区块内全部回执，未配置receipt_strategy时供应量统计以eth_getBlockReceipts一次获取，回执补全按默认的逐笔方式获取
+func (bc *BlockchainCollector) applyReceipts(ctx context.Context, connector *NetworkConnector, blockModel *models.Block) error {
+	var receipts []*types.Receipt
+	var err error
+	if connector.config.ReceiptStrategy == "" && !connector.config.Receipts.Enabled {
+		receipts, err = connector.blockReceipts(ctx, blockModel.Number)
+	} else {
+		hashes := make([]common.Hash, 0, len(blockModel.Transactions))
+		for i := range blockModel.Transactions {
+			hashes = append(hashes, common.HexToHash(blockModel.Transactions[i].Hash))
+		}
+		receipts, err = connector.receipts(ctx, blockModel.Number, hashes)
+	}
+	if err != nil {
+		return err
+	}
+
+	byHash := make(map[string]*types.Receipt, len(receipts))
+	for _, receipt := range receipts {
+		byHash[receipt.TxHash.Hex()] = receipt
+	}
+
+	for i := range blockModel.Transactions {
+		tx := &blockModel.Transactions[i]
+		receipt, exists := byHash[tx.Hash]
+		if !exists {
+			return fmt.Errorf("missing receipt for transaction %s", tx.Hash)
+		}
+		tx.GasUsed = receipt.GasUsed
+		tx.Status = receipt.Status
+		tx.EffectiveGasPrice = receipt.EffectiveGasPrice
+		tx.HasReceipt = true
+		if connector.config.Receipts.Logs {
+			tx.Logs = transactionLogs(receipt.Logs)
+		}
+	}
+	return nil
+}
+
+// blockReceipts 获取区块内全部交易回执
+func (nc *NetworkConnector) blockReceipts(ctx context.Context, blockNumber uint64) ([]*types.Receipt, error) {
+	if nc.rpcClient == nil {
+		return nil, fmt.Errorf("no RPC client available")
+	}
+
+	nc.recordCall("eth_getBlockReceipts")
+	return nc.rpcClient.BlockReceipts(ctx, rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(blockNumber)))
+}
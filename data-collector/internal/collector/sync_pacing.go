@@ -0,0 +1,218 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// pacingStep 延迟超过目标或请求失败时请求间隔的增加步长
+	pacingStep = 50 * time.Millisecond
+	// pacingAlpha 延迟及错误率指数移动平均的平滑系数
+	pacingAlpha = 0.2
+	// pacingErrorWeight 错误率对请求间隔的放大权重，错误率为100%时间隔放大到5倍
+	pacingErrorWeight = 4
+)
+
+// rateLimitPatterns 服务商以JSON-RPC错误（而非HTTP 429）返回限流时的常见错误信息
+var rateLimitPatterns = []string{
+	"429",
+	"too many requests",
+	"rate limit",
+	"exceeded the rate",
+	"request limit",
+}
+
+// syncPacer 历史同步的自适应节奏：根据RPC平均延迟、错误率及距链头的区块数决定请求间隔，
+// 节点健康时不等待以用满服务商容量，延迟升高时线性放慢，收到429时指数退避
+type syncPacer struct {
+	targetLatency    time.Duration
+	maxDelay         time.Duration
+	rateLimitBackoff time.Duration
+	catchUpBlocks    uint64
+	latency          time.Duration // RPC延迟的指数移动平均
+	errorRate        float64       // RPC失败率的指数移动平均
+	delay            time.Duration
+	retryAt          time.Time // 服务商通过Retry-After要求的最早重试时间
+	mu               sync.Mutex
+}
+
+// newSyncPacer 根据配置创建同步节奏控制，未启用时返回nil
+func newSyncPacer(cfg config.SyncPacingConfig) *syncPacer {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	return &syncPacer{
+		targetLatency:    parseDurationOr(cfg.TargetLatency, 500*time.Millisecond),
+		maxDelay:         parseDurationOr(cfg.MaxDelay, 10*time.Second),
+		rateLimitBackoff: parseDurationOr(cfg.RateLimitBackoff, time.Second),
+		catchUpBlocks:    cfg.CatchUpBlocks,
+	}
+}
+
+// parseDurationOr 解析时长，为空或无效时返回默认值
+func parseDurationOr(value string, fallback time.Duration) time.Duration {
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return fallback
+	}
+	return duration
+}
+
+// observe 记录一次RPC请求的延迟及结果，latency为0时不更新平均延迟，retryAfter为服务商要求的等待时长
+func (p *syncPacer) observe(latency time.Duration, failed, rateLimited bool, retryAfter time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.latency == 0 {
+		p.latency = latency
+	} else if latency > 0 {
+		p.latency = time.Duration(pacingAlpha*float64(latency) + (1-pacingAlpha)*float64(p.latency))
+	}
+	sample := 0.0
+	if failed || rateLimited {
+		sample = 1
+	}
+	p.errorRate = pacingAlpha*sample + (1-pacingAlpha)*p.errorRate
+
+	switch {
+	case rateLimited:
+		p.delay *= 2
+		if p.delay < p.rateLimitBackoff {
+			p.delay = p.rateLimitBackoff
+		}
+		if retryAfter > 0 {
+			p.retryAt = time.Now().Add(retryAfter)
+		}
+	case failed:
+		p.delay = p.delay * 3 / 2
+		if p.delay < pacingStep {
+			p.delay = pacingStep
+		}
+	case p.latency > p.targetLatency:
+		p.delay += pacingStep
+	default:
+		// 节点健康时快速收敛到不等待
+		p.delay /= 2
+		if p.delay < time.Millisecond {
+			p.delay = 0
+		}
+	}
+	if p.delay > p.maxDelay {
+		p.delay = p.maxDelay
+	}
+}
+
+// next 下一次同步请求前的等待时长，remaining为距链头或回填终点的区块数
+func (p *syncPacer) next(remaining uint64) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delay := time.Duration(float64(p.delay) * (1 + pacingErrorWeight*p.errorRate))
+	// 远落后链头时缩短间隔尽快追上，服务商要求的等待不缩短
+	if p.catchUpBlocks > 0 && remaining >= p.catchUpBlocks {
+		delay /= 2
+	}
+	if delay > p.maxDelay {
+		delay = p.maxDelay
+	}
+	if wait := time.Until(p.retryAt); wait > delay {
+		delay = wait
+	}
+	return delay
+}
+
+// isRateLimitError 判断RPC错误是否为服务商限流
+func isRateLimitError(err error) bool {
+	message := strings.ToLower(err.Error())
+	for _, pattern := range rateLimitPatterns {
+		if strings.Contains(message, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// reportRateLimit 判断错误是否为服务商限流，是时计入同步节奏（HTTP 429已由pacingTransport记录）
+func (nc *NetworkConnector) reportRateLimit(err error) bool {
+	if nc.pacer == nil || !isRateLimitError(err) {
+		return false
+	}
+	var httpErr rpc.HTTPError
+	if !errors.As(err, &httpErr) {
+		nc.pacer.observe(0, false, true, 0)
+	}
+	return true
+}
+
+// parseRetryAfter 解析Retry-After响应头（秒数或HTTP日期）
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
+
+// pacingTransport 记录HTTP JSON-RPC请求的延迟、失败及429响应，供同步节奏调整
+type pacingTransport struct {
+	base  http.RoundTripper
+	pacer *syncPacer
+}
+
+func (t *pacingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		// 调用方取消的请求不反映节点状态
+		if req.Context().Err() == nil {
+			t.pacer.observe(time.Since(start), true, false, 0)
+		}
+		return nil, err
+	}
+
+	rateLimited := resp.StatusCode == http.StatusTooManyRequests
+	failed := resp.StatusCode >= http.StatusInternalServerError
+	t.pacer.observe(time.Since(start), failed, rateLimited, parseRetryAfter(resp.Header.Get("Retry-After")))
+	return resp, nil
+}
+
+// paceSync 历史同步请求前按自适应节奏等待，ctx结束或收集器停止时返回false
+func (bc *BlockchainCollector) paceSync(ctx context.Context, connector *NetworkConnector, remaining uint64) bool {
+	if connector.pacer == nil {
+		return true
+	}
+
+	delay := connector.pacer.next(remaining)
+	bc.metricsManager.SetSyncPacingDelay(connector.name, delay)
+	if delay <= 0 {
+		return true
+	}
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-bc.stopChan:
+		return false
+	case <-time.After(delay):
+		return true
+	}
+}
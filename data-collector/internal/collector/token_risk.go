@@ -0,0 +1,300 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"web3-data-collector/internal/models"
+	"web3-data-collector/internal/processor"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// 所有权变更及Uniswap V2交易对事件签名（SushiSwap、PancakeSwap等分叉相同）
+var (
+	ownershipTransferredTopic = crypto.Keccak256Hash([]byte("OwnershipTransferred(address,address)"))
+	pairCreatedTopic          = crypto.Keccak256Hash([]byte("PairCreated(address,address,address,uint256)"))
+	pairBurnTopic             = crypto.Keccak256Hash([]byte("Burn(address,uint256,uint256,address)"))
+)
+
+// ownerSelector Ownable owner()，getReservesSelector Uniswap V2交易对 getReserves()
+var (
+	ownerSelector       = crypto.Keccak256([]byte("owner()"))[:4]
+	getReservesSelector = crypto.Keccak256([]byte("getReserves()"))[:4]
+)
+
+// tokenRiskEnabled 网络上是否启用代币跑路/貔貅检测
+func (bc *BlockchainCollector) tokenRiskEnabled(connector *NetworkConnector) bool {
+	return bc.dataProcessor.TokenRisk() != nil && connector.solana == nil
+}
+
+// processTokenRisk 分析区块内新部署的代币合约，记录已分析代币的所有权变更、交易对及流动性撤出，
+// 启用关注列表时检查关注地址与可疑代币的转账及调用，返回生成的告警
+func (bc *BlockchainCollector) processTokenRisk(ctx context.Context, connector *NetworkConnector, block *types.Block, blockModel *models.Block) []*models.RiskAlert {
+	monitor := bc.dataProcessor.TokenRisk()
+	watchlists := bc.dataProcessor.Watchlists()
+
+	// 合约创建交易部署的代币
+	for i := range blockModel.Transactions {
+		tx := &blockModel.Transactions[i]
+		if tx.ToAddress != "" {
+			continue
+		}
+		token := crypto.CreateAddress(common.HexToAddress(tx.FromAddress), tx.Nonce)
+		if _, err := bc.analyzeToken(ctx, connector, token, tx.FromAddress, tx.Hash, block.Number(), true); err != nil {
+			bc.reportWarning(err, connector.name, blockModel.Number, fmt.Sprintf("Failed to analyze token %s", token.Hex()))
+		}
+	}
+
+	topics := []common.Hash{ownershipTransferredTopic, pairCreatedTopic, pairBurnTopic}
+	if watchlists != nil {
+		topics = append(topics, erc20TransferTopic)
+	}
+	blockHash := block.Hash()
+	logs, err := connector.filterLogs(ctx, ethereum.FilterQuery{
+		BlockHash: &blockHash,
+		Topics:    [][]common.Hash{topics},
+	})
+	if err != nil {
+		bc.reportError(err, connector.name, block.NumberU64(), "Failed to get token risk logs")
+		bc.metricsManager.IncrementError(connector.name, "token_risk_error")
+		return nil
+	}
+
+	var watched map[string]bool
+	if watchlists != nil {
+		watched, err = watchlists.AddressSet(connector.name)
+		if err != nil {
+			bc.reportError(err, connector.name, block.NumberU64(), "Failed to load watched addresses")
+		}
+	}
+
+	var interactions []*processor.TokenInteraction
+	transferred := make(map[string]bool)
+	for i := range logs {
+		log := &logs[i]
+		switch log.Topics[0] {
+		case ownershipTransferredTopic:
+			if len(log.Topics) != 3 {
+				continue
+			}
+			if _, err := monitor.UpdateOwner(connector.name, log.Address.Hex(), topicAddress(log.Topics[2])); err != nil {
+				bc.reportWarning(err, connector.name, log.BlockNumber, fmt.Sprintf("Failed to update owner of token %s", log.Address.Hex()))
+			}
+
+		case pairCreatedTopic:
+			if len(log.Topics) != 3 || len(log.Data) < 32 {
+				continue
+			}
+			pair := common.BytesToAddress(log.Data[12:32]).Hex()
+			for index, topic := range log.Topics[1:] {
+				token := topicAddress(topic)
+				if _, exists, err := monitor.Profile(connector.name, token); err != nil || !exists {
+					continue
+				}
+				if err := monitor.RecordPair(connector.name, pair, token, index); err != nil {
+					bc.reportWarning(err, connector.name, log.BlockNumber, fmt.Sprintf("Failed to record pair %s of token %s", pair, token))
+				}
+			}
+
+		case pairBurnTopic:
+			if len(log.Topics) != 3 || len(log.Data) < 64 {
+				continue
+			}
+			bc.checkLiquidityPull(ctx, connector, log, blockModel)
+
+		case erc20TransferTopic:
+			// ERC-721的同名事件tokenId为indexed参数（4个topic），只处理ERC-20
+			if len(log.Topics) != 3 || len(log.Data) < 32 {
+				continue
+			}
+			from, to := topicAddress(log.Topics[1]), topicAddress(log.Topics[2])
+			if !watched[strings.ToLower(from)] && !watched[strings.ToLower(to)] {
+				continue
+			}
+			transferred[log.TxHash.Hex()+":"+log.Address.Hex()] = true
+			interactions = append(interactions, &processor.TokenInteraction{
+				ID:              models.TransferID(connector.name, log.BlockHash.Hex(), log.TxIndex, log.Index),
+				Network:         connector.name,
+				Token:           log.Address.Hex(),
+				Kind:            "transfer",
+				From:            from,
+				To:              to,
+				Amount:          dataWord(log.Data, 0),
+				TransactionHash: log.TxHash.Hex(),
+				BlockNumber:     blockModel.Number,
+				Timestamp:       blockModel.Timestamp,
+			})
+		}
+	}
+
+	// 关注地址直接调用代币合约（如授权），只检查已分析过的代币，同一交易已有该代币转账时不重复
+	for i := range blockModel.Transactions {
+		tx := &blockModel.Transactions[i]
+		if tx.ToAddress == "" || !tx.IsContractCall || !watched[strings.ToLower(tx.FromAddress)] {
+			continue
+		}
+		token := common.HexToAddress(tx.ToAddress).Hex()
+		if transferred[tx.Hash+":"+token] {
+			continue
+		}
+		interactions = append(interactions, &processor.TokenInteraction{
+			ID:              tx.ID,
+			Network:         connector.name,
+			Token:           token,
+			Kind:            "call",
+			From:            tx.FromAddress,
+			TransactionHash: tx.Hash,
+			BlockNumber:     blockModel.Number,
+			Timestamp:       blockModel.Timestamp,
+		})
+	}
+	if len(interactions) == 0 {
+		return nil
+	}
+
+	// 转账涉及的代币未分析过时先分析（如由工厂合约部署或在启用前部署）
+	profiles := make(map[string]*models.TokenRiskProfile)
+	for _, interaction := range interactions {
+		if _, checked := profiles[interaction.Token]; checked {
+			continue
+		}
+		profile, exists, err := monitor.Profile(connector.name, interaction.Token)
+		if err != nil {
+			bc.reportWarning(err, connector.name, block.NumberU64(), fmt.Sprintf("Failed to get token risk of %s", interaction.Token))
+			continue
+		}
+		if !exists && interaction.Kind == "transfer" {
+			profile, err = bc.analyzeToken(ctx, connector, common.HexToAddress(interaction.Token), "", "", block.Number(), false)
+			if err != nil {
+				bc.reportWarning(err, connector.name, block.NumberU64(), fmt.Sprintf("Failed to analyze token %s", interaction.Token))
+				continue
+			}
+		}
+		profiles[interaction.Token] = profile
+	}
+	for token, profile := range profiles {
+		if profile == nil {
+			delete(profiles, token)
+		}
+	}
+
+	return bc.dataProcessor.ProcessTokenInteractions(interactions, profiles)
+}
+
+// analyzeToken 分析合约字节码及owner并保存；requireToken时不是ERC-20代币返回nil，
+// 已发出转账事件的合约（如代理合约）不要求字节码包含ERC-20函数
+func (bc *BlockchainCollector) analyzeToken(ctx context.Context, connector *NetworkConnector, token common.Address, creator, txHash string, blockNumber *big.Int, requireToken bool) (*models.TokenRiskProfile, error) {
+	code, err := connector.codeAt(ctx, token)
+	if err != nil {
+		return nil, connector.rpcError("eth_getCode", 0, err)
+	}
+	analysis := processor.AnalyzeTokenCode(code)
+	if requireToken && !analysis.IsToken {
+		return nil, nil
+	}
+
+	profile := &models.TokenRiskProfile{
+		Network:       connector.name,
+		Token:         token.Hex(),
+		Creator:       creator,
+		CreationTx:    txHash,
+		AnalyzedBlock: blockNumber.Uint64(),
+	}
+	if analysis.HasOwner {
+		owner, err := connector.tokenOwner(ctx, token, blockNumber)
+		if err != nil {
+			logger.Debugf("Failed to get owner of token %s on %s: %v", token.Hex(), connector.name, err)
+		} else {
+			profile.Owner = owner.Hex()
+		}
+	}
+
+	if err := bc.dataProcessor.TokenRisk().Record(profile, analysis); err != nil {
+		return nil, err
+	}
+	if profile.Flagged {
+		logger.Infof("Token %s on %s flagged with risk score %.2f (%s)", profile.Token, connector.name, profile.Score, strings.Join(profile.Flags, ", "))
+	}
+	return profile, nil
+}
+
+// checkLiquidityPull 已分析代币的交易对被撤出流动性时，按撤出后的储备计算另一资产的撤出比例，达到阈值时记录撤池
+func (bc *BlockchainCollector) checkLiquidityPull(ctx context.Context, connector *NetworkConnector, log *types.Log, blockModel *models.Block) {
+	monitor := bc.dataProcessor.TokenRisk()
+	pair := log.Address.Hex()
+	token, index, exists, err := monitor.PairToken(connector.name, pair)
+	if err != nil {
+		bc.reportWarning(err, connector.name, log.BlockNumber, fmt.Sprintf("Failed to get token of pair %s", pair))
+		return
+	}
+	if !exists {
+		return
+	}
+
+	reserves, err := connector.pairReserves(ctx, log.Address, new(big.Int).SetUint64(log.BlockNumber))
+	if err != nil {
+		bc.reportWarning(err, connector.name, log.BlockNumber, fmt.Sprintf("Failed to get reserves of pair %s", pair))
+		return
+	}
+
+	// 另一资产（通常为WETH或稳定币）撤出量占撤出前储备的比例
+	other := 1 - index
+	removed := dataWord(log.Data, other)
+	before := new(big.Int).Add(removed, reserves[other])
+	if before.Sign() == 0 {
+		return
+	}
+	ratio, _ := new(big.Float).Quo(new(big.Float).SetInt(removed), new(big.Float).SetInt(before)).Float64()
+	if ratio < monitor.PullRatio() {
+		return
+	}
+
+	profile, err := monitor.RecordLiquidityPull(connector.name, token, pair, log.TxHash.Hex(), ratio, blockModel.Timestamp)
+	if err != nil {
+		bc.reportWarning(err, connector.name, log.BlockNumber, fmt.Sprintf("Failed to record liquidity pull of token %s", token))
+		return
+	}
+	if profile != nil {
+		logger.Warnf("Liquidity of token %s pulled from pair %s on %s (%.0f%% removed in %s)", token, pair, connector.name, ratio*100, log.TxHash.Hex())
+	}
+}
+
+// tokenOwner 通过owner()查询合约在指定区块的owner
+func (nc *NetworkConnector) tokenOwner(ctx context.Context, token common.Address, blockNumber *big.Int) (common.Address, error) {
+	if nc.rpcClient == nil {
+		return common.Address{}, fmt.Errorf("no RPC client available")
+	}
+
+	nc.recordCall("eth_call")
+	result, err := nc.rpcClient.CallContract(ctx, ethereum.CallMsg{To: &token, Data: ownerSelector}, blockNumber)
+	if err != nil {
+		return common.Address{}, nc.rpcError("eth_call", blockNumber.Uint64(), err)
+	}
+	if len(result) < 32 {
+		return common.Address{}, fmt.Errorf("invalid owner result")
+	}
+	return common.BytesToAddress(result[12:32]), nil
+}
+
+// pairReserves 通过getReserves查询Uniswap V2交易对在指定区块结束时的储备
+func (nc *NetworkConnector) pairReserves(ctx context.Context, pair common.Address, blockNumber *big.Int) ([2]*big.Int, error) {
+	if nc.rpcClient == nil {
+		return [2]*big.Int{}, fmt.Errorf("no RPC client available")
+	}
+
+	nc.recordCall("eth_call")
+	result, err := nc.rpcClient.CallContract(ctx, ethereum.CallMsg{To: &pair, Data: getReservesSelector}, blockNumber)
+	if err != nil {
+		return [2]*big.Int{}, nc.rpcError("eth_call", blockNumber.Uint64(), err)
+	}
+	if len(result) < 64 {
+		return [2]*big.Int{}, fmt.Errorf("invalid getReserves result")
+	}
+	return [2]*big.Int{dataWord(result, 0), dataWord(result, 1)}, nil
+}
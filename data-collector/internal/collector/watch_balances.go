@@ -0,0 +1,158 @@
+package collector
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"web3-data-collector/internal/models"
+	"web3-data-collector/internal/processor"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// watchBalancesEnabled 是否跟踪网络上关注地址的余额
+func (bc *BlockchainCollector) watchBalancesEnabled(connector *NetworkConnector) bool {
+	return bc.dataProcessor.WatchBalances() != nil && connector.solana == nil
+}
+
+// pollWatchedBalances 定期查询网络上全部关注地址在最新区块的余额，覆盖合约内部转账等区块处理中看不到的余额变化
+func (bc *BlockchainCollector) pollWatchedBalances(ctx context.Context, connector *NetworkConnector) {
+	defer bc.wg.Done()
+	defer bc.track(goroutineWatchBalances)()
+	defer bc.supervisor.Recover(goroutineWatchBalances, connector.name)
+
+	ticker := time.NewTicker(bc.dataProcessor.WatchBalances().Interval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-bc.stopChan:
+			return
+		case <-ticker.C:
+			addresses, err := bc.watchedAddresses(connector)
+			if err != nil {
+				logger.Errorf("Failed to get watched addresses for %s: %v", connector.name, err)
+				continue
+			}
+			if len(addresses) == 0 {
+				continue
+			}
+			blockNumber, err := connector.getLatestBlockNumber(ctx)
+			if err != nil {
+				logger.Warnf("Failed to get latest block for watched balances of %s: %v", connector.name, err)
+				continue
+			}
+			bc.refreshWatchedBalances(ctx, connector, addresses, new(big.Int).SetUint64(blockNumber), time.Now())
+		}
+	}
+}
+
+// processWatchedBalances 区块中的交易或监控代币的转账涉及关注地址时，查询这些地址在该区块后的余额，返回生成的回撤告警
+func (bc *BlockchainCollector) processWatchedBalances(ctx context.Context, connector *NetworkConnector, block *types.Block, blockModel *models.Block) []*models.RiskAlert {
+	watched, err := bc.watchedAddresses(connector)
+	if err != nil {
+		logger.Errorf("Failed to get watched addresses for %s: %v", connector.name, err)
+		return nil
+	}
+	if len(watched) == 0 {
+		return nil
+	}
+
+	touched := make(map[common.Address]bool)
+	for _, tx := range blockModel.Transactions {
+		for _, address := range []string{tx.FromAddress, tx.ToAddress} {
+			if address != "" && watched[common.HexToAddress(address)] {
+				touched[common.HexToAddress(address)] = true
+			}
+		}
+	}
+
+	if tokens := bc.dataProcessor.WatchBalances().Tokens(connector.name); len(tokens) > 0 {
+		blockHash := block.Hash()
+		logs, err := connector.filterLogs(ctx, ethereum.FilterQuery{
+			BlockHash: &blockHash,
+			Addresses: tokens,
+			Topics:    [][]common.Hash{{erc20TransferTopic}},
+		})
+		if err != nil {
+			logger.Errorf("Failed to get token transfers of block %d for %s: %v", block.NumberU64(), connector.name, err)
+			bc.metricsManager.IncrementError(connector.name, "watch_balance_error")
+		}
+		for _, log := range logs {
+			if len(log.Topics) != 3 {
+				continue
+			}
+			for _, topic := range log.Topics[1:] {
+				if address := common.BytesToAddress(topic.Bytes()); watched[address] {
+					touched[address] = true
+				}
+			}
+		}
+	}
+
+	if len(touched) == 0 {
+		return nil
+	}
+	return bc.refreshWatchedBalances(ctx, connector, touched, block.Number(), blockModel.Timestamp)
+}
+
+// refreshWatchedBalances 查询地址在指定区块的原生币及监控代币余额，交给数据处理器记录
+func (bc *BlockchainCollector) refreshWatchedBalances(ctx context.Context, connector *NetworkConnector, addresses map[common.Address]bool, blockNumber *big.Int, timestamp time.Time) []*models.RiskAlert {
+	tokens := bc.dataProcessor.WatchBalances().Tokens(connector.name)
+
+	var samples []*processor.BalanceSample
+	for address := range addresses {
+		balance, err := connector.balanceAt(ctx, address, blockNumber)
+		if err != nil {
+			logger.Warnf("Failed to get balance of %s for %s: %v", address.Hex(), connector.name, err)
+		} else {
+			samples = append(samples, &processor.BalanceSample{
+				Network:     connector.name,
+				Address:     address.Hex(),
+				Asset:       processor.WatchBalanceNative,
+				Balance:     balance,
+				BlockNumber: blockNumber.Uint64(),
+				Timestamp:   timestamp,
+			})
+		}
+
+		for _, token := range tokens {
+			balance, err := connector.tokenBalance(ctx, token, address, blockNumber)
+			if err != nil {
+				logger.Warnf("Failed to get %s balance of %s for %s: %v", token.Hex(), address.Hex(), connector.name, err)
+				continue
+			}
+			samples = append(samples, &processor.BalanceSample{
+				Network:     connector.name,
+				Address:     address.Hex(),
+				Asset:       token.Hex(),
+				Balance:     balance,
+				BlockNumber: blockNumber.Uint64(),
+				Timestamp:   timestamp,
+			})
+		}
+	}
+
+	return bc.dataProcessor.ProcessWatchedBalances(samples)
+}
+
+// watchedAddresses 网络上关注的EVM地址
+func (bc *BlockchainCollector) watchedAddresses(connector *NetworkConnector) (map[common.Address]bool, error) {
+	set, err := bc.dataProcessor.Watchlists().AddressSet(connector.name)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make(map[common.Address]bool, len(set))
+	for address := range set {
+		if common.IsHexAddress(address) {
+			addresses[common.HexToAddress(address)] = true
+		}
+	}
+	return addresses, nil
+}
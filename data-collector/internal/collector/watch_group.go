@@ -0,0 +1,290 @@
+package collector
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+	"text/template"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/faults"
+	"web3-data-collector/internal/models"
+	"web3-data-collector/internal/processor"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// 未配置模板时的默认告警标题及描述
+const (
+	defaultWatchTitle       = "{{.Group}}: {{.Event}}"
+	defaultWatchDescription = "{{.Network}} 合约 {{.Contract}} 触发 {{.Event}} 事件"
+)
+
+// watchRule 关注组中的单个事件规则
+type watchRule struct {
+	group       string
+	networks    map[string]bool // 为空表示全部网络
+	contracts   map[common.Address]bool
+	event       abi.Event
+	level       string
+	title       *template.Template
+	description *template.Template
+}
+
+// watchTemplateData 告警模板可用的字段
+type watchTemplateData struct {
+	Group       string
+	Event       string
+	Contract    string
+	Network     string
+	TxHash      string
+	BlockNumber uint64
+	Args        map[string]interface{}
+}
+
+// watchGroups 由配置编译的关注组规则，按事件topic0索引
+type watchGroups struct {
+	rules     map[common.Hash][]*watchRule
+	criteria  []logCriteria
+	ruleCount int
+}
+
+// newWatchGroups 编译关注组配置，无有效规则时返回nil，无法解析的事件声明或模板被忽略
+func newWatchGroups(configs []config.WatchGroupConfig) *watchGroups {
+	groups := &watchGroups{rules: make(map[common.Hash][]*watchRule)}
+
+	for _, group := range configs {
+		criteria := logCriteria{}
+		contracts := make(map[common.Address]bool)
+		for _, contract := range group.Contracts {
+			if !common.IsHexAddress(contract) {
+				logger.Warnf("Ignoring invalid contract %s in watch group %s", contract, group.Name)
+				continue
+			}
+			address := common.HexToAddress(contract)
+			contracts[address] = true
+			criteria.addresses = append(criteria.addresses, address)
+		}
+		if len(contracts) == 0 {
+			logger.Warnf("Watch group %s has no valid contracts, skipping", group.Name)
+			continue
+		}
+
+		var networks map[string]bool
+		if len(group.Networks) > 0 {
+			networks = make(map[string]bool, len(group.Networks))
+			for _, network := range group.Networks {
+				networks[network] = true
+			}
+		}
+
+		for _, eventCfg := range group.Events {
+			rule, err := compileWatchRule(group.Name, eventCfg)
+			if err != nil {
+				logger.Warnf("Ignoring event %q in watch group %s: %v", eventCfg.Signature, group.Name, err)
+				continue
+			}
+			rule.networks = networks
+			rule.contracts = contracts
+			groups.rules[rule.event.ID] = append(groups.rules[rule.event.ID], rule)
+			criteria.topics = append(criteria.topics, rule.event.ID)
+			groups.ruleCount++
+		}
+		if len(criteria.topics) > 0 {
+			groups.criteria = append(groups.criteria, criteria)
+		}
+	}
+
+	if groups.ruleCount == 0 {
+		return nil
+	}
+	logger.Infof("Watch groups enabled with %d event rules", groups.ruleCount)
+	return groups
+}
+
+// compileWatchRule 解析事件声明并编译告警模板
+func compileWatchRule(group string, cfg config.WatchEventConfig) (*watchRule, error) {
+	event, err := parseEventDeclaration(cfg.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := &watchRule{group: group, event: event, level: cfg.Level}
+	if rule.level == "" {
+		return rule, nil
+	}
+
+	title, description := cfg.Title, cfg.Description
+	if title == "" {
+		title = defaultWatchTitle
+	}
+	if description == "" {
+		description = defaultWatchDescription
+	}
+	if rule.title, err = template.New("title").Option("missingkey=zero").Parse(title); err != nil {
+		return nil, fmt.Errorf("invalid title template: %w", err)
+	}
+	if rule.description, err = template.New("description").Option("missingkey=zero").Parse(description); err != nil {
+		return nil, fmt.Errorf("invalid description template: %w", err)
+	}
+	return rule, nil
+}
+
+// parseEventDeclaration 解析事件声明，如 "Transfer(address indexed from, address indexed to, uint256 value)"，
+// 参数名可省略，不支持tuple参数
+func parseEventDeclaration(declaration string) (abi.Event, error) {
+	declaration = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(declaration), "event "))
+	open := strings.Index(declaration, "(")
+	if open <= 0 || !strings.HasSuffix(declaration, ")") {
+		return abi.Event{}, fmt.Errorf("invalid event declaration")
+	}
+	name := strings.TrimSpace(declaration[:open])
+	params := strings.TrimSpace(declaration[open+1 : len(declaration)-1])
+	if strings.ContainsAny(params, "()") {
+		return abi.Event{}, fmt.Errorf("tuple parameters are not supported")
+	}
+
+	var inputs abi.Arguments
+	if params != "" {
+		for _, param := range strings.Split(params, ",") {
+			fields := strings.Fields(param)
+			if len(fields) == 0 {
+				return abi.Event{}, fmt.Errorf("empty parameter")
+			}
+
+			typeName := fields[0]
+			// 签名中须使用规范类型名
+			switch {
+			case typeName == "uint" || strings.HasPrefix(typeName, "uint["):
+				typeName = "uint256" + typeName[len("uint"):]
+			case typeName == "int" || strings.HasPrefix(typeName, "int["):
+				typeName = "int256" + typeName[len("int"):]
+			}
+			typ, err := abi.NewType(typeName, "", nil)
+			if err != nil {
+				return abi.Event{}, fmt.Errorf("parameter %q: %w", param, err)
+			}
+
+			argument := abi.Argument{Type: typ}
+			for _, field := range fields[1:] {
+				if field == "indexed" {
+					argument.Indexed = true
+				} else {
+					argument.Name = field
+				}
+			}
+			inputs = append(inputs, argument)
+		}
+	}
+
+	return abi.NewEvent(name, name, false, inputs), nil
+}
+
+// match 查找日志匹配的规则
+func (groups *watchGroups) match(network string, log *types.Log) *watchRule {
+	if len(log.Topics) == 0 {
+		return nil
+	}
+	for _, rule := range groups.rules[log.Topics[0]] {
+		if rule.networks != nil && !rule.networks[network] {
+			continue
+		}
+		if rule.contracts[log.Address] {
+			return rule
+		}
+	}
+	return nil
+}
+
+// decode 解码事件参数，地址及整数等转为字符串以便JSON序列化
+func (wr *watchRule) decode(log *types.Log) (map[string]interface{}, error) {
+	raw := make(map[string]interface{})
+	if err := wr.event.Inputs.NonIndexed().UnpackIntoMap(raw, log.Data); err != nil {
+		return nil, fmt.Errorf("failed to unpack data: %w", err)
+	}
+
+	var indexed abi.Arguments
+	for _, input := range wr.event.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+	if err := abi.ParseTopicsIntoMap(raw, indexed, log.Topics[1:]); err != nil {
+		return nil, fmt.Errorf("failed to parse topics: %w", err)
+	}
+
+	args := make(map[string]interface{}, len(raw))
+	for name, value := range raw {
+		args[name] = formatWatchArg(value)
+	}
+	return args, nil
+}
+
+// render 按模板渲染告警标题及描述
+func (wr *watchRule) render(event *models.Event, args map[string]interface{}) *processor.WatchMatch {
+	match := &processor.WatchMatch{Group: wr.group, Event: wr.event.Sig, Level: wr.level}
+	if wr.level == "" {
+		return match
+	}
+
+	data := watchTemplateData{
+		Group:       wr.group,
+		Event:       wr.event.Sig,
+		Contract:    event.ContractAddress,
+		Network:     event.Network,
+		TxHash:      event.TransactionHash,
+		BlockNumber: event.BlockNumber,
+		Args:        args,
+	}
+	match.Title = executeWatchTemplate(wr.title, data)
+	match.Description = executeWatchTemplate(wr.description, data)
+	return match
+}
+
+// executeWatchTemplate 渲染模板，出错时返回已渲染的部分
+func executeWatchTemplate(tmpl *template.Template, data watchTemplateData) string {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		logger.Warnf("Failed to render watch group %s template %s: %v", data.Group, tmpl.Name(), err)
+	}
+	return buf.String()
+}
+
+// formatWatchArg 格式化解码后的参数值
+func formatWatchArg(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *big.Int:
+		return v.String()
+	case common.Address:
+		return v.Hex()
+	case common.Hash:
+		return v.Hex()
+	case []byte:
+		return hexutil.Encode(v)
+	case [32]byte:
+		return hexutil.Encode(v[:])
+	default:
+		return v
+	}
+}
+
+// watchEvent 关注组匹配的事件解码参数后发布，按规则生成告警
+func (bc *BlockchainCollector) watchEvent(rule *watchRule, log *types.Log, event *models.Event) error {
+	event.EventName = rule.event.Name
+	args, err := rule.decode(log)
+	if err != nil {
+		// 参数不符合声明（如同名事件的indexed不同）时仍发布原始事件
+		decodeErr := &faults.DecodeError{Network: event.Network, Kind: "log", Block: event.BlockNumber, Ref: rule.event.Sig, Err: err}
+		logger.WithFields(faults.Describe(decodeErr, "", 0).Fields()).Warnf("Failed to decode in watch group %s: %v", rule.group, decodeErr)
+		bc.metricsManager.RecordError(decodeErr, "", 0)
+	} else {
+		event.DecodedData = args
+	}
+
+	_, err = bc.dataProcessor.ProcessWatchEvent(event, rule.render(event, args))
+	return err
+}
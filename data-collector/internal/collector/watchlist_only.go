@@ -0,0 +1,142 @@
+package collector
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// watchTargets 仅关注模式下网络关注的地址及日志过滤条件
+type watchTargets struct {
+	addresses map[common.Address]bool
+	filter    *logFilter // 网络的日志过滤器（含关注组），未启用时为nil
+}
+
+// watchTargets 汇总关注列表中适用于网络的EVM地址及网络的日志过滤器
+func (bc *BlockchainCollector) watchTargets(connector *NetworkConnector) (*watchTargets, error) {
+	targets := &watchTargets{addresses: make(map[common.Address]bool), filter: connector.logFilter}
+
+	if watchlists := bc.dataProcessor.Watchlists(); watchlists != nil {
+		set, err := watchlists.AddressSet(connector.name)
+		if err != nil {
+			return nil, err
+		}
+		for address := range set {
+			if common.IsHexAddress(address) {
+				targets.addresses[common.HexToAddress(address)] = true
+			}
+		}
+	}
+
+	return targets, nil
+}
+
+// mayContain 根据logsBloom判断是否可能包含关注地址的日志（日志合约或indexed参数）或符合过滤条件的日志
+func (t *watchTargets) mayContain(bloom types.Bloom) bool {
+	if t.filter != nil && t.filter.mayContain(bloom) {
+		return true
+	}
+	for address := range t.addresses {
+		// indexed地址参数在topic中左补零到32字节
+		if types.BloomLookup(bloom, address) || types.BloomLookup(bloom, common.BytesToHash(address.Bytes())) {
+			return true
+		}
+	}
+	return false
+}
+
+// involves 判断地址是否为关注地址
+func (t *watchTargets) involves(address *common.Address) bool {
+	return address != nil && t.addresses[*address]
+}
+
+// receiptMatches 判断回执中是否有涉及关注地址或符合过滤条件的日志，过滤条件先按回执bloom排除
+func (t *watchTargets) receiptMatches(receipt *types.Receipt) bool {
+	if t.filter != nil && t.filter.mayContain(receipt.Bloom) {
+		for _, log := range receipt.Logs {
+			if t.filter.matches(log) {
+				return true
+			}
+		}
+	}
+
+	if len(t.addresses) == 0 {
+		return false
+	}
+	for _, log := range receipt.Logs {
+		if t.addresses[log.Address] {
+			return true
+		}
+		for i := 1; i < len(log.Topics); i++ {
+			if t.addresses[common.BytesToAddress(log.Topics[i].Bytes())] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SelectTransactions 仅关注模式下筛选交易：发起方或接收方为关注地址的交易直接选中；区块logsBloom可能包含
+// 关注地址或事件时获取区块回执，选中产生相关日志的交易，回执保留给日志过滤阶段使用
+func (a *evmAdapter) SelectTransactions(ctx context.Context, connector *NetworkConnector, block *ChainBlock) error {
+	raw := block.Raw.(*evmBlock)
+	targets, err := a.bc.watchTargets(connector)
+	if err != nil {
+		return err
+	}
+
+	var byHash map[common.Hash]*types.Receipt
+	block.SkipLogs = !targets.mayContain(raw.block.Bloom())
+	if !block.SkipLogs {
+		hashes := make([]common.Hash, 0, len(raw.txs))
+		for _, entry := range raw.txs {
+			if entry.system != nil {
+				hashes = append(hashes, entry.system.Hash)
+			} else {
+				hashes = append(hashes, entry.tx.Hash())
+			}
+		}
+		receipts, err := connector.receipts(ctx, raw.block.NumberU64(), hashes)
+		if err != nil {
+			return err
+		}
+		raw.receipts = receipts
+		byHash = make(map[common.Hash]*types.Receipt, len(receipts))
+		for _, receipt := range receipts {
+			byHash[receipt.TxHash] = receipt
+		}
+	}
+
+	signer := types.LatestSignerForChainID(big.NewInt(connector.config.ChainID))
+	selected := make([]int, 0)
+	for i, entry := range raw.txs {
+		var from, to *common.Address
+		var hash common.Hash
+		if entry.system != nil {
+			from, to, hash = &entry.system.From, entry.system.To, entry.system.Hash
+		} else {
+			// 发送者缓存在交易上，解码时不再重复恢复签名；无法恢复时只按接收方及日志匹配
+			sender, err := types.Sender(signer, entry.tx)
+			if err != nil {
+				logger.Debugf("Failed to recover sender of %s on %s: %v", entry.tx.Hash().Hex(), connector.name, err)
+			} else {
+				from = &sender
+			}
+			to, hash = entry.tx.To(), entry.tx.Hash()
+		}
+
+		if targets.involves(from) || targets.involves(to) {
+			selected = append(selected, i)
+			continue
+		}
+		if receipt, exists := byHash[hash]; exists && targets.receiptMatches(receipt) {
+			selected = append(selected, i)
+		}
+	}
+
+	block.Selected = selected
+	a.bc.metricsManager.RecordWatchlistOnlyBlock(connector.name, len(selected), len(raw.txs)-len(selected))
+	return nil
+}
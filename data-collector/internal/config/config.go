@@ -1,11 +1,13 @@
 package config
 
 import (
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
 	Server         ServerConfig         `yaml:"server"`
+	GRPC           GRPCConfig           `yaml:"grpc"`
 	Blockchain     BlockchainConfig     `yaml:"blockchain"`
 	Kafka          KafkaConfig          `yaml:"kafka"`
 	InfluxDB       InfluxDBConfig       `yaml:"influxdb"`
@@ -13,46 +15,516 @@ type Config struct {
 	Logging        LoggingConfig        `yaml:"logging"`
 	Metrics        MetricsConfig        `yaml:"metrics"`
 	DataProcessing DataProcessingConfig `yaml:"data_processing"`
+	Pricing        PricingConfig        `yaml:"pricing"`
+	ENS            ENSConfig            `yaml:"ens"`
+	Memory         MemoryConfig         `yaml:"memory"`
+	LeaderElection LeaderElectionConfig `yaml:"leader_election"`
+	Devtool        DevtoolConfig        `yaml:"devtool"`
+
+	secrets    map[string]bool // 由密钥引用解析出的配置路径，见 secrets.go
+	secretErrs []error         // 解析失败的密钥引用，带配置路径
 }
 
 type ServerConfig struct {
-	Port int    `yaml:"port"`
-	Mode string `yaml:"mode"`
+	Port        int    `yaml:"port"`
+	Mode        string `yaml:"mode"`
+	WatchConfig bool   `yaml:"watch_config"` // 监听配置文件变化并自动热加载
+	Auth        AuthConfig `yaml:"auth"`
+	Pprof       PprofConfig `yaml:"pprof"`
+	QueryCache  QueryCacheConfig `yaml:"query_cache"`
+	GraphQL     GraphQLConfig    `yaml:"graphql"`
+	OpenAPI     OpenAPIConfig    `yaml:"openapi"`
+	RateLimit   APIRateLimitConfig `yaml:"rate_limit"`
+	Readiness   ReadinessConfig  `yaml:"readiness"`
+	// 可信反向代理的IP或CIDR，仅来自这些地址的请求按X-Forwarded-For/X-Real-IP确定客户端IP；
+	// 为空时不信任任何代理，客户端IP为连接的对端地址
+	TrustedProxies []string `yaml:"trusted_proxies"`
+}
+
+// ReadinessConfig /api/v1/readyz就绪检查，至少一个网络落后链头不超过max_block_lag个区块时视为已同步
+type ReadinessConfig struct {
+	MaxBlockLag int `yaml:"max_block_lag"`
+}
+
+// APIRateLimitConfig API限流，令牌桶保存在Redis中，多实例共享。
+// 按调用方（API key或JWT主体）及客户端IP分别限流，/admin/*与其余接口使用各自的令牌桶
+type APIRateLimitConfig struct {
+	Enabled bool            `yaml:"enabled"`
+	PerKey  RateLimitBucket `yaml:"per_key"` // 每个认证调用方，未启用认证时不生效
+	PerIP   RateLimitBucket `yaml:"per_ip"`  // 每个客户端IP，认证前检查
+}
+
+// RateLimitBucket 令牌桶，每秒补充rate个令牌，最多积累burst个
+type RateLimitBucket struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+// OpenAPIConfig /api/v1/openapi.json及/api/v1/docs（Swagger UI），文档由路由生成，不需要认证。
+// Swagger UI的页面内置，脚本及样式从ui_assets_url加载
+type OpenAPIConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	UIAssetsURL string `yaml:"ui_assets_url"`
+}
+
+// GraphQLConfig /graphql查询接口，max_depth限制查询的嵌套层数，max_page_size限制列表字段单次返回的条数
+type GraphQLConfig struct {
+	Enabled     bool `yaml:"enabled"`
+	MaxDepth    int  `yaml:"max_depth"`
+	MaxPageSize int  `yaml:"max_page_size"`
+}
+
+// QueryCacheConfig 查询接口结果缓存，缓存在Redis中按网络最新区块失效，ttl为最长缓存时间
+type QueryCacheConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	TTL     string `yaml:"ttl"`
+}
+
+// PprofConfig pprof诊断接口，挂载在/admin下，未启用认证时对外开放，默认关闭
+type PprofConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// AuthConfig API认证配置，启用后/admin/*接口要求admin角色，其余接口要求read角色
+type AuthConfig struct {
+	Enabled   bool           `yaml:"enabled"`
+	JWTSecret string         `yaml:"jwt_secret"` // HS256签名密钥，为空时不接受JWT
+	JWTIssuer string         `yaml:"jwt_issuer"` // 设置后校验JWT的iss
+	APIKeys   []APIKeyConfig `yaml:"api_keys"`   // 静态API key，另可通过/admin/apikeys动态管理
+}
+
+// APIKeyConfig 静态API key
+type APIKeyConfig struct {
+	Name string `yaml:"name"`
+	Key  string `yaml:"key"`
+	Role string `yaml:"role"` // read / admin
+}
+
+// GRPCConfig gRPC服务配置
+type GRPCConfig struct {
+	Enabled          bool   `yaml:"enabled"`
+	Host             string `yaml:"host"` // 监听地址，默认127.0.0.1；认证沿用server.auth
+	Port             int    `yaml:"port"`
+	StreamBufferSize int    `yaml:"stream_buffer_size"`
 }
 
 type BlockchainConfig struct {
-	Networks map[string]NetworkConfig `yaml:"networks"`
+	Networks    map[string]NetworkConfig `yaml:"networks"`
+	AutoDisable AutoDisableConfig        `yaml:"auto_disable"`
+	LogFilter   LogFilterConfig          `yaml:"log_filter"`
+	RPCCost     RPCCostConfig            `yaml:"rpc_cost"`
+	FlashLoan   FlashLoanConfig          `yaml:"flash_loan"`
+	// 历史同步（日志回填、落后区块补处理）按RPC延迟、错误率及落后区块数自适应调整请求间隔
+	SyncPacing SyncPacingConfig `yaml:"sync_pacing"`
+	// 链头停滞检测：链头长时间未前进时发送CHAIN_STALLED运维告警
+	StallDetection StallDetectionConfig `yaml:"stall_detection"`
+	// 区块连续性检查：校验已处理区块的区块号连续及父哈希，遗漏或被链重组替换的区块自动重新获取
+	Continuity ContinuityConfig `yaml:"continuity"`
+	// RPC响应录制/回放，用于无节点的确定性测试与问题复现
+	RPCRecording RPCRecordingConfig `yaml:"rpc_recording"`
+	// 运行模式：hybrid（实时+历史回填）、realtime（仅实时）、backfill（仅回填：从start_block处理到启动时的链头，不订阅不轮询）
+	RunMode string `yaml:"run_mode"`
+	// 合约关注组，合约地址及事件自动并入日志过滤，匹配的事件按模板解码并告警
+	WatchGroups []WatchGroupConfig `yaml:"watch_groups"`
+	// 网络分片：多个实例通过Redis协调，各自只处理分配到的网络
+	Sharding ShardingConfig `yaml:"sharding"`
+	// 仅关注模式：EVM网络只解码涉及关注地址（关注列表）或日志过滤条件（含关注组）的交易，
+	// 按区块logsBloom及交易回执跳过不相关的区块和交易
+	WatchlistOnly bool `yaml:"watchlist_only"`
+}
+
+// ShardingConfig 网络分片配置，同一组实例使用相同的key_prefix及网络配置
+type ShardingConfig struct {
+	Enabled           bool   `yaml:"enabled"`
+	KeyPrefix         string `yaml:"key_prefix"`
+	NodeID            string `yaml:"node_id"`            // 为空时使用主机名及进程号
+	LeaseDuration     string `yaml:"lease_duration"`     // 实例心跳及网络锁的有效期，实例失联后其网络最迟在该时长后被接管
+	HeartbeatInterval string `yaml:"heartbeat_interval"` // 心跳、续期及重新分配的间隔
+}
+
+// WatchGroupConfig 合约关注组，如一个协议的全部合约
+type WatchGroupConfig struct {
+	Name      string             `yaml:"name"`
+	Networks  []string           `yaml:"networks"` // 为空表示全部EVM网络
+	Contracts []string           `yaml:"contracts"`
+	Events    []WatchEventConfig `yaml:"events"`
+}
+
+// WatchEventConfig 关注的事件及告警模板
+type WatchEventConfig struct {
+	// 事件声明，如 "Paused(address account)"、"Transfer(address indexed from, address indexed to, uint256 value)"
+	Signature string `yaml:"signature"`
+	Level     string `yaml:"level"` // LOW/MEDIUM/HIGH/CRITICAL，为空时只解码发布事件不告警
+	// text/template模板，可用 .Group .Event .Contract .Network .TxHash .BlockNumber .Args
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+}
+
+// FlashLoanConfig 闪电贷检测配置
+type FlashLoanConfig struct {
+	Enabled   bool `yaml:"enabled"`
+	UseTraces bool `yaml:"use_traces"` // 通过debug_traceTransaction识别Uniswap V2闪电兑换，需节点开启debug接口
+}
+
+// StallDetectionConfig 链头停滞检测配置
+type StallDetectionConfig struct {
+	Enabled     bool    `yaml:"enabled"`
+	Multiplier  float64 `yaml:"multiplier"`   // 链头超过 multiplier×出块间隔 未前进视为停滞
+	MinDuration string  `yaml:"min_duration"` // 停滞判定的最短时长，避免出块快的链受轮询间隔影响误报
+}
+
+// ContinuityConfig 区块连续性检查配置
+type ContinuityConfig struct {
+	Enabled    bool `yaml:"enabled"`
+	MaxQueued  int  `yaml:"max_queued"`  // 每个网络排队重新获取的区块数上限，超出的只计入指标及报告
+	MaxRetries int  `yaml:"max_retries"` // 重新获取失败后的重试次数上限
+	History    int  `yaml:"history"`     // 每个网络报告保留的最近问题数
+}
+
+// RPCRecordingConfig RPC响应录制/回放配置
+type RPCRecordingConfig struct {
+	Mode string `yaml:"mode"` // 为空时关闭，record：录制到目录，replay：从目录回放
+	Dir  string `yaml:"dir"`  // 录制文件目录，每个网络一个 <network>.jsonl
+}
+
+// RPCCostConfig RPC调用成本核算配置
+type RPCCostConfig struct {
+	Enabled        bool                          `yaml:"enabled"`
+	Providers      map[string]RPCProviderPricing `yaml:"providers"`        // 按服务商名称配置单价
+	DailyBudgetUSD float64                       `yaml:"daily_budget_usd"` // 全部网络的每日预算，0表示不限
+	NetworkBudgets map[string]float64            `yaml:"network_budgets"`  // 按网络的每日预算(USD)
+}
+
+// RPCProviderPricing RPC服务商单价（USD/次）
+type RPCProviderPricing struct {
+	DefaultCostUSD float64            `yaml:"default_cost_usd"`
+	Methods        map[string]float64 `yaml:"methods"` // 按方法覆盖单价，如 eth_getLogs
+}
+
+// LogFilterConfig 合约日志过滤配置，启用后仅获取可能包含关注地址/事件的区块回执
+type LogFilterConfig struct {
+	Enabled   bool              `yaml:"enabled"`
+	Addresses []string          `yaml:"addresses"` // 关注的合约地址，为空表示不限
+	Topics    []string          `yaml:"topics"`    // 关注的事件签名(topic0)，为空表示不限
+	Backfill  LogBackfillConfig `yaml:"backfill"`
+}
+
+// LogBackfillConfig 历史日志回填配置，通过eth_getLogs按区块区间分段拉取
+type LogBackfillConfig struct {
+	Enabled        bool   `yaml:"enabled"`
+	LookbackBlocks uint64 `yaml:"lookback_blocks"` // 网络未指定起始区块时回填的区块数
+	InitialRange   uint64 `yaml:"initial_range"`   // 初始分段区块数
+	MinRange       uint64 `yaml:"min_range"`       // 节点限制时缩小分段的下限
+	MaxRange       uint64 `yaml:"max_range"`       // 连续成功时放大分段的上限
+}
+
+// SyncPacingConfig 历史同步自适应节奏配置，节点健康时不等待，延迟升高或出错时逐步放慢
+type SyncPacingConfig struct {
+	Enabled          bool   `yaml:"enabled"`
+	TargetLatency    string `yaml:"target_latency"`     // RPC平均延迟超过该值时增加请求间隔，如 "500ms"
+	MaxDelay         string `yaml:"max_delay"`          // 请求间隔上限，如 "10s"
+	RateLimitBackoff string `yaml:"rate_limit_backoff"` // 收到429后的最小请求间隔，之后每次429翻倍
+	CatchUpBlocks    uint64 `yaml:"catch_up_blocks"`    // 距链头超过该区块数时间隔减半，尽快追上
+}
+
+// AutoDisableConfig 故障网络自动停用配置
+type AutoDisableConfig struct {
+	Enabled              bool   `yaml:"enabled"`
+	MaxStartupFailures   int    `yaml:"max_startup_failures"`   // 启动验证连续失败次数上限
+	StartupRetryInterval string `yaml:"startup_retry_interval"` // 启动验证重试间隔，如 "10s"
+	DownTimeout          string `yaml:"down_timeout"`           // 持续不可用超过该时长后停用，如 "10m"
 }
 
 type NetworkConfig struct {
-	RPCURL  string `yaml:"rpc_url"`
-	WSURL   string `yaml:"ws_url"`
-	ChainID int64  `yaml:"chain_id"`
-	Enabled bool   `yaml:"enabled"`
+	RPCURL         string               `yaml:"rpc_url"`
+	WSURL          string               `yaml:"ws_url"`
+	ChainID        int64                `yaml:"chain_id"`
+	Enabled        bool                 `yaml:"enabled"`
+	NativeCurrency NativeCurrencyConfig `yaml:"native_currency"`
+	// 日志回填起始区块，为0时按lookback_blocks计算
+	LogBackfillFrom uint64 `yaml:"log_backfill_from"`
+	// RPC服务商名称，用于成本核算，为空时取rpc_url的主机名
+	RPCProvider string `yaml:"rpc_provider"`
+	// 链类型：evm(默认)或solana
+	Chain string `yaml:"chain"`
+	// Solana确认级别：processed/confirmed/finalized，默认confirmed
+	Commitment string `yaml:"commitment"`
+	// 区块头extraData解码格式：builder/clique/arbitrum/optimism/none，为空时按chain_id选择
+	ExtraDataFormat string `yaml:"extra_data_format"`
+	// 预期出块间隔，如 "12s"，为空时按chain_id选择，未知网络不做停滞检测
+	BlockTime string `yaml:"block_time"`
+	// RPC请求限流，避免回填或回执拉取耗尽付费套餐额度
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	// 窗口类风险规则的时间来源：received(默认，收集器收到区块的时间)或block(区块时间戳)
+	TimeSource string `yaml:"time_source"`
+	// 区块时间领先本地时钟超过该值时视为时钟偏差，落后超过该值的区块视为补块，默认 "30s"
+	MaxClockSkew string `yaml:"max_clock_skew"`
+	// 处理的区块：latest(默认，最新区块)、safe或finalized（以区块标签查询，不受链重组影响），仅EVM网络
+	BlockTag string `yaml:"block_tag"`
+	// 高吞吐网络的交易发布抽样，区块照常发布，风险检测及告警不受影响
+	Sampling SamplingConfig `yaml:"sampling"`
+	// 轮询新区块的间隔，如 "1s"，为空时EVM网络为5s，Solana网络为2s
+	PollInterval string `yaml:"poll_interval"`
+	// 处理前要求的确认区块数，block_tag为latest时生效；大于0时不订阅新区块头及日志，由轮询推进
+	Confirmations uint64 `yaml:"confirmations"`
+	// 首次启动时开始处理的区块，为0时从最新区块开始；落后的区块按同步节奏补处理。
+	// 网络重新启用或热加载重启时从上次处理到的区块继续，不再回退到该区块
+	StartBlock uint64 `yaml:"start_block"`
+	// 单次轮询最多处理的区块数，为0时不限（Solana网络为100），其余留到下次轮询
+	MaxBatchSize int `yaml:"max_batch_size"`
+	// 交易回执获取方式：transaction(默认，逐笔eth_getTransactionReceipt)或block(eth_getBlockReceipts，需节点支持)
+	ReceiptStrategy string `yaml:"receipt_strategy"`
+	// L2网络（Arbitrum、OP Stack）获取交易回执中的L1数据费用，启用供应量统计时总会获取
+	L1Fees bool `yaml:"l1_fees"`
+	// 获取每笔交易的回执填充gas_used、status等字段，失败交易过滤规则依赖status
+	Receipts ReceiptsConfig `yaml:"receipts"`
+	// 网络级合约日志过滤，配置了地址或事件时替代blockchain.log_filter的条件，仅EVM网络
+	LogFilter NetworkLogFilterConfig `yaml:"log_filter"`
+}
+
+// NetworkLogFilterConfig 网络级合约日志过滤条件，用于日志订阅、回执过滤及日志回填；
+// 配置后该网络按过滤模式处理日志，无需开启blockchain.log_filter
+type NetworkLogFilterConfig struct {
+	Addresses []string `yaml:"addresses"` // 关注的合约地址，为空表示不限
+	Topics    []string `yaml:"topics"`    // 关注的事件签名(topic0)，为空表示不限
+}
+
+// ReceiptsConfig 交易回执补全，仅EVM网络
+type ReceiptsConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// 交易消息中包含回执日志（仅JSON编码）
+	Logs bool `yaml:"logs"`
+	// 逐笔获取回执时每个JSON-RPC批量请求包含的回执数，为0时为100
+	BatchSize int `yaml:"batch_size"`
+}
+
+// SamplingConfig 交易发布抽样配置，达到阈值的交易总是发布，其余按比例抽样
+type SamplingConfig struct {
+	Enabled     bool    `yaml:"enabled"`
+	Rate        float64 `yaml:"rate"`          // 低于阈值的交易的发布比例[0, 1]，为0时只发布达到阈值或被过滤规则命中的交易
+	MinValue    string  `yaml:"min_value"`     // 以原生币为单位，达到该金额的交易总是发布，如 "10"
+	MinValueUSD float64 `yaml:"min_value_usd"` // 达到该美元价值的交易（含代币转账）总是发布
+	KeepMatched bool    `yaml:"keep_matched"`  // 被include_addresses或include规则命中的交易总是发布
+}
+
+// RateLimitConfig 令牌桶限流配置，requests_per_second为0时不限流
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `yaml:"requests_per_second"`
+	Burst             int     `yaml:"burst"` // 桶容量，为0时取每秒请求数
+}
+
+// NativeCurrencyConfig 网络原生币配置
+type NativeCurrencyConfig struct {
+	Symbol             string  `yaml:"symbol"`
+	Decimals           uint8   `yaml:"decimals"`
+	USDPrice           float64 `yaml:"usd_price"`
+	HighValueThreshold string  `yaml:"high_value_threshold"` // 以原生币为单位，如 "1000"
+	// 以美元计的大额阈值，有实时价格时优先使用
+	HighValueThresholdUSD float64 `yaml:"high_value_threshold_usd"`
+	CoinGeckoID           string  `yaml:"coingecko_id"`   // 原生币在CoinGecko的ID，如 "ethereum"
+	PricePlatform         string  `yaml:"price_platform"` // 代币价格查询平台，如 "ethereum"
+	// 每个区块的发行奖励（原生币单位），PoS链执行层为0
+	BlockReward string `yaml:"block_reward"`
+}
+
+// DevtoolConfig 开发工具配置
+type DevtoolConfig struct {
+	Traffic TrafficConfig `yaml:"traffic"`
+}
+
+// TrafficConfig 测试网合成交易生成配置
+type TrafficConfig struct {
+	Network         string         `yaml:"network"`          // 发送交易的网络，只允许测试网
+	PrivateKeyEnv   string         `yaml:"private_key_env"`  // 保存已注资开发私钥的环境变量名
+	Rate            float64        `yaml:"rate"`             // 每秒交易数
+	Pattern         string         `yaml:"pattern"`          // constant/burst/ramp
+	BurstSize       int            `yaml:"burst_size"`       // burst模式每批交易数
+	Duration        string         `yaml:"duration"`         // 运行时长，为空时持续运行
+	ValueWei        string         `yaml:"value_wei"`        // 普通转账金额
+	HighValueWei    string         `yaml:"high_value_wei"`   // 大额转账金额，应高于网络的大额阈值
+	Recipients      []string       `yaml:"recipients"`       // 转账接收地址，为空时转给自己
+	ContractAddress string         `yaml:"contract_address"` // contract_call场景的调用目标
+	Scenarios       map[string]int `yaml:"scenarios"`        // 场景权重：transfer/high_value/self_transfer/contract_call
+}
+
+// PricingConfig 价格服务配置
+type PricingConfig struct {
+	Enabled        bool   `yaml:"enabled"`
+	CoinGeckoURL   string `yaml:"coingecko_url"`
+	APIKey         string `yaml:"api_key"`
+	CacheTTL       string `yaml:"cache_ttl"`
+	RequestTimeout string `yaml:"request_timeout"`
+}
+
+// ENSConfig ENS名称解析配置，通过以太坊主网节点查询注册表
+type ENSConfig struct {
+	Enabled         bool   `yaml:"enabled"`
+	RPCURL          string `yaml:"rpc_url"`
+	RegistryAddress string `yaml:"registry_address"` // 为空时使用主网ENS注册表
+	CacheTTL        string `yaml:"cache_ttl"`
+	RequestTimeout  string `yaml:"request_timeout"`
+}
+
+// MemoryConfig 内存预算与降载配置，阈值为堆内存占预算的比例
+type MemoryConfig struct {
+	Enabled        bool    `yaml:"enabled"`
+	BudgetMB       int     `yaml:"budget_mb"`
+	CheckInterval  string  `yaml:"check_interval"`
+	ShedEnrichment float64 `yaml:"shed_enrichment"` // 跳过价格查询、MEV/闪电贷/授权盗取分析
+	Sampling       float64 `yaml:"sampling"`        // 交易按sample_rate抽样发布，风险检测不受影响
+	PauseIngest    float64 `yaml:"pause_ingest"`    // 暂停历史日志回填
+	SampleRate     float64 `yaml:"sample_rate"`
+}
+
+// LeaderElectionConfig 主备部署的主实例选举，只有持有锁的实例订阅和处理区块
+type LeaderElectionConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	Backend       string `yaml:"backend"`        // 锁的存储，目前支持redis
+	Key           string `yaml:"key"`            // 同一组副本使用相同的键
+	NodeID        string `yaml:"node_id"`        // 为空时使用主机名及进程号
+	LeaseDuration string `yaml:"lease_duration"` // 锁的有效期，主实例失联后备实例最迟在该时长后接管
+	RetryInterval string `yaml:"retry_interval"` // 备实例抢锁及主实例续期的间隔
 }
 
 type KafkaConfig struct {
 	Brokers  []string     `yaml:"brokers"`
 	Topics   TopicsConfig `yaml:"topics"`
 	Producer ProducerConfig `yaml:"producer"`
+	Region   RegionConfig `yaml:"region"`
+	// 按主题选择消息编码：json(默认)/avro/protobuf，仅transactions/blocks/alerts支持后两者
+	Encoding       map[string]string    `yaml:"encoding"`
+	SchemaRegistry SchemaRegistryConfig `yaml:"schema_registry"`
+	// 消息后端：kafka(默认)/kinesis/pubsub，后两者以topics中的名称作为stream名/topic ID，消息内容与头相同
+	Backend string        `yaml:"backend"`
+	Kinesis KinesisConfig `yaml:"kinesis"`
+	PubSub  PubSubConfig  `yaml:"pubsub"`
+}
+
+// KinesisConfig AWS Kinesis Data Streams后端配置，凭证为空时读取AWS_ACCESS_KEY_ID等环境变量
+type KinesisConfig struct {
+	Region          string `yaml:"region"`
+	Endpoint        string `yaml:"endpoint"` // 为空时使用区域的默认端点，可指向localstack等兼容服务
+	AccessKeyID     string `yaml:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key"`
+	SessionToken    string `yaml:"session_token"`
+}
+
+// PubSubConfig Google Cloud Pub/Sub后端配置
+type PubSubConfig struct {
+	ProjectID string `yaml:"project_id"`
+	Endpoint  string `yaml:"endpoint"` // 为空时使用默认端点，设置PUBSUB_EMULATOR_HOST时连接模拟器且不认证
+	// 服务账号密钥文件，为空时读取GOOGLE_APPLICATION_CREDENTIALS，仍为空时从GCE元数据服务获取令牌
+	CredentialsFile string `yaml:"credentials_file"`
+}
+
+// SchemaRegistryConfig Confluent Schema Registry配置，avro/protobuf编码的主题以 主题名-value 注册schema
+type SchemaRegistryConfig struct {
+	URL      string `yaml:"url"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// RegionConfig 多区域部署配置，消息附加来源区域头，下游可按message_id去重或只取主区域数据
+type RegionConfig struct {
+	Name          string `yaml:"name"`           // 本实例所在区域，为空时不附加区域头
+	PrimaryRegion string `yaml:"primary_region"` // 主区域名称，设置后附加region_role头(primary/secondary)
 }
 
 type TopicsConfig struct {
-	Transactions string `yaml:"transactions"`
-	Blocks       string `yaml:"blocks"`
-	Alerts       string `yaml:"alerts"`
+	Transactions    string `yaml:"transactions"`
+	Blocks          string `yaml:"blocks"`
+	Alerts          string `yaml:"alerts"`
+	Events          string `yaml:"events"`
+	Headers         string `yaml:"headers"`          // 低延迟区块头摘要
+	GasStats        string `yaml:"gas_stats"`        // 按区块的gas费用统计
+	Withdrawals     string `yaml:"withdrawals"`      // 信标链提款
+	BridgeTransfers string `yaml:"bridge_transfers"` // 跨链桥存入及到账
+	EnrichedBlocks  string `yaml:"enriched_blocks"`  // 处理完成的完整区块
+	DeadLetter      string `yaml:"dead_letter"`      // 发布失败的数据（kafka死信后端）
 }
 
 type ProducerConfig struct {
 	BatchSize    int    `yaml:"batch_size"`
 	BatchTimeout string `yaml:"batch_timeout"`
+	// 幂等写入：等待全部ISR副本确认，消息带确定性的message_id头供下游去重
+	Idempotent  bool `yaml:"idempotent"`
+	MaxAttempts int  `yaml:"max_attempts"` // 单条消息的最大写入次数
+	// 事务模式：区块内的交易消息在区块处理完成后以一个Kafka事务写出并记录检查点，已提交的区块重新处理时不再写出。
+	// 仅kafka后端，消费端需设置isolation.level=read_committed
+	Transactional bool `yaml:"transactional"`
+	// 事务生产者ID，各实例须不同且重启后保持不变，新实例初始化时broker中止同ID旧实例未完成的事务
+	TransactionalID string `yaml:"transactional_id"`
+	// 消息压缩：none/gzip/snappy/lz4/zstd，仅kafka后端，kinesis/pubsub忽略
+	Compression string `yaml:"compression"`
+	// 单条消息及单次批量写入的字节上限（压缩前），应不大于broker的message.max.bytes，超出的批量拆分写入
+	MaxMessageBytes int `yaml:"max_message_bytes"`
+	// blocks主题的区块消息不含交易列表，交易仅经transactions主题发布
+	OmitBlockTransactions bool `yaml:"omit_block_transactions"`
+	// 同步写出：每条消息等待broker确认后返回，写出错误交给输出管道按on_error策略重试及写入死信队列；
+	// 异步写出（默认）时写出失败的消息由写入器回调写入死信队列
+	Sync bool `yaml:"sync"`
 }
 
 type InfluxDBConfig struct {
-	URL    string `yaml:"url"`
-	Token  string `yaml:"token"`
-	Org    string `yaml:"org"`
-	Bucket string `yaml:"bucket"`
+	URL          string                   `yaml:"url"`
+	Token        string                   `yaml:"token"`
+	Org          string                   `yaml:"org"`
+	Bucket       string                   `yaml:"bucket"`
+	Measurements InfluxMeasurementsConfig `yaml:"measurements"`
+	Write        InfluxWriteConfig        `yaml:"write"`
+	Retention    InfluxRetentionConfig    `yaml:"retention"`
+	Downsampling InfluxDownsamplingConfig `yaml:"downsampling"`
+}
+
+// InfluxRetentionConfig 按measurement的原始数据保留时长，超期的数据点按周期删除。
+// InfluxDB 2的保留策略以bucket为单位，同一bucket内不同measurement的保留时长只能由删除实现
+type InfluxRetentionConfig struct {
+	Enabled      bool              `yaml:"enabled"`
+	Interval     string            `yaml:"interval"`     // 删除周期
+	Measurements map[string]string `yaml:"measurements"` // measurement -> 保留时长，未列出的measurement不删除
+}
+
+// InfluxDownsamplingConfig 降采样任务配置，启动时在InfluxDB中创建或更新各级汇总任务及目标bucket
+type InfluxDownsamplingConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// 每次执行重新汇总最近这段时间内的窗口，覆盖延迟写入的数据点（如只处理finalized区块时）
+	Lag     string               `yaml:"lag"`
+	Rollups []InfluxRollupConfig `yaml:"rollups"`
+}
+
+// InfluxRollupConfig 一级汇总，第一级汇总原始数据，之后各级汇总上一级的结果
+type InfluxRollupConfig struct {
+	Every     string `yaml:"every"`     // 汇总窗口，同时为任务执行周期，须为上一级窗口的整数倍
+	Bucket    string `yaml:"bucket"`    // 汇总结果写入的bucket，不存在时创建
+	Retention string `yaml:"retention"` // 目标bucket的保留时长，为空表示永久保留
+}
+
+// InfluxWriteConfig 异步批量写入配置，写入失败的批次按间隔指数退避重试，
+// 重试耗尽、不可重试（如行协议错误）或等待队列已满被丢弃的批次写入死信队列
+type InfluxWriteConfig struct {
+	BatchSize        int    `yaml:"batch_size"`         // 每批数据点数
+	FlushInterval    string `yaml:"flush_interval"`     // 未满的批次写出间隔
+	MaxRetries       int    `yaml:"max_retries"`        // 失败批次的最大重试次数，0表示不重试
+	RetryInterval    string `yaml:"retry_interval"`     // 首次重试间隔，之后逐次加倍
+	MaxRetryInterval string `yaml:"max_retry_interval"` // 重试间隔上限
+}
+
+// InfluxMeasurementsConfig 各measurement的写入配置
+type InfluxMeasurementsConfig struct {
+	Blocks          MeasurementConfig `yaml:"blocks"`
+	Transactions    MeasurementConfig `yaml:"transactions"`
+	Alerts          MeasurementConfig `yaml:"alerts"`
+	BlockAggregates MeasurementConfig `yaml:"block_aggregates"` // 按区块汇总的交易指标，可替代逐笔交易写入
+	GasStats        MeasurementConfig `yaml:"gas_stats"`        // 按区块的gas费用统计，需启用gas_oracle
+	Withdrawals     MeasurementConfig `yaml:"withdrawals"`      // 信标链提款
+	BridgeTransfers MeasurementConfig `yaml:"bridge_transfers"` // 跨链桥存入及到账，需启用bridges
+}
+
+// MeasurementConfig 单个measurement的字段选择
+type MeasurementConfig struct {
+	Enabled       bool     `yaml:"enabled"`
+	Fields        []string `yaml:"fields"`         // 仅写入这些字段，为空表示全部
+	ExcludeFields []string `yaml:"exclude_fields"` // 不写入的字段
 }
 
 type RedisConfig struct {
@@ -60,60 +532,723 @@ type RedisConfig struct {
 	Port     int    `yaml:"port"`
 	Password string `yaml:"password"`
 	DB       int    `yaml:"db"`
+	// 启动时自动升级旧版本的键布局，关闭时存在待升级布局则拒绝启动，需以 -mode migrate 手动执行
+	AutoMigrate bool `yaml:"auto_migrate"`
 }
 
 type LoggingConfig struct {
 	Level  string `yaml:"level"`
 	Format string `yaml:"format"`
+	// 按模块单独设置的级别，如 collector: debug，未设置的模块使用level
+	Modules map[string]string `yaml:"modules"`
 }
 
 type MetricsConfig struct {
-	Enabled bool   `yaml:"enabled"`
-	Path    string `yaml:"path"`
+	Enabled     bool              `yaml:"enabled"`
+	Path        string            `yaml:"path"`
+	ErrorBudget ErrorBudgetConfig `yaml:"error_budget"`
+}
+
+// ErrorBudgetConfig 按错误类别（rpc/decode/sink/other）在窗口内允许的错误数，未配置的类别只计数
+type ErrorBudgetConfig struct {
+	Window  string         `yaml:"window"`
+	Budgets map[string]int `yaml:"budgets"`
 }
 
 type DataProcessingConfig struct {
+	// 部署配置：full（默认）发布全部数据；alerts_only 仅过滤→风险检测→告警推送，不连接InfluxDB、不发布区块/交易等数据
+	Profile     string            `yaml:"profile"`
 	FilterRules FilterRulesConfig `yaml:"filter_rules"`
 	BatchSize   int               `yaml:"batch_size"`
 	Workers     int               `yaml:"workers"`
+	Sinks       []SinkConfig      `yaml:"sinks"`
+	EventDedup  EventDedupConfig  `yaml:"event_dedup"`
+	TxDedup     TxDedupConfig     `yaml:"tx_dedup"`
+	SLO         SLOConfig         `yaml:"slo"`
+	DeadLetter  DeadLetterConfig  `yaml:"dead_letter"`
+	Quarantine  QuarantineConfig  `yaml:"quarantine"`
+	// 授权盗取检测：向未知合约授权后余额被该合约转空
+	ApprovalDrain ApprovalDrainConfig `yaml:"approval_drain"`
+	// 混币器交互跟踪：为存取款地址累计混币暴露分并计入后续风险分析
+	Mixer MixerConfig `yaml:"mixer"`
+	// 地址统计增量导出到ClickHouse，长期分析不依赖Redis内存
+	Warehouse WarehouseConfig `yaml:"warehouse"`
+	// 地址统计在Redis中的保留时长及过期前汇总到InfluxDB
+	AddressStats AddressStatsConfig `yaml:"address_stats"`
+	// 代币在交易所、DeFi、跨链桥等实体类别之间流向的按天汇总
+	TokenFlows TokenFlowConfig `yaml:"token_flows"`
+	// 地址转出频率与金额突增检测：与地址自身历史平均比较，并检查短时间内是否转空余额
+	Velocity VelocityConfig `yaml:"velocity"`
+	// 区块发行、EIP-1559基础费用销毁及小费统计
+	Supply SupplyConfig `yaml:"supply"`
+	// 稳定币铸造/销毁（发行/赎回）跟踪，超过阈值时告警
+	Stablecoins StablecoinConfig `yaml:"stablecoins"`
+	// 跨链桥存入/到账解码及两端关联，目标链到账没有对应存入时告警
+	Bridges BridgeConfig `yaml:"bridges"`
+	// 地址聚类：按共同注资来源、共用充值地址及共同转出归并同一实体的地址，黑名单按聚类传递
+	Clusters ClusterConfig `yaml:"clusters"`
+	// 按区块的gas费用统计（基础费用、优先费分位数、利用率）及费用估算
+	GasOracle GasOracleConfig `yaml:"gas_oracle"`
+	// 告警转发到SIEM系统（Splunk HEC、Elasticsearch、syslog）
+	SIEM SIEMConfig `yaml:"siem"`
+	// 按过滤条件将区块、交易及告警推送到客户的Webhook地址
+	Webhooks WebhookConfig `yaml:"webhooks"`
+	// 原始区块及交易按小时归档为Parquet或gzip JSON文件，上传到S3/GCS
+	Archive ArchiveConfig `yaml:"archive"`
+	// 用户通过 /api/v1/watchlists 注册的关注地址，涉及这些地址的交易及代币转账立即告警
+	Watchlists WatchlistConfig `yaml:"watchlists"`
+	// 4字节函数签名库，解析交易调用的函数名供过滤规则及风险检测使用
+	MethodSignatures MethodSignatureConfig `yaml:"method_signatures"`
+	// 告警聚合及限速：同一(类型, 地址, 网络)的告警在窗口内合并为带计数的汇总告警，抑制告警洪泛
+	AlertAggregation AlertAggregationConfig `yaml:"alert_aggregation"`
+	// 保存已发布的告警，通过 /api/v1/alerts 查询、确认、解决及按地址静默
+	AlertStore AlertStoreConfig `yaml:"alert_store"`
+	// 规则检测之外的风险评分器（如外部ML推理服务），评分按合并方式计入风险分
+	RiskScoring RiskScoringConfig `yaml:"risk_scoring"`
+	// 新部署代币合约的跑路/貔貅特征分析，关注地址与可疑代币交互时告警
+	TokenRisk TokenRiskConfig `yaml:"token_risk"`
+	// 定期同步社区诈骗/钓鱼地址库，库中地址标记为可疑合约
+	ScamFeeds ScamFeedsConfig `yaml:"scam_feeds"`
+	// 先推送区块头摘要，处理完成后再推送完整区块
+	DualPublishing bool `yaml:"dual_publishing"`
+}
+
+// ApprovalDrainConfig 授权盗取检测配置
+type ApprovalDrainConfig struct {
+	Enabled         bool     `yaml:"enabled"`
+	Window          string   `yaml:"window"`           // 授权后与转出关联的时长
+	TrustedSpenders []string `yaml:"trusted_spenders"` // 已知路由/协议合约，对其授权不做跟踪
+}
+
+// WarehouseConfig 地址统计导出配置，通过ClickHouse HTTP接口写入
+type WarehouseConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	URL       string `yaml:"url"` // ClickHouse HTTP地址，如 http://localhost:8123
+	Database  string `yaml:"database"`
+	Table     string `yaml:"table"`
+	Username  string `yaml:"username"`
+	Password  string `yaml:"password"`
+	Interval  string `yaml:"interval"`   // 导出周期
+	BatchSize int    `yaml:"batch_size"` // 每次写入的地址数
+}
+
+// AddressStatsConfig 地址统计保留配置
+type AddressStatsConfig struct {
+	Retention string                   `yaml:"retention"` // 最后一次活动后保留的时长，每次活动刷新，为空时永久保留
+	Rollup    AddressStatsRollupConfig `yaml:"rollup"`
+}
+
+// AddressStatsRollupConfig 不活跃地址统计的汇总配置，启用后由定期清理代替到期自动删除
+type AddressStatsRollupConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	Interval  string `yaml:"interval"`   // 检查不活跃地址的周期
+	BatchSize int    `yaml:"batch_size"` // 每次检查处理的地址数
+}
+
+// MixerConfig 混币器交互跟踪配置
+type MixerConfig struct {
+	Enabled         bool                  `yaml:"enabled"`
+	Contracts       []MixerContractConfig `yaml:"contracts"`
+	DepositScore    float64               `yaml:"deposit_score"`    // 每次存款为存款方增加的暴露分
+	WithdrawalScore float64               `yaml:"withdrawal_score"` // 每次取款为接收方增加的暴露分
+	ExposureTTL     string                `yaml:"exposure_ttl"`     // 最后一次交互后暴露分保留的时长
+}
+
+// SupplyConfig 原生币供应量统计配置，启用后每个区块额外获取一次全部交易回执
+type SupplyConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	Retention string `yaml:"retention"` // 按天统计的保留时长，累计值不过期
+}
+
+// StablecoinConfig 稳定币铸造/销毁监控配置
+type StablecoinConfig struct {
+	Enabled   bool                    `yaml:"enabled"`
+	Retention string                  `yaml:"retention"` // 按天统计的保留时长，累计值不过期
+	Tokens    []StablecoinTokenConfig `yaml:"tokens"`
+}
+
+// StablecoinTokenConfig 监控的稳定币合约
+type StablecoinTokenConfig struct {
+	Network        string `yaml:"network"`
+	Address        string `yaml:"address"`
+	Symbol         string `yaml:"symbol"`
+	Decimals       uint8  `yaml:"decimals"`
+	AlertThreshold string `yaml:"alert_threshold"` // 单笔铸造/销毁的告警阈值（代币单位），为空时只统计不告警
+}
+
+// BridgeConfig 跨链桥监控配置
+type BridgeConfig struct {
+	Enabled   bool                   `yaml:"enabled"`
+	LinkTTL   string                 `yaml:"link_ttl"` // 存入记录等待目标链到账的时长
+	Contracts []BridgeContractConfig `yaml:"contracts"`
+}
+
+// BridgeContractConfig 监控的跨链桥合约
+type BridgeContractConfig struct {
+	Network  string `yaml:"network"`
+	Protocol string `yaml:"protocol"` // wormhole / layerzero / op_standard
+	Address  string `yaml:"address"`  // Wormhole代币桥、LayerZero端点或OP Stack标准桥合约
+	Core     string `yaml:"core"`     // Wormhole核心合约，代币桥的跨链消息由其发出
+	ChainID  uint32 `yaml:"chain_id"` // 协议内的链ID：Wormhole链ID或LayerZero端点ID
+	Peer     string `yaml:"peer"`     // OP Stack标准桥对端网络：L1桥填L2网络，L2桥填L1网络
+}
+
+// ClusterConfig 地址聚类配置
+
+type ClusterConfig struct {
+	Enabled            bool     `yaml:"enabled"`
+	MaxClusterSize     int      `yaml:"max_cluster_size"`    // 合并后超过该大小时不合并，避免误关联扩散
+	MaxFundingFanout   int      `yaml:"max_funding_fanout"`  // 注资地址数超过该值的注资方视为交易所等公共来源，不再关联
+	FundingWindow      string   `yaml:"funding_window"`      // 首次注资记录及充值地址标记的保留时长
+	ExchangeWallets    []string `yaml:"exchange_wallets"`    // 交易所热钱包：转入热钱包的地址视为充值地址，热钱包本身不参与聚类
+	PropagateBlacklist bool     `yaml:"propagate_blacklist"` // 与黑名单地址同一聚类的地址按关联黑名单告警
+}
+
+// GasOracleConfig gas费用统计配置
+type GasOracleConfig struct {
+	Enabled       bool `yaml:"enabled"`
+	HistoryBlocks int  `yaml:"history_blocks"` // 费用估算参考的最近区块数
+}
+
+// SIEMConfig 告警转发配置，启用后作为名为siem的输出端加入输出管道
+type SIEMConfig struct {
+	Enabled   bool                 `yaml:"enabled"`
+	Exporters []SIEMExporterConfig `yaml:"exporters"`
+}
+
+// SIEMExporterConfig 单个SIEM转发目标
+type SIEMExporterConfig struct {
+	Name       string `yaml:"name"`
+	Type       string `yaml:"type"`     // splunk_hec / elastic / syslog
+	URL        string `yaml:"url"`      // splunk_hec为HEC事件接口地址，elastic为集群地址
+	Token      string `yaml:"token"`    // Splunk HEC令牌或Elasticsearch API Key
+	Username   string `yaml:"username"` // Elasticsearch基本认证，设置token时忽略
+	Password   string `yaml:"password"`
+	Index      string `yaml:"index"`       // Splunk索引或Elasticsearch索引
+	SourceType string `yaml:"source_type"` // Splunk sourcetype
+	Address    string `yaml:"address"`     // syslog地址，如 udp://localhost:514
+	Format     string `yaml:"format"`      // syslog消息格式：cef / json
+	MinLevel   string `yaml:"min_level"`   // 低于该级别的告警不转发
+	Timeout    string `yaml:"timeout"`
+	// 告警字段到目标字段名的映射，映射为空字符串的字段不转发；metadata字段以 metadata. 为前缀
+	FieldMapping map[string]string `yaml:"field_mapping"`
+}
+
+// ArchiveConfig 冷归档配置，启用后作为名为archive的输出端加入输出管道；文件按
+// {prefix}/{blocks|transactions}/network={网络}/date={日期}/hour={小时}/ 分区，可直接用Athena/Spark查询
+type ArchiveConfig struct {
+	Enabled       bool             `yaml:"enabled"`
+	Backend       string           `yaml:"backend"` // s3 / gcs
+	Bucket        string           `yaml:"bucket"`
+	Prefix        string           `yaml:"prefix"`
+	Format        string           `yaml:"format"`         // parquet / json（gzip压缩的JSON Lines）
+	FlushInterval string           `yaml:"flush_interval"` // 检查并上传已结束小时的间隔
+	MaxRecords    int              `yaml:"max_records"`    // 单个文件的最大记录数，达到时提前上传
+	S3            ArchiveS3Config  `yaml:"s3"`
+	GCS           ArchiveGCSConfig `yaml:"gcs"`
+}
+
+// ArchiveS3Config S3归档配置，凭证为空时读取AWS_ACCESS_KEY_ID等环境变量
+type ArchiveS3Config struct {
+	Region          string `yaml:"region"`
+	Endpoint        string `yaml:"endpoint"` // 为空时使用区域的默认端点，可指向MinIO等兼容服务
+	PathStyle       bool   `yaml:"path_style"`
+	AccessKeyID     string `yaml:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key"`
+	SessionToken    string `yaml:"session_token"`
+}
+
+// ArchiveGCSConfig GCS归档配置，凭证文件为空时读取GOOGLE_APPLICATION_CREDENTIALS，仍为空时使用GCE元数据服务
+type ArchiveGCSConfig struct {
+	Endpoint        string `yaml:"endpoint"` // 为空时使用 https://storage.googleapis.com
+	CredentialsFile string `yaml:"credentials_file"`
+}
+
+// WebhookConfig Webhook推送配置，启用后作为名为webhook的输出端加入输出管道
+type WebhookConfig struct {
+	Enabled   bool                    `yaml:"enabled"`
+	Endpoints []WebhookEndpointConfig `yaml:"endpoints"`
+}
+
+// WebhookEndpointConfig 单个Webhook地址及其过滤条件，过滤条件为空表示不限
+type WebhookEndpointConfig struct {
+	Name        string            `yaml:"name"`
+	URL         string            `yaml:"url"`
+	Secret      string            `yaml:"secret"` // HMAC-SHA256签名密钥，为空时不签名
+	Events      []string          `yaml:"events"` // block / transaction / alert
+	Networks    []string          `yaml:"networks"`
+	Addresses   []string          `yaml:"addresses"`     // 交易的发送方或接收方、告警的关联地址
+	MinValueWei string            `yaml:"min_value_wei"` // 交易的最小金额
+	MinLevel    string            `yaml:"min_level"`     // 告警的最低级别
+	AlertTypes  []string          `yaml:"alert_types"`
+	Headers     map[string]string `yaml:"headers"` // 附加的请求头，如认证信息
+	Timeout     string            `yaml:"timeout"`
+	// 网络错误、408、429及5xx响应按指数退避重试
+	MaxRetries     int    `yaml:"max_retries"`
+	InitialBackoff string `yaml:"initial_backoff"`
+	MaxBackoff     string `yaml:"max_backoff"`
+	QueueSize      int    `yaml:"queue_size"` // 待推送队列长度，队列满时丢弃
+}
+
+// WatchlistConfig 地址关注列表配置，列表保存在Redis
+type WatchlistConfig struct {
+	Enabled      bool               `yaml:"enabled"`
+	MaxAddresses int                `yaml:"max_addresses"` // 单个关注列表的最大地址数
+	Balances     WatchBalanceConfig `yaml:"balances"`
+}
+
+// WatchBalanceConfig 关注地址的余额跟踪：定期及相关交易后查询原生币与代币余额，写入InfluxDB，
+// 余额在窗口内相对最高点回撤达到比例时告警
+type WatchBalanceConfig struct {
+	Enabled         bool               `yaml:"enabled"`
+	Interval        string             `yaml:"interval"`         // 定期查询间隔
+	DrawdownPercent float64            `yaml:"drawdown_percent"` // 回撤告警比例（%）
+	DrawdownWindow  string             `yaml:"drawdown_window"`  // 计算最高点的时间窗口
+	Tokens          []WatchTokenConfig `yaml:"tokens"`           // 查询余额的ERC-20代币
+}
+
+// WatchTokenConfig 查询余额的代币
+type WatchTokenConfig struct {
+	Network  string `yaml:"network"`
+	Address  string `yaml:"address"`
+	Symbol   string `yaml:"symbol"`
+	Decimals uint8  `yaml:"decimals"`
+}
+
+// MethodSignatureConfig 函数签名库配置，通过API添加的签名保存在Redis
+type MethodSignatureConfig struct {
+	Enabled      bool     `yaml:"enabled"`
+	SeedFiles    []string `yaml:"seed_files"`    // openchain/4byte导出文件，从中提取文本签名
+	RiskyMethods []string `yaml:"risky_methods"` // 调用这些函数的交易视为风险，如 setApprovalForAll
+}
+
+// VelocityConfig 地址转出频率检测配置
+type VelocityConfig struct {
+	Enabled         bool    `yaml:"enabled"`
+	Window          string  `yaml:"window"`           // 滑动窗口长度
+	Multiplier      float64 `yaml:"multiplier"`       // 窗口内笔数或金额达到历史每窗口平均的倍数时告警
+	MinTransactions int     `yaml:"min_transactions"` // 笔数突增要求的最少笔数
+	MinHistory      int     `yaml:"min_history"`      // 建立基线所需的历史窗口数
+	HistoryTTL      string  `yaml:"history_ttl"`      // 地址无转出后统计保留的时长
+	DrainMinValue   string  `yaml:"drain_min_value"`  // 窗口内转出达到该金额（原生币单位）时检查余额是否被转空
+}
+
+// TokenFlowConfig 代币流向汇总配置
+type TokenFlowConfig struct {
+	Enabled   bool                `yaml:"enabled"`
+	Retention string              `yaml:"retention"` // 按天汇总数据的保留时长
+	Entities  []EntityLabelConfig `yaml:"entities"`  // 已标注的实体地址，未标注的地址归为unknown
+}
+
+// EntityLabelConfig 实体地址标注
+type EntityLabelConfig struct {
+	Address  string `yaml:"address"`
+	Name     string `yaml:"name"`
+	Category string `yaml:"category"` // exchange / defi / bridge
+}
+
+// MixerContractConfig 混币器合约
+type MixerContractConfig struct {
+	Address string `yaml:"address"`
+	Name    string `yaml:"name"`
+}
+
+// QuarantineConfig 隔离存储配置，保存导致panic或反复处理失败的数据
+type QuarantineConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	Stream      string `yaml:"stream"`       // redis stream键名
+	MaxLen      int64  `yaml:"max_len"`      // 保留的最大条数
+	MaxFailures int    `yaml:"max_failures"` // 同一数据连续失败多少次后隔离
+}
+
+// DeadLetterConfig 死信队列配置，输出端重试耗尽后保存原始数据与失败原因
+type DeadLetterConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	Backend       string `yaml:"backend"`        // redis / kafka
+	Stream        string `yaml:"stream"`         // redis后端的stream键名
+	MaxLen        int64  `yaml:"max_len"`        // redis stream保留的最大条数
+	ConsumerGroup string `yaml:"consumer_group"` // kafka后端重放时使用的消费组
+}
+
+// SLOConfig 区块处理SLO配置
+type SLOConfig struct {
+	Enabled          bool                        `yaml:"enabled"`
+	Target           float64                     `yaml:"target"`            // 按时处理的区块比例目标，如 0.95
+	LatencyThreshold string                      `yaml:"latency_threshold"` // 区块处理完成时限，如 "5s"
+	Window           string                      `yaml:"window"`            // 合规率与剩余错误预算的统计窗口，如 "1h"
+	AlertCooldown    string                      `yaml:"alert_cooldown"`    // 同一网络同一窗口告警的最小间隔
+	BurnRateAlerts   []BurnRateAlertConfig       `yaml:"burn_rate_alerts"`
+	Networks         map[string]NetworkSLOConfig `yaml:"networks"` // 按网络覆盖目标与时限
+}
+
+// BurnRateAlertConfig 错误预算燃烧率告警规则
+type BurnRateAlertConfig struct {
+	Window    string  `yaml:"window"`    // 燃烧率计算窗口，如 "5m"
+	Threshold float64 `yaml:"threshold"` // 燃烧率超过该值时告警，如 14.4
+	Level     string  `yaml:"level"`     // 告警级别
+}
+
+// NetworkSLOConfig 单个网络的SLO覆盖配置，零值表示沿用全局配置
+type NetworkSLOConfig struct {
+	Target           float64 `yaml:"target"`
+	LatencyThreshold string  `yaml:"latency_threshold"`
+}
+
+// EventDedupConfig 合约事件去重窗口配置
+type EventDedupConfig struct {
+	Backend      string `yaml:"backend"`       // memory / redis
+	WindowBlocks uint64 `yaml:"window_blocks"` // memory后端保留的区块数
+	TTL          string `yaml:"ttl"`           // redis后端记录的过期时间，如 "1h"
+}
+
+// TxDedupConfig 重复交易抑制配置，最近处理过的交易记录在Redis中
+type TxDedupConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	TTL     string `yaml:"ttl"` // 记录的过期时间，需覆盖WebSocket与轮询的重叠及重放窗口
+}
+
+// AlertAggregationConfig 告警聚合配置
+type AlertAggregationConfig struct {
+	Enabled      bool     `yaml:"enabled"`
+	Window       string   `yaml:"window"`        // 聚合窗口，窗口结束时发布汇总告警
+	PassThrough  int      `yaml:"pass_through"`  // 每组在窗口内照常发布的告警数，其余只计入汇总
+	RateLimit    float64  `yaml:"rate_limit"`    // 每个网络每秒允许发布的告警数，0为不限速
+	Burst        int      `yaml:"burst"`         // 限速允许的突发告警数
+	ExcludeTypes []string `yaml:"exclude_types"` // 不聚合也不限速的告警类型
+}
+
+// AlertStoreConfig 告警存储配置
+type AlertStoreConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	Backend   string `yaml:"backend"`   // 目前只支持redis
+	Retention string `yaml:"retention"` // 告警记录的保留时长
+}
+
+// RiskScoringConfig 风险评分器配置
+type RiskScoringConfig struct {
+	Enabled    bool               `yaml:"enabled"`
+	Merge      string             `yaml:"merge"`       // max / sum / weighted_average
+	RuleWeight float64            `yaml:"rule_weight"` // weighted_average时规则检测分的权重
+	Scorers    []RiskScorerConfig `yaml:"scorers"`
+}
+
+// RiskScorerConfig 单个风险评分器配置
+type RiskScorerConfig struct {
+	Name           string            `yaml:"name"`
+	Type           string            `yaml:"type"` // http / grpc，或程序注册的评分器类型
+	Enabled        bool              `yaml:"enabled"`
+	Endpoint       string            `yaml:"endpoint"` // http为URL，grpc为host:port
+	Method         string            `yaml:"method"`   // grpc方法全名，默认 /web3.risk.v1.RiskScorer/Score
+	TLS            bool              `yaml:"tls"`      // grpc连接是否使用TLS
+	Headers        map[string]string `yaml:"headers"`  // http请求头或grpc元数据，如认证信息
+	Weight         float64           `yaml:"weight"`   // 评分合并时的权重，默认1
+	LatencyBudget  string            `yaml:"latency_budget"`
+	AlertThreshold float64           `yaml:"alert_threshold"` // 加权分达到该值时单独触发告警，0为不单独告警
+	Networks       []string          `yaml:"networks"`        // 为空表示全部网络
+}
+
+// TokenRiskConfig 代币跑路/貔貅风险检测配置
+type TokenRiskConfig struct {
+	Enabled       bool    `yaml:"enabled"`
+	Threshold     float64 `yaml:"threshold"`      // 风险分达到该值的代币视为可疑
+	LiquidityPull float64 `yaml:"liquidity_pull"` // 单笔撤出交易对中另一资产的比例达到该值时视为撤池
+	Retention     string  `yaml:"retention"`      // 代币分析结果的保留时长
+}
+
+// ScamFeedsConfig 诈骗/钓鱼地址库同步配置
+type ScamFeedsConfig struct {
+	Enabled  bool             `yaml:"enabled"`
+	Interval string           `yaml:"interval"` // 同步间隔
+	Timeout  string           `yaml:"timeout"`  // 单个地址库的下载超时
+	Feeds    []ScamFeedConfig `yaml:"feeds"`
+}
+
+// ScamFeedConfig 单个地址库配置
+type ScamFeedConfig struct {
+	Name     string            `yaml:"name"`
+	Enabled  bool              `yaml:"enabled"`
+	URL      string            `yaml:"url"`
+	Format   string            `yaml:"format"`   // text：每行一个地址；json：地址数组或对象数组
+	Field    string            `yaml:"field"`    // json对象数组中的地址字段，默认address
+	Category string            `yaml:"category"` // 标记类别，如 phishing / scam
+	Headers  map[string]string `yaml:"headers"`
+	Networks []string          `yaml:"networks"` // 地址库适用的网络，为空表示全部网络
+}
+
+// SinkConfig 数据输出端配置
+type SinkConfig struct {
+	Type         string `yaml:"type"` // kafka / stream / influxdb / redis
+	Enabled      bool   `yaml:"enabled"`
+	OnError      string `yaml:"on_error"` // continue / fail
+	MaxRetries   int    `yaml:"max_retries"`
+	RetryBackoff string `yaml:"retry_backoff"` // 如 "100ms"
 }
 
 type FilterRulesConfig struct {
 	MinValueWei      string   `yaml:"min_value_wei"`
 	ExcludeContracts []string `yaml:"exclude_contracts"`
 	IncludeAddresses []string `yaml:"include_addresses"`
+	// 表达式规则，配置后替代上述固定规则（include_addresses仍优先处理）：按顺序求值，第一条命中的规则决定是否处理
+	Rules         []FilterRuleConfig `yaml:"rules"`
+	DefaultAction string             `yaml:"default_action"` // 没有规则命中时的动作：include / exclude
+}
+
+// FilterRuleConfig 过滤表达式规则，语法见 https://expr-lang.org ，可用变量见 filterexpr.Env
+type FilterRuleConfig struct {
+	Name   string `yaml:"name"`
+	Expr   string `yaml:"expr"`   // 如 tx.value > 1e18 && tx.network == "ethereum" && tx.to in watchlist
+	Action string `yaml:"action"` // include / exclude
 }
 
 func Load(configPath string) (*Config, error) {
-	viper.SetConfigFile(configPath)
-	viper.SetConfigType("yaml")
+	// 每次加载使用独立的viper实例，热加载时不影响当前配置
+	v := viper.New()
+	v.SetConfigFile(configPath)
+	v.SetConfigType("yaml")
 
 	// 设置默认值
-	setDefaults()
+	setDefaults(v)
 
 	// 读取配置文件
-	if err := viper.ReadInConfig(); err != nil {
+	if err := v.ReadInConfig(); err != nil {
 		return nil, err
 	}
 
 	// 允许环境变量覆盖配置
-	viper.AutomaticEnv()
+	v.AutomaticEnv()
 
+	// 按yaml标签解码，与配置文件及Diff、密钥解析使用的键名一致
 	var config Config
-	if err := viper.Unmarshal(&config); err != nil {
+	if err := v.Unmarshal(&config, func(dc *mapstructure.DecoderConfig) { dc.TagName = "yaml" }); err != nil {
 		return nil, err
 	}
 
+	// 密钥引用（env:// vault:// awskms://）在加载时解析，明文不写入配置文件；
+	// 解析失败的引用不中断加载，由Validate与其余问题一并报告
+	config.secrets, config.secretErrs = resolveSecrets(&config)
+
 	return &config, nil
 }
 
-func setDefaults() {
-	viper.SetDefault("server.port", 8082)
-	viper.SetDefault("server.mode", "debug")
-	viper.SetDefault("logging.level", "info")
-	viper.SetDefault("logging.format", "text")
-	viper.SetDefault("metrics.enabled", true)
-	viper.SetDefault("metrics.path", "/metrics")
-	viper.SetDefault("data_processing.batch_size", 50)
-	viper.SetDefault("data_processing.workers", 10)
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("server.port", 8082)
+	v.SetDefault("server.mode", "debug")
+	v.SetDefault("server.watch_config", true)
+	v.SetDefault("server.auth.enabled", false)
+	v.SetDefault("server.pprof.enabled", false)
+	v.SetDefault("server.query_cache.enabled", false)
+	v.SetDefault("server.query_cache.ttl", "10s")
+	v.SetDefault("server.graphql.enabled", false)
+	v.SetDefault("server.graphql.max_depth", 6)
+	v.SetDefault("server.graphql.max_page_size", 100)
+	v.SetDefault("server.openapi.enabled", true)
+	v.SetDefault("server.openapi.ui_assets_url", "https://unpkg.com/swagger-ui-dist@5")
+	v.SetDefault("server.rate_limit.enabled", false)
+	v.SetDefault("server.rate_limit.per_key.rate", 20)
+	v.SetDefault("server.rate_limit.per_key.burst", 40)
+	v.SetDefault("server.rate_limit.per_ip.rate", 50)
+	v.SetDefault("server.rate_limit.per_ip.burst", 100)
+	v.SetDefault("server.readiness.max_block_lag", 50)
+	v.SetDefault("devtool.traffic.private_key_env", "DEVTOOL_PRIVATE_KEY")
+	v.SetDefault("devtool.traffic.rate", 1)
+	v.SetDefault("devtool.traffic.pattern", "constant")
+	v.SetDefault("devtool.traffic.burst_size", 10)
+	v.SetDefault("devtool.traffic.value_wei", "1000000000000000")
+	v.SetDefault("grpc.enabled", false)
+	v.SetDefault("grpc.host", "127.0.0.1")
+	v.SetDefault("grpc.port", 9090)
+	v.SetDefault("grpc.stream_buffer_size", 256)
+	v.SetDefault("blockchain.auto_disable.enabled", true)
+	v.SetDefault("blockchain.auto_disable.max_startup_failures", 3)
+	v.SetDefault("blockchain.auto_disable.startup_retry_interval", "10s")
+	v.SetDefault("blockchain.auto_disable.down_timeout", "10m")
+	v.SetDefault("blockchain.log_filter.enabled", false)
+	v.SetDefault("blockchain.rpc_cost.enabled", false)
+	v.SetDefault("blockchain.flash_loan.enabled", false)
+	v.SetDefault("blockchain.flash_loan.use_traces", false)
+	v.SetDefault("blockchain.sharding.enabled", false)
+	v.SetDefault("blockchain.sharding.key_prefix", "web3:shards")
+	v.SetDefault("blockchain.sharding.lease_duration", "15s")
+	v.SetDefault("blockchain.sharding.heartbeat_interval", "3s")
+	v.SetDefault("blockchain.stall_detection.enabled", true)
+	v.SetDefault("blockchain.stall_detection.multiplier", 10)
+	v.SetDefault("blockchain.stall_detection.min_duration", "1m")
+	v.SetDefault("blockchain.continuity.enabled", true)
+	v.SetDefault("blockchain.continuity.max_queued", 1000)
+	v.SetDefault("blockchain.continuity.max_retries", 3)
+	v.SetDefault("blockchain.continuity.history", 100)
+	v.SetDefault("blockchain.rpc_recording.mode", "")
+	v.SetDefault("blockchain.run_mode", "hybrid")
+	v.SetDefault("blockchain.rpc_recording.dir", "testdata/rpc")
+	v.SetDefault("blockchain.log_filter.backfill.enabled", false)
+	v.SetDefault("blockchain.log_filter.backfill.lookback_blocks", 10000)
+	v.SetDefault("blockchain.log_filter.backfill.initial_range", 2000)
+	v.SetDefault("blockchain.log_filter.backfill.min_range", 10)
+	v.SetDefault("blockchain.log_filter.backfill.max_range", 10000)
+	v.SetDefault("blockchain.sync_pacing.enabled", true)
+	v.SetDefault("blockchain.sync_pacing.target_latency", "500ms")
+	v.SetDefault("blockchain.sync_pacing.max_delay", "10s")
+	v.SetDefault("blockchain.sync_pacing.rate_limit_backoff", "1s")
+	v.SetDefault("blockchain.sync_pacing.catch_up_blocks", 1000)
+	v.SetDefault("kafka.topics.events", "blockchain-events")
+	v.SetDefault("kafka.topics.headers", "blockchain-headers")
+	v.SetDefault("kafka.topics.gas_stats", "blockchain-gas-stats")
+	v.SetDefault("kafka.topics.withdrawals", "blockchain-withdrawals")
+	v.SetDefault("kafka.topics.bridge_transfers", "blockchain-bridge-transfers")
+	v.SetDefault("kafka.topics.enriched_blocks", "blockchain-blocks-enriched")
+	v.SetDefault("kafka.topics.dead_letter", "blockchain-dead-letters")
+	v.SetDefault("kafka.producer.idempotent", true)
+	v.SetDefault("kafka.producer.max_attempts", 10)
+	v.SetDefault("kafka.producer.transactional", false)
+	v.SetDefault("kafka.producer.transactional_id", "web3-data-collector")
+	v.SetDefault("kafka.producer.compression", "none")
+	v.SetDefault("kafka.producer.max_message_bytes", 1048576)
+	v.SetDefault("kafka.producer.omit_block_transactions", false)
+	v.SetDefault("kafka.producer.sync", false)
+	v.SetDefault("kafka.backend", "kafka")
+	v.SetDefault("kafka.pubsub.endpoint", "https://pubsub.googleapis.com")
+	v.SetDefault("data_processing.profile", "full")
+	v.SetDefault("data_processing.dual_publishing", true)
+	v.SetDefault("data_processing.dead_letter.enabled", false)
+	v.SetDefault("data_processing.dead_letter.backend", "redis")
+	v.SetDefault("data_processing.dead_letter.stream", "dead_letters")
+	v.SetDefault("data_processing.dead_letter.max_len", 100000)
+	v.SetDefault("data_processing.dead_letter.consumer_group", "web3-dead-letter-replay")
+	v.SetDefault("data_processing.quarantine.enabled", true)
+	v.SetDefault("data_processing.quarantine.stream", "quarantine")
+	v.SetDefault("data_processing.quarantine.max_len", 10000)
+	v.SetDefault("data_processing.quarantine.max_failures", 3)
+	v.SetDefault("data_processing.approval_drain.enabled", false)
+	v.SetDefault("data_processing.approval_drain.window", "72h")
+	v.SetDefault("data_processing.warehouse.enabled", false)
+	v.SetDefault("data_processing.warehouse.url", "http://localhost:8123")
+	v.SetDefault("data_processing.warehouse.database", "web3")
+	v.SetDefault("data_processing.warehouse.table", "address_stats")
+	v.SetDefault("data_processing.warehouse.username", "default")
+	v.SetDefault("data_processing.warehouse.interval", "1m")
+	v.SetDefault("data_processing.warehouse.batch_size", 1000)
+	v.SetDefault("data_processing.address_stats.retention", "")
+	v.SetDefault("data_processing.address_stats.rollup.enabled", false)
+	v.SetDefault("data_processing.address_stats.rollup.interval", "10m")
+	v.SetDefault("data_processing.address_stats.rollup.batch_size", 1000)
+	v.SetDefault("data_processing.mixer.enabled", false)
+	v.SetDefault("data_processing.mixer.deposit_score", 0.3)
+	v.SetDefault("data_processing.mixer.withdrawal_score", 0.6)
+	v.SetDefault("data_processing.mixer.exposure_ttl", "2160h")
+	v.SetDefault("data_processing.token_flows.enabled", false)
+	v.SetDefault("data_processing.supply.enabled", false)
+	v.SetDefault("data_processing.supply.retention", "8760h")
+	v.SetDefault("data_processing.stablecoins.enabled", false)
+	v.SetDefault("data_processing.stablecoins.retention", "8760h")
+	v.SetDefault("data_processing.bridges.enabled", false)
+	v.SetDefault("data_processing.bridges.link_ttl", "720h")
+	v.SetDefault("data_processing.clusters.enabled", false)
+	v.SetDefault("data_processing.clusters.max_cluster_size", 500)
+	v.SetDefault("data_processing.clusters.max_funding_fanout", 20)
+	v.SetDefault("data_processing.clusters.funding_window", "720h")
+	v.SetDefault("data_processing.clusters.propagate_blacklist", true)
+	v.SetDefault("data_processing.gas_oracle.enabled", false)
+	v.SetDefault("data_processing.gas_oracle.history_blocks", 20)
+	v.SetDefault("data_processing.siem.enabled", false)
+	v.SetDefault("data_processing.webhooks.enabled", false)
+	v.SetDefault("data_processing.archive.enabled", false)
+	v.SetDefault("data_processing.archive.backend", "s3")
+	v.SetDefault("data_processing.archive.prefix", "raw")
+	v.SetDefault("data_processing.archive.format", "parquet")
+	v.SetDefault("data_processing.archive.flush_interval", "5m")
+	v.SetDefault("data_processing.archive.max_records", 100000)
+	v.SetDefault("data_processing.filter_rules.default_action", "exclude")
+	v.SetDefault("data_processing.watchlists.enabled", false)
+	v.SetDefault("data_processing.watchlists.max_addresses", 1000)
+	v.SetDefault("data_processing.watchlists.balances.enabled", false)
+	v.SetDefault("data_processing.watchlists.balances.interval", "5m")
+	v.SetDefault("data_processing.watchlists.balances.drawdown_percent", 50)
+	v.SetDefault("data_processing.watchlists.balances.drawdown_window", "24h")
+	v.SetDefault("data_processing.method_signatures.enabled", false)
+	v.SetDefault("data_processing.velocity.enabled", false)
+	v.SetDefault("data_processing.velocity.window", "10m")
+	v.SetDefault("data_processing.velocity.multiplier", 5.0)
+	v.SetDefault("data_processing.velocity.min_transactions", 10)
+	v.SetDefault("data_processing.velocity.min_history", 6)
+	v.SetDefault("data_processing.velocity.history_ttl", "720h")
+	v.SetDefault("data_processing.velocity.drain_min_value", "1")
+	v.SetDefault("data_processing.token_flows.retention", "2160h")
+	v.SetDefault("data_processing.slo.enabled", false)
+	v.SetDefault("data_processing.slo.target", 0.95)
+	v.SetDefault("data_processing.slo.latency_threshold", "5s")
+	v.SetDefault("data_processing.slo.window", "1h")
+	v.SetDefault("data_processing.slo.alert_cooldown", "15m")
+	v.SetDefault("influxdb.measurements.blocks.enabled", true)
+	v.SetDefault("influxdb.measurements.transactions.enabled", true)
+	v.SetDefault("influxdb.measurements.alerts.enabled", true)
+	v.SetDefault("influxdb.measurements.block_aggregates.enabled", false)
+	v.SetDefault("influxdb.measurements.gas_stats.enabled", true)
+	v.SetDefault("influxdb.measurements.withdrawals.enabled", true)
+	v.SetDefault("influxdb.measurements.bridge_transfers.enabled", true)
+	v.SetDefault("influxdb.write.batch_size", 5000)
+	v.SetDefault("influxdb.write.flush_interval", "1s")
+	v.SetDefault("influxdb.write.max_retries", 3)
+	v.SetDefault("influxdb.write.retry_interval", "1s")
+	v.SetDefault("influxdb.write.max_retry_interval", "30s")
+	v.SetDefault("influxdb.retention.enabled", false)
+	v.SetDefault("influxdb.retention.interval", "1h")
+	v.SetDefault("influxdb.downsampling.enabled", false)
+	v.SetDefault("influxdb.downsampling.lag", "15m")
+	v.SetDefault("redis.auto_migrate", true)
+	v.SetDefault("pricing.enabled", false)
+	v.SetDefault("pricing.coingecko_url", "https://api.coingecko.com/api/v3")
+	v.SetDefault("pricing.cache_ttl", "5m")
+	v.SetDefault("pricing.request_timeout", "5s")
+	v.SetDefault("ens.enabled", false)
+	v.SetDefault("ens.cache_ttl", "24h")
+	v.SetDefault("ens.request_timeout", "5s")
+
+	v.SetDefault("memory.enabled", false)
+	v.SetDefault("memory.budget_mb", 1024)
+	v.SetDefault("memory.check_interval", "5s")
+	v.SetDefault("memory.shed_enrichment", 0.7)
+	v.SetDefault("memory.sampling", 0.8)
+	v.SetDefault("memory.pause_ingest", 0.9)
+	v.SetDefault("memory.sample_rate", 0.25)
+
+	v.SetDefault("leader_election.enabled", false)
+	v.SetDefault("leader_election.backend", "redis")
+	v.SetDefault("leader_election.key", "web3:leader")
+	v.SetDefault("leader_election.lease_duration", "10s")
+	v.SetDefault("leader_election.retry_interval", "2s")
+	v.SetDefault("logging.level", "info")
+	v.SetDefault("logging.format", "text")
+	v.SetDefault("metrics.enabled", true)
+	v.SetDefault("metrics.path", "/metrics")
+	v.SetDefault("metrics.error_budget.window", "1h")
+	v.SetDefault("metrics.error_budget.budgets", map[string]int{
+		"rpc":    600,
+		"decode": 10,
+		"sink":   100,
+	})
+	v.SetDefault("data_processing.event_dedup.backend", "memory")
+	v.SetDefault("data_processing.event_dedup.window_blocks", 128)
+	v.SetDefault("data_processing.event_dedup.ttl", "1h")
+	v.SetDefault("data_processing.tx_dedup.enabled", true)
+	v.SetDefault("data_processing.tx_dedup.ttl", "1h")
+	v.SetDefault("data_processing.alert_aggregation.enabled", false)
+	v.SetDefault("data_processing.alert_aggregation.window", "5m")
+	v.SetDefault("data_processing.alert_aggregation.pass_through", 1)
+	v.SetDefault("data_processing.alert_aggregation.rate_limit", 10)
+	v.SetDefault("data_processing.alert_aggregation.burst", 100)
+	v.SetDefault("data_processing.alert_store.enabled", false)
+	v.SetDefault("data_processing.alert_store.backend", "redis")
+	v.SetDefault("data_processing.alert_store.retention", "720h")
+	v.SetDefault("data_processing.risk_scoring.enabled", false)
+	v.SetDefault("data_processing.risk_scoring.merge", "max")
+	v.SetDefault("data_processing.risk_scoring.rule_weight", 1.0)
+	v.SetDefault("data_processing.token_risk.enabled", false)
+	v.SetDefault("data_processing.token_risk.threshold", 0.6)
+	v.SetDefault("data_processing.token_risk.liquidity_pull", 0.8)
+	v.SetDefault("data_processing.token_risk.retention", "2160h")
+	v.SetDefault("data_processing.scam_feeds.enabled", false)
+	v.SetDefault("data_processing.scam_feeds.interval", "6h")
+	v.SetDefault("data_processing.scam_feeds.timeout", "30s")
+	v.SetDefault("data_processing.batch_size", 50)
+	v.SetDefault("data_processing.workers", 10)
 }
\ No newline at end of file
@@ -0,0 +1,286 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+)
+
+// reloadDebounce 文件连续变化时合并为一次加载
+const reloadDebounce = 500 * time.Millisecond
+
+// maskedValue 敏感配置项在差异中的展示值
+const maskedValue = "******"
+
+// hotReloadPaths 无需重启即可生效的配置项前缀
+var hotReloadPaths = []string{
+	"logging.level",
+	"logging.modules",
+	"data_processing.filter_rules",
+	"blockchain.networks",
+}
+
+// sensitiveKeys 差异中需要脱敏的配置项
+var sensitiveKeys = []string{"password", "token", "api_key", "rpc_url", "ws_url", "jwt_secret", "key", "secret"}
+
+// ConfigChange 配置项变更
+type ConfigChange struct {
+	Path      string `json:"path"`
+	Old       string `json:"old,omitempty"`
+	New       string `json:"new,omitempty"`
+	HotReload bool   `json:"hot_reload"` // false表示需重启服务才能生效
+}
+
+// ReloadResult 配置热加载结果
+type ReloadResult struct {
+	Applied bool           `json:"applied"`
+	Changes []ConfigChange `json:"changes"`
+	Errors  []string       `json:"errors,omitempty"`
+}
+
+// ReloadHandler 应用新配置的回调，回滚时以交换后的参数再次调用，因此应按next设置状态而非按差异增量修改
+type ReloadHandler func(previous, next *Config) error
+
+// Reloader 监听配置文件并热加载
+type Reloader struct {
+	path     string
+	current  *Config
+	handlers []ReloadHandler
+	mu       sync.Mutex
+}
+
+// NewReloader 创建配置热加载器
+func NewReloader(path string, current *Config) *Reloader {
+	return &Reloader{
+		path:    path,
+		current: current,
+	}
+}
+
+// OnReload 注册应用新配置的回调，按注册顺序执行
+func (r *Reloader) OnReload(handler ReloadHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers = append(r.handlers, handler)
+}
+
+// Current 获取当前生效的配置
+func (r *Reloader) Current() *Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// Reload 重新读取配置文件，校验通过后应用变更；校验或任一回调失败时保留当前配置，Applied为false。
+// 回调失败时不再执行后续回调，已执行成功的回调按相反顺序以当前配置重新执行，恢复原状态
+func (r *Reloader) Reload() *ReloadResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := &ReloadResult{Changes: []ConfigChange{}}
+
+	next, err := Load(r.path)
+	if err != nil {
+		result.Errors = []string{fmt.Sprintf("failed to load config: %v", err)}
+		return result
+	}
+
+	result.Changes = Diff(r.current, next)
+
+	if errs := next.Validate(); len(errs) > 0 {
+		for _, err := range errs {
+			result.Errors = append(result.Errors, err.Error())
+		}
+		return result
+	}
+
+	if len(result.Changes) == 0 {
+		result.Applied = true
+		return result
+	}
+
+	for i, handler := range r.handlers {
+		if err := handler(r.current, next); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			r.rollback(i, next, result)
+			// 不更新当前配置，下次加载时按同一差异重新执行全部回调
+			return result
+		}
+	}
+
+	r.current = next
+	result.Applied = true
+
+	return result
+}
+
+// rollback 按相反顺序以当前配置重新执行前failed个回调，撤销已应用的新配置，失败时追加到错误列表
+func (r *Reloader) rollback(failed int, next *Config, result *ReloadResult) {
+	for i := failed - 1; i >= 0; i-- {
+		if err := r.handlers[i](next, r.current); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("rollback: %v", err))
+		}
+	}
+}
+
+// Watch 监听配置文件变化并自动热加载，直到ctx结束
+func (r *Reloader) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	// 监听所在目录，编辑器保存时常以重命名方式替换文件
+	target := filepath.Clean(r.path)
+	if err := watcher.Add(filepath.Dir(target)); err != nil {
+		return fmt.Errorf("failed to watch config directory: %w", err)
+	}
+
+	logrus.Infof("Watching %s for configuration changes", target)
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) != target {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+				debounce = time.After(reloadDebounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logrus.Errorf("Config watcher error: %v", err)
+		case <-debounce:
+			debounce = nil
+			result := r.Reload()
+			if !result.Applied {
+				logrus.Errorf("Rejected config change: %s", strings.Join(result.Errors, "; "))
+				continue
+			}
+			logrus.Infof("Configuration reloaded with %d changes", len(result.Changes))
+			for _, change := range result.Changes {
+				if !change.HotReload {
+					logrus.Warnf("Config change %s requires a restart to take effect", change.Path)
+				}
+			}
+		}
+	}
+}
+
+// Diff 比较两份配置，返回按路径排序的变更列表（敏感项已脱敏）
+func Diff(previous, next *Config) []ConfigChange {
+	oldValues := make(map[string]string)
+	newValues := make(map[string]string)
+	flatten("", reflect.ValueOf(*previous), oldValues)
+	flatten("", reflect.ValueOf(*next), newValues)
+
+	paths := make(map[string]bool)
+	for path := range oldValues {
+		paths[path] = true
+	}
+	for path := range newValues {
+		paths[path] = true
+	}
+
+	changes := []ConfigChange{}
+	for path := range paths {
+		oldValue, newValue := oldValues[path], newValues[path]
+		if oldValue == newValue {
+			continue
+		}
+		if isSensitive(path) || previous.secrets[path] || next.secrets[path] {
+			oldValue, newValue = maskValue(oldValue), maskValue(newValue)
+		}
+		changes = append(changes, ConfigChange{
+			Path:      path,
+			Old:       oldValue,
+			New:       newValue,
+			HotReload: isHotReloadable(path),
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes
+}
+
+// flatten 按yaml键名将配置展开为 路径->值
+func flatten(prefix string, value reflect.Value, out map[string]string) {
+	switch value.Kind() {
+	case reflect.Struct:
+		valueType := value.Type()
+		for i := 0; i < value.NumField(); i++ {
+			field := valueType.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+			flatten(joinPath(prefix, name), value.Field(i), out)
+		}
+	case reflect.Map:
+		for _, key := range value.MapKeys() {
+			flatten(joinPath(prefix, fmt.Sprint(key.Interface())), value.MapIndex(key), out)
+		}
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			flatten(fmt.Sprintf("%s[%d]", prefix, i), value.Index(i), out)
+		}
+	default:
+		out[prefix] = fmt.Sprint(value.Interface())
+	}
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func isHotReloadable(path string) bool {
+	for _, prefix := range hotReloadPaths {
+		if path == prefix || strings.HasPrefix(path, prefix+".") || strings.HasPrefix(path, prefix+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+func isSensitive(path string) bool {
+	key := path[strings.LastIndex(path, ".")+1:]
+	for _, sensitive := range sensitiveKeys {
+		if key == sensitive {
+			return true
+		}
+	}
+	return false
+}
+
+func maskValue(value string) string {
+	if value == "" {
+		return ""
+	}
+	return maskedValue
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReloaderAppliesChange(t *testing.T) {
+	path := writeTestConfig(t)
+	current, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	reloader := NewReloader(path, current)
+	var applied *Config
+	reloader.OnReload(func(previous, next *Config) error {
+		applied = next
+		return nil
+	})
+
+	result := reloader.Reload()
+	if !result.Applied || len(result.Changes) != 0 || len(result.Errors) != 0 {
+		t.Fatalf("reload of unchanged file = %+v", result)
+	}
+	if applied != nil {
+		t.Fatalf("handlers should not run without changes")
+	}
+
+	rewriteTestConfig(t, path, `level: "info"`, `level: "debug"`)
+	result = reloader.Reload()
+	if !result.Applied || len(result.Errors) != 0 {
+		t.Fatalf("reload = %+v", result)
+	}
+	if len(result.Changes) != 1 || result.Changes[0].Path != "logging.level" || !result.Changes[0].HotReload {
+		t.Errorf("changes = %+v", result.Changes)
+	}
+	if applied == nil || applied.Logging.Level != "debug" || reloader.Current() != applied {
+		t.Errorf("new config was not applied")
+	}
+}
+
+func TestReloaderRejectsInvalidConfig(t *testing.T) {
+	path := writeTestConfig(t)
+	current, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	reloader := NewReloader(path, current)
+
+	rewriteTestConfig(t, path, `level: "info"`, `level: "verbose"`)
+	result := reloader.Reload()
+	if result.Applied {
+		t.Fatalf("invalid config was applied")
+	}
+	if len(result.Errors) != 1 || !strings.HasPrefix(result.Errors[0], "logging.level:") {
+		t.Errorf("errors = %v", result.Errors)
+	}
+	if reloader.Current() != current {
+		t.Errorf("current config changed after a rejected reload")
+	}
+}
+
+func TestReloaderRollsBackFailedReload(t *testing.T) {
+	path := writeTestConfig(t)
+	current, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	reloader := NewReloader(path, current)
+
+	var levels []string
+	reloader.OnReload(func(previous, next *Config) error {
+		levels = append(levels, next.Logging.Level)
+		return nil
+	})
+	reloader.OnReload(func(previous, next *Config) error {
+		return errors.New("apply failed")
+	})
+	third := false
+	reloader.OnReload(func(previous, next *Config) error {
+		third = true
+		return nil
+	})
+
+	rewriteTestConfig(t, path, `level: "info"`, `level: "debug"`)
+	result := reloader.Reload()
+	if result.Applied || len(result.Errors) != 1 || result.Errors[0] != "apply failed" {
+		t.Fatalf("reload = %+v", result)
+	}
+	if third {
+		t.Errorf("handlers after the failed one should not run")
+	}
+	if len(levels) != 2 || levels[0] != "debug" || levels[1] != "info" {
+		t.Errorf("applied levels = %v, want the new level rolled back to the current one", levels)
+	}
+	if reloader.Current() != current {
+		t.Errorf("current config changed after a failed reload")
+	}
+}
+
+// rewriteTestConfig 替换配置文件中的一处内容
+func rewriteTestConfig(t *testing.T, path, old, new string) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), old) {
+		t.Fatalf("%s does not contain %q", path, old)
+	}
+	if err := os.WriteFile(path, []byte(strings.Replace(string(data), old, new, 1)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
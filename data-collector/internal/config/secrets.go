@@ -0,0 +1,351 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 配置值可以是密钥引用，加载时替换为解析出的值：
+//
+//	env://NAME                       环境变量NAME，未设置时报错
+//	vault://mount/path#field         Vault KV中path的field字段，地址及令牌取VAULT_ADDR、VAULT_TOKEN，
+//	                                 可选VAULT_NAMESPACE；VAULT_KV_VERSION=1时按KV v1读取，默认v2
+//	awskms://<base64密文>[?region=]  AWS KMS解密，region默认取AWS_REGION或AWS_DEFAULT_REGION，
+//	                                 凭证取AWS_ACCESS_KEY_ID、AWS_SECRET_ACCESS_KEY及AWS_SESSION_TOKEN
+const (
+	secretSchemeEnv    = "env://"
+	secretSchemeVault  = "vault://"
+	secretSchemeAWSKMS = "awskms://"
+)
+
+// secretResolveTimeout 一次加载中解析全部密钥引用的超时
+const secretResolveTimeout = 30 * time.Second
+
+// isSecretReference 判断配置值是否为密钥引用
+func isSecretReference(value string) bool {
+	return strings.HasPrefix(value, secretSchemeEnv) ||
+		strings.HasPrefix(value, secretSchemeVault) ||
+		strings.HasPrefix(value, secretSchemeAWSKMS)
+}
+
+// secretResolver 解析密钥引用，同一次加载中的Vault路径只读取一次
+type secretResolver struct {
+	http     *http.Client
+	vault    map[string]map[string]interface{}
+	resolved map[string]bool // 已替换的配置路径
+	errs     []error         // 解析失败的引用，带配置路径
+}
+
+// resolveSecrets 替换配置中全部的密钥引用，返回被替换的配置路径（格式与Diff相同）及解析失败的引用；
+// 一个引用失败不影响其余引用的解析，失败的值保持原引用
+func resolveSecrets(config *Config) (map[string]bool, []error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretResolveTimeout)
+	defer cancel()
+
+	resolver := &secretResolver{
+		http:     &http.Client{Timeout: 10 * time.Second},
+		vault:    make(map[string]map[string]interface{}),
+		resolved: make(map[string]bool),
+	}
+	resolver.walk(ctx, "", reflect.ValueOf(config).Elem())
+	return resolver.resolved, resolver.errs
+}
+
+// walk 按yaml键名遍历配置，value须可设置；map的值不可寻址，复制后解析再写回
+func (r *secretResolver) walk(ctx context.Context, path string, value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Struct:
+		valueType := value.Type()
+		for i := 0; i < value.NumField(); i++ {
+			field := valueType.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+			r.walk(ctx, joinPath(path, name), value.Field(i))
+		}
+	case reflect.Ptr:
+		if !value.IsNil() {
+			r.walk(ctx, path, value.Elem())
+		}
+	case reflect.Map:
+		for _, key := range value.MapKeys() {
+			elem := reflect.New(value.Type().Elem()).Elem()
+			elem.Set(value.MapIndex(key))
+			r.walk(ctx, joinPath(path, fmt.Sprint(key.Interface())), elem)
+			value.SetMapIndex(key, elem)
+		}
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			r.walk(ctx, fmt.Sprintf("%s[%d]", path, i), value.Index(i))
+		}
+	case reflect.String:
+		reference := value.String()
+		if !isSecretReference(reference) {
+			return
+		}
+		secret, err := r.resolve(ctx, reference)
+		if err != nil {
+			r.errs = append(r.errs, fmt.Errorf("%s: %w", path, err))
+			return
+		}
+		value.SetString(secret)
+		r.resolved[path] = true
+	}
+}
+
+func (r *secretResolver) resolve(ctx context.Context, reference string) (string, error) {
+	switch {
+	case strings.HasPrefix(reference, secretSchemeEnv):
+		name := strings.TrimPrefix(reference, secretSchemeEnv)
+		secret, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return secret, nil
+	case strings.HasPrefix(reference, secretSchemeVault):
+		return r.resolveVault(ctx, strings.TrimPrefix(reference, secretSchemeVault))
+	default:
+		return r.resolveAWSKMS(ctx, strings.TrimPrefix(reference, secretSchemeAWSKMS))
+	}
+}
+
+// resolveVault 读取 mount/path#field
+func (r *secretResolver) resolveVault(ctx context.Context, reference string) (string, error) {
+	secretPath, field, ok := strings.Cut(reference, "#")
+	secretPath = strings.Trim(secretPath, "/")
+	if !ok || field == "" || secretPath == "" {
+		return "", fmt.Errorf("vault reference must be vault://mount/path#field")
+	}
+
+	data, cached := r.vault[secretPath]
+	if !cached {
+		var err error
+		if data, err = r.readVault(ctx, secretPath); err != nil {
+			return "", err
+		}
+		r.vault[secretPath] = data
+	}
+
+	value, exists := data[field]
+	if !exists {
+		return "", fmt.Errorf("vault secret %s has no field %s", secretPath, field)
+	}
+	if secret, isString := value.(string); isString {
+		return secret, nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+func (r *secretResolver) readVault(ctx context.Context, secretPath string) (map[string]interface{}, error) {
+	address := strings.TrimRight(os.Getenv("VAULT_ADDR"), "/")
+	token := os.Getenv("VAULT_TOKEN")
+	if address == "" || token == "" {
+		return nil, fmt.Errorf("VAULT_ADDR and VAULT_TOKEN are required to resolve vault references")
+	}
+
+	// KV v2的读取路径为 mount/data/path
+	apiPath := secretPath
+	kvV2 := os.Getenv("VAULT_KV_VERSION") != "1"
+	if kvV2 {
+		mount, rest, found := strings.Cut(secretPath, "/")
+		if !found {
+			return nil, fmt.Errorf("vault reference %s has no secret path under mount %s", secretPath, mount)
+		}
+		apiPath = mount + "/data/" + rest
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, address+"/v1/"+apiPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace := os.Getenv("VAULT_NAMESPACE"); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault secret %s: %w", secretPath, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("vault returned %d for %s: %s", resp.StatusCode, secretPath, bytes.TrimSpace(message))
+	}
+
+	var body struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid vault response for %s: %w", secretPath, err)
+	}
+
+	var data map[string]interface{}
+	if kvV2 {
+		var versioned struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		err = json.Unmarshal(body.Data, &versioned)
+		data = versioned.Data
+	} else {
+		err = json.Unmarshal(body.Data, &data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid vault response for %s: %w", secretPath, err)
+	}
+	if data == nil {
+		return nil, fmt.Errorf("vault secret %s not found", secretPath)
+	}
+	return data, nil
+}
+
+// resolveAWSKMS 调用KMS Decrypt解密 <base64密文>[?region=]
+func (r *secretResolver) resolveAWSKMS(ctx context.Context, reference string) (string, error) {
+	ciphertext, query, _ := strings.Cut(reference, "?")
+	if ciphertext == "" {
+		return "", fmt.Errorf("awskms reference must be awskms://<base64 ciphertext>")
+	}
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return "", fmt.Errorf("invalid awskms reference: %w", err)
+	}
+
+	region := params.Get("region")
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	accessKeyID, secretAccessKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if region == "" || accessKeyID == "" || secretAccessKey == "" {
+		return "", fmt.Errorf("AWS region and credentials are required to resolve awskms references")
+	}
+
+	body, err := json.Marshal(map[string]string{"CiphertextBlob": ciphertext})
+	if err != nil {
+		return "", err
+	}
+	host := fmt.Sprintf("kms.%s.amazonaws.com", region)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://"+host+"/", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService.Decrypt")
+	signKMSRequest(req, host, region, accessKeyID, secretAccessKey, os.Getenv("AWS_SESSION_TOKEN"), body, time.Now().UTC())
+
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to call KMS Decrypt: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("kms returned %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+
+	var result struct {
+		Plaintext string `json:"Plaintext"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("invalid kms response: %w", err)
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(result.Plaintext)
+	if err != nil {
+		return "", fmt.Errorf("invalid kms plaintext: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// signKMSRequest 按AWS Signature Version 4签名KMS请求
+func signKMSRequest(req *http.Request, host, region, accessKeyID, secretAccessKey, sessionToken string, body []byte, now time.Time) {
+	signV4(req, "kms", host, region, accessKeyID, secretAccessKey, sessionToken, []string{"content-type", "x-amz-target"}, body, now)
+}
+
+// signV4 按AWS Signature Version 4签名路径为/且不带查询参数的请求，headers为host、x-amz-date及
+// x-amz-security-token之外需要签名的头（小写）
+func signV4(req *http.Request, service, host, region, accessKeyID, secretAccessKey, sessionToken string, headers []string, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+
+	// 签名的头须按名称排序
+	signedHeaders := append([]string{"host", "x-amz-date"}, headers...)
+	if sessionToken != "" {
+		signedHeaders = append(signedHeaders, "x-amz-security-token")
+	}
+	sort.Strings(signedHeaders)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range signedHeaders {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		"/",
+		"",
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", date, region, service)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(canonicalHash[:]),
+	}, "\n")
+
+	signature := hex.EncodeToString(hmacSum(signingKey(secretAccessKey, date, region, service), stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, strings.Join(signedHeaders, ";"), signature))
+}
+
+// signingKey 由私有访问密钥逐级派生当日、区域及服务的签名密钥
+func signingKey(secretAccessKey, date, region, service string) []byte {
+	key := []byte("AWS4" + secretAccessKey)
+	for _, part := range []string{date, region, service, "aws4_request"} {
+		key = hmacSum(key, part)
+	}
+	return key
+}
+
+func hmacSum(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
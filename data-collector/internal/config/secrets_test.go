@@ -0,0 +1,146 @@
+package config
+
+import (
+	"bytes"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// AWS Signature Version 4测试套件（aws-sig-v4-test-suite）的凭证及时间
+const (
+	sigV4TestAccessKeyID     = "AKIDEXAMPLE"
+	sigV4TestSecretAccessKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	sigV4TestHost            = "example.amazonaws.com"
+)
+
+func TestSignV4(t *testing.T) {
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		method        string
+		contentType   string
+		body          string
+		signedHeaders string
+		signature     string
+	}{
+		{
+			name:          "get-vanilla",
+			method:        http.MethodGet,
+			signedHeaders: "host;x-amz-date",
+			signature:     "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:          "post-vanilla",
+			method:        http.MethodPost,
+			signedHeaders: "host;x-amz-date",
+			signature:     "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		{
+			name:          "post-x-www-form-urlencoded",
+			method:        http.MethodPost,
+			contentType:   "application/x-www-form-urlencoded",
+			body:          "Param1=value1",
+			signedHeaders: "content-type;host;x-amz-date",
+			signature:     "ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, "https://"+sigV4TestHost+"/", bytes.NewReader([]byte(tt.body)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var headers []string
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+				headers = append(headers, "content-type")
+			}
+
+			signV4(req, "service", sigV4TestHost, "us-east-1", sigV4TestAccessKeyID, sigV4TestSecretAccessKey, "", headers, []byte(tt.body), now)
+
+			want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=" + tt.signedHeaders + ", Signature=" + tt.signature
+			if got := req.Header.Get("Authorization"); got != want {
+				t.Errorf("Authorization\n got: %s\nwant: %s", got, want)
+			}
+			if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+				t.Errorf("X-Amz-Date = %s", got)
+			}
+		})
+	}
+}
+
+func TestSignV4SessionToken(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "https://kms.us-east-1.amazonaws.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService.Decrypt")
+
+	signKMSRequest(req, "kms.us-east-1.amazonaws.com", "us-east-1", sigV4TestAccessKeyID, sigV4TestSecretAccessKey, "session", nil, time.Now())
+
+	if got := req.Header.Get("X-Amz-Security-Token"); got != "session" {
+		t.Errorf("X-Amz-Security-Token = %q", got)
+	}
+	authorization := req.Header.Get("Authorization")
+	if !strings.Contains(authorization, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target,") {
+		t.Errorf("signed headers are not sorted or incomplete: %s", authorization)
+	}
+}
+
+// 派生签名密钥的示例取自AWS文档
+func TestSigningKey(t *testing.T) {
+	key := signingKey(sigV4TestSecretAccessKey, "20120215", "us-east-1", "iam")
+	if got := hex.EncodeToString(key); got != "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d" {
+		t.Errorf("signing key = %s", got)
+	}
+}
+
+func TestResolveSecretsWalk(t *testing.T) {
+	t.Setenv("WEB3_COLLECTOR_TEST_RPC", "https://rpc.example")
+	t.Setenv("WEB3_COLLECTOR_TEST_API_KEY", "key-1")
+
+	config := &Config{}
+	config.Blockchain.Networks = map[string]NetworkConfig{
+		"ethereum": {RPCURL: "env://WEB3_COLLECTOR_TEST_RPC", WSURL: "wss://ws.example", ChainID: 1},
+		"polygon":  {RPCURL: "env://WEB3_COLLECTOR_TEST_UNSET", ChainID: 137},
+	}
+	config.Server.Auth.APIKeys = []APIKeyConfig{
+		{Name: "reader", Key: "plain", Role: "read"},
+		{Name: "admin", Key: "env://WEB3_COLLECTOR_TEST_API_KEY", Role: "admin"},
+	}
+
+	resolved, errs := resolveSecrets(config)
+
+	// map的值复制后解析再写回，其余字段保持不变
+	ethereum := config.Blockchain.Networks["ethereum"]
+	if ethereum.RPCURL != "https://rpc.example" || ethereum.WSURL != "wss://ws.example" || ethereum.ChainID != 1 {
+		t.Errorf("ethereum network = %+v", ethereum)
+	}
+	if config.Server.Auth.APIKeys[1].Key != "key-1" || config.Server.Auth.APIKeys[0].Key != "plain" {
+		t.Errorf("api keys = %+v", config.Server.Auth.APIKeys)
+	}
+	// 解析失败的值保持原引用
+	if got := config.Blockchain.Networks["polygon"].RPCURL; got != "env://WEB3_COLLECTOR_TEST_UNSET" {
+		t.Errorf("polygon rpc_url = %q", got)
+	}
+
+	for _, path := range []string{"blockchain.networks.ethereum.rpc_url", "server.auth.api_keys[1].key"} {
+		if !resolved[path] {
+			t.Errorf("%s not reported as resolved: %v", path, resolved)
+		}
+	}
+	if len(resolved) != 2 {
+		t.Errorf("resolved = %v, want 2 paths", resolved)
+	}
+
+	if len(errs) != 1 || !strings.HasPrefix(errs[0].Error(), "blockchain.networks.polygon.rpc_url: ") {
+		t.Errorf("errs = %v", errs)
+	}
+}
@@ -0,0 +1,1374 @@
+package config
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"web3-data-collector/internal/filterexpr"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+)
+
+// maxWorkers data_processing.workers的上限，超过时多为误填
+const maxWorkers = 1024
+
+// knownChainIDs 常见网络名称对应的链ID，同名网络的chain_id须一致
+var knownChainIDs = map[string]int64{
+	"ethereum":  1,
+	"optimism":  10,
+	"bsc":       56,
+	"polygon":   137,
+	"base":      8453,
+	"arbitrum":  42161,
+	"avalanche": 43114,
+	"sepolia":   11155111,
+	"holesky":   17000,
+}
+
+// 各消息后端的主题名规则及日志过滤的事件签名格式
+var (
+	eventTopicPattern   = regexp.MustCompile(`^0x[0-9a-fA-F]{64}$`)
+	kafkaTopicPattern   = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,249}$`)
+	kinesisTopicPattern = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,128}$`)
+	pubsubTopicPattern  = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9._~+%-]{2,254}$`)
+)
+
+// Validate 校验配置，返回全部错误，包括加载时解析失败的密钥引用
+func (c *Config) Validate() []error {
+	errs := append([]error(nil), c.secretErrs...)
+
+	errs = append(errs, c.validateServer()...)
+	errs = append(errs, c.validateLogging()...)
+	errs = append(errs, c.validateMetrics()...)
+	errs = append(errs, c.validateLeaderElection()...)
+	errs = append(errs, c.validateBlockchain()...)
+	errs = append(errs, c.validateWatchGroups()...)
+	errs = append(errs, c.validateNetworks()...)
+	errs = append(errs, c.validateInfluxDB()...)
+	errs = append(errs, c.validateKafka()...)
+	errs = append(errs, c.validateFilterRules()...)
+	errs = append(errs, c.validateAnalytics()...)
+	errs = append(errs, c.validateDetectors()...)
+	errs = append(errs, c.validateAlerting()...)
+	errs = append(errs, c.validateExports()...)
+	errs = append(errs, c.validateENS()...)
+	errs = append(errs, c.validateConnections()...)
+	errs = append(errs, c.validateNetworkEndpoints()...)
+	errs = append(errs, c.validateWorkers()...)
+
+	return errs
+}
+
+// validateServer 校验HTTP及gRPC服务的端口、认证、缓存、限流等配置
+func (c *Config) validateServer() []error {
+	var errs []error
+
+	if c.Server.Port <= 0 || c.Server.Port > 65535 {
+		errs = append(errs, fmt.Errorf("server.port: invalid port %d", c.Server.Port))
+	}
+
+	if c.GRPC.Enabled {
+		if err := validateHostPort(net.JoinHostPort(c.GRPC.Host, strconv.Itoa(c.GRPC.Port))); err != nil {
+			errs = append(errs, fmt.Errorf("grpc: %w", err))
+		}
+	}
+
+	if auth := c.Server.Auth; auth.Enabled {
+		if auth.JWTSecret == "" && len(auth.APIKeys) == 0 {
+			errs = append(errs, fmt.Errorf("server.auth: jwt_secret or api_keys required when enabled"))
+		}
+		for i, key := range auth.APIKeys {
+			if key.Key == "" {
+				errs = append(errs, fmt.Errorf("server.auth.api_keys[%d].key: required", i))
+			}
+			if key.Role != "read" && key.Role != "admin" {
+				errs = append(errs, fmt.Errorf("server.auth.api_keys[%d].role: must be read or admin, got %q", i, key.Role))
+			}
+		}
+	}
+
+	if cache := c.Server.QueryCache; cache.Enabled {
+		if ttl, err := time.ParseDuration(cache.TTL); err != nil || ttl <= 0 {
+			errs = append(errs, fmt.Errorf("server.query_cache.ttl: invalid duration %q", cache.TTL))
+		}
+	}
+
+	if graphql := c.Server.GraphQL; graphql.Enabled {
+		if graphql.MaxDepth <= 0 {
+			errs = append(errs, fmt.Errorf("server.graphql.max_depth: must be positive, got %d", graphql.MaxDepth))
+		}
+		if graphql.MaxPageSize <= 0 {
+			errs = append(errs, fmt.Errorf("server.graphql.max_page_size: must be positive, got %d", graphql.MaxPageSize))
+		}
+	}
+
+	if openapi := c.Server.OpenAPI; openapi.Enabled && openapi.UIAssetsURL == "" {
+		errs = append(errs, fmt.Errorf("server.openapi.ui_assets_url: required when openapi is enabled"))
+	}
+
+	if c.Server.Readiness.MaxBlockLag < 0 {
+		errs = append(errs, fmt.Errorf("server.readiness.max_block_lag: must not be negative, got %d", c.Server.Readiness.MaxBlockLag))
+	}
+
+	if rateLimit := c.Server.RateLimit; rateLimit.Enabled {
+		buckets := []struct {
+			name   string
+			bucket RateLimitBucket
+		}{{"per_key", rateLimit.PerKey}, {"per_ip", rateLimit.PerIP}}
+		for _, b := range buckets {
+			if b.bucket.Rate <= 0 {
+				errs = append(errs, fmt.Errorf("server.rate_limit.%s.rate: must be positive, got %v", b.name, b.bucket.Rate))
+			}
+			if b.bucket.Burst < 1 {
+				errs = append(errs, fmt.Errorf("server.rate_limit.%s.burst: must be at least 1, got %d", b.name, b.bucket.Burst))
+			}
+		}
+	}
+
+	for i, proxy := range c.Server.TrustedProxies {
+		if err := validateIPOrCIDR(proxy); err != nil {
+			errs = append(errs, fmt.Errorf("server.trusted_proxies[%d]: %w", i, err))
+		}
+	}
+
+	return errs
+}
+
+// validateLogging 校验日志级别及格式，模块按名称排序
+func (c *Config) validateLogging() []error {
+	var errs []error
+
+	if _, err := logrus.ParseLevel(c.Logging.Level); err != nil {
+		errs = append(errs, fmt.Errorf("logging.level: %v", err))
+	}
+	modules := make([]string, 0, len(c.Logging.Modules))
+	for module := range c.Logging.Modules {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+	for _, module := range modules {
+		if _, err := logrus.ParseLevel(c.Logging.Modules[module]); err != nil {
+			errs = append(errs, fmt.Errorf("logging.modules.%s: %v", module, err))
+		}
+	}
+	if c.Logging.Format != "" && c.Logging.Format != "json" && c.Logging.Format != "text" {
+		errs = append(errs, fmt.Errorf("logging.format: must be json or text, got %q", c.Logging.Format))
+	}
+
+	return errs
+}
+
+// validateMetrics 校验错误预算
+func (c *Config) validateMetrics() []error {
+	var errs []error
+
+	if window, err := time.ParseDuration(c.Metrics.ErrorBudget.Window); err != nil || window <= 0 {
+		errs = append(errs, fmt.Errorf("metrics.error_budget.window: invalid duration %q", c.Metrics.ErrorBudget.Window))
+	}
+	for category, budget := range c.Metrics.ErrorBudget.Budgets {
+		switch category {
+		case "rpc", "decode", "sink", "other":
+		default:
+			errs = append(errs, fmt.Errorf("metrics.error_budget.budgets: unknown category %q, must be rpc, decode, sink or other", category))
+		}
+		if budget <= 0 {
+			errs = append(errs, fmt.Errorf("metrics.error_budget.budgets.%s: must be greater than 0", category))
+		}
+	}
+
+	return errs
+}
+
+// validateLeaderElection 校验主节点选举
+func (c *Config) validateLeaderElection() []error {
+	var errs []error
+
+	if election := c.LeaderElection; election.Enabled {
+		if election.Backend != "redis" {
+			errs = append(errs, fmt.Errorf("leader_election.backend: only redis is supported, got %q", election.Backend))
+		}
+		if election.Key == "" {
+			errs = append(errs, fmt.Errorf("leader_election.key: required"))
+		}
+		lease, err := time.ParseDuration(election.LeaseDuration)
+		if err != nil || lease <= 0 {
+			errs = append(errs, fmt.Errorf("leader_election.lease_duration: invalid duration %q", election.LeaseDuration))
+		}
+		retry, err := time.ParseDuration(election.RetryInterval)
+		if err != nil || retry <= 0 {
+			errs = append(errs, fmt.Errorf("leader_election.retry_interval: invalid duration %q", election.RetryInterval))
+		} else if lease > 0 && retry*2 > lease {
+			errs = append(errs, fmt.Errorf("leader_election.retry_interval: must be at most half of lease_duration"))
+		}
+	}
+
+	return errs
+}
+
+// validateBlockchain 校验采集的运行模式、分片、停滞检测及同步节奏
+func (c *Config) validateBlockchain() []error {
+	var errs []error
+
+	switch c.Blockchain.RPCRecording.Mode {
+	case "", "record", "replay":
+	default:
+		errs = append(errs, fmt.Errorf("blockchain.rpc_recording.mode: must be record or replay, got %q", c.Blockchain.RPCRecording.Mode))
+	}
+
+	logFiltered := c.Blockchain.LogFilter.Enabled || len(c.Blockchain.WatchGroups) > 0
+	for _, network := range c.Blockchain.Networks {
+		logFiltered = logFiltered || len(network.LogFilter.Addresses) > 0 || len(network.LogFilter.Topics) > 0
+	}
+
+	switch c.Blockchain.RunMode {
+	case "", "hybrid", "realtime", "backfill":
+	default:
+		errs = append(errs, fmt.Errorf("blockchain.run_mode: must be hybrid, realtime or backfill, got %q", c.Blockchain.RunMode))
+	}
+
+	// 仅关注模式只解码部分交易，按区块统计全部交易的功能无法使用
+	if c.Blockchain.WatchlistOnly {
+		if !logFiltered && !c.DataProcessing.Watchlists.Enabled {
+			errs = append(errs, fmt.Errorf("blockchain.watchlist_only: requires data_processing.watchlists, log_filter, watch_groups or a network log_filter"))
+		}
+		if c.DataProcessing.Supply.Enabled {
+			errs = append(errs, fmt.Errorf("blockchain.watchlist_only: data_processing.supply needs every transaction of a block, disable it"))
+		}
+		if c.DataProcessing.GasOracle.Enabled {
+			errs = append(errs, fmt.Errorf("blockchain.watchlist_only: data_processing.gas_oracle needs every transaction of a block, disable it"))
+		}
+	}
+
+	if sharding := c.Blockchain.Sharding; sharding.Enabled {
+		if c.LeaderElection.Enabled {
+			errs = append(errs, fmt.Errorf("blockchain.sharding: cannot be combined with leader_election"))
+		}
+		if sharding.KeyPrefix == "" {
+			errs = append(errs, fmt.Errorf("blockchain.sharding.key_prefix: required"))
+		}
+		lease, err := time.ParseDuration(sharding.LeaseDuration)
+		if err != nil || lease <= 0 {
+			errs = append(errs, fmt.Errorf("blockchain.sharding.lease_duration: invalid duration %q", sharding.LeaseDuration))
+		}
+		heartbeat, err := time.ParseDuration(sharding.HeartbeatInterval)
+		if err != nil || heartbeat <= 0 {
+			errs = append(errs, fmt.Errorf("blockchain.sharding.heartbeat_interval: invalid duration %q", sharding.HeartbeatInterval))
+		} else if lease > 0 && heartbeat*2 > lease {
+			errs = append(errs, fmt.Errorf("blockchain.sharding.heartbeat_interval: must be at most half of lease_duration"))
+		}
+	}
+
+	if stall := c.Blockchain.StallDetection; stall.Enabled {
+		if stall.Multiplier <= 0 {
+			errs = append(errs, fmt.Errorf("blockchain.stall_detection.multiplier: must be positive"))
+		}
+		if stall.MinDuration != "" {
+			if _, err := time.ParseDuration(stall.MinDuration); err != nil {
+				errs = append(errs, fmt.Errorf("blockchain.stall_detection.min_duration: %v", err))
+			}
+		}
+	}
+
+	if continuity := c.Blockchain.Continuity; continuity.Enabled {
+		if continuity.MaxQueued < 0 || continuity.MaxRetries < 0 || continuity.History < 0 {
+			errs = append(errs, fmt.Errorf("blockchain.continuity: max_queued, max_retries and history must not be negative"))
+		}
+	}
+
+	if pacing := c.Blockchain.SyncPacing; pacing.Enabled {
+		for _, field := range []struct{ name, value string }{
+			{"target_latency", pacing.TargetLatency},
+			{"max_delay", pacing.MaxDelay},
+			{"rate_limit_backoff", pacing.RateLimitBackoff},
+		} {
+			if field.value == "" {
+				continue
+			}
+			if duration, err := time.ParseDuration(field.value); err != nil || duration <= 0 {
+				errs = append(errs, fmt.Errorf("blockchain.sync_pacing.%s: invalid duration %q", field.name, field.value))
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateWatchGroups 校验关注组的合约及事件
+func (c *Config) validateWatchGroups() []error {
+	var errs []error
+
+	groupNames := make(map[string]bool)
+	for i, group := range c.Blockchain.WatchGroups {
+		prefix := fmt.Sprintf("blockchain.watch_groups[%d]", i)
+		if group.Name == "" {
+			errs = append(errs, fmt.Errorf("%s.name: required", prefix))
+		} else if groupNames[group.Name] {
+			errs = append(errs, fmt.Errorf("%s.name: duplicate watch group %q", prefix, group.Name))
+		}
+		groupNames[group.Name] = true
+		if len(group.Contracts) == 0 {
+			errs = append(errs, fmt.Errorf("%s.contracts: at least one contract required", prefix))
+		}
+		for _, contract := range group.Contracts {
+			if !common.IsHexAddress(contract) {
+				errs = append(errs, fmt.Errorf("%s.contracts: invalid address %q", prefix, contract))
+			}
+		}
+		if len(group.Events) == 0 {
+			errs = append(errs, fmt.Errorf("%s.events: at least one event required", prefix))
+		}
+		for j, event := range group.Events {
+			eventPrefix := fmt.Sprintf("%s.events[%d]", prefix, j)
+			if !strings.Contains(event.Signature, "(") || !strings.HasSuffix(event.Signature, ")") {
+				errs = append(errs, fmt.Errorf("%s.signature: invalid event declaration %q", eventPrefix, event.Signature))
+			}
+			switch event.Level {
+			case "", "LOW", "MEDIUM", "HIGH", "CRITICAL":
+			default:
+				errs = append(errs, fmt.Errorf("%s.level: must be LOW, MEDIUM, HIGH or CRITICAL, got %q", eventPrefix, event.Level))
+			}
+			for field, text := range map[string]string{"title": event.Title, "description": event.Description} {
+				if _, err := template.New(field).Parse(text); err != nil {
+					errs = append(errs, fmt.Errorf("%s.%s: %v", eventPrefix, field, err))
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateNetworks 校验启用的网络，至少启用一个
+func (c *Config) validateNetworks() []error {
+	var errs []error
+
+	enabled := 0
+	for name, network := range c.Blockchain.Networks {
+		if !network.Enabled {
+			continue
+		}
+		enabled++
+		errs = append(errs, validateNetwork("blockchain.networks."+name, network)...)
+	}
+	if enabled == 0 {
+		errs = append(errs, fmt.Errorf("blockchain.networks: at least one network must be enabled"))
+	}
+
+	return errs
+}
+
+// validateNetwork 校验单个启用网络的配置
+func validateNetwork(prefix string, network NetworkConfig) []error {
+	var errs []error
+
+	if network.RPCURL == "" {
+		errs = append(errs, fmt.Errorf("%s.rpc_url: required for enabled network", prefix))
+	}
+	if network.Chain != "" && network.Chain != "evm" && network.Chain != "solana" {
+		errs = append(errs, fmt.Errorf("%s.chain: must be evm or solana, got %q", prefix, network.Chain))
+	}
+	if threshold := network.NativeCurrency.HighValueThreshold; threshold != "" {
+		if _, ok := new(big.Float).SetString(threshold); !ok {
+			errs = append(errs, fmt.Errorf("%s.native_currency.high_value_threshold: invalid amount %q", prefix, threshold))
+		}
+	}
+	switch network.ExtraDataFormat {
+	case "", "builder", "clique", "arbitrum", "optimism", "none":
+	default:
+		errs = append(errs, fmt.Errorf("%s.extra_data_format: unknown format %q", prefix, network.ExtraDataFormat))
+	}
+	if network.NativeCurrency.HighValueThresholdUSD < 0 {
+		errs = append(errs, fmt.Errorf("%s.native_currency.high_value_threshold_usd: must not be negative", prefix))
+	}
+	if reward := network.NativeCurrency.BlockReward; reward != "" {
+		if value, ok := new(big.Float).SetString(reward); !ok || value.Sign() < 0 {
+			errs = append(errs, fmt.Errorf("%s.native_currency.block_reward: invalid amount %q", prefix, reward))
+		}
+	}
+	if network.BlockTime != "" {
+		if blockTime, err := time.ParseDuration(network.BlockTime); err != nil || blockTime <= 0 {
+			errs = append(errs, fmt.Errorf("%s.block_time: invalid duration %q", prefix, network.BlockTime))
+		}
+	}
+	if network.TimeSource != "" && network.TimeSource != "received" && network.TimeSource != "block" {
+		errs = append(errs, fmt.Errorf("%s.time_source: must be received or block, got %q", prefix, network.TimeSource))
+	}
+	if network.MaxClockSkew != "" {
+		if skew, err := time.ParseDuration(network.MaxClockSkew); err != nil || skew <= 0 {
+			errs = append(errs, fmt.Errorf("%s.max_clock_skew: invalid duration %q", prefix, network.MaxClockSkew))
+		}
+	}
+	switch network.BlockTag {
+	case "", "latest":
+	case "safe", "finalized":
+		if network.Chain == "solana" {
+			errs = append(errs, fmt.Errorf("%s.block_tag: not supported for solana, use commitment", prefix))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("%s.block_tag: must be latest, safe or finalized, got %q", prefix, network.BlockTag))
+	}
+	if network.PollInterval != "" {
+		if interval, err := time.ParseDuration(network.PollInterval); err != nil || interval <= 0 {
+			errs = append(errs, fmt.Errorf("%s.poll_interval: invalid duration %q", prefix, network.PollInterval))
+		}
+	}
+	if network.Confirmations > 0 && network.BlockTag != "" && network.BlockTag != "latest" {
+		errs = append(errs, fmt.Errorf("%s.confirmations: only supported with block_tag latest", prefix))
+	}
+	if network.MaxBatchSize < 0 {
+		errs = append(errs, fmt.Errorf("%s.max_batch_size: must not be negative", prefix))
+	}
+	switch network.ReceiptStrategy {
+	case "", "block", "transaction":
+	default:
+		errs = append(errs, fmt.Errorf("%s.receipt_strategy: must be block or transaction, got %q", prefix, network.ReceiptStrategy))
+	}
+	if network.Chain == "solana" && (network.Confirmations > 0 || network.StartBlock > 0 || network.ReceiptStrategy != "") {
+		errs = append(errs, fmt.Errorf("%s: confirmations, start_block and receipt_strategy are not supported for solana", prefix))
+	}
+	if network.Chain == "solana" && network.L1Fees {
+		errs = append(errs, fmt.Errorf("%s.l1_fees: only supported for arbitrum and optimism networks", prefix))
+	}
+	if network.Chain == "solana" && network.Receipts.Enabled {
+		errs = append(errs, fmt.Errorf("%s.receipts: not supported for solana, status is taken from the transaction meta", prefix))
+	}
+	if network.Receipts.BatchSize < 0 {
+		errs = append(errs, fmt.Errorf("%s.receipts.batch_size: must not be negative", prefix))
+	}
+	if network.Chain == "solana" && (len(network.LogFilter.Addresses) > 0 || len(network.LogFilter.Topics) > 0) {
+		errs = append(errs, fmt.Errorf("%s.log_filter: not supported for solana", prefix))
+	}
+	errs = append(errs, ValidateLogFilter(prefix+".log_filter", network.LogFilter)...)
+	if network.RateLimit.RequestsPerSecond < 0 || network.RateLimit.Burst < 0 {
+		errs = append(errs, fmt.Errorf("%s.rate_limit: requests_per_second and burst must not be negative", prefix))
+	}
+	if sampling := network.Sampling; sampling.Enabled {
+		if sampling.Rate < 0 || sampling.Rate > 1 {
+			errs = append(errs, fmt.Errorf("%s.sampling.rate: must be within [0, 1]", prefix))
+		}
+		if sampling.MinValue != "" {
+			if value, ok := new(big.Float).SetString(sampling.MinValue); !ok || value.Sign() < 0 {
+				errs = append(errs, fmt.Errorf("%s.sampling.min_value: invalid amount %q", prefix, sampling.MinValue))
+			}
+		}
+		if sampling.MinValueUSD < 0 {
+			errs = append(errs, fmt.Errorf("%s.sampling.min_value_usd: must not be negative", prefix))
+		}
+	}
+
+	return errs
+}
+
+// validateInfluxDB 校验InfluxDB的写入、保留策略及降采样
+func (c *Config) validateInfluxDB() []error {
+	var errs []error
+
+	write := c.InfluxDB.Write
+	if write.BatchSize <= 0 {
+		errs = append(errs, fmt.Errorf("influxdb.write.batch_size: must be greater than 0"))
+	}
+	if write.MaxRetries < 0 {
+		errs = append(errs, fmt.Errorf("influxdb.write.max_retries: must not be negative"))
+	}
+	for _, field := range []struct{ name, value string }{
+		{"flush_interval", write.FlushInterval},
+		{"retry_interval", write.RetryInterval},
+		{"max_retry_interval", write.MaxRetryInterval},
+	} {
+		if duration, err := time.ParseDuration(field.value); err != nil || duration <= 0 {
+			errs = append(errs, fmt.Errorf("influxdb.write.%s: invalid duration %q", field.name, field.value))
+		}
+	}
+
+	if retention := c.InfluxDB.Retention; retention.Enabled {
+		if interval, err := time.ParseDuration(retention.Interval); err != nil || interval <= 0 {
+			errs = append(errs, fmt.Errorf("influxdb.retention.interval: invalid duration %q", retention.Interval))
+		}
+		for measurement, value := range retention.Measurements {
+			if duration, err := time.ParseDuration(value); err != nil || duration <= 0 {
+				errs = append(errs, fmt.Errorf("influxdb.retention.measurements.%s: invalid duration %q", measurement, value))
+			}
+		}
+	}
+
+	if downsampling := c.InfluxDB.Downsampling; downsampling.Enabled {
+		if lag, err := time.ParseDuration(downsampling.Lag); err != nil || lag < 0 {
+			errs = append(errs, fmt.Errorf("influxdb.downsampling.lag: invalid duration %q", downsampling.Lag))
+		}
+		if len(downsampling.Rollups) == 0 {
+			errs = append(errs, fmt.Errorf("influxdb.downsampling.rollups: at least one rollup is required"))
+		}
+		var previous time.Duration
+		for i, rollup := range downsampling.Rollups {
+			every, err := time.ParseDuration(rollup.Every)
+			switch {
+			case err != nil || every < time.Second || every%time.Second != 0:
+				errs = append(errs, fmt.Errorf("influxdb.downsampling.rollups[%d].every: must be a whole number of seconds, got %q", i, rollup.Every))
+			case previous > 0 && (every <= previous || every%previous != 0):
+				errs = append(errs, fmt.Errorf("influxdb.downsampling.rollups[%d].every: must be a multiple of the previous rollup's %v", i, previous))
+			default:
+				previous = every
+			}
+			if rollup.Bucket == "" || rollup.Bucket == c.InfluxDB.Bucket {
+				errs = append(errs, fmt.Errorf("influxdb.downsampling.rollups[%d].bucket: must be set and differ from influxdb.bucket", i))
+			}
+			if rollup.Retention != "" {
+				if retention, err := time.ParseDuration(rollup.Retention); err != nil || retention < time.Hour {
+					errs = append(errs, fmt.Errorf("influxdb.downsampling.rollups[%d].retention: must be at least 1h, got %q", i, rollup.Retention))
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateKafka 校验消息后端的编码、压缩及事务配置
+func (c *Config) validateKafka() []error {
+	var errs []error
+
+	schemaEncoded := false
+	for topic, encoding := range c.Kafka.Encoding {
+		switch encoding {
+		case "", "json":
+			continue
+		case "avro", "protobuf":
+		default:
+			errs = append(errs, fmt.Errorf("kafka.encoding.%s: must be json, avro or protobuf, got %q", topic, encoding))
+			continue
+		}
+		if topic != "transactions" && topic != "blocks" && topic != "alerts" {
+			errs = append(errs, fmt.Errorf("kafka.encoding.%s: only transactions, blocks and alerts support %s", topic, encoding))
+		}
+		schemaEncoded = true
+	}
+	if schemaEncoded && c.Kafka.SchemaRegistry.URL == "" {
+		errs = append(errs, fmt.Errorf("kafka.schema_registry.url: required for avro or protobuf encoding"))
+	}
+	switch c.Kafka.Producer.Compression {
+	case "", "none", "gzip", "snappy", "lz4", "zstd":
+	default:
+		errs = append(errs, fmt.Errorf("kafka.producer.compression: must be none, gzip, snappy, lz4 or zstd, got %q", c.Kafka.Producer.Compression))
+	}
+	if c.Kafka.Producer.MaxMessageBytes < 0 {
+		errs = append(errs, fmt.Errorf("kafka.producer.max_message_bytes: must not be negative"))
+	}
+	switch c.Kafka.Backend {
+	case "", "kafka":
+	case "kinesis":
+		if c.Kafka.Kinesis.Region == "" {
+			errs = append(errs, fmt.Errorf("kafka.kinesis.region: required for kinesis backend"))
+		}
+	case "pubsub":
+		if c.Kafka.PubSub.ProjectID == "" {
+			errs = append(errs, fmt.Errorf("kafka.pubsub.project_id: required for pubsub backend"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("kafka.backend: must be kafka, kinesis or pubsub, got %q", c.Kafka.Backend))
+	}
+	if c.Kafka.Producer.Transactional {
+		if c.Kafka.Backend != "" && c.Kafka.Backend != "kafka" {
+			errs = append(errs, fmt.Errorf("kafka.producer.transactional: not supported by %s backend", c.Kafka.Backend))
+		}
+		if c.Kafka.Producer.TransactionalID == "" {
+			errs = append(errs, fmt.Errorf("kafka.producer.transactional_id: required when transactional is enabled"))
+		}
+	}
+	deadLetter := c.DataProcessing.DeadLetter
+	if deadLetter.Enabled && deadLetter.Backend == "kafka" && c.Kafka.Backend != "" && c.Kafka.Backend != "kafka" {
+		errs = append(errs, fmt.Errorf("data_processing.dead_letter.backend: kafka dead letters cannot be replayed from %s, use redis", c.Kafka.Backend))
+	}
+
+	return errs
+}
+
+// validateFilterRules 校验过滤规则的地址、表达式及规则名
+func (c *Config) validateFilterRules() []error {
+	var errs []error
+
+	rules := c.DataProcessing.FilterRules
+	if rules.MinValueWei != "" {
+		if _, ok := new(big.Int).SetString(rules.MinValueWei, 10); !ok {
+			errs = append(errs, fmt.Errorf("data_processing.filter_rules.min_value_wei: invalid integer %q", rules.MinValueWei))
+		}
+	}
+	for _, address := range rules.ExcludeContracts {
+		if !common.IsHexAddress(address) {
+			errs = append(errs, fmt.Errorf("data_processing.filter_rules.exclude_contracts: invalid address %q", address))
+		}
+	}
+	for _, address := range rules.IncludeAddresses {
+		if !common.IsHexAddress(address) {
+			errs = append(errs, fmt.Errorf("data_processing.filter_rules.include_addresses: invalid address %q", address))
+		}
+	}
+	switch rules.DefaultAction {
+	case "", "include", "exclude":
+	default:
+		errs = append(errs, fmt.Errorf("data_processing.filter_rules.default_action: must be include or exclude, got %q", rules.DefaultAction))
+	}
+	ruleNames := make(map[string]bool)
+	for i, rule := range rules.Rules {
+		prefix := fmt.Sprintf("data_processing.filter_rules.rules[%d]", i)
+		if rule.Name == "" {
+			errs = append(errs, fmt.Errorf("%s.name: required", prefix))
+		} else if ruleNames[rule.Name] {
+			errs = append(errs, fmt.Errorf("%s.name: duplicate rule %q", prefix, rule.Name))
+		}
+		ruleNames[rule.Name] = true
+		if rule.Action != "include" && rule.Action != "exclude" {
+			errs = append(errs, fmt.Errorf("%s.action: must be include or exclude, got %q", prefix, rule.Action))
+		}
+		if _, err := filterexpr.Compile(rule.Expr); err != nil {
+			errs = append(errs, fmt.Errorf("%s.expr: %v", prefix, err))
+		}
+	}
+
+	return errs
+}
+
+// validateAnalytics 校验地址统计、供应量、稳定币、跨链桥、资金流等统计分析配置
+func (c *Config) validateAnalytics() []error {
+	var errs []error
+
+	addressStats := c.DataProcessing.AddressStats
+	if addressStats.Retention != "" {
+		if retention, err := time.ParseDuration(addressStats.Retention); err != nil || retention <= 0 {
+			errs = append(errs, fmt.Errorf("data_processing.address_stats.retention: invalid duration %q", addressStats.Retention))
+		}
+	}
+	if addressStats.Rollup.Enabled {
+		if addressStats.Retention == "" {
+			errs = append(errs, fmt.Errorf("data_processing.address_stats.rollup: retention is required"))
+		}
+		if c.DataProcessing.Profile == "alerts_only" {
+			errs = append(errs, fmt.Errorf("data_processing.address_stats.rollup: requires InfluxDB, not available in alerts_only profile"))
+		}
+		if interval, err := time.ParseDuration(addressStats.Rollup.Interval); err != nil || interval <= 0 {
+			errs = append(errs, fmt.Errorf("data_processing.address_stats.rollup.interval: invalid duration %q", addressStats.Rollup.Interval))
+		}
+	}
+
+	if supply := c.DataProcessing.Supply; supply.Enabled && supply.Retention != "" {
+		if retention, err := time.ParseDuration(supply.Retention); err != nil || retention <= 0 {
+			errs = append(errs, fmt.Errorf("data_processing.supply.retention: invalid duration %q", supply.Retention))
+		}
+	}
+
+	stablecoins := c.DataProcessing.Stablecoins
+	if stablecoins.Enabled && stablecoins.Retention != "" {
+		if retention, err := time.ParseDuration(stablecoins.Retention); err != nil || retention <= 0 {
+			errs = append(errs, fmt.Errorf("data_processing.stablecoins.retention: invalid duration %q", stablecoins.Retention))
+		}
+	}
+	for _, token := range stablecoins.Tokens {
+		if _, exists := c.Blockchain.Networks[token.Network]; !exists {
+			errs = append(errs, fmt.Errorf("data_processing.stablecoins.tokens: unknown network %q", token.Network))
+		}
+		if !common.IsHexAddress(token.Address) {
+			errs = append(errs, fmt.Errorf("data_processing.stablecoins.tokens: invalid address %q", token.Address))
+		}
+		if token.Symbol == "" || token.Decimals == 0 {
+			errs = append(errs, fmt.Errorf("data_processing.stablecoins.tokens: symbol and decimals of %s are required", token.Address))
+		}
+		if token.AlertThreshold != "" {
+			if threshold, ok := new(big.Float).SetString(token.AlertThreshold); !ok || threshold.Sign() <= 0 {
+				errs = append(errs, fmt.Errorf("data_processing.stablecoins.tokens: invalid alert_threshold %q of %s", token.AlertThreshold, token.Address))
+			}
+		}
+	}
+
+	bridges := c.DataProcessing.Bridges
+	if bridges.Enabled && bridges.LinkTTL != "" {
+		if ttl, err := time.ParseDuration(bridges.LinkTTL); err != nil || ttl <= 0 {
+			errs = append(errs, fmt.Errorf("data_processing.bridges.link_ttl: invalid duration %q", bridges.LinkTTL))
+		}
+	}
+	for _, contract := range bridges.Contracts {
+		if _, exists := c.Blockchain.Networks[contract.Network]; !exists {
+			errs = append(errs, fmt.Errorf("data_processing.bridges.contracts: unknown network %q", contract.Network))
+		}
+		if !common.IsHexAddress(contract.Address) {
+			errs = append(errs, fmt.Errorf("data_processing.bridges.contracts: invalid address %q", contract.Address))
+		}
+		switch contract.Protocol {
+		case "wormhole":
+			if !common.IsHexAddress(contract.Core) {
+				errs = append(errs, fmt.Errorf("data_processing.bridges.contracts: wormhole bridge %s requires core contract address", contract.Address))
+			}
+			if contract.ChainID == 0 {
+				errs = append(errs, fmt.Errorf("data_processing.bridges.contracts: wormhole bridge %s requires chain_id", contract.Address))
+			}
+		case "layerzero":
+			if contract.ChainID == 0 {
+				errs = append(errs, fmt.Errorf("data_processing.bridges.contracts: layerzero endpoint %s requires chain_id", contract.Address))
+			}
+		case "op_standard":
+			if contract.Peer == "" || contract.Peer == contract.Network {
+				errs = append(errs, fmt.Errorf("data_processing.bridges.contracts: op_standard bridge %s requires a peer network, got %q", contract.Address, contract.Peer))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("data_processing.bridges.contracts: protocol must be wormhole, layerzero or op_standard, got %q", contract.Protocol))
+		}
+	}
+
+	clusters := c.DataProcessing.Clusters
+	if clusters.Enabled {
+		if clusters.MaxClusterSize < 2 {
+			errs = append(errs, fmt.Errorf("data_processing.clusters.max_cluster_size: must be at least 2"))
+		}
+		if clusters.MaxFundingFanout < 0 {
+			errs = append(errs, fmt.Errorf("data_processing.clusters.max_funding_fanout: must not be negative"))
+		}
+		if window, err := time.ParseDuration(clusters.FundingWindow); err != nil || window <= 0 {
+			errs = append(errs, fmt.Errorf("data_processing.clusters.funding_window: invalid duration %q", clusters.FundingWindow))
+		}
+	}
+	for _, wallet := range clusters.ExchangeWallets {
+		if !common.IsHexAddress(wallet) {
+			errs = append(errs, fmt.Errorf("data_processing.clusters.exchange_wallets: invalid address %q", wallet))
+		}
+	}
+
+	if oracle := c.DataProcessing.GasOracle; oracle.Enabled && oracle.HistoryBlocks <= 0 {
+		errs = append(errs, fmt.Errorf("data_processing.gas_oracle.history_blocks: must be positive"))
+	}
+
+	flows := c.DataProcessing.TokenFlows
+	if flows.Retention != "" {
+		if retention, err := time.ParseDuration(flows.Retention); err != nil || retention <= 0 {
+			errs = append(errs, fmt.Errorf("data_processing.token_flows.retention: invalid duration %q", flows.Retention))
+		}
+	}
+	for _, entity := range flows.Entities {
+		if !common.IsHexAddress(entity.Address) {
+			errs = append(errs, fmt.Errorf("data_processing.token_flows.entities: invalid address %q", entity.Address))
+		}
+		switch entity.Category {
+		case "exchange", "defi", "bridge":
+		default:
+			errs = append(errs, fmt.Errorf("data_processing.token_flows.entities: category of %s must be exchange, defi or bridge, got %q", entity.Address, entity.Category))
+		}
+	}
+
+	return errs
+}
+
+// validateDetectors 校验授权盗取、交易速度、代币风险、诈骗列表、关注列表、混币器等检测配置
+func (c *Config) validateDetectors() []error {
+	var errs []error
+
+	if window := c.DataProcessing.ApprovalDrain.Window; window != "" {
+		if _, err := time.ParseDuration(window); err != nil {
+			errs = append(errs, fmt.Errorf("data_processing.approval_drain.window: %v", err))
+		}
+	}
+	for _, address := range c.DataProcessing.ApprovalDrain.TrustedSpenders {
+		if !common.IsHexAddress(address) {
+			errs = append(errs, fmt.Errorf("data_processing.approval_drain.trusted_spenders: invalid address %q", address))
+		}
+	}
+
+	if velocity := c.DataProcessing.Velocity; velocity.Enabled {
+		if window, err := time.ParseDuration(velocity.Window); err != nil || window < time.Second {
+			errs = append(errs, fmt.Errorf("data_processing.velocity.window: invalid duration %q", velocity.Window))
+		}
+		if velocity.HistoryTTL != "" {
+			if _, err := time.ParseDuration(velocity.HistoryTTL); err != nil {
+				errs = append(errs, fmt.Errorf("data_processing.velocity.history_ttl: %v", err))
+			}
+		}
+		if velocity.Multiplier <= 1 {
+			errs = append(errs, fmt.Errorf("data_processing.velocity.multiplier: must be greater than 1"))
+		}
+		if velocity.DrainMinValue != "" {
+			if _, ok := new(big.Float).SetString(velocity.DrainMinValue); !ok {
+				errs = append(errs, fmt.Errorf("data_processing.velocity.drain_min_value: invalid amount %q", velocity.DrainMinValue))
+			}
+		}
+	}
+
+	if tokenRisk := c.DataProcessing.TokenRisk; tokenRisk.Enabled {
+		if tokenRisk.Threshold <= 0 || tokenRisk.Threshold > 1 {
+			errs = append(errs, fmt.Errorf("data_processing.token_risk.threshold: must be in (0, 1]"))
+		}
+		if tokenRisk.LiquidityPull <= 0 || tokenRisk.LiquidityPull > 1 {
+			errs = append(errs, fmt.Errorf("data_processing.token_risk.liquidity_pull: must be in (0, 1]"))
+		}
+		if tokenRisk.Retention != "" {
+			if retention, err := time.ParseDuration(tokenRisk.Retention); err != nil || retention <= 0 {
+				errs = append(errs, fmt.Errorf("data_processing.token_risk.retention: invalid duration %q", tokenRisk.Retention))
+			}
+		}
+	}
+
+	if scamFeeds := c.DataProcessing.ScamFeeds; scamFeeds.Enabled {
+		if interval, err := time.ParseDuration(scamFeeds.Interval); err != nil || interval <= 0 {
+			errs = append(errs, fmt.Errorf("data_processing.scam_feeds.interval: invalid duration %q", scamFeeds.Interval))
+		}
+		if timeout, err := time.ParseDuration(scamFeeds.Timeout); err != nil || timeout <= 0 {
+			errs = append(errs, fmt.Errorf("data_processing.scam_feeds.timeout: invalid duration %q", scamFeeds.Timeout))
+		}
+		names := make(map[string]bool, len(scamFeeds.Feeds))
+		for i, feed := range scamFeeds.Feeds {
+			path := fmt.Sprintf("data_processing.scam_feeds.feeds[%d]", i)
+			if feed.Name == "" {
+				errs = append(errs, fmt.Errorf("%s.name: required", path))
+			} else if feed.Name == "built-in" {
+				errs = append(errs, fmt.Errorf("%s.name: %q is reserved", path, feed.Name))
+			} else if names[feed.Name] {
+				errs = append(errs, fmt.Errorf("%s.name: duplicate feed %q", path, feed.Name))
+			}
+			names[feed.Name] = true
+			if feed.URL == "" {
+				errs = append(errs, fmt.Errorf("%s.url: required", path))
+			}
+			if feed.Format != "" && feed.Format != "text" && feed.Format != "json" {
+				errs = append(errs, fmt.Errorf("%s.format: must be text or json, got %q", path, feed.Format))
+			}
+		}
+	}
+
+	if c.DataProcessing.Watchlists.MaxAddresses < 0 {
+		errs = append(errs, fmt.Errorf("data_processing.watchlists.max_addresses: must not be negative"))
+	}
+	balances := c.DataProcessing.Watchlists.Balances
+	if balances.Enabled {
+		if !c.DataProcessing.Watchlists.Enabled {
+			errs = append(errs, fmt.Errorf("data_processing.watchlists.balances: requires watchlists to be enabled"))
+		}
+		if interval, err := time.ParseDuration(balances.Interval); err != nil || interval <= 0 {
+			errs = append(errs, fmt.Errorf("data_processing.watchlists.balances.interval: invalid duration %q", balances.Interval))
+		}
+		if window, err := time.ParseDuration(balances.DrawdownWindow); err != nil || window <= 0 {
+			errs = append(errs, fmt.Errorf("data_processing.watchlists.balances.drawdown_window: invalid duration %q", balances.DrawdownWindow))
+		}
+		if balances.DrawdownPercent <= 0 || balances.DrawdownPercent > 100 {
+			errs = append(errs, fmt.Errorf("data_processing.watchlists.balances.drawdown_percent: must be within (0, 100]"))
+		}
+	}
+	for _, token := range balances.Tokens {
+		if _, exists := c.Blockchain.Networks[token.Network]; !exists {
+			errs = append(errs, fmt.Errorf("data_processing.watchlists.balances.tokens: unknown network %q", token.Network))
+		}
+		if !common.IsHexAddress(token.Address) {
+			errs = append(errs, fmt.Errorf("data_processing.watchlists.balances.tokens: invalid address %q", token.Address))
+		}
+		if token.Symbol == "" {
+			errs = append(errs, fmt.Errorf("data_processing.watchlists.balances.tokens: symbol of %s is required", token.Address))
+		}
+	}
+
+	for _, method := range c.DataProcessing.MethodSignatures.RiskyMethods {
+		if method == "" || strings.ContainsAny(method, "(), ") {
+			errs = append(errs, fmt.Errorf("data_processing.method_signatures.risky_methods: %q must be a function name such as transferFrom", method))
+		}
+	}
+
+	mixer := c.DataProcessing.Mixer
+	if mixer.ExposureTTL != "" {
+		if _, err := time.ParseDuration(mixer.ExposureTTL); err != nil {
+			errs = append(errs, fmt.Errorf("data_processing.mixer.exposure_ttl: %v", err))
+		}
+	}
+	for _, contract := range mixer.Contracts {
+		if !common.IsHexAddress(contract.Address) {
+			errs = append(errs, fmt.Errorf("data_processing.mixer.contracts: invalid address %q", contract.Address))
+		}
+	}
+	if mixer.DepositScore < 0 || mixer.DepositScore > 1 || mixer.WithdrawalScore < 0 || mixer.WithdrawalScore > 1 {
+		errs = append(errs, fmt.Errorf("data_processing.mixer: deposit_score and withdrawal_score must be within [0, 1]"))
+	}
+
+	return errs
+}
+
+// validateAlerting 校验处理模式、去重、告警聚合、存储及风险评分
+func (c *Config) validateAlerting() []error {
+	var errs []error
+
+	switch c.DataProcessing.Profile {
+	case "", "full":
+	case "alerts_only":
+		if c.Kafka.Producer.Transactional {
+			errs = append(errs, fmt.Errorf("data_processing.profile: alerts_only does not publish transactions, disable kafka.producer.transactional"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("data_processing.profile: must be full or alerts_only, got %q", c.DataProcessing.Profile))
+	}
+
+	if ttl := c.DataProcessing.TxDedup.TTL; ttl != "" {
+		if duration, err := time.ParseDuration(ttl); err != nil || duration <= 0 {
+			errs = append(errs, fmt.Errorf("data_processing.tx_dedup.ttl: invalid duration %q", ttl))
+		}
+	}
+
+	if aggregation := c.DataProcessing.AlertAggregation; aggregation.Enabled {
+		if window, err := time.ParseDuration(aggregation.Window); err != nil || window <= 0 {
+			errs = append(errs, fmt.Errorf("data_processing.alert_aggregation.window: invalid duration %q", aggregation.Window))
+		}
+		if aggregation.PassThrough < 0 {
+			errs = append(errs, fmt.Errorf("data_processing.alert_aggregation.pass_through: must not be negative"))
+		}
+		if aggregation.RateLimit < 0 {
+			errs = append(errs, fmt.Errorf("data_processing.alert_aggregation.rate_limit: must not be negative"))
+		}
+		if aggregation.RateLimit > 0 && aggregation.Burst < 1 {
+			errs = append(errs, fmt.Errorf("data_processing.alert_aggregation.burst: must be at least 1 when rate_limit is set"))
+		}
+	}
+
+	if store := c.DataProcessing.AlertStore; store.Enabled {
+		if store.Backend != "redis" {
+			errs = append(errs, fmt.Errorf("data_processing.alert_store.backend: only redis is supported, got %q", store.Backend))
+		}
+		if retention, err := time.ParseDuration(store.Retention); err != nil || retention <= 0 {
+			errs = append(errs, fmt.Errorf("data_processing.alert_store.retention: invalid duration %q", store.Retention))
+		}
+	}
+
+	if scoring := c.DataProcessing.RiskScoring; scoring.Enabled {
+		switch scoring.Merge {
+		case "max", "sum", "weighted_average":
+		default:
+			errs = append(errs, fmt.Errorf("data_processing.risk_scoring.merge: must be max, sum or weighted_average, got %q", scoring.Merge))
+		}
+		if scoring.RuleWeight < 0 {
+			errs = append(errs, fmt.Errorf("data_processing.risk_scoring.rule_weight: must not be negative"))
+		}
+		names := make(map[string]bool, len(scoring.Scorers))
+		for i, scorer := range scoring.Scorers {
+			path := fmt.Sprintf("data_processing.risk_scoring.scorers[%d]", i)
+			if scorer.Name == "" {
+				errs = append(errs, fmt.Errorf("%s.name: required", path))
+			} else if names[scorer.Name] {
+				errs = append(errs, fmt.Errorf("%s.name: duplicate scorer %q", path, scorer.Name))
+			}
+			names[scorer.Name] = true
+			if (scorer.Type == "http" || scorer.Type == "grpc") && scorer.Endpoint == "" {
+				errs = append(errs, fmt.Errorf("%s.endpoint: required for %s scorers", path, scorer.Type))
+			}
+			if scorer.LatencyBudget != "" {
+				if budget, err := time.ParseDuration(scorer.LatencyBudget); err != nil || budget <= 0 {
+					errs = append(errs, fmt.Errorf("%s.latency_budget: invalid duration %q", path, scorer.LatencyBudget))
+				}
+			}
+			if scorer.Weight < 0 {
+				errs = append(errs, fmt.Errorf("%s.weight: must not be negative", path))
+			}
+			if scorer.AlertThreshold < 0 {
+				errs = append(errs, fmt.Errorf("%s.alert_threshold: must not be negative", path))
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateExports 校验数据仓库、SIEM、Webhook及归档等导出配置
+func (c *Config) validateExports() []error {
+	var errs []error
+
+	if warehouse := c.DataProcessing.Warehouse; warehouse.Enabled {
+		if warehouse.URL == "" || warehouse.Table == "" {
+			errs = append(errs, fmt.Errorf("data_processing.warehouse: url and table are required"))
+		}
+		if warehouse.Interval != "" {
+			if interval, err := time.ParseDuration(warehouse.Interval); err != nil || interval <= 0 {
+				errs = append(errs, fmt.Errorf("data_processing.warehouse.interval: invalid duration %q", warehouse.Interval))
+			}
+		}
+	}
+
+	if siem := c.DataProcessing.SIEM; siem.Enabled {
+		if len(siem.Exporters) == 0 {
+			errs = append(errs, fmt.Errorf("data_processing.siem.exporters: at least one exporter required"))
+		}
+		for i, exporter := range siem.Exporters {
+			prefix := fmt.Sprintf("data_processing.siem.exporters[%d]", i)
+			switch exporter.Type {
+			case "splunk_hec", "elastic":
+				if exporter.URL == "" {
+					errs = append(errs, fmt.Errorf("%s.url: required for %s", prefix, exporter.Type))
+				}
+				if exporter.Type == "elastic" && exporter.Index == "" {
+					errs = append(errs, fmt.Errorf("%s.index: required for elastic", prefix))
+				}
+			case "syslog":
+				if !strings.HasPrefix(exporter.Address, "udp://") && !strings.HasPrefix(exporter.Address, "tcp://") {
+					errs = append(errs, fmt.Errorf("%s.address: must start with udp:// or tcp://, got %q", prefix, exporter.Address))
+				}
+				switch exporter.Format {
+				case "", "cef", "json":
+				default:
+					errs = append(errs, fmt.Errorf("%s.format: must be cef or json, got %q", prefix, exporter.Format))
+				}
+			default:
+				errs = append(errs, fmt.Errorf("%s.type: must be splunk_hec, elastic or syslog, got %q", prefix, exporter.Type))
+			}
+			switch exporter.MinLevel {
+			case "", "LOW", "MEDIUM", "HIGH", "CRITICAL":
+			default:
+				errs = append(errs, fmt.Errorf("%s.min_level: must be LOW, MEDIUM, HIGH or CRITICAL, got %q", prefix, exporter.MinLevel))
+			}
+			if exporter.Timeout != "" {
+				if timeout, err := time.ParseDuration(exporter.Timeout); err != nil || timeout <= 0 {
+					errs = append(errs, fmt.Errorf("%s.timeout: invalid duration %q", prefix, exporter.Timeout))
+				}
+			}
+		}
+	}
+
+	if webhooks := c.DataProcessing.Webhooks; webhooks.Enabled {
+		if len(webhooks.Endpoints) == 0 {
+			errs = append(errs, fmt.Errorf("data_processing.webhooks.endpoints: at least one endpoint required"))
+		}
+		names := make(map[string]bool)
+		for i, endpoint := range webhooks.Endpoints {
+			prefix := fmt.Sprintf("data_processing.webhooks.endpoints[%d]", i)
+			if !strings.HasPrefix(endpoint.URL, "http://") && !strings.HasPrefix(endpoint.URL, "https://") {
+				errs = append(errs, fmt.Errorf("%s.url: must start with http:// or https://, got %q", prefix, endpoint.URL))
+			}
+			name := endpoint.Name
+			if name == "" {
+				name = endpoint.URL
+			}
+			if names[name] {
+				errs = append(errs, fmt.Errorf("%s.name: duplicate endpoint %q", prefix, name))
+			}
+			names[name] = true
+			for _, event := range endpoint.Events {
+				switch event {
+				case "block", "transaction", "alert":
+				default:
+					errs = append(errs, fmt.Errorf("%s.events: must be block, transaction or alert, got %q", prefix, event))
+				}
+			}
+			for _, address := range endpoint.Addresses {
+				if !common.IsHexAddress(address) {
+					errs = append(errs, fmt.Errorf("%s.addresses: invalid address %q", prefix, address))
+				}
+			}
+			if endpoint.MinValueWei != "" {
+				if _, ok := new(big.Int).SetString(endpoint.MinValueWei, 10); !ok {
+					errs = append(errs, fmt.Errorf("%s.min_value_wei: invalid amount %q", prefix, endpoint.MinValueWei))
+				}
+			}
+			switch endpoint.MinLevel {
+			case "", "LOW", "MEDIUM", "HIGH", "CRITICAL":
+			default:
+				errs = append(errs, fmt.Errorf("%s.min_level: must be LOW, MEDIUM, HIGH or CRITICAL, got %q", prefix, endpoint.MinLevel))
+			}
+			for _, field := range []struct{ name, value string }{
+				{"timeout", endpoint.Timeout},
+				{"initial_backoff", endpoint.InitialBackoff},
+				{"max_backoff", endpoint.MaxBackoff},
+			} {
+				if field.value == "" {
+					continue
+				}
+				if duration, err := time.ParseDuration(field.value); err != nil || duration <= 0 {
+					errs = append(errs, fmt.Errorf("%s.%s: invalid duration %q", prefix, field.name, field.value))
+				}
+			}
+			if endpoint.MaxRetries < 0 {
+				errs = append(errs, fmt.Errorf("%s.max_retries: must not be negative", prefix))
+			}
+			if endpoint.QueueSize < 0 {
+				errs = append(errs, fmt.Errorf("%s.queue_size: must not be negative", prefix))
+			}
+		}
+	}
+
+	if archive := c.DataProcessing.Archive; archive.Enabled {
+		switch archive.Backend {
+		case "s3":
+			if archive.S3.Region == "" {
+				errs = append(errs, fmt.Errorf("data_processing.archive.s3.region: required for s3 backend"))
+			}
+		case "gcs":
+		default:
+			errs = append(errs, fmt.Errorf("data_processing.archive.backend: must be s3 or gcs, got %q", archive.Backend))
+		}
+		if archive.Bucket == "" {
+			errs = append(errs, fmt.Errorf("data_processing.archive.bucket: required"))
+		}
+		if archive.Format != "parquet" && archive.Format != "json" {
+			errs = append(errs, fmt.Errorf("data_processing.archive.format: must be parquet or json, got %q", archive.Format))
+		}
+		if interval, err := time.ParseDuration(archive.FlushInterval); err != nil || interval <= 0 {
+			errs = append(errs, fmt.Errorf("data_processing.archive.flush_interval: invalid duration %q", archive.FlushInterval))
+		}
+		if archive.MaxRecords <= 0 {
+			errs = append(errs, fmt.Errorf("data_processing.archive.max_records: must be positive"))
+		}
+	}
+
+	return errs
+}
+
+// validateENS 校验ENS解析
+func (c *Config) validateENS() []error {
+	var errs []error
+
+	if ens := c.ENS; ens.Enabled {
+		if ens.RPCURL == "" {
+			errs = append(errs, fmt.Errorf("ens.rpc_url: required when ens is enabled"))
+		}
+		if ens.RegistryAddress != "" && !common.IsHexAddress(ens.RegistryAddress) {
+			errs = append(errs, fmt.Errorf("ens.registry_address: invalid address %q", ens.RegistryAddress))
+		}
+	}
+
+	return errs
+}
+
+// validateConnections 校验Redis、InfluxDB及消息后端的必填项和地址格式
+func (c *Config) validateConnections() []error {
+	var errs []error
+
+	if c.Redis.Host == "" {
+		errs = append(errs, fmt.Errorf("redis.host: required"))
+	}
+	if c.Redis.Port <= 0 || c.Redis.Port > 65535 {
+		errs = append(errs, fmt.Errorf("redis.port: invalid port %d", c.Redis.Port))
+	}
+	if c.Redis.DB < 0 {
+		errs = append(errs, fmt.Errorf("redis.db: must not be negative, got %d", c.Redis.DB))
+	}
+
+	// 告警专用部署不连接InfluxDB
+	if c.DataProcessing.Profile != "alerts_only" {
+		if err := validateURL(c.InfluxDB.URL, "http", "https"); err != nil {
+			errs = append(errs, fmt.Errorf("influxdb.url: %w", err))
+		}
+		for _, field := range []struct{ name, value string }{
+			{"token", c.InfluxDB.Token},
+			{"org", c.InfluxDB.Org},
+			{"bucket", c.InfluxDB.Bucket},
+		} {
+			if field.value == "" {
+				errs = append(errs, fmt.Errorf("influxdb.%s: required", field.name))
+			}
+		}
+	}
+
+	if c.Kafka.SchemaRegistry.URL != "" {
+		if err := validateURL(c.Kafka.SchemaRegistry.URL, "http", "https"); err != nil {
+			errs = append(errs, fmt.Errorf("kafka.schema_registry.url: %w", err))
+		}
+	}
+	if c.Kafka.Backend == "" || c.Kafka.Backend == "kafka" {
+		if len(c.Kafka.Brokers) == 0 && c.sinkEnabled("kafka") {
+			errs = append(errs, fmt.Errorf("kafka.brokers: at least one broker is required when the kafka sink is enabled"))
+		}
+		for i, broker := range c.Kafka.Brokers {
+			if err := validateHostPort(broker); err != nil {
+				errs = append(errs, fmt.Errorf("kafka.brokers[%d]: %w", i, err))
+			}
+		}
+	}
+
+	return append(errs, c.validateTopics()...)
+}
+
+// validateTopics 按消息后端的命名规则校验主题名，为空的主题不写出
+func (c *Config) validateTopics() []error {
+	pattern, rule := kafkaTopicPattern, "letters, digits, '.', '_' or '-', at most 249 characters"
+	switch c.Kafka.Backend {
+	case "kinesis":
+		pattern, rule = kinesisTopicPattern, "letters, digits, '.', '_' or '-', at most 128 characters"
+	case "pubsub":
+		pattern, rule = pubsubTopicPattern, "3-255 characters starting with a letter"
+	}
+
+	topics := c.Kafka.Topics
+	var errs []error
+	for _, topic := range []struct{ name, value string }{
+		{"transactions", topics.Transactions},
+		{"blocks", topics.Blocks},
+		{"alerts", topics.Alerts},
+		{"events", topics.Events},
+		{"headers", topics.Headers},
+		{"gas_stats", topics.GasStats},
+		{"withdrawals", topics.Withdrawals},
+		{"bridge_transfers", topics.BridgeTransfers},
+		{"enriched_blocks", topics.EnrichedBlocks},
+		{"dead_letter", topics.DeadLetter},
+	} {
+		if topic.value == "" {
+			continue
+		}
+		if topic.value == "." || topic.value == ".." || !pattern.MatchString(topic.value) {
+			errs = append(errs, fmt.Errorf("kafka.topics.%s: invalid name %q, must be %s", topic.name, topic.value, rule))
+		}
+		if c.Kafka.Backend == "pubsub" && strings.HasPrefix(strings.ToLower(topic.value), "goog") {
+			errs = append(errs, fmt.Errorf("kafka.topics.%s: pubsub topic %q must not start with goog", topic.name, topic.value))
+		}
+	}
+	if topics.Alerts == "" && c.sinkEnabled("kafka") {
+		errs = append(errs, fmt.Errorf("kafka.topics.alerts: required when the kafka sink is enabled"))
+	}
+	return errs
+}
+
+// validateNetworkEndpoints 校验启用网络的节点地址格式及链ID，错误按网络名排序
+func (c *Config) validateNetworkEndpoints() []error {
+	names := make([]string, 0, len(c.Blockchain.Networks))
+	for name, network := range c.Blockchain.Networks {
+		if network.Enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var errs []error
+	chainIDs := make(map[int64]string)
+	for _, name := range names {
+		network := c.Blockchain.Networks[name]
+		prefix := "blockchain.networks." + name
+
+		// rpc_url为空已单独报告；不含协议的地址按IPC路径处理
+		if strings.Contains(network.RPCURL, "://") {
+			if err := validateURL(network.RPCURL, "http", "https", "ws", "wss"); err != nil {
+				errs = append(errs, fmt.Errorf("%s.rpc_url: %w", prefix, err))
+			}
+		}
+		if network.WSURL != "" {
+			if err := validateURL(network.WSURL, "ws", "wss"); err != nil {
+				errs = append(errs, fmt.Errorf("%s.ws_url: %w", prefix, err))
+			}
+		}
+
+		if network.Chain == "solana" {
+			continue
+		}
+		switch {
+		case network.ChainID <= 0:
+			errs = append(errs, fmt.Errorf("%s.chain_id: must be a positive EVM chain ID, got %d", prefix, network.ChainID))
+			continue
+		case knownChainIDs[name] != 0 && knownChainIDs[name] != network.ChainID:
+			errs = append(errs, fmt.Errorf("%s.chain_id: %s has chain ID %d, got %d", prefix, name, knownChainIDs[name], network.ChainID))
+		}
+		if other, exists := chainIDs[network.ChainID]; exists {
+			errs = append(errs, fmt.Errorf("%s.chain_id: chain ID %d is already used by network %s", prefix, network.ChainID, other))
+		}
+		chainIDs[network.ChainID] = name
+	}
+	return errs
+}
+
+// validateWorkers 校验数据处理的并发数及批量大小
+func (c *Config) validateWorkers() []error {
+	var errs []error
+	if c.DataProcessing.Workers <= 0 || c.DataProcessing.Workers > maxWorkers {
+		errs = append(errs, fmt.Errorf("data_processing.workers: must be within [1, %d], got %d", maxWorkers, c.DataProcessing.Workers))
+	}
+	if c.DataProcessing.BatchSize <= 0 {
+		errs = append(errs, fmt.Errorf("data_processing.batch_size: must be greater than 0, got %d", c.DataProcessing.BatchSize))
+	}
+	if c.Kafka.Producer.BatchSize < 0 {
+		errs = append(errs, fmt.Errorf("kafka.producer.batch_size: must not be negative, got %d", c.Kafka.Producer.BatchSize))
+	}
+	if c.Kafka.Producer.MaxAttempts <= 0 {
+		errs = append(errs, fmt.Errorf("kafka.producer.max_attempts: must be greater than 0, got %d", c.Kafka.Producer.MaxAttempts))
+	}
+	return errs
+}
+
+// sinkEnabled 判断输出端是否启用，未配置输出端时使用默认列表，其中包含kafka
+func (c *Config) sinkEnabled(sinkType string) bool {
+	if len(c.DataProcessing.Sinks) == 0 {
+		return sinkType == "kafka" || sinkType == "stream" || sinkType == "influxdb" || sinkType == "redis"
+	}
+	for _, sink := range c.DataProcessing.Sinks {
+		if sink.Type == sinkType && sink.Enabled {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateLogFilter 校验网络级日志过滤的合约地址及事件签名，也用于运行时修改过滤条件
+func ValidateLogFilter(prefix string, filter NetworkLogFilterConfig) []error {
+	var errs []error
+	for i, address := range filter.Addresses {
+		if !common.IsHexAddress(address) {
+			errs = append(errs, fmt.Errorf("%s.addresses[%d]: invalid address %q", prefix, i, address))
+		}
+	}
+	for i, topic := range filter.Topics {
+		if !eventTopicPattern.MatchString(topic) {
+			errs = append(errs, fmt.Errorf("%s.topics[%d]: invalid topic %q, must be a 32-byte hex string", prefix, i, topic))
+		}
+	}
+	return errs
+}
+
+// validateURL 校验URL格式及协议
+func validateURL(raw string, schemes ...string) error {
+	if raw == "" {
+		return fmt.Errorf("required")
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %v", raw, err)
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("invalid URL %q: missing host", raw)
+	}
+	for _, scheme := range schemes {
+		if parsed.Scheme == scheme {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid URL %q: scheme must be %s", raw, strings.Join(schemes, ", "))
+}
+
+// validateHostPort 校验 host:port 格式
+func validateHostPort(address string) error {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid address %q, expected host:port", address)
+	}
+	if host == "" {
+		return fmt.Errorf("invalid address %q: missing host", address)
+	}
+	if number, err := strconv.Atoi(port); err != nil || number <= 0 || number > 65535 {
+		return fmt.Errorf("invalid address %q: invalid port", address)
+	}
+	return nil
+}
+
+// validateIPOrCIDR 校验IP地址或CIDR网段
+func validateIPOrCIDR(value string) error {
+	if strings.Contains(value, "/") {
+		if _, _, err := net.ParseCIDR(value); err != nil {
+			return fmt.Errorf("invalid CIDR %q", value)
+		}
+		return nil
+	}
+	if net.ParseIP(value) == nil {
+		return fmt.Errorf("invalid IP address %q", value)
+	}
+	return nil
+}
+
+// FormatValidationErrors 将校验错误整理为启动时输出的多行报告，按字段路径排序
+func FormatValidationErrors(errs []error) string {
+	lines := make([]string, 0, len(errs))
+	for _, err := range errs {
+		lines = append(lines, "  - "+err.Error())
+	}
+	sort.Strings(lines)
+	return fmt.Sprintf("invalid configuration (%d problems):\n%s", len(errs), strings.Join(lines, "\n"))
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestConfig 复制仓库中的config.yml到临时目录，按old、new成对替换内容，返回文件路径
+func writeTestConfig(t *testing.T, replacements ...string) string {
+	t.Helper()
+
+	data, err := os.ReadFile("../../config.yml")
+	if err != nil {
+		t.Fatalf("read config.yml: %v", err)
+	}
+	content := string(data)
+	for i := 0; i+1 < len(replacements); i += 2 {
+		if !strings.Contains(content, replacements[i]) {
+			t.Fatalf("config.yml does not contain %q", replacements[i])
+		}
+		content = strings.Replace(content, replacements[i], replacements[i+1], 1)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func loadTestConfig(t *testing.T, replacements ...string) *Config {
+	t.Helper()
+
+	config, err := Load(writeTestConfig(t, replacements...))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return config
+}
+
+// expectErrors 检查错误列表中依次包含各子串
+func expectErrors(t *testing.T, errs []error, want ...string) {
+	t.Helper()
+
+	for _, substr := range want {
+		found := false
+		for _, err := range errs {
+			if strings.Contains(err.Error(), substr) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("expected an error containing %q, got:\n%s", substr, FormatValidationErrors(errs))
+		}
+	}
+}
+
+func TestValidateRepoConfig(t *testing.T) {
+	config := loadTestConfig(t)
+
+	// 多单词的键只有按yaml标签解码才能读到
+	if config.GRPC.StreamBufferSize != 256 {
+		t.Errorf("grpc.stream_buffer_size = %d, want 256", config.GRPC.StreamBufferSize)
+	}
+	if errs := config.Validate(); len(errs) > 0 {
+		t.Fatalf("config.yml should validate:\n%s", FormatValidationErrors(errs))
+	}
+}
+
+func TestValidateReportsSecretErrors(t *testing.T) {
+	config := loadTestConfig(t,
+		`token: "your-influxdb-token"`, `token: "env://WEB3_COLLECTOR_TEST_UNSET"`,
+		`port: 6379`, `port: 0`,
+	)
+
+	errs := config.Validate()
+	expectErrors(t, errs,
+		"influxdb.token: environment variable WEB3_COLLECTOR_TEST_UNSET is not set",
+		"redis.port: invalid port 0",
+	)
+}
+
+func TestValidateConnections(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(c *Config)
+		want   []string
+	}{
+		{
+			name: "valid",
+		},
+		{
+			name: "redis",
+			mutate: func(c *Config) {
+				c.Redis.Host = ""
+				c.Redis.Port = 70000
+				c.Redis.DB = -1
+			},
+			want: []string{"redis.host: required", "redis.port: invalid port 70000", "redis.db: must not be negative"},
+		},
+		{
+			name: "influxdb",
+			mutate: func(c *Config) {
+				c.InfluxDB.URL = "localhost:8086"
+				c.InfluxDB.Bucket = ""
+			},
+			want: []string{"influxdb.url:", "influxdb.bucket: required"},
+		},
+		{
+			name: "alerts only skips influxdb",
+			mutate: func(c *Config) {
+				c.DataProcessing.Profile = "alerts_only"
+				c.InfluxDB.URL = ""
+				c.InfluxDB.Token = ""
+			},
+		},
+		{
+			name: "kafka brokers",
+			mutate: func(c *Config) {
+				c.Kafka.Backend = "kafka"
+				c.Kafka.Brokers = []string{"localhost:9092", "localhost"}
+			},
+			want: []string{"kafka.brokers[1]:"},
+		},
+		{
+			name: "schema registry",
+			mutate: func(c *Config) {
+				c.Kafka.SchemaRegistry.URL = "ftp://registry"
+			},
+			want: []string{"kafka.schema_registry.url:"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := loadTestConfig(t)
+			if tt.mutate != nil {
+				tt.mutate(config)
+			}
+
+			errs := config.validateConnections()
+			if len(tt.want) == 0 {
+				if len(errs) > 0 {
+					t.Fatalf("unexpected errors:\n%s", FormatValidationErrors(errs))
+				}
+				return
+			}
+			if len(errs) != len(tt.want) {
+				t.Errorf("got %d errors, want %d:\n%s", len(errs), len(tt.want), FormatValidationErrors(errs))
+			}
+			expectErrors(t, errs, tt.want...)
+		})
+	}
+}
@@ -0,0 +1,319 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"web3-data-collector/internal/config"
+
+	"github.com/influxdata/influxdb-client-go/v2/api"
+	"github.com/influxdata/influxdb-client-go/v2/domain"
+)
+
+// rollupSpec 一组字段的汇总方式
+type rollupSpec struct {
+	measurement string
+	fields      []string
+	rawFn       string // 汇总原始数据的函数
+	output      string // 汇总结果的字段名，为空时保持原名，仅单个字段时可设置
+	rollupFn    string // 汇总上一级结果的函数
+}
+
+// rollupSpecs 各measurement的降采样字段。交易笔数按value_native计数，启用采样时为采样后的笔数
+func rollupSpecs(measurements config.InfluxMeasurementsConfig) []rollupSpec {
+	var specs []rollupSpec
+	if measurements.GasStats.Enabled {
+		specs = append(specs,
+			rollupSpec{measurement: "gas_stats", fields: []string{"base_fee", "next_base_fee", "priority_fee_p50", "priority_fee_p90", "utilization"}, rawFn: "mean", rollupFn: "mean"},
+			rollupSpec{measurement: "gas_stats", fields: []string{"gas_used", "tx_count"}, rawFn: "sum", rollupFn: "sum"},
+		)
+	}
+	if measurements.Transactions.Enabled {
+		specs = append(specs,
+			rollupSpec{measurement: "transactions", fields: []string{"value_native"}, rawFn: "count", output: "tx_count", rollupFn: "sum"},
+			rollupSpec{measurement: "transactions", fields: []string{"value_native"}, rawFn: "sum", output: "volume_native", rollupFn: "sum"},
+			rollupSpec{measurement: "transactions", fields: []string{"value_usd"}, rawFn: "sum", output: "volume_usd", rollupFn: "sum"},
+		)
+	}
+	if measurements.BlockAggregates.Enabled {
+		specs = append(specs,
+			rollupSpec{measurement: "block_aggregates", fields: []string{"tx_count", "total_value_native", "total_gas_used"}, rawFn: "sum", rollupFn: "sum"},
+		)
+	}
+	return specs
+}
+
+// influxRollup 解析后的一级汇总
+type influxRollup struct {
+	every     time.Duration
+	source    string // 读取的bucket，第一级为原始数据bucket
+	bucket    string
+	retention time.Duration
+	first     bool
+}
+
+// InfluxMaintainer 创建及更新降采样任务和汇总bucket，按周期删除超过保留时长的原始数据点
+type InfluxMaintainer struct {
+	client    *InfluxDBClient
+	org       string
+	bucket    string
+	specs     []rollupSpec
+	rollups   []influxRollup
+	lag       time.Duration
+	retention map[string]time.Duration
+	interval  time.Duration
+	ready     bool // 汇总任务已创建
+}
+
+// NewInfluxMaintainer 创建InfluxDB维护任务，降采样及保留时长均未启用时返回nil
+func NewInfluxMaintainer(cfg config.InfluxDBConfig, client *InfluxDBClient) (*InfluxMaintainer, error) {
+	if !cfg.Retention.Enabled && !cfg.Downsampling.Enabled {
+		return nil, nil
+	}
+	if client == nil {
+		return nil, fmt.Errorf("influxdb maintenance requires an InfluxDB client")
+	}
+
+	m := &InfluxMaintainer{
+		client:    client,
+		org:       cfg.Org,
+		bucket:    cfg.Bucket,
+		retention: make(map[string]time.Duration),
+		interval:  time.Hour,
+		ready:     !cfg.Downsampling.Enabled,
+	}
+
+	if cfg.Retention.Enabled {
+		interval, err := time.ParseDuration(cfg.Retention.Interval)
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("invalid influxdb retention interval: %q", cfg.Retention.Interval)
+		}
+		m.interval = interval
+		for measurement, value := range cfg.Retention.Measurements {
+			retention, err := time.ParseDuration(value)
+			if err != nil || retention <= 0 {
+				return nil, fmt.Errorf("invalid retention of measurement %s: %q", measurement, value)
+			}
+			m.retention[measurement] = retention
+		}
+	}
+
+	if cfg.Downsampling.Enabled {
+		lag, err := time.ParseDuration(cfg.Downsampling.Lag)
+		if err != nil || lag < 0 {
+			return nil, fmt.Errorf("invalid influxdb downsampling lag: %q", cfg.Downsampling.Lag)
+		}
+		m.lag = lag
+		m.specs = rollupSpecs(cfg.Measurements)
+
+		source := cfg.Bucket
+		for i, rollup := range cfg.Downsampling.Rollups {
+			every, err := time.ParseDuration(rollup.Every)
+			if err != nil || every < time.Second {
+				return nil, fmt.Errorf("invalid rollup interval: %q", rollup.Every)
+			}
+			var retention time.Duration
+			if rollup.Retention != "" {
+				if retention, err = time.ParseDuration(rollup.Retention); err != nil {
+					return nil, fmt.Errorf("invalid retention of rollup bucket %s: %q", rollup.Bucket, rollup.Retention)
+				}
+			}
+			m.rollups = append(m.rollups, influxRollup{
+				every:     every,
+				source:    source,
+				bucket:    rollup.Bucket,
+				retention: retention,
+				first:     i == 0,
+			})
+			source = rollup.Bucket
+		}
+	}
+
+	logger.Infof("InfluxDB maintenance enabled (%d rollups, retention for %d measurements)", len(m.rollups), len(m.retention))
+	return m, nil
+}
+
+// Run 创建汇总任务后按周期删除超期数据点，直到ctx结束；创建失败时在下个周期重试
+func (m *InfluxMaintainer) Run(ctx context.Context) {
+	m.maintain(ctx)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.maintain(ctx)
+		}
+	}
+}
+
+func (m *InfluxMaintainer) maintain(ctx context.Context) {
+	if !m.ready {
+		if err := m.setupRollups(ctx); err != nil {
+			logger.Errorf("Failed to set up InfluxDB downsampling: %v", err)
+		} else {
+			m.ready = true
+		}
+	}
+	m.enforceRetention(ctx)
+}
+
+// setupRollups 创建或更新各级汇总的目标bucket及任务
+func (m *InfluxMaintainer) setupRollups(ctx context.Context) error {
+	org, err := m.client.client.OrganizationsAPI().FindOrganizationByName(ctx, m.org)
+	if err != nil {
+		return fmt.Errorf("failed to find organization %s: %w", m.org, err)
+	}
+
+	for _, rollup := range m.rollups {
+		if err := m.ensureBucket(ctx, org, rollup); err != nil {
+			return err
+		}
+		if err := m.ensureTask(ctx, *org.Id, rollup); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureBucket 创建汇总bucket，已存在时按配置更新保留时长
+func (m *InfluxMaintainer) ensureBucket(ctx context.Context, org *domain.Organization, rollup influxRollup) error {
+	bucketsAPI := m.client.client.BucketsAPI()
+	rule := domain.RetentionRule{EverySeconds: int64(rollup.retention.Seconds())}
+
+	buckets, err := bucketsAPI.FindBucketsByOrgName(ctx, m.org)
+	if err != nil {
+		return fmt.Errorf("failed to list buckets: %w", err)
+	}
+	for _, bucket := range *buckets {
+		if bucket.Name != rollup.bucket {
+			continue
+		}
+		if len(bucket.RetentionRules) == 1 && bucket.RetentionRules[0].EverySeconds == rule.EverySeconds {
+			return nil
+		}
+		bucket.RetentionRules = domain.RetentionRules{rule}
+		if _, err := bucketsAPI.UpdateBucket(ctx, &bucket); err != nil {
+			return fmt.Errorf("failed to update retention of bucket %s: %w", rollup.bucket, err)
+		}
+		logger.Infof("Updated retention of InfluxDB bucket %s to %v", rollup.bucket, rollup.retention)
+		return nil
+	}
+
+	if _, err := bucketsAPI.CreateBucketWithName(ctx, org, rollup.bucket, rule); err != nil {
+		return fmt.Errorf("failed to create bucket %s: %w", rollup.bucket, err)
+	}
+	logger.Infof("Created InfluxDB bucket %s (retention: %v)", rollup.bucket, rollup.retention)
+	return nil
+}
+
+// ensureTask 创建汇总任务，同名任务已存在时更新脚本
+func (m *InfluxMaintainer) ensureTask(ctx context.Context, orgID string, rollup influxRollup) error {
+	tasksAPI := m.client.client.TasksAPI()
+	name := rollupTaskName(m.bucket, rollup)
+	flux := m.rollupFlux(name, rollup)
+
+	tasks, err := tasksAPI.FindTasks(ctx, &api.TaskFilter{Name: name, OrgID: orgID})
+	if err != nil {
+		return fmt.Errorf("failed to find task %s: %w", name, err)
+	}
+	if len(tasks) > 0 {
+		task := tasks[0]
+		if task.Flux == flux {
+			return nil
+		}
+		task.Flux = flux
+		task.Every, task.Cron = nil, nil
+		if _, err := tasksAPI.UpdateTask(ctx, &task); err != nil {
+			return fmt.Errorf("failed to update task %s: %w", name, err)
+		}
+		logger.Infof("Updated InfluxDB downsampling task %s", name)
+		return nil
+	}
+
+	if _, err := tasksAPI.CreateTaskByFlux(ctx, flux, orgID); err != nil {
+		return fmt.Errorf("failed to create task %s: %w", name, err)
+	}
+	logger.Infof("Created InfluxDB downsampling task %s", name)
+	return nil
+}
+
+// rollupTaskName 任务名包含原始数据bucket，同一InfluxDB上的多套部署互不覆盖
+func rollupTaskName(bucket string, rollup influxRollup) string {
+	return fmt.Sprintf("%s rollup %s", bucket, rollup.every)
+}
+
+// rollupFlux 生成汇总任务的Flux脚本。每次执行重新汇总截至本次调度时间（任务中的now()）的已结束窗口及lag内的窗口，
+// 重复写入的汇总点时间戳及标签相同，覆盖之前的结果；汇总只保留network标签
+func (m *InfluxMaintainer) rollupFlux(name string, rollup influxRollup) string {
+	every := fluxDuration(rollup.every)
+	// 回看整数个窗口，最早的窗口也是完整的，不会以部分数据覆盖之前的结果
+	windows := 1 + (m.lag+rollup.every-1)/rollup.every
+	lookback := fluxDuration(time.Duration(windows) * rollup.every)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "import \"date\"\n\n")
+	fmt.Fprintf(&b, "option task = {name: %q, every: %s, offset: 10s}\n\n", name, every)
+	fmt.Fprintf(&b, "stop = date.truncate(t: now(), unit: %s)\n", every)
+	fmt.Fprintf(&b, "start = date.sub(d: %s, from: stop)\n", lookback)
+
+	for _, spec := range m.specs {
+		fields, fn := spec.fields, spec.rawFn
+		if !rollup.first {
+			fn = spec.rollupFn
+			if spec.output != "" {
+				fields = []string{spec.output}
+			}
+		}
+		conditions := make([]string, len(fields))
+		for i, field := range fields {
+			conditions[i] = fmt.Sprintf("r._field == %q", field)
+		}
+
+		fmt.Fprintf(&b, "\nfrom(bucket: %q)\n", rollup.source)
+		fmt.Fprintf(&b, "    |> range(start: start, stop: stop)\n")
+		fmt.Fprintf(&b, "    |> filter(fn: (r) => r._measurement == %q and (%s))\n", spec.measurement, strings.Join(conditions, " or "))
+		fmt.Fprintf(&b, "    |> group(columns: [\"_measurement\", \"_field\", \"network\"])\n")
+		fmt.Fprintf(&b, "    |> aggregateWindow(every: %s, fn: %s, timeSrc: \"_start\", createEmpty: false)\n", every, fn)
+		if rollup.first && spec.output != "" {
+			fmt.Fprintf(&b, "    |> set(key: \"_field\", value: %q)\n", spec.output)
+		}
+		fmt.Fprintf(&b, "    |> to(bucket: %q, org: %q)\n", rollup.bucket, m.org)
+	}
+	return b.String()
+}
+
+// fluxDuration 以秒表示的Flux时长
+func fluxDuration(d time.Duration) string {
+	return fmt.Sprintf("%ds", int64(d/time.Second))
+}
+
+// enforceRetention 删除各measurement超过保留时长的数据点
+func (m *InfluxMaintainer) enforceRetention(ctx context.Context) {
+	measurements := make([]string, 0, len(m.retention))
+	for measurement := range m.retention {
+		measurements = append(measurements, measurement)
+	}
+	sort.Strings(measurements)
+
+	deleteAPI := m.client.client.DeleteAPI()
+	now := time.Now()
+	for _, measurement := range measurements {
+		if ctx.Err() != nil {
+			return
+		}
+		cutoff := now.Add(-m.retention[measurement])
+		predicate := fmt.Sprintf("_measurement=%q", measurement)
+		if err := deleteAPI.DeleteWithName(ctx, m.org, m.bucket, time.Unix(0, 0), cutoff, predicate); err != nil {
+			logger.Errorf("Failed to delete %s points older than %s: %v", measurement, cutoff.Format(time.RFC3339), err)
+			continue
+		}
+		logger.Debugf("Deleted %s points older than %s", measurement, cutoff.Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,318 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+
+	"github.com/influxdata/influxdb-client-go/v2/api"
+	influxhttp "github.com/influxdata/influxdb-client-go/v2/api/http"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+)
+
+// 写入配置未指定时的默认值
+const (
+	defaultInfluxBatchSize        = 5000
+	defaultInfluxFlushInterval    = time.Second
+	defaultInfluxRetryInterval    = time.Second
+	defaultInfluxMaxRetryInterval = 30 * time.Second
+
+	// 等待写出的批次上限，写入持续失败时丢弃最早的批次
+	influxWriteQueueSize = 16
+)
+
+var errInfluxWriteQueueFull = errors.New("write queue full, oldest batch discarded")
+
+// influxWriteSettings 解析后的写入配置
+type influxWriteSettings struct {
+	batchSize        int
+	flushInterval    time.Duration
+	maxRetries       int
+	retryInterval    time.Duration
+	maxRetryInterval time.Duration
+}
+
+// parseWriteSettings 解析写入配置，未设置的字段使用默认值
+func parseWriteSettings(cfg config.InfluxWriteConfig) (influxWriteSettings, error) {
+	settings := influxWriteSettings{
+		batchSize:        cfg.BatchSize,
+		flushInterval:    defaultInfluxFlushInterval,
+		maxRetries:       cfg.MaxRetries,
+		retryInterval:    defaultInfluxRetryInterval,
+		maxRetryInterval: defaultInfluxMaxRetryInterval,
+	}
+	if settings.batchSize <= 0 {
+		settings.batchSize = defaultInfluxBatchSize
+	}
+	if settings.maxRetries < 0 {
+		settings.maxRetries = 0
+	}
+
+	for _, field := range []struct {
+		name   string
+		value  string
+		target *time.Duration
+	}{
+		{"flush_interval", cfg.FlushInterval, &settings.flushInterval},
+		{"retry_interval", cfg.RetryInterval, &settings.retryInterval},
+		{"max_retry_interval", cfg.MaxRetryInterval, &settings.maxRetryInterval},
+	} {
+		if field.value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(field.value)
+		if err != nil || duration <= 0 {
+			return settings, fmt.Errorf("invalid influxdb.write.%s: %q", field.name, field.value)
+		}
+		*field.target = duration
+	}
+
+	return settings, nil
+}
+
+// influxBatch 待写出的批次；flushed非空时为Flush的同步标记，不含数据
+type influxBatch struct {
+	lines   []string
+	flushed chan struct{}
+}
+
+// influxBatchWriter 按批次数或间隔以阻塞API写出数据点，失败的批次按指数退避重试。
+// 重试耗尽、不可重试或因队列已满被丢弃的批次都交给onFailure
+type influxBatchWriter struct {
+	writeAPI  api.WriteAPIBlocking
+	settings  influxWriteSettings
+	onFailure func(failure *InfluxWriteFailure)
+
+	mu    sync.Mutex
+	lines []string
+
+	queue chan influxBatch
+	stop  chan struct{}
+	done  chan struct{}
+	once  sync.Once
+}
+
+func newInfluxBatchWriter(writeAPI api.WriteAPIBlocking, settings influxWriteSettings, onFailure func(failure *InfluxWriteFailure)) *influxBatchWriter {
+	w := &influxBatchWriter{
+		writeAPI:  writeAPI,
+		settings:  settings,
+		onFailure: onFailure,
+		lines:     make([]string, 0, settings.batchSize),
+		queue:     make(chan influxBatch, influxWriteQueueSize),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// WritePoint 将数据点加入当前批次，达到批次大小时提交写出
+func (w *influxBatchWriter) WritePoint(point *write.Point) {
+	line := write.PointToLineProtocol(point, time.Nanosecond)
+
+	w.mu.Lock()
+	w.lines = append(w.lines, line)
+	var full []string
+	if len(w.lines) >= w.settings.batchSize {
+		full = w.takeLocked()
+	}
+	w.mu.Unlock()
+
+	if full != nil {
+		w.enqueue(influxBatch{lines: full})
+	}
+}
+
+// QueueDepth 队列中的批次数，当前批次非空时计为一批
+func (w *influxBatchWriter) QueueDepth() int64 {
+	w.mu.Lock()
+	depth := int64(len(w.queue))
+	if len(w.lines) > 0 {
+		depth++
+	}
+	w.mu.Unlock()
+	return depth
+}
+
+// Flush 提交当前批次并等待之前提交的批次全部写出或放弃
+func (w *influxBatchWriter) Flush() {
+	w.flushBuffer()
+
+	flushed := make(chan struct{})
+	select {
+	case w.queue <- influxBatch{flushed: flushed}:
+	case <-w.done:
+		return
+	}
+	select {
+	case <-flushed:
+	case <-w.done:
+	}
+}
+
+// Close 写出剩余数据后停止，之后写入的数据点不再写出
+func (w *influxBatchWriter) Close() {
+	w.once.Do(func() {
+		w.Flush()
+		close(w.stop)
+		<-w.done
+	})
+}
+
+func (w *influxBatchWriter) takeLocked() []string {
+	if len(w.lines) == 0 {
+		return nil
+	}
+	lines := w.lines
+	w.lines = make([]string, 0, w.settings.batchSize)
+	return lines
+}
+
+func (w *influxBatchWriter) flushBuffer() {
+	w.mu.Lock()
+	lines := w.takeLocked()
+	w.mu.Unlock()
+
+	if lines != nil {
+		w.enqueue(influxBatch{lines: lines})
+	}
+}
+
+// enqueue 提交批次，队列已满时丢弃最早的批次，避免写入持续失败时阻塞处理流程
+func (w *influxBatchWriter) enqueue(batch influxBatch) {
+	for {
+		select {
+		case w.queue <- batch:
+			return
+		case <-w.done:
+			w.fail(batch.lines, 0, errors.New("writer closed"))
+			return
+		default:
+		}
+
+		select {
+		case oldest := <-w.queue:
+			if oldest.flushed != nil {
+				close(oldest.flushed)
+				continue
+			}
+			w.fail(oldest.lines, 0, errInfluxWriteQueueFull)
+		default:
+		}
+	}
+}
+
+func (w *influxBatchWriter) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.settings.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case batch := <-w.queue:
+			w.handle(batch)
+		case <-ticker.C:
+			w.flushBuffer()
+		case <-w.stop:
+			// Close已等待队列写完，此处只处理并发写入的残留批次
+			for {
+				select {
+				case batch := <-w.queue:
+					w.handle(batch)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (w *influxBatchWriter) handle(batch influxBatch) {
+	if batch.flushed != nil {
+		close(batch.flushed)
+		return
+	}
+	w.writeWithRetry(batch.lines)
+}
+
+// writeWithRetry 写出批次，网络错误、限流及服务端错误在配置的次数内按退避间隔重试，
+// 其余错误（如行协议错误）重试无效，直接放弃。停止时不再等待重试
+func (w *influxBatchWriter) writeWithRetry(lines []string) {
+	delay := w.settings.retryInterval
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		err := w.writeAPI.WriteRecord(ctx, lines...)
+		cancel()
+		if err == nil {
+			return
+		}
+
+		wait, retryable := retryableWrite(err)
+		if !retryable || attempt > w.settings.maxRetries {
+			w.fail(lines, attempt, err)
+			return
+		}
+		w.report(&InfluxWriteFailure{
+			Batch:    strings.Join(lines, "\n"),
+			Points:   len(lines),
+			Attempts: attempt,
+			Err:      err,
+		})
+
+		if wait <= 0 {
+			wait = delay
+		}
+		delay *= 2
+		if delay > w.settings.maxRetryInterval {
+			delay = w.settings.maxRetryInterval
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-w.stop:
+			w.fail(lines, attempt, err)
+			return
+		}
+	}
+}
+
+func (w *influxBatchWriter) fail(lines []string, attempts int, err error) {
+	w.report(&InfluxWriteFailure{
+		Batch:    strings.Join(lines, "\n"),
+		Points:   len(lines),
+		Attempts: attempts,
+		Final:    true,
+		Err:      err,
+	})
+}
+
+func (w *influxBatchWriter) report(failure *InfluxWriteFailure) {
+	if failure.Final {
+		logger.Errorf("InfluxDB write of %d points failed after %d attempts, dropping batch: %v", failure.Points, failure.Attempts, failure.Err)
+	} else {
+		logger.Warnf("InfluxDB write of %d points failed (attempt %d), retrying: %v", failure.Points, failure.Attempts, failure.Err)
+	}
+	if w.onFailure != nil {
+		w.onFailure(failure)
+	}
+}
+
+// retryableWrite 与客户端库的判断一致：网络错误（无状态码）及429以上的状态码可重试，
+// 返回服务端通过Retry-After要求的等待时间
+func retryableWrite(err error) (time.Duration, bool) {
+	var writeErr *influxhttp.Error
+	if !errors.As(err, &writeErr) {
+		return 0, true
+	}
+	if writeErr.StatusCode != 0 && writeErr.StatusCode < http.StatusTooManyRequests {
+		return 0, false
+	}
+	return time.Duration(writeErr.RetryAfter) * time.Second, true
+}
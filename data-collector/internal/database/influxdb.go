@@ -3,25 +3,50 @@ package database
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/logging"
 
 	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
 	"github.com/influxdata/influxdb-client-go/v2/api"
-	"github.com/sirupsen/logrus"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
 )
 
+// logger database模块日志，级别可通过logging.modules.database单独设置
+var logger = logging.Module("database")
+
 // InfluxDBClient InfluxDB客户端
 type InfluxDBClient struct {
-	client   influxdb2.Client
-	writeAPI api.WriteAPI
-	queryAPI api.QueryAPI
-	config   config.InfluxDBConfig
+	client        influxdb2.Client
+	writer        *influxBatchWriter
+	queryAPI      api.QueryAPI
+	config        config.InfluxDBConfig
+	failedHandler InfluxWriteFailedHandler
+	mu            sync.RWMutex
+}
+
+// InfluxWriteFailure 一批数据点的异步写入失败
+type InfluxWriteFailure struct {
+	Batch    string // 行协议格式的数据点，每行一个
+	Points   int
+	Attempts int  // 已尝试写入的次数，因等待队列已满被丢弃时为0
+	Final    bool // 重试耗尽、不可重试或等待队列已满，批次被丢弃
+	Err      error
 }
 
+// InfluxWriteFailedHandler 处理异步写入失败，在批量写入器的后台goroutine中调用，不应长时间阻塞
+type InfluxWriteFailedHandler func(failure *InfluxWriteFailure)
+
 // NewInfluxDBClient 创建新的InfluxDB客户端
 func NewInfluxDBClient(config config.InfluxDBConfig) (*InfluxDBClient, error) {
+	settings, err := parseWriteSettings(config.Write)
+	if err != nil {
+		return nil, err
+	}
+
 	// 创建InfluxDB客户端
 	client := influxdb2.NewClient(config.URL, config.Token)
 
@@ -31,25 +56,74 @@ func NewInfluxDBClient(config config.InfluxDBConfig) (*InfluxDBClient, error) {
 
 	health, err := client.Health(ctx)
 	if err != nil {
+		client.Close()
 		return nil, fmt.Errorf("failed to connect to InfluxDB: %w", err)
 	}
 
 	if health.Status != "pass" {
+		client.Close()
 		return nil, fmt.Errorf("InfluxDB health check failed: %s", health.Status)
 	}
 
-	// 创建写入和查询API
-	writeAPI := client.WriteAPI(config.Org, config.Bucket)
+	// 创建查询API，写入由批量写入器按批次调用阻塞API完成
 	queryAPI := client.QueryAPI(config.Org)
 
-	logrus.Infof("Successfully connected to InfluxDB at %s", config.URL)
+	logger.Infof("Successfully connected to InfluxDB at %s", config.URL)
 
-	return &InfluxDBClient{
+	idb := &InfluxDBClient{
 		client:   client,
-		writeAPI: writeAPI,
 		queryAPI: queryAPI,
 		config:   config,
-	}, nil
+	}
+	idb.writer = newInfluxBatchWriter(client.WriteAPIBlocking(config.Org, config.Bucket), settings, idb.writeFailed)
+
+	return idb, nil
+}
+
+// SetWriteFailedHandler 设置异步写入失败的处理函数，未设置时只记录日志
+func (idb *InfluxDBClient) SetWriteFailedHandler(handler InfluxWriteFailedHandler) {
+	idb.mu.Lock()
+	defer idb.mu.Unlock()
+	idb.failedHandler = handler
+}
+
+// writeFailed 批量写入器的失败回调，转交给设置的处理函数
+func (idb *InfluxDBClient) writeFailed(failure *InfluxWriteFailure) {
+	idb.mu.RLock()
+	handler := idb.failedHandler
+	idb.mu.RUnlock()
+	if handler != nil {
+		handler(failure)
+	}
+}
+
+// Ping 检查InfluxDB健康状态
+func (idb *InfluxDBClient) Ping(ctx context.Context) error {
+	health, err := idb.client.Health(ctx)
+	if err != nil {
+		return err
+	}
+	if health.Status != "pass" {
+		return fmt.Errorf("InfluxDB health check failed: %s", health.Status)
+	}
+	return nil
+}
+
+// QueueDepth 等待写出的批次数，包括未满的当前批次
+func (idb *InfluxDBClient) QueueDepth() int64 {
+	return idb.writer.QueueDepth()
+}
+
+// WriteLineProtocol 同步写入行协议格式的数据点，用于重放死信队列中写入失败的批次
+func (idb *InfluxDBClient) WriteLineProtocol(batch string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	lines := strings.Split(strings.TrimRight(batch, "\n"), "\n")
+	if err := idb.client.WriteAPIBlocking(idb.config.Org, idb.config.Bucket).WriteRecord(ctx, lines...); err != nil {
+		return fmt.Errorf("failed to write line protocol: %w", err)
+	}
+	return nil
 }
 
 // WritePoint 写入数据点
@@ -63,19 +137,24 @@ func (idb *InfluxDBClient) WritePoint(
 	point := influxdb2.NewPoint(measurement, tags, fields, timestamp)
 
 	// 写入数据点
-	idb.writeAPI.WritePoint(point)
+	idb.writer.WritePoint(point)
 
 	return nil
 }
 
+// Measurements 获取各measurement的写入配置
+func (idb *InfluxDBClient) Measurements() config.InfluxMeasurementsConfig {
+	return idb.config.Measurements
+}
+
 // WriteBatch 批量写入数据点
-func (idb *InfluxDBClient) WriteBatch(points []*influxdb2.Point) error {
+func (idb *InfluxDBClient) WriteBatch(points []*write.Point) error {
 	for _, point := range points {
-		idb.writeAPI.WritePoint(point)
+		idb.writer.WritePoint(point)
 	}
 
 	// 强制刷新
-	idb.writeAPI.Flush()
+	idb.writer.Flush()
 
 	return nil
 }
@@ -197,18 +276,300 @@ func (idb *InfluxDBClient) GetTransactionVolume(network string, timeRange string
 	return idb.Query(query)
 }
 
+// GetBlockByNumber 按区块号查询区块，未找到时返回nil
+func (idb *InfluxDBClient) GetBlockByNumber(network string, number uint64, start, stop time.Time) (map[string]interface{}, error) {
+	query := fmt.Sprintf(`
+		from(bucket: "%s")
+		|> %s
+		|> filter(fn: (r) => r["_measurement"] == "blocks")
+		|> filter(fn: (r) => r["network"] == "%s")
+		|> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+		|> filter(fn: (r) => r["number"] == %d)
+		|> limit(n: 1)
+	`, idb.config.Bucket, fluxRange(start, stop), network, number)
+
+	records, err := idb.Query(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	return cleanRecord(records[0]), nil
+}
+
+// GetTransactionByHash 按交易哈希查询交易，未找到时返回nil
+func (idb *InfluxDBClient) GetTransactionByHash(network, hash string, start, stop time.Time) (map[string]interface{}, error) {
+	query := fmt.Sprintf(`
+		from(bucket: "%s")
+		|> %s
+		|> filter(fn: (r) => r["_measurement"] == "transactions")
+		|> filter(fn: (r) => r["network"] == "%s")
+		|> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+		|> filter(fn: (r) => r["hash"] == "%s")
+		|> limit(n: 1)
+	`, idb.config.Bucket, fluxRange(start, stop), network, hash)
+
+	records, err := idb.Query(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	return cleanRecord(records[0]), nil
+}
+
+// GetAddressTransactions 分页查询地址相关交易，按时间倒序
+func (idb *InfluxDBClient) GetAddressTransactions(network, address string, start, stop time.Time, limit, offset int) ([]map[string]interface{}, error) {
+	query := fmt.Sprintf(`
+		from(bucket: "%s")
+		|> %s
+		|> filter(fn: (r) => r["_measurement"] == "transactions")
+		|> filter(fn: (r) => r["network"] == "%s")
+		|> filter(fn: (r) => r["from_address"] == "%s" or r["to_address"] == "%s")
+		|> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+		|> group()
+		|> sort(columns: ["_time"], desc: true)
+		|> limit(n: %d, offset: %d)
+	`, idb.config.Bucket, fluxRange(start, stop), network, address, address, limit, offset)
+
+	records, err := idb.Query(query)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]map[string]interface{}, 0, len(records))
+	for _, record := range records {
+		transactions = append(transactions, cleanRecord(record))
+	}
+
+	return transactions, nil
+}
+
+// GetBlocks 分页查询区块，按时间倒序
+func (idb *InfluxDBClient) GetBlocks(network string, start, stop time.Time, limit, offset int) ([]map[string]interface{}, error) {
+	query := fmt.Sprintf(`
+		from(bucket: "%s")
+		|> %s
+		|> filter(fn: (r) => r["_measurement"] == "blocks")
+		|> filter(fn: (r) => r["network"] == "%s")
+		|> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+		|> group()
+		|> sort(columns: ["_time"], desc: true)
+		|> limit(n: %d, offset: %d)
+	`, idb.config.Bucket, fluxRange(start, stop), network, limit, offset)
+
+	return idb.queryRecords(query)
+}
+
+// GetBlockTransactions 分页查询区块内的交易，按交易哈希排序
+func (idb *InfluxDBClient) GetBlockTransactions(network string, number uint64, start, stop time.Time, limit, offset int) ([]map[string]interface{}, error) {
+	query := fmt.Sprintf(`
+		from(bucket: "%s")
+		|> %s
+		|> filter(fn: (r) => r["_measurement"] == "transactions")
+		|> filter(fn: (r) => r["network"] == "%s")
+		|> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+		|> filter(fn: (r) => r["block_number"] == %d)
+		|> group()
+		|> sort(columns: ["hash"])
+		|> limit(n: %d, offset: %d)
+	`, idb.config.Bucket, fluxRange(start, stop), network, number, limit, offset)
+
+	return idb.queryRecords(query)
+}
+
+// GetTransfers 分页查询非零原生币转账，address不为空时只查询该地址转出或转入的转账，按时间倒序
+func (idb *InfluxDBClient) GetTransfers(network, address string, start, stop time.Time, limit, offset int) ([]map[string]interface{}, error) {
+	addressFilter := ""
+	if address != "" {
+		addressFilter = fmt.Sprintf(`|> filter(fn: (r) => r["from_address"] == "%s" or r["to_address"] == "%s")`, address, address)
+	}
+
+	query := fmt.Sprintf(`
+		from(bucket: "%s")
+		|> %s
+		|> filter(fn: (r) => r["_measurement"] == "transactions")
+		|> filter(fn: (r) => r["network"] == "%s")
+		%s
+		|> filter(fn: (r) => r["_field"] == "hash" or r["_field"] == "value" or r["_field"] == "value_native" or r["_field"] == "value_usd" or r["_field"] == "block_number")
+		|> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+		|> filter(fn: (r) => r["value"] != "0" and r["to_address"] != "")
+		|> group()
+		|> sort(columns: ["_time"], desc: true)
+		|> limit(n: %d, offset: %d)
+	`, idb.config.Bucket, fluxRange(start, stop), network, addressFilter, limit, offset)
+
+	return idb.queryRecords(query)
+}
+
+// queryRecords 执行查询并去除内部列
+func (idb *InfluxDBClient) queryRecords(query string) ([]map[string]interface{}, error) {
+	records, err := idb.Query(query)
+	if err != nil {
+		return nil, err
+	}
+
+	cleaned := make([]map[string]interface{}, 0, len(records))
+	for _, record := range records {
+		cleaned = append(cleaned, cleanRecord(record))
+	}
+
+	return cleaned, nil
+}
+
+// GetOutgoingTransfers 查询地址发出的非零原生币转账，按时间升序
+func (idb *InfluxDBClient) GetOutgoingTransfers(network, address string, start, stop time.Time, limit int) ([]map[string]interface{}, error) {
+	query := fmt.Sprintf(`
+		from(bucket: "%s")
+		|> %s
+		|> filter(fn: (r) => r["_measurement"] == "transactions")
+		|> filter(fn: (r) => r["network"] == "%s")
+		|> filter(fn: (r) => r["from_address"] == "%s")
+		|> filter(fn: (r) => r["_field"] == "hash" or r["_field"] == "value" or r["_field"] == "value_native" or r["_field"] == "block_number")
+		|> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+		|> filter(fn: (r) => r["value"] != "0" and r["to_address"] != "")
+		|> group()
+		|> sort(columns: ["_time"])
+		|> limit(n: %d)
+	`, idb.config.Bucket, fluxRange(start, stop), network, address, limit)
+
+	records, err := idb.Query(query)
+	if err != nil {
+		return nil, err
+	}
+
+	transfers := make([]map[string]interface{}, 0, len(records))
+	for _, record := range records {
+		transfers = append(transfers, cleanRecord(record))
+	}
+
+	return transfers, nil
+}
+
+// GetGasPriceHistory 按窗口计算交易gas价格（wei）的p25/p50/p90，
+// gas_price以字符串字段存储，查询时转换为浮点数
+func (idb *InfluxDBClient) GetGasPriceHistory(network string, start, stop time.Time, every time.Duration) ([]map[string]interface{}, error) {
+	query := fmt.Sprintf(`
+		data = from(bucket: "%s")
+			|> %s
+			|> filter(fn: (r) => r["_measurement"] == "transactions")
+			|> filter(fn: (r) => r["network"] == "%s")
+			|> filter(fn: (r) => r["_field"] == "gas_price")
+			|> map(fn: (r) => ({r with _value: float(v: r._value)}))
+			|> group(columns: ["network"])
+
+		percentile = (q, name) => data
+			|> aggregateWindow(every: %ds, fn: (column, tables=<-) => tables |> quantile(q: q, column: column), createEmpty: false)
+			|> set(key: "percentile", value: name)
+
+		union(tables: [percentile(q: 0.25, name: "p25"), percentile(q: 0.5, name: "p50"), percentile(q: 0.9, name: "p90")])
+	`, idb.config.Bucket, fluxRange(start, stop), network, int64(every.Seconds()))
+
+	return idb.Query(query)
+}
+
+// GetTransactionVolumeHistory 按窗口统计交易笔数及原生币、美元交易额，
+// 笔数按value_native计数，启用采样时为采样后的笔数
+func (idb *InfluxDBClient) GetTransactionVolumeHistory(network string, start, stop time.Time, every time.Duration) ([]map[string]interface{}, error) {
+	query := fmt.Sprintf(`
+		data = from(bucket: "%s")
+			|> %s
+			|> filter(fn: (r) => r["_measurement"] == "transactions")
+			|> filter(fn: (r) => r["network"] == "%s")
+			|> filter(fn: (r) => r["_field"] == "value_native" or r["_field"] == "value_usd")
+			|> group(columns: ["network", "_field"])
+
+		count = data
+			|> filter(fn: (r) => r["_field"] == "value_native")
+			|> aggregateWindow(every: %[4]ds, fn: count, createEmpty: false)
+			|> set(key: "_field", value: "tx_count")
+		volume = data
+			|> aggregateWindow(every: %[4]ds, fn: sum, createEmpty: false)
+
+		union(tables: [count, volume])
+	`, idb.config.Bucket, fluxRange(start, stop), network, int64(every.Seconds()))
+
+	return idb.Query(query)
+}
+
+// GetAlerts 查询时间范围内的告警，network为空时查询全部网络，按时间升序
+func (idb *InfluxDBClient) GetAlerts(network string, start, stop time.Time, limit int) ([]map[string]interface{}, error) {
+	networkFilter := ""
+	if network != "" {
+		networkFilter = fmt.Sprintf(`|> filter(fn: (r) => r["network"] == "%s")`, network)
+	}
+
+	query := fmt.Sprintf(`
+		from(bucket: "%s")
+		|> %s
+		|> filter(fn: (r) => r["_measurement"] == "alerts")
+		%s
+		|> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+		|> group()
+		|> sort(columns: ["_time"])
+		|> limit(n: %d)
+	`, idb.config.Bucket, fluxRange(start, stop), networkFilter, limit)
+
+	records, err := idb.Query(query)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := make([]map[string]interface{}, 0, len(records))
+	for _, record := range records {
+		alerts = append(alerts, cleanRecord(record))
+	}
+
+	return alerts, nil
+}
+
+// fluxRange 生成range子句，未指定开始时间时查询全部数据
+func fluxRange(start, stop time.Time) string {
+	startExpr := "0"
+	if !start.IsZero() {
+		startExpr = start.UTC().Format(time.RFC3339)
+	}
+
+	if stop.IsZero() {
+		return fmt.Sprintf("range(start: %s)", startExpr)
+	}
+
+	return fmt.Sprintf("range(start: %s, stop: %s)", startExpr, stop.UTC().Format(time.RFC3339))
+}
+
+// cleanRecord 去除查询结果中的内部列，保留数据字段、标签和时间
+func cleanRecord(record map[string]interface{}) map[string]interface{} {
+	cleaned := make(map[string]interface{}, len(record))
+	for key, value := range record {
+		switch key {
+		case "_time":
+			cleaned["timestamp"] = value
+		case "result", "table", "_start", "_stop", "_measurement":
+		default:
+			cleaned[key] = value
+		}
+	}
+	return cleaned
+}
+
 // Flush 刷新写入缓冲区
 func (idb *InfluxDBClient) Flush() {
-	idb.writeAPI.Flush()
+	idb.writer.Flush()
 }
 
 // Close 关闭连接
 func (idb *InfluxDBClient) Close() {
-	if idb.writeAPI != nil {
-		idb.writeAPI.Flush()
+	if idb.writer != nil {
+		idb.writer.Close()
 	}
 	if idb.client != nil {
 		idb.client.Close()
 	}
-	logrus.Info("InfluxDB client closed")
+	logger.Info("InfluxDB client closed")
 }
\ No newline at end of file
@@ -2,13 +2,13 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"web3-data-collector/internal/config"
 
 	"github.com/go-redis/redis/v8"
-	"github.com/sirupsen/logrus"
 )
 
 // RedisClient Redis客户端封装
@@ -35,7 +35,7 @@ func NewRedisClient(config config.RedisConfig) (*RedisClient, error) {
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
-	logrus.Infof("Successfully connected to Redis at %s:%d", config.Host, config.Port)
+	logger.Infof("Successfully connected to Redis at %s:%d", config.Host, config.Port)
 
 	return &RedisClient{
 		client: client,
@@ -51,6 +51,14 @@ func (rc *RedisClient) Set(key string, value interface{}, expiration time.Durati
 	return rc.client.Set(ctx, key, value, expiration).Err()
 }
 
+// SetNX 键不存在时设置键值对，返回是否设置成功
+func (rc *RedisClient) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return rc.client.SetNX(ctx, key, value, expiration).Result()
+}
+
 // Get 获取值
 func (rc *RedisClient) Get(key string) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -59,6 +67,26 @@ func (rc *RedisClient) Get(key string) (string, error) {
 	return rc.client.Get(ctx, key).Result()
 }
 
+// GetIfExists 获取字符串值，键不存在时返回false
+func (rc *RedisClient) GetIfExists(key string) (string, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	value, err := rc.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return "", false, nil
+	}
+	return value, err == nil, err
+}
+
+// MGet 批量获取字符串值，键不存在时对应位置为nil
+func (rc *RedisClient) MGet(keys ...string) ([]interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return rc.client.MGet(ctx, keys...).Result()
+}
+
 // GetInt64 获取整数值
 func (rc *RedisClient) GetInt64(key string) (int64, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -91,6 +119,140 @@ func (rc *RedisClient) Delete(key string) error {
 	return rc.client.Del(ctx, key).Err()
 }
 
+// DeleteIfExists 删除键，返回键是否存在
+func (rc *RedisClient) DeleteIfExists(key string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	count, err := rc.client.Del(ctx, key).Result()
+	return count > 0, err
+}
+
+// 值匹配时才续期或删除，用于持有者校验的锁
+var (
+	expireIfValueScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+	deleteIfValueScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+	// 分数未超过阈值时才删除，用于清理不活跃的记录
+	deleteIfScoreScript = redis.NewScript(`
+local score = redis.call("ZSCORE", KEYS[1], ARGV[1])
+if score and tonumber(score) <= tonumber(ARGV[2]) then
+	redis.call("ZREM", KEYS[1], ARGV[1])
+	return redis.call("DEL", KEYS[2])
+end
+return 0`)
+	// 令牌桶，哈希保存剩余令牌数及上次补充时间（毫秒），返回是否取得令牌及需等待的毫秒数
+	takeTokenScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(bucket[1]) or burst
+local ts = tonumber(bucket[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+local allowed, wait = 0, 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) * 1000 / rate)
+end
+redis.call("HSET", KEYS[1], "tokens", tokens, "ts", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+return {allowed, wait}`)
+)
+
+// TakeToken 从key对应的令牌桶中取一个令牌，令牌每秒补充rate个，最多burst个。
+// 未取得时返回需等待的时间，桶在补满后过期
+func (rc *RedisClient) TakeToken(key string, rate float64, burst int) (bool, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := takeTokenScript.Run(ctx, rc.client, []string{key}, rate, burst, time.Now().UnixMilli()).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	if len(result) != 2 {
+		return false, 0, fmt.Errorf("unexpected token bucket result: %v", result)
+	}
+	return result[0] == 1, time.Duration(result[1]) * time.Millisecond, nil
+}
+
+// ExpireIfValue 键的值等于value时重新设置过期时间，返回是否续期
+func (rc *RedisClient) ExpireIfValue(key, value string, expiration time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := expireIfValueScript.Run(ctx, rc.client, []string{key}, value, expiration.Milliseconds()).Int64()
+	return result == 1, err
+}
+
+// DeleteIfValue 键的值等于value时删除，返回是否删除
+func (rc *RedisClient) DeleteIfValue(key, value string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := deleteIfValueScript.Run(ctx, rc.client, []string{key}, value).Int64()
+	return result == 1, err
+}
+
+// DeleteIfScore 有序集合中member的分数不大于max时移除member并删除key，返回是否删除
+func (rc *RedisClient) DeleteIfScore(index, member string, max float64, key string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := deleteIfScoreScript.Run(ctx, rc.client, []string{index, key}, member, max).Int64()
+	return result == 1, err
+}
+
+// XAdd 向stream追加记录，maxLen大于0时近似裁剪到该长度
+func (rc *RedisClient) XAdd(stream string, maxLen int64, values map[string]interface{}) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return rc.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		MaxLen: maxLen,
+		Approx: maxLen > 0,
+		Values: values,
+	}).Result()
+}
+
+// XRange 按顺序读取stream中最早的count条记录
+func (rc *RedisClient) XRange(stream string, count int64) ([]redis.XMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return rc.client.XRangeN(ctx, stream, "-", "+", count).Result()
+}
+
+// XGet 按ID读取stream中的单条记录，不存在时返回nil
+func (rc *RedisClient) XGet(stream, id string) (*redis.XMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	messages, err := rc.client.XRangeN(ctx, stream, id, id, 1).Result()
+	if err != nil || len(messages) == 0 {
+		return nil, err
+	}
+	return &messages[0], nil
+}
+
+// XDel 删除stream中的记录
+func (rc *RedisClient) XDel(stream string, ids ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return rc.client.XDel(ctx, stream, ids...).Err()
+}
+
 // HSet 设置哈希字段
 func (rc *RedisClient) HSet(key string, field string, value interface{}) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -115,6 +277,14 @@ func (rc *RedisClient) HGetAll(key string) (map[string]string, error) {
 	return rc.client.HGetAll(ctx, key).Result()
 }
 
+// HDel 删除哈希字段
+func (rc *RedisClient) HDel(key string, fields ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return rc.client.HDel(ctx, key, fields...).Err()
+}
+
 // HMSet 批量设置哈希字段
 func (rc *RedisClient) HMSet(key string, fields map[string]interface{}) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -174,6 +344,22 @@ func (rc *RedisClient) ZAdd(key string, score float64, member string) error {
 	return rc.client.ZAdd(ctx, key, z).Err()
 }
 
+// ZRem 移除有序集合成员
+func (rc *RedisClient) ZRem(key string, members ...interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return rc.client.ZRem(ctx, key, members...).Err()
+}
+
+// ZRemRangeByScore 移除分数范围内的有序集合成员
+func (rc *RedisClient) ZRemRangeByScore(key string, min, max string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return rc.client.ZRemRangeByScore(ctx, key, min, max).Err()
+}
+
 // ZRange 获取有序集合范围内的成员
 func (rc *RedisClient) ZRange(key string, start, stop int64) ([]string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -203,6 +389,35 @@ func (rc *RedisClient) ZRangeByScore(key string, min, max string) ([]string, err
 	return rc.client.ZRangeByScore(ctx, key, opt).Result()
 }
 
+// ZRevRangeByScoreN 按分数从高到低获取有序集合中跳过offset个后的最多count个成员
+func (rc *RedisClient) ZRevRangeByScoreN(key string, max, min string, offset, count int64) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opt := &redis.ZRangeBy{
+		Min:    min,
+		Max:    max,
+		Offset: offset,
+		Count:  count,
+	}
+
+	return rc.client.ZRevRangeByScore(ctx, key, opt).Result()
+}
+
+// ZRangeByScoreN 按分数范围获取有序集合中最多count个成员
+func (rc *RedisClient) ZRangeByScoreN(key string, min, max string, count int64) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opt := &redis.ZRangeBy{
+		Min:   min,
+		Max:   max,
+		Count: count,
+	}
+
+	return rc.client.ZRangeByScore(ctx, key, opt).Result()
+}
+
 // LPush 从列表左侧推入元素
 func (rc *RedisClient) LPush(key string, values ...interface{}) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -227,6 +442,18 @@ func (rc *RedisClient) LPop(key string) (string, error) {
 	return rc.client.LPop(ctx, key).Result()
 }
 
+// LPopIfExists 从列表左侧弹出元素，列表为空或不存在时返回false
+func (rc *RedisClient) LPopIfExists(key string) (string, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	value, err := rc.client.LPop(ctx, key).Result()
+	if err == redis.Nil {
+		return "", false, nil
+	}
+	return value, err == nil, err
+}
+
 // RPop 从列表右侧弹出元素
 func (rc *RedisClient) RPop(key string) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -267,6 +494,22 @@ func (rc *RedisClient) SMembers(key string) ([]string, error) {
 	return rc.client.SMembers(ctx, key).Result()
 }
 
+// SCard 获取集合成员数
+func (rc *RedisClient) SCard(key string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return rc.client.SCard(ctx, key).Result()
+}
+
+// SPopN 随机取出并移除至多count个集合成员
+func (rc *RedisClient) SPopN(key string, count int64) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return rc.client.SPopN(ctx, key, count).Result()
+}
+
 // SIsMember 检查是否为集合成员
 func (rc *RedisClient) SIsMember(key string, member interface{}) (bool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -333,9 +576,40 @@ func (rc *RedisClient) TxPipeline() redis.Pipeliner {
 	return rc.client.TxPipeline()
 }
 
+// errTxValueChanged 乐观事务中被监视键的值与预期不符
+var errTxValueChanged = errors.New("watched value changed")
+
+// TxIfValue 监视key，其值（不存在时为空字符串）等于expected时以MULTI/EXEC原子执行queue中排队的命令，返回是否执行。
+// 值不符或提交前被其他客户端修改时不执行任何命令
+func (rc *RedisClient) TxIfValue(key, expected string, queue func(ctx context.Context, pipe redis.Pipeliner)) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := rc.client.Watch(ctx, func(tx *redis.Tx) error {
+		value, err := tx.Get(ctx, key).Result()
+		if err != nil && err != redis.Nil {
+			return err
+		}
+		if value != expected {
+			return errTxValueChanged
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			queue(ctx, pipe)
+			return nil
+		})
+		return err
+	}, key)
+
+	if errors.Is(err, errTxValueChanged) || errors.Is(err, redis.TxFailedErr) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
 // Close 关闭连接
 func (rc *RedisClient) Close() error {
 	err := rc.client.Close()
-	logrus.Info("Redis client closed")
+	logger.Info("Redis client closed")
 	return err
 }
\ No newline at end of file
@@ -0,0 +1,213 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// 键布局版本的存储：哈希字段为布局名称，值为版本号；迁移期间持有锁，避免多个实例同时迁移
+const (
+	schemaVersionsKey  = "schema:versions"
+	schemaLockKey      = "schema:migration_lock"
+	schemaLockTTL      = 10 * time.Minute
+	schemaWaitInterval = time.Second
+)
+
+// baselineSchemaVersion 引入版本记录前的键布局视为版本1
+const baselineSchemaVersion = 1
+
+// RedisLayout 一类Redis键的布局，修改键名或字段结构时提升Version并注册对应的迁移
+type RedisLayout struct {
+	Name        string
+	Pattern     string // 键名格式，仅用于说明
+	Version     int
+	Description string
+	Migrations  []SchemaMigration
+}
+
+// SchemaMigration 将布局从Version-1升级到Version，须可重复执行（中断后会重新执行）
+type SchemaMigration struct {
+	Version     int
+	Description string
+	Migrate     func(ctx context.Context, client *RedisClient) error
+}
+
+// LayoutStatus 布局的存储版本与当前代码版本
+type LayoutStatus struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+	Stored  int    `json:"stored"`
+	Current int    `json:"current"`
+}
+
+// SchemaMigrator 按布局记录Redis中数据的版本并执行升级
+type SchemaMigrator struct {
+	client  *RedisClient
+	layouts []RedisLayout
+}
+
+// NewSchemaMigrator 创建迁移执行器，布局的迁移须覆盖从版本2到当前版本的每一步
+func NewSchemaMigrator(client *RedisClient, layouts ...RedisLayout) (*SchemaMigrator, error) {
+	names := make(map[string]bool, len(layouts))
+	for i := range layouts {
+		layout := &layouts[i]
+		if names[layout.Name] {
+			return nil, fmt.Errorf("duplicate redis layout %s", layout.Name)
+		}
+		names[layout.Name] = true
+
+		sort.Slice(layout.Migrations, func(a, b int) bool {
+			return layout.Migrations[a].Version < layout.Migrations[b].Version
+		})
+		for step, migration := range layout.Migrations {
+			if migration.Version != baselineSchemaVersion+step+1 {
+				return nil, fmt.Errorf("redis layout %s: missing migration to version %d", layout.Name, baselineSchemaVersion+step+1)
+			}
+		}
+		if layout.Version != baselineSchemaVersion+len(layout.Migrations) {
+			return nil, fmt.Errorf("redis layout %s: version %d has %d migrations", layout.Name, layout.Version, len(layout.Migrations))
+		}
+	}
+
+	sort.Slice(layouts, func(i, j int) bool {
+		return layouts[i].Name < layouts[j].Name
+	})
+	return &SchemaMigrator{client: client, layouts: layouts}, nil
+}
+
+// Status 获取各布局的存储版本，未记录版本的布局按基线版本计
+func (m *SchemaMigrator) Status() ([]*LayoutStatus, error) {
+	stored, err := m.client.HGetAll(schemaVersionsKey)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*LayoutStatus, 0, len(m.layouts))
+	for _, layout := range m.layouts {
+		version := baselineSchemaVersion
+		if value, exists := stored[layout.Name]; exists {
+			if version, err = strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("invalid stored version %q for redis layout %s", value, layout.Name)
+			}
+		}
+		statuses = append(statuses, &LayoutStatus{
+			Name:    layout.Name,
+			Pattern: layout.Pattern,
+			Stored:  version,
+			Current: layout.Version,
+		})
+	}
+	return statuses, nil
+}
+
+// Pending 返回待升级的布局；存储版本高于代码版本（由更新的版本写入）时返回错误，避免旧版本改写新格式的数据
+func (m *SchemaMigrator) Pending() ([]*LayoutStatus, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []*LayoutStatus
+	for _, status := range statuses {
+		if status.Stored > status.Current {
+			return nil, fmt.Errorf("redis layout %s is at version %d, this build supports up to %d", status.Name, status.Stored, status.Current)
+		}
+		if status.Stored < status.Current {
+			pending = append(pending, status)
+		}
+	}
+	return pending, nil
+}
+
+// Migrate 执行待升级布局的迁移，每步成功后记录版本；其他实例正在迁移时等待其完成
+func (m *SchemaMigrator) Migrate(ctx context.Context) error {
+	hostname, _ := os.Hostname()
+	owner := fmt.Sprintf("%s-%d", hostname, os.Getpid())
+
+	for {
+		pending, err := m.Pending()
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			return m.recordBaseline()
+		}
+
+		locked, err := m.client.SetNX(schemaLockKey, owner, schemaLockTTL)
+		if err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		if locked {
+			defer m.client.DeleteIfValue(schemaLockKey, owner)
+			return m.migrate(ctx)
+		}
+
+		logger.Info("Waiting for another instance to finish redis migrations")
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(schemaWaitInterval):
+		}
+	}
+}
+
+func (m *SchemaMigrator) migrate(ctx context.Context) error {
+	// 获取锁前其他实例可能已完成部分迁移，重新读取版本
+	pending, err := m.Pending()
+	if err != nil {
+		return err
+	}
+
+	for _, status := range pending {
+		layout := m.layout(status.Name)
+		for _, migration := range layout.Migrations {
+			if migration.Version <= status.Stored {
+				continue
+			}
+
+			logger.Infof("Migrating redis layout %s to version %d: %s", layout.Name, migration.Version, migration.Description)
+			started := time.Now()
+			if err := migration.Migrate(ctx, m.client); err != nil {
+				return fmt.Errorf("failed to migrate redis layout %s to version %d: %w", layout.Name, migration.Version, err)
+			}
+			if err := m.client.HSet(schemaVersionsKey, layout.Name, migration.Version); err != nil {
+				return fmt.Errorf("failed to record version of redis layout %s: %w", layout.Name, err)
+			}
+			logger.Infof("Migrated redis layout %s to version %d in %v", layout.Name, migration.Version, time.Since(started))
+		}
+	}
+
+	return m.recordBaseline()
+}
+
+// recordBaseline 为未记录版本的布局写入当前版本，此后新增的布局同样从记录的版本开始升级
+func (m *SchemaMigrator) recordBaseline() error {
+	stored, err := m.client.HGetAll(schemaVersionsKey)
+	if err != nil {
+		return err
+	}
+
+	fields := make(map[string]interface{})
+	for _, layout := range m.layouts {
+		if _, exists := stored[layout.Name]; !exists {
+			fields[layout.Name] = layout.Version
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return m.client.HMSet(schemaVersionsKey, fields)
+}
+
+func (m *SchemaMigrator) layout(name string) *RedisLayout {
+	for i := range m.layouts {
+		if m.layouts[i].Name == name {
+			return &m.layouts[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,350 @@
+package devtool
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"web3-data-collector/internal/config"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/sirupsen/logrus"
+)
+
+// 合成交易场景
+const (
+	ScenarioTransfer     = "transfer"
+	ScenarioHighValue    = "high_value"
+	ScenarioSelfTransfer = "self_transfer"
+	ScenarioContractCall = "contract_call"
+)
+
+// mainnetChainIDs 禁止发送合成交易的主网链ID
+var mainnetChainIDs = map[int64]string{
+	1:     "ethereum",
+	10:    "optimism",
+	56:    "bsc",
+	137:   "polygon",
+	8453:  "base",
+	42161: "arbitrum",
+	43114: "avalanche",
+}
+
+// contractCallData contract_call场景发送的调用数据（任意函数选择器），用于触发零值合约调用规则
+var contractCallData = []byte{0xde, 0xad, 0xbe, 0xef}
+
+// TrafficGenerator 在测试网按配置的速率与模式发送合成交易
+type TrafficGenerator struct {
+	client      *ethclient.Client
+	key         *ecdsa.PrivateKey
+	from        common.Address
+	chainID     *big.Int
+	signer      types.Signer
+	nonce       uint64
+	rate        float64
+	pattern     string
+	burstSize   int
+	duration    time.Duration
+	value       *big.Int
+	highValue   *big.Int
+	recipients  []common.Address
+	contract    common.Address
+	scenarios   []string
+	weights     []int
+	totalWeight int
+	sent        map[string]int
+	failed      int
+}
+
+// NewTrafficGenerator 校验配置并连接测试网
+func NewTrafficGenerator(ctx context.Context, cfg config.TrafficConfig, networks map[string]config.NetworkConfig) (*TrafficGenerator, error) {
+	network, exists := networks[cfg.Network]
+	if !exists {
+		return nil, fmt.Errorf("network %s is not configured", cfg.Network)
+	}
+	if name, isMainnet := mainnetChainIDs[network.ChainID]; isMainnet {
+		return nil, fmt.Errorf("refusing to send synthetic traffic to mainnet %s (chain id %d)", name, network.ChainID)
+	}
+	if cfg.Rate <= 0 {
+		return nil, fmt.Errorf("rate must be positive, got %v", cfg.Rate)
+	}
+
+	rawKey := strings.TrimPrefix(os.Getenv(cfg.PrivateKeyEnv), "0x")
+	if rawKey == "" {
+		return nil, fmt.Errorf("dev private key not set in $%s", cfg.PrivateKeyEnv)
+	}
+	key, err := crypto.HexToECDSA(rawKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dev private key: %w", err)
+	}
+
+	generator := &TrafficGenerator{
+		key:       key,
+		from:      crypto.PubkeyToAddress(key.PublicKey),
+		rate:      cfg.Rate,
+		pattern:   cfg.Pattern,
+		burstSize: cfg.BurstSize,
+		sent:      make(map[string]int),
+	}
+
+	if generator.burstSize <= 0 {
+		generator.burstSize = 1
+	}
+	if cfg.Duration != "" {
+		if generator.duration, err = time.ParseDuration(cfg.Duration); err != nil {
+			return nil, fmt.Errorf("invalid duration: %w", err)
+		}
+	}
+	if generator.value, err = parseWei(cfg.ValueWei); err != nil {
+		return nil, fmt.Errorf("invalid value_wei: %w", err)
+	}
+	if generator.highValue, err = parseWei(cfg.HighValueWei); err != nil {
+		return nil, fmt.Errorf("invalid high_value_wei: %w", err)
+	}
+
+	for _, recipient := range cfg.Recipients {
+		if !common.IsHexAddress(recipient) {
+			return nil, fmt.Errorf("invalid recipient address %q", recipient)
+		}
+		generator.recipients = append(generator.recipients, common.HexToAddress(recipient))
+	}
+	if cfg.ContractAddress != "" {
+		if !common.IsHexAddress(cfg.ContractAddress) {
+			return nil, fmt.Errorf("invalid contract address %q", cfg.ContractAddress)
+		}
+		generator.contract = common.HexToAddress(cfg.ContractAddress)
+	}
+
+	if err := generator.setScenarios(cfg.Scenarios); err != nil {
+		return nil, err
+	}
+
+	generator.client, err = ethclient.DialContext(ctx, network.RPCURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", cfg.Network, err)
+	}
+
+	// 以节点返回的链ID为准，防止rpc_url误指向主网
+	if generator.chainID, err = generator.client.ChainID(ctx); err != nil {
+		generator.client.Close()
+		return nil, fmt.Errorf("failed to get chain id: %w", err)
+	}
+	if name, isMainnet := mainnetChainIDs[generator.chainID.Int64()]; isMainnet {
+		generator.client.Close()
+		return nil, fmt.Errorf("refusing to send synthetic traffic: rpc_url points to mainnet %s", name)
+	}
+	generator.signer = types.LatestSignerForChainID(generator.chainID)
+
+	if generator.nonce, err = generator.client.PendingNonceAt(ctx, generator.from); err != nil {
+		generator.client.Close()
+		return nil, fmt.Errorf("failed to get nonce: %w", err)
+	}
+
+	return generator, nil
+}
+
+// setScenarios 校验场景权重，未配置时只发送普通转账
+func (tg *TrafficGenerator) setScenarios(weights map[string]int) error {
+	if len(weights) == 0 {
+		weights = map[string]int{ScenarioTransfer: 1}
+	}
+
+	for scenario, weight := range weights {
+		switch scenario {
+		case ScenarioTransfer, ScenarioSelfTransfer:
+		case ScenarioHighValue:
+			if tg.highValue.Sign() == 0 && weight > 0 {
+				return fmt.Errorf("scenario %s requires high_value_wei", scenario)
+			}
+		case ScenarioContractCall:
+			if tg.contract == (common.Address{}) && weight > 0 {
+				return fmt.Errorf("scenario %s requires contract_address", scenario)
+			}
+		default:
+			return fmt.Errorf("unknown scenario %q", scenario)
+		}
+		if weight <= 0 {
+			continue
+		}
+		tg.scenarios = append(tg.scenarios, scenario)
+	}
+	if len(tg.scenarios) == 0 {
+		return fmt.Errorf("no scenario has a positive weight")
+	}
+
+	// 固定顺序使相同种子下的场景序列可复现
+	sort.Strings(tg.scenarios)
+	for _, scenario := range tg.scenarios {
+		tg.weights = append(tg.weights, weights[scenario])
+		tg.totalWeight += weights[scenario]
+	}
+
+	return nil
+}
+
+// Run 按配置的模式发送交易，直到时长结束或ctx取消
+func (tg *TrafficGenerator) Run(ctx context.Context) error {
+	defer tg.client.Close()
+
+	if tg.duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, tg.duration)
+		defer cancel()
+	}
+
+	logrus.Infof("Sending synthetic traffic from %s on chain %s: %.2f tx/s, pattern %s, scenarios %v",
+		tg.from.Hex(), tg.chainID, tg.rate, tg.pattern, tg.scenarios)
+
+	startedAt := time.Now()
+	for {
+		batch := 1
+		if tg.pattern == "burst" {
+			batch = tg.burstSize
+		}
+
+		for i := 0; i < batch; i++ {
+			tg.send(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			tg.logSummary(time.Since(startedAt))
+			return nil
+		case <-time.After(tg.interval(batch, time.Since(startedAt))):
+		}
+	}
+}
+
+// interval 计算发送下一批交易前的等待时间
+func (tg *TrafficGenerator) interval(batch int, elapsed time.Duration) time.Duration {
+	rate := tg.rate
+
+	// ramp模式在运行时长内从10%线性增加到目标速率
+	if tg.pattern == "ramp" && tg.duration > 0 {
+		progress := float64(elapsed) / float64(tg.duration)
+		if progress > 1 {
+			progress = 1
+		}
+		rate = tg.rate * (0.1 + 0.9*progress)
+	}
+
+	return time.Duration(float64(batch) / rate * float64(time.Second))
+}
+
+// send 随机选择场景并发送一笔交易
+func (tg *TrafficGenerator) send(ctx context.Context) {
+	scenario := tg.pickScenario()
+
+	tx, err := tg.buildTransaction(ctx, scenario)
+	if err == nil {
+		err = tg.client.SendTransaction(ctx, tx)
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			tg.failed++
+			logrus.Warnf("Failed to send %s transaction: %v", scenario, err)
+			tg.resyncNonce(ctx)
+		}
+		return
+	}
+
+	tg.nonce++
+	tg.sent[scenario]++
+	logrus.Debugf("Sent %s transaction %s", scenario, tx.Hash().Hex())
+}
+
+// pickScenario 按权重随机选择场景
+func (tg *TrafficGenerator) pickScenario() string {
+	n := rand.Intn(tg.totalWeight)
+	for i, weight := range tg.weights {
+		if n < weight {
+			return tg.scenarios[i]
+		}
+		n -= weight
+	}
+	return tg.scenarios[len(tg.scenarios)-1]
+}
+
+// buildTransaction 构建并签名场景对应的交易
+func (tg *TrafficGenerator) buildTransaction(ctx context.Context, scenario string) (*types.Transaction, error) {
+	to := tg.from
+	value := tg.value
+	var data []byte
+
+	switch scenario {
+	case ScenarioTransfer:
+		if len(tg.recipients) > 0 {
+			to = tg.recipients[rand.Intn(len(tg.recipients))]
+		}
+	case ScenarioHighValue:
+		if len(tg.recipients) > 0 {
+			to = tg.recipients[rand.Intn(len(tg.recipients))]
+		}
+		value = tg.highValue
+	case ScenarioContractCall:
+		to = tg.contract
+		value = big.NewInt(0)
+		data = contractCallData
+	}
+
+	gasPrice, err := tg.client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gas price: %w", err)
+	}
+
+	gas := uint64(21000)
+	if len(data) > 0 {
+		gas = 100000
+	}
+
+	tx := types.NewTx(&types.LegacyTx{
+		Nonce:    tg.nonce,
+		To:       &to,
+		Value:    value,
+		Gas:      gas,
+		GasPrice: gasPrice,
+		Data:     data,
+	})
+
+	return types.SignTx(tx, tg.signer, tg.key)
+}
+
+// resyncNonce 发送失败后从节点重新获取nonce
+func (tg *TrafficGenerator) resyncNonce(ctx context.Context) {
+	nonce, err := tg.client.PendingNonceAt(ctx, tg.from)
+	if err != nil {
+		logrus.Warnf("Failed to resync nonce: %v", err)
+		return
+	}
+	tg.nonce = nonce
+}
+
+// logSummary 输出发送统计
+func (tg *TrafficGenerator) logSummary(elapsed time.Duration) {
+	total := 0
+	for _, count := range tg.sent {
+		total += count
+	}
+	logrus.Infof("Synthetic traffic finished after %v: %d sent %v, %d failed", elapsed.Round(time.Second), total, tg.sent, tg.failed)
+}
+
+// parseWei 解析wei金额，为空时为0
+func parseWei(value string) (*big.Int, error) {
+	if value == "" {
+		return big.NewInt(0), nil
+	}
+	amount, ok := new(big.Int).SetString(value, 10)
+	if !ok || amount.Sign() < 0 {
+		return nil, fmt.Errorf("invalid amount %q", value)
+	}
+	return amount, nil
+}
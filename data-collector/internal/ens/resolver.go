@@ -0,0 +1,282 @@
+package ens
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultRegistryAddress 以太坊主网ENS注册表
+	defaultRegistryAddress = "0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e"
+	defaultCacheTTL        = 24 * time.Hour
+	defaultRequestTimeout  = 5 * time.Second
+	// 查询失败后的短期缓存，避免节点异常时反复请求
+	failureCacheTTL = time.Minute
+	// maxLocalEntries 进程内缓存条目上限，超出后清空重建
+	maxLocalEntries = 100000
+)
+
+// 合约方法选择器
+var (
+	resolverSelector = crypto.Keccak256([]byte("resolver(bytes32)"))[:4]
+	addrSelector     = crypto.Keccak256([]byte("addr(bytes32)"))[:4]
+	nameSelector     = crypto.Keccak256([]byte("name(bytes32)"))[:4]
+)
+
+// cachedName 进程内缓存的解析结果，value为空表示没有记录
+type cachedName struct {
+	value     string
+	expiresAt time.Time
+}
+
+// Resolver ENS正向及反向解析（Redis缓存）
+type Resolver struct {
+	client   *ethclient.Client
+	registry common.Address
+	cacheTTL time.Duration
+	timeout  time.Duration
+	redis    *database.RedisClient
+	cache    map[string]cachedName
+	mu       sync.RWMutex
+}
+
+// NewResolver 创建ENS解析器，未启用时返回nil
+func NewResolver(cfg config.ENSConfig, redisClient *database.RedisClient) (*Resolver, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	client, err := ethclient.Dial(cfg.RPCURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to ENS RPC: %w", err)
+	}
+
+	resolver := &Resolver{
+		client:   client,
+		registry: common.HexToAddress(defaultRegistryAddress),
+		cacheTTL: defaultCacheTTL,
+		timeout:  defaultRequestTimeout,
+		redis:    redisClient,
+		cache:    make(map[string]cachedName),
+	}
+
+	if cfg.RegistryAddress != "" {
+		resolver.registry = common.HexToAddress(cfg.RegistryAddress)
+	}
+	if cfg.CacheTTL != "" {
+		if ttl, err := time.ParseDuration(cfg.CacheTTL); err == nil {
+			resolver.cacheTTL = ttl
+		} else {
+			logrus.Warnf("Invalid ens cache_ttl %q, using %v", cfg.CacheTTL, resolver.cacheTTL)
+		}
+	}
+	if cfg.RequestTimeout != "" {
+		if timeout, err := time.ParseDuration(cfg.RequestTimeout); err == nil {
+			resolver.timeout = timeout
+		} else {
+			logrus.Warnf("Invalid ens request_timeout %q, using %v", cfg.RequestTimeout, resolver.timeout)
+		}
+	}
+
+	logrus.Infof("ENS resolver enabled (registry: %s, cache ttl: %v)", resolver.registry.Hex(), resolver.cacheTTL)
+	return resolver, nil
+}
+
+// Close 关闭RPC连接
+func (r *Resolver) Close() {
+	r.client.Close()
+}
+
+// LookupAddress 反向解析地址的主ENS名称，只返回正向解析指回该地址的名称
+func (r *Resolver) LookupAddress(address string) (string, bool) {
+	if !common.IsHexAddress(address) {
+		return "", false
+	}
+	address = strings.ToLower(address)
+
+	name := r.cached("reverse:"+address, func(ctx context.Context) (string, error) {
+		return r.reverse(ctx, common.HexToAddress(address))
+	})
+	return name, name != ""
+}
+
+// ResolveName 正向解析ENS名称，返回校验和格式的地址
+func (r *Resolver) ResolveName(name string) (string, bool) {
+	name = Normalize(name)
+	if name == "" {
+		return "", false
+	}
+
+	address := r.cached("forward:"+name, func(ctx context.Context) (string, error) {
+		resolved, err := r.forward(ctx, name)
+		if err != nil || resolved == (common.Address{}) {
+			return "", err
+		}
+		return resolved.Hex(), nil
+	})
+	return address, address != ""
+}
+
+// cached 依次查询进程内缓存、Redis缓存和链上合约，没有记录的结果同样缓存
+func (r *Resolver) cached(key string, fetch func(ctx context.Context) (string, error)) string {
+	now := time.Now()
+
+	r.mu.RLock()
+	entry, exists := r.cache[key]
+	r.mu.RUnlock()
+	if exists && now.Before(entry.expiresAt) {
+		return entry.value
+	}
+
+	redisKey := "ens:" + key
+	if value, err := r.redis.Get(redisKey); err == nil {
+		r.store(key, value, r.cacheTTL)
+		return value
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	value, err := fetch(ctx)
+	if err != nil {
+		logrus.Debugf("Failed to resolve ENS %s: %v", key, err)
+		r.store(key, "", failureCacheTTL)
+		return ""
+	}
+
+	if err := r.redis.Set(redisKey, value, r.cacheTTL); err != nil {
+		logrus.Warnf("Failed to cache ENS %s: %v", key, err)
+	}
+	r.store(key, value, r.cacheTTL)
+	return value
+}
+
+// store 写入进程内缓存
+func (r *Resolver) store(key, value string, ttl time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.cache) >= maxLocalEntries {
+		r.cache = make(map[string]cachedName)
+	}
+	r.cache[key] = cachedName{value: value, expiresAt: time.Now().Add(ttl)}
+}
+
+// forward 查询名称的解析器合约并读取addr记录
+func (r *Resolver) forward(ctx context.Context, name string) (common.Address, error) {
+	node := NameHash(name)
+	resolver, err := r.resolverOf(ctx, node)
+	if err != nil || resolver == (common.Address{}) {
+		return common.Address{}, err
+	}
+
+	result, err := r.call(ctx, resolver, addrSelector, node)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(result) < 32 {
+		return common.Address{}, nil
+	}
+	return common.BytesToAddress(result[12:32]), nil
+}
+
+// reverse 读取 <地址>.addr.reverse 的name记录，并通过正向解析校验
+func (r *Resolver) reverse(ctx context.Context, address common.Address) (string, error) {
+	node := NameHash(strings.ToLower(address.Hex()[2:]) + ".addr.reverse")
+	resolver, err := r.resolverOf(ctx, node)
+	if err != nil || resolver == (common.Address{}) {
+		return "", err
+	}
+
+	result, err := r.call(ctx, resolver, nameSelector, node)
+	if err != nil {
+		return "", err
+	}
+	name := Normalize(decodeString(result))
+	if name == "" {
+		return "", nil
+	}
+
+	// 反向记录可由地址所有者任意设置，正向解析不一致时视为没有名称
+	resolved, err := r.forward(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if resolved != address {
+		return "", nil
+	}
+	return name, nil
+}
+
+// resolverOf 从注册表查询节点的解析器合约地址
+func (r *Resolver) resolverOf(ctx context.Context, node common.Hash) (common.Address, error) {
+	result, err := r.call(ctx, r.registry, resolverSelector, node)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(result) < 32 {
+		return common.Address{}, nil
+	}
+	return common.BytesToAddress(result[12:32]), nil
+}
+
+// call 以节点为唯一参数调用合约只读方法
+func (r *Resolver) call(ctx context.Context, to common.Address, selector []byte, node common.Hash) ([]byte, error) {
+	data := make([]byte, 0, len(selector)+common.HashLength)
+	data = append(data, selector...)
+	data = append(data, node.Bytes()...)
+	return r.client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+}
+
+// NameHash 计算ENS名称的namehash（EIP-137）
+func NameHash(name string) common.Hash {
+	var node common.Hash
+	if name == "" {
+		return node
+	}
+
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		node = crypto.Keccak256Hash(node.Bytes(), crypto.Keccak256([]byte(labels[i])))
+	}
+	return node
+}
+
+// Normalize 规范化名称：去除首尾空白及末尾的点并转为小写，不做完整的UTS-46处理
+func Normalize(name string) string {
+	name = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+	if name == "" || strings.Contains(name, "..") || strings.HasPrefix(name, ".") {
+		return ""
+	}
+	return name
+}
+
+// decodeString 解码ABI编码的单个string返回值
+func decodeString(data []byte) string {
+	if len(data) < 64 {
+		return ""
+	}
+	offset := binary.BigEndian.Uint64(data[24:32])
+	if offset > uint64(len(data))-32 {
+		return ""
+	}
+	length := binary.BigEndian.Uint64(data[offset+24 : offset+32])
+	start := offset + 32
+	if length > uint64(len(data))-start {
+		return ""
+	}
+	return string(data[start : start+length])
+}
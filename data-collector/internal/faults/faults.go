@@ -0,0 +1,135 @@
+// Package faults 定义按类别区分的错误类型，错误携带网络及区块上下文，用于日志字段及按类别计数
+package faults
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 错误类别，用作指标标签及错误预算的键
+const (
+	CategoryRPC    = "rpc"    // 节点RPC调用失败
+	CategoryDecode = "decode" // 区块、交易、日志或重放数据无法解码
+	CategorySink   = "sink"   // 输出端发布失败
+	CategoryOther  = "other"  // 未分类的错误
+)
+
+// Categories 全部错误类别
+var Categories = []string{CategoryRPC, CategoryDecode, CategorySink, CategoryOther}
+
+// RPCError 节点RPC调用失败
+type RPCError struct {
+	Network string
+	Method  string
+	Block   uint64 // 与区块无关的调用为0
+	Err     error
+}
+
+func (e *RPCError) Error() string {
+	if e.Block > 0 {
+		return fmt.Sprintf("rpc %s failed for %s at block %d: %v", e.Method, e.Network, e.Block, e.Err)
+	}
+	return fmt.Sprintf("rpc %s failed for %s: %v", e.Method, e.Network, e.Err)
+}
+
+func (e *RPCError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError 区块、交易、日志或重放数据无法解码
+type DecodeError struct {
+	Network string
+	Kind    string // block / transaction / log / payload
+	Block   uint64
+	Ref     string // 交易哈希、日志位置等，可为空
+	Err     error
+}
+
+func (e *DecodeError) Error() string {
+	target := e.Kind
+	if e.Ref != "" {
+		target += " " + e.Ref
+	}
+	if e.Block > 0 {
+		return fmt.Sprintf("failed to decode %s of block %d for %s: %v", target, e.Block, e.Network, e.Err)
+	}
+	return fmt.Sprintf("failed to decode %s for %s: %v", target, e.Network, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// SinkError 输出端重试后仍发布失败
+type SinkError struct {
+	Sink    string
+	Kind    string
+	Network string
+	Block   uint64
+	Err     error
+}
+
+func (e *SinkError) Error() string {
+	return fmt.Sprintf("sink %s failed to publish %s for %s: %v", e.Sink, e.Kind, e.Network, e.Err)
+}
+
+func (e *SinkError) Unwrap() error {
+	return e.Err
+}
+
+// Context 错误的类别及网络、区块上下文
+type Context struct {
+	Category string
+	Network  string
+	Block    uint64
+}
+
+// Describe 获取错误的类别及上下文，错误链中没有分类错误时为other，
+// 错误本身未携带的网络及区块号使用调用方提供的值
+func Describe(err error, network string, block uint64) Context {
+	ctx := Context{Category: CategoryOther}
+
+	var decodeErr *DecodeError
+	var rpcErr *RPCError
+	var sinkErr *SinkError
+	switch {
+	case errors.As(err, &decodeErr):
+		ctx = Context{Category: CategoryDecode, Network: decodeErr.Network, Block: decodeErr.Block}
+	case errors.As(err, &rpcErr):
+		ctx = Context{Category: CategoryRPC, Network: rpcErr.Network, Block: rpcErr.Block}
+	case errors.As(err, &sinkErr):
+		ctx = Context{Category: CategorySink, Network: sinkErr.Network, Block: sinkErr.Block}
+	}
+
+	if ctx.Network == "" {
+		ctx.Network = network
+	}
+	if ctx.Block == 0 {
+		ctx.Block = block
+	}
+	return ctx
+}
+
+// Fields 日志字段
+func (c Context) Fields() logrus.Fields {
+	fields := logrus.Fields{"category": c.Category}
+	if c.Network != "" {
+		fields["network"] = c.Network
+	}
+	if c.Block > 0 {
+		fields["block"] = c.Block
+	}
+	return fields
+}
+
+// Log 以类别及上下文作为字段输出错误日志
+func Log(err error, network string, block uint64, message string) {
+	logrus.WithFields(Describe(err, network, block).Fields()).Errorf("%s: %v", message, err)
+}
+
+// Warn 同Log，用于跳过部分数据后继续处理的降级路径，以警告级别输出
+func Warn(err error, network string, block uint64, message string) {
+	logrus.WithFields(Describe(err, network, block).Fields()).Warnf("%s: %v", message, err)
+}
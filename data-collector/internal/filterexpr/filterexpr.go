@@ -0,0 +1,117 @@
+// Package filterexpr 过滤规则表达式（expr语言）的编译与求值，配置校验与过滤引擎使用同一环境定义
+package filterexpr
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"web3-data-collector/internal/models"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+)
+
+// Env 表达式可访问的变量，如 tx.value > 1e18 && tx.network == "ethereum" && tx.to in watchlist
+type Env struct {
+	Tx        Tx              `expr:"tx"`
+	Watchlist map[string]bool `expr:"watchlist"` // 交易所在网络的关注地址
+}
+
+// Tx 表达式中的交易字段。金额为浮点数（wei或代币最小单位），EVM地址为小写
+type Tx struct {
+	Hash            string  `expr:"hash"`
+	Network         string  `expr:"network"`
+	Chain           string  `expr:"chain"` // 为空表示EVM链
+	From            string  `expr:"from"`
+	To              string  `expr:"to"`
+	Value           float64 `expr:"value"`
+	Gas             uint64  `expr:"gas"`
+	GasPrice        float64 `expr:"gas_price"`
+	Nonce           uint64  `expr:"nonce"`
+	BlockNumber     uint64  `expr:"block_number"`
+	Status          uint64  `expr:"status"`
+	HasReceipt      bool    `expr:"has_receipt"` // 为false时status无意义
+	Type            uint8   `expr:"type"`
+	IsContractCall  bool    `expr:"is_contract_call"`
+	IsTokenTransfer bool    `expr:"is_token_transfer"`
+	TokenSymbol     string  `expr:"token_symbol"`
+	TokenAmount     float64 `expr:"token_amount"`
+	MethodID        string  `expr:"method_id"` // 调用数据的前4字节，如 0xa9059cbb
+	Method          string  `expr:"method"`    // 解析出的函数名，如 transferFrom，需启用method_signatures
+	InputSize       int     `expr:"input_size"`
+}
+
+// Compile 编译表达式并检查字段名、类型及返回值是否为布尔
+func Compile(expression string) (*vm.Program, error) {
+	if strings.TrimSpace(expression) == "" {
+		return nil, fmt.Errorf("expression is empty")
+	}
+	return expr.Compile(expression, expr.Env(Env{}), expr.AsBool())
+}
+
+// Match 对交易求值
+func Match(program *vm.Program, env *Env) (bool, error) {
+	out, err := expr.Run(program, env)
+	if err != nil {
+		return false, err
+	}
+	return out.(bool), nil
+}
+
+// NewEnv 由交易构造求值环境，watchlist为交易所在网络的关注地址，可为nil
+func NewEnv(tx *models.Transaction, watchlist map[string]bool) *Env {
+	env := &Env{
+		Tx: Tx{
+			Hash:            tx.Hash,
+			Network:         tx.Network,
+			Chain:           tx.Chain,
+			From:            normalizeAddress(tx.FromAddress),
+			To:              normalizeAddress(tx.ToAddress),
+			Value:           toFloat(tx.Value),
+			Gas:             tx.Gas,
+			GasPrice:        toFloat(tx.GasPrice),
+			Nonce:           tx.Nonce,
+			BlockNumber:     tx.BlockNumber,
+			Status:          tx.Status,
+			HasReceipt:      tx.HasReceipt,
+			Type:            tx.TransactionType,
+			IsContractCall:  tx.IsContractCall,
+			IsTokenTransfer: tx.IsTokenTransfer,
+			TokenSymbol:     tx.TokenSymbol,
+			TokenAmount:     toFloat(tx.TokenAmount),
+			Method:          tx.MethodName,
+			InputSize:       inputSize(tx.InputData),
+		},
+		Watchlist: watchlist,
+	}
+	if len(tx.InputData) >= 10 {
+		env.Tx.MethodID = strings.ToLower(tx.InputData[:10])
+	}
+	if env.Watchlist == nil {
+		env.Watchlist = map[string]bool{}
+	}
+	return env
+}
+
+func toFloat(value *big.Int) float64 {
+	if value == nil {
+		return 0
+	}
+	f, _ := new(big.Float).SetInt(value).Float64()
+	return f
+}
+
+// normalizeAddress EVM地址转为小写，Solana等base58地址区分大小写，保持原样
+func normalizeAddress(address string) string {
+	if strings.HasPrefix(address, "0x") || strings.HasPrefix(address, "0X") {
+		return strings.ToLower(address)
+	}
+	return address
+}
+
+// inputSize 调用数据的字节数
+func inputSize(inputData string) int {
+	data := strings.TrimPrefix(inputData, "0x")
+	return len(data) / 2
+}
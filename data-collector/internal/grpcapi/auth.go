@@ -0,0 +1,54 @@
+package grpcapi
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// authorizeUnary 一元调用的认证拦截器
+func (s *Server) authorizeUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := s.authorize(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// authorizeStream 流式调用的认证拦截器
+func (s *Server) authorizeStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := s.authorize(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// authorize 按元数据x-api-key或authorization（Bearer JWT）认证调用方，与HTTP API的read角色相同，
+// 任一有效凭证即可访问；认证器为nil（未启用认证）时不做检查
+func (s *Server) authorize(ctx context.Context, method string) error {
+	if s.auth == nil {
+		return nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	if _, err := s.auth.Authenticate(firstValue(md, "x-api-key"), firstValue(md, "authorization")); err != nil {
+		remote := "unknown"
+		if p, ok := peer.FromContext(ctx); ok {
+			remote = p.Addr.String()
+		}
+		logger.Warnf("Rejected %s from %s: %v", method, remote, err)
+		return status.Error(codes.Unauthenticated, "unauthorized")
+	}
+	return nil
+}
+
+// firstValue 获取元数据键的第一个值
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,376 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: collector.proto
+
+package collectorpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	CollectorService_GetNetworkStats_FullMethodName       = "/web3.collector.v1.CollectorService/GetNetworkStats"
+	CollectorService_ListNetworkStats_FullMethodName      = "/web3.collector.v1.CollectorService/ListNetworkStats"
+	CollectorService_GetLatestBlock_FullMethodName        = "/web3.collector.v1.CollectorService/GetLatestBlock"
+	CollectorService_SubscribeBlocks_FullMethodName       = "/web3.collector.v1.CollectorService/SubscribeBlocks"
+	CollectorService_SubscribeTransactions_FullMethodName = "/web3.collector.v1.CollectorService/SubscribeTransactions"
+	CollectorService_SubscribeAlerts_FullMethodName       = "/web3.collector.v1.CollectorService/SubscribeAlerts"
+)
+
+// CollectorServiceClient is the client API for CollectorService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CollectorServiceClient interface {
+	GetNetworkStats(ctx context.Context, in *GetNetworkStatsRequest, opts ...grpc.CallOption) (*NetworkStats, error)
+	ListNetworkStats(ctx context.Context, in *ListNetworkStatsRequest, opts ...grpc.CallOption) (*ListNetworkStatsResponse, error)
+	GetLatestBlock(ctx context.Context, in *GetLatestBlockRequest, opts ...grpc.CallOption) (*Block, error)
+	SubscribeBlocks(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (CollectorService_SubscribeBlocksClient, error)
+	SubscribeTransactions(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (CollectorService_SubscribeTransactionsClient, error)
+	SubscribeAlerts(ctx context.Context, in *SubscribeAlertsRequest, opts ...grpc.CallOption) (CollectorService_SubscribeAlertsClient, error)
+}
+
+type collectorServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCollectorServiceClient(cc grpc.ClientConnInterface) CollectorServiceClient {
+	return &collectorServiceClient{cc}
+}
+
+func (c *collectorServiceClient) GetNetworkStats(ctx context.Context, in *GetNetworkStatsRequest, opts ...grpc.CallOption) (*NetworkStats, error) {
+	out := new(NetworkStats)
+	err := c.cc.Invoke(ctx, CollectorService_GetNetworkStats_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collectorServiceClient) ListNetworkStats(ctx context.Context, in *ListNetworkStatsRequest, opts ...grpc.CallOption) (*ListNetworkStatsResponse, error) {
+	out := new(ListNetworkStatsResponse)
+	err := c.cc.Invoke(ctx, CollectorService_ListNetworkStats_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collectorServiceClient) GetLatestBlock(ctx context.Context, in *GetLatestBlockRequest, opts ...grpc.CallOption) (*Block, error) {
+	out := new(Block)
+	err := c.cc.Invoke(ctx, CollectorService_GetLatestBlock_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collectorServiceClient) SubscribeBlocks(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (CollectorService_SubscribeBlocksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &CollectorService_ServiceDesc.Streams[0], CollectorService_SubscribeBlocks_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &collectorServiceSubscribeBlocksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CollectorService_SubscribeBlocksClient interface {
+	Recv() (*Block, error)
+	grpc.ClientStream
+}
+
+type collectorServiceSubscribeBlocksClient struct {
+	grpc.ClientStream
+}
+
+func (x *collectorServiceSubscribeBlocksClient) Recv() (*Block, error) {
+	m := new(Block)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *collectorServiceClient) SubscribeTransactions(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (CollectorService_SubscribeTransactionsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &CollectorService_ServiceDesc.Streams[1], CollectorService_SubscribeTransactions_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &collectorServiceSubscribeTransactionsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CollectorService_SubscribeTransactionsClient interface {
+	Recv() (*Transaction, error)
+	grpc.ClientStream
+}
+
+type collectorServiceSubscribeTransactionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *collectorServiceSubscribeTransactionsClient) Recv() (*Transaction, error) {
+	m := new(Transaction)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *collectorServiceClient) SubscribeAlerts(ctx context.Context, in *SubscribeAlertsRequest, opts ...grpc.CallOption) (CollectorService_SubscribeAlertsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &CollectorService_ServiceDesc.Streams[2], CollectorService_SubscribeAlerts_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &collectorServiceSubscribeAlertsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CollectorService_SubscribeAlertsClient interface {
+	Recv() (*Alert, error)
+	grpc.ClientStream
+}
+
+type collectorServiceSubscribeAlertsClient struct {
+	grpc.ClientStream
+}
+
+func (x *collectorServiceSubscribeAlertsClient) Recv() (*Alert, error) {
+	m := new(Alert)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// CollectorServiceServer is the server API for CollectorService service.
+// All implementations must embed UnimplementedCollectorServiceServer
+// for forward compatibility
+type CollectorServiceServer interface {
+	GetNetworkStats(context.Context, *GetNetworkStatsRequest) (*NetworkStats, error)
+	ListNetworkStats(context.Context, *ListNetworkStatsRequest) (*ListNetworkStatsResponse, error)
+	GetLatestBlock(context.Context, *GetLatestBlockRequest) (*Block, error)
+	SubscribeBlocks(*SubscribeRequest, CollectorService_SubscribeBlocksServer) error
+	SubscribeTransactions(*SubscribeRequest, CollectorService_SubscribeTransactionsServer) error
+	SubscribeAlerts(*SubscribeAlertsRequest, CollectorService_SubscribeAlertsServer) error
+	mustEmbedUnimplementedCollectorServiceServer()
+}
+
+// UnimplementedCollectorServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedCollectorServiceServer struct {
+}
+
+func (UnimplementedCollectorServiceServer) GetNetworkStats(context.Context, *GetNetworkStatsRequest) (*NetworkStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNetworkStats not implemented")
+}
+func (UnimplementedCollectorServiceServer) ListNetworkStats(context.Context, *ListNetworkStatsRequest) (*ListNetworkStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListNetworkStats not implemented")
+}
+func (UnimplementedCollectorServiceServer) GetLatestBlock(context.Context, *GetLatestBlockRequest) (*Block, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLatestBlock not implemented")
+}
+func (UnimplementedCollectorServiceServer) SubscribeBlocks(*SubscribeRequest, CollectorService_SubscribeBlocksServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeBlocks not implemented")
+}
+func (UnimplementedCollectorServiceServer) SubscribeTransactions(*SubscribeRequest, CollectorService_SubscribeTransactionsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeTransactions not implemented")
+}
+func (UnimplementedCollectorServiceServer) SubscribeAlerts(*SubscribeAlertsRequest, CollectorService_SubscribeAlertsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeAlerts not implemented")
+}
+func (UnimplementedCollectorServiceServer) mustEmbedUnimplementedCollectorServiceServer() {}
+
+// UnsafeCollectorServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CollectorServiceServer will
+// result in compilation errors.
+type UnsafeCollectorServiceServer interface {
+	mustEmbedUnimplementedCollectorServiceServer()
+}
+
+func RegisterCollectorServiceServer(s grpc.ServiceRegistrar, srv CollectorServiceServer) {
+	s.RegisterService(&CollectorService_ServiceDesc, srv)
+}
+
+func _CollectorService_GetNetworkStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNetworkStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectorServiceServer).GetNetworkStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectorService_GetNetworkStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectorServiceServer).GetNetworkStats(ctx, req.(*GetNetworkStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollectorService_ListNetworkStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListNetworkStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectorServiceServer).ListNetworkStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectorService_ListNetworkStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectorServiceServer).ListNetworkStats(ctx, req.(*ListNetworkStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollectorService_GetLatestBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLatestBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectorServiceServer).GetLatestBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectorService_GetLatestBlock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectorServiceServer).GetLatestBlock(ctx, req.(*GetLatestBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollectorService_SubscribeBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CollectorServiceServer).SubscribeBlocks(m, &collectorServiceSubscribeBlocksServer{stream})
+}
+
+type CollectorService_SubscribeBlocksServer interface {
+	Send(*Block) error
+	grpc.ServerStream
+}
+
+type collectorServiceSubscribeBlocksServer struct {
+	grpc.ServerStream
+}
+
+func (x *collectorServiceSubscribeBlocksServer) Send(m *Block) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _CollectorService_SubscribeTransactions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CollectorServiceServer).SubscribeTransactions(m, &collectorServiceSubscribeTransactionsServer{stream})
+}
+
+type CollectorService_SubscribeTransactionsServer interface {
+	Send(*Transaction) error
+	grpc.ServerStream
+}
+
+type collectorServiceSubscribeTransactionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *collectorServiceSubscribeTransactionsServer) Send(m *Transaction) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _CollectorService_SubscribeAlerts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeAlertsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CollectorServiceServer).SubscribeAlerts(m, &collectorServiceSubscribeAlertsServer{stream})
+}
+
+type CollectorService_SubscribeAlertsServer interface {
+	Send(*Alert) error
+	grpc.ServerStream
+}
+
+type collectorServiceSubscribeAlertsServer struct {
+	grpc.ServerStream
+}
+
+func (x *collectorServiceSubscribeAlertsServer) Send(m *Alert) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// CollectorService_ServiceDesc is the grpc.ServiceDesc for CollectorService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CollectorService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "web3.collector.v1.CollectorService",
+	HandlerType: (*CollectorServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetNetworkStats",
+			Handler:    _CollectorService_GetNetworkStats_Handler,
+		},
+		{
+			MethodName: "ListNetworkStats",
+			Handler:    _CollectorService_ListNetworkStats_Handler,
+		},
+		{
+			MethodName: "GetLatestBlock",
+			Handler:    _CollectorService_GetLatestBlock_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeBlocks",
+			Handler:       _CollectorService_SubscribeBlocks_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribeTransactions",
+			Handler:       _CollectorService_SubscribeTransactions_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribeAlerts",
+			Handler:       _CollectorService_SubscribeAlerts_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "collector.proto",
+}
@@ -0,0 +1,106 @@
+package collectorpb
+
+import (
+	_ "embed"
+	"encoding/json"
+	"math/big"
+
+	"web3-data-collector/internal/models"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ProtoSchema collector.proto源文件，用于向Schema Registry注册
+//
+//go:embed collector.proto
+var ProtoSchema string
+
+// FromBlock 转换区块为protobuf消息
+func FromBlock(block *models.Block) *Block {
+	pb := &Block{
+		Number:        block.Number,
+		Hash:          block.Hash,
+		ParentHash:    block.ParentHash,
+		Timestamp:     timestamppb.New(block.Timestamp),
+		Difficulty:    bigIntString(block.Difficulty),
+		GasLimit:      block.GasLimit,
+		GasUsed:       block.GasUsed,
+		Miner:         block.Miner,
+		Network:       block.Network,
+		TxCount:       int32(block.TxCount),
+		Size:          block.Size,
+		BaseFeePerGas: bigIntString(block.BaseFeePerGas),
+		Transactions:  make([]*Transaction, 0, len(block.Transactions)),
+	}
+
+	for i := range block.Transactions {
+		pb.Transactions = append(pb.Transactions, FromTransaction(&block.Transactions[i]))
+	}
+
+	return pb
+}
+
+// FromTransaction 转换交易为protobuf消息
+func FromTransaction(tx *models.Transaction) *Transaction {
+	return &Transaction{
+		Hash:             tx.Hash,
+		BlockNumber:      tx.BlockNumber,
+		BlockHash:        tx.BlockHash,
+		TransactionIndex: uint32(tx.TransactionIndex),
+		FromAddress:      tx.FromAddress,
+		ToAddress:        tx.ToAddress,
+		Value:            bigIntString(tx.Value),
+		Gas:              tx.Gas,
+		GasPrice:         bigIntString(tx.GasPrice),
+		GasUsed:          tx.GasUsed,
+		Nonce:            tx.Nonce,
+		InputData:        tx.InputData,
+		Timestamp:        timestamppb.New(tx.Timestamp),
+		Network:          tx.Network,
+		Status:           tx.Status,
+		ContractAddress:  tx.ContractAddress,
+		IsContractCall:   tx.IsContractCall,
+		IsTokenTransfer:  tx.IsTokenTransfer,
+		TransactionType:  uint32(tx.TransactionType),
+	}
+}
+
+// FromAlert 转换告警为protobuf消息，元数据值以JSON编码
+func FromAlert(alert *models.RiskAlert) *Alert {
+	metadata := make(map[string]string, len(alert.Metadata))
+	for key, value := range alert.Metadata {
+		if text, ok := value.(string); ok {
+			metadata[key] = text
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			continue
+		}
+		metadata[key] = string(encoded)
+	}
+
+	return &Alert{
+		Id:              alert.ID,
+		Type:            alert.Type,
+		Level:           alert.Level,
+		Title:           alert.Title,
+		Description:     alert.Description,
+		TransactionHash: alert.TransactionHash,
+		Address:         alert.Address,
+		Network:         alert.Network,
+		RiskScore:       alert.RiskScore,
+		RiskFactors:     alert.RiskFactors,
+		Timestamp:       timestamppb.New(alert.Timestamp),
+		Status:          alert.Status,
+		Metadata:        metadata,
+	}
+}
+
+// bigIntString 转换大整数为十进制字符串
+func bigIntString(value *big.Int) string {
+	if value == nil {
+		return ""
+	}
+	return value.String()
+}
@@ -0,0 +1,229 @@
+package grpcapi
+
+//go:generate protoc -I collectorpb --go_out=collectorpb --go_opt=paths=source_relative --go-grpc_out=collectorpb --go-grpc_opt=paths=source_relative collectorpb/collector.proto
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"web3-data-collector/internal/api"
+	"web3-data-collector/internal/collector"
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/grpcapi/collectorpb"
+	"web3-data-collector/internal/logging"
+	"web3-data-collector/internal/models"
+	"web3-data-collector/internal/stream"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var logger = logging.Module("grpcapi")
+
+// riskLevelRank 告警等级排序，用于按最低等级过滤
+var riskLevelRank = map[string]int{
+	"INFO":     0,
+	"LOW":      1,
+	"MEDIUM":   2,
+	"HIGH":     3,
+	"CRITICAL": 4,
+}
+
+// Server gRPC服务
+type Server struct {
+	collectorpb.UnimplementedCollectorServiceServer
+
+	config     config.GRPCConfig
+	collector  *collector.BlockchainCollector
+	hub        *stream.Hub
+	auth       *api.Authenticator // 与HTTP API共用，未启用认证时为nil
+	grpcServer *grpc.Server
+}
+
+// NewServer 创建新的gRPC服务，启用认证时全部调用需携带与HTTP API相同的API key或JWT
+func NewServer(config config.GRPCConfig, collector *collector.BlockchainCollector, hub *stream.Hub, auth *api.Authenticator) *Server {
+	server := &Server{
+		config:    config,
+		collector: collector,
+		hub:       hub,
+		auth:      auth,
+	}
+	server.grpcServer = grpc.NewServer(
+		grpc.UnaryInterceptor(server.authorizeUnary),
+		grpc.StreamInterceptor(server.authorizeStream),
+	)
+
+	collectorpb.RegisterCollectorServiceServer(server.grpcServer, server)
+
+	return server
+}
+
+// Start 启动gRPC服务（阻塞直到服务停止）
+func (s *Server) Start() error {
+	address := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+
+	if s.auth == nil && !isLoopback(s.config.Host) {
+		logger.Warnf("gRPC server listens on %s without authentication", address)
+	}
+	logger.Infof("Starting gRPC server on %s", address)
+	return s.grpcServer.Serve(listener)
+}
+
+// Stop 优雅停止gRPC服务：先结束流式订阅，再等待处理中的请求完成，ctx到期后强制停止
+func (s *Server) Stop(ctx context.Context) {
+	s.hub.Close()
+
+	done := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		logger.Info("gRPC server stopped")
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		logger.Warn("gRPC server did not stop gracefully, forced to stop")
+	}
+}
+
+// isLoopback 判断监听地址是否仅限本机
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// GetNetworkStats 获取特定网络统计信息
+func (s *Server) GetNetworkStats(ctx context.Context, req *collectorpb.GetNetworkStatsRequest) (*collectorpb.NetworkStats, error) {
+	stats, exists := s.collector.GetNetworkStats()[req.GetNetwork()]
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "network %s not found", req.GetNetwork())
+	}
+
+	return toProtoNetworkStats(stats), nil
+}
+
+// ListNetworkStats 获取所有网络统计信息
+func (s *Server) ListNetworkStats(ctx context.Context, req *collectorpb.ListNetworkStatsRequest) (*collectorpb.ListNetworkStatsResponse, error) {
+	networkStats := s.collector.GetNetworkStats()
+
+	response := &collectorpb.ListNetworkStatsResponse{
+		Networks: make([]*collectorpb.NetworkStats, 0, len(networkStats)),
+	}
+	for _, stats := range networkStats {
+		response.Networks = append(response.Networks, toProtoNetworkStats(stats))
+	}
+
+	return response, nil
+}
+
+// GetLatestBlock 获取网络最近处理的区块
+func (s *Server) GetLatestBlock(ctx context.Context, req *collectorpb.GetLatestBlockRequest) (*collectorpb.Block, error) {
+	block, exists := s.hub.LatestBlock(req.GetNetwork())
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "no block processed for network %s", req.GetNetwork())
+	}
+
+	return collectorpb.FromBlock(block), nil
+}
+
+// SubscribeBlocks 订阅实时区块
+func (s *Server) SubscribeBlocks(req *collectorpb.SubscribeRequest, srv collectorpb.CollectorService_SubscribeBlocksServer) error {
+	blocks, cancel := s.hub.SubscribeBlocks(req.GetNetworks())
+	defer cancel()
+
+	for {
+		select {
+		case <-srv.Context().Done():
+			return nil
+		case block, ok := <-blocks:
+			if !ok {
+				return nil
+			}
+			if err := srv.Send(collectorpb.FromBlock(block)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// SubscribeTransactions 订阅实时交易
+func (s *Server) SubscribeTransactions(req *collectorpb.SubscribeRequest, srv collectorpb.CollectorService_SubscribeTransactionsServer) error {
+	transactions, cancel := s.hub.SubscribeTransactions(req.GetNetworks())
+	defer cancel()
+
+	for {
+		select {
+		case <-srv.Context().Done():
+			return nil
+		case tx, ok := <-transactions:
+			if !ok {
+				return nil
+			}
+			if err := srv.Send(collectorpb.FromTransaction(tx)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// SubscribeAlerts 订阅实时告警
+func (s *Server) SubscribeAlerts(req *collectorpb.SubscribeAlertsRequest, srv collectorpb.CollectorService_SubscribeAlertsServer) error {
+	minRank := 0
+	if req.GetMinLevel() != "" {
+		rank, exists := riskLevelRank[strings.ToUpper(req.GetMinLevel())]
+		if !exists {
+			return status.Errorf(codes.InvalidArgument, "unknown alert level %s", req.GetMinLevel())
+		}
+		minRank = rank
+	}
+
+	alerts, cancel := s.hub.SubscribeAlerts(req.GetNetworks())
+	defer cancel()
+
+	for {
+		select {
+		case <-srv.Context().Done():
+			return nil
+		case alert, ok := <-alerts:
+			if !ok {
+				return nil
+			}
+			if riskLevelRank[alert.Level] < minRank {
+				continue
+			}
+			if err := srv.Send(collectorpb.FromAlert(alert)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// toProtoNetworkStats 转换网络统计为protobuf消息
+func toProtoNetworkStats(stats *models.NetworkStats) *collectorpb.NetworkStats {
+	return &collectorpb.NetworkStats{
+		Network:          stats.Network,
+		LatestBlock:      stats.LatestBlock,
+		ChainHead:        stats.ChainHead,
+		BlockLag:         stats.BlockLag,
+		TotalTxProcessed: stats.TotalTxProcessed,
+		TxPerSecond:      stats.TxPerSecond,
+		AvgBlockTime:     stats.AvgBlockTime,
+		LastUpdateTime:   timestamppb.New(stats.LastUpdateTime),
+		IsHealthy:        stats.IsHealthy,
+		ErrorCount:       stats.ErrorCount,
+	}
+}
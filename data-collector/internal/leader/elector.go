@@ -0,0 +1,205 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/metrics"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Status 选举状态
+type Status struct {
+	NodeID string     `json:"node_id"`
+	Leader bool       `json:"leader"`
+	Holder string     `json:"holder,omitempty"` // 当前持有锁的实例
+	Since  *time.Time `json:"since,omitempty"`  // 成为主实例的时间
+}
+
+// Elector 基于Redis锁的主实例选举，锁带有效期，主实例按间隔续期；
+// 主实例退出或失联时锁过期，备实例在下一次抢锁时接管
+type Elector struct {
+	client         *database.RedisClient
+	metricsManager *metrics.Manager
+	key            string
+	nodeID         string
+	lease          time.Duration
+	retry          time.Duration
+
+	leader    bool
+	since     time.Time
+	renewedAt time.Time
+	elected   chan struct{}
+	lost      chan struct{}
+	mu        sync.RWMutex
+}
+
+// NewElector 根据配置创建选举器，未启用时返回nil
+func NewElector(cfg config.LeaderElectionConfig, client *database.RedisClient, metricsManager *metrics.Manager) (*Elector, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	lease, err := time.ParseDuration(cfg.LeaseDuration)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lease_duration: %w", err)
+	}
+	retry, err := time.ParseDuration(cfg.RetryInterval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid retry_interval: %w", err)
+	}
+
+	nodeID := cfg.NodeID
+	if nodeID == "" {
+		hostname, _ := os.Hostname()
+		nodeID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	metricsManager.SetLeaderStatus(false)
+	return &Elector{
+		client:         client,
+		metricsManager: metricsManager,
+		key:            cfg.Key,
+		nodeID:         nodeID,
+		lease:          lease,
+		retry:          retry,
+		elected:        make(chan struct{}),
+		lost:           make(chan struct{}),
+	}, nil
+}
+
+// Run 抢锁直到成为主实例，之后按间隔续期，失去主实例身份或ctx结束时返回
+func (e *Elector) Run(ctx context.Context) {
+	logrus.Infof("Leader election started as %s on %s", e.nodeID, e.key)
+
+	ticker := time.NewTicker(e.retry)
+	defer ticker.Stop()
+
+	for {
+		if e.IsLeader() {
+			if !e.renew() {
+				return
+			}
+		} else {
+			e.acquire()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// acquire 尝试获取锁
+func (e *Elector) acquire() {
+	acquired, err := e.client.SetNX(e.key, e.nodeID, e.lease)
+	if err != nil {
+		logrus.Warnf("Failed to acquire leader lock: %v", err)
+		return
+	}
+	if !acquired {
+		return
+	}
+
+	now := time.Now()
+	e.mu.Lock()
+	e.leader = true
+	e.since = now
+	e.renewedAt = now
+	e.mu.Unlock()
+
+	e.metricsManager.SetLeaderStatus(true)
+	close(e.elected)
+	logrus.Infof("Acquired leader lock %s as %s", e.key, e.nodeID)
+}
+
+// renew 续期锁，锁已被其他实例持有，或续期失败且锁可能已过期时放弃主实例身份
+func (e *Elector) renew() bool {
+	renewed, err := e.client.ExpireIfValue(e.key, e.nodeID, e.lease)
+	if err == nil && renewed {
+		e.mu.Lock()
+		e.renewedAt = time.Now()
+		e.mu.Unlock()
+		return true
+	}
+
+	if err != nil {
+		e.mu.RLock()
+		elapsed := time.Since(e.renewedAt)
+		e.mu.RUnlock()
+		// 在锁过期前一个间隔放弃，避免备实例接管后两个实例同时处理
+		if elapsed < e.lease-e.retry {
+			logrus.Warnf("Failed to renew leader lock, retrying: %v", err)
+			return true
+		}
+		logrus.Errorf("Failed to renew leader lock for %s: %v", elapsed, err)
+	} else {
+		logrus.Errorf("Leader lock %s is no longer held by %s", e.key, e.nodeID)
+	}
+
+	e.mu.Lock()
+	e.leader = false
+	e.mu.Unlock()
+
+	e.metricsManager.SetLeaderStatus(false)
+	close(e.lost)
+	return false
+}
+
+// Release 主实例退出时释放锁，备实例无需等待锁过期即可接管
+func (e *Elector) Release() {
+	if !e.IsLeader() {
+		return
+	}
+
+	if _, err := e.client.DeleteIfValue(e.key, e.nodeID); err != nil {
+		logrus.Warnf("Failed to release leader lock: %v", err)
+		return
+	}
+	logrus.Infof("Released leader lock %s", e.key)
+}
+
+// Elected 成为主实例时关闭
+func (e *Elector) Elected() <-chan struct{} {
+	return e.elected
+}
+
+// Lost 失去主实例身份时关闭，收集器不支持重启，此时应退出进程，由编排系统重新拉起为备实例
+func (e *Elector) Lost() <-chan struct{} {
+	return e.lost
+}
+
+// IsLeader 是否为主实例
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.leader
+}
+
+// Status 获取选举状态
+func (e *Elector) Status() *Status {
+	e.mu.RLock()
+	status := &Status{
+		NodeID: e.nodeID,
+		Leader: e.leader,
+	}
+	if e.leader {
+		since := e.since
+		status.Since = &since
+	}
+	e.mu.RUnlock()
+
+	if holder, err := e.client.Get(e.key); err == nil {
+		status.Holder = holder
+	}
+	return status
+}
@@ -0,0 +1,218 @@
+// Package logging 按模块（collector、processor等）设置日志级别，并通过上下文传递network、block、tx等日志字段。
+// 模块日志的格式及输出跟随logrus全局日志，未单独设置级别的模块使用默认级别
+package logging
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	mu           sync.Mutex
+	defaultLevel = logrus.InfoLevel
+	modules      = make(map[string]*module)
+)
+
+// module 模块日志及单独设置的级别，未设置时跟随默认级别
+type module struct {
+	logger *logrus.Logger
+	level  *logrus.Level
+}
+
+// Logger 模块日志，Entry带有module字段
+type Logger struct {
+	*logrus.Entry
+}
+
+// Levels 默认级别及各模块的生效级别
+type Levels struct {
+	Level   string            `json:"level"`
+	Modules map[string]string `json:"modules"`
+}
+
+// Module 获取模块日志，通常赋值给包级变量，同名模块共用同一日志
+func Module(name string) *Logger {
+	mu.Lock()
+	defer mu.Unlock()
+
+	m, exists := modules[name]
+	if !exists {
+		m = newModule()
+		modules[name] = m
+	}
+	return &Logger{Entry: m.logger.WithField("module", name)}
+}
+
+// newModule 创建使用默认级别的模块日志，调用方需持有mu
+func newModule() *module {
+	return &module{logger: &logrus.Logger{
+		Out:       standardOutput{},
+		Formatter: standardFormatter{},
+		Hooks:     make(logrus.LevelHooks),
+		Level:     defaultLevel,
+		ExitFunc:  logrus.StandardLogger().ExitFunc,
+	}}
+}
+
+// effectiveLevel 单独设置的级别，未设置时为默认级别，调用方需持有mu
+func (m *module) effectiveLevel() logrus.Level {
+	if m.level != nil {
+		return *m.level
+	}
+	return defaultLevel
+}
+
+// WithContext 返回附加了上下文中日志字段的日志条目
+func (l *Logger) WithContext(ctx context.Context) *logrus.Entry {
+	return l.Entry.WithContext(ctx).WithFields(fieldsFrom(ctx))
+}
+
+type fieldsKey struct{}
+
+// WithFields 在上下文中附加日志字段，与已有字段合并，同名字段以新值为准
+func WithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	existing := fieldsFrom(ctx)
+	merged := make(logrus.Fields, len(existing)+len(fields))
+	for key, value := range existing {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+func fieldsFrom(ctx context.Context) logrus.Fields {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsKey{}).(logrus.Fields)
+	return fields
+}
+
+// Configure 按配置设置默认级别及各模块级别，清除运行时的单独设置
+func Configure(level string, moduleLevels map[string]string) error {
+	parsed, err := logrus.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("logging.level: %w", err)
+	}
+	overrides := make(map[string]logrus.Level, len(moduleLevels))
+	for name, value := range moduleLevels {
+		moduleLevel, err := logrus.ParseLevel(value)
+		if err != nil {
+			return fmt.Errorf("logging.modules.%s: %w", name, err)
+		}
+		overrides[name] = moduleLevel
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	defaultLevel = parsed
+	logrus.SetLevel(parsed)
+	for name := range overrides {
+		if _, exists := modules[name]; !exists {
+			modules[name] = newModule()
+		}
+	}
+	for name, m := range modules {
+		m.level = nil
+		if moduleLevel, exists := overrides[name]; exists {
+			m.level = &moduleLevel
+		}
+		m.logger.SetLevel(m.effectiveLevel())
+	}
+	return nil
+}
+
+// SetDefaultLevel 设置全局日志及未单独设置级别的模块的级别
+func SetDefaultLevel(level logrus.Level) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	defaultLevel = level
+	logrus.SetLevel(level)
+	for _, m := range modules {
+		m.logger.SetLevel(m.effectiveLevel())
+	}
+}
+
+// SetModuleLevel 单独设置模块的级别，模块尚未注册时先注册
+func SetModuleLevel(name string, level logrus.Level) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	m, exists := modules[name]
+	if !exists {
+		m = newModule()
+		modules[name] = m
+	}
+	m.level = &level
+	m.logger.SetLevel(level)
+}
+
+// ResetModuleLevel 清除模块的单独设置，恢复为默认级别
+func ResetModuleLevel(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if m, exists := modules[name]; exists {
+		m.level = nil
+		m.logger.SetLevel(defaultLevel)
+	}
+}
+
+// Known 判断模块是否已注册
+func Known(name string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	_, exists := modules[name]
+	return exists
+}
+
+// Names 已注册的模块名，按名称排序
+func Names() []string {
+	mu.Lock()
+	defer mu.Unlock()
+
+	names := make([]string, 0, len(modules))
+	for name := range modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Current 当前默认级别及各模块的生效级别
+func Current() Levels {
+	mu.Lock()
+	defer mu.Unlock()
+
+	levels := Levels{
+		Level:   defaultLevel.String(),
+		Modules: make(map[string]string, len(modules)),
+	}
+	for name, m := range modules {
+		levels.Modules[name] = m.logger.GetLevel().String()
+	}
+	return levels
+}
+
+// standardFormatter 使用全局日志的格式，启动后修改全局格式时模块日志同步生效
+type standardFormatter struct{}
+
+func (standardFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	return logrus.StandardLogger().Formatter.Format(entry)
+}
+
+// standardOutput 写入全局日志的输出
+type standardOutput struct{}
+
+func (standardOutput) Write(p []byte) (int, error) {
+	return logrus.StandardLogger().Out.Write(p)
+}
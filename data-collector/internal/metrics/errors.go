@@ -0,0 +1,166 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/faults"
+)
+
+// maxErrorSamples 保留的最近错误样本数
+const maxErrorSamples = 50
+
+// ErrorSample 最近发生的一个错误
+type ErrorSample struct {
+	Category string    `json:"category"`
+	Network  string    `json:"network,omitempty"`
+	Block    uint64    `json:"block,omitempty"`
+	Message  string    `json:"message"`
+	Time     time.Time `json:"time"`
+}
+
+// ErrorBudgetStatus 错误类别在预算窗口内的用量
+type ErrorBudgetStatus struct {
+	Category  string  `json:"category"`
+	Budget    int     `json:"budget"`
+	Used      int     `json:"used"`
+	Remaining float64 `json:"remaining"` // 剩余比例，超出预算时为负
+}
+
+// ErrorSummary 按类别的错误计数、错误预算及最近的错误样本
+type ErrorSummary struct {
+	Window  string              `json:"window,omitempty"`
+	Counts  map[string]uint64   `json:"counts"`
+	Budgets []ErrorBudgetStatus `json:"budgets"`
+	Recent  []ErrorSample       `json:"recent"` // 最新的在前
+}
+
+// errorTracker 错误计数、预算窗口内的分钟计数及最近样本
+type errorTracker struct {
+	window  time.Duration
+	budgets map[string]int
+	counts  map[string]uint64
+	minutes map[string]map[int64]int // 类别 -> 分钟起点(unix) -> 错误数
+	samples []ErrorSample
+	mu      sync.Mutex
+}
+
+func newErrorTracker() *errorTracker {
+	return &errorTracker{
+		window:  time.Hour,
+		budgets: make(map[string]int),
+		counts:  make(map[string]uint64),
+		minutes: make(map[string]map[int64]int),
+	}
+}
+
+// SetErrorBudgets 设置各错误类别在窗口内允许的错误数，未设置预算的类别只计数
+func (m *Manager) SetErrorBudgets(window time.Duration, budgets map[string]int) {
+	m.errors.mu.Lock()
+	if window > 0 {
+		m.errors.window = window
+	}
+	m.errors.budgets = make(map[string]int, len(budgets))
+	for category, budget := range budgets {
+		m.errors.budgets[category] = budget
+	}
+	m.errors.mu.Unlock()
+
+	m.refreshErrorBudgets()
+}
+
+// RecordError 按类别记录错误，错误本身未携带的网络及区块号使用调用方提供的值
+func (m *Manager) RecordError(err error, network string, block uint64) {
+	if err == nil {
+		return
+	}
+	ctx := faults.Describe(err, network, block)
+	m.errorsByCategory.WithLabelValues(ctx.Network, ctx.Category).Inc()
+
+	now := time.Now()
+	m.errors.mu.Lock()
+	m.errors.counts[ctx.Category]++
+	minutes, exists := m.errors.minutes[ctx.Category]
+	if !exists {
+		minutes = make(map[int64]int)
+		m.errors.minutes[ctx.Category] = minutes
+	}
+	minutes[now.Truncate(time.Minute).Unix()]++
+
+	m.errors.samples = append(m.errors.samples, ErrorSample{
+		Category: ctx.Category,
+		Network:  ctx.Network,
+		Block:    ctx.Block,
+		Message:  err.Error(),
+		Time:     now,
+	})
+	if len(m.errors.samples) > maxErrorSamples {
+		m.errors.samples = m.errors.samples[len(m.errors.samples)-maxErrorSamples:]
+	}
+	m.errors.mu.Unlock()
+
+	m.refreshErrorBudgets()
+}
+
+// ErrorSummary 获取按类别的错误计数、错误预算用量及最近的错误样本
+func (m *Manager) ErrorSummary() *ErrorSummary {
+	statuses := m.refreshErrorBudgets()
+
+	m.errors.mu.Lock()
+	defer m.errors.mu.Unlock()
+
+	summary := &ErrorSummary{
+		Counts:  make(map[string]uint64, len(faults.Categories)),
+		Budgets: statuses,
+		Recent:  make([]ErrorSample, 0, len(m.errors.samples)),
+	}
+	if len(statuses) > 0 {
+		summary.Window = m.errors.window.String()
+	}
+	for _, category := range faults.Categories {
+		summary.Counts[category] = m.errors.counts[category]
+	}
+	for i := len(m.errors.samples) - 1; i >= 0; i-- {
+		summary.Recent = append(summary.Recent, m.errors.samples[i])
+	}
+	return summary
+}
+
+// refreshErrorBudgets 清理窗口外的分钟计数，更新剩余预算指标
+func (m *Manager) refreshErrorBudgets() []ErrorBudgetStatus {
+	m.errors.mu.Lock()
+	defer m.errors.mu.Unlock()
+
+	cutoff := time.Now().Add(-m.errors.window).Truncate(time.Minute).Unix()
+	for _, minutes := range m.errors.minutes {
+		for minute := range minutes {
+			if minute < cutoff {
+				delete(minutes, minute)
+			}
+		}
+	}
+
+	statuses := make([]ErrorBudgetStatus, 0, len(m.errors.budgets))
+	for category, budget := range m.errors.budgets {
+		if budget <= 0 {
+			continue
+		}
+		used := 0
+		for _, count := range m.errors.minutes[category] {
+			used += count
+		}
+		remaining := 1 - float64(used)/float64(budget)
+		m.errorBudgetRemaining.WithLabelValues(category).Set(remaining)
+		statuses = append(statuses, ErrorBudgetStatus{
+			Category:  category,
+			Budget:    budget,
+			Used:      used,
+			Remaining: remaining,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Category < statuses[j].Category
+	})
+	return statuses
+}
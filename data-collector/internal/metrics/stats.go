@@ -0,0 +1,291 @@
+package metrics
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// statsSnapshotInterval 采集快照的间隔，性能指标的时间窗口按快照对齐
+const statsSnapshotInterval = time.Minute
+
+// statsRetention 快照的保留时长，更长的窗口按最早的快照计算
+const statsRetention = 24 * time.Hour
+
+// 统计用到的指标名
+const (
+	metricBlocksProcessed     = "web3_blocks_processed_total"
+	metricTransactions        = "web3_transactions_processed_total"
+	metricAlertsGenerated     = "web3_alerts_generated_total"
+	metricSinkPublishTotal    = "web3_sink_publish_total"
+	metricBlockProcessingTime = "web3_block_processing_duration_seconds"
+	metricTxProcessingTime    = "web3_transaction_processing_duration_seconds"
+	metricSinkPublishDuration = "web3_sink_publish_duration_seconds"
+)
+
+// statsBucket 直方图的累计桶
+type statsBucket struct {
+	upper float64
+	count float64
+}
+
+// statsSeries 一个序列在快照时的值，计数器及仪表盘只有value
+type statsSeries struct {
+	labels  map[string]string
+	value   float64
+	count   float64
+	sum     float64
+	buckets []statsBucket
+}
+
+// statsFamily 同名指标的全部序列，按标签键索引
+type statsFamily struct {
+	kind   string // counter / gauge / histogram
+	series map[string]*statsSeries
+}
+
+// statsSnapshot 某一时刻从registry采集的全部指标
+type statsSnapshot struct {
+	at       time.Time
+	families map[string]*statsFamily
+}
+
+// histogramStats 直方图在时间窗口内的样本
+type histogramStats struct {
+	count   float64
+	sum     float64
+	buckets []statsBucket
+}
+
+// Run 定期采集指标快照，供按时间窗口计算性能指标，并刷新错误预算
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(statsSnapshotInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.refreshErrorBudgets()
+			snapshot, err := m.snapshot()
+			if err != nil {
+				logrus.Warnf("Failed to gather metrics snapshot: %v", err)
+				continue
+			}
+			m.snapshotsMu.Lock()
+			m.snapshots = append(m.snapshots, snapshot)
+			cutoff := snapshot.at.Add(-statsRetention)
+			for len(m.snapshots) > 1 && m.snapshots[1].at.Before(cutoff) {
+				m.snapshots = m.snapshots[1:]
+			}
+			m.snapshotsMu.Unlock()
+		}
+	}
+}
+
+// resetSnapshots 清空快照，以当前值作为启动时的快照
+func (m *Manager) resetSnapshots() {
+	m.snapshotsMu.Lock()
+	defer m.snapshotsMu.Unlock()
+
+	m.snapshots = nil
+	snapshot, err := m.snapshot()
+	if err != nil {
+		logrus.Warnf("Failed to gather metrics snapshot: %v", err)
+		snapshot = &statsSnapshot{at: time.Now()}
+	}
+	m.snapshots = append(m.snapshots, snapshot)
+}
+
+// window 当前快照及时间窗口起点的快照；运行时间不足窗口时起点为启动时的快照
+func (m *Manager) window(timeWindow time.Duration) (*statsSnapshot, *statsSnapshot, error) {
+	current, err := m.snapshot()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m.snapshotsMu.Lock()
+	defer m.snapshotsMu.Unlock()
+
+	start := current.at.Add(-timeWindow)
+	base := m.snapshots[0]
+	for _, snapshot := range m.snapshots {
+		if snapshot.at.After(start) {
+			break
+		}
+		base = snapshot
+	}
+	return current, base, nil
+}
+
+// snapshot 从registry采集全部指标
+func (m *Manager) snapshot() (*statsSnapshot, error) {
+	families, err := m.registry.Gather()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &statsSnapshot{
+		at:       time.Now(),
+		families: make(map[string]*statsFamily, len(families)),
+	}
+	for _, family := range families {
+		collected := &statsFamily{
+			kind:   strings.ToLower(family.GetType().String()),
+			series: make(map[string]*statsSeries, len(family.GetMetric())),
+		}
+		for _, metric := range family.GetMetric() {
+			series := &statsSeries{labels: make(map[string]string)}
+			for _, label := range metric.GetLabel() {
+				series.labels[label.GetName()] = label.GetValue()
+			}
+			switch {
+			case metric.GetCounter() != nil:
+				series.value = metric.GetCounter().GetValue()
+			case metric.GetGauge() != nil:
+				series.value = metric.GetGauge().GetValue()
+			case metric.GetHistogram() != nil:
+				histogram := metric.GetHistogram()
+				series.count = float64(histogram.GetSampleCount())
+				series.sum = histogram.GetSampleSum()
+				for _, bucket := range histogram.GetBucket() {
+					series.buckets = append(series.buckets, statsBucket{
+						upper: bucket.GetUpperBound(),
+						count: float64(bucket.GetCumulativeCount()),
+					})
+				}
+			}
+			collected.series[labelKey(series.labels)] = series
+		}
+		snapshot.families[family.GetName()] = collected
+	}
+	return snapshot, nil
+}
+
+// series 指标中标签匹配的序列，match为nil时返回全部
+func (s *statsSnapshot) series(name string, match map[string]string) []*statsSeries {
+	family, exists := s.families[name]
+	if !exists {
+		return nil
+	}
+
+	var matched []*statsSeries
+	for _, series := range family.series {
+		if labelsMatch(series.labels, match) {
+			matched = append(matched, series)
+		}
+	}
+	return matched
+}
+
+// counter 标签匹配的序列之和；base非nil时为相对base的增量，序列在窗口内重置时取当前值
+func (s *statsSnapshot) counter(name string, match map[string]string, base *statsSnapshot) float64 {
+	var total float64
+	for _, series := range s.series(name, match) {
+		value := series.value
+		if previous := base.find(name, series.labels); previous != nil && previous.value <= value {
+			value -= previous.value
+		}
+		total += value
+	}
+	return total
+}
+
+// histogram 标签匹配的序列合并后的样本；base非nil时为相对base的增量
+func (s *statsSnapshot) histogram(name string, match map[string]string, base *statsSnapshot) *histogramStats {
+	result := &histogramStats{}
+	for _, series := range s.series(name, match) {
+		count, sum := series.count, series.sum
+		buckets := append([]statsBucket(nil), series.buckets...)
+		if previous := base.find(name, series.labels); previous != nil && previous.count <= count && len(previous.buckets) == len(buckets) {
+			count -= previous.count
+			sum -= previous.sum
+			for i := range buckets {
+				buckets[i].count -= previous.buckets[i].count
+			}
+		}
+
+		result.count += count
+		result.sum += sum
+		if result.buckets == nil {
+			result.buckets = buckets
+			continue
+		}
+		for i := range buckets {
+			if i < len(result.buckets) {
+				result.buckets[i].count += buckets[i].count
+			}
+		}
+	}
+	return result
+}
+
+// find 标签完全相同的序列
+func (s *statsSnapshot) find(name string, labels map[string]string) *statsSeries {
+	if s == nil {
+		return nil
+	}
+	family, exists := s.families[name]
+	if !exists {
+		return nil
+	}
+	return family.series[labelKey(labels)]
+}
+
+// average 样本的平均值，没有样本时为0
+func (h *histogramStats) average() float64 {
+	if h.count == 0 {
+		return 0
+	}
+	return h.sum / h.count
+}
+
+// quantile 按Prometheus histogram_quantile的方式在桶内线性插值，落在+Inf桶时取最大的有限上界
+func (h *histogramStats) quantile(q float64) float64 {
+	if h.count == 0 || len(h.buckets) == 0 {
+		return 0
+	}
+
+	rank := q * h.count
+	lower, previous := 0.0, 0.0
+	for _, bucket := range h.buckets {
+		if bucket.count >= rank {
+			if bucket.count == previous {
+				return bucket.upper
+			}
+			return lower + (bucket.upper-lower)*(rank-previous)/(bucket.count-previous)
+		}
+		lower, previous = bucket.upper, bucket.count
+	}
+	// 采集结果中不含+Inf桶，超出最大上界的样本只计入样本数
+	return lower
+}
+
+// labelKey 按名称排序的标签，作为序列的键
+func labelKey(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, name+"="+labels[name])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// labelsMatch 序列是否包含match中的全部标签
+func labelsMatch(labels, match map[string]string) bool {
+	for name, value := range match {
+		if labels[name] != value {
+			return false
+		}
+	}
+	return true
+}
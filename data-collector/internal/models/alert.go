@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// 告警处理状态，新告警为ACTIVE
+const (
+	AlertStatusActive       = "ACTIVE"
+	AlertStatusAcknowledged = "ACKNOWLEDGED"
+	AlertStatusResolved     = "RESOLVED"
+	AlertStatusSnoozed      = "SNOOZED" // 地址静默期间产生的告警，只保存不推送
+)
+
+// 告警处理操作
+const (
+	AlertActionAcknowledge = "acknowledge"
+	AlertActionResolve     = "resolve"
+	AlertActionSnooze      = "snooze"
+)
+
+// AlertRecord 保存的告警及处理记录
+type AlertRecord struct {
+	RiskAlert
+	AcknowledgedBy string        `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt *time.Time    `json:"acknowledged_at,omitempty"`
+	ResolvedBy     string        `json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time    `json:"resolved_at,omitempty"`
+	SnoozeID       string        `json:"snooze_id,omitempty"` // 静默期间产生时对应的静默规则
+	History        []AlertAction `json:"history,omitempty"`
+}
+
+// AlertAction 告警处理操作记录
+type AlertAction struct {
+	Action string    `json:"action"`
+	Actor  string    `json:"actor,omitempty"` // 未启用认证时为请求中的operator，可为空
+	Note   string    `json:"note,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+// AlertSnooze 按地址静默告警，期间该地址的告警只保存不推送
+type AlertSnooze struct {
+	ID        string    `json:"id"`
+	Network   string    `json:"network"`
+	Address   string    `json:"address"`
+	Types     []string  `json:"types,omitempty"` // 为空表示全部类型
+	Reason    string    `json:"reason,omitempty"`
+	Until     time.Time `json:"until"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AlertQuery 告警查询条件，空字段不过滤
+type AlertQuery struct {
+	Network string
+	Type    string
+	Level   string
+	Status  string
+	Address string
+	Since   time.Time
+	Until   time.Time
+	Limit   int
+}
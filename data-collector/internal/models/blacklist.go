@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// 黑名单条目状态
+const (
+	BlacklistStatusProposed = "PROPOSED"
+	BlacklistStatusActive   = "ACTIVE"
+	BlacklistStatusRetired  = "RETIRED"
+)
+
+// BlacklistEntry 表示黑名单条目
+type BlacklistEntry struct {
+	Address        string     `json:"address"`
+	Status         string     `json:"status"`
+	Reason         string     `json:"reason,omitempty"`
+	ProposedBy     string     `json:"proposed_by,omitempty"`
+	ReviewedBy     string     `json:"reviewed_by,omitempty"`
+	RetiredBy      string     `json:"retired_by,omitempty"`
+	EffectiveFrom  time.Time  `json:"effective_from"`
+	EffectiveUntil *time.Time `json:"effective_until,omitempty"`
+	Version        uint64     `json:"version"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// BlacklistChange 表示黑名单变更记录
+type BlacklistChange struct {
+	Version   uint64    `json:"version"`
+	Address   string    `json:"address"`
+	From      string    `json:"from,omitempty"`
+	To        string    `json:"to"`
+	Operator  string    `json:"operator,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
@@ -7,6 +7,7 @@ import (
 
 // Transaction 表示区块链交易
 type Transaction struct {
+	ID                string    `json:"id"` // 见 TransactionID
 	Hash              string    `json:"hash"`
 	BlockNumber       uint64    `json:"block_number"`
 	BlockHash         string    `json:"block_hash"`
@@ -19,10 +20,13 @@ type Transaction struct {
 	GasUsed           uint64    `json:"gas_used,omitempty"`
 	Nonce             uint64    `json:"nonce"`
 	InputData         string    `json:"input_data,omitempty"`
+	MethodName        string    `json:"method_name,omitempty"` // 由函数签名库解析的函数名，如 transferFrom
 	Timestamp         time.Time `json:"timestamp"`
 	Network           string    `json:"network"`
 	Status            uint64    `json:"status"`
 	ContractAddress   string    `json:"contract_address,omitempty"`
+	FromENS           string    `json:"from_ens,omitempty"` // 发起方的主ENS名称
+	ToENS             string    `json:"to_ens,omitempty"`   // 接收方的主ENS名称
 	IsContractCall    bool      `json:"is_contract_call"`
 	IsTokenTransfer   bool      `json:"is_token_transfer"`
 	TokenSymbol       string    `json:"token_symbol,omitempty"`
@@ -30,11 +34,37 @@ type Transaction struct {
 	TokenDecimals     uint8     `json:"token_decimals,omitempty"`
 	MaxFeePerGas      *big.Int  `json:"max_fee_per_gas,omitempty"`
 	MaxPriorityFeePerGas *big.Int `json:"max_priority_fee_per_gas,omitempty"`
+	EffectiveGasPrice *big.Int  `json:"effective_gas_price,omitempty"` // 取自交易回执
 	TransactionType   uint8     `json:"transaction_type"`
+	USDValue          float64   `json:"usd_value,omitempty"` // 原生币及代币转账的美元价值
+	Chain             string    `json:"chain,omitempty"`
+	// 非EVM链的代币余额变化（如Solana SPL代币）
+	TokenBalanceChanges []TokenBalanceChange `json:"token_balance_changes,omitempty"`
+	// 收集器收到所在区块的时间
+	ReceivedAt time.Time `json:"received_at"`
+	// 所在区块的确认状态，见 Finality*
+	Finality string `json:"finality,omitempty"`
+	// 按网络抽样配置发布时的抽样比例，下游聚合按 1/sample_rate 加权；未抽样时为空
+	SampleRate float64 `json:"sample_rate,omitempty"`
+	// L2网络特有的字段，非L2网络为空
+	L2 *L2TransactionInfo `json:"l2,omitempty"`
+	// gas_used、status已按交易回执（Solana为交易执行结果）填充，为false时status无意义
+	HasReceipt bool `json:"has_receipt,omitempty"`
+	// 交易回执中的日志，需启用网络的receipts.logs
+	Logs []TransactionLog `json:"logs,omitempty"`
+}
+
+// TransactionLog 交易回执中的日志
+type TransactionLog struct {
+	Address  string   `json:"address"`
+	Topics   []string `json:"topics"`
+	Data     string   `json:"data,omitempty"`
+	LogIndex uint     `json:"log_index"`
 }
 
 // Block 表示区块信息
 type Block struct {
+	ID           string      `json:"id"` // 见 BlockID
 	Number       uint64      `json:"number"`
 	Hash         string      `json:"hash"`
 	ParentHash   string      `json:"parent_hash"`
@@ -48,10 +78,184 @@ type Block struct {
 	TxCount      int         `json:"tx_count"`
 	Size         uint64      `json:"size"`
 	BaseFeePerGas *big.Int   `json:"base_fee_per_gas,omitempty"`
+	Chain        string      `json:"chain,omitempty"` // 为空表示EVM链
+	ExtraData    *BlockExtraData `json:"extra_data,omitempty"`
+	ReceivedAt   time.Time   `json:"received_at"`             // 收集器收到区块的时间
+	ClockSkewMs  int64       `json:"clock_skew_ms,omitempty"` // 区块时间减接收时间，补块不计算
+	Finality     string      `json:"finality,omitempty"`      // 见 Finality*
+	Withdrawals  []Withdrawal `json:"withdrawals,omitempty"`  // 上海升级后的信标链提款
+	L2           *L2BlockInfo `json:"l2,omitempty"`           // L2网络的L1起源信息
+}
+
+// Withdrawal 信标链提款（EIP-4895），金额已由Gwei换算为wei
+type Withdrawal struct {
+	ID             string    `json:"id"` // 见 WithdrawalID
+	Index          uint64    `json:"index"`
+	ValidatorIndex uint64    `json:"validator_index"`
+	Address        string    `json:"address"`
+	Amount         *big.Int  `json:"amount"`
+	BlockNumber    uint64    `json:"block_number"`
+	BlockHash      string    `json:"block_hash"`
+	Timestamp      time.Time `json:"timestamp"`
+	Network        string    `json:"network"`
+	Finality       string    `json:"finality,omitempty"`
+}
+
+// 区块确认状态：EVM网络为处理区块时使用的区块标签，Solana网络为确认级别
+const (
+	FinalityLatest    = "latest"    // 最新区块，可能因链重组被替换
+	FinalitySafe      = "safe"      // 已被多数验证者认可，重组可能性很低
+	FinalityFinalized = "finalized" // 已最终确定，不会被重组
+)
+
+// extraData解码格式
+const (
+	ExtraDataBuilder  = "builder"  // 以太坊主网等：出块者/构建者标识文本
+	ExtraDataClique   = "clique"   // Clique PoA：vanity + 签名者列表 + 出块签名
+	ExtraDataArbitrum = "arbitrum" // Arbitrum：extraData为send root，mixHash含L1区块号
+	ExtraDataOptimism = "optimism" // OP Stack：Holocene起extraData含EIP-1559参数
+	ExtraDataNone     = "none"
+)
+
+// BlockExtraData 按网络约定解码的区块头extraData
+type BlockExtraData struct {
+	Format     string         `json:"format"`
+	BuilderTag string         `json:"builder_tag,omitempty"`
+	Clique     *CliqueExtra   `json:"clique,omitempty"`
+	Arbitrum   *ArbitrumExtra `json:"arbitrum,omitempty"`
+	Optimism   *OptimismExtra `json:"optimism,omitempty"`
+	Raw        string         `json:"raw,omitempty"` // 无法按格式解码时保留原始数据
+}
+
+// CliqueExtra Clique PoA区块头信息
+type CliqueExtra struct {
+	Vanity  string   `json:"vanity,omitempty"`
+	Signer  string   `json:"signer,omitempty"`  // 由出块签名恢复的签名者
+	Signers []string `json:"signers,omitempty"` // 检查点区块携带的签名者列表
+}
+
+// ArbitrumExtra Arbitrum定序器写入的区块头信息
+type ArbitrumExtra struct {
+	SendRoot      string `json:"send_root"`
+	SendCount     uint64 `json:"send_count"`
+	L1BlockNumber uint64 `json:"l1_block_number"`
+	ArbOSVersion  uint64 `json:"arbos_version"`
+}
+
+// OptimismExtra OP Stack区块头中的EIP-1559参数
+type OptimismExtra struct {
+	Version            uint8  `json:"version"`
+	EIP1559Denominator uint32 `json:"eip1559_denominator"`
+	EIP1559Elasticity  uint32 `json:"eip1559_elasticity"`
+}
+
+// 链类型
+const (
+	ChainEVM    = "evm"
+	ChainSolana = "solana"
+)
+
+// TokenBalanceChange 表示交易前后代币账户余额变化
+type TokenBalanceChange struct {
+	Account    string   `json:"account"`
+	Owner      string   `json:"owner"`
+	Mint       string   `json:"mint"`
+	Decimals   uint8    `json:"decimals"`
+	PreAmount  *big.Int `json:"pre_amount"`
+	PostAmount *big.Int `json:"post_amount"`
+	Delta      *big.Int `json:"delta"`
+}
+
+// BlockHeader 表示新区块头摘要，用于低延迟推送
+type BlockHeader struct {
+	Network       string    `json:"network"`
+	Number        uint64    `json:"number"`
+	Hash          string    `json:"hash"`
+	ParentHash    string    `json:"parent_hash"`
+	Timestamp     time.Time `json:"timestamp"`
+	GasUsed       uint64    `json:"gas_used"`
+	GasLimit      uint64    `json:"gas_limit"`
+	Miner         string    `json:"miner"`
+	BaseFeePerGas *big.Int  `json:"base_fee_per_gas,omitempty"`
+	ObservedAt    time.Time `json:"observed_at"`
+}
+
+// EnrichedBlock 表示处理完成的完整区块（含风险结果与解码事件）
+type EnrichedBlock struct {
+	Block       *Block       `json:"block"`
+	Alerts      []*RiskAlert `json:"alerts"`
+	Events      []*Event     `json:"events,omitempty"`
+	ObservedAt  time.Time    `json:"observed_at"`
+	ProcessedAt time.Time    `json:"processed_at"`
+	LatencyMs   int64        `json:"latency_ms"`
+	Supply      *BlockSupply `json:"supply,omitempty"`
+	Gas         *GasStats    `json:"gas,omitempty"`
+}
+
+// GasStats 单个区块的gas费用统计，价格单位为wei
+type GasStats struct {
+	ID                string    `json:"id"` // 见 GasStatsID
+	Network           string    `json:"network"`
+	BlockNumber       uint64    `json:"block_number"`
+	BlockHash         string    `json:"block_hash"`
+	Timestamp         time.Time `json:"timestamp"`
+	BaseFeePerGas     *big.Int  `json:"base_fee_per_gas,omitempty"`
+	NextBaseFeePerGas *big.Int  `json:"next_base_fee_per_gas,omitempty"` // 按EIP-1559默认参数推算
+	GasUsed           uint64    `json:"gas_used"`
+	GasLimit          uint64    `json:"gas_limit"`
+	Utilization       float64   `json:"utilization"` // gas_used / gas_limit
+	TxCount           int       `json:"tx_count"`
+	// 区块内交易优先费（实际gas单价减基础费用）的分位数，没有交易时为空
+	PriorityFees *FeePercentiles `json:"priority_fees,omitempty"`
+}
+
+// FeePercentiles 费用分位数
+type FeePercentiles struct {
+	P10 *big.Int `json:"p10"`
+	P25 *big.Int `json:"p25"`
+	P50 *big.Int `json:"p50"`
+	P75 *big.Int `json:"p75"`
+	P90 *big.Int `json:"p90"`
+}
+
+// GasEstimate 基于最近区块的费用估算
+type GasEstimate struct {
+	Network           string         `json:"network"`
+	BlockNumber       uint64         `json:"block_number"` // 最新统计的区块
+	Blocks            int            `json:"blocks"`       // 参与估算的区块数
+	BaseFeePerGas     *big.Int       `json:"base_fee_per_gas,omitempty"`
+	NextBaseFeePerGas *big.Int       `json:"next_base_fee_per_gas,omitempty"`
+	Utilization       float64        `json:"utilization"` // 参与估算区块的平均利用率
+	Slow              *FeeSuggestion `json:"slow"`
+	Standard          *FeeSuggestion `json:"standard"`
+	Fast              *FeeSuggestion `json:"fast"`
+}
+
+// FeeSuggestion 建议的EIP-1559费用参数，未启用EIP-1559的网络只有GasPrice
+type FeeSuggestion struct {
+	MaxPriorityFeePerGas *big.Int `json:"max_priority_fee_per_gas"`
+	MaxFeePerGas         *big.Int `json:"max_fee_per_gas,omitempty"`
+	GasPrice             *big.Int `json:"gas_price,omitempty"`
+}
+
+// BlockSupply 区块对原生币供应量的影响，金额为最小单位
+type BlockSupply struct {
+	Network       string    `json:"network"`
+	BlockNumber   uint64    `json:"block_number"`
+	Timestamp     time.Time `json:"timestamp"`
+	BaseFeePerGas *big.Int  `json:"base_fee_per_gas,omitempty"`
+	GasUsed       uint64    `json:"gas_used"`
+	Issuance      *big.Int  `json:"issuance"`   // 区块奖励
+	Burned        *big.Int  `json:"burned"`     // 销毁的基础费用（EIP-1559）
+	Tips          *big.Int  `json:"tips"`       // 支付给出块者的优先费
+	NetChange     *big.Int  `json:"net_change"` // 发行减销毁，为负时供应量减少
+	// OP Stack交易另付的L1数据费用，不计入供应量变化
+	L1Fees *big.Int `json:"l1_fees,omitempty"`
 }
 
 // TokenTransfer 表示代币转账事件
 type TokenTransfer struct {
+	ID              string    `json:"id"` // 见 TransferID
 	TransactionHash string    `json:"transaction_hash"`
 	BlockNumber     uint64    `json:"block_number"`
 	LogIndex        uint      `json:"log_index"`
@@ -63,26 +267,32 @@ type TokenTransfer struct {
 	TokenDecimals   uint8     `json:"token_decimals"`
 	Timestamp       time.Time `json:"timestamp"`
 	Network         string    `json:"network"`
+	USDValue        float64   `json:"usd_value,omitempty"`
 }
 
 // Event 表示智能合约事件
 type Event struct {
-	TransactionHash string      `json:"transaction_hash"`
-	BlockNumber     uint64      `json:"block_number"`
-	LogIndex        uint        `json:"log_index"`
-	ContractAddress string      `json:"contract_address"`
-	EventName       string      `json:"event_name"`
-	EventSignature  string      `json:"event_signature"`
-	Topics          []string    `json:"topics"`
-	Data            string      `json:"data"`
-	DecodedData     interface{} `json:"decoded_data,omitempty"`
-	Timestamp       time.Time   `json:"timestamp"`
-	Network         string      `json:"network"`
+	ID               string      `json:"id"` // 见 EventID
+	TransactionHash  string      `json:"transaction_hash"`
+	BlockNumber      uint64      `json:"block_number"`
+	BlockHash        string      `json:"block_hash"`
+	TransactionIndex uint        `json:"transaction_index"`
+	LogIndex         uint        `json:"log_index"`
+	ContractAddress  string      `json:"contract_address"`
+	EventName        string      `json:"event_name"`
+	EventSignature   string      `json:"event_signature"`
+	Topics           []string    `json:"topics"`
+	Data             string      `json:"data"`
+	DecodedData      interface{} `json:"decoded_data,omitempty"`
+	Timestamp        time.Time   `json:"timestamp"`
+	Network          string      `json:"network"`
+	Removed          bool        `json:"removed"` // 为true时表示因链重组撤回此前发布的事件
+	Finality         string      `json:"finality,omitempty"`
 }
 
 // RiskAlert 表示风险告警
 type RiskAlert struct {
-	ID              string                 `json:"id"`
+	ID              string                 `json:"id"` // 见 AlertID、OpsAlertID
 	Type            string                 `json:"type"`
 	Level           string                 `json:"level"`
 	Title           string                 `json:"title"`
@@ -97,16 +307,132 @@ type RiskAlert struct {
 	Status          string                 `json:"status"`
 }
 
+// 网络运行状态
+const (
+	NetworkStatusActive   = "ACTIVE"
+	NetworkStatusDisabled = "DISABLED"
+)
+
+// 网络初始化状态
+const (
+	NetworkInitPending  = "PENDING"
+	NetworkInitRetrying = "RETRYING"
+	NetworkInitReady    = "READY"
+	NetworkInitFailed   = "FAILED"
+)
+
+// NetworkInitStatus 表示网络初始化状态
+type NetworkInitStatus struct {
+	Network     string     `json:"network"`
+	State       string     `json:"state"`
+	Attempts    int        `json:"attempts"`
+	LastError   string     `json:"last_error,omitempty"`
+	LastAttempt *time.Time `json:"last_attempt,omitempty"`
+	ReadyAt     *time.Time `json:"ready_at,omitempty"`
+}
+
+// NetworkLogFilter 网络生效的合约日志过滤条件
+type NetworkLogFilter struct {
+	Network     string   `json:"network"`
+	Enabled     bool     `json:"enabled"`      // 是否按过滤条件处理日志
+	Source      string   `json:"source"`       // 条件来源：global/network/runtime
+	Addresses   []string `json:"addresses"`    // 为空表示不限
+	Topics      []string `json:"topics"`       // 事件签名(topic0)，为空表示不限
+	WatchGroups bool     `json:"watch_groups"` // 关注组的条件是否一并加入
+}
+
 // NetworkStats 表示网络统计信息
 type NetworkStats struct {
 	Network          string    `json:"network"`
 	LatestBlock      uint64    `json:"latest_block"`
+	ChainHead        uint64    `json:"chain_head"`
+	BlockLag         uint64    `json:"block_lag"`
 	TotalTxProcessed uint64    `json:"total_tx_processed"`
 	TxPerSecond      float64   `json:"tx_per_second"`
 	AvgBlockTime     float64   `json:"avg_block_time"`
 	LastUpdateTime   time.Time `json:"last_update_time"`
 	IsHealthy        bool      `json:"is_healthy"`
 	ErrorCount       uint64    `json:"error_count"`
+	Status           string    `json:"status"`
+	DisabledReason   string    `json:"disabled_reason,omitempty"`
+	DisabledAt       *time.Time `json:"disabled_at,omitempty"`
+	LastProcessedAt  *time.Time `json:"last_processed_at,omitempty"` // 最后处理区块最近一次前进的时间
+}
+
+// PipelineStage 处理流水线中的一个阶段及其运行统计
+type PipelineStage struct {
+	Name         string     `json:"name"`
+	Kind         string     `json:"kind"` // collector/processor/sink
+	Enabled      bool       `json:"enabled"`
+	Next         []string   `json:"next,omitempty"`
+	QueueDepth   int64      `json:"queue_depth"` // 等待该阶段处理的数量，无法统计时为-1
+	Processed    uint64     `json:"processed"`
+	Errors       uint64     `json:"errors"`
+	Throughput   float64    `json:"throughput"` // 每秒处理次数
+	AvgLatencyMs float64    `json:"avg_latency_ms"`
+	LastRun      *time.Time `json:"last_run,omitempty"`
+}
+
+// NetworkPipeline 单个网络的处理流水线
+type NetworkPipeline struct {
+	Network string           `json:"network"`
+	Chain   string           `json:"chain"`
+	Status  string           `json:"status"`
+	Stages  []*PipelineStage `json:"stages"`
+}
+
+// SubscriptionHealth WebSocket订阅的运行状态
+type SubscriptionHealth struct {
+	Kind          string     `json:"kind"` // heads / logs / slots
+	Active        bool       `json:"active"`
+	Since         time.Time  `json:"since"` // 最近一次建立或断开的时间
+	LastMessageAt *time.Time `json:"last_message_at,omitempty"`
+	Messages      uint64     `json:"messages"`
+	Failures      uint64     `json:"failures"`
+	LastError     string     `json:"last_error,omitempty"`
+}
+
+// NetworkDiagnostics 单个网络的连接及订阅状态
+type NetworkDiagnostics struct {
+	Network        string                `json:"network"`
+	Connected      bool                  `json:"connected"`
+	LastBlock      uint64                `json:"last_block"`
+	ChainHead      uint64                `json:"chain_head"`
+	BlockLag       uint64                `json:"block_lag"`
+	HeadAgeSeconds float64               `json:"head_age_seconds"` // 链头未前进的时长
+	Stalled        bool                  `json:"stalled"`
+	ErrorCount     uint64                `json:"error_count"`
+	DownSince      *time.Time            `json:"down_since,omitempty"`
+	Subscriptions  []*SubscriptionHealth `json:"subscriptions"`
+}
+
+// RuntimeMemory 堆及栈内存
+type RuntimeMemory struct {
+	HeapAllocBytes  uint64 `json:"heap_alloc_bytes"`
+	HeapInuseBytes  uint64 `json:"heap_inuse_bytes"`
+	HeapObjects     uint64 `json:"heap_objects"`
+	StackInuseBytes uint64 `json:"stack_inuse_bytes"`
+	SysBytes        uint64 `json:"sys_bytes"`
+}
+
+// RuntimeGC 垃圾回收统计
+type RuntimeGC struct {
+	NumGC             uint32     `json:"num_gc"`
+	LastGC            *time.Time `json:"last_gc,omitempty"`
+	LastPauseSeconds  float64    `json:"last_pause_seconds"`
+	PauseTotalSeconds float64    `json:"pause_total_seconds"`
+	CPUFraction       float64    `json:"cpu_fraction"`
+	NextGCBytes       uint64     `json:"next_gc_bytes"`
+}
+
+// RuntimeDiagnostics 进程运行状态，用于在线排查卡死及泄漏
+type RuntimeDiagnostics struct {
+	Goroutines          int                   `json:"goroutines"`
+	CollectorGoroutines map[string]int64      `json:"collector_goroutines"` // 收集器各类协程的运行数量
+	QueueDepths         map[string]int64      `json:"queue_depths"`         // 各输出端的待发送数量，不支持统计时为-1
+	Memory              RuntimeMemory         `json:"memory"`
+	GC                  RuntimeGC             `json:"gc"`
+	Networks            []*NetworkDiagnostics `json:"networks"`
 }
 
 // ProcessingResult 表示数据处理结果
@@ -124,6 +450,8 @@ type FilterResult struct {
 	ShouldProcess   bool     `json:"should_process"`
 	FilteredReasons []string `json:"filtered_reasons"`
 	RiskScore       float64  `json:"risk_score"`
+	Duplicate       bool     `json:"duplicate,omitempty"` // 最近已处理过的交易
+	Matched         bool     `json:"matched,omitempty"`   // 被include_addresses或include规则命中
 }
 
 // ContractInfo 表示智能合约信息
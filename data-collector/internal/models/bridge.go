@@ -0,0 +1,44 @@
+package models
+
+import (
+	"math/big"
+	"time"
+)
+
+// 跨链桥协议
+const (
+	BridgeWormhole   = "wormhole"    // Wormhole代币桥
+	BridgeLayerZero  = "layerzero"   // LayerZero V2端点
+	BridgeOPStandard = "op_standard" // OP Stack（Optimism、Base等）L1↔L2标准桥
+)
+
+// 跨链转账方向
+const (
+	BridgeDeposit    = "deposit"    // 在源链发起
+	BridgeWithdrawal = "withdrawal" // 在目标链到账
+)
+
+// BridgeTransfer 跨链桥的一次存入（源链）或到账（目标链），两端的MessageID相同。
+// 到账记录找到对应的存入时，源链的发送方、金额等字段由存入记录补全
+type BridgeTransfer struct {
+	ID                    string    `json:"id"` // 见 BridgeTransferID
+	Network               string    `json:"network"`
+	Protocol              string    `json:"protocol"`
+	Direction             string    `json:"direction"`
+	Bridge                string    `json:"bridge"`                        // 发出事件的桥合约
+	SourceNetwork         string    `json:"source_network,omitempty"`      // 未监控的链为空
+	SourceChain           string    `json:"source_chain"`                  // 协议内的链ID，OP Stack为网络名称
+	DestinationNetwork    string    `json:"destination_network,omitempty"` // 未监控的链为空
+	DestinationChain      string    `json:"destination_chain"`
+	Sender                string    `json:"sender,omitempty"`
+	Recipient             string    `json:"recipient,omitempty"`
+	Token                 string    `json:"token,omitempty"`  // 源链代币，为空表示原生币或消息不含代币
+	Amount                *big.Int  `json:"amount,omitempty"` // Wormhole为规范化到8位小数的金额
+	MessageID             string    `json:"message_id"`
+	Sequence              uint64    `json:"sequence,omitempty"` // Wormhole序号或LayerZero nonce，OP Stack标准桥没有
+	Linked                bool      `json:"linked"`             // 到账记录是否找到对应的存入
+	SourceTransactionHash string    `json:"source_transaction_hash,omitempty"`
+	TransactionHash       string    `json:"transaction_hash"`
+	BlockNumber           uint64    `json:"block_number"`
+	Timestamp             time.Time `json:"timestamp"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// 区块连续性问题类型
+const (
+	BlockGapMissing        = "missing"         // 区块号不连续，中间的区块未处理
+	BlockGapParentMismatch = "parent_mismatch" // 父哈希与已处理的前一区块不符，前一区块已被链重组替换
+	BlockGapOutOfOrder     = "out_of_order"    // 区块晚于其后的区块处理，且不是排队重新获取的区块
+)
+
+// BlockGap 检测到的一次连续性问题
+type BlockGap struct {
+	Network   string `json:"network"`
+	Kind      string `json:"kind"`
+	FromBlock uint64 `json:"from_block"`
+	ToBlock   uint64 `json:"to_block"`
+	Queued    int    `json:"queued"` // 排队重新获取的区块数，超出排队上限的不重新获取
+	// 父哈希不符时为已处理的前一区块哈希及新区块的父哈希
+	ExpectedParent string    `json:"expected_parent,omitempty"`
+	ActualParent   string    `json:"actual_parent,omitempty"`
+	DetectedAt     time.Time `json:"detected_at"`
+}
+
+// NetworkContinuity 网络的区块连续性报告
+type NetworkContinuity struct {
+	Network      string     `json:"network"`
+	HighestBlock uint64     `json:"highest_block"`
+	Queued       []uint64   `json:"queued"` // 等待重新获取的区块
+	Missing      uint64     `json:"missing_total"`
+	Refetched    uint64     `json:"refetched_total"` // 重新获取并处理成功的区块
+	Abandoned    uint64     `json:"abandoned_total"` // 超出排队上限、重试用尽或被隔离的区块
+	OutOfOrder   uint64     `json:"out_of_order_total"`
+	RecentGaps   []BlockGap `json:"recent_gaps"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DeadLetter 表示发布到输出端失败的数据
+type DeadLetter struct {
+	ID       string          `json:"id,omitempty"`
+	Sink     string          `json:"sink"`
+	Kind     string          `json:"kind"`
+	Network  string          `json:"network"`
+	Payload  json.RawMessage `json:"payload"`
+	Error    string          `json:"error"`
+	Attempts int             `json:"attempts"`
+	FailedAt time.Time       `json:"failed_at"`
+}
+
+// DeadLetterReplayResult 表示死信重放结果
+type DeadLetterReplayResult struct {
+	Fetched  int      `json:"fetched"`
+	Replayed int      `json:"replayed"`
+	Failed   int      `json:"failed"`
+	Errors   []string `json:"errors,omitempty"`
+}
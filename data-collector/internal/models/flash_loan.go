@@ -0,0 +1,27 @@
+package models
+
+import "math/big"
+
+// 闪电贷协议
+const (
+	FlashLoanAaveV2    = "aave_v2"
+	FlashLoanAaveV3    = "aave_v3"
+	FlashLoanBalancer  = "balancer"
+	FlashLoanUniswapV2 = "uniswap_v2"
+	FlashLoanUniswapV3 = "uniswap_v3"
+)
+
+// FlashLoan 表示交易中的一笔闪电贷
+type FlashLoan struct {
+	TransactionHash string   `json:"transaction_hash"`
+	TransactionID   string   `json:"transaction_id"`
+	BlockNumber     uint64   `json:"block_number"`
+	Network         string   `json:"network"`
+	Protocol        string   `json:"protocol"`
+	Lender          string   `json:"lender"`             // 放贷合约（Aave Pool/Balancer Vault/Uniswap池）
+	Receiver        string   `json:"receiver,omitempty"` // 接收借款并执行回调的合约
+	Asset           string   `json:"asset,omitempty"`    // Uniswap池借出的代币需结合池子token0/token1确定
+	Amount          *big.Int `json:"amount"`
+	Premium         *big.Int `json:"premium,omitempty"`
+	Source          string   `json:"source"` // event / trace
+}
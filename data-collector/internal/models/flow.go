@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// FlowGraph 从源地址出发的资金流向图，边为原生币转账，只沿资金到达地址之后的转出继续追踪
+type FlowGraph struct {
+	Network   string     `json:"network"`
+	Source    string     `json:"source"`
+	Depth     int        `json:"depth"`
+	Nodes     []FlowNode `json:"nodes"`
+	Edges     []FlowEdge `json:"edges"`
+	Truncated bool       `json:"truncated"` // 达到节点数上限或某地址的转出超过单地址上限
+}
+
+// FlowNode 资金流经的地址，Hop为距源地址的最少跳数
+type FlowNode struct {
+	Address  string `json:"address"`
+	Hop      int    `json:"hop"`
+	Received string `json:"received"` // 图中流入的金额（wei）
+	Sent     string `json:"sent"`     // 图中流出的金额（wei）
+	Expanded bool   `json:"expanded"` // 是否已查询该地址的转出，超过深度或节点上限时为false
+}
+
+// FlowEdge 一笔转账
+type FlowEdge struct {
+	From            string    `json:"from"`
+	To              string    `json:"to"`
+	Value           string    `json:"value"` // wei
+	ValueNative     float64   `json:"value_native,omitempty"`
+	Hop             int       `json:"hop"` // 从1开始
+	TransactionHash string    `json:"transaction_hash"`
+	BlockNumber     uint64    `json:"block_number,omitempty"`
+	Timestamp       time.Time `json:"timestamp"`
+}
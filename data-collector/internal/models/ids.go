@@ -0,0 +1,70 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 记录ID由类型前缀、网络及区块哈希、交易序号、日志序号组成，同一数据重复处理时生成相同ID，
+// 下游可按ID幂等写入；区块哈希不同（重组）时ID不同。哈希按原样使用（Solana为区分大小写的base58）
+const (
+	RecordBlock       = "block"
+	RecordTransaction = "tx"
+	RecordEvent       = "event"
+	RecordTransfer    = "transfer"
+	RecordAlert       = "alert"
+	RecordGasStats    = "gas"
+	RecordWithdrawal  = "withdrawal"
+	RecordBridge      = "bridge"
+)
+
+// BlockID 区块ID
+func BlockID(network, blockHash string) string {
+	return recordID(RecordBlock, network, blockHash)
+}
+
+// TransactionID 交易ID
+func TransactionID(network, blockHash string, txIndex uint) string {
+	return recordID(RecordTransaction, network, blockHash, fmt.Sprint(txIndex))
+}
+
+// EventID 日志事件ID
+func EventID(network, blockHash string, txIndex, logIndex uint) string {
+	return recordID(RecordEvent, network, blockHash, fmt.Sprint(txIndex), fmt.Sprint(logIndex))
+}
+
+// TransferID 代币转账ID，与对应的Transfer日志事件序号相同
+func TransferID(network, blockHash string, txIndex, logIndex uint) string {
+	return recordID(RecordTransfer, network, blockHash, fmt.Sprint(txIndex), fmt.Sprint(logIndex))
+}
+
+// GasStatsID 区块gas统计ID
+func GasStatsID(network, blockHash string) string {
+	return recordID(RecordGasStats, network, blockHash)
+}
+
+// WithdrawalID 信标链提款ID，序号为提款的全局序号
+func WithdrawalID(network, blockHash string, index uint64) string {
+	return recordID(RecordWithdrawal, network, blockHash, fmt.Sprint(index))
+}
+
+// BridgeTransferID 跨链桥记录ID，与对应的日志事件序号相同
+func BridgeTransferID(network, blockHash string, txIndex, logIndex uint) string {
+	return recordID(RecordBridge, network, blockHash, fmt.Sprint(txIndex), fmt.Sprint(logIndex))
+}
+
+// AlertID 由触发记录派生的告警ID，同一记录触发的同类告警ID相同
+func AlertID(alertType, sourceID string) string {
+	return recordID(RecordAlert, strings.ToLower(alertType), sourceID)
+}
+
+// OpsAlertID 不关联链上记录的运维告警ID，按网络等来源及触发时间（秒）区分
+func OpsAlertID(alertType string, at time.Time, parts ...string) string {
+	parts = append([]string{strings.ToLower(alertType)}, parts...)
+	return recordID(RecordAlert, append(parts, fmt.Sprint(at.Unix()))...)
+}
+
+func recordID(kind string, parts ...string) string {
+	return kind + ":" + strings.Join(parts, ":")
+}
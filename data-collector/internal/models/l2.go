@@ -0,0 +1,86 @@
+package models
+
+import (
+	"math/big"
+	"time"
+)
+
+// L2网络类型
+const (
+	L2Arbitrum = "arbitrum"
+	L2Optimism = "optimism" // OP Stack（OP Mainnet、Base等）
+)
+
+// Arbitrum由L1消息或定序器产生的系统交易类型
+const (
+	ArbitrumDepositTx  = "deposit"  // L1存入ETH
+	ArbitrumUnsignedTx = "unsigned" // L1合约发起的无签名交易
+	ArbitrumContractTx = "contract" // L1合约以别名地址发起的交易
+	ArbitrumRetryTx    = "retry"    // 可重试票据的兑付执行
+	ArbitrumSubmitTx   = "submit_retryable"
+	ArbitrumInternalTx = "internal" // ArbOS内部交易，如每个区块开头的StartBlock
+)
+
+// L2TransactionInfo L2交易特有的字段：L1数据费用、OP Stack存款交易及Arbitrum系统交易
+type L2TransactionInfo struct {
+	Type string `json:"type"` // arbitrum / optimism
+	// L1数据费用，需获取回执：OP Stack取自回执l1Fee；Arbitrum为gasUsedForL1按实际gas单价折算，已包含在gas_used中
+	L1Fee *big.Int `json:"l1_fee,omitempty"`
+	// 交易总费用，OP Stack为L2执行费用加L1数据费用
+	TotalFee *big.Int `json:"total_fee,omitempty"`
+	// OP Stack回执中的L1费用参数，Ecotone起使用base fee及blob base fee两个系数
+	L1GasUsed           uint64   `json:"l1_gas_used,omitempty"`
+	L1GasPrice          *big.Int `json:"l1_gas_price,omitempty"`
+	L1FeeScalar         string   `json:"l1_fee_scalar,omitempty"`
+	L1BlobBaseFee       *big.Int `json:"l1_blob_base_fee,omitempty"`
+	L1BaseFeeScalar     uint64   `json:"l1_base_fee_scalar,omitempty"`
+	L1BlobBaseFeeScalar uint64   `json:"l1_blob_base_fee_scalar,omitempty"`
+	// Arbitrum回执中用于支付L1数据的gas及交易对应的L1区块号
+	GasUsedForL1  uint64 `json:"gas_used_for_l1,omitempty"`
+	L1BlockNumber uint64 `json:"l1_block_number,omitempty"`
+	// OP Stack存款交易，含每个区块开头的L1属性系统交易
+	Deposit *OptimismDeposit `json:"deposit,omitempty"`
+	// Arbitrum系统交易类型（见 Arbitrum*Tx）及对应的L1消息请求ID
+	ArbitrumKind string `json:"arbitrum_kind,omitempty"`
+	RequestID    string `json:"request_id,omitempty"`
+	// Arbitrum可重试票据的创建或兑付
+	Retryable *ArbitrumRetryable `json:"retryable,omitempty"`
+}
+
+// OptimismDeposit OP Stack存款交易
+type OptimismDeposit struct {
+	SourceHash string   `json:"source_hash"`
+	Mint       *big.Int `json:"mint,omitempty"` // 在L2铸造的ETH
+	IsSystemTx bool     `json:"is_system_tx"`
+}
+
+// ArbitrumRetryable Arbitrum可重试票据。提交交易的哈希即票据ID，兑付交易引用该ID
+type ArbitrumRetryable struct {
+	TicketID            string   `json:"ticket_id"`
+	To                  string   `json:"to,omitempty"`
+	Value               *big.Int `json:"value,omitempty"`
+	DepositValue        *big.Int `json:"deposit_value,omitempty"` // 提交时从L1存入的ETH
+	MaxSubmissionFee    *big.Int `json:"max_submission_fee,omitempty"`
+	SubmissionFeeRefund *big.Int `json:"submission_fee_refund,omitempty"`
+	MaxRefund           *big.Int `json:"max_refund,omitempty"`
+	L1BaseFee           *big.Int `json:"l1_base_fee,omitempty"`
+	Beneficiary         string   `json:"beneficiary,omitempty"` // 票据过期或取消时接收退款的地址
+	RefundTo            string   `json:"refund_to,omitempty"`
+}
+
+// L2BlockInfo L2区块对应的L1起源及批次信息，用于定序器监控及成本核算
+type L2BlockInfo struct {
+	Type          string     `json:"type"`
+	L1BlockNumber uint64     `json:"l1_block_number"`
+	L1BlockHash   string     `json:"l1_block_hash,omitempty"`
+	L1Timestamp   *time.Time `json:"l1_timestamp,omitempty"`
+	L1BaseFee     *big.Int   `json:"l1_base_fee,omitempty"`
+	L1BlobBaseFee *big.Int   `json:"l1_blob_base_fee,omitempty"`
+	// OP Stack：同一L1起源内的L2区块序号及提交批次的batcher
+	SequenceNumber uint64 `json:"sequence_number,omitempty"`
+	BatcherHash    string `json:"batcher_hash,omitempty"`
+	// L2区块时间领先L1起源区块时间的秒数
+	SequencerDrift float64 `json:"sequencer_drift_seconds,omitempty"`
+	// 区块内交易的L1数据费用合计，需获取回执
+	L1Fees *big.Int `json:"l1_fees,omitempty"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// QuarantineEntry 表示处理阶段发生panic或反复处理失败而被隔离的数据
+type QuarantineEntry struct {
+	ID            string          `json:"id,omitempty"`
+	Stage         string          `json:"stage"`
+	Network       string          `json:"network"`
+	Key           string          `json:"key,omitempty"` // 数据标识，如交易哈希、区块号
+	Payload       json.RawMessage `json:"payload,omitempty"`
+	Panic         string          `json:"panic,omitempty"`
+	Stack         string          `json:"stack,omitempty"`
+	Error         string          `json:"error,omitempty"`
+	Failures      int             `json:"failures"`
+	QuarantinedAt time.Time       `json:"quarantined_at"`
+}
@@ -0,0 +1,22 @@
+package models
+
+// RPCCostSummary 表示网络当日的RPC调用成本
+type RPCCostSummary struct {
+	Network      string            `json:"network"`
+	Provider     string            `json:"provider"`
+	Calls        map[string]uint64 `json:"calls"`
+	SpentUSD     float64           `json:"spent_usd"`
+	ProjectedUSD float64           `json:"projected_usd"`
+	BudgetUSD    float64           `json:"budget_usd,omitempty"`
+	OverBudget   bool              `json:"over_budget"`
+}
+
+// RPCCostReport 表示当日（UTC）全部网络的RPC调用成本
+type RPCCostReport struct {
+	Date         string                     `json:"date"`
+	Networks     map[string]*RPCCostSummary `json:"networks"`
+	SpentUSD     float64                    `json:"spent_usd"`
+	ProjectedUSD float64                    `json:"projected_usd"`
+	BudgetUSD    float64                    `json:"budget_usd,omitempty"`
+	OverBudget   bool                       `json:"over_budget"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// 函数签名来源
+const (
+	SignatureSourceBuiltin = "builtin" // 内置常用签名
+	SignatureSourceSeed    = "seed"    // openchain/4byte导出文件
+	SignatureSourceAPI     = "api"     // 通过 /api/v1/admin/signatures 添加
+)
+
+// MethodSignature 函数选择器（调用数据的前4字节）对应的文本签名
+type MethodSignature struct {
+	Selector  string    `json:"selector"`  // 如 0xa9059cbb
+	Signature string    `json:"signature"` // 如 transfer(address,uint256)
+	Name      string    `json:"name"`      // 如 transfer
+	Source    string    `json:"source"`
+	AddedBy   string    `json:"added_by,omitempty"`
+	AddedAt   time.Time `json:"added_at,omitempty"`
+}
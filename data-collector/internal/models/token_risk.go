@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// 代币合约的跑路/貔貅风险特征
+const (
+	TokenRiskOwnerActive     = "ownership_not_renounced" // 存在owner且未放弃所有权
+	TokenRiskMint            = "mint_function"           // 可增发
+	TokenRiskTradingControl  = "trading_control"         // 可开关交易或暂停转账
+	TokenRiskBlacklist       = "blacklist_function"      // 可禁止指定地址转账
+	TokenRiskFeeControl      = "fee_control"             // 可修改交易税或单笔上限
+	TokenRiskLiquidityPulled = "liquidity_pulled"        // 交易对流动性被大比例撤出
+)
+
+// TokenRiskProfile 代币合约的风险分析结果
+type TokenRiskProfile struct {
+	Network        string   `json:"network"`
+	Token          string   `json:"token"`
+	Creator        string   `json:"creator,omitempty"` // 合约创建交易的发起方，首次交互时才分析的代币为空
+	CreationTx     string   `json:"creation_tx,omitempty"`
+	Owner          string   `json:"owner,omitempty"`
+	OwnerRenounced bool     `json:"owner_renounced"` // owner为零地址或合约没有owner
+	Flags          []string `json:"flags"`
+	Score          float64  `json:"score"`
+	Flagged        bool     `json:"flagged"` // 风险分达到配置的阈值
+	// 最近一次流动性撤出
+	LiquidityPair      string     `json:"liquidity_pair,omitempty"`
+	LiquidityPullTx    string     `json:"liquidity_pull_tx,omitempty"`
+	LiquidityPullRatio float64    `json:"liquidity_pull_ratio,omitempty"`
+	LiquidityPulledAt  *time.Time `json:"liquidity_pulled_at,omitempty"`
+	AnalyzedBlock      uint64     `json:"analyzed_block"`
+	AnalyzedAt         time.Time  `json:"analyzed_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Watchlist 用户注册的地址关注列表，涉及其中地址的交易及代币转账立即生成WATCHLIST告警
+type Watchlist struct {
+	ID        string           `json:"id"`
+	Name      string           `json:"name"`
+	Owner     string           `json:"owner,omitempty"`    // 创建者，未启用认证时为空
+	Networks  []string         `json:"networks,omitempty"` // 为空表示全部网络
+	Addresses []WatchedAddress `json:"addresses"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// WatchedAddress 关注的地址
+type WatchedAddress struct {
+	Address string `json:"address"`
+	Label   string `json:"label,omitempty"`
+	Level   string `json:"level,omitempty"` // 告警级别，为空时为MEDIUM
+}
@@ -0,0 +1,226 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultCoinGeckoURL   = "https://api.coingecko.com/api/v3"
+	defaultCacheTTL       = 5 * time.Minute
+	defaultRequestTimeout = 5 * time.Second
+	// 查询失败后的短期缓存，避免频繁请求外部接口
+	failureCacheTTL = time.Minute
+)
+
+// cachedPrice 进程内缓存的价格
+type cachedPrice struct {
+	price     float64
+	ok        bool
+	expiresAt time.Time
+}
+
+// Service 代币美元价格服务（CoinGecko，Redis缓存）
+type Service struct {
+	baseURL    string
+	apiKey     string
+	cacheTTL   time.Duration
+	networks   map[string]config.NativeCurrencyConfig
+	redis      *database.RedisClient
+	httpClient *http.Client
+	cache      map[string]cachedPrice
+	mu         sync.RWMutex
+}
+
+// NewService 创建价格服务，未启用时返回nil
+func NewService(cfg config.PricingConfig, networks map[string]config.NetworkConfig, redisClient *database.RedisClient) *Service {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	service := &Service{
+		baseURL:    strings.TrimRight(cfg.CoinGeckoURL, "/"),
+		apiKey:     cfg.APIKey,
+		cacheTTL:   defaultCacheTTL,
+		networks:   make(map[string]config.NativeCurrencyConfig),
+		redis:      redisClient,
+		httpClient: &http.Client{Timeout: defaultRequestTimeout},
+		cache:      make(map[string]cachedPrice),
+	}
+
+	if service.baseURL == "" {
+		service.baseURL = defaultCoinGeckoURL
+	}
+
+	if cfg.CacheTTL != "" {
+		if ttl, err := time.ParseDuration(cfg.CacheTTL); err == nil {
+			service.cacheTTL = ttl
+		} else {
+			logrus.Warnf("Invalid pricing cache_ttl %q, using %v", cfg.CacheTTL, service.cacheTTL)
+		}
+	}
+
+	if cfg.RequestTimeout != "" {
+		if timeout, err := time.ParseDuration(cfg.RequestTimeout); err == nil {
+			service.httpClient.Timeout = timeout
+		} else {
+			logrus.Warnf("Invalid pricing request_timeout %q, using %v", cfg.RequestTimeout, service.httpClient.Timeout)
+		}
+	}
+
+	for name, networkCfg := range networks {
+		service.networks[name] = networkCfg.NativeCurrency
+	}
+
+	logrus.Infof("Price service enabled (source: %s, cache ttl: %v)", service.baseURL, service.cacheTTL)
+	return service
+}
+
+// NativePriceUSD 获取网络原生币美元价格
+func (s *Service) NativePriceUSD(network string) (float64, bool) {
+	coinID := s.networks[network].CoinGeckoID
+	if coinID == "" {
+		return 0, false
+	}
+
+	return s.getPrice("coin:"+coinID, func() (float64, error) {
+		return s.fetchCoinPrice(coinID)
+	})
+}
+
+// TokenPriceUSD 获取代币合约美元价格
+func (s *Service) TokenPriceUSD(network, contract string) (float64, bool) {
+	platform := s.networks[network].PricePlatform
+	if platform == "" || contract == "" {
+		return 0, false
+	}
+
+	contract = strings.ToLower(contract)
+	return s.getPrice(fmt.Sprintf("token:%s:%s", platform, contract), func() (float64, error) {
+		return s.fetchTokenPrice(platform, contract)
+	})
+}
+
+// TokenValueUSD 将代币最小单位数量换算为美元价值
+func (s *Service) TokenValueUSD(network, contract string, amount *big.Int, decimals uint8) (float64, bool) {
+	if amount == nil {
+		return 0, false
+	}
+
+	price, ok := s.TokenPriceUSD(network, contract)
+	if !ok {
+		return 0, false
+	}
+
+	unit := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	units, _ := new(big.Float).Quo(new(big.Float).SetInt(amount), unit).Float64()
+	return units * price, true
+}
+
+// getPrice 依次查询进程内缓存、Redis缓存和外部接口
+func (s *Service) getPrice(key string, fetch func() (float64, error)) (float64, bool) {
+	now := time.Now()
+
+	s.mu.RLock()
+	cached, exists := s.cache[key]
+	s.mu.RUnlock()
+	if exists && now.Before(cached.expiresAt) {
+		return cached.price, cached.ok
+	}
+
+	redisKey := "price:usd:" + key
+	if value, err := s.redis.Get(redisKey); err == nil {
+		if price, err := strconv.ParseFloat(value, 64); err == nil {
+			s.store(key, price, true, s.cacheTTL)
+			return price, true
+		}
+	}
+
+	price, err := fetch()
+	if err != nil {
+		logrus.Warnf("Failed to fetch price for %s: %v", key, err)
+		s.store(key, 0, false, failureCacheTTL)
+		return 0, false
+	}
+
+	if err := s.redis.Set(redisKey, strconv.FormatFloat(price, 'f', -1, 64), s.cacheTTL); err != nil {
+		logrus.Warnf("Failed to cache price for %s: %v", key, err)
+	}
+	s.store(key, price, true, s.cacheTTL)
+
+	return price, true
+}
+
+// store 写入进程内缓存
+func (s *Service) store(key string, price float64, ok bool, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cache[key] = cachedPrice{
+		price:     price,
+		ok:        ok,
+		expiresAt: time.Now().Add(ttl),
+	}
+}
+
+// fetchCoinPrice 查询CoinGecko币种价格
+func (s *Service) fetchCoinPrice(coinID string) (float64, error) {
+	endpoint := fmt.Sprintf("%s/simple/price?ids=%s&vs_currencies=usd", s.baseURL, url.QueryEscape(coinID))
+	return s.fetchUSD(endpoint, coinID)
+}
+
+// fetchTokenPrice 查询CoinGecko代币合约价格
+func (s *Service) fetchTokenPrice(platform, contract string) (float64, error) {
+	endpoint := fmt.Sprintf("%s/simple/token_price/%s?contract_addresses=%s&vs_currencies=usd",
+		s.baseURL, url.PathEscape(platform), url.QueryEscape(contract))
+	return s.fetchUSD(endpoint, contract)
+}
+
+// fetchUSD 请求价格接口并解析指定条目的美元价格
+func (s *Service) fetchUSD(endpoint, id string) (float64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.httpClient.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, err
+	}
+	if s.apiKey != "" {
+		req.Header.Set("x-cg-pro-api-key", s.apiKey)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var result map[string]map[string]float64
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("failed to decode price response: %w", err)
+	}
+
+	price, exists := result[id]["usd"]
+	if !exists {
+		return 0, fmt.Errorf("no usd price for %s", id)
+	}
+
+	return price, nil
+}
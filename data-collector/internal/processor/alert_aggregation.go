@@ -0,0 +1,305 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/metrics"
+	"web3-data-collector/internal/models"
+)
+
+// maxAggregatedSamples 汇总告警中保留的被抑制交易哈希或地址数
+const maxAggregatedSamples = 10
+
+// alertLevelRanks 告警级别高低，汇总告警取组内最高级别
+var alertLevelRanks = map[string]int{
+	"LOW":      1,
+	"MEDIUM":   2,
+	"HIGH":     3,
+	"CRITICAL": 4,
+}
+
+// 告警被抑制的原因
+const (
+	alertSuppressedAggregated  = "aggregated"   // 同组告警超过窗口内照常发布的数量
+	alertSuppressedRateLimited = "rate_limited" // 网络告警速率超过限制
+)
+
+// AlertAggregator 告警聚合：同一(类型, 地址, 网络)在窗口内只照常发布前pass_through条，
+// 其余计数后在窗口结束时合并为一条汇总告警；各网络按令牌桶限速，超出的告警按(类型, 网络)汇总
+type AlertAggregator struct {
+	window         time.Duration
+	passThrough    int
+	rate           float64 // 每个网络每秒允许发布的告警数，0为不限速
+	burst          float64
+	exclude        map[string]bool
+	groups         map[string]*alertGroup
+	limiters       map[string]*alertLimiter
+	publish        func(*models.RiskAlert) error
+	metricsManager *metrics.Manager
+	mu             sync.Mutex
+}
+
+// alertGroup 一组告警在当前窗口内的状态
+type alertGroup struct {
+	template   *models.RiskAlert // 组内第一条告警，作为汇总告警的模板
+	reason     string
+	start      time.Time
+	last       time.Time
+	total      int
+	published  int
+	suppressed int
+	level      string
+	maxScore   float64
+	samples    []string // 被抑制告警的交易哈希，限速汇总时为地址
+}
+
+// alertLimiter 单个网络的告警发布令牌桶
+type alertLimiter struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewAlertAggregator 根据配置创建告警聚合器，publish为实际发布告警的函数；未启用时返回nil
+func NewAlertAggregator(cfg config.AlertAggregationConfig, publish func(*models.RiskAlert) error, metricsManager *metrics.Manager) (*AlertAggregator, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	window := 5 * time.Minute
+	if cfg.Window != "" {
+		parsed, err := time.ParseDuration(cfg.Window)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid alert aggregation window %q", cfg.Window)
+		}
+		window = parsed
+	}
+
+	aggregator := &AlertAggregator{
+		window:         window,
+		passThrough:    cfg.PassThrough,
+		rate:           cfg.RateLimit,
+		burst:          float64(cfg.Burst),
+		exclude:        make(map[string]bool, len(cfg.ExcludeTypes)),
+		groups:         make(map[string]*alertGroup),
+		limiters:       make(map[string]*alertLimiter),
+		publish:        publish,
+		metricsManager: metricsManager,
+	}
+	if aggregator.burst < 1 {
+		aggregator.burst = 1
+	}
+	for _, alertType := range cfg.ExcludeTypes {
+		aggregator.exclude[alertType] = true
+	}
+
+	logger.Infof("Alert aggregation enabled (window: %v, pass_through: %d, rate_limit: %g/s)", window, cfg.PassThrough, cfg.RateLimit)
+	return aggregator, nil
+}
+
+// Publish 按聚合及限速规则发布告警，被抑制的告警计入所在组，由汇总告警体现
+func (a *AlertAggregator) Publish(alert *models.RiskAlert) error {
+	if a.exclude[alert.Type] {
+		return a.publish(alert)
+	}
+
+	now := time.Now()
+	var expired []*alertGroup
+
+	a.mu.Lock()
+	key := alertGroupKey(alertSuppressedAggregated, alert.Type, alert.Address, alert.Network)
+	group, expiredGroup := a.group(key, alert, alertSuppressedAggregated, now)
+	if expiredGroup != nil {
+		expired = append(expired, expiredGroup)
+	}
+
+	reason := ""
+	switch {
+	case group.published >= a.passThrough:
+		reason = alertSuppressedAggregated
+		group.add(alert, now)
+		group.suppress(alert.TransactionHash)
+	case !a.allow(alert.Network, now):
+		// 限速的告警按(类型, 网络)汇总，避免大量不同地址各自产生汇总告警
+		reason = alertSuppressedRateLimited
+		overflowKey := alertGroupKey(alertSuppressedRateLimited, alert.Type, "", alert.Network)
+		overflow, expiredOverflow := a.group(overflowKey, alert, alertSuppressedRateLimited, now)
+		if expiredOverflow != nil {
+			expired = append(expired, expiredOverflow)
+		}
+		overflow.add(alert, now)
+		overflow.suppress(alert.Address)
+	default:
+		group.add(alert, now)
+		group.published++
+	}
+	a.mu.Unlock()
+
+	a.flush(expired)
+	if reason != "" {
+		a.metricsManager.RecordSuppressedAlert(alert.Network, alert.Type, reason)
+		return nil
+	}
+	return a.publish(alert)
+}
+
+// Run 定期发布窗口已结束的汇总告警，退出时发布全部未结束的汇总
+func (a *AlertAggregator) Run(ctx context.Context) {
+	interval := a.window / 10
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			a.flush(a.take(func(*alertGroup) bool { return true }))
+			return
+		case now := <-ticker.C:
+			a.flush(a.take(func(group *alertGroup) bool {
+				return now.Sub(group.start) >= a.window
+			}))
+		}
+	}
+}
+
+// group 获取当前窗口的告警组，已过窗口的组被替换并返回供发布汇总，调用方需持有锁
+func (a *AlertAggregator) group(key string, alert *models.RiskAlert, reason string, now time.Time) (*alertGroup, *alertGroup) {
+	group, exists := a.groups[key]
+	if exists && now.Sub(group.start) < a.window {
+		return group, nil
+	}
+
+	template := *alert
+	if reason == alertSuppressedRateLimited {
+		template.Address = ""
+	}
+	a.groups[key] = &alertGroup{
+		template: &template,
+		reason:   reason,
+		start:    now,
+	}
+	return a.groups[key], group
+}
+
+// allow 消耗网络的一个发布令牌，令牌不足时返回false
+func (a *AlertAggregator) allow(network string, now time.Time) bool {
+	if a.rate <= 0 {
+		return true
+	}
+
+	limiter, exists := a.limiters[network]
+	if !exists {
+		limiter = &alertLimiter{tokens: a.burst, updated: now}
+		a.limiters[network] = limiter
+	}
+	limiter.tokens += now.Sub(limiter.updated).Seconds() * a.rate
+	if limiter.tokens > a.burst {
+		limiter.tokens = a.burst
+	}
+	limiter.updated = now
+
+	if limiter.tokens < 1 {
+		return false
+	}
+	limiter.tokens--
+	return true
+}
+
+// take 移除满足条件的告警组
+func (a *AlertAggregator) take(match func(*alertGroup) bool) []*alertGroup {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var taken []*alertGroup
+	for key, group := range a.groups {
+		if match(group) {
+			taken = append(taken, group)
+			delete(a.groups, key)
+		}
+	}
+	return taken
+}
+
+// flush 为有被抑制告警的组发布汇总告警
+func (a *AlertAggregator) flush(groups []*alertGroup) {
+	for _, group := range groups {
+		if group.suppressed == 0 {
+			continue
+		}
+		summary := group.summary(a.window)
+		if err := a.publish(summary); err != nil {
+			logger.Errorf("Failed to publish aggregated %s alert for %s: %v", summary.Type, summary.Network, err)
+		}
+	}
+}
+
+// add 计入一条告警
+func (g *alertGroup) add(alert *models.RiskAlert, now time.Time) {
+	g.total++
+	g.last = now
+	if alertLevelRanks[alert.Level] > alertLevelRanks[g.level] {
+		g.level = alert.Level
+	}
+	if alert.RiskScore > g.maxScore {
+		g.maxScore = alert.RiskScore
+	}
+}
+
+// suppress 记录一条被抑制的告警
+func (g *alertGroup) suppress(sample string) {
+	g.suppressed++
+	if sample != "" && len(g.samples) < maxAggregatedSamples {
+		g.samples = append(g.samples, sample)
+	}
+}
+
+// summary 创建汇总告警，类型与组内告警相同，元数据中标明聚合数量
+func (g *alertGroup) summary(window time.Duration) *models.RiskAlert {
+	summary := *g.template
+	summary.ID = models.OpsAlertID(g.template.Type, g.start, g.template.Network, g.template.Address, g.reason)
+	summary.Level = g.level
+	summary.RiskScore = g.maxScore
+	summary.TransactionHash = ""
+	summary.Timestamp = g.last
+	summary.Status = "ACTIVE"
+
+	sampleKey := "sample_transactions"
+	if g.reason == alertSuppressedRateLimited {
+		sampleKey = "sample_addresses"
+		summary.Title = fmt.Sprintf("%d %s alerts rate limited on %s", g.suppressed, g.template.Type, g.template.Network)
+		summary.Description = fmt.Sprintf("%d %s alerts on %s exceeded the alert rate limit between %s and %s and were not published individually",
+			g.suppressed, g.template.Type, g.template.Network, g.start.Format(time.RFC3339), g.last.Format(time.RFC3339))
+	} else {
+		target := g.template.Network
+		if g.template.Address != "" {
+			target = g.template.Address + " on " + target
+		}
+		summary.Title = fmt.Sprintf("%s (%d alerts aggregated)", g.template.Title, g.total)
+		summary.Description = fmt.Sprintf("%d %s alerts for %s between %s and %s, %d not published individually",
+			g.total, g.template.Type, target, g.start.Format(time.RFC3339), g.last.Format(time.RFC3339), g.suppressed)
+	}
+
+	summary.Metadata = map[string]interface{}{
+		"aggregated":       true,
+		"aggregate_reason": g.reason,
+		"alert_count":      g.total,
+		"suppressed_count": g.suppressed,
+		"window":           window.String(),
+		"window_start":     g.start,
+		"window_end":       g.last,
+		"first_alert_id":   g.template.ID,
+		sampleKey:          g.samples,
+	}
+	return &summary
+}
+
+// alertGroupKey 告警组的键，限速汇总组的地址为空
+func alertGroupKey(reason, alertType, address, network string) string {
+	return reason + "|" + alertType + "|" + address + "|" + network
+}
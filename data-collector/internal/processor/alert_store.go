@@ -0,0 +1,399 @@
+package processor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/models"
+)
+
+// 告警记录在Redis中的键
+const (
+	alertRecordKeyPrefix = "alert_record"  // 告警记录，值为AlertRecord的JSON，按保留时长过期
+	alertIndexKey        = "alert_index"   // 告警ID有序集合，分数为告警时间
+	alertSnoozesKey      = "alert_snoozes" // 地址静默哈希，字段为 {network}:{address}，值为AlertSnooze的JSON
+)
+
+// ErrAlertResolved 告警已解决，不能再确认或解决
+var ErrAlertResolved = errors.New("alert is already resolved")
+
+// alertSnoozeCacheTTL 地址静默的本地缓存时间，其他实例的变更最迟在该时间后生效
+const alertSnoozeCacheTTL = 10 * time.Second
+
+// alertListBatch 查询告警时每次从索引读取的数量
+const alertListBatch = 200
+
+// maxAlertListLimit 单次查询返回的最大告警数
+const maxAlertListLimit = 1000
+
+// maxAlertListScan 单次查询最多检查的告警数，过滤条件命中很少时避免遍历整个保留期
+const maxAlertListScan = 20000
+
+// AlertStore 保存已发布的告警及确认、解决、按地址静默等处理记录
+type AlertStore struct {
+	client    *database.RedisClient
+	retention time.Duration
+	snoozes   map[string]*models.AlertSnooze
+	loadedAt  time.Time
+	mu        sync.Mutex
+}
+
+// NewAlertStore 根据配置创建告警存储，未启用时返回nil
+func NewAlertStore(cfg config.AlertStoreConfig, redisClient *database.RedisClient) (*AlertStore, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.Backend != "" && cfg.Backend != "redis" {
+		return nil, fmt.Errorf("unsupported alert store backend: %s", cfg.Backend)
+	}
+
+	retention := 30 * 24 * time.Hour
+	if cfg.Retention != "" {
+		parsed, err := time.ParseDuration(cfg.Retention)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid alert store retention %q", cfg.Retention)
+		}
+		retention = parsed
+	}
+
+	logger.Infof("Alert store enabled (backend: redis, retention: %v)", retention)
+	return &AlertStore{client: redisClient, retention: retention}, nil
+}
+
+// Record 保存新发布的告警，已存在的记录（如重放同一交易）保留原有处理状态
+func (s *AlertStore) Record(alert *models.RiskAlert) error {
+	return s.record(&models.AlertRecord{RiskAlert: *alert})
+}
+
+// Snoozed 判断告警的地址是否处于静默期，是则以SNOOZED状态保存并返回true，调用方不再推送
+func (s *AlertStore) Snoozed(alert *models.RiskAlert) (bool, error) {
+	if alert.Address == "" {
+		return false, nil
+	}
+	snoozes, err := s.currentSnoozes()
+	if err != nil {
+		return false, err
+	}
+
+	snooze, exists := snoozes[alertSnoozeID(alert.Network, alert.Address)]
+	if !exists || !time.Now().Before(snooze.Until) {
+		return false, nil
+	}
+	if len(snooze.Types) > 0 && !containsString(snooze.Types, alert.Type) {
+		return false, nil
+	}
+
+	record := &models.AlertRecord{RiskAlert: *alert, SnoozeID: snooze.ID}
+	record.Status = models.AlertStatusSnoozed
+	return true, s.record(record)
+}
+
+// Get 获取告警记录
+func (s *AlertStore) Get(id string) (*models.AlertRecord, bool, error) {
+	value, exists, err := s.client.GetIfExists(alertRecordKey(id))
+	if err != nil || !exists {
+		return nil, false, err
+	}
+
+	var record models.AlertRecord
+	if err := json.Unmarshal([]byte(value), &record); err != nil {
+		return nil, false, fmt.Errorf("invalid alert record %s: %w", id, err)
+	}
+	return &record, true, nil
+}
+
+// List 按时间从新到旧查询告警
+func (s *AlertStore) List(query models.AlertQuery) ([]*models.AlertRecord, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > maxAlertListLimit {
+		limit = maxAlertListLimit
+	}
+
+	now := time.Now()
+	if err := s.client.ZRemRangeByScore(alertIndexKey, "-inf", strconv.FormatInt(now.Add(-s.retention).Unix(), 10)); err != nil {
+		logger.Warnf("Failed to prune alert index: %v", err)
+	}
+
+	max, min := "+inf", "-inf"
+	if !query.Until.IsZero() {
+		max = strconv.FormatInt(query.Until.Unix(), 10)
+	}
+	if !query.Since.IsZero() {
+		min = strconv.FormatInt(query.Since.Unix(), 10)
+	}
+
+	address := normalizeWatchAddress(query.Address)
+	records := make([]*models.AlertRecord, 0, limit)
+	for offset := int64(0); len(records) < limit && offset < maxAlertListScan; offset += alertListBatch {
+		ids, err := s.client.ZRevRangeByScoreN(alertIndexKey, max, min, offset, alertListBatch)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		keys := make([]string, len(ids))
+		for i, id := range ids {
+			keys[i] = alertRecordKey(id)
+		}
+		values, err := s.client.MGet(keys...)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, value := range values {
+			data, ok := value.(string)
+			if !ok {
+				continue // 记录已过期
+			}
+			var record models.AlertRecord
+			if err := json.Unmarshal([]byte(data), &record); err != nil {
+				logger.Warnf("Skipping invalid alert record %s: %v", ids[i], err)
+				continue
+			}
+			if !alertMatches(&record, query, address) {
+				continue
+			}
+			records = append(records, &record)
+			if len(records) == limit {
+				break
+			}
+		}
+	}
+	return records, nil
+}
+
+// Acknowledge 确认告警，已解决的告警不能再确认
+func (s *AlertStore) Acknowledge(id, actor, note string) (*models.AlertRecord, bool, error) {
+	return s.update(id, models.AlertActionAcknowledge, actor, note, func(record *models.AlertRecord, now time.Time) error {
+		if record.Status == models.AlertStatusResolved {
+			return ErrAlertResolved
+		}
+		record.Status = models.AlertStatusAcknowledged
+		record.AcknowledgedBy = actor
+		record.AcknowledgedAt = &now
+		return nil
+	})
+}
+
+// Resolve 解决告警
+func (s *AlertStore) Resolve(id, actor, note string) (*models.AlertRecord, bool, error) {
+	return s.update(id, models.AlertActionResolve, actor, note, func(record *models.AlertRecord, now time.Time) error {
+		if record.Status == models.AlertStatusResolved {
+			return ErrAlertResolved
+		}
+		record.Status = models.AlertStatusResolved
+		record.ResolvedBy = actor
+		record.ResolvedAt = &now
+		return nil
+	})
+}
+
+// Snooze 静默网络上地址的告警，同一地址已有的静默被替换；该地址未处理的告警标记为SNOOZED
+func (s *AlertStore) Snooze(snooze *models.AlertSnooze) (*models.AlertSnooze, int, error) {
+	snooze.Address = normalizeWatchAddress(strings.TrimSpace(snooze.Address))
+	if snooze.Network == "" || snooze.Address == "" {
+		return nil, 0, fmt.Errorf("network and address are required")
+	}
+	if !snooze.Until.After(time.Now()) {
+		return nil, 0, fmt.Errorf("until must be in the future")
+	}
+	snooze.ID = alertSnoozeID(snooze.Network, snooze.Address)
+	snooze.CreatedAt = time.Now()
+
+	data, err := json.Marshal(snooze)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := s.client.HSet(alertSnoozesKey, snooze.ID, data); err != nil {
+		return nil, 0, err
+	}
+	s.invalidate()
+
+	open, err := s.List(models.AlertQuery{Network: snooze.Network, Address: snooze.Address, Status: models.AlertStatusActive, Limit: maxAlertListLimit})
+	if err != nil {
+		return snooze, 0, err
+	}
+	snoozed := 0
+	for _, record := range open {
+		if len(snooze.Types) > 0 && !containsString(snooze.Types, record.Type) {
+			continue
+		}
+		_, _, err := s.update(record.ID, models.AlertActionSnooze, snooze.CreatedBy, snooze.Reason, func(record *models.AlertRecord, now time.Time) error {
+			record.Status = models.AlertStatusSnoozed
+			record.SnoozeID = snooze.ID
+			return nil
+		})
+		if err != nil {
+			return snooze, snoozed, err
+		}
+		snoozed++
+	}
+	return snooze, snoozed, nil
+}
+
+// Snoozes 获取未过期的地址静默
+func (s *AlertStore) Snoozes() ([]*models.AlertSnooze, error) {
+	s.invalidate()
+	snoozes, err := s.currentSnoozes()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := make([]*models.AlertSnooze, 0, len(snoozes))
+	for _, snooze := range snoozes {
+		if now.Before(snooze.Until) {
+			result = append(result, snooze)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Until.Before(result[j].Until)
+	})
+	return result, nil
+}
+
+// Unsnooze 取消地址静默，之后的告警恢复推送
+func (s *AlertStore) Unsnooze(network, address string) (bool, error) {
+	id := alertSnoozeID(network, address)
+	values, err := s.client.HGetAll(alertSnoozesKey)
+	if err != nil {
+		return false, err
+	}
+	if _, exists := values[id]; !exists {
+		return false, nil
+	}
+	if err := s.client.HDel(alertSnoozesKey, id); err != nil {
+		return false, err
+	}
+	s.invalidate()
+	return true, nil
+}
+
+// record 保存告警记录并加入索引，记录已存在时不覆盖
+func (s *AlertStore) record(record *models.AlertRecord) error {
+	if record.Status == "" {
+		record.Status = models.AlertStatusActive
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	created, err := s.client.SetNX(alertRecordKey(record.ID), data, s.retention)
+	if err != nil || !created {
+		return err
+	}
+	return s.client.ZAdd(alertIndexKey, float64(record.Timestamp.Unix()), record.ID)
+}
+
+// update 修改告警记录并追加操作记录，保留原有过期时间
+func (s *AlertStore) update(id, action, actor, note string, apply func(*models.AlertRecord, time.Time) error) (*models.AlertRecord, bool, error) {
+	record, exists, err := s.Get(id)
+	if err != nil || !exists {
+		return nil, exists, err
+	}
+
+	now := time.Now()
+	if err := apply(record, now); err != nil {
+		return nil, true, err
+	}
+	record.History = append(record.History, models.AlertAction{Action: action, Actor: actor, Note: note, At: now})
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, true, err
+	}
+	ttl, err := s.client.TTL(alertRecordKey(id))
+	if err != nil {
+		return nil, true, err
+	}
+	if ttl <= 0 {
+		ttl = s.retention
+	}
+	if err := s.client.Set(alertRecordKey(id), data, ttl); err != nil {
+		return nil, true, err
+	}
+	return record, true, nil
+}
+
+// currentSnoozes 获取地址静默，缓存过期时从Redis重新加载
+func (s *AlertStore) currentSnoozes() (map[string]*models.AlertSnooze, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.snoozes != nil && time.Since(s.loadedAt) < alertSnoozeCacheTTL {
+		return s.snoozes, nil
+	}
+
+	values, err := s.client.HGetAll(alertSnoozesKey)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	snoozes := make(map[string]*models.AlertSnooze, len(values))
+	for id, value := range values {
+		var snooze models.AlertSnooze
+		if err := json.Unmarshal([]byte(value), &snooze); err != nil {
+			logger.Warnf("Skipping invalid alert snooze %s: %v", id, err)
+			continue
+		}
+		// 过期的静默顺带清理
+		if !now.Before(snooze.Until) {
+			if err := s.client.HDel(alertSnoozesKey, id); err != nil {
+				logger.Warnf("Failed to remove expired alert snooze %s: %v", id, err)
+			}
+			continue
+		}
+		snoozes[id] = &snooze
+	}
+
+	s.snoozes = snoozes
+	s.loadedAt = now
+	return snoozes, nil
+}
+
+// invalidate 使地址静默缓存失效
+func (s *AlertStore) invalidate() {
+	s.mu.Lock()
+	s.snoozes = nil
+	s.mu.Unlock()
+}
+
+// alertMatches 告警是否满足查询条件，address已规范化
+func alertMatches(record *models.AlertRecord, query models.AlertQuery, address string) bool {
+	switch {
+	case query.Network != "" && record.Network != query.Network:
+		return false
+	case query.Type != "" && record.Type != query.Type:
+		return false
+	case query.Level != "" && record.Level != query.Level:
+		return false
+	case query.Status != "" && record.Status != query.Status:
+		return false
+	case address != "" && normalizeWatchAddress(record.Address) != address:
+		return false
+	}
+	return true
+}
+
+func alertRecordKey(id string) string {
+	return alertRecordKeyPrefix + ":" + id
+}
+
+// alertSnoozeID 地址静默的ID，同一网络上的地址只有一个静默
+func alertSnoozeID(network, address string) string {
+	return network + ":" + normalizeWatchAddress(address)
+}
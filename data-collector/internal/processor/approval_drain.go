@@ -0,0 +1,213 @@
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/models"
+)
+
+// approvalKeyPrefix 待关联授权在Redis中的键前缀，按 网络:代币:持有人 存储，字段为被授权合约
+const approvalKeyPrefix = "approval_drain"
+
+// TokenApproval ERC-20 Approval事件
+type TokenApproval struct {
+	Network         string
+	Token           string
+	Owner           string
+	Spender         string
+	Amount          *big.Int
+	TransactionHash string
+	BlockNumber     uint64
+}
+
+// TokenTransfer ERC-20 Transfer事件及所在交易的发起方/调用目标
+type TokenTransfer struct {
+	ID              string // 见 models.TransferID
+	Network         string
+	Token           string
+	From            string
+	To              string
+	Amount          *big.Int
+	TxFrom          string
+	TxTo            string
+	TransactionHash string
+	BlockNumber     uint64
+	Timestamp       time.Time
+}
+
+// pendingApproval 已记录的对未知合约的授权
+type pendingApproval struct {
+	Amount          string `json:"amount"`
+	TransactionHash string `json:"transaction_hash"`
+	BlockNumber     uint64 `json:"block_number"`
+}
+
+// ApprovalDrain 持有人向未知合约授权后，余额被该合约通过transferFrom转走
+type ApprovalDrain struct {
+	Transfer         *TokenTransfer
+	Drainer          string   // 被授权的合约
+	ApprovalTx       string   // 授权交易
+	ApprovalBlock    uint64   // 授权所在区块
+	ApprovedAmount   *big.Int // 授权额度
+	RemainingBalance *big.Int // 转出后的余额，查询失败时为nil
+}
+
+// ApprovalDrainDetector 跨区块关联授权与转出，识别钱包盗取合约
+type ApprovalDrainDetector struct {
+	client  *database.RedisClient
+	window  time.Duration
+	trusted map[string]bool
+}
+
+// NewApprovalDrainDetector 根据配置创建授权盗取检测器，未启用时返回nil
+func NewApprovalDrainDetector(cfg config.ApprovalDrainConfig, redisClient *database.RedisClient) (*ApprovalDrainDetector, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	window := 72 * time.Hour
+	if cfg.Window != "" {
+		parsed, err := time.ParseDuration(cfg.Window)
+		if err != nil {
+			return nil, fmt.Errorf("invalid approval_drain window: %w", err)
+		}
+		window = parsed
+	}
+
+	trusted := make(map[string]bool, len(cfg.TrustedSpenders))
+	for _, spender := range cfg.TrustedSpenders {
+		trusted[strings.ToLower(spender)] = true
+	}
+
+	return &ApprovalDrainDetector{client: redisClient, window: window, trusted: trusted}, nil
+}
+
+// IsTrusted 判断被授权地址是否为已知的协议合约
+func (d *ApprovalDrainDetector) IsTrusted(spender string) bool {
+	return d.trusted[strings.ToLower(spender)]
+}
+
+// RecordApproval 记录对未知合约的授权，授权额度为0时视为撤销
+func (d *ApprovalDrainDetector) RecordApproval(approval *TokenApproval) error {
+	key := approvalKey(approval.Network, approval.Token, approval.Owner)
+	spender := strings.ToLower(approval.Spender)
+
+	if approval.Amount.Sign() == 0 {
+		return d.client.HDel(key, spender)
+	}
+
+	data, err := json.Marshal(&pendingApproval{
+		Amount:          approval.Amount.String(),
+		TransactionHash: approval.TransactionHash,
+		BlockNumber:     approval.BlockNumber,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := d.client.HSet(key, spender, string(data)); err != nil {
+		return err
+	}
+	return d.client.Expire(key, d.window)
+}
+
+// CheckTransfer 检查转出是否由持有人授权过的未知合约发起（持有人本人未签名该交易）
+func (d *ApprovalDrainDetector) CheckTransfer(transfer *TokenTransfer) (*ApprovalDrain, error) {
+	if strings.EqualFold(transfer.TxFrom, transfer.From) {
+		return nil, nil
+	}
+
+	key := approvalKey(transfer.Network, transfer.Token, transfer.From)
+	approvals, err := d.client.HGetAll(key)
+	if err != nil || len(approvals) == 0 {
+		return nil, err
+	}
+
+	for spender, raw := range approvals {
+		if spender != strings.ToLower(transfer.TxTo) && spender != strings.ToLower(transfer.TxFrom) {
+			continue
+		}
+
+		var pending pendingApproval
+		if err := json.Unmarshal([]byte(raw), &pending); err != nil {
+			logger.Errorf("Dropping undecodable approval %s/%s: %v", key, spender, err)
+			d.client.HDel(key, spender)
+			continue
+		}
+		approved, _ := new(big.Int).SetString(pending.Amount, 10)
+
+		return &ApprovalDrain{
+			Transfer:       transfer,
+			Drainer:        spender,
+			ApprovalTx:     pending.TransactionHash,
+			ApprovalBlock:  pending.BlockNumber,
+			ApprovedAmount: approved,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// Resolve 告警后移除授权记录，同一授权只告警一次
+func (d *ApprovalDrainDetector) Resolve(drain *ApprovalDrain) error {
+	return d.client.HDel(approvalKey(drain.Transfer.Network, drain.Transfer.Token, drain.Transfer.From), drain.Drainer)
+}
+
+func approvalKey(network, token, owner string) string {
+	return fmt.Sprintf("%s:%s:%s:%s", approvalKeyPrefix, network, strings.ToLower(token), strings.ToLower(owner))
+}
+
+// ProcessApprovalDrain 为授权盗取生成并发布CRITICAL告警
+func (dp *DataProcessor) ProcessApprovalDrain(drain *ApprovalDrain) (*models.RiskAlert, error) {
+	alert := dp.createApprovalDrainAlert(drain)
+	logger.Warnf("%s: %s", alert.Title, alert.Description)
+	if err := dp.PublishOpsAlert(alert); err != nil {
+		return nil, err
+	}
+	return alert, nil
+}
+
+// createApprovalDrainAlert 创建授权盗取告警，地址为盗取合约
+func (dp *DataProcessor) createApprovalDrainAlert(drain *ApprovalDrain) *models.RiskAlert {
+	transfer := drain.Transfer
+
+	alert := &models.RiskAlert{
+		ID:              models.AlertID("APPROVAL_DRAIN", transfer.ID),
+		Type:            "APPROVAL_DRAIN",
+		Level:           "CRITICAL",
+		Title:           "授权盗取",
+		Description:     fmt.Sprintf("合约 %s 在获得 %s 的代币授权后转走了其余额", drain.Drainer, transfer.From),
+		TransactionHash: transfer.TransactionHash,
+		Address:         drain.Drainer,
+		Network:         transfer.Network,
+		RiskScore:       0.9,
+		RiskFactors:     []string{"approval_to_unknown_contract", "transfer_from_drain"},
+		Metadata: map[string]interface{}{
+			"block_number":   transfer.BlockNumber,
+			"drainer":        drain.Drainer,
+			"victim":         transfer.From,
+			"recipient":      transfer.To,
+			"token":          transfer.Token,
+			"amount":         transfer.Amount.String(),
+			"approval_tx":    drain.ApprovalTx,
+			"approval_block": drain.ApprovalBlock,
+		},
+		Timestamp: transfer.Timestamp,
+		Status:    "ACTIVE",
+	}
+
+	if drain.ApprovedAmount != nil {
+		alert.Metadata["approved_amount"] = drain.ApprovedAmount.String()
+	}
+	if drain.RemainingBalance != nil {
+		alert.Metadata["remaining_balance"] = drain.RemainingBalance.String()
+	}
+
+	return alert
+}
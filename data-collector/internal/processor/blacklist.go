@@ -0,0 +1,449 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/models"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// systemOperator 系统内置操作人
+const systemOperator = "system"
+
+// 黑名单在Redis中的键：条目哈希（字段为小写地址，值为BlacklistEntry的JSON）、变更历史列表及当前版本
+const (
+	blacklistKeyPrefix  = "blacklist"
+	blacklistEntriesKey = blacklistKeyPrefix + ":entries"
+	blacklistHistoryKey = blacklistKeyPrefix + ":history"
+	blacklistVersionKey = blacklistKeyPrefix + ":version"
+)
+
+// blacklistSyncInterval 读取黑名单时检查Redis中版本的最短间隔，其他实例的变更最迟在该时间后生效
+const blacklistSyncInterval = 10 * time.Second
+
+// maxBlacklistCommitAttempts 其他实例同时修改黑名单时的最多提交次数
+const maxBlacklistCommitAttempts = 3
+
+// ErrBlacklistStorage 黑名单读写Redis失败，区别于请求本身不合法（如状态不允许）的错误
+var ErrBlacklistStorage = errors.New("blacklist storage error")
+
+// Blacklist 带审核流程与版本的黑名单，条目、历史及版本保存在Redis。修改前与Redis同步并以版本做乐观锁，
+// 匹配及查询使用本地副本，按blacklistSyncInterval检查版本，其他实例修改后重新加载
+type Blacklist struct {
+	client   *database.RedisClient
+	entries  map[string]*models.BlacklistEntry
+	history  []models.BlacklistChange
+	version  uint64
+	syncedAt time.Time
+	mu       sync.RWMutex
+}
+
+// blacklistChange 一次待提交的变更：变更后的条目及变更记录信息
+type blacklistChange struct {
+	entry    *models.BlacklistEntry
+	from     string
+	operator string
+	comment  string
+	at       time.Time
+}
+
+// NewBlacklist 创建黑名单并从Redis加载已有条目、历史及版本
+func NewBlacklist(redisClient *database.RedisClient) (*Blacklist, error) {
+	bl := &Blacklist{
+		client:  redisClient,
+		entries: make(map[string]*models.BlacklistEntry),
+	}
+	if err := bl.load(); err != nil {
+		return nil, err
+	}
+	return bl, nil
+}
+
+// Propose 提议新增黑名单条目，需审核后生效
+func (bl *Blacklist) Propose(address, reason, proposedBy string, effectiveFrom time.Time) (*models.BlacklistEntry, error) {
+	address = strings.ToLower(address)
+	if address == "" {
+		return nil, fmt.Errorf("address is required")
+	}
+	if proposedBy == "" {
+		return nil, fmt.Errorf("proposer is required")
+	}
+
+	return bl.update(func() (*blacklistChange, error) {
+		now := time.Now()
+		from := effectiveFrom
+		if from.IsZero() {
+			from = now
+		}
+
+		previous := ""
+		if entry, exists := bl.entries[address]; exists {
+			if entry.Status != models.BlacklistStatusRetired {
+				return nil, fmt.Errorf("address %s is already %s", address, strings.ToLower(entry.Status))
+			}
+			previous = entry.Status
+		}
+
+		entry := &models.BlacklistEntry{
+			Address:       address,
+			Status:        models.BlacklistStatusProposed,
+			Reason:        reason,
+			ProposedBy:    proposedBy,
+			EffectiveFrom: from,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		return &blacklistChange{entry: entry, from: previous, operator: proposedBy, comment: reason, at: now}, nil
+	})
+}
+
+// Approve 审核通过黑名单条目，审核人不能是提议人
+func (bl *Blacklist) Approve(address, reviewer string) (*models.BlacklistEntry, error) {
+	address = strings.ToLower(address)
+	if reviewer == "" {
+		return nil, fmt.Errorf("reviewer is required")
+	}
+
+	return bl.update(func() (*blacklistChange, error) {
+		entry, exists := bl.entries[address]
+		if !exists {
+			return nil, fmt.Errorf("address %s is not in blacklist", address)
+		}
+		if entry.Status != models.BlacklistStatusProposed {
+			return nil, fmt.Errorf("address %s is %s, only proposed entries can be approved", address, strings.ToLower(entry.Status))
+		}
+		if entry.ProposedBy == reviewer {
+			return nil, fmt.Errorf("address %s was proposed by %s, it must be approved by another reviewer", address, reviewer)
+		}
+
+		now := time.Now()
+		updated := *entry
+		updated.Status = models.BlacklistStatusActive
+		updated.ReviewedBy = reviewer
+		updated.UpdatedAt = now
+		return &blacklistChange{entry: &updated, from: models.BlacklistStatusProposed, operator: reviewer, at: now}, nil
+	})
+}
+
+// Retire 停用黑名单条目，保留历史记录
+func (bl *Blacklist) Retire(address, operator, comment string) (*models.BlacklistEntry, error) {
+	address = strings.ToLower(address)
+	if operator == "" {
+		return nil, fmt.Errorf("operator is required")
+	}
+
+	return bl.update(func() (*blacklistChange, error) {
+		entry, exists := bl.entries[address]
+		if !exists {
+			return nil, fmt.Errorf("address %s is not in blacklist", address)
+		}
+		if entry.Status == models.BlacklistStatusRetired {
+			return nil, fmt.Errorf("address %s is already retired", address)
+		}
+
+		now := time.Now()
+		updated := *entry
+		updated.Status = models.BlacklistStatusRetired
+		updated.RetiredBy = operator
+		updated.EffectiveUntil = &now
+		updated.UpdatedAt = now
+		return &blacklistChange{entry: &updated, from: entry.Status, operator: operator, comment: comment, at: now}, nil
+	})
+}
+
+// activate 直接激活条目（用于内置列表和兼容旧接口），已生效时不做修改
+func (bl *Blacklist) activate(address, reason, operator string) error {
+	address = strings.ToLower(address)
+
+	_, err := bl.update(func() (*blacklistChange, error) {
+		previous := ""
+		if entry, exists := bl.entries[address]; exists {
+			if entry.Status == models.BlacklistStatusActive {
+				return nil, nil
+			}
+			previous = entry.Status
+		}
+
+		now := time.Now()
+		entry := &models.BlacklistEntry{
+			Address:       address,
+			Status:        models.BlacklistStatusActive,
+			Reason:        reason,
+			ProposedBy:    operator,
+			ReviewedBy:    operator,
+			EffectiveFrom: now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		return &blacklistChange{entry: entry, from: previous, operator: operator, comment: reason, at: now}, nil
+	})
+	return err
+}
+
+// update 与Redis同步后由prepare基于最新状态校验并生成变更（返回nil表示无需修改），然后提交。
+// 其他实例在此期间提交了变更时重新同步、校验后重试
+func (bl *Blacklist) update(prepare func() (*blacklistChange, error)) (*models.BlacklistEntry, error) {
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+
+	for attempt := 1; ; attempt++ {
+		if err := bl.sync(); err != nil {
+			return nil, err
+		}
+
+		change, err := prepare()
+		if err != nil || change == nil {
+			return nil, err
+		}
+
+		committed, err := bl.commit(change)
+		if err != nil {
+			return nil, err
+		}
+		if committed {
+			copied := *change.entry
+			return &copied, nil
+		}
+		if attempt == maxBlacklistCommitAttempts {
+			return nil, fmt.Errorf("%w: blacklist changed concurrently %d times, giving up", ErrBlacklistStorage, attempt)
+		}
+	}
+}
+
+// commit 以MULTI/EXEC写入条目、变更记录及新版本，Redis中的版本仍为本地版本时才提交，
+// 成功后更新本地副本；返回false表示其他实例已先提交（调用方需持有写锁）
+func (bl *Blacklist) commit(change *blacklistChange) (bool, error) {
+	entry := change.entry
+	entry.Version = bl.version + 1
+	record := models.BlacklistChange{
+		Version:   entry.Version,
+		Address:   entry.Address,
+		From:      change.from,
+		To:        entry.Status,
+		Operator:  change.operator,
+		Comment:   change.comment,
+		Timestamp: change.at,
+	}
+
+	entryData, err := json.Marshal(entry)
+	if err != nil {
+		return false, err
+	}
+	recordData, err := json.Marshal(record)
+	if err != nil {
+		return false, err
+	}
+
+	committed, err := bl.client.TxIfValue(blacklistVersionKey, formatBlacklistVersion(bl.version), func(ctx context.Context, pipe redis.Pipeliner) {
+		pipe.HSet(ctx, blacklistEntriesKey, entry.Address, entryData)
+		pipe.RPush(ctx, blacklistHistoryKey, recordData)
+		pipe.Set(ctx, blacklistVersionKey, formatBlacklistVersion(entry.Version), 0)
+	})
+	if err != nil {
+		return false, fmt.Errorf("%w: failed to save blacklist change for %s: %v", ErrBlacklistStorage, entry.Address, err)
+	}
+	if !committed {
+		return false, nil
+	}
+
+	bl.entries[entry.Address] = entry
+	bl.history = append(bl.history, record)
+	bl.version = entry.Version
+	return true, nil
+}
+
+// sync Redis中的版本与本地不同时重新加载（调用方需持有写锁）
+func (bl *Blacklist) sync() error {
+	value, _, err := bl.client.GetIfExists(blacklistVersionKey)
+	if err != nil {
+		return fmt.Errorf("%w: failed to load blacklist version: %v", ErrBlacklistStorage, err)
+	}
+	if value == formatBlacklistVersion(bl.version) {
+		bl.syncedAt = time.Now()
+		return nil
+	}
+	return bl.load()
+}
+
+// load 从Redis加载全部条目、历史及版本，替换本地副本（调用方需持有写锁或尚未共享）
+func (bl *Blacklist) load() error {
+	value, exists, err := bl.client.GetIfExists(blacklistVersionKey)
+	if err != nil {
+		return fmt.Errorf("%w: failed to load blacklist version: %v", ErrBlacklistStorage, err)
+	}
+	var version uint64
+	if exists {
+		if version, err = strconv.ParseUint(value, 10, 64); err != nil {
+			return fmt.Errorf("invalid blacklist version %q: %w", value, err)
+		}
+	}
+
+	values, err := bl.client.HGetAll(blacklistEntriesKey)
+	if err != nil {
+		return fmt.Errorf("%w: failed to load blacklist entries: %v", ErrBlacklistStorage, err)
+	}
+	entries := make(map[string]*models.BlacklistEntry, len(values))
+	for address, value := range values {
+		var entry models.BlacklistEntry
+		if err := json.Unmarshal([]byte(value), &entry); err != nil {
+			return fmt.Errorf("invalid blacklist entry %s: %w", address, err)
+		}
+		entries[address] = &entry
+	}
+
+	changes, err := bl.client.LRange(blacklistHistoryKey, 0, -1)
+	if err != nil {
+		return fmt.Errorf("%w: failed to load blacklist history: %v", ErrBlacklistStorage, err)
+	}
+	history := make([]models.BlacklistChange, 0, len(changes))
+	for _, value := range changes {
+		var change models.BlacklistChange
+		if err := json.Unmarshal([]byte(value), &change); err != nil {
+			return fmt.Errorf("invalid blacklist change: %w", err)
+		}
+		history = append(history, change)
+	}
+
+	bl.entries = entries
+	bl.history = history
+	bl.version = version
+	bl.syncedAt = time.Now()
+	return nil
+}
+
+// refresh 距上次同步超过blacklistSyncInterval时与Redis同步，失败时记录日志并继续使用本地副本
+func (bl *Blacklist) refresh() {
+	bl.mu.RLock()
+	fresh := time.Since(bl.syncedAt) < blacklistSyncInterval
+	bl.mu.RUnlock()
+	if fresh {
+		return
+	}
+
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+	if time.Since(bl.syncedAt) < blacklistSyncInterval {
+		return
+	}
+	if err := bl.sync(); err != nil {
+		// 失败后同样等待一个间隔再重试，避免每次匹配都访问Redis
+		bl.syncedAt = time.Now()
+		logger.Warnf("Failed to refresh blacklist, using local copy (version %d): %v", bl.version, err)
+	}
+}
+
+// formatBlacklistVersion 版本在Redis中的值，0（尚无变更）对应不存在的键
+func formatBlacklistVersion(version uint64) string {
+	if version == 0 {
+		return ""
+	}
+	return strconv.FormatUint(version, 10)
+}
+
+// Match 检查地址在指定时间是否命中生效中的黑名单条目
+func (bl *Blacklist) Match(address string, at time.Time) (*models.BlacklistEntry, bool) {
+	bl.refresh()
+
+	bl.mu.RLock()
+	defer bl.mu.RUnlock()
+
+	entry, exists := bl.entries[strings.ToLower(address)]
+	if !exists || entry.Status != models.BlacklistStatusActive {
+		return nil, false
+	}
+	if at.Before(entry.EffectiveFrom) {
+		return nil, false
+	}
+
+	copied := *entry
+	return &copied, true
+}
+
+// Get 获取黑名单条目
+func (bl *Blacklist) Get(address string) (*models.BlacklistEntry, bool) {
+	bl.refresh()
+
+	bl.mu.RLock()
+	defer bl.mu.RUnlock()
+
+	entry, exists := bl.entries[strings.ToLower(address)]
+	if !exists {
+		return nil, false
+	}
+	copied := *entry
+	return &copied, true
+}
+
+// Entries 获取指定状态的条目，status为空时返回全部
+func (bl *Blacklist) Entries(status string) []models.BlacklistEntry {
+	bl.refresh()
+
+	bl.mu.RLock()
+	defer bl.mu.RUnlock()
+
+	entries := make([]models.BlacklistEntry, 0, len(bl.entries))
+	for _, entry := range bl.entries {
+		if status != "" && !strings.EqualFold(entry.Status, status) {
+			continue
+		}
+		entries = append(entries, *entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Address < entries[j].Address
+	})
+	return entries
+}
+
+// History 获取变更历史，address为空时返回全部
+func (bl *Blacklist) History(address string) []models.BlacklistChange {
+	bl.refresh()
+
+	bl.mu.RLock()
+	defer bl.mu.RUnlock()
+
+	address = strings.ToLower(address)
+	changes := make([]models.BlacklistChange, 0)
+	for _, change := range bl.history {
+		if address != "" && change.Address != address {
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// Version 获取当前黑名单版本
+func (bl *Blacklist) Version() uint64 {
+	bl.refresh()
+
+	bl.mu.RLock()
+	defer bl.mu.RUnlock()
+	return bl.version
+}
+
+// ActiveCount 获取生效中的条目数量
+func (bl *Blacklist) ActiveCount() int {
+	bl.refresh()
+
+	bl.mu.RLock()
+	defer bl.mu.RUnlock()
+
+	count := 0
+	for _, entry := range bl.entries {
+		if entry.Status == models.BlacklistStatusActive {
+			count++
+		}
+	}
+	return count
+}
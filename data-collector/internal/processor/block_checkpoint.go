@@ -0,0 +1,53 @@
+package processor
+
+import (
+	"fmt"
+	"time"
+
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/models"
+)
+
+// blockCheckpointKeyPrefix 已提交区块在Redis中的键前缀，按 网络:区块号 存储区块哈希
+const blockCheckpointKeyPrefix = "kafka_checkpoint"
+
+// blockCheckpointTTL 检查点保留时长，只需覆盖重启及隔离重提交的时间范围
+const blockCheckpointTTL = 24 * time.Hour
+
+// BlockCheckpoints 记录交易消息已提交的区块，按区块哈希比较，重组后的新区块不受影响
+type BlockCheckpoints struct {
+	client *database.RedisClient
+}
+
+// NewBlockCheckpoints 创建区块检查点存储
+func NewBlockCheckpoints(redisClient *database.RedisClient) *BlockCheckpoints {
+	return &BlockCheckpoints{client: redisClient}
+}
+
+// Committed 判断区块是否已提交，查询失败时按未提交处理
+func (c *BlockCheckpoints) Committed(block *models.Block) bool {
+	hash, err := c.client.Get(blockCheckpointKey(block.Network, block.Number))
+	return err == nil && hash == block.Hash
+}
+
+// Commit 记录区块已提交
+func (c *BlockCheckpoints) Commit(block *models.Block) error {
+	return c.client.Set(blockCheckpointKey(block.Network, block.Number), block.Hash, blockCheckpointTTL)
+}
+
+func blockCheckpointKey(network string, number uint64) string {
+	return fmt.Sprintf("%s:%s:%d", blockCheckpointKeyPrefix, network, number)
+}
+
+// commitBlock 写出区块缓冲的交易消息并记录检查点
+func (dp *DataProcessor) commitBlock(block *models.Block) error {
+	if err := dp.sinks.CommitBlock(block.Network, block.Number); err != nil {
+		return fmt.Errorf("failed to commit block %d: %w", block.Number, err)
+	}
+
+	// 检查点写入失败只会使重新处理时重复写出
+	if err := dp.checkpoints.Commit(block); err != nil {
+		logger.Warnf("Failed to record checkpoint of block %d for %s: %v", block.Number, block.Network, err)
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/models"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// bridgeKeyPrefix 跨链桥记录在Redis中的键前缀：
+// bridge:link:{消息ID} 为等待到账的存入记录列表，bridge:watermark:{消息流} 为开始监控后看到的第一个序号，
+// bridge:seen:{记录ID} 用于去重
+const bridgeKeyPrefix = "bridge"
+
+// BridgeMonitor 跨链桥合约注册表，按消息ID关联源链存入与目标链到账，
+// 并识别没有对应存入的到账（伪造消息或重复兑付等盗取）
+type BridgeMonitor struct {
+	client    *database.RedisClient
+	contracts map[string][]config.BridgeContractConfig // 网络 -> 监控的桥合约
+	networks  map[string]string                        // 协议:链ID -> 网络
+	linkTTL   time.Duration
+}
+
+// NewBridgeMonitor 根据配置创建跨链桥监控，未启用时返回nil
+func NewBridgeMonitor(cfg config.BridgeConfig, redisClient *database.RedisClient) (*BridgeMonitor, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	linkTTL := 30 * 24 * time.Hour
+	if cfg.LinkTTL != "" {
+		parsed, err := time.ParseDuration(cfg.LinkTTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bridges link_ttl: %w", err)
+		}
+		linkTTL = parsed
+	}
+
+	monitor := &BridgeMonitor{
+		client:    redisClient,
+		contracts: make(map[string][]config.BridgeContractConfig),
+		networks:  make(map[string]string),
+		linkTTL:   linkTTL,
+	}
+	for _, contract := range cfg.Contracts {
+		contract.Address = common.HexToAddress(contract.Address).Hex()
+		if contract.Core != "" {
+			contract.Core = common.HexToAddress(contract.Core).Hex()
+		}
+		monitor.contracts[contract.Network] = append(monitor.contracts[contract.Network], contract)
+		if contract.ChainID != 0 {
+			monitor.networks[bridgeChainIndex(contract.Protocol, contract.ChainID)] = contract.Network
+		}
+	}
+
+	return monitor, nil
+}
+
+// Contracts 获取网络上监控的桥合约，地址为校验和格式
+func (m *BridgeMonitor) Contracts(network string) []config.BridgeContractConfig {
+	return m.contracts[network]
+}
+
+// Monitors 网络上是否配置了监控的桥合约
+func (m *BridgeMonitor) Monitors(network string) bool {
+	return len(m.contracts[network]) > 0
+}
+
+// NetworkOf 获取协议链ID对应的网络，链未监控时为空
+func (m *BridgeMonitor) NetworkOf(protocol string, chainID uint32) string {
+	return m.networks[bridgeChainIndex(protocol, chainID)]
+}
+
+// Record 记录存入等待到账，或为到账查找对应的存入并补全源链字段；同一日志事件只处理一次，已处理过时返回false
+func (m *BridgeMonitor) Record(transfer *models.BridgeTransfer) (bool, error) {
+	first, err := m.client.SetNX(fmt.Sprintf("%s:seen:%s", bridgeKeyPrefix, transfer.ID), 1, supplyDedupTTL)
+	if err != nil || !first {
+		return false, err
+	}
+
+	if transfer.Direction == models.BridgeDeposit {
+		return true, m.recordDeposit(transfer)
+	}
+	return true, m.link(transfer)
+}
+
+// recordDeposit 将存入追加到消息ID的等待列表（OP Stack标准桥没有序号，相同参数的存入按顺序关联），并记录消息流的起始序号
+func (m *BridgeMonitor) recordDeposit(transfer *models.BridgeTransfer) error {
+	data, err := json.Marshal(transfer)
+	if err != nil {
+		return err
+	}
+
+	key := bridgeLinkKey(transfer.MessageID)
+	if err := m.client.RPush(key, string(data)); err != nil {
+		return err
+	}
+	if err := m.client.Expire(key, m.linkTTL); err != nil {
+		return err
+	}
+
+	if stream, ok := bridgeStream(transfer); ok {
+		if _, err := m.client.SetNX(bridgeWatermarkKey(stream), transfer.Sequence, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// link 取出到账对应的存入，补全发送方、金额等到账事件中没有的字段
+func (m *BridgeMonitor) link(transfer *models.BridgeTransfer) error {
+	data, found, err := m.client.LPopIfExists(bridgeLinkKey(transfer.MessageID))
+	if err != nil || !found {
+		return err
+	}
+
+	var deposit models.BridgeTransfer
+	if err := json.Unmarshal([]byte(data), &deposit); err != nil {
+		return fmt.Errorf("invalid deposit record of %s: %w", transfer.MessageID, err)
+	}
+
+	transfer.Linked = true
+	transfer.SourceTransactionHash = deposit.TransactionHash
+	if transfer.Sender == "" {
+		transfer.Sender = deposit.Sender
+	}
+	if transfer.Recipient == "" {
+		transfer.Recipient = deposit.Recipient
+	}
+	if transfer.Token == "" {
+		transfer.Token = deposit.Token
+	}
+	if transfer.Amount == nil {
+		transfer.Amount = deposit.Amount
+	}
+	return nil
+}
+
+// Unbacked 判断未关联的到账是否缺少本应看到的存入：源链已监控，且序号不早于开始监控后看到的第一个序号。
+// OP Stack标准桥没有序号，不做判断；源链处理落后于目标链超过协议确认时间时可能误报
+func (m *BridgeMonitor) Unbacked(transfer *models.BridgeTransfer) (bool, error) {
+	if transfer.Direction != models.BridgeWithdrawal || transfer.Linked || transfer.SourceNetwork == "" {
+		return false, nil
+	}
+	stream, ok := bridgeStream(transfer)
+	if !ok {
+		return false, nil
+	}
+
+	value, exists, err := m.client.GetIfExists(bridgeWatermarkKey(stream))
+	if err != nil || !exists {
+		return false, err
+	}
+	watermark, err := parseInt64(value)
+	if err != nil {
+		return false, err
+	}
+	return transfer.Sequence >= uint64(watermark), nil
+}
+
+// bridgeStream 有序号协议的消息流（消息ID去掉末尾的序号），同一消息流内序号递增
+func bridgeStream(transfer *models.BridgeTransfer) (string, bool) {
+	if transfer.Protocol == models.BridgeOPStandard {
+		return "", false
+	}
+	index := strings.LastIndex(transfer.MessageID, ":")
+	if index < 0 {
+		return "", false
+	}
+	return transfer.MessageID[:index], true
+}
+
+func bridgeChainIndex(protocol string, chainID uint32) string {
+	return fmt.Sprintf("%s:%d", protocol, chainID)
+}
+
+func bridgeLinkKey(messageID string) string {
+	return fmt.Sprintf("%s:link:%s", bridgeKeyPrefix, messageID)
+}
+
+func bridgeWatermarkKey(stream string) string {
+	return fmt.Sprintf("%s:watermark:%s", bridgeKeyPrefix, stream)
+}
+
+// ProcessBridgeTransfers 关联并发布区块内的跨链桥记录，没有对应存入的到账发布CRITICAL告警，返回生成的告警
+func (dp *DataProcessor) ProcessBridgeTransfers(transfers []*models.BridgeTransfer) []*models.RiskAlert {
+	var alerts []*models.RiskAlert
+	for _, transfer := range transfers {
+		recorded, err := dp.bridges.Record(transfer)
+		if err != nil {
+			logger.Errorf("Failed to record bridge %s %s: %v", transfer.Direction, transfer.ID, err)
+			continue
+		}
+		if !recorded {
+			continue
+		}
+		dp.metricsManager.RecordBridgeTransfer(transfer.Network, transfer.Protocol, transfer.Direction, transfer.Linked)
+
+		if err := dp.sinks.PublishBridgeTransfer(transfer); err != nil {
+			logger.Errorf("Failed to publish bridge transfer %s: %v", transfer.ID, err)
+		}
+
+		unbacked, err := dp.bridges.Unbacked(transfer)
+		if err != nil {
+			logger.Errorf("Failed to check bridge withdrawal %s: %v", transfer.ID, err)
+			continue
+		}
+		if !unbacked {
+			continue
+		}
+		alert := dp.createBridgeDrainAlert(transfer)
+		logger.Warnf("%s: %s", alert.Title, alert.Description)
+		if err := dp.PublishOpsAlert(alert); err != nil {
+			logger.Errorf("Failed to publish bridge drain alert for %s: %v", transfer.TransactionHash, err)
+			continue
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts
+}
+
+// createBridgeDrainAlert 创建跨链桥盗取告警，地址为桥合约
+func (dp *DataProcessor) createBridgeDrainAlert(transfer *models.BridgeTransfer) *models.RiskAlert {
+	alert := &models.RiskAlert{
+		ID:              models.AlertID("BRIDGE_DRAIN", transfer.ID),
+		Type:            "BRIDGE_DRAIN",
+		Level:           "CRITICAL",
+		Title:           "跨链桥异常到账",
+		Description:     fmt.Sprintf("%s 桥合约 %s 兑付了源链 %s 上不存在或已兑付的消息 %s", transfer.Protocol, transfer.Bridge, transfer.SourceNetwork, transfer.MessageID),
+		TransactionHash: transfer.TransactionHash,
+		Address:         transfer.Bridge,
+		Network:         transfer.Network,
+		RiskScore:       0.9,
+		RiskFactors:     []string{"unbacked_bridge_withdrawal"},
+		Metadata: map[string]interface{}{
+			"block_number":   transfer.BlockNumber,
+			"protocol":       transfer.Protocol,
+			"message_id":     transfer.MessageID,
+			"sequence":       transfer.Sequence,
+			"source_network": transfer.SourceNetwork,
+		},
+		Timestamp: transfer.Timestamp,
+		Status:    "ACTIVE",
+	}
+
+	if transfer.Recipient != "" {
+		alert.Metadata["recipient"] = transfer.Recipient
+	}
+	if transfer.Amount != nil {
+		alert.Metadata["amount"] = transfer.Amount.String()
+	}
+
+	return alert
+}
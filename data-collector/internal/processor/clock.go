@@ -0,0 +1,106 @@
+package processor
+
+import (
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/metrics"
+	"web3-data-collector/internal/models"
+)
+
+// 窗口类风险规则的时间来源
+const (
+	TimeSourceReceived = "received" // 收集器收到区块的时间
+	TimeSourceBlock    = "block"    // 区块时间戳
+)
+
+// defaultMaxClockSkew 区块时间与接收时间的默认允许偏差
+const defaultMaxClockSkew = 30 * time.Second
+
+// networkClock 网络的时间来源配置
+type networkClock struct {
+	source  string
+	maxSkew time.Duration
+}
+
+// ClockRegistry 各网络的时间来源及时钟偏差检测。
+// 区块时间由出块者填写，可能漂移或被操纵，把交易分散到不同的统计窗口以规避速率类规则，
+// 因此窗口类规则默认使用收集器的接收时间
+type ClockRegistry struct {
+	clocks         map[string]networkClock
+	metricsManager *metrics.Manager
+	mu             sync.RWMutex
+}
+
+// NewClockRegistry 根据网络配置创建时间来源注册表
+func NewClockRegistry(networks map[string]config.NetworkConfig, metricsManager *metrics.Manager) *ClockRegistry {
+	registry := &ClockRegistry{metricsManager: metricsManager}
+	registry.Update(networks)
+
+	return registry
+}
+
+// Update 根据新的网络配置更新时间来源（配置热加载）
+func (cr *ClockRegistry) Update(networks map[string]config.NetworkConfig) {
+	clocks := make(map[string]networkClock, len(networks))
+	for name, networkCfg := range networks {
+		clock := networkClock{source: networkCfg.TimeSource, maxSkew: defaultMaxClockSkew}
+		if clock.source == "" {
+			clock.source = TimeSourceReceived
+		}
+		if skew, err := time.ParseDuration(networkCfg.MaxClockSkew); err == nil && skew > 0 {
+			clock.maxSkew = skew
+		}
+		clocks[name] = clock
+	}
+
+	cr.mu.Lock()
+	cr.clocks = clocks
+	cr.mu.Unlock()
+}
+
+func (cr *ClockRegistry) get(network string) networkClock {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+
+	if clock, exists := cr.clocks[network]; exists {
+		return clock
+	}
+	return networkClock{source: TimeSourceReceived, maxSkew: defaultMaxClockSkew}
+}
+
+// Annotate 为区块及其交易记录接收时间并检测时钟偏差。
+// 区块时间落后接收时间超过max_clock_skew时视为补块，不计算偏差
+func (cr *ClockRegistry) Annotate(block *models.Block, receivedAt time.Time) {
+	block.ReceivedAt = receivedAt
+	for i := range block.Transactions {
+		block.Transactions[i].ReceivedAt = receivedAt
+	}
+
+	clock := cr.get(block.Network)
+	skew := block.Timestamp.Sub(receivedAt)
+	if skew < -clock.maxSkew {
+		return
+	}
+
+	block.ClockSkewMs = skew.Milliseconds()
+	exceeded := skew > clock.maxSkew
+	cr.metricsManager.RecordClockSkew(block.Network, skew, exceeded)
+	if exceeded {
+		logger.Warnf("Block %d of %s is %v ahead of the local clock", block.Number, block.Network, skew.Round(time.Millisecond))
+	}
+}
+
+// RuleTime 窗口类风险规则使用的时间。time_source为received时取接收时间；
+// 补块及未记录接收时间的交易（如隔离后重新提交的旧数据）取区块时间
+func (cr *ClockRegistry) RuleTime(tx *models.Transaction) time.Time {
+	clock := cr.get(tx.Network)
+	if clock.source == TimeSourceBlock || tx.ReceivedAt.IsZero() {
+		return tx.Timestamp
+	}
+	if tx.ReceivedAt.Sub(tx.Timestamp) > clock.maxSkew {
+		return tx.Timestamp
+	}
+	return tx.ReceivedAt
+}
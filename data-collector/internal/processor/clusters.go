@@ -0,0 +1,365 @@
+package processor
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/metrics"
+	"web3-data-collector/internal/models"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// clusterKeyPrefix 地址聚类在Redis中的键前缀：
+// cluster:id:{网络}:{地址} 为地址所属聚类，cluster:members:{网络}:{聚类ID} 为聚类成员集合，
+// cluster:info:{网络}:{聚类ID} 为各启发式的关联次数，cluster:funder:{网络}:{地址} 为首次注资方，
+// cluster:fanout:{网络}:{地址} 为注资地址数，cluster:deposit:{网络}:{地址} 为充值地址标记，cluster:seq 为聚类ID序号
+const clusterKeyPrefix = "cluster"
+
+// 聚类启发式
+const (
+	ClusterFunding    = "funding"     // 共同注资来源：地址与其首次注资方
+	ClusterDeposit    = "deposit"     // 共用充值地址：向同一交易所充值地址转入的地址
+	ClusterCoSpending = "co_spending" // 共同转出：同一交易中发起方与其他外部账户同时转出代币
+)
+
+// 关联结果
+const (
+	clusterLinked    = "linked"     // 地址加入已有聚类或新建聚类
+	clusterMerged    = "merged"     // 两个聚类合并
+	clusterUnchanged = "unchanged"  // 已在同一聚类
+	clusterSizeLimit = "size_limit" // 合并后超过上限，未关联
+)
+
+// Cluster 地址聚类
+type Cluster struct {
+	ID       string           `json:"id"`
+	Network  string           `json:"network"`
+	Members  []string         `json:"members"`
+	Evidence map[string]int64 `json:"evidence"` // 启发式 -> 关联次数
+}
+
+// ClusterBlacklistMatch 交易地址所在聚类中的黑名单地址
+type ClusterBlacklistMatch struct {
+	Address   string                `json:"address"` // 交易地址
+	ClusterID string                `json:"cluster_id"`
+	Entry     models.BlacklistEntry `json:"entry"`
+}
+
+// ClusterEngine 按启发式将地址归并为实体聚类，聚类关系存储在Redis中。
+// 合并在进程内串行执行，每次合并将较小聚类的成员改标为较大聚类的ID
+type ClusterEngine struct {
+	mu               sync.Mutex
+	client           *database.RedisClient
+	maxClusterSize   int64
+	maxFundingFanout int64
+	fundingWindow    time.Duration
+	exchangeWallets  map[string]bool // 校验和地址
+	propagate        bool
+	metricsManager   *metrics.Manager
+}
+
+// NewClusterEngine 根据配置创建地址聚类，未启用时返回nil
+func NewClusterEngine(cfg config.ClusterConfig, redisClient *database.RedisClient, metricsManager *metrics.Manager) (*ClusterEngine, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	window, err := time.ParseDuration(cfg.FundingWindow)
+	if err != nil || window <= 0 {
+		return nil, fmt.Errorf("invalid clusters funding_window: %q", cfg.FundingWindow)
+	}
+
+	engine := &ClusterEngine{
+		client:           redisClient,
+		maxClusterSize:   int64(cfg.MaxClusterSize),
+		maxFundingFanout: int64(cfg.MaxFundingFanout),
+		fundingWindow:    window,
+		exchangeWallets:  make(map[string]bool, len(cfg.ExchangeWallets)),
+		propagate:        cfg.PropagateBlacklist,
+		metricsManager:   metricsManager,
+	}
+	for _, wallet := range cfg.ExchangeWallets {
+		engine.exchangeWallets[common.HexToAddress(wallet).Hex()] = true
+	}
+
+	return engine, nil
+}
+
+// ObserveTransaction 按原生币转账应用共同注资及共用充值地址启发式
+func (ce *ClusterEngine) ObserveTransaction(tx *models.Transaction) {
+	// 只处理成功的普通转账，合约调用的value不代表注资
+	if tx.Status != 1 || tx.ToAddress == "" || tx.IsContractCall || tx.Value == nil || tx.Value.Sign() <= 0 {
+		return
+	}
+	from, to := common.HexToAddress(tx.FromAddress).Hex(), common.HexToAddress(tx.ToAddress).Hex()
+	if from == to {
+		return
+	}
+
+	ce.observeDeposit(tx.Network, from, to)
+	if ce.exchangeWallets[from] || ce.exchangeWallets[to] {
+		return
+	}
+
+	// 只关联首次注资方，注资地址过多的注资方视为公共来源
+	first, err := ce.client.SetNX(ce.key("funder", tx.Network, to), from, ce.fundingWindow)
+	if err != nil {
+		logger.Errorf("Failed to record funder of %s on %s: %v", to, tx.Network, err)
+		return
+	}
+	if !first {
+		return
+	}
+	fanoutKey := ce.key("fanout", tx.Network, from)
+	fanout, err := ce.client.Incr(fanoutKey)
+	if err != nil {
+		logger.Errorf("Failed to count funded addresses of %s on %s: %v", from, tx.Network, err)
+		return
+	}
+	if fanout == 1 {
+		if err := ce.client.Expire(fanoutKey, ce.fundingWindow); err != nil {
+			logger.Warnf("Failed to set expiration of %s: %v", fanoutKey, err)
+		}
+	}
+	if ce.maxFundingFanout > 0 && fanout > ce.maxFundingFanout {
+		return
+	}
+	ce.Link(tx.Network, from, to, ClusterFunding)
+}
+
+// ObserveTokenTransfer 按代币转账应用共用充值地址启发式
+func (ce *ClusterEngine) ObserveTokenTransfer(transfer *TokenTransfer) {
+	if transfer.Amount == nil || transfer.Amount.Sign() <= 0 {
+		return
+	}
+	from, to := common.HexToAddress(transfer.From).Hex(), common.HexToAddress(transfer.To).Hex()
+	if from != to {
+		ce.observeDeposit(transfer.Network, from, to)
+	}
+}
+
+// observeDeposit 转入交易所热钱包的地址标记为充值地址，向充值地址转入的地址与其关联
+func (ce *ClusterEngine) observeDeposit(network, from, to string) {
+	if ce.exchangeWallets[to] {
+		if !ce.exchangeWallets[from] {
+			if err := ce.client.Set(ce.key("deposit", network, from), 1, ce.fundingWindow); err != nil {
+				logger.Errorf("Failed to mark deposit address %s on %s: %v", from, network, err)
+			}
+		}
+		return
+	}
+	if ce.exchangeWallets[from] {
+		return
+	}
+
+	deposit, err := ce.client.Exists(ce.key("deposit", network, to))
+	if err != nil {
+		logger.Errorf("Failed to check deposit address %s on %s: %v", to, network, err)
+		return
+	}
+	if deposit {
+		ce.Link(network, from, to, ClusterDeposit)
+	}
+}
+
+// Link 将两个地址归入同一聚类，返回关联结果
+func (ce *ClusterEngine) Link(network, a, b, heuristic string) string {
+	a, b = common.HexToAddress(a).Hex(), common.HexToAddress(b).Hex()
+	if a == b || ce.exchangeWallets[a] || ce.exchangeWallets[b] {
+		return clusterUnchanged
+	}
+
+	ce.mu.Lock()
+	outcome, err := ce.link(network, a, b, heuristic)
+	ce.mu.Unlock()
+	if err != nil {
+		logger.Errorf("Failed to link %s and %s on %s by %s: %v", a, b, network, heuristic, err)
+		return ""
+	}
+
+	ce.metricsManager.RecordClusterLink(network, heuristic, outcome)
+	return outcome
+}
+
+func (ce *ClusterEngine) link(network, a, b, heuristic string) (string, error) {
+	idA, _, err := ce.client.GetIfExists(ce.key("id", network, a))
+	if err != nil {
+		return "", err
+	}
+	idB, _, err := ce.client.GetIfExists(ce.key("id", network, b))
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case idA == "" && idB == "":
+		seq, err := ce.client.Incr(clusterKeyPrefix + ":seq")
+		if err != nil {
+			return "", err
+		}
+		id := fmt.Sprintf("c%d", seq)
+		if err := ce.addMembers(network, id, a, b); err != nil {
+			return "", err
+		}
+		return clusterLinked, ce.addEvidence(network, id, map[string]int64{heuristic: 1})
+
+	case idA == idB:
+		return clusterUnchanged, ce.addEvidence(network, idA, map[string]int64{heuristic: 1})
+
+	case idA == "" || idB == "":
+		id, address := idA, b
+		if id == "" {
+			id, address = idB, a
+		}
+		size, err := ce.client.SCard(ce.key("members", network, id))
+		if err != nil {
+			return "", err
+		}
+		if size+1 > ce.maxClusterSize {
+			return clusterSizeLimit, nil
+		}
+		if err := ce.addMembers(network, id, address); err != nil {
+			return "", err
+		}
+		return clusterLinked, ce.addEvidence(network, id, map[string]int64{heuristic: 1})
+	}
+
+	return ce.merge(network, idA, idB, heuristic)
+}
+
+// merge 将较小聚类的成员及关联次数并入较大聚类
+func (ce *ClusterEngine) merge(network, idA, idB, heuristic string) (string, error) {
+	membersA, err := ce.client.SMembers(ce.key("members", network, idA))
+	if err != nil {
+		return "", err
+	}
+	membersB, err := ce.client.SMembers(ce.key("members", network, idB))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(membersA)+len(membersB)) > ce.maxClusterSize {
+		return clusterSizeLimit, nil
+	}
+
+	target, source, moved := idA, idB, membersB
+	if len(membersB) > len(membersA) {
+		target, source, moved = idB, idA, membersA
+	}
+
+	evidence, err := ce.evidence(network, source)
+	if err != nil {
+		return "", err
+	}
+	evidence[heuristic]++
+	if err := ce.addMembers(network, target, moved...); err != nil {
+		return "", err
+	}
+	if err := ce.addEvidence(network, target, evidence); err != nil {
+		return "", err
+	}
+	if err := ce.client.Delete(ce.key("members", network, source)); err != nil {
+		return "", err
+	}
+	return clusterMerged, ce.client.Delete(ce.key("info", network, source))
+}
+
+// addMembers 将地址加入聚类并记录地址所属聚类
+func (ce *ClusterEngine) addMembers(network, id string, addresses ...string) error {
+	members := make([]interface{}, len(addresses))
+	for i, address := range addresses {
+		if err := ce.client.Set(ce.key("id", network, address), id, 0); err != nil {
+			return err
+		}
+		members[i] = address
+	}
+	return ce.client.SAdd(ce.key("members", network, id), members...)
+}
+
+// addEvidence 累加聚类各启发式的关联次数
+func (ce *ClusterEngine) addEvidence(network, id string, counts map[string]int64) error {
+	current, err := ce.evidence(network, id)
+	if err != nil {
+		return err
+	}
+	fields := make(map[string]interface{}, len(counts)+1)
+	for heuristic, count := range counts {
+		fields[heuristic] = current[heuristic] + count
+	}
+	fields["updated_at"] = time.Now().Unix()
+	return ce.client.HMSet(ce.key("info", network, id), fields)
+}
+
+func (ce *ClusterEngine) evidence(network, id string) (map[string]int64, error) {
+	info, err := ce.client.HGetAll(ce.key("info", network, id))
+	if err != nil {
+		return nil, err
+	}
+	evidence := make(map[string]int64, len(info))
+	for _, heuristic := range []string{ClusterFunding, ClusterDeposit, ClusterCoSpending} {
+		if value, exists := info[heuristic]; exists {
+			if count, err := parseInt64(value); err == nil {
+				evidence[heuristic] = count
+			}
+		}
+	}
+	return evidence, nil
+}
+
+// Get 获取地址所在聚类，地址不属于任何聚类时返回nil
+func (ce *ClusterEngine) Get(network, address string) (*Cluster, error) {
+	address = common.HexToAddress(address).Hex()
+	id, exists, err := ce.client.GetIfExists(ce.key("id", network, address))
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	members, err := ce.client.SMembers(ce.key("members", network, id))
+	if err != nil {
+		return nil, err
+	}
+	evidence, err := ce.evidence(network, id)
+	if err != nil {
+		return nil, err
+	}
+	return &Cluster{
+		ID:       id,
+		Network:  network,
+		Members:  members,
+		Evidence: evidence,
+	}, nil
+}
+
+// MatchBlacklist 查找地址所在聚类中交易时间点生效的黑名单地址，未启用黑名单传递时返回nil
+func (ce *ClusterEngine) MatchBlacklist(blacklist *Blacklist, network, address string, at time.Time) ([]ClusterBlacklistMatch, error) {
+	if !ce.propagate || address == "" {
+		return nil, nil
+	}
+	cluster, err := ce.Get(network, address)
+	if err != nil || cluster == nil {
+		return nil, err
+	}
+
+	var matches []ClusterBlacklistMatch
+	for _, member := range cluster.Members {
+		if strings.EqualFold(member, address) {
+			continue
+		}
+		if entry, ok := blacklist.Match(member, at); ok {
+			matches = append(matches, ClusterBlacklistMatch{
+				Address:   address,
+				ClusterID: cluster.ID,
+				Entry:     *entry,
+			})
+		}
+	}
+	return matches, nil
+}
+
+func (ce *ClusterEngine) key(kind, network, suffix string) string {
+	return fmt.Sprintf("%s:%s:%s:%s", clusterKeyPrefix, kind, network, suffix)
+}
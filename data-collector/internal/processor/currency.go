@@ -0,0 +1,150 @@
+package processor
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"web3-data-collector/internal/config"
+)
+
+const (
+	defaultNativeSymbol       = "ETH"
+	defaultNativeDecimals     = 18
+	defaultHighValueThreshold = "1000"
+	// 异常Gas费用阈值（以原生币为单位）
+	defaultAbnormalGasFee = "100"
+)
+
+// NativeCurrency 网络原生币元数据
+type NativeCurrency struct {
+	Symbol                string
+	Decimals              uint8
+	USDPrice              float64
+	HighValueThreshold    *big.Int // 以最小单位表示
+	HighValueThresholdUSD float64
+	AbnormalGasFee        *big.Int // 以最小单位表示
+	BlockReward           *big.Int // 每个区块的发行奖励，以最小单位表示
+}
+
+// CurrencyRegistry 各网络原生币注册表
+type CurrencyRegistry struct {
+	currencies map[string]*NativeCurrency
+	fallback   *NativeCurrency
+	mu         sync.RWMutex
+}
+
+// NewCurrencyRegistry 根据网络配置创建原生币注册表
+func NewCurrencyRegistry(networks map[string]config.NetworkConfig) *CurrencyRegistry {
+	registry := &CurrencyRegistry{
+		fallback: newNativeCurrency(config.NativeCurrencyConfig{}),
+	}
+	registry.Update(networks)
+
+	return registry
+}
+
+// Update 根据新的网络配置重建原生币元数据（配置热加载）
+func (cr *CurrencyRegistry) Update(networks map[string]config.NetworkConfig) {
+	currencies := make(map[string]*NativeCurrency, len(networks))
+	for name, networkCfg := range networks {
+		currencies[name] = newNativeCurrency(networkCfg.NativeCurrency)
+	}
+
+	cr.mu.Lock()
+	cr.currencies = currencies
+	cr.mu.Unlock()
+}
+
+// newNativeCurrency 根据配置创建原生币元数据，未配置项使用ETH默认值
+func newNativeCurrency(cfg config.NativeCurrencyConfig) *NativeCurrency {
+	currency := &NativeCurrency{
+		Symbol:                cfg.Symbol,
+		Decimals:              cfg.Decimals,
+		USDPrice:              cfg.USDPrice,
+		HighValueThresholdUSD: cfg.HighValueThresholdUSD,
+	}
+
+	if currency.Symbol == "" {
+		currency.Symbol = defaultNativeSymbol
+	}
+	if currency.Decimals == 0 {
+		currency.Decimals = defaultNativeDecimals
+	}
+
+	threshold := cfg.HighValueThreshold
+	if threshold == "" {
+		threshold = defaultHighValueThreshold
+	}
+	currency.HighValueThreshold = currency.parseUnits(threshold)
+	if currency.HighValueThreshold == nil {
+		currency.HighValueThreshold = currency.parseUnits(defaultHighValueThreshold)
+	}
+	currency.AbnormalGasFee = currency.parseUnits(defaultAbnormalGasFee)
+	currency.BlockReward = new(big.Int)
+	if cfg.BlockReward != "" {
+		if reward := currency.parseUnits(cfg.BlockReward); reward != nil {
+			currency.BlockReward = reward
+		}
+	}
+
+	return currency
+}
+
+// Get 获取网络原生币元数据
+func (cr *CurrencyRegistry) Get(network string) *NativeCurrency {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+
+	if currency, exists := cr.currencies[network]; exists {
+		return currency
+	}
+	return cr.fallback
+}
+
+// parseUnits 将原生币单位的十进制字符串转换为最小单位
+func (nc *NativeCurrency) parseUnits(amount string) *big.Int {
+	value, ok := new(big.Float).SetPrec(256).SetString(amount)
+	if !ok {
+		return nil
+	}
+	value.Mul(value, new(big.Float).SetInt(nc.unit()))
+
+	result, _ := value.Int(nil)
+	return result
+}
+
+// unit 返回 10^decimals
+func (nc *NativeCurrency) unit() *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(nc.Decimals)), nil)
+}
+
+// ToUnits 将最小单位金额转换为原生币单位
+func (nc *NativeCurrency) ToUnits(value *big.Int) *big.Float {
+	if value == nil {
+		return new(big.Float)
+	}
+	return new(big.Float).SetPrec(256).Quo(
+		new(big.Float).SetPrec(256).SetInt(value),
+		new(big.Float).SetPrec(256).SetInt(nc.unit()),
+	)
+}
+
+// ToUSD 将最小单位金额转换为美元，未配置价格时返回false
+func (nc *NativeCurrency) ToUSD(value *big.Int) (float64, bool) {
+	if nc.USDPrice <= 0 {
+		return 0, false
+	}
+	units, _ := nc.ToUnits(value).Float64()
+	return units * nc.USDPrice, true
+}
+
+// Format 格式化金额用于展示，如 "1.5 ETH"
+func (nc *NativeCurrency) Format(value *big.Int) string {
+	text := nc.ToUnits(value).Text('f', int(nc.Decimals))
+	if strings.Contains(text, ".") {
+		text = strings.TrimRight(strings.TrimRight(text, "0"), ".")
+	}
+	return fmt.Sprintf("%s %s", text, nc.Symbol)
+}
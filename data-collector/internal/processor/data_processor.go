@@ -1,291 +1,529 @@
 package processor
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"math/big"
 	"strings"
+	"sync"
 	"time"
 
 	"web3-data-collector/internal/config"
 	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/ens"
+	"web3-data-collector/internal/faults"
+	"web3-data-collector/internal/logging"
 	"web3-data-collector/internal/metrics"
 	"web3-data-collector/internal/models"
+	"web3-data-collector/internal/pricing"
 	"web3-data-collector/internal/publisher"
+	"web3-data-collector/internal/siem"
+	"web3-data-collector/internal/stream"
+	"web3-data-collector/internal/watchdog"
+	"web3-data-collector/internal/webhook"
 
 	"github.com/sirupsen/logrus"
 )
 
+// logger processor模块日志，级别可通过logging.modules.processor单独设置
+var logger = logging.Module("processor")
+
 // DataProcessor 数据处理器
 type DataProcessor struct {
 	config           config.DataProcessingConfig
-	kafkaPublisher   *publisher.KafkaPublisher
-	influxClient     *database.InfluxDBClient
-	redisClient      *database.RedisClient
+	sinks            *SinkPipeline
+	eventWindow      EventWindow
+	sloTracker       *SLOTracker
 	metricsManager   *metrics.Manager
 	riskDetector     *RiskDetector
+	riskScorers      *RiskScorers // 未启用风险评分器时为nil
+	scamFeeds        *ScamFeedSyncer // 未启用诈骗地址库同步时为nil
 	filterEngine     *FilterEngine
+	currencies       *CurrencyRegistry
+	clocks           *ClockRegistry
+	sampler          *TransactionSampler
+	priceService     *pricing.Service
+	ensResolver      *ens.Resolver
+	deadLetters      DeadLetterQueue
+	supervisor       *Supervisor
+	approvalDrains   *ApprovalDrainDetector
+	watchlists       *Watchlists
+	watchBalances    *WatchBalanceTracker
+	signatures       *SignatureRegistry
+	tokenFlows       *TokenFlowAggregator
+	velocity         *VelocityTracker
+	supply           *SupplyTracker
+	stablecoins      *StablecoinMonitor
+	bridges          *BridgeMonitor
+	tokenRisk        *TokenRiskMonitor
+	clusters         *ClusterEngine
+	gasOracle        *GasOracle
+	siemForwarder    *siem.Forwarder
+	pipeline         *PipelineStats
+	checkpoints      *BlockCheckpoints // Kafka事务模式下的区块检查点，未启用时为nil
+	memory           *watchdog.MemoryWatchdog
+	replayMu         sync.Mutex
 }
 
 // NewDataProcessor 创建新的数据处理器
 func NewDataProcessor(
 	config config.DataProcessingConfig,
+	networks map[string]config.NetworkConfig,
 	kafkaPublisher *publisher.KafkaPublisher,
 	influxClient *database.InfluxDBClient,
 	redisClient *database.RedisClient,
 	metricsManager *metrics.Manager,
-) *DataProcessor {
-	return &DataProcessor{
-		config:         config,
-		kafkaPublisher: kafkaPublisher,
-		influxClient:   influxClient,
-		redisClient:    redisClient,
-		metricsManager: metricsManager,
-		riskDetector:   NewRiskDetector(),
-		filterEngine:   NewFilterEngine(config.FilterRules),
+	streamHub *stream.Hub,
+	priceService *pricing.Service,
+	ensResolver *ens.Resolver,
+	siemForwarder *siem.Forwarder,
+	webhookDispatcher *webhook.Dispatcher,
+	archiver *publisher.Archiver,
+	memoryWatchdog *watchdog.MemoryWatchdog,
+) (*DataProcessor, error) {
+	currencies := NewCurrencyRegistry(networks)
+
+	// 可用的输出端，实际启用哪些由配置决定
+	available := map[string]Sink{
+		"kafka":  NewKafkaSink(kafkaPublisher),
+		"stream": NewStreamSink(streamHub),
+		"redis":  NewRedisSink(redisClient, config.Warehouse.Enabled, config.AddressStats),
+	}
+	sinkConfigs := config.Sinks
+	if config.Profile == ProfileAlertsOnly {
+		sinkConfigs = alertSinkConfigs(sinkConfigs)
+	} else {
+		available["influxdb"] = NewInfluxSink(influxClient, currencies)
+	}
+	if siemForwarder != nil {
+		available["siem"] = NewSIEMSink(siemForwarder)
+		sinkConfigs = withSink(sinkConfigs, "siem")
+	}
+	if webhookDispatcher != nil {
+		available["webhook"] = NewWebhookSink(webhookDispatcher)
+		sinkConfigs = withSink(sinkConfigs, "webhook")
+	}
+	if archiver != nil {
+		available["archive"] = NewArchiveSink(archiver)
+		sinkConfigs = withSink(sinkConfigs, "archive")
 	}
-}
 
-// ProcessBlock 处理区块数据
-func (dp *DataProcessor) ProcessBlock(block *models.Block) error {
-	startTime := time.Now()
+	sinks, err := NewSinkPipeline(sinkConfigs, available, metricsManager)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sink pipeline: %w", err)
+	}
+	sinks.alertsOnly = config.Profile == ProfileAlertsOnly
+	sinks.aggregator, err = NewAlertAggregator(config.AlertAggregation, sinks.publishAlert, metricsManager)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create alert aggregator: %w", err)
+	}
+	sinks.alerts, err = NewAlertStore(config.AlertStore, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create alert store: %w", err)
+	}
 
-	logrus.Debugf("Processing block %d with %d transactions", block.Number, len(block.Transactions))
+	eventWindow, err := NewEventWindow(config.EventDedup, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create event dedup window: %w", err)
+	}
 
-	// 发布区块数据到Kafka
-	if err := dp.kafkaPublisher.PublishBlock(block); err != nil {
-		logrus.Errorf("Failed to publish block to Kafka: %v", err)
-		dp.metricsManager.IncrementError(block.Network, "kafka_publish_block_error")
+	deadLetters, err := NewDeadLetterQueue(config.DeadLetter, redisClient, kafkaPublisher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dead letter queue: %w", err)
+	}
+	sinks.deadLetters = deadLetters
+	if kafkaPublisher != nil {
+		kafkaPublisher.SetDeliveryHandler(sinks.HandleKafkaDelivery)
 	}
+	if influxClient != nil {
+		influxClient.SetWriteFailedHandler(sinks.HandleInfluxWriteFailure)
+	}
+	pipeline := NewPipelineStats()
+	sinks.stats = pipeline
 
-	// 存储区块指标到InfluxDB
-	if err := dp.storeBlockMetrics(block); err != nil {
-		logrus.Errorf("Failed to store block metrics: %v", err)
-		dp.metricsManager.IncrementError(block.Network, "influxdb_store_error")
+	approvalDrains, err := NewApprovalDrainDetector(config.ApprovalDrain, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create approval drain detector: %w", err)
 	}
 
-	// 处理区块中的每个交易
-	for _, tx := range block.Transactions {
-		if err := dp.ProcessTransaction(&tx); err != nil {
-			logrus.Errorf("Failed to process transaction %s: %v", tx.Hash, err)
-			continue
-		}
+	watchlists, err := NewWatchlists(config.Watchlists, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create watchlists: %w", err)
 	}
 
-	// 更新Redis中的最新区块信息
-	if err := dp.updateLatestBlockInfo(block); err != nil {
-		logrus.Errorf("Failed to update latest block info: %v", err)
+	watchBalances, err := NewWatchBalanceTracker(config.Watchlists.Balances, redisClient, influxClient, currencies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create watch balance tracker: %w", err)
 	}
 
-	processingTime := time.Since(startTime)
-	dp.metricsManager.RecordBlockProcessingTime(block.Network, processingTime)
+	signatures, err := NewSignatureRegistry(config.MethodSignatures, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create method signature registry: %w", err)
+	}
 
-	logrus.Debugf("Block %d processed in %v", block.Number, processingTime)
+	txDedup, err := NewTransactionDedup(config.TxDedup, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transaction dedup: %w", err)
+	}
 
-	return nil
-}
+	tokenFlows, err := NewTokenFlowAggregator(config.TokenFlows, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token flow aggregator: %w", err)
+	}
 
-// ProcessTransaction 处理单个交易
-func (dp *DataProcessor) ProcessTransaction(tx *models.Transaction) error {
-	startTime := time.Now()
+	mixers, err := NewMixerTracker(config.Mixer, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mixer tracker: %w", err)
+	}
 
-	// 应用过滤规则
-	filterResult := dp.filterEngine.ShouldProcess(tx)
-	if !filterResult.ShouldProcess {
-		logrus.Debugf("Transaction %s filtered out: %s", tx.Hash, strings.Join(filterResult.FilteredReasons, ", "))
-		return nil
+	clocks := NewClockRegistry(networks, metricsManager)
+
+	velocity, err := NewVelocityTracker(config.Velocity, redisClient, currencies, clocks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create velocity tracker: %w", err)
 	}
 
-	// 发布交易数据到Kafka
-	if err := dp.kafkaPublisher.PublishTransaction(tx); err != nil {
-		logrus.Errorf("Failed to publish transaction to Kafka: %v", err)
-		dp.metricsManager.IncrementError(tx.Network, "kafka_publish_tx_error")
+	supply, err := NewSupplyTracker(config.Supply, redisClient, currencies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create supply tracker: %w", err)
 	}
 
-	// 存储交易指标到InfluxDB
-	if err := dp.storeTransactionMetrics(tx); err != nil {
-		logrus.Errorf("Failed to store transaction metrics: %v", err)
+	stablecoins, err := NewStablecoinMonitor(config.Stablecoins, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stablecoin monitor: %w", err)
 	}
 
-	// 风险检测
-	riskResult := dp.riskDetector.AnalyzeTransaction(tx)
-	if riskResult.RiskDetected {
-		alert := dp.createRiskAlert(tx, riskResult)
-		if err := dp.kafkaPublisher.PublishAlert(alert); err != nil {
-			logrus.Errorf("Failed to publish risk alert: %v", err)
-		}
-		
-		// 记录高风险交易到Redis
-		if err := dp.recordHighRiskTransaction(tx, riskResult); err != nil {
-			logrus.Errorf("Failed to record high risk transaction: %v", err)
-		}
+	bridges, err := NewBridgeMonitor(config.Bridges, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bridge monitor: %w", err)
 	}
 
-	// 更新地址统计信息
-	if err := dp.updateAddressStats(tx); err != nil {
-		logrus.Errorf("Failed to update address stats: %v", err)
+	tokenRisk, err := NewTokenRiskMonitor(config.TokenRisk, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token risk monitor: %w", err)
 	}
 
-	processingTime := time.Since(startTime)
-	dp.metricsManager.RecordTransactionProcessingTime(tx.Network, processingTime)
-	dp.metricsManager.IncrementTransactionsProcessed(tx.Network)
+	clusters, err := NewClusterEngine(config.Clusters, redisClient, metricsManager)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cluster engine: %w", err)
+	}
 
-	return nil
-}
+	riskDetector, err := NewRiskDetector(currencies, mixers, velocity, clusters, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create risk detector: %w", err)
+	}
 
-// storeBlockMetrics 存储区块指标到InfluxDB
-func (dp *DataProcessor) storeBlockMetrics(block *models.Block) error {
-	point := map[string]interface{}{
-		"number":      block.Number,
-		"tx_count":    block.TxCount,
-		"gas_used":    block.GasUsed,
-		"gas_limit":   block.GasLimit,
-		"size":        block.Size,
-		"difficulty":  block.Difficulty.String(),
+	riskScorers, err := NewRiskScorers(config.RiskScoring, metricsManager)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create risk scorers: %w", err)
 	}
 
-	if block.BaseFeePerGas != nil {
-		point["base_fee"] = block.BaseFeePerGas.String()
+	sloTracker, err := NewSLOTracker(config.SLO, metricsManager)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create slo tracker: %w", err)
 	}
 
-	tags := map[string]string{
-		"network": block.Network,
-		"miner":   block.Miner,
+	dp := &DataProcessor{
+		config:         config,
+		sinks:          sinks,
+		eventWindow:    eventWindow,
+		sloTracker:     sloTracker,
+		metricsManager: metricsManager,
+		riskDetector:   riskDetector,
+		riskScorers:    riskScorers,
+		filterEngine:   NewFilterEngine(config.FilterRules, watchlists, txDedup, metricsManager),
+		currencies:     currencies,
+		clocks:         clocks,
+		sampler:        NewTransactionSampler(networks),
+		priceService:   priceService,
+		ensResolver:    ensResolver,
+		deadLetters:    deadLetters,
+		supervisor:     NewSupervisor(config.Quarantine, redisClient, metricsManager),
+		approvalDrains: approvalDrains,
+		watchlists:     watchlists,
+		watchBalances:  watchBalances,
+		signatures:     signatures,
+		tokenFlows:     tokenFlows,
+		velocity:       velocity,
+		supply:         supply,
+		stablecoins:    stablecoins,
+		bridges:        bridges,
+		tokenRisk:      tokenRisk,
+		clusters:       clusters,
+		gasOracle:      NewGasOracle(config.GasOracle),
+		siemForwarder:  siemForwarder,
+		pipeline:       pipeline,
+		memory:         memoryWatchdog,
+	}
+	if kafkaPublisher != nil && kafkaPublisher.Transactional() {
+		dp.checkpoints = NewBlockCheckpoints(redisClient)
+	}
+	dp.riskDetector.SetRiskyMethods(config.MethodSignatures.RiskyMethods)
+	dp.scamFeeds, err = NewScamFeedSyncer(config.ScamFeeds, dp.riskDetector, metricsManager)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scam feed syncer: %w", err)
 	}
+	dp.supervisor.RegisterResubmitter(StageTransaction, dp.resubmitTransaction)
 
-	return dp.influxClient.WritePoint("blocks", tags, point, block.Timestamp)
+	return dp, nil
 }
 
-// storeTransactionMetrics 存储交易指标到InfluxDB
-func (dp *DataProcessor) storeTransactionMetrics(tx *models.Transaction) error {
-	point := map[string]interface{}{
-		"value":           tx.Value.String(),
-		"gas":             tx.Gas,
-		"gas_price":       tx.GasPrice.String(),
-		"gas_used":        tx.GasUsed,
-		"is_contract":     tx.IsContractCall,
-		"is_token":        tx.IsTokenTransfer,
-		"transaction_type": tx.TransactionType,
-	}
+// ProcessBlock 处理区块数据，返回包含风险结果的完整区块
+func (dp *DataProcessor) ProcessBlock(ctx context.Context, block *models.Block) (*models.EnrichedBlock, error) {
+	startTime := time.Now()
+	ctx = logging.WithFields(ctx, logrus.Fields{"network": block.Network, "block": block.Number})
 
-	if tx.MaxFeePerGas != nil {
-		point["max_fee_per_gas"] = tx.MaxFeePerGas.String()
+	logger.WithContext(ctx).Debugf("Processing block %d with %d transactions", block.Number, len(block.Transactions))
+
+	// 记录接收时间并检测时钟偏差，收集器未设置接收时间时取当前时间
+	receivedAt := block.ReceivedAt
+	if receivedAt.IsZero() {
+		receivedAt = startTime
 	}
+	dp.clocks.Annotate(block, receivedAt)
 
-	if tx.MaxPriorityFeePerGas != nil {
-		point["max_priority_fee_per_gas"] = tx.MaxPriorityFeePerGas.String()
+	// 发布区块数据到各输出端
+	if err := dp.sinks.PublishBlock(block); err != nil {
+		return nil, err
 	}
 
-	tags := map[string]string{
-		"network":      tx.Network,
-		"from_address": tx.FromAddress,
-		"to_address":   tx.ToAddress,
+	// Kafka事务模式：区块内的交易消息缓冲到区块处理完成后一次性写出，已提交的区块不再写出
+	if dp.checkpoints != nil {
+		dp.sinks.BeginBlock(block.Network, block.Number, dp.checkpoints.Committed(block))
+		// 任一出错返回时丢弃缓冲，提交后缓冲已取出，AbortBlock不再有作用
+		defer dp.sinks.AbortBlock(block.Network, block.Number)
 	}
 
-	return dp.influxClient.WritePoint("transactions", tags, point, tx.Timestamp)
-}
+	enriched := &models.EnrichedBlock{
+		Block:  block,
+		Alerts: []*models.RiskAlert{},
+	}
 
-// updateLatestBlockInfo 更新最新区块信息到Redis
-func (dp *DataProcessor) updateLatestBlockInfo(block *models.Block) error {
-	key := fmt.Sprintf("latest_block:%s", block.Network)
-	data := map[string]interface{}{
-		"number":    block.Number,
-		"hash":      block.Hash,
-		"timestamp": block.Timestamp.Unix(),
-		"tx_count":  block.TxCount,
+	// 处理区块中的每个交易，单笔交易panic或反复失败时隔离该交易并继续处理
+	for i := range block.Transactions {
+		tx := &block.Transactions[i]
+		txCtx := logging.WithFields(ctx, logrus.Fields{"tx": tx.Hash})
+		var alert *models.RiskAlert
+		err := dp.supervisor.Attempt(StageTransaction, block.Network, tx.Hash, tx, func() error {
+			var err error
+			alert, err = dp.processTransaction(txCtx, tx)
+			return err
+		})
+		if err != nil {
+			logger.WithContext(txCtx).Errorf("Failed to process transaction %s: %v", tx.Hash, err)
+			continue
+		}
+		if alert != nil {
+			enriched.Alerts = append(enriched.Alerts, alert)
+		}
 	}
 
-	return dp.redisClient.HMSet(key, data)
-}
+	// 发布信标链提款，提款金额计入接收地址的统计
+	for i := range block.Withdrawals {
+		if err := dp.sinks.PublishWithdrawal(&block.Withdrawals[i]); err != nil {
+			return nil, err
+		}
+	}
 
-// updateAddressStats 更新地址统计信息
-func (dp *DataProcessor) updateAddressStats(tx *models.Transaction) error {
-	// 更新发送方地址统计
-	if err := dp.updateSingleAddressStats(tx.FromAddress, tx, true); err != nil {
-		return err
+	// 区块级MEV分析，内存降载时跳过
+	var attacks []*SandwichAttack
+	if !dp.shedding(watchdog.LevelShedEnrichment) {
+		if err := dp.supervisor.Guard(StageMEV, block.Network, fmt.Sprint(block.Number), block, func() error {
+			attacks = dp.riskDetector.DetectSandwiches(block)
+			return nil
+		}); err != nil {
+			logger.WithContext(ctx).Errorf("MEV analysis failed for block %d: %v", block.Number, err)
+		}
+	}
+	for _, attack := range attacks {
+		alert := dp.createSandwichAlert(attack)
+		logger.WithContext(ctx).Warnf("%s: %s", alert.Title, alert.Description)
+		if err := dp.PublishOpsAlert(alert); err != nil {
+			logger.WithContext(ctx).Errorf("Failed to publish sandwich alert for block %d: %v", block.Number, err)
+			continue
+		}
+		enriched.Alerts = append(enriched.Alerts, alert)
 	}
 
-	// 更新接收方地址统计
-	if tx.ToAddress != "" {
-		if err := dp.updateSingleAddressStats(tx.ToAddress, tx, false); err != nil {
-			return err
+	if dp.checkpoints != nil {
+		if err := dp.commitBlock(block); err != nil {
+			return nil, err
 		}
 	}
 
-	return nil
+	processingTime := time.Since(startTime)
+	dp.metricsManager.RecordBlockProcessingTime(block.Network, processingTime)
+
+	logger.WithContext(ctx).Debugf("Block %d processed in %v", block.Number, processingTime)
+
+	return enriched, nil
 }
 
-// updateSingleAddressStats 更新单个地址统计
-func (dp *DataProcessor) updateSingleAddressStats(address string, tx *models.Transaction, isSender bool) error {
-	key := fmt.Sprintf("address_stats:%s:%s", tx.Network, address)
-	
-	// 获取当前统计
-	stats, err := dp.redisClient.HGetAll(key)
-	if err != nil {
-		// 如果不存在，创建新的统计
-		stats = make(map[string]string)
+// ProcessTransaction 处理单个交易
+func (dp *DataProcessor) ProcessTransaction(tx *models.Transaction) error {
+	_, err := dp.processTransaction(context.Background(), tx)
+	return err
+}
+
+// ReplayTransaction 回放单个历史交易，检测到风险时返回生成的告警
+func (dp *DataProcessor) ReplayTransaction(tx *models.Transaction) (*models.RiskAlert, error) {
+	return dp.processTransaction(context.Background(), tx)
+}
+
+// resubmitTransaction 重新处理被隔离的交易
+func (dp *DataProcessor) resubmitTransaction(network string, payload json.RawMessage) error {
+	var tx models.Transaction
+	if err := json.Unmarshal(payload, &tx); err != nil {
+		return &faults.DecodeError{Network: network, Kind: "payload", Ref: "transaction", Err: err}
 	}
+	_, err := dp.processTransaction(context.Background(), &tx)
+	return err
+}
 
-	// 更新统计信息
-	if isSender {
-		// 更新发送统计
-		if err := dp.incrementCounterInMap(stats, "sent_count"); err != nil {
-			return err
+// processTransaction 处理单个交易，检测到风险时返回生成的告警，ctx只用于传递日志字段
+func (dp *DataProcessor) processTransaction(ctx context.Context, tx *models.Transaction) (*models.RiskAlert, error) {
+	startTime := time.Now()
+	ctx = logging.WithFields(ctx, logrus.Fields{"network": tx.Network, "tx": tx.Hash})
+
+	// 解析调用的函数名，供过滤规则及风险检测按函数名匹配
+	dp.decodeMethod(tx)
+
+	// 应用过滤规则，关注地址告警不受过滤规则影响，但重复交易不再告警
+	filterResult := dp.filterEngine.ShouldProcess(tx)
+	if !filterResult.Duplicate {
+		dp.processWatchedTransaction(tx)
+	}
+	if !filterResult.ShouldProcess {
+		logger.WithContext(ctx).Debugf("Transaction %s filtered out: %s", tx.Hash, strings.Join(filterResult.FilteredReasons, ", "))
+		return nil, nil
+	}
+
+	// 处理失败或panic时移除去重记录，重试时不被视为重复交易
+	completed := false
+	defer func() {
+		if !completed {
+			dp.filterEngine.ForgetTransaction(tx)
 		}
-		if err := dp.addValueInMap(stats, "sent_volume", tx.Value); err != nil {
-			return err
+	}()
+
+	// 计算美元价值（大额判断需要）及解析ENS名称，告警专用部署跳过ENS解析
+	dp.enrichUSDValue(tx)
+	if !dp.AlertsOnly() {
+		dp.enrichENS(tx)
+	}
+
+	// 发布交易数据到各输出端，按网络抽样配置或内存降载抽样时只发布部分交易，风险检测不受影响
+	publish, sampleRate := dp.sampler.Sample(tx, filterResult)
+	if !publish {
+		dp.metricsManager.RecordSampledOutTransaction(tx.Network)
+	} else if dp.memory == nil || dp.memory.Sample(tx.Hash) {
+		tx.SampleRate = sampleRate
+		if err := dp.sinks.PublishTransaction(tx); err != nil {
+			return nil, err
 		}
 	} else {
-		// 更新接收统计
-		if err := dp.incrementCounterInMap(stats, "received_count"); err != nil {
-			return err
-		}
-		if err := dp.addValueInMap(stats, "received_volume", tx.Value); err != nil {
-			return err
+		dp.metricsManager.RecordShedTransaction(tx.Network)
+	}
+
+	// 风险检测
+	var alert *models.RiskAlert
+	riskResult := dp.riskDetector.AnalyzeTransaction(tx)
+	// 外部评分器在内存降载时跳过，只使用规则检测结果
+	if dp.riskScorers != nil && !dp.shedding(watchdog.LevelShedEnrichment) && dp.riskScorers.Apply(tx, riskResult) {
+		riskResult.RiskLevel = dp.riskDetector.calculateRiskLevel(riskResult.RiskScore)
+	}
+	if riskResult.RiskDetected {
+		alert = dp.createRiskAlert(tx, riskResult)
+		if err := dp.sinks.PublishAlert(alert); err != nil {
+			return nil, err
 		}
 	}
 
-	// 更新最后活动时间
-	stats["last_activity"] = fmt.Sprintf("%d", tx.Timestamp.Unix())
+	processingTime := time.Since(startTime)
+	dp.metricsManager.RecordTransactionProcessingTime(tx.Network, processingTime)
+	dp.metricsManager.IncrementTransactionsProcessed(tx.Network)
+
+	completed = true
+	return alert, nil
+}
+
+// PublishHeader 发布区块头摘要（双阶段发布的第一阶段）
+func (dp *DataProcessor) PublishHeader(header *models.BlockHeader) error {
+	if !dp.config.DualPublishing {
+		return nil
+	}
+	return dp.sinks.PublishHeader(header)
+}
 
-	// 设置首次见到时间（如果不存在）
-	if _, exists := stats["first_seen"]; !exists {
-		stats["first_seen"] = fmt.Sprintf("%d", tx.Timestamp.Unix())
+// PublishEnrichedBlock 发布处理完成的完整区块（双阶段发布的第二阶段），并计入处理延迟SLO
+func (dp *DataProcessor) PublishEnrichedBlock(enriched *models.EnrichedBlock) error {
+	if dp.sloTracker != nil {
+		latency := enriched.ProcessedAt.Sub(enriched.ObservedAt)
+		for _, alert := range dp.sloTracker.Record(enriched.Block.Network, latency, enriched.ProcessedAt) {
+			logger.Warnf("%s: %s", alert.Title, alert.Description)
+			if err := dp.PublishOpsAlert(alert); err != nil {
+				logger.Errorf("Failed to publish SLO alert for %s: %v", alert.Network, err)
+			}
+		}
 	}
 
-	// 保存到Redis
-	return dp.redisClient.HMSetString(key, stats)
+	if !dp.config.DualPublishing {
+		return nil
+	}
+	return dp.sinks.PublishEnrichedBlock(enriched)
 }
 
-// incrementCounterInMap 在map中递增计数器
-func (dp *DataProcessor) incrementCounterInMap(stats map[string]string, key string) error {
-	currentValue := int64(0)
-	if val, exists := stats[key]; exists {
-		if parsed, err := parseInt64(val); err == nil {
-			currentValue = parsed
+// enrichUSDValue 计算交易的美元价值，无实时价格时使用配置的原生币价格
+func (dp *DataProcessor) enrichUSDValue(tx *models.Transaction) {
+	currency := dp.currencies.Get(tx.Network)
+
+	var usdValue float64
+	var priced bool
+
+	if dp.priceService != nil && !dp.shedding(watchdog.LevelShedEnrichment) {
+		if price, ok := dp.priceService.NativePriceUSD(tx.Network); ok {
+			units, _ := currency.ToUnits(tx.Value).Float64()
+			usdValue, priced = units*price, true
+		}
+
+		// 代币转账按合约价格计入美元价值
+		if tx.IsTokenTransfer && tx.TokenAmount != nil {
+			if tokenValue, ok := dp.priceService.TokenValueUSD(tx.Network, tx.ToAddress, tx.TokenAmount, tx.TokenDecimals); ok {
+				usdValue += tokenValue
+				priced = true
+			}
 		}
 	}
-	stats[key] = fmt.Sprintf("%d", currentValue+1)
-	return nil
+
+	if !priced {
+		usdValue, priced = currency.ToUSD(tx.Value)
+	}
+
+	if priced {
+		tx.USDValue = usdValue
+	}
 }
 
-// addValueInMap 在map中累加数值
-func (dp *DataProcessor) addValueInMap(stats map[string]string, key string, value *big.Int) error {
-	currentValue := big.NewInt(0)
-	if val, exists := stats[key]; exists {
-		if parsed, ok := currentValue.SetString(val, 10); ok {
-			currentValue = parsed
+// enrichENS 为交易的发起方及接收方附加主ENS名称，内存降载时跳过
+func (dp *DataProcessor) enrichENS(tx *models.Transaction) {
+	if dp.ensResolver == nil || tx.Chain == models.ChainSolana || dp.shedding(watchdog.LevelShedEnrichment) {
+		return
+	}
+
+	if name, ok := dp.ensResolver.LookupAddress(tx.FromAddress); ok {
+		tx.FromENS = name
+	}
+	if tx.ToAddress != "" {
+		if name, ok := dp.ensResolver.LookupAddress(tx.ToAddress); ok {
+			tx.ToENS = name
 		}
 	}
-	currentValue = currentValue.Add(currentValue, value)
-	stats[key] = currentValue.String()
-	return nil
 }
 
 // createRiskAlert 创建风险告警
 func (dp *DataProcessor) createRiskAlert(tx *models.Transaction, riskResult *RiskResult) *models.RiskAlert {
-	return &models.RiskAlert{
-		ID:              fmt.Sprintf("alert_%s_%d", tx.Hash, time.Now().UnixNano()),
+	alert := &models.RiskAlert{
+		ID:              models.AlertID(riskResult.RiskType, tx.ID),
 		Type:            riskResult.RiskType,
 		Level:           riskResult.RiskLevel,
 		Title:           riskResult.Title,
@@ -304,29 +542,306 @@ func (dp *DataProcessor) createRiskAlert(tx *models.Transaction, riskResult *Ris
 		Timestamp: tx.Timestamp,
 		Status:    "ACTIVE",
 	}
+
+	// 按网络原生币展示金额及美元估值
+	currency := dp.currencies.Get(tx.Network)
+	alert.Metadata["native_symbol"] = currency.Symbol
+	alert.Metadata["value_display"] = currency.Format(tx.Value)
+	if tx.USDValue > 0 {
+		alert.Metadata["value_usd"] = tx.USDValue
+	}
+	if tx.FromENS != "" {
+		alert.Metadata["from_ens"] = tx.FromENS
+	}
+	if tx.ToENS != "" {
+		alert.Metadata["to_ens"] = tx.ToENS
+	}
+
+	// 记录触发告警的黑名单版本
+	if len(riskResult.BlacklistMatches) > 0 {
+		alert.Metadata["blacklist_version"] = riskResult.BlacklistVersion
+		alert.Metadata["blacklist_entries"] = riskResult.BlacklistMatches
+	}
+
+	// 记录聚类中命中的黑名单地址
+	if len(riskResult.ClusterMatches) > 0 {
+		alert.Metadata["blacklist_version"] = riskResult.BlacklistVersion
+		alert.Metadata["cluster_matches"] = riskResult.ClusterMatches
+	}
+
+	// 记录可疑合约的来源标记
+	if len(riskResult.SuspiciousTags) > 0 {
+		alert.Metadata["suspicious_tags"] = riskResult.SuspiciousTags
+	}
+
+	// 记录混币器交互及地址的混币暴露分
+	if riskResult.MixerInteraction != nil {
+		alert.Metadata["mixer_interaction"] = riskResult.MixerInteraction
+	}
+	if riskResult.MixerExposure > 0 {
+		alert.Metadata["mixer_exposure"] = riskResult.MixerExposure
+	}
+
+	// 记录发起方滑动窗口内的转出统计及历史平均
+	if riskResult.Velocity != nil {
+		alert.Metadata["velocity"] = riskResult.Velocity
+	}
+
+	// 记录合并前的规则检测分及各评分器的评分
+	if len(riskResult.ScorerScores) > 0 {
+		alert.Metadata["rule_score"] = riskResult.RuleScore
+		alert.Metadata["scorer_scores"] = riskResult.ScorerScores
+	}
+
+	return alert
+}
+
+// createSandwichAlert 创建三明治攻击告警，交易哈希为受害者交易，地址为攻击者
+func (dp *DataProcessor) createSandwichAlert(attack *SandwichAttack) *models.RiskAlert {
+	alert := &models.RiskAlert{
+		ID:              models.AlertID("MEV_SANDWICH", attack.VictimTx.ID),
+		Type:            "MEV_SANDWICH",
+		Level:           "HIGH",
+		Title:           "三明治攻击",
+		Description:     fmt.Sprintf("地址 %s 在区块 %d 中包夹了 %s 的兑换交易", attack.Attacker, attack.Block, attack.Victim),
+		TransactionHash: attack.VictimTx.Hash,
+		Address:         attack.Attacker,
+		Network:         attack.Network,
+		RiskScore:       0.7,
+		RiskFactors:     []string{"mev_sandwich"},
+		Metadata: map[string]interface{}{
+			"block_number":              attack.Block,
+			"attacker":                  attack.Attacker,
+			"victim":                    attack.Victim,
+			"front_run_tx":              attack.FrontRun.Hash,
+			"victim_tx":                 attack.VictimTx.Hash,
+			"back_run_tx":               attack.BackRun.Hash,
+			"token_in":                  attack.TokenIn,
+			"token_out":                 attack.TokenOut,
+			"estimated_extracted_value": attack.EstimatedValue.String(),
+		},
+		Timestamp: attack.VictimTx.Timestamp,
+		Status:    "ACTIVE",
+	}
+
+	// 获利以原生币计价时展示金额及美元估值
+	if attack.NativeValue {
+		currency := dp.currencies.Get(attack.Network)
+		alert.Metadata["native_symbol"] = currency.Symbol
+		alert.Metadata["value_display"] = currency.Format(attack.EstimatedValue)
+		if usdValue, priced := currency.ToUSD(attack.EstimatedValue); priced {
+			alert.Metadata["value_usd"] = usdValue
+		}
+	}
+
+	return alert
+}
+
+// ProcessEvent 处理合约事件，重复事件被忽略，被重组撤回的事件发布撤回记录
+func (dp *DataProcessor) ProcessEvent(event *models.Event) error {
+	_, err := dp.publishEvent(event)
+	return err
 }
 
-// recordHighRiskTransaction 记录高风险交易
-func (dp *DataProcessor) recordHighRiskTransaction(tx *models.Transaction, riskResult *RiskResult) error {
-	key := fmt.Sprintf("high_risk_tx:%s", tx.Network)
-	
-	record := map[string]interface{}{
-		"hash":         tx.Hash,
-		"from_address": tx.FromAddress,
-		"to_address":   tx.ToAddress,
-		"value":        tx.Value.String(),
-		"risk_score":   riskResult.RiskScore,
-		"risk_type":    riskResult.RiskType,
-		"timestamp":    tx.Timestamp.Unix(),
+// publishEvent 去重并发布事件，返回是否首次发布了该事件（撤回记录不计）
+func (dp *DataProcessor) publishEvent(event *models.Event) (bool, error) {
+	key := EventKey{
+		Network:         event.Network,
+		TransactionHash: event.TransactionHash,
+		LogIndex:        event.LogIndex,
 	}
 
-	data, err := json.Marshal(record)
+	if event.Removed {
+		published, err := dp.eventWindow.Remove(key)
+		if err != nil {
+			return false, fmt.Errorf("failed to check published event %s: %w", key, err)
+		}
+		if !published {
+			logger.Debugf("Removed event %s was never published, skipping retraction", key)
+			return false, nil
+		}
+
+		logger.Infof("Retracting event %s after chain reorganization", key)
+		return false, dp.sinks.PublishEvent(event)
+	}
+
+	added, err := dp.eventWindow.Add(key, event.BlockNumber)
 	if err != nil {
-		return err
+		return false, fmt.Errorf("failed to record event %s: %w", key, err)
 	}
+	if !added {
+		logger.Debugf("Event %s already published, skipping", key)
+		return false, nil
+	}
+
+	if err := dp.sinks.PublishEvent(event); err != nil {
+		// 发布失败时移除记录，允许重试
+		if _, removeErr := dp.eventWindow.Remove(key); removeErr != nil {
+			logger.Errorf("Failed to remove event %s from dedup window: %v", key, removeErr)
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// PublishOpsAlert 发布运维告警（如网络被自动停用）
+func (dp *DataProcessor) PublishOpsAlert(alert *models.RiskAlert) error {
+	return dp.sinks.PublishAlert(alert)
+}
+
+// ReplayDeadLetters 重放死信队列中至多limit条数据，重放失败的数据重新写入死信队列
+func (dp *DataProcessor) ReplayDeadLetters(limit int) (*models.DeadLetterReplayResult, error) {
+	if dp.deadLetters == nil {
+		return nil, fmt.Errorf("dead letter queue is not enabled")
+	}
+
+	// 同一时间只允许一个重放任务
+	dp.replayMu.Lock()
+	defer dp.replayMu.Unlock()
+
+	result := &models.DeadLetterReplayResult{}
+	fetched, err := dp.deadLetters.Drain(limit, func(entry *models.DeadLetter) {
+		replayErr := dp.sinks.Replay(entry)
+		if replayErr == nil {
+			result.Replayed++
+			return
+		}
+
+		result.Failed++
+		result.Errors = append(result.Errors, fmt.Sprintf("%s %s/%s: %v", entry.ID, entry.Sink, entry.Kind, replayErr))
+
+		entry.ID = ""
+		entry.Error = replayErr.Error()
+		entry.Attempts++
+		entry.FailedAt = time.Now()
+		if err := dp.deadLetters.Push(entry); err != nil {
+			logger.Errorf("Failed to requeue dead letter %s/%s: %v", entry.Sink, entry.Kind, err)
+		}
+	})
+	result.Fetched = fetched
+
+	logger.Infof("Dead letter replay finished: %d fetched, %d replayed, %d failed", result.Fetched, result.Replayed, result.Failed)
+	return result, err
+}
+
+// Close 停止输出端的发布重试，关闭服务时在排空处理中的区块之前调用
+func (dp *DataProcessor) Close() {
+	dp.sinks.Close()
+}
+
+// ApplyConfig 应用热加载的过滤规则与各网络风险阈值
+func (dp *DataProcessor) ApplyConfig(cfg config.DataProcessingConfig, networks map[string]config.NetworkConfig) {
+	dp.filterEngine.Update(cfg.FilterRules)
+	dp.currencies.Update(networks)
+	dp.clocks.Update(networks)
+	dp.sampler.Update(networks)
+	logger.Info("Applied reloaded filter rules and risk thresholds")
+}
+
+// Supervisor 获取处理阶段监督器
+func (dp *DataProcessor) Supervisor() *Supervisor {
+	return dp.supervisor
+}
+
+// MemoryWatchdog 获取内存看门狗，未启用时为nil
+func (dp *DataProcessor) MemoryWatchdog() *watchdog.MemoryWatchdog {
+	return dp.memory
+}
+
+// AlertAggregator 获取告警聚合器，未启用时为nil
+func (dp *DataProcessor) AlertAggregator() *AlertAggregator {
+	return dp.sinks.aggregator
+}
+
+// AlertStore 获取告警存储，未启用时为nil
+func (dp *DataProcessor) AlertStore() *AlertStore {
+	return dp.sinks.alerts
+}
+
+// shedding 判断是否已达到指定的内存降载级别
+func (dp *DataProcessor) shedding(level int) bool {
+	return dp.memory != nil && dp.memory.Shedding(level)
+}
+
+// FilterEngine 获取过滤引擎
+func (dp *DataProcessor) FilterEngine() *FilterEngine {
+	return dp.filterEngine
+}
+
+// Watchlists 获取地址关注列表，未启用时为nil
+func (dp *DataProcessor) Watchlists() *Watchlists {
+	return dp.watchlists
+}
+
+// WatchBalances 获取关注地址余额跟踪，未启用时为nil
+func (dp *DataProcessor) WatchBalances() *WatchBalanceTracker {
+	return dp.watchBalances
+}
+
+// Signatures 获取函数签名库，未启用时为nil
+func (dp *DataProcessor) Signatures() *SignatureRegistry {
+	return dp.signatures
+}
+
+// ApprovalDrains 获取授权盗取检测器，未启用时为nil
+func (dp *DataProcessor) ApprovalDrains() *ApprovalDrainDetector {
+	return dp.approvalDrains
+}
+
+// TokenFlows 获取代币流向汇总，未启用时为nil
+func (dp *DataProcessor) TokenFlows() *TokenFlowAggregator {
+	return dp.tokenFlows
+}
+
+// Velocity 获取转出频率跟踪器，未启用时为nil
+func (dp *DataProcessor) Velocity() *VelocityTracker {
+	return dp.velocity
+}
+
+// Supply 获取供应量统计，未启用时为nil
+func (dp *DataProcessor) Supply() *SupplyTracker {
+	return dp.supply
+}
+
+// Stablecoins 获取稳定币铸造/销毁监控，未启用时为nil
+func (dp *DataProcessor) Stablecoins() *StablecoinMonitor {
+	return dp.stablecoins
+}
+
+// Bridges 获取跨链桥监控，未启用时为nil
+func (dp *DataProcessor) Bridges() *BridgeMonitor {
+	return dp.bridges
+}
+
+// TokenRisk 获取代币风险检测，未启用时为nil
+func (dp *DataProcessor) TokenRisk() *TokenRiskMonitor {
+	return dp.tokenRisk
+}
+
+// Clusters 获取地址聚类，未启用时为nil
+func (dp *DataProcessor) Clusters() *ClusterEngine {
+	return dp.clusters
+}
+
+// ENS 获取ENS解析器，未启用时为nil
+func (dp *DataProcessor) ENS() *ens.Resolver {
+	return dp.ensResolver
+}
+
+// RiskScorers 获取风险评分器，未启用时为nil
+func (dp *DataProcessor) RiskScorers() *RiskScorers {
+	return dp.riskScorers
+}
+
+// ScamFeeds 获取诈骗地址库同步任务，未启用时为nil
+func (dp *DataProcessor) ScamFeeds() *ScamFeedSyncer {
+	return dp.scamFeeds
+}
 
-	// 使用交易哈希作为分数，时间戳作为值
-	return dp.redisClient.ZAdd(key, float64(tx.Timestamp.Unix()), string(data))
+// RiskDetector 获取风险检测器
+func (dp *DataProcessor) RiskDetector() *RiskDetector {
+	return dp.riskDetector
 }
 
 // 辅助函数
@@ -0,0 +1,144 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/models"
+	"web3-data-collector/internal/publisher"
+)
+
+// deadLetterFetchTimeout kafka后端重放时等待新消息的超时，超时视为已读完
+const deadLetterFetchTimeout = 3 * time.Second
+
+// DeadLetterQueue 输出端发布失败数据的死信队列
+type DeadLetterQueue interface {
+	// Push 保存一条死信
+	Push(entry *models.DeadLetter) error
+	// Drain 读取至多limit条死信交给handle处理，处理后从队列移除
+	Drain(limit int, handle func(entry *models.DeadLetter)) (int, error)
+}
+
+// NewDeadLetterQueue 根据配置创建死信队列，未启用时返回nil
+func NewDeadLetterQueue(cfg config.DeadLetterConfig, redisClient *database.RedisClient, kafkaPublisher *publisher.KafkaPublisher) (DeadLetterQueue, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	switch cfg.Backend {
+	case "", "redis":
+		stream := cfg.Stream
+		if stream == "" {
+			stream = "dead_letters"
+		}
+		return &redisDeadLetterQueue{client: redisClient, stream: stream, maxLen: cfg.MaxLen}, nil
+	case "kafka":
+		groupID := cfg.ConsumerGroup
+		if groupID == "" {
+			groupID = "web3-dead-letter-replay"
+		}
+		return &kafkaDeadLetterQueue{publisher: kafkaPublisher, groupID: groupID}, nil
+	default:
+		return nil, fmt.Errorf("unknown dead_letter backend: %s", cfg.Backend)
+	}
+}
+
+// redisDeadLetterQueue 基于Redis stream的死信队列
+type redisDeadLetterQueue struct {
+	client *database.RedisClient
+	stream string
+	maxLen int64
+}
+
+func (q *redisDeadLetterQueue) Push(entry *models.DeadLetter) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter: %w", err)
+	}
+
+	_, err = q.client.XAdd(q.stream, q.maxLen, map[string]interface{}{
+		"sink":  entry.Sink,
+		"kind":  entry.Kind,
+		"entry": string(data),
+	})
+	return err
+}
+
+func (q *redisDeadLetterQueue) Drain(limit int, handle func(entry *models.DeadLetter)) (int, error) {
+	messages, err := q.client.XRange(q.stream, int64(limit))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read dead letters: %w", err)
+	}
+
+	for i, message := range messages {
+		var entry models.DeadLetter
+		raw, _ := message.Values["entry"].(string)
+		if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+			// 无法解析的记录无法重放，直接丢弃避免阻塞队列
+			logger.Errorf("Dropping undecodable dead letter %s: %v", message.ID, err)
+		} else {
+			entry.ID = message.ID
+			handle(&entry)
+		}
+
+		if err := q.client.XDel(q.stream, message.ID); err != nil {
+			return i + 1, fmt.Errorf("failed to remove dead letter %s: %w", message.ID, err)
+		}
+	}
+
+	return len(messages), nil
+}
+
+// kafkaDeadLetterQueue 基于Kafka主题的死信队列，重放进度由消费组提交
+type kafkaDeadLetterQueue struct {
+	publisher *publisher.KafkaPublisher
+	groupID   string
+}
+
+func (q *kafkaDeadLetterQueue) Push(entry *models.DeadLetter) error {
+	return q.publisher.PublishDeadLetter(entry)
+}
+
+func (q *kafkaDeadLetterQueue) Drain(limit int, handle func(entry *models.DeadLetter)) (int, error) {
+	reader := q.publisher.NewDeadLetterReader(q.groupID)
+	defer reader.Close()
+
+	// 重放失败的数据会重新写入主题，只处理本次重放开始前的死信
+	startedAt := time.Now()
+	count := 0
+
+	for count < limit {
+		ctx, cancel := context.WithTimeout(context.Background(), deadLetterFetchTimeout)
+		message, err := reader.FetchMessage(ctx)
+		cancel()
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				break
+			}
+			return count, fmt.Errorf("failed to read dead letters: %w", err)
+		}
+		if message.Time.After(startedAt) {
+			break
+		}
+
+		var entry models.DeadLetter
+		if err := json.Unmarshal(message.Value, &entry); err != nil {
+			logger.Errorf("Dropping undecodable dead letter at offset %d: %v", message.Offset, err)
+		} else {
+			entry.ID = fmt.Sprintf("%d-%d", message.Partition, message.Offset)
+			handle(&entry)
+		}
+		count++
+
+		if err := reader.CommitMessages(context.Background(), message); err != nil {
+			return count, fmt.Errorf("failed to commit dead letter %s: %w", entry.ID, err)
+		}
+	}
+
+	return count, nil
+}
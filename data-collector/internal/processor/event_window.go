@@ -0,0 +1,128 @@
+package processor
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+)
+
+// EventKey 事件唯一标识（交易哈希+日志索引）
+type EventKey struct {
+	Network         string
+	TransactionHash string
+	LogIndex        uint
+}
+
+func (k EventKey) String() string {
+	return fmt.Sprintf("%s:%s:%d", k.Network, k.TransactionHash, k.LogIndex)
+}
+
+// EventWindow 已发布事件的去重窗口
+type EventWindow interface {
+	// Add 记录已发布的事件，事件已存在时返回false
+	Add(key EventKey, blockNumber uint64) (bool, error)
+	// Remove 移除事件记录，事件不存在时返回false
+	Remove(key EventKey) (bool, error)
+}
+
+// NewEventWindow 根据配置创建去重窗口
+func NewEventWindow(cfg config.EventDedupConfig, redisClient *database.RedisClient) (EventWindow, error) {
+	switch cfg.Backend {
+	case "", "memory":
+		windowBlocks := cfg.WindowBlocks
+		if windowBlocks == 0 {
+			windowBlocks = 128
+		}
+		return newMemoryEventWindow(windowBlocks), nil
+	case "redis":
+		ttl := time.Hour
+		if cfg.TTL != "" {
+			parsed, err := time.ParseDuration(cfg.TTL)
+			if err != nil {
+				return nil, fmt.Errorf("invalid event_dedup ttl: %w", err)
+			}
+			ttl = parsed
+		}
+		return &redisEventWindow{client: redisClient, ttl: ttl}, nil
+	default:
+		return nil, fmt.Errorf("unknown event_dedup backend: %s", cfg.Backend)
+	}
+}
+
+// memoryEventWindow 基于内存、按区块数保留的去重窗口
+type memoryEventWindow struct {
+	windowBlocks uint64
+	events       map[EventKey]uint64
+	highest      map[string]uint64
+	mu           sync.Mutex
+}
+
+func newMemoryEventWindow(windowBlocks uint64) *memoryEventWindow {
+	return &memoryEventWindow{
+		windowBlocks: windowBlocks,
+		events:       make(map[EventKey]uint64),
+		highest:      make(map[string]uint64),
+	}
+}
+
+func (w *memoryEventWindow) Add(key EventKey, blockNumber uint64) (bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, exists := w.events[key]; exists {
+		return false, nil
+	}
+	w.events[key] = blockNumber
+
+	// 区块高度前进时清理窗口外的记录
+	if blockNumber > w.highest[key.Network] {
+		w.highest[key.Network] = blockNumber
+		if blockNumber > w.windowBlocks {
+			w.prune(key.Network, blockNumber-w.windowBlocks)
+		}
+	}
+
+	return true, nil
+}
+
+func (w *memoryEventWindow) Remove(key EventKey) (bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, exists := w.events[key]; !exists {
+		return false, nil
+	}
+	delete(w.events, key)
+
+	return true, nil
+}
+
+// prune 清理指定网络中早于给定区块的记录
+func (w *memoryEventWindow) prune(network string, beforeBlock uint64) {
+	for key, blockNumber := range w.events {
+		if key.Network == network && blockNumber < beforeBlock {
+			delete(w.events, key)
+		}
+	}
+}
+
+// redisEventWindow 基于Redis、按过期时间保留的去重窗口，可在多实例间共享
+type redisEventWindow struct {
+	client *database.RedisClient
+	ttl    time.Duration
+}
+
+func (w *redisEventWindow) Add(key EventKey, blockNumber uint64) (bool, error) {
+	return w.client.SetNX(w.redisKey(key), blockNumber, w.ttl)
+}
+
+func (w *redisEventWindow) Remove(key EventKey) (bool, error) {
+	return w.client.DeleteIfExists(w.redisKey(key))
+}
+
+func (w *redisEventWindow) redisKey(key EventKey) string {
+	return fmt.Sprintf("published_event:%s", key)
+}
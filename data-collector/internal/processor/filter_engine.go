@@ -3,26 +3,65 @@ package processor
 import (
 	"math/big"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/filterexpr"
+	"web3-data-collector/internal/metrics"
 	"web3-data-collector/internal/models"
+
+	"github.com/expr-lang/expr/vm"
+)
+
+// 过滤表达式规则的动作
+const (
+	FilterActionInclude = "include"
+	FilterActionExclude = "exclude"
 )
 
+// filterRule 编译后的过滤表达式规则
+type filterRule struct {
+	name    string
+	action  string
+	program *vm.Program
+	hits    uint64
+}
+
 // FilterEngine 过滤引擎
 type FilterEngine struct {
 	config           config.FilterRulesConfig
 	minValueWei      *big.Int
 	excludeContracts map[string]bool
 	includeAddresses map[string]bool
+	rules            []*filterRule // 配置后替代固定规则
+	defaultAction    string
+	watchlists       *Watchlists
+	dedup            *TransactionDedup // 未启用重复交易抑制时为nil
+	metricsManager   *metrics.Manager
+	mu               sync.RWMutex
 }
 
-// NewFilterEngine 创建新的过滤引擎
-func NewFilterEngine(config config.FilterRulesConfig) *FilterEngine {
-	fe := &FilterEngine{
-		config:           config,
-		excludeContracts: make(map[string]bool),
-		includeAddresses: make(map[string]bool),
-	}
+// NewFilterEngine 创建新的过滤引擎，watchlists为nil时表达式中的watchlist为空，dedup为nil时不检查重复交易
+func NewFilterEngine(config config.FilterRulesConfig, watchlists *Watchlists, dedup *TransactionDedup, metricsManager *metrics.Manager) *FilterEngine {
+	fe := &FilterEngine{watchlists: watchlists, dedup: dedup, metricsManager: metricsManager}
+	fe.load(config)
+	return fe
+}
+
+// Update 应用新的过滤规则（配置热加载）
+func (fe *FilterEngine) Update(config config.FilterRulesConfig) {
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
+	fe.load(config)
+}
+
+// load 根据配置重建过滤规则
+func (fe *FilterEngine) load(config config.FilterRulesConfig) {
+	fe.config = config
+	fe.minValueWei = nil
+	fe.excludeContracts = make(map[string]bool)
+	fe.includeAddresses = make(map[string]bool)
 
 	// 解析最小价值阈值
 	if config.MinValueWei != "" {
@@ -42,20 +81,51 @@ func NewFilterEngine(config config.FilterRulesConfig) *FilterEngine {
 		fe.includeAddresses[strings.ToLower(address)] = true
 	}
 
-	return fe
+	// 编译表达式规则，配置校验已检查过语法，此处失败的规则跳过
+	fe.rules = nil
+	for _, rule := range config.Rules {
+		program, err := filterexpr.Compile(rule.Expr)
+		if err != nil {
+			logger.Errorf("Skipping invalid filter rule %s: %v", rule.Name, err)
+			continue
+		}
+		fe.rules = append(fe.rules, &filterRule{name: rule.Name, action: rule.Action, program: program})
+	}
+	fe.defaultAction = config.DefaultAction
+	if fe.defaultAction == "" {
+		fe.defaultAction = FilterActionExclude
+	}
 }
 
 // ShouldProcess 判断是否应该处理交易
 func (fe *FilterEngine) ShouldProcess(tx *models.Transaction) *models.FilterResult {
+	fe.mu.RLock()
+	defer fe.mu.RUnlock()
+
 	result := &models.FilterResult{
 		ShouldProcess:   true,
 		FilteredReasons: []string{},
 		RiskScore:       0.0,
 	}
 
+	// 重复交易不论其他规则一律跳过
+	if fe.isDuplicateTransaction(tx) {
+		result.ShouldProcess = false
+		result.Duplicate = true
+		result.FilteredReasons = append(result.FilteredReasons, "duplicate_transaction")
+		return result
+	}
+
 	// 如果地址在包含列表中，优先处理
 	if fe.isIncludedAddress(tx) {
 		result.RiskScore += 0.1
+		result.Matched = true
+		return result
+	}
+
+	// 配置了表达式规则时替代下面的固定规则
+	if len(fe.rules) > 0 {
+		fe.applyRules(tx, result)
 		return result
 	}
 
@@ -77,8 +147,8 @@ func (fe *FilterEngine) ShouldProcess(tx *models.Transaction) *models.FilterResu
 		result.FilteredReasons = append(result.FilteredReasons, "zero_value_non_contract")
 	}
 
-	// 检查失败的交易
-	if tx.Status == 0 {
+	// 检查失败的交易，未获取回执的交易状态未知
+	if tx.HasReceipt && tx.Status == 0 {
 		result.ShouldProcess = false
 		result.FilteredReasons = append(result.FilteredReasons, "failed_transaction")
 	}
@@ -95,13 +165,46 @@ func (fe *FilterEngine) ShouldProcess(tx *models.Transaction) *models.FilterResu
 		result.FilteredReasons = append(result.FilteredReasons, "spam_transaction")
 	}
 
-	// 检查重复交易
-	if fe.isDuplicateTransaction(tx) {
-		result.ShouldProcess = false
-		result.FilteredReasons = append(result.FilteredReasons, "duplicate_transaction")
+	return result
+}
+
+// applyRules 按顺序对表达式规则求值，第一条命中的规则决定是否处理，求值出错的规则视为未命中
+func (fe *FilterEngine) applyRules(tx *models.Transaction, result *models.FilterResult) {
+	var watchlist map[string]bool
+	if fe.watchlists != nil {
+		set, err := fe.watchlists.AddressSet(tx.Network)
+		if err != nil {
+			logger.Warnf("Failed to load watchlist for filter rules: %v", err)
+		}
+		watchlist = set
 	}
+	env := filterexpr.NewEnv(tx, watchlist)
 
-	return result
+	for _, rule := range fe.rules {
+		matched, err := filterexpr.Match(rule.program, env)
+		if err != nil {
+			logger.Debugf("Filter rule %s failed on transaction %s: %v", rule.name, tx.Hash, err)
+			continue
+		}
+		if !matched {
+			continue
+		}
+
+		atomic.AddUint64(&rule.hits, 1)
+		fe.metricsManager.RecordFilterRuleHit(rule.name, rule.action)
+		if rule.action == FilterActionExclude {
+			result.ShouldProcess = false
+			result.FilteredReasons = append(result.FilteredReasons, "rule:"+rule.name)
+		} else {
+			result.Matched = true
+		}
+		return
+	}
+
+	if fe.defaultAction == FilterActionExclude {
+		result.ShouldProcess = false
+		result.FilteredReasons = append(result.FilteredReasons, "no_rule_matched")
+	}
 }
 
 // isIncludedAddress 检查是否为包含地址
@@ -120,6 +223,11 @@ func (fe *FilterEngine) isExcludedContract(tx *models.Transaction) bool {
 
 // isSpamTransaction 检查是否为垃圾交易
 func (fe *FilterEngine) isSpamTransaction(tx *models.Transaction) bool {
+	// Gas规则只适用于EVM链
+	if tx.Chain == models.ChainSolana {
+		return false
+	}
+
 	// 检查非常低的Gas价格（小于1 Gwei）
 	oneGwei := big.NewInt(1000000000) // 1 Gwei = 10^9 wei
 	if tx.GasPrice.Cmp(oneGwei) < 0 {
@@ -134,47 +242,86 @@ func (fe *FilterEngine) isSpamTransaction(tx *models.Transaction) bool {
 	return false
 }
 
-// isDuplicateTransaction 检查是否为重复交易
+// isDuplicateTransaction 记录交易并检查最近是否已处理过，Redis不可用时视为不重复
 func (fe *FilterEngine) isDuplicateTransaction(tx *models.Transaction) bool {
-	// 这里可以实现基于Redis的重复检测逻辑
-	// 简单实现：检查相同nonce的交易
-	// 在实际应用中，应该查询Redis或数据库
-	return false
+	if fe.dedup == nil {
+		return false
+	}
+	added, err := fe.dedup.Mark(tx)
+	if err != nil {
+		logger.Warnf("Failed to check duplicate transaction %s: %v", tx.Hash, err)
+		return false
+	}
+	return !added
+}
+
+// ForgetTransaction 移除交易的去重记录，交易处理失败时调用以允许重试
+func (fe *FilterEngine) ForgetTransaction(tx *models.Transaction) {
+	if fe.dedup == nil {
+		return
+	}
+	if err := fe.dedup.Forget(tx); err != nil {
+		logger.Errorf("Failed to remove transaction %s from dedup records: %v", tx.Hash, err)
+	}
 }
 
 // AddExcludeContract 添加排除合约
 func (fe *FilterEngine) AddExcludeContract(contractAddress string) {
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
 	fe.excludeContracts[strings.ToLower(contractAddress)] = true
 }
 
 // RemoveExcludeContract 移除排除合约
 func (fe *FilterEngine) RemoveExcludeContract(contractAddress string) {
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
 	delete(fe.excludeContracts, strings.ToLower(contractAddress))
 }
 
 // AddIncludeAddress 添加包含地址
 func (fe *FilterEngine) AddIncludeAddress(address string) {
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
 	fe.includeAddresses[strings.ToLower(address)] = true
 }
 
 // RemoveIncludeAddress 移除包含地址
 func (fe *FilterEngine) RemoveIncludeAddress(address string) {
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
 	delete(fe.includeAddresses, strings.ToLower(address))
 }
 
 // SetMinValueThreshold 设置最小价值阈值
 func (fe *FilterEngine) SetMinValueThreshold(threshold *big.Int) {
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
 	fe.minValueWei = threshold
 }
 
 // GetFilterStats 获取过滤统计信息
 func (fe *FilterEngine) GetFilterStats() map[string]interface{} {
+	fe.mu.RLock()
+	defer fe.mu.RUnlock()
+
+	rules := make([]map[string]interface{}, 0, len(fe.rules))
+	for _, rule := range fe.rules {
+		rules = append(rules, map[string]interface{}{
+			"name":   rule.name,
+			"action": rule.action,
+			"hits":   atomic.LoadUint64(&rule.hits),
+		})
+	}
+
 	return map[string]interface{}{
 		"min_value_wei":        fe.minValueWei.String(),
 		"exclude_contracts":    len(fe.excludeContracts),
 		"include_addresses":    len(fe.includeAddresses),
 		"exclude_contract_list": fe.getExcludeContractList(),
 		"include_address_list":  fe.getIncludeAddressList(),
+		"rules":                 rules,
+		"default_action":        fe.defaultAction,
 	}
 }
 
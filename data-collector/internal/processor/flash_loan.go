@@ -0,0 +1,81 @@
+package processor
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"web3-data-collector/internal/models"
+)
+
+// ProcessFlashLoans 为同一交易中的闪电贷生成并发布FLASH_LOAN告警
+func (dp *DataProcessor) ProcessFlashLoans(loans []*models.FlashLoan) (*models.RiskAlert, error) {
+	if len(loans) == 0 {
+		return nil, nil
+	}
+
+	alert := dp.createFlashLoanAlert(loans)
+	if err := dp.PublishOpsAlert(alert); err != nil {
+		return nil, err
+	}
+
+	return alert, nil
+}
+
+// createFlashLoanAlert 创建闪电贷告警，涉及多个协议时提高风险分
+func (dp *DataProcessor) createFlashLoanAlert(loans []*models.FlashLoan) *models.RiskAlert {
+	first := loans[0]
+
+	protocolSet := make(map[string]bool)
+	borrowed := make([]map[string]interface{}, 0, len(loans))
+	for _, loan := range loans {
+		protocolSet[loan.Protocol] = true
+
+		entry := map[string]interface{}{
+			"protocol": loan.Protocol,
+			"lender":   loan.Lender,
+			"receiver": loan.Receiver,
+			"asset":    loan.Asset,
+			"amount":   loan.Amount.String(),
+			"source":   loan.Source,
+		}
+		if loan.Premium != nil {
+			entry["premium"] = loan.Premium.String()
+		}
+		borrowed = append(borrowed, entry)
+	}
+
+	protocols := make([]string, 0, len(protocolSet))
+	for protocol := range protocolSet {
+		protocols = append(protocols, protocol)
+	}
+	sort.Strings(protocols)
+
+	riskScore := 0.6
+	level := "HIGH"
+	if len(protocols) > 1 {
+		// 多个来源叠加借款常见于攻击
+		riskScore = 0.8
+		level = "CRITICAL"
+	}
+
+	return &models.RiskAlert{
+		ID:              models.AlertID("FLASH_LOAN", first.TransactionID),
+		Type:            "FLASH_LOAN",
+		Level:           level,
+		Title:           "闪电贷交易",
+		Description:     fmt.Sprintf("检测到交易通过 %v 借入 %d 笔闪电贷", protocols, len(loans)),
+		TransactionHash: first.TransactionHash,
+		Address:         first.Receiver,
+		Network:         first.Network,
+		RiskScore:       riskScore,
+		RiskFactors:     []string{"flash_loan"},
+		Metadata: map[string]interface{}{
+			"block_number": first.BlockNumber,
+			"protocols":    protocols,
+			"loans":        borrowed,
+		},
+		Timestamp: time.Now(),
+		Status:    "ACTIVE",
+	}
+}
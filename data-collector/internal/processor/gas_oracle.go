@@ -0,0 +1,236 @@
+package processor
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/models"
+)
+
+// EIP-1559默认参数，L2等自定义参数的网络推算的下一区块基础费用仅供参考
+const (
+	baseFeeElasticity        = 2
+	baseFeeChangeDenominator = 8
+	defaultGasOracleHistory  = 20
+	maxFeeBaseFeeMultiplier  = 2 // 建议的max_fee为2倍基础费用加优先费，可承受连续6个满块
+)
+
+// GasOracle 统计每个区块的gas费用，并按各网络最近区块估算建议费用
+type GasOracle struct {
+	history int
+	recent  map[string][]*models.GasStats // 按区块号升序
+	mu      sync.RWMutex
+}
+
+// NewGasOracle 根据配置创建gas费用统计，未启用时返回nil
+func NewGasOracle(cfg config.GasOracleConfig) *GasOracle {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	history := cfg.HistoryBlocks
+	if history <= 0 {
+		history = defaultGasOracleHistory
+	}
+	return &GasOracle{
+		history: history,
+		recent:  make(map[string][]*models.GasStats),
+	}
+}
+
+// Compute 计算区块的gas统计，有回执时按实际gas单价计算优先费
+func (g *GasOracle) Compute(block *models.Block) *models.GasStats {
+	stats := &models.GasStats{
+		ID:          models.GasStatsID(block.Network, block.Hash),
+		Network:     block.Network,
+		BlockNumber: block.Number,
+		BlockHash:   block.Hash,
+		Timestamp:   block.Timestamp,
+		GasUsed:     block.GasUsed,
+		GasLimit:    block.GasLimit,
+		TxCount:     len(block.Transactions),
+	}
+	if block.GasLimit > 0 {
+		stats.Utilization = float64(block.GasUsed) / float64(block.GasLimit)
+	}
+
+	baseFee := block.BaseFeePerGas
+	if baseFee != nil {
+		stats.BaseFeePerGas = new(big.Int).Set(baseFee)
+		stats.NextBaseFeePerGas = nextBaseFee(baseFee, block.GasUsed, block.GasLimit)
+	}
+
+	fees := make([]*big.Int, 0, len(block.Transactions))
+	for i := range block.Transactions {
+		tx := &block.Transactions[i]
+		price := effectiveGasPrice(tx, baseFee)
+		if price == nil {
+			continue
+		}
+		// 伦敦升级前没有基础费用，gas单价即为优先费
+		fee := new(big.Int).Set(price)
+		if baseFee != nil {
+			fee.Sub(fee, baseFee)
+		}
+		if fee.Sign() < 0 {
+			fee.SetInt64(0)
+		}
+		fees = append(fees, fee)
+	}
+
+	if len(fees) > 0 {
+		sort.Slice(fees, func(i, j int) bool {
+			return fees[i].Cmp(fees[j]) < 0
+		})
+		stats.PriorityFees = &models.FeePercentiles{
+			P10: percentile(fees, 10),
+			P25: percentile(fees, 25),
+			P50: percentile(fees, 50),
+			P75: percentile(fees, 75),
+			P90: percentile(fees, 90),
+		}
+	}
+
+	return stats
+}
+
+// Record 记录区块统计，重新处理的区块（含重组）替换该区块号及之后的记录
+func (g *GasOracle) Record(stats *models.GasStats) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	recent := g.recent[stats.Network]
+	keep := len(recent)
+	for keep > 0 && recent[keep-1].BlockNumber >= stats.BlockNumber {
+		keep--
+	}
+	recent = append(recent[:keep], stats)
+	if len(recent) > g.history {
+		recent = recent[len(recent)-g.history:]
+	}
+	g.recent[stats.Network] = recent
+}
+
+// Latest 获取网络最新区块的统计
+func (g *GasOracle) Latest(network string) *models.GasStats {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	recent := g.recent[network]
+	if len(recent) == 0 {
+		return nil
+	}
+	return recent[len(recent)-1]
+}
+
+// Estimate 按最近区块优先费25/50/90分位数的平均值给出慢/标准/快三档建议，没有数据时返回nil
+func (g *GasOracle) Estimate(network string) *models.GasEstimate {
+	g.mu.RLock()
+	recent := append([]*models.GasStats(nil), g.recent[network]...)
+	g.mu.RUnlock()
+
+	if len(recent) == 0 {
+		return nil
+	}
+
+	latest := recent[len(recent)-1]
+	estimate := &models.GasEstimate{
+		Network:           network,
+		BlockNumber:       latest.BlockNumber,
+		Blocks:            len(recent),
+		BaseFeePerGas:     latest.BaseFeePerGas,
+		NextBaseFeePerGas: latest.NextBaseFeePerGas,
+	}
+
+	slow, standard, fast := new(big.Int), new(big.Int), new(big.Int)
+	sampled := int64(0)
+	for _, stats := range recent {
+		estimate.Utilization += stats.Utilization
+		if stats.PriorityFees == nil {
+			continue
+		}
+		slow.Add(slow, stats.PriorityFees.P25)
+		standard.Add(standard, stats.PriorityFees.P50)
+		fast.Add(fast, stats.PriorityFees.P90)
+		sampled++
+	}
+	estimate.Utilization /= float64(len(recent))
+
+	if sampled > 0 {
+		divisor := big.NewInt(sampled)
+		slow.Div(slow, divisor)
+		standard.Div(standard, divisor)
+		fast.Div(fast, divisor)
+	}
+
+	estimate.Slow = feeSuggestion(slow, latest)
+	estimate.Standard = feeSuggestion(standard, latest)
+	estimate.Fast = feeSuggestion(fast, latest)
+	return estimate
+}
+
+// feeSuggestion 按优先费生成建议参数，未启用EIP-1559的网络以优先费作为gas单价
+func feeSuggestion(priorityFee *big.Int, latest *models.GasStats) *models.FeeSuggestion {
+	suggestion := &models.FeeSuggestion{MaxPriorityFeePerGas: priorityFee}
+	if latest.NextBaseFeePerGas == nil {
+		suggestion.GasPrice = priorityFee
+		return suggestion
+	}
+
+	maxFee := new(big.Int).Mul(latest.NextBaseFeePerGas, big.NewInt(maxFeeBaseFeeMultiplier))
+	suggestion.MaxFeePerGas = maxFee.Add(maxFee, priorityFee)
+	return suggestion
+}
+
+// nextBaseFee 按EIP-1559推算下一区块的基础费用
+func nextBaseFee(baseFee *big.Int, gasUsed, gasLimit uint64) *big.Int {
+	target := gasLimit / baseFeeElasticity
+	if target == 0 || gasUsed == target {
+		return new(big.Int).Set(baseFee)
+	}
+
+	var delta uint64
+	if gasUsed > target {
+		delta = gasUsed - target
+	} else {
+		delta = target - gasUsed
+	}
+	change := new(big.Int).Mul(baseFee, new(big.Int).SetUint64(delta))
+	change.Div(change, new(big.Int).SetUint64(target))
+	change.Div(change, big.NewInt(baseFeeChangeDenominator))
+
+	if gasUsed > target {
+		if change.Sign() == 0 {
+			change.SetInt64(1)
+		}
+		return change.Add(baseFee, change)
+	}
+	next := change.Sub(baseFee, change)
+	if next.Sign() < 0 {
+		next.SetInt64(0)
+	}
+	return next
+}
+
+// percentile 按最近秩法取已排序数值的分位数
+func percentile(sorted []*big.Int, p int) *big.Int {
+	index := (len(sorted)*p + 99) / 100
+	if index > 0 {
+		index--
+	}
+	return new(big.Int).Set(sorted[index])
+}
+
+// RecordGasStats 计算并发布区块的gas统计
+func (dp *DataProcessor) RecordGasStats(block *models.Block) (*models.GasStats, error) {
+	stats := dp.gasOracle.Compute(block)
+	dp.gasOracle.Record(stats)
+	return stats, dp.sinks.PublishGasStats(stats)
+}
+
+// GasOracle 获取gas费用统计，未启用时为nil
+func (dp *DataProcessor) GasOracle() *GasOracle {
+	return dp.gasOracle
+}
@@ -0,0 +1,219 @@
+package processor
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+
+	"web3-data-collector/internal/models"
+)
+
+// Uniswap V2兼容路由的兑换函数选择器
+const (
+	selectorSwapExactETHForTokens                 = "7ff36ab5"
+	selectorSwapExactETHForTokensFeeOnTransfer    = "b6f9de95"
+	selectorSwapETHForExactTokens                 = "fb3bdb41"
+	selectorSwapExactTokensForETH                 = "18cbafe5"
+	selectorSwapExactTokensForETHFeeOnTransfer    = "791ac947"
+	selectorSwapTokensForExactETH                 = "4a25d94a"
+	selectorSwapExactTokensForTokens              = "38ed1739"
+	selectorSwapExactTokensForTokensFeeOnTransfer = "5c11d795"
+	selectorSwapTokensForExactTokens              = "8803dbee"
+)
+
+// swapCall 从路由调用数据解码出的兑换
+type swapCall struct {
+	tx        *models.Transaction
+	tokenIn   string
+	tokenOut  string
+	amountIn  *big.Int // 输入数量（exact out调用时为最大输入）
+	amountOut *big.Int // 输出数量（exact in调用时为最小输出）
+	nativeIn  bool
+	nativeOut bool
+}
+
+// SandwichAttack 区块内的三明治攻击：攻击者在受害者兑换前后以相同交易对先买后卖
+type SandwichAttack struct {
+	Network  string
+	Block    uint64
+	Attacker string
+	Victim   string
+	FrontRun *models.Transaction
+	VictimTx *models.Transaction
+	BackRun  *models.Transaction
+	TokenIn  string // 受害者卖出的代币，也是攻击者获利的计价代币
+	TokenOut string // 受害者买入的代币
+	// 按调用数据中的最小输出/最大输入估算的获利（TokenIn单位），为下限估计
+	EstimatedValue *big.Int
+	NativeValue    bool // TokenIn为网络原生币（包装币）
+}
+
+// DetectSandwiches 分析区块内的兑换交易，识别三明治攻击
+func (rd *RiskDetector) DetectSandwiches(block *models.Block) []*SandwichAttack {
+	var swaps []*swapCall
+	for i := range block.Transactions {
+		if swap, ok := decodeSwap(&block.Transactions[i]); ok {
+			swaps = append(swaps, swap)
+		}
+	}
+	if len(swaps) < 3 {
+		return nil
+	}
+
+	var attacks []*SandwichAttack
+	used := make(map[string]bool)
+
+	// swaps按交易在区块中的顺序排列
+	for v := 1; v < len(swaps)-1; v++ {
+		victim := swaps[v]
+		if used[victim.tx.Hash] {
+			continue
+		}
+
+		attack := findSandwich(swaps, v, used)
+		if attack == nil {
+			continue
+		}
+		attack.Network = block.Network
+		attack.Block = block.Number
+
+		used[attack.FrontRun.Hash] = true
+		used[attack.VictimTx.Hash] = true
+		used[attack.BackRun.Hash] = true
+		attacks = append(attacks, attack)
+	}
+
+	return attacks
+}
+
+// findSandwich 查找包夹第v笔兑换的前后交易：前置交易与受害者同向，后置交易由同一地址反向卖出
+func findSandwich(swaps []*swapCall, v int, used map[string]bool) *SandwichAttack {
+	victim := swaps[v]
+
+	for f := v - 1; f >= 0; f-- {
+		front := swaps[f]
+		if used[front.tx.Hash] || strings.EqualFold(front.tx.FromAddress, victim.tx.FromAddress) {
+			continue
+		}
+		if front.tokenIn != victim.tokenIn || front.tokenOut != victim.tokenOut {
+			continue
+		}
+
+		for b := v + 1; b < len(swaps); b++ {
+			back := swaps[b]
+			if used[back.tx.Hash] || !strings.EqualFold(back.tx.FromAddress, front.tx.FromAddress) {
+				continue
+			}
+			if back.tokenIn != victim.tokenOut || back.tokenOut != victim.tokenIn {
+				continue
+			}
+
+			estimated := new(big.Int).Sub(back.amountOut, front.amountIn)
+			if estimated.Sign() < 0 {
+				estimated.SetInt64(0)
+			}
+
+			return &SandwichAttack{
+				Attacker:       front.tx.FromAddress,
+				Victim:         victim.tx.FromAddress,
+				FrontRun:       front.tx,
+				VictimTx:       victim.tx,
+				BackRun:        back.tx,
+				TokenIn:        victim.tokenIn,
+				TokenOut:       victim.tokenOut,
+				EstimatedValue: estimated,
+				NativeValue:    front.nativeIn || back.nativeOut || victim.nativeIn,
+			}
+		}
+	}
+
+	return nil
+}
+
+// decodeSwap 解码Uniswap V2兼容路由的兑换调用
+func decodeSwap(tx *models.Transaction) (*swapCall, bool) {
+	if tx.Status == 0 || tx.Chain == models.ChainSolana {
+		return nil, false
+	}
+
+	data, err := hex.DecodeString(strings.TrimPrefix(tx.InputData, "0x"))
+	if err != nil || len(data) < 4 {
+		return nil, false
+	}
+	selector := hex.EncodeToString(data[:4])
+	args := data[4:]
+
+	swap := &swapCall{tx: tx}
+	var pathArg int
+
+	switch selector {
+	case selectorSwapExactETHForTokens, selectorSwapExactETHForTokensFeeOnTransfer:
+		// (amountOutMin, path, to, deadline)
+		swap.amountIn = tx.Value
+		swap.amountOut = abiWord(args, 0)
+		swap.nativeIn = true
+		pathArg = 1
+	case selectorSwapETHForExactTokens:
+		// (amountOut, path, to, deadline)
+		swap.amountIn = tx.Value
+		swap.amountOut = abiWord(args, 0)
+		swap.nativeIn = true
+		pathArg = 1
+	case selectorSwapExactTokensForETH, selectorSwapExactTokensForETHFeeOnTransfer,
+		selectorSwapExactTokensForTokens, selectorSwapExactTokensForTokensFeeOnTransfer:
+		// (amountIn, amountOutMin, path, to, deadline)
+		swap.amountIn = abiWord(args, 0)
+		swap.amountOut = abiWord(args, 1)
+		pathArg = 2
+	case selectorSwapTokensForExactETH, selectorSwapTokensForExactTokens:
+		// (amountOut, amountInMax, path, to, deadline)
+		swap.amountOut = abiWord(args, 0)
+		swap.amountIn = abiWord(args, 1)
+		pathArg = 2
+	default:
+		return nil, false
+	}
+
+	swap.nativeOut = selector == selectorSwapExactTokensForETH ||
+		selector == selectorSwapExactTokensForETHFeeOnTransfer ||
+		selector == selectorSwapTokensForExactETH
+
+	path := abiAddressArray(args, pathArg)
+	if len(path) < 2 || swap.amountIn == nil || swap.amountOut == nil {
+		return nil, false
+	}
+	swap.tokenIn = path[0]
+	swap.tokenOut = path[len(path)-1]
+
+	return swap, true
+}
+
+// abiWord 读取第index个32字节参数
+func abiWord(args []byte, index int) *big.Int {
+	start := index * 32
+	if start+32 > len(args) {
+		return nil
+	}
+	return new(big.Int).SetBytes(args[start : start+32])
+}
+
+// abiAddressArray 读取第index个参数指向的address[]
+func abiAddressArray(args []byte, index int) []string {
+	offset := abiWord(args, index)
+	if offset == nil || !offset.IsInt64() || offset.Int64()+32 > int64(len(args)) {
+		return nil
+	}
+
+	start := int(offset.Int64())
+	length := new(big.Int).SetBytes(args[start : start+32])
+	if !length.IsInt64() || length.Int64() > int64(len(args)/32) || start+32+int(length.Int64())*32 > len(args) {
+		return nil
+	}
+
+	addresses := make([]string, 0, length.Int64())
+	for i := 0; i < int(length.Int64()); i++ {
+		word := args[start+32+i*32 : start+64+i*32]
+		addresses = append(addresses, "0x"+hex.EncodeToString(word[12:]))
+	}
+	return addresses
+}
@@ -0,0 +1,176 @@
+package processor
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/models"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// mixerExposureKeyPrefix 地址混币暴露分在Redis中的键前缀，按 网络:地址 存储
+const mixerExposureKeyPrefix = "mixer_exposure"
+
+// 混币器交互类型
+const (
+	MixerDeposit    = "deposit"
+	MixerWithdrawal = "withdrawal"
+)
+
+// Tornado Cash 资金池及路由合约的函数选择器
+var (
+	mixerDepositSelectors = map[string]bool{
+		"b214faa5": true, // deposit(bytes32)
+		"13d98d13": true, // deposit(address,bytes32,bytes)
+	}
+	// 取款函数选择器 -> 接收地址所在的参数位置
+	mixerWithdrawSelectors = map[string]int{
+		"21a0adb6": 3, // withdraw(bytes,bytes32,bytes32,address,address,uint256,uint256)
+		"b438689f": 4, // withdraw(address,bytes,bytes32,bytes32,address,address,uint256,uint256)
+	}
+)
+
+// MixerInteraction 一次混币器存款或取款
+type MixerInteraction struct {
+	Network   string `json:"network"`
+	Mixer     string `json:"mixer"`
+	MixerName string `json:"mixer_name"`
+	Kind      string `json:"kind"`    // deposit / withdrawal
+	Address   string `json:"address"` // 存款方或取款接收方
+	TxHash    string `json:"tx_hash"`
+}
+
+// MixerTracker 识别混币器存取款，并在Redis中累计相关地址的混币暴露分
+type MixerTracker struct {
+	client          *database.RedisClient
+	contracts       map[string]string // 小写地址 -> 名称
+	depositScore    float64
+	withdrawalScore float64
+	ttl             time.Duration
+}
+
+// NewMixerTracker 根据配置创建混币器跟踪器，未启用时返回nil
+func NewMixerTracker(cfg config.MixerConfig, redisClient *database.RedisClient) (*MixerTracker, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	ttl := 90 * 24 * time.Hour
+	if cfg.ExposureTTL != "" {
+		parsed, err := time.ParseDuration(cfg.ExposureTTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mixer exposure_ttl: %w", err)
+		}
+		ttl = parsed
+	}
+
+	contracts := make(map[string]string, len(cfg.Contracts))
+	for _, contract := range cfg.Contracts {
+		contracts[strings.ToLower(contract.Address)] = contract.Name
+	}
+
+	return &MixerTracker{
+		client:          redisClient,
+		contracts:       contracts,
+		depositScore:    cfg.DepositScore,
+		withdrawalScore: cfg.WithdrawalScore,
+		ttl:             ttl,
+	}, nil
+}
+
+// Detect 判断交易是否为混币器存款或取款，非混币器交易返回nil
+func (mt *MixerTracker) Detect(tx *models.Transaction) *MixerInteraction {
+	name, isMixer := mt.contracts[strings.ToLower(tx.ToAddress)]
+	if !isMixer {
+		return nil
+	}
+
+	interaction := &MixerInteraction{
+		Network:   tx.Network,
+		Mixer:     tx.ToAddress,
+		MixerName: name,
+		TxHash:    tx.Hash,
+	}
+
+	input := strings.TrimPrefix(tx.InputData, "0x")
+	if len(input) < 8 {
+		return nil
+	}
+	selector := strings.ToLower(input[:8])
+
+	if mixerDepositSelectors[selector] {
+		interaction.Kind = MixerDeposit
+		interaction.Address = tx.FromAddress
+		return interaction
+	}
+
+	// 取款通常由中继发起，暴露的是calldata中的接收地址
+	if position, isWithdraw := mixerWithdrawSelectors[selector]; isWithdraw {
+		args, err := hex.DecodeString(input[8:])
+		if err != nil || len(args) < (position+1)*32 {
+			return nil
+		}
+		interaction.Kind = MixerWithdrawal
+		interaction.Address = common.BytesToAddress(args[position*32 : (position+1)*32]).Hex()
+		return interaction
+	}
+
+	return nil
+}
+
+// Record 按交互类型累加地址的暴露分（上限为1），并刷新过期时间
+func (mt *MixerTracker) Record(interaction *MixerInteraction) error {
+	key := mixerExposureKey(interaction.Network, interaction.Address)
+
+	current, err := mt.client.HGetAll(key)
+	if err != nil {
+		return err
+	}
+	// 交易重试时不重复累加
+	if current["last_transaction"] == interaction.TxHash {
+		return nil
+	}
+
+	score, _ := strconv.ParseFloat(current["score"], 64)
+	count, _ := strconv.ParseInt(current[interaction.Kind], 10, 64)
+
+	delta := mt.depositScore
+	if interaction.Kind == MixerWithdrawal {
+		delta = mt.withdrawalScore
+	}
+
+	if err := mt.client.HMSet(key, map[string]interface{}{
+		"score":            math.Min(1, score+delta),
+		interaction.Kind:   count + 1,
+		"last_mixer":       interaction.Mixer,
+		"last_transaction": interaction.TxHash,
+		"updated_at":       time.Now().Unix(),
+	}); err != nil {
+		return err
+	}
+	return mt.client.Expire(key, mt.ttl)
+}
+
+// Exposure 获取地址的混币暴露分，无记录时为0
+func (mt *MixerTracker) Exposure(network, address string) (float64, error) {
+	if address == "" {
+		return 0, nil
+	}
+
+	exposure, err := mt.client.HGetAll(mixerExposureKey(network, address))
+	if err != nil || exposure["score"] == "" {
+		return 0, err
+	}
+	return strconv.ParseFloat(exposure["score"], 64)
+}
+
+func mixerExposureKey(network, address string) string {
+	return fmt.Sprintf("%s:%s:%s", mixerExposureKeyPrefix, network, strings.ToLower(address))
+}
@@ -0,0 +1,151 @@
+package processor
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// 区块处理流水线阶段，按执行顺序排列
+const (
+	PipelineStageFetch         = "fetch"          // 获取区块
+	PipelineStageReceipts      = "receipts"       // 获取交易回执（供应量统计）
+	PipelineStageProcess       = "process"        // 交易处理与风险检测
+	PipelineStageLogFilter     = "log_filter"     // 关注合约日志
+	PipelineStageFlashLoans    = "flash_loans"    // 闪电贷检测
+	PipelineStageTokenLogs     = "token_logs"     // 授权盗取检测及代币流向
+	PipelineStageTokenRisk     = "token_risk"     // 代币跑路/貔貅检测
+	PipelineStageBridges       = "bridges"        // 跨链桥存入/到账解码
+	PipelineStageBalanceDrains = "balance_drains" // 余额清空检测
+	PipelineStageWatchBalances = "watch_balances" // 关注地址余额跟踪
+	PipelineStagePublish       = "publish"        // 推送完整区块
+)
+
+// pipelineRateWindow 吞吐量按该窗口内的处理次数计算
+const pipelineRateWindow = time.Minute
+
+// StageStats 单个阶段的运行统计
+type StageStats struct {
+	Processed    uint64     `json:"processed"`
+	Errors       uint64     `json:"errors"`
+	Throughput   float64    `json:"throughput"` // 最近一个完整窗口的每秒处理次数
+	AvgLatencyMs float64    `json:"avg_latency_ms"`
+	LastRun      *time.Time `json:"last_run,omitempty"`
+}
+
+// stageCounter 阶段计数及吞吐量窗口
+type stageCounter struct {
+	processed     uint64
+	errors        uint64
+	totalDuration time.Duration
+	lastRun       time.Time
+	windowStart   time.Time
+	windowCount   uint64
+	rate          float64
+}
+
+// PipelineStats 按网络记录各流水线阶段的处理次数、错误及耗时
+type PipelineStats struct {
+	stages map[string]map[string]*stageCounter
+	mu     sync.Mutex
+}
+
+// NewPipelineStats 创建流水线统计
+func NewPipelineStats() *PipelineStats {
+	return &PipelineStats{stages: make(map[string]map[string]*stageCounter)}
+}
+
+// Record 记录一次阶段执行
+func (ps *PipelineStats) Record(network, stage string, duration time.Duration, err error) {
+	now := time.Now()
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if ps.stages[network] == nil {
+		ps.stages[network] = make(map[string]*stageCounter)
+	}
+	counter, exists := ps.stages[network][stage]
+	if !exists {
+		counter = &stageCounter{windowStart: now}
+		ps.stages[network][stage] = counter
+	}
+
+	counter.roll(now)
+	counter.processed++
+	counter.windowCount++
+	counter.totalDuration += duration
+	counter.lastRun = now
+	if err != nil {
+		counter.errors++
+	}
+}
+
+// Get 获取阶段统计，未执行过的阶段返回零值
+func (ps *PipelineStats) Get(network, stage string) StageStats {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	counter, exists := ps.stages[network][stage]
+	if !exists {
+		return StageStats{}
+	}
+	counter.roll(time.Now())
+
+	lastRun := counter.lastRun
+	return StageStats{
+		Processed:    counter.processed,
+		Errors:       counter.errors,
+		Throughput:   counter.rate,
+		AvgLatencyMs: float64(counter.totalDuration) / float64(time.Millisecond) / float64(counter.processed),
+		LastRun:      &lastRun,
+	}
+}
+
+// roll 窗口结束时计算吞吐量并开始新窗口，空闲超过一个窗口时吞吐量为0
+func (sc *stageCounter) roll(now time.Time) {
+	elapsed := now.Sub(sc.windowStart)
+	if elapsed < pipelineRateWindow {
+		return
+	}
+	if elapsed < 2*pipelineRateWindow {
+		sc.rate = float64(sc.windowCount) / elapsed.Seconds()
+	} else {
+		sc.rate = 0
+	}
+	sc.windowStart = now
+	sc.windowCount = 0
+}
+
+// PipelineStats 获取流水线统计
+func (dp *DataProcessor) PipelineStats() *PipelineStats {
+	return dp.pipeline
+}
+
+// SinkNames 获取已启用的输出端名称
+func (dp *DataProcessor) SinkNames() []string {
+	return dp.sinks.SinkNames()
+}
+
+// CheckSinks 检查支持连通性检查的输出端，返回输出端名称到检查结果，nil表示可用
+func (dp *DataProcessor) CheckSinks(ctx context.Context) map[string]error {
+	results := make(map[string]error, len(dp.sinks.sinks))
+	for _, entry := range dp.sinks.sinks {
+		if checker, ok := entry.sink.(HealthChecker); ok {
+			results[entry.sink.Name()] = checker.Ping(ctx)
+		}
+	}
+	return results
+}
+
+// SinkQueueDepths 获取各输出端的待发送数量，不支持统计的输出端为-1
+func (dp *DataProcessor) SinkQueueDepths() map[string]int64 {
+	depths := make(map[string]int64, len(dp.sinks.sinks))
+	for _, entry := range dp.sinks.sinks {
+		depths[entry.sink.Name()] = -1
+		if reporter, ok := entry.sink.(QueueReporter); ok {
+			depths[entry.sink.Name()] = reporter.QueueDepth()
+		}
+	}
+	return depths
+}
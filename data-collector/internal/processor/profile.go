@@ -0,0 +1,37 @@
+package processor
+
+import (
+	"web3-data-collector/internal/config"
+)
+
+// 部署配置
+const (
+	ProfileFull = "full"
+	// ProfileAlertsOnly 仅运行过滤、风险检测及告警推送：不连接InfluxDB，不发布区块、交易、事件等数据，也不做ENS解析
+	ProfileAlertsOnly = "alerts_only"
+)
+
+// alertSinkConfigs 告警专用部署的输出端，移除InfluxDB，未配置时使用Kafka及实时订阅
+func alertSinkConfigs(sinkConfigs []config.SinkConfig) []config.SinkConfig {
+	if len(sinkConfigs) == 0 {
+		return []config.SinkConfig{
+			{Type: "kafka", Enabled: true, OnError: SinkErrorPolicyContinue},
+			{Type: "stream", Enabled: true, OnError: SinkErrorPolicyContinue},
+		}
+	}
+
+	filtered := make([]config.SinkConfig, 0, len(sinkConfigs))
+	for _, sinkCfg := range sinkConfigs {
+		if sinkCfg.Type == "influxdb" {
+			logger.Infof("Sink influxdb is skipped in %s profile", ProfileAlertsOnly)
+			continue
+		}
+		filtered = append(filtered, sinkCfg)
+	}
+	return filtered
+}
+
+// AlertsOnly 是否为告警专用部署
+func (dp *DataProcessor) AlertsOnly() bool {
+	return dp.config.Profile == ProfileAlertsOnly
+}
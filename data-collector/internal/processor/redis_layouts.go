@@ -0,0 +1,163 @@
+package processor
+
+import (
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/warehouse"
+)
+
+// RedisLayouts 数据处理器写入的Redis键布局。
+// 修改键名或字段结构时提升对应布局的Version，并在Migrations中追加将旧数据转换为新结构的迁移
+func RedisLayouts() []database.RedisLayout {
+	return []database.RedisLayout{
+		{
+			Name:        "address_stats",
+			Pattern:     "address_stats:{network}:{address}",
+			Version:     1,
+			Description: "地址统计哈希（sent_count、sent_volume、received_count、received_volume、withdrawal_count、withdrawal_volume、first_seen、last_activity），接收金额含信标链提款，地址为校验和格式；配置retention时每次活动刷新过期时间",
+		},
+		{
+			Name:        "address_stats_changed",
+			Pattern:     warehouse.AddressStatsChangedKey,
+			Version:     1,
+			Description: "待导出到数仓的地址集合，成员为 {network}:{address}",
+		},
+		{
+			Name:        "address_stats_activity",
+			Pattern:     warehouse.AddressStatsActivityKey,
+			Version:     1,
+			Description: "地址最近一次统计更新的时间，成员为 {network}:{address}，分数为Unix时间，启用汇总时用于查找不活跃地址",
+		},
+		{
+			Name:        "kafka_checkpoint",
+			Pattern:     blockCheckpointKeyPrefix + ":{network}:{number}",
+			Version:     1,
+			Description: "区块发布检查点",
+		},
+		{
+			Name:        "seen_tx",
+			Pattern:     seenTransactionKeyPrefix + ":{network}:{hash}:{block_hash}",
+			Version:     1,
+			Description: "最近处理过的交易标记，值为区块高度，按配置的TTL过期",
+		},
+		{
+			Name:        "watchlists",
+			Pattern:     watchlistsKey,
+			Version:     1,
+			Description: "地址关注列表哈希，字段为关注列表ID，值为Watchlist的JSON",
+		},
+		{
+			Name:        "alert_record",
+			Pattern:     alertRecordKeyPrefix + ":{alert_id}",
+			Version:     1,
+			Description: "已发布告警及确认、解决、静默记录，值为AlertRecord的JSON，按保留时长过期；alert_index 为告警ID有序集合，分数为告警时间",
+		},
+		{
+			Name:        "blacklist",
+			Pattern:     blacklistKeyPrefix + ":{entries|history|version}",
+			Version:     1,
+			Description: "审核制黑名单：entries为条目哈希（字段为小写地址，值为BlacklistEntry的JSON），history为BlacklistChange的JSON列表，version为版本计数器",
+		},
+		{
+			Name:        "alert_snoozes",
+			Pattern:     alertSnoozesKey,
+			Version:     1,
+			Description: "按地址静默告警的哈希，字段为 {network}:{address}，值为AlertSnooze的JSON",
+		},
+		{
+			Name:        "method_signatures",
+			Pattern:     methodSignaturesKey,
+			Version:     1,
+			Description: "通过API添加的函数签名哈希，字段为文本签名，值为MethodSignature的JSON",
+		},
+		{
+			Name:        "latest_block",
+			Pattern:     "latest_block:{network}",
+			Version:     1,
+			Description: "最新区块哈希（number、hash、timestamp、tx_count）",
+		},
+		{
+			Name:        "latest_header",
+			Pattern:     "latest_header:{network}",
+			Version:     1,
+			Description: "最新观测到的链头哈希（number、hash、timestamp、observed_at）",
+		},
+		{
+			Name:        "high_risk_tx",
+			Pattern:     "high_risk_tx:{network}",
+			Version:     1,
+			Description: "高风险交易有序集合，成员为交易记录的JSON，分数为交易时间",
+		},
+		{
+			Name:        "published_event",
+			Pattern:     "published_event:{key}",
+			Version:     1,
+			Description: "已发布事件的去重标记",
+		},
+		{
+			Name:        "mixer_exposure",
+			Pattern:     mixerExposureKeyPrefix + ":{network}:{address}",
+			Version:     1,
+			Description: "混币器关联暴露记录",
+		},
+		{
+			Name:        "velocity",
+			Pattern:     velocityKeyPrefix + ":{network}:{address}",
+			Version:     1,
+			Description: "地址交易速率历史",
+		},
+		{
+			Name:        "token_flow",
+			Pattern:     tokenFlowKeyPrefix + ":{network}:{token}:{date}",
+			Version:     1,
+			Description: "代币资金流向按天汇总",
+		},
+		{
+			Name:        "approval_drain",
+			Pattern:     approvalKeyPrefix + ":{network}:{token}:{owner}",
+			Version:     1,
+			Description: "代币授权记录",
+		},
+		{
+			Name:        "supply",
+			Pattern:     supplyKeyPrefix + ":{network}[:{date}]",
+			Version:     1,
+			Description: "供应量累计及按天统计",
+		},
+		{
+			Name:        "bridge_link",
+			Pattern:     bridgeKeyPrefix + ":link:{message_id}",
+			Version:     1,
+			Description: "等待目标链到账的跨链桥存入记录列表（BridgeTransfer JSON），按到账顺序弹出",
+		},
+		{
+			Name:        "bridge_watermark",
+			Pattern:     bridgeKeyPrefix + ":watermark:{stream}",
+			Version:     1,
+			Description: "Wormhole/LayerZero消息流开始监控后看到的第一个序号，bridge:seen:{record_id} 为去重标记",
+		},
+		{
+			Name:        "token_risk",
+			Pattern:     tokenRiskKeyPrefix + ":{network}:{token}",
+			Version:     1,
+			Description: "代币跑路/貔貅分析结果，值为TokenRiskProfile的JSON，按保留时长过期；token_risk:pair:{network}:{pair} 为交易对中被分析代币的地址及位置",
+		},
+		{
+			Name:        "cluster",
+			Pattern:     clusterKeyPrefix + ":{kind}:{network}:{key}",
+			Version:     1,
+			Description: "地址聚类：id为地址所属聚类，members为聚类成员，info为各启发式关联次数，funder/fanout/deposit为注资及充值地址记录",
+		},
+		{
+			Name:        "watch_balance",
+			Pattern:     watchBalanceKeyPrefix + ":{network}:{address}:{asset}",
+			Version:     1,
+			Description: "关注地址回撤窗口内的余额记录（有序集合，成员为 Unix时间:余额），watch_balance:alerted:... 为同一最高点的告警去重标记",
+		},
+		{
+			Name:        "stablecoin_supply",
+			Pattern:     stablecoinKeyPrefix + ":{network}:{token}[:{date}]",
+			Version:     1,
+			Description: "稳定币铸造/销毁累计及按天统计（minted、burned、mints、burns），stablecoin_supply:seen:{record_id} 为去重标记",
+		},
+	}
+}
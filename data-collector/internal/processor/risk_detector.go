@@ -1,18 +1,31 @@
 package processor
 
 import (
+	"fmt"
+	"math"
 	"math/big"
 	"strings"
+	"sync"
 	"time"
 
+	"web3-data-collector/internal/database"
 	"web3-data-collector/internal/models"
 )
 
+// mixerExposureWeight 地址混币暴露分计入风险分的权重
+const mixerExposureWeight = 0.5
+
 // RiskDetector 风险检测器
 type RiskDetector struct {
-	blacklistedAddresses map[string]bool
-	suspiciousContracts  map[string]bool
-	highValueThreshold   *big.Int
+	blacklist            *Blacklist
+	suspiciousContracts  map[string][]SuspiciousTag // 小写地址，同步地址库时整体替换
+	suspiciousMu         sync.RWMutex
+	riskyMethods         map[string]bool // 函数名，需启用函数签名库
+	highValueThreshold   *big.Int // 全局覆盖阈值，为空时使用各网络配置
+	currencies           *CurrencyRegistry
+	mixers               *MixerTracker // 未启用混币器跟踪时为nil
+	velocity             *VelocityTracker // 未启用转出频率检测时为nil
+	clusters             *ClusterEngine // 未启用地址聚类时为nil
 }
 
 // RiskResult 风险检测结果
@@ -24,19 +37,47 @@ type RiskResult struct {
 	RiskFactors  []string `json:"risk_factors"`
 	Title        string   `json:"title"`
 	Description  string   `json:"description"`
+	// 命中黑名单时记录的黑名单版本及条目
+	BlacklistVersion uint64                  `json:"blacklist_version,omitempty"`
+	BlacklistMatches []models.BlacklistEntry `json:"blacklist_matches,omitempty"`
+	// 交易地址未直接命中黑名单，但所在聚类中有黑名单地址
+	ClusterMatches []ClusterBlacklistMatch `json:"cluster_matches,omitempty"`
+	// 混币器交互及交易地址中最高的历史混币暴露分
+	MixerInteraction *MixerInteraction `json:"mixer_interaction,omitempty"`
+	MixerExposure    float64           `json:"mixer_exposure,omitempty"`
+	// 发起方转出笔数或金额相对自身历史平均突增
+	Velocity *VelocityObservation `json:"velocity,omitempty"`
+	// 交互的可疑合约的来源标记（内置列表或诈骗地址库）
+	SuspiciousTags []SuspiciousTag `json:"suspicious_tags,omitempty"`
+	// 启用风险评分器时合并前的规则检测分及各评分器的评分
+	RuleScore    float64            `json:"rule_score,omitempty"`
+	ScorerScores map[string]float64 `json:"scorer_scores,omitempty"`
 }
 
 // NewRiskDetector 创建新的风险检测器
-func NewRiskDetector() *RiskDetector {
-	// 初始化高价值阈值 (1000 ETH)
-	highValueThreshold := new(big.Int)
-	highValueThreshold.SetString("1000000000000000000000", 10) // 1000 * 10^18 wei
+func NewRiskDetector(currencies *CurrencyRegistry, mixers *MixerTracker, velocity *VelocityTracker, clusters *ClusterEngine, redisClient *database.RedisClient) (*RiskDetector, error) {
+	blacklist, err := NewBlacklist(redisClient)
+	if err != nil {
+		return nil, err
+	}
+	// 内置地址只在首次加入，已被停用的内置地址不再重新激活
+	for address := range initBlacklistedAddresses() {
+		if _, exists := blacklist.Get(address); exists {
+			continue
+		}
+		if err := blacklist.activate(address, "built-in blacklist", systemOperator); err != nil {
+			return nil, err
+		}
+	}
 
 	return &RiskDetector{
-		blacklistedAddresses: initBlacklistedAddresses(),
+		blacklist:            blacklist,
 		suspiciousContracts:  initSuspiciousContracts(),
-		highValueThreshold:   highValueThreshold,
-	}
+		currencies:           currencies,
+		mixers:               mixers,
+		velocity:             velocity,
+		clusters:             clusters,
+	}, nil
 }
 
 // AnalyzeTransaction 分析交易风险
@@ -49,7 +90,9 @@ func (rd *RiskDetector) AnalyzeTransaction(tx *models.Transaction) *RiskResult {
 	}
 
 	// 检查黑名单地址
-	if rd.checkBlacklistedAddress(tx) {
+	if matches := rd.checkBlacklistedAddress(tx); len(matches) > 0 {
+		result.BlacklistVersion = rd.blacklist.Version()
+		result.BlacklistMatches = matches
 		result.RiskDetected = true
 		result.RiskScore += 0.8
 		result.RiskFactors = append(result.RiskFactors, "blacklisted_address")
@@ -58,6 +101,22 @@ func (rd *RiskDetector) AnalyzeTransaction(tx *models.Transaction) *RiskResult {
 		result.Description = "检测到与黑名单地址相关的交易"
 	}
 
+	// 按交易更新地址聚类，未直接命中黑名单时检查交易地址所在聚类中的黑名单地址
+	if rd.clusters != nil {
+		rd.checkCluster(tx, result)
+	}
+	if len(result.ClusterMatches) > 0 {
+		result.BlacklistVersion = rd.blacklist.Version()
+		result.RiskDetected = true
+		result.RiskScore += 0.6
+		result.RiskFactors = append(result.RiskFactors, "blacklisted_cluster")
+		if result.RiskType == "" {
+			result.RiskType = "BLACKLIST_CLUSTER"
+			result.Title = "黑名单关联地址交易"
+			result.Description = "交易地址与黑名单地址属于同一实体聚类"
+		}
+	}
+
 	// 检查高价值交易
 	if rd.checkHighValueTransaction(tx) {
 		result.RiskDetected = true
@@ -71,7 +130,8 @@ func (rd *RiskDetector) AnalyzeTransaction(tx *models.Transaction) *RiskResult {
 	}
 
 	// 检查可疑合约
-	if rd.checkSuspiciousContract(tx) {
+	if tags := rd.checkSuspiciousContract(tx); len(tags) > 0 {
+		result.SuspiciousTags = tags
 		result.RiskDetected = true
 		result.RiskScore += 0.7
 		result.RiskFactors = append(result.RiskFactors, "suspicious_contract")
@@ -82,6 +142,50 @@ func (rd *RiskDetector) AnalyzeTransaction(tx *models.Transaction) *RiskResult {
 		}
 	}
 
+	// 检查高风险函数调用
+	if rd.checkRiskyMethod(tx) {
+		result.RiskDetected = true
+		result.RiskScore += 0.4
+		result.RiskFactors = append(result.RiskFactors, "risky_method")
+		if result.RiskType == "" {
+			result.RiskType = "RISKY_METHOD"
+			result.Title = "高风险函数调用"
+			result.Description = fmt.Sprintf("检测到对 %s 的调用", tx.MethodName)
+		}
+	}
+
+	// 检查混币器交互及地址的历史混币暴露
+	if rd.mixers != nil {
+		rd.checkMixer(tx, result)
+	}
+	if result.MixerInteraction != nil {
+		result.RiskDetected = true
+		result.RiskScore += 0.5
+		result.RiskFactors = append(result.RiskFactors, "mixer_"+result.MixerInteraction.Kind)
+		if result.RiskType == "" {
+			result.RiskType = "MIXER"
+			result.Title = "混币器交互"
+			result.Description = "检测到与混币器合约的存取款交互"
+		}
+	}
+	if result.MixerExposure > 0 {
+		result.RiskScore += result.MixerExposure * mixerExposureWeight
+		result.RiskFactors = append(result.RiskFactors, "mixer_exposure")
+		if result.MixerExposure >= 0.5 {
+			result.RiskDetected = true
+			if result.RiskType == "" {
+				result.RiskType = "MIXER_EXPOSURE"
+				result.Title = "混币器关联地址"
+				result.Description = "交易地址曾与混币器存在资金往来"
+			}
+		}
+	}
+
+	// 检查发起方转出频率及金额突增
+	if rd.velocity != nil {
+		rd.checkVelocity(tx, result)
+	}
+
 	// 检查异常Gas费用
 	if rd.checkAbnormalGasFee(tx) {
 		result.RiskScore += 0.3
@@ -119,23 +223,180 @@ func (rd *RiskDetector) AnalyzeTransaction(tx *models.Transaction) *RiskResult {
 	return result
 }
 
-// checkBlacklistedAddress 检查黑名单地址
-func (rd *RiskDetector) checkBlacklistedAddress(tx *models.Transaction) bool {
-	return rd.blacklistedAddresses[strings.ToLower(tx.FromAddress)] ||
-		rd.blacklistedAddresses[strings.ToLower(tx.ToAddress)]
+// checkMixer 先读取交易双方已有的暴露分，再记录本笔混币器交互，本笔交易不计入自身暴露
+func (rd *RiskDetector) checkMixer(tx *models.Transaction, result *RiskResult) {
+	for _, address := range []string{tx.FromAddress, tx.ToAddress} {
+		exposure, err := rd.mixers.Exposure(tx.Network, address)
+		if err != nil {
+			logger.Warnf("Failed to get mixer exposure of %s on %s: %v", address, tx.Network, err)
+			continue
+		}
+		result.MixerExposure = math.Max(result.MixerExposure, exposure)
+	}
+
+	interaction := rd.mixers.Detect(tx)
+	if interaction == nil {
+		return
+	}
+	result.MixerInteraction = interaction
+	if err := rd.mixers.Record(interaction); err != nil {
+		logger.Errorf("Failed to record mixer %s of %s in %s: %v", interaction.Kind, interaction.Address, tx.Hash, err)
+	}
+}
+
+// checkCluster 先按本笔交易关联地址，再查找交易双方所在聚类中的黑名单地址
+func (rd *RiskDetector) checkCluster(tx *models.Transaction, result *RiskResult) {
+	rd.clusters.ObserveTransaction(tx)
+	if len(result.BlacklistMatches) > 0 {
+		return
+	}
+
+	addresses := []string{tx.FromAddress}
+	if tx.ToAddress != "" && !strings.EqualFold(tx.ToAddress, tx.FromAddress) {
+		addresses = append(addresses, tx.ToAddress)
+	}
+	for _, address := range addresses {
+		matches, err := rd.clusters.MatchBlacklist(rd.blacklist, tx.Network, address, tx.Timestamp)
+		if err != nil {
+			logger.Warnf("Failed to check cluster of %s on %s: %v", address, tx.Network, err)
+			continue
+		}
+		result.ClusterMatches = append(result.ClusterMatches, matches...)
+	}
+}
+
+// AddressReputation 地址信誉查询结果
+type AddressReputation struct {
+	Address        string                  `json:"address"`
+	Network        string                  `json:"network,omitempty"`
+	Blacklisted    bool                    `json:"blacklisted"`
+	BlacklistEntry *models.BlacklistEntry  `json:"blacklist_entry,omitempty"`
+	SuspiciousTags []SuspiciousTag         `json:"suspicious_tags"`
+	ClusterMatches []ClusterBlacklistMatch `json:"cluster_matches,omitempty"`
+	MixerExposure  float64                 `json:"mixer_exposure,omitempty"`
+	RiskScore      float64                 `json:"risk_score"`
+	RiskLevel      string                  `json:"risk_level"`
+	RiskFactors    []string                `json:"risk_factors"`
+}
+
+// CheckAddress 按需查询地址信誉，各项与交易检测使用相同的分值；
+// network为空时只查询黑名单及全部网络的可疑标记
+func (rd *RiskDetector) CheckAddress(network, address string) (*AddressReputation, error) {
+	now := time.Now()
+	reputation := &AddressReputation{
+		Address:        address,
+		Network:        network,
+		SuspiciousTags: []SuspiciousTag{},
+		RiskFactors:    []string{},
+	}
+
+	if entry, ok := rd.blacklist.Match(address, now); ok {
+		reputation.Blacklisted = true
+		reputation.BlacklistEntry = entry
+		reputation.RiskScore += 0.8
+		reputation.RiskFactors = append(reputation.RiskFactors, "blacklisted_address")
+	}
+
+	if tags := rd.SuspiciousTags(network, address); len(tags) > 0 {
+		reputation.SuspiciousTags = tags
+		reputation.RiskScore += 0.7
+		reputation.RiskFactors = append(reputation.RiskFactors, "suspicious_contract")
+	}
+
+	if network != "" && rd.clusters != nil && !reputation.Blacklisted {
+		matches, err := rd.clusters.MatchBlacklist(rd.blacklist, network, address, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check cluster: %w", err)
+		}
+		if len(matches) > 0 {
+			reputation.ClusterMatches = matches
+			reputation.RiskScore += 0.6
+			reputation.RiskFactors = append(reputation.RiskFactors, "blacklisted_cluster")
+		}
+	}
+
+	if network != "" && rd.mixers != nil {
+		exposure, err := rd.mixers.Exposure(network, address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get mixer exposure: %w", err)
+		}
+		if exposure > 0 {
+			reputation.MixerExposure = exposure
+			reputation.RiskScore += exposure * mixerExposureWeight
+			reputation.RiskFactors = append(reputation.RiskFactors, "mixer_exposure")
+		}
+	}
+
+	reputation.RiskLevel = rd.calculateRiskLevel(reputation.RiskScore)
+	return reputation, nil
+}
+
+// checkVelocity 将交易计入发起方的转出统计，笔数或金额达到自身历史平均的数倍时视为风险
+func (rd *RiskDetector) checkVelocity(tx *models.Transaction, result *RiskResult) {
+	observation, err := rd.velocity.Observe(tx)
+	if err != nil {
+		logger.Errorf("Failed to record velocity of %s in %s: %v", tx.FromAddress, tx.Hash, err)
+		return
+	}
+	if observation == nil || !(observation.Burst || observation.VolumeBurst) {
+		return
+	}
+
+	result.Velocity = observation
+	result.RiskDetected = true
+	result.RiskScore += 0.5
+	if observation.Burst {
+		result.RiskFactors = append(result.RiskFactors, "velocity_burst")
+	}
+	if observation.VolumeBurst {
+		result.RiskFactors = append(result.RiskFactors, "volume_burst")
+	}
+	if result.RiskType == "" {
+		result.RiskType = "VELOCITY_BURST"
+		result.Title = "转出频率突增"
+		result.Description = "地址短时间内的转出笔数或金额远超其历史水平"
+	}
+}
+
+// checkBlacklistedAddress 检查黑名单地址，返回交易时间点生效的命中条目
+func (rd *RiskDetector) checkBlacklistedAddress(tx *models.Transaction) []models.BlacklistEntry {
+	var matches []models.BlacklistEntry
+	if entry, ok := rd.blacklist.Match(tx.FromAddress, tx.Timestamp); ok {
+		matches = append(matches, *entry)
+	}
+	if tx.ToAddress != "" && !strings.EqualFold(tx.ToAddress, tx.FromAddress) {
+		if entry, ok := rd.blacklist.Match(tx.ToAddress, tx.Timestamp); ok {
+			matches = append(matches, *entry)
+		}
+	}
+	return matches
 }
 
 // checkHighValueTransaction 检查高价值交易
 func (rd *RiskDetector) checkHighValueTransaction(tx *models.Transaction) bool {
-	return tx.Value.Cmp(rd.highValueThreshold) > 0
+	threshold := rd.highValueThreshold
+	if threshold == nil {
+		currency := rd.currencies.Get(tx.Network)
+		// 已知美元价值且配置了法币阈值时按美元判断
+		if tx.USDValue > 0 && currency.HighValueThresholdUSD > 0 {
+			return tx.USDValue > currency.HighValueThresholdUSD
+		}
+		threshold = currency.HighValueThreshold
+	}
+	return tx.Value.Cmp(threshold) > 0
 }
 
-// checkSuspiciousContract 检查可疑合约
-func (rd *RiskDetector) checkSuspiciousContract(tx *models.Transaction) bool {
+// checkSuspiciousContract 检查可疑合约，返回适用于交易网络的标记
+func (rd *RiskDetector) checkSuspiciousContract(tx *models.Transaction) []SuspiciousTag {
 	if tx.ToAddress == "" {
-		return false
+		return nil
 	}
-	return rd.suspiciousContracts[strings.ToLower(tx.ToAddress)]
+	return rd.SuspiciousTags(tx.Network, tx.ToAddress)
+}
+
+// checkRiskyMethod 检查交易调用的函数是否为高风险函数，函数名由函数签名库解析
+func (rd *RiskDetector) checkRiskyMethod(tx *models.Transaction) bool {
+	return tx.MethodName != "" && rd.riskyMethods[tx.MethodName]
 }
 
 // checkAbnormalGasFee 检查异常Gas费用
@@ -143,10 +404,9 @@ func (rd *RiskDetector) checkAbnormalGasFee(tx *models.Transaction) bool {
 	// 计算总Gas费用
 	totalGasFee := new(big.Int).Mul(tx.GasPrice, big.NewInt(int64(tx.Gas)))
 	
-	// 异常高的Gas费用阈值 (100 ETH)
-	abnormalThreshold := new(big.Int)
-	abnormalThreshold.SetString("100000000000000000000", 10) // 100 * 10^18 wei
-	
+	// 异常高的Gas费用阈值 (100个原生币)
+	abnormalThreshold := rd.currencies.Get(tx.Network).AbnormalGasFee
+
 	return totalGasFee.Cmp(abnormalThreshold) > 0
 }
 
@@ -193,37 +453,105 @@ func initBlacklistedAddresses() map[string]bool {
 	return blacklist
 }
 
-// initSuspiciousContracts 初始化可疑合约
-func initSuspiciousContracts() map[string]bool {
-	// 这里应该从数据库加载已知的可疑合约地址
-	suspicious := map[string]bool{
+// initSuspiciousContracts 初始化可疑合约，诈骗地址库中的地址由同步任务另行标记
+func initSuspiciousContracts() map[string][]SuspiciousTag {
+	suspicious := map[string][]SuspiciousTag{
 		// 示例可疑合约地址（小写）
-		"0x1234567890abcdef1234567890abcdef12345678": true, // 混币器合约
-		"0xabcdef1234567890abcdef1234567890abcdef12": true, // 钓鱼合约
+		"0x1234567890abcdef1234567890abcdef12345678": {{Source: builtinSuspiciousSource, Category: "mixer"}},
+		"0xabcdef1234567890abcdef1234567890abcdef12": {{Source: builtinSuspiciousSource, Category: "phishing"}},
 	}
 	return suspicious
 }
 
-// UpdateBlacklist 更新黑名单
+// SuspiciousTags 获取地址在指定网络上的可疑标记，network为空时返回全部标记
+func (rd *RiskDetector) SuspiciousTags(network, address string) []SuspiciousTag {
+	rd.suspiciousMu.RLock()
+	tags := rd.suspiciousContracts[strings.ToLower(address)]
+	rd.suspiciousMu.RUnlock()
+
+	var matched []SuspiciousTag
+	for _, tag := range tags {
+		if network == "" || tag.appliesTo(network) {
+			matched = append(matched, tag)
+		}
+	}
+	return matched
+}
+
+// ReplaceSuspiciousContracts 用来源的最新地址整体替换该来源的标记，返回标记的地址数；由同步任务串行调用
+func (rd *RiskDetector) ReplaceSuspiciousContracts(tag SuspiciousTag, addresses []string) int {
+	rd.suspiciousMu.RLock()
+	current := rd.suspiciousContracts
+	rd.suspiciousMu.RUnlock()
+
+	// 复制后替换，同步期间的检测仍使用旧标记
+	next := make(map[string][]SuspiciousTag, len(current)+len(addresses))
+	for address, tags := range current {
+		var kept []SuspiciousTag
+		for _, existing := range tags {
+			if existing.Source != tag.Source {
+				kept = append(kept, existing)
+			}
+		}
+		if len(kept) > 0 {
+			next[address] = kept
+		}
+	}
+	tagged := 0
+	for _, address := range addresses {
+		address = strings.ToLower(address)
+		if containsSuspiciousSource(next[address], tag.Source) {
+			continue
+		}
+		next[address] = append(next[address], tag)
+		tagged++
+	}
+
+	rd.suspiciousMu.Lock()
+	rd.suspiciousContracts = next
+	rd.suspiciousMu.Unlock()
+	return tagged
+}
+
+// UpdateBlacklist 更新黑名单（直接生效，不经审核）
 func (rd *RiskDetector) UpdateBlacklist(addresses []string) {
 	for _, addr := range addresses {
-		rd.blacklistedAddresses[strings.ToLower(addr)] = true
+		if err := rd.blacklist.activate(addr, "", systemOperator); err != nil {
+			logger.Errorf("Failed to blacklist %s: %v", addr, err)
+		}
 	}
 }
 
-// RemoveFromBlacklist 从黑名单移除
+// RemoveFromBlacklist 从黑名单移除（软删除，保留历史）
 func (rd *RiskDetector) RemoveFromBlacklist(address string) {
-	delete(rd.blacklistedAddresses, strings.ToLower(address))
+	if _, err := rd.blacklist.Retire(address, systemOperator, ""); err != nil {
+		logger.Errorf("Failed to retire blacklist entry %s: %v", address, err)
+	}
 }
 
 // IsBlacklisted 检查地址是否在黑名单中
 func (rd *RiskDetector) IsBlacklisted(address string) bool {
-	return rd.blacklistedAddresses[strings.ToLower(address)]
+	_, ok := rd.blacklist.Match(address, time.Now())
+	return ok
 }
 
-// GetBlacklistSize 获取黑名单大小
+// GetBlacklistSize 获取生效中的黑名单大小
 func (rd *RiskDetector) GetBlacklistSize() int {
-	return len(rd.blacklistedAddresses)
+	return rd.blacklist.ActiveCount()
+}
+
+// Blacklist 获取黑名单，用于审核流程管理
+func (rd *RiskDetector) Blacklist() *Blacklist {
+	return rd.blacklist
+}
+
+// SetRiskyMethods 设置高风险函数名
+func (rd *RiskDetector) SetRiskyMethods(methods []string) {
+	riskyMethods := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		riskyMethods[method] = true
+	}
+	rd.riskyMethods = riskyMethods
 }
 
 // SetHighValueThreshold 设置高价值阈值
@@ -0,0 +1,427 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/metrics"
+	"web3-data-collector/internal/models"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// 风险评分合并方式
+const (
+	RiskMergeMax             = "max"              // 取规则分与各评分器加权分的最大值
+	RiskMergeSum             = "sum"              // 在规则分上累加各评分器加权分
+	RiskMergeWeightedAverage = "weighted_average" // 按权重平均规则分与各评分器分
+)
+
+// defaultRiskScorerBudget 未配置latency_budget时评分器的耗时上限
+const defaultRiskScorerBudget = 100 * time.Millisecond
+
+// defaultRiskScorerMethod grpc评分器默认调用的方法
+const defaultRiskScorerMethod = "/web3.risk.v1.RiskScorer/Score"
+
+// RiskScorer 风险评分器，与规则检测并行为交易打分，需在ctx结束时返回
+type RiskScorer interface {
+	Name() string
+	Score(ctx context.Context, request *RiskScoreRequest) (*RiskScore, error)
+}
+
+// RiskScoreRequest 评分请求，附带规则检测的结果供模型参考
+type RiskScoreRequest struct {
+	Transaction *models.Transaction `json:"transaction"`
+	RuleScore   float64             `json:"rule_score"`
+	RuleFactors []string            `json:"rule_factors"`
+}
+
+// RiskScore 评分器结果，Score取值0-1
+type RiskScore struct {
+	Score       float64  `json:"score"`
+	Factors     []string `json:"factors,omitempty"`
+	Type        string   `json:"type,omitempty"` // 单独触发告警时的告警类型，默认MODEL_RISK
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Model       string   `json:"model,omitempty"` // 模型版本
+}
+
+// RiskScorerFactory 按配置创建评分器
+type RiskScorerFactory func(cfg config.RiskScorerConfig) (RiskScorer, error)
+
+// riskScorerFactories 按类型创建评分器，可通过 RegisterRiskScorerType 增加类型
+var riskScorerFactories = map[string]RiskScorerFactory{
+	"http": newHTTPRiskScorer,
+	"grpc": newGRPCRiskScorer,
+}
+
+// RegisterRiskScorerType 注册评分器类型，配置中type为该类型的评分器由factory创建；需在创建数据处理器前调用
+func RegisterRiskScorerType(kind string, factory RiskScorerFactory) {
+	riskScorerFactories[kind] = factory
+}
+
+// RiskScorers 规则检测之外的风险评分器，按各自的耗时上限并行调用并合并评分
+type RiskScorers struct {
+	scorers        []*riskScorerEntry
+	merge          string
+	ruleWeight     float64
+	metricsManager *metrics.Manager
+}
+
+// riskScorerEntry 已注册的评分器及其合并参数
+type riskScorerEntry struct {
+	scorer    RiskScorer
+	weight    float64
+	budget    time.Duration
+	threshold float64
+	networks  map[string]bool // 为空表示全部网络
+}
+
+// riskScorerOutcome 单个评分器的调用结果
+type riskScorerOutcome struct {
+	entry *riskScorerEntry
+	score *RiskScore
+	err   error
+}
+
+// NewRiskScorers 根据配置创建评分器，未启用或没有启用的评分器时返回nil
+func NewRiskScorers(cfg config.RiskScoringConfig, metricsManager *metrics.Manager) (*RiskScorers, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	scorers := &RiskScorers{
+		merge:          cfg.Merge,
+		ruleWeight:     cfg.RuleWeight,
+		metricsManager: metricsManager,
+	}
+	if scorers.merge == "" {
+		scorers.merge = RiskMergeMax
+	}
+	for _, scorerCfg := range cfg.Scorers {
+		if !scorerCfg.Enabled {
+			continue
+		}
+		factory, exists := riskScorerFactories[scorerCfg.Type]
+		if !exists {
+			return nil, fmt.Errorf("unknown risk scorer type %q for %s", scorerCfg.Type, scorerCfg.Name)
+		}
+		scorer, err := factory(scorerCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create risk scorer %s: %w", scorerCfg.Name, err)
+		}
+		if err := scorers.Register(scorer, scorerCfg); err != nil {
+			return nil, err
+		}
+	}
+	if len(scorers.scorers) == 0 {
+		return nil, nil
+	}
+
+	logger.Infof("Risk scoring enabled with %d scorers (merge: %s)", len(scorers.scorers), scorers.merge)
+	return scorers, nil
+}
+
+// Register 注册评分器，cfg中只使用合并参数（weight、latency_budget、alert_threshold、networks）
+func (rs *RiskScorers) Register(scorer RiskScorer, cfg config.RiskScorerConfig) error {
+	entry := &riskScorerEntry{
+		scorer:    scorer,
+		weight:    cfg.Weight,
+		budget:    defaultRiskScorerBudget,
+		threshold: cfg.AlertThreshold,
+	}
+	if entry.weight == 0 {
+		entry.weight = 1
+	}
+	if cfg.LatencyBudget != "" {
+		budget, err := time.ParseDuration(cfg.LatencyBudget)
+		if err != nil || budget <= 0 {
+			return fmt.Errorf("invalid latency budget %q for risk scorer %s", cfg.LatencyBudget, scorer.Name())
+		}
+		entry.budget = budget
+	}
+	if len(cfg.Networks) > 0 {
+		entry.networks = make(map[string]bool, len(cfg.Networks))
+		for _, network := range cfg.Networks {
+			entry.networks[network] = true
+		}
+	}
+
+	rs.scorers = append(rs.scorers, entry)
+	return nil
+}
+
+// Apply 调用适用于交易网络的评分器并将评分合并到规则检测结果，超过耗时上限或失败的评分器不计入；
+// 返回是否有评分器的结果被合并
+func (rs *RiskScorers) Apply(tx *models.Transaction, result *RiskResult) bool {
+	request := &RiskScoreRequest{
+		Transaction: tx,
+		RuleScore:   result.RiskScore,
+		RuleFactors: append([]string(nil), result.RiskFactors...),
+	}
+
+	outcomes := rs.score(tx.Network, request)
+	if len(outcomes) == 0 {
+		return false
+	}
+
+	result.RuleScore = result.RiskScore
+	result.ScorerScores = make(map[string]float64, len(outcomes))
+	merged := result.RiskScore
+	totalWeight := rs.ruleWeight
+	if rs.merge == RiskMergeWeightedAverage {
+		merged *= rs.ruleWeight
+	}
+
+	for _, outcome := range outcomes {
+		name := outcome.entry.scorer.Name()
+		score := math.Max(0, math.Min(1, outcome.score.Score))
+		weighted := score * outcome.entry.weight
+		result.ScorerScores[name] = score
+
+		switch rs.merge {
+		case RiskMergeSum:
+			merged += weighted
+		case RiskMergeWeightedAverage:
+			merged += weighted
+			totalWeight += outcome.entry.weight
+		default:
+			merged = math.Max(merged, weighted)
+		}
+
+		if outcome.entry.threshold <= 0 || weighted < outcome.entry.threshold {
+			continue
+		}
+		result.RiskDetected = true
+		result.RiskFactors = append(result.RiskFactors, "scorer_"+name)
+		result.RiskFactors = append(result.RiskFactors, outcome.score.Factors...)
+		if result.RiskType == "" {
+			result.RiskType = outcome.score.Type
+			if result.RiskType == "" {
+				result.RiskType = "MODEL_RISK"
+			}
+			result.Title = outcome.score.Title
+			if result.Title == "" {
+				result.Title = "模型风险评分"
+			}
+			result.Description = outcome.score.Description
+			if result.Description == "" {
+				result.Description = fmt.Sprintf("评分器 %s 给出风险分 %.2f", name, score)
+			}
+		}
+	}
+
+	if rs.merge == RiskMergeWeightedAverage && totalWeight > 0 {
+		merged /= totalWeight
+	}
+	result.RiskScore = merged
+	return true
+}
+
+// score 并行调用评分器，等待到最长的耗时上限为止，未按时返回的结果丢弃
+func (rs *RiskScorers) score(network string, request *RiskScoreRequest) []*riskScorerOutcome {
+	var entries []*riskScorerEntry
+	var deadline time.Duration
+	for _, entry := range rs.scorers {
+		if entry.networks != nil && !entry.networks[network] {
+			continue
+		}
+		entries = append(entries, entry)
+		if entry.budget > deadline {
+			deadline = entry.budget
+		}
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	results := make(chan *riskScorerOutcome, len(entries))
+	for _, entry := range entries {
+		go func(entry *riskScorerEntry) {
+			ctx, cancel := context.WithTimeout(context.Background(), entry.budget)
+			defer cancel()
+			score, err := entry.scorer.Score(ctx, request)
+			if err == nil && ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			results <- &riskScorerOutcome{entry: entry, score: score, err: err}
+		}(entry)
+	}
+
+	timer := time.NewTimer(deadline)
+	defer timer.Stop()
+
+	pending := make(map[*riskScorerEntry]bool, len(entries))
+	for _, entry := range entries {
+		pending[entry] = true
+	}
+	var outcomes []*riskScorerOutcome
+	for len(pending) > 0 {
+		select {
+		case outcome := <-results:
+			delete(pending, outcome.entry)
+			name := outcome.entry.scorer.Name()
+			elapsed := time.Since(start)
+			switch {
+			case errors.Is(outcome.err, context.DeadlineExceeded) || elapsed >= outcome.entry.budget:
+				rs.metricsManager.RecordRiskScorer(name, "timeout", outcome.entry.budget)
+			case outcome.err != nil || outcome.score == nil:
+				logger.Warnf("Risk scorer %s failed for %s: %v", name, request.Transaction.Hash, outcome.err)
+				rs.metricsManager.RecordRiskScorer(name, "error", elapsed)
+			default:
+				rs.metricsManager.RecordRiskScorer(name, "ok", elapsed)
+				outcomes = append(outcomes, outcome)
+			}
+		case <-timer.C:
+			// 忽略ctx的评分器不再等待，其结果到达时被丢弃
+			for entry := range pending {
+				rs.metricsManager.RecordRiskScorer(entry.scorer.Name(), "timeout", entry.budget)
+			}
+			return outcomes
+		}
+	}
+	return outcomes
+}
+
+// httpRiskScorer 通过HTTP调用外部推理服务：POST RiskScoreRequest的JSON，返回RiskScore的JSON
+type httpRiskScorer struct {
+	name     string
+	endpoint string
+	headers  map[string]string
+	http     *http.Client
+}
+
+func newHTTPRiskScorer(cfg config.RiskScorerConfig) (RiskScorer, error) {
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("endpoint is required")
+	}
+	// 耗时由调用方的ctx控制
+	return &httpRiskScorer{
+		name:     cfg.Name,
+		endpoint: cfg.Endpoint,
+		headers:  cfg.Headers,
+		http:     &http.Client{},
+	}, nil
+}
+
+func (hs *httpRiskScorer) Name() string {
+	return hs.name
+}
+
+func (hs *httpRiskScorer) Score(ctx context.Context, request *RiskScoreRequest) (*RiskScore, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hs.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range hs.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := hs.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("scorer returned status %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+
+	var score RiskScore
+	if err := json.NewDecoder(resp.Body).Decode(&score); err != nil {
+		return nil, fmt.Errorf("invalid scorer response: %w", err)
+	}
+	return &score, nil
+}
+
+// grpcRiskScorer 通过gRPC调用外部推理服务，请求及响应为 google.protobuf.Struct，字段与HTTP评分器相同
+type grpcRiskScorer struct {
+	name    string
+	method  string
+	headers map[string]string
+	conn    *grpc.ClientConn
+}
+
+func newGRPCRiskScorer(cfg config.RiskScorerConfig) (RiskScorer, error) {
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("endpoint is required")
+	}
+
+	creds := insecure.NewCredentials()
+	if cfg.TLS {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+	// 连接在首次调用时建立，推理服务暂不可用不影响启动
+	conn, err := grpc.Dial(cfg.Endpoint, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+
+	method := cfg.Method
+	if method == "" {
+		method = defaultRiskScorerMethod
+	}
+	return &grpcRiskScorer{
+		name:    cfg.Name,
+		method:  method,
+		headers: cfg.Headers,
+		conn:    conn,
+	}, nil
+}
+
+func (gs *grpcRiskScorer) Name() string {
+	return gs.name
+}
+
+func (gs *grpcRiskScorer) Score(ctx context.Context, request *RiskScoreRequest) (*RiskScore, error) {
+	// 经JSON转换为Struct，数值字段为double
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	in, err := structpb.NewStruct(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(gs.headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(gs.headers))
+	}
+	out := &structpb.Struct{}
+	if err := gs.conn.Invoke(ctx, gs.method, in, out); err != nil {
+		return nil, err
+	}
+
+	data, err = json.Marshal(out.AsMap())
+	if err != nil {
+		return nil, err
+	}
+	var score RiskScore
+	if err := json.Unmarshal(data, &score); err != nil {
+		return nil, fmt.Errorf("invalid scorer response: %w", err)
+	}
+	return &score, nil
+}
@@ -0,0 +1,93 @@
+package processor
+
+import (
+	"hash/fnv"
+	"math/big"
+	"sync"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/models"
+)
+
+// networkSampling 网络的交易发布抽样参数
+type networkSampling struct {
+	rate        float64
+	minValue    *big.Int // 以最小单位表示，为nil时不按金额保留
+	minValueUSD float64
+	keepMatched bool
+}
+
+// TransactionSampler 按网络配置抽样发布低于阈值的交易，避免高吞吐网络压垮Kafka及InfluxDB。
+// 区块（含区块内交易汇总）照常发布，处理计数等指标仍按全部交易统计
+type TransactionSampler struct {
+	networks map[string]*networkSampling
+	mu       sync.RWMutex
+}
+
+// NewTransactionSampler 根据网络配置创建交易抽样器
+func NewTransactionSampler(networks map[string]config.NetworkConfig) *TransactionSampler {
+	sampler := &TransactionSampler{}
+	sampler.Update(networks)
+
+	return sampler
+}
+
+// Update 根据新的网络配置更新抽样参数（配置热加载）
+func (ts *TransactionSampler) Update(networks map[string]config.NetworkConfig) {
+	samplings := make(map[string]*networkSampling)
+	for name, networkCfg := range networks {
+		cfg := networkCfg.Sampling
+		if !cfg.Enabled {
+			continue
+		}
+		sampling := &networkSampling{
+			rate:        cfg.Rate,
+			minValueUSD: cfg.MinValueUSD,
+			keepMatched: cfg.KeepMatched,
+		}
+		if cfg.MinValue != "" {
+			sampling.minValue = newNativeCurrency(networkCfg.NativeCurrency).parseUnits(cfg.MinValue)
+		}
+		samplings[name] = sampling
+	}
+
+	ts.mu.Lock()
+	ts.networks = samplings
+	ts.mu.Unlock()
+}
+
+// Sample 判断交易是否发布，返回发布时记录到交易上的抽样比例，达到阈值总是发布的交易及未配置抽样的网络为0。
+// 按交易哈希决定，同一交易的结果稳定
+func (ts *TransactionSampler) Sample(tx *models.Transaction, filterResult *models.FilterResult) (bool, float64) {
+	ts.mu.RLock()
+	sampling, exists := ts.networks[tx.Network]
+	ts.mu.RUnlock()
+
+	if !exists || sampling.keeps(tx, filterResult) {
+		return true, 0
+	}
+	if sampling.rate <= 0 {
+		return false, 0
+	}
+	if sampling.rate >= 1 {
+		return true, 0
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(tx.Hash))
+	if float64(hash.Sum32()%10000) < sampling.rate*10000 {
+		return true, sampling.rate
+	}
+	return false, 0
+}
+
+// keeps 交易达到金额阈值或被过滤规则命中时总是发布
+func (ns *networkSampling) keeps(tx *models.Transaction, filterResult *models.FilterResult) bool {
+	if ns.keepMatched && filterResult != nil && filterResult.Matched {
+		return true
+	}
+	if ns.minValue != nil && tx.Value != nil && tx.Value.Cmp(ns.minValue) >= 0 {
+		return true
+	}
+	return ns.minValueUSD > 0 && tx.USDValue >= ns.minValueUSD
+}
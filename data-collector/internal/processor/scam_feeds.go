@@ -0,0 +1,282 @@
+package processor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/metrics"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// builtinSuspiciousSource 内置可疑合约列表的来源名
+const builtinSuspiciousSource = "built-in"
+
+// maxScamFeedSize 单个地址库下载内容的上限
+const maxScamFeedSize = 64 << 20
+
+// SuspiciousTag 可疑地址的一个来源标记
+type SuspiciousTag struct {
+	Source   string   `json:"source"`             // built-in 或地址库名称
+	Category string   `json:"category,omitempty"` // 如 phishing / scam / mixer
+	Networks []string `json:"networks,omitempty"` // 为空表示全部网络
+}
+
+// appliesTo 标记是否适用于指定网络
+func (tag SuspiciousTag) appliesTo(network string) bool {
+	if len(tag.Networks) == 0 {
+		return true
+	}
+	for _, name := range tag.Networks {
+		if name == network {
+			return true
+		}
+	}
+	return false
+}
+
+// containsSuspiciousSource 标记中是否已有指定来源
+func containsSuspiciousSource(tags []SuspiciousTag, source string) bool {
+	for _, tag := range tags {
+		if tag.Source == source {
+			return true
+		}
+	}
+	return false
+}
+
+// ScamFeedStatus 地址库最近一次同步的状态
+type ScamFeedStatus struct {
+	Name      string     `json:"name"`
+	Addresses int        `json:"addresses"` // 当前标记的地址数
+	LastSync  *time.Time `json:"last_sync,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+}
+
+// ScamFeedSyncer 定期下载社区诈骗/钓鱼地址库，库中地址作为可疑合约标记到风险检测器；
+// 下载或解析失败时保留该库上一次同步的标记
+type ScamFeedSyncer struct {
+	feeds          []config.ScamFeedConfig
+	interval       time.Duration
+	detector       *RiskDetector
+	http           *http.Client
+	metricsManager *metrics.Manager
+	status         map[string]*ScamFeedStatus
+	mu             sync.RWMutex
+}
+
+// NewScamFeedSyncer 根据配置创建地址库同步任务，未启用或没有启用的地址库时返回nil
+func NewScamFeedSyncer(cfg config.ScamFeedsConfig, detector *RiskDetector, metricsManager *metrics.Manager) (*ScamFeedSyncer, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	interval := 6 * time.Hour
+	if cfg.Interval != "" {
+		parsed, err := time.ParseDuration(cfg.Interval)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid scam feed interval %q", cfg.Interval)
+		}
+		interval = parsed
+	}
+	timeout := 30 * time.Second
+	if cfg.Timeout != "" {
+		parsed, err := time.ParseDuration(cfg.Timeout)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid scam feed timeout %q", cfg.Timeout)
+		}
+		timeout = parsed
+	}
+
+	syncer := &ScamFeedSyncer{
+		interval:       interval,
+		detector:       detector,
+		http:           &http.Client{Timeout: timeout},
+		metricsManager: metricsManager,
+		status:         make(map[string]*ScamFeedStatus),
+	}
+	for _, feed := range cfg.Feeds {
+		if !feed.Enabled {
+			continue
+		}
+		if feed.Name == "" || feed.URL == "" {
+			return nil, fmt.Errorf("scam feed name and url are required")
+		}
+		if feed.Name == builtinSuspiciousSource {
+			return nil, fmt.Errorf("scam feed name %q is reserved", feed.Name)
+		}
+		switch feed.Format {
+		case "", "text", "json":
+		default:
+			return nil, fmt.Errorf("unsupported format %q of scam feed %s", feed.Format, feed.Name)
+		}
+		syncer.feeds = append(syncer.feeds, feed)
+		syncer.status[feed.Name] = &ScamFeedStatus{Name: feed.Name}
+	}
+	if len(syncer.feeds) == 0 {
+		return nil, nil
+	}
+	return syncer, nil
+}
+
+// Run 启动时立即同步，之后按间隔定期同步
+func (s *ScamFeedSyncer) Run(ctx context.Context) {
+	s.syncAll(ctx)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.syncAll(ctx)
+		}
+	}
+}
+
+// Status 获取各地址库的同步状态
+func (s *ScamFeedSyncer) Status() []ScamFeedStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]ScamFeedStatus, 0, len(s.feeds))
+	for _, feed := range s.feeds {
+		statuses = append(statuses, *s.status[feed.Name])
+	}
+	return statuses
+}
+
+// syncAll 依次同步全部地址库
+func (s *ScamFeedSyncer) syncAll(ctx context.Context) {
+	for _, feed := range s.feeds {
+		if ctx.Err() != nil {
+			return
+		}
+		s.sync(ctx, feed)
+	}
+}
+
+// sync 下载并解析地址库，替换该库在风险检测器中的标记
+func (s *ScamFeedSyncer) sync(ctx context.Context, feed config.ScamFeedConfig) {
+	addresses, err := s.fetch(ctx, feed)
+	if err != nil {
+		logger.Warnf("Failed to sync scam feed %s: %v", feed.Name, err)
+		s.metricsManager.RecordScamFeedSync(feed.Name, "error")
+		s.mu.Lock()
+		s.status[feed.Name].LastError = err.Error()
+		s.mu.Unlock()
+		return
+	}
+
+	tagged := s.detector.ReplaceSuspiciousContracts(SuspiciousTag{
+		Source:   feed.Name,
+		Category: feed.Category,
+		Networks: feed.Networks,
+	}, addresses)
+	s.metricsManager.RecordScamFeedSync(feed.Name, "ok")
+	s.metricsManager.SetScamFeedAddresses(feed.Name, tagged)
+	logger.Infof("Synced scam feed %s: %d addresses tagged", feed.Name, tagged)
+
+	now := time.Now()
+	s.mu.Lock()
+	s.status[feed.Name] = &ScamFeedStatus{Name: feed.Name, Addresses: tagged, LastSync: &now}
+	s.mu.Unlock()
+}
+
+// fetch 下载地址库并解析出其中的EVM地址，无效地址被忽略
+func (s *ScamFeedSyncer) fetch(ctx context.Context, feed config.ScamFeedConfig) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feed.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range feed.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("feed returned status %d", resp.StatusCode)
+	}
+
+	body := io.LimitReader(resp.Body, maxScamFeedSize)
+	var entries []string
+	if feed.Format == "json" {
+		entries, err = parseJSONScamFeed(body, feed.Field)
+	} else {
+		entries, err = parseTextScamFeed(body)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if common.IsHexAddress(entry) {
+			addresses = append(addresses, entry)
+		}
+	}
+	if len(entries) > 0 && len(addresses) == 0 {
+		return nil, fmt.Errorf("feed contains no valid addresses")
+	}
+	return addresses, nil
+}
+
+// parseTextScamFeed 每行一个地址，忽略空行及#开头的注释，CSV取第一列
+func parseTextScamFeed(r io.Reader) ([]string, error) {
+	var entries []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.IndexAny(line, ", \t"); i >= 0 {
+			line = line[:i]
+		}
+		entries = append(entries, line)
+	}
+	return entries, scanner.Err()
+}
+
+// parseJSONScamFeed 地址字符串数组，或对象数组中field字段（默认address）的地址
+func parseJSONScamFeed(r io.Reader, field string) ([]string, error) {
+	if field == "" {
+		field = "address"
+	}
+
+	var items []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, fmt.Errorf("invalid json feed: %w", err)
+	}
+	entries := make([]string, 0, len(items))
+	for _, item := range items {
+		var address string
+		if err := json.Unmarshal(item, &address); err == nil {
+			entries = append(entries, address)
+			continue
+		}
+		var object map[string]interface{}
+		if err := json.Unmarshal(item, &object); err != nil {
+			continue
+		}
+		if address, ok := object[field].(string); ok {
+			entries = append(entries, address)
+		}
+	}
+	return entries, nil
+}
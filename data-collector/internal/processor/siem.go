@@ -0,0 +1,10 @@
+package processor
+
+import (
+	"web3-data-collector/internal/siem"
+)
+
+// SIEMForwarder 获取SIEM告警转发，未启用时为nil
+func (dp *DataProcessor) SIEMForwarder() *siem.Forwarder {
+	return dp.siemForwarder
+}
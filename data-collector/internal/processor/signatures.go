@@ -0,0 +1,305 @@
+package processor
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/models"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// methodSignaturesKey 通过API添加的函数签名在Redis中的哈希，字段为文本签名，值为models.MethodSignature的JSON
+const methodSignaturesKey = "method_signatures"
+
+// signatureCacheTTL API添加的签名的本地缓存时间，其他实例的变更最迟在该时间后生效
+const signatureCacheTTL = 30 * time.Second
+
+// signaturePattern 规范文本签名，如 transfer(address,uint256)、fill((address,uint256)[],bytes)
+var signaturePattern = regexp.MustCompile(`[A-Za-z_$][A-Za-z0-9_$]*\([A-Za-z0-9_$,()\[\]]*\)`)
+
+// builtinSignatures 内置的常用函数签名，未配置种子文件时也能解析
+var builtinSignatures = []string{
+	"transfer(address,uint256)",
+	"transferFrom(address,address,uint256)",
+	"approve(address,uint256)",
+	"increaseAllowance(address,uint256)",
+	"permit(address,address,uint256,uint256,uint8,bytes32,bytes32)",
+	"setApprovalForAll(address,bool)",
+	"safeTransferFrom(address,address,uint256)",
+	"safeTransferFrom(address,address,uint256,bytes)",
+	"safeTransferFrom(address,address,uint256,uint256,bytes)",
+	"safeBatchTransferFrom(address,address,uint256[],uint256[],bytes)",
+	"deposit()",
+	"withdraw(uint256)",
+	"multicall(bytes[])",
+	"multicall(uint256,bytes[])",
+	"execute(bytes,bytes[],uint256)",
+	"swapExactETHForTokens(uint256,address[],address,uint256)",
+	"swapExactETHForTokensSupportingFeeOnTransferTokens(uint256,address[],address,uint256)",
+	"swapETHForExactTokens(uint256,address[],address,uint256)",
+	"swapExactTokensForETH(uint256,uint256,address[],address,uint256)",
+	"swapExactTokensForETHSupportingFeeOnTransferTokens(uint256,uint256,address[],address,uint256)",
+	"swapTokensForExactETH(uint256,uint256,address[],address,uint256)",
+	"swapExactTokensForTokens(uint256,uint256,address[],address,uint256)",
+	"swapExactTokensForTokensSupportingFeeOnTransferTokens(uint256,uint256,address[],address,uint256)",
+	"swapTokensForExactTokens(uint256,uint256,address[],address,uint256)",
+	"exactInputSingle((address,address,uint24,address,uint256,uint256,uint256,uint160))",
+	"exactInput((bytes,address,uint256,uint256,uint256))",
+	"flashLoan(address,address[],uint256[],uint256[],address,bytes,uint16)",
+}
+
+// SignatureRegistry 4字节函数签名库，用于将调用数据的选择器解析为函数名。
+// 内置签名及种子文件在启动时加载，API添加的签名保存在Redis
+type SignatureRegistry struct {
+	client   *database.RedisClient
+	static   map[string][]models.MethodSignature // 选择器 -> 内置及种子文件签名
+	custom   map[string][]models.MethodSignature // 选择器 -> API添加的签名
+	loadedAt time.Time
+	mu       sync.Mutex
+}
+
+// NewSignatureRegistry 根据配置创建函数签名库，未启用时返回nil
+func NewSignatureRegistry(cfg config.MethodSignatureConfig, redisClient *database.RedisClient) (*SignatureRegistry, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	r := &SignatureRegistry{
+		client: redisClient,
+		static: make(map[string][]models.MethodSignature),
+	}
+	for _, signature := range builtinSignatures {
+		ms, err := NewMethodSignature(signature, models.SignatureSourceBuiltin)
+		if err != nil {
+			return nil, err
+		}
+		r.addStatic(ms)
+	}
+	for _, path := range cfg.SeedFiles {
+		count, err := r.loadSeedFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load method signatures from %s: %w", path, err)
+		}
+		logger.Infof("Loaded %d method signatures from %s", count, path)
+	}
+	return r, nil
+}
+
+// NewMethodSignature 校验文本签名并计算选择器
+func NewMethodSignature(signature, source string) (*models.MethodSignature, error) {
+	signature = strings.TrimSpace(signature)
+	if match := signaturePattern.FindString(signature); match == "" || match != signature {
+		return nil, fmt.Errorf("invalid method signature %q", signature)
+	}
+
+	return &models.MethodSignature{
+		Selector:  "0x" + hex.EncodeToString(crypto.Keccak256([]byte(signature))[:4]),
+		Signature: signature,
+		Name:      signature[:strings.Index(signature, "(")],
+		Source:    source,
+	}, nil
+}
+
+// loadSeedFile 从openchain/4byte导出文件（JSON、CSV或每行一个签名）中提取文本签名，
+// 选择器由签名重新计算，不依赖文件中的选择器列
+func (r *SignatureRegistry) loadSeedFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, signature := range signaturePattern.FindAllString(string(data), -1) {
+		ms, err := NewMethodSignature(signature, models.SignatureSourceSeed)
+		if err != nil {
+			continue
+		}
+		if r.addStatic(ms) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// addStatic 添加内置或种子文件签名，已存在时返回false
+func (r *SignatureRegistry) addStatic(ms *models.MethodSignature) bool {
+	for _, existing := range r.static[ms.Selector] {
+		if existing.Signature == ms.Signature {
+			return false
+		}
+	}
+	r.static[ms.Selector] = append(r.static[ms.Selector], *ms)
+	return true
+}
+
+// Lookup 获取选择器对应的全部签名，API添加的签名在前，其后依次为内置及种子文件签名
+func (r *SignatureRegistry) Lookup(selector string) ([]models.MethodSignature, error) {
+	normalized, ok := normalizeSelector(selector)
+	if !ok {
+		return nil, fmt.Errorf("invalid selector %q", selector)
+	}
+
+	custom, err := r.currentCustom()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.MethodSignature, 0, len(custom[normalized])+len(r.static[normalized]))
+	result = append(result, custom[normalized]...)
+	for _, ms := range r.static[normalized] {
+		if !containsSignature(result, ms.Signature) {
+			result = append(result, ms)
+		}
+	}
+	return result, nil
+}
+
+// MethodName 解析调用数据对应的函数名，无法解析时返回空字符串。
+// 选择器冲突时取优先级最高的签名；Redis不可用时只使用内置及种子文件签名
+func (r *SignatureRegistry) MethodName(inputData string) string {
+	if len(inputData) < 10 {
+		return ""
+	}
+	selector, ok := normalizeSelector(inputData[:10])
+	if !ok {
+		return ""
+	}
+
+	custom, err := r.currentCustom()
+	if err != nil {
+		logger.Warnf("Failed to load method signatures: %v", err)
+	}
+	if candidates := custom[selector]; len(candidates) > 0 {
+		return candidates[0].Name
+	}
+	if candidates := r.static[selector]; len(candidates) > 0 {
+		return candidates[0].Name
+	}
+	return ""
+}
+
+// List 获取通过API添加的签名
+func (r *SignatureRegistry) List() ([]models.MethodSignature, error) {
+	values, err := r.client.HGetAll(methodSignaturesKey)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.MethodSignature, 0, len(values))
+	for signature, value := range values {
+		var ms models.MethodSignature
+		if err := json.Unmarshal([]byte(value), &ms); err != nil {
+			logger.Warnf("Skipping invalid method signature %s: %v", signature, err)
+			continue
+		}
+		result = append(result, ms)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].AddedAt.Before(result[j].AddedAt)
+	})
+	return result, nil
+}
+
+// Add 校验并保存签名，已存在时覆盖添加人及时间
+func (r *SignatureRegistry) Add(signature, addedBy string) (*models.MethodSignature, error) {
+	ms, err := NewMethodSignature(signature, models.SignatureSourceAPI)
+	if err != nil {
+		return nil, err
+	}
+	ms.AddedBy = addedBy
+	ms.AddedAt = time.Now()
+
+	data, err := json.Marshal(ms)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.client.HSet(methodSignaturesKey, ms.Signature, string(data)); err != nil {
+		return nil, err
+	}
+	r.invalidate()
+	return ms, nil
+}
+
+// Delete 删除通过API添加的签名，内置及种子文件签名不可删除
+func (r *SignatureRegistry) Delete(signature string) (bool, error) {
+	values, err := r.client.HGetAll(methodSignaturesKey)
+	if err != nil {
+		return false, err
+	}
+	if _, exists := values[signature]; !exists {
+		return false, nil
+	}
+	if err := r.client.HDel(methodSignaturesKey, signature); err != nil {
+		return false, err
+	}
+	r.invalidate()
+	return true, nil
+}
+
+// currentCustom 获取API添加的签名索引，缓存过期时从Redis重新加载，加载失败时返回上次的索引
+func (r *SignatureRegistry) currentCustom() (map[string][]models.MethodSignature, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.custom != nil && time.Since(r.loadedAt) <= signatureCacheTTL {
+		return r.custom, nil
+	}
+
+	signatures, err := r.List()
+	if err != nil {
+		return r.custom, err
+	}
+
+	custom := make(map[string][]models.MethodSignature)
+	for _, ms := range signatures {
+		custom[ms.Selector] = append(custom[ms.Selector], ms)
+	}
+	r.custom = custom
+	r.loadedAt = time.Now()
+	return custom, nil
+}
+
+func (r *SignatureRegistry) invalidate() {
+	r.mu.Lock()
+	r.custom = nil
+	r.mu.Unlock()
+}
+
+// normalizeSelector 规范化为小写带0x前缀的4字节选择器
+func normalizeSelector(selector string) (string, bool) {
+	selector = strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(selector, "0x"), "0X"))
+	if len(selector) != 8 {
+		return "", false
+	}
+	if _, err := hex.DecodeString(selector); err != nil {
+		return "", false
+	}
+	return "0x" + selector, true
+}
+
+func containsSignature(signatures []models.MethodSignature, signature string) bool {
+	for _, ms := range signatures {
+		if ms.Signature == signature {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeMethod 根据函数签名库解析交易调用的函数名
+func (dp *DataProcessor) decodeMethod(tx *models.Transaction) {
+	if dp.signatures == nil || tx.MethodName != "" {
+		return
+	}
+	tx.MethodName = dp.signatures.MethodName(tx.InputData)
+}
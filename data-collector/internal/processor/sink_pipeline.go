@@ -0,0 +1,518 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/faults"
+	"web3-data-collector/internal/metrics"
+	"web3-data-collector/internal/models"
+	"web3-data-collector/internal/publisher"
+)
+
+// 数据输出错误策略
+const (
+	SinkErrorPolicyContinue = "continue" // 记录错误后继续处理
+	SinkErrorPolicyFail     = "fail"     // 返回错误，中断当前数据的处理
+)
+
+// Sink 数据输出端
+type Sink interface {
+	Name() string
+	PublishBlock(block *models.Block) error
+	PublishTransaction(tx *models.Transaction) error
+	PublishAlert(alert *models.RiskAlert) error
+	PublishEvent(event *models.Event) error
+	PublishHeader(header *models.BlockHeader) error
+	PublishGasStats(stats *models.GasStats) error
+	PublishWithdrawal(withdrawal *models.Withdrawal) error
+	PublishBridgeTransfer(transfer *models.BridgeTransfer) error
+	PublishEnrichedBlock(enriched *models.EnrichedBlock) error
+}
+
+// lineProtocolWriter 支持直接写入行协议的输出端，用于重放写入失败的InfluxDB批次
+type lineProtocolWriter interface {
+	WriteLineProtocol(batch string) error
+}
+
+// BlockCommitter 支持按区块提交交易消息的输出端
+type BlockCommitter interface {
+	BeginBlock(network string, number uint64, committed bool)
+	CommitBlock(network string, number uint64) error
+	AbortBlock(network string, number uint64)
+}
+
+// QueueReporter 可报告待发送数量的输出端
+type QueueReporter interface {
+	QueueDepth() int64
+}
+
+// HealthChecker 可检查后端连通性的输出端
+type HealthChecker interface {
+	Ping(ctx context.Context) error
+}
+
+// SinkStageName 输出端在流水线中的阶段名称
+func SinkStageName(sink string) string {
+	return "sink_" + sink
+}
+
+// sinkEntry 带错误策略的输出端
+type sinkEntry struct {
+	sink         Sink
+	onError      string
+	maxRetries   int
+	retryBackoff time.Duration
+}
+
+// SinkPipeline 按配置扇出到多个输出端
+type SinkPipeline struct {
+	sinks          []*sinkEntry
+	deadLetters    DeadLetterQueue
+	stats          *PipelineStats
+	metricsManager *metrics.Manager
+	alertsOnly     bool             // 告警专用部署，只发布告警
+	aggregator     *AlertAggregator // 告警聚合及限速，未启用时为nil
+	alerts         *AlertStore      // 告警存储，未启用时为nil
+	stopChan       chan struct{}    // 关闭后不再等待重试
+	stopOnce       sync.Once
+}
+
+// DefaultSinkConfigs 未配置输出端时的默认列表，与原有硬编码行为一致
+func DefaultSinkConfigs() []config.SinkConfig {
+	return []config.SinkConfig{
+		{Type: "kafka", Enabled: true, OnError: SinkErrorPolicyContinue},
+		{Type: "stream", Enabled: true, OnError: SinkErrorPolicyContinue},
+		{Type: "influxdb", Enabled: true, OnError: SinkErrorPolicyContinue},
+		{Type: "redis", Enabled: true, OnError: SinkErrorPolicyContinue},
+	}
+}
+
+// withSink 启用可选的输出端时，未在输出端列表中配置则按默认策略追加
+func withSink(sinkConfigs []config.SinkConfig, sinkType string) []config.SinkConfig {
+	if len(sinkConfigs) == 0 {
+		sinkConfigs = DefaultSinkConfigs()
+	}
+	for _, sinkCfg := range sinkConfigs {
+		if sinkCfg.Type == sinkType {
+			return sinkConfigs
+		}
+	}
+	return append(sinkConfigs, config.SinkConfig{Type: sinkType, Enabled: true, OnError: SinkErrorPolicyContinue})
+}
+
+// NewSinkPipeline 根据配置创建输出管道，available为可用的输出端（按名称索引）
+func NewSinkPipeline(sinkConfigs []config.SinkConfig, available map[string]Sink, metricsManager *metrics.Manager) (*SinkPipeline, error) {
+	if len(sinkConfigs) == 0 {
+		sinkConfigs = DefaultSinkConfigs()
+	}
+
+	pipeline := &SinkPipeline{
+		metricsManager: metricsManager,
+		stopChan:       make(chan struct{}),
+	}
+
+	for _, sinkCfg := range sinkConfigs {
+		if !sinkCfg.Enabled {
+			logger.Infof("Sink %s is disabled, skipping", sinkCfg.Type)
+			continue
+		}
+
+		sink, exists := available[sinkCfg.Type]
+		if !exists || sink == nil {
+			return nil, fmt.Errorf("unknown sink type: %s", sinkCfg.Type)
+		}
+
+		onError := sinkCfg.OnError
+		if onError == "" {
+			onError = SinkErrorPolicyContinue
+		}
+		if onError != SinkErrorPolicyContinue && onError != SinkErrorPolicyFail {
+			return nil, fmt.Errorf("invalid on_error policy %q for sink %s", onError, sinkCfg.Type)
+		}
+
+		retryBackoff := 100 * time.Millisecond
+		if sinkCfg.RetryBackoff != "" {
+			parsed, err := time.ParseDuration(sinkCfg.RetryBackoff)
+			if err != nil {
+				return nil, fmt.Errorf("invalid retry_backoff for sink %s: %w", sinkCfg.Type, err)
+			}
+			retryBackoff = parsed
+		}
+
+		pipeline.sinks = append(pipeline.sinks, &sinkEntry{
+			sink:         sink,
+			onError:      onError,
+			maxRetries:   sinkCfg.MaxRetries,
+			retryBackoff: retryBackoff,
+		})
+		logger.Infof("Enabled sink %s (on_error: %s, max_retries: %d)", sinkCfg.Type, onError, sinkCfg.MaxRetries)
+	}
+
+	return pipeline, nil
+}
+
+// PublishBlock 向所有输出端发布区块
+func (sp *SinkPipeline) PublishBlock(block *models.Block) error {
+	return sp.publish("block", block.Network, block, func(sink Sink) error {
+		return sink.PublishBlock(block)
+	})
+}
+
+// PublishTransaction 向所有输出端发布交易
+func (sp *SinkPipeline) PublishTransaction(tx *models.Transaction) error {
+	return sp.publish("transaction", tx.Network, tx, func(sink Sink) error {
+		return sink.PublishTransaction(tx)
+	})
+}
+
+// PublishAlert 向所有输出端发布告警，启用告警聚合时按聚合及限速规则发布；
+// 地址被静默时告警只保存不发布
+func (sp *SinkPipeline) PublishAlert(alert *models.RiskAlert) error {
+	if sp.alerts != nil {
+		snoozed, err := sp.alerts.Snoozed(alert)
+		if err != nil {
+			faults.Warn(err, alert.Network, 0, fmt.Sprintf("Failed to check alert snoozes for %s", alert.ID))
+			sp.metricsManager.RecordError(err, alert.Network, 0)
+		} else if snoozed {
+			sp.metricsManager.RecordSuppressedAlert(alert.Network, alert.Type, "snoozed")
+			return nil
+		}
+	}
+	if sp.aggregator != nil {
+		return sp.aggregator.Publish(alert)
+	}
+	return sp.publishAlert(alert)
+}
+
+// publishAlert 向所有输出端发布告警，发布前计入告警数并保存告警
+func (sp *SinkPipeline) publishAlert(alert *models.RiskAlert) error {
+	sp.metricsManager.IncrementAlerts(alert.Network, alert.Level, alert.Type)
+	if sp.alerts != nil {
+		if err := sp.alerts.Record(alert); err != nil {
+			faults.Warn(err, alert.Network, 0, fmt.Sprintf("Failed to store alert %s", alert.ID))
+			sp.metricsManager.RecordError(err, alert.Network, 0)
+		}
+	}
+	return sp.publish("alert", alert.Network, alert, func(sink Sink) error {
+		return sink.PublishAlert(alert)
+	})
+}
+
+// PublishEvent 向所有输出端发布合约事件
+func (sp *SinkPipeline) PublishEvent(event *models.Event) error {
+	return sp.publish("event", event.Network, event, func(sink Sink) error {
+		return sink.PublishEvent(event)
+	})
+}
+
+// PublishHeader 向所有输出端发布区块头摘要
+func (sp *SinkPipeline) PublishHeader(header *models.BlockHeader) error {
+	return sp.publish("header", header.Network, header, func(sink Sink) error {
+		return sink.PublishHeader(header)
+	})
+}
+
+// PublishGasStats 向所有输出端发布区块gas统计
+func (sp *SinkPipeline) PublishGasStats(stats *models.GasStats) error {
+	return sp.publish("gas_stats", stats.Network, stats, func(sink Sink) error {
+		return sink.PublishGasStats(stats)
+	})
+}
+
+// PublishWithdrawal 向所有输出端发布信标链提款
+func (sp *SinkPipeline) PublishWithdrawal(withdrawal *models.Withdrawal) error {
+	return sp.publish("withdrawal", withdrawal.Network, withdrawal, func(sink Sink) error {
+		return sink.PublishWithdrawal(withdrawal)
+	})
+}
+
+// PublishBridgeTransfer 向所有输出端发布跨链桥存入或到账
+func (sp *SinkPipeline) PublishBridgeTransfer(transfer *models.BridgeTransfer) error {
+	return sp.publish("bridge_transfer", transfer.Network, transfer, func(sink Sink) error {
+		return sink.PublishBridgeTransfer(transfer)
+	})
+}
+
+// PublishEnrichedBlock 向所有输出端发布完整区块
+func (sp *SinkPipeline) PublishEnrichedBlock(enriched *models.EnrichedBlock) error {
+	return sp.publish("enriched_block", enriched.Block.Network, enriched, func(sink Sink) error {
+		return sink.PublishEnrichedBlock(enriched)
+	})
+}
+
+// BeginBlock 通知按区块提交的输出端开始缓冲区块交易
+func (sp *SinkPipeline) BeginBlock(network string, number uint64, committed bool) {
+	for _, entry := range sp.sinks {
+		if committer, ok := entry.sink.(BlockCommitter); ok {
+			committer.BeginBlock(network, number, committed)
+		}
+	}
+}
+
+// CommitBlock 提交区块缓冲的交易，任一输出端失败时丢弃其余缓冲并返回错误，由区块重试重新写出
+func (sp *SinkPipeline) CommitBlock(network string, number uint64) error {
+	var commitErr error
+	for _, entry := range sp.sinks {
+		committer, ok := entry.sink.(BlockCommitter)
+		if !ok {
+			continue
+		}
+		if commitErr != nil {
+			committer.AbortBlock(network, number)
+			continue
+		}
+		if err := committer.CommitBlock(network, number); err != nil {
+			sp.metricsManager.IncrementError(network, fmt.Sprintf("sink_%s_commit_error", entry.sink.Name()))
+			commitErr = fmt.Errorf("sink %s: %w", entry.sink.Name(), err)
+		}
+	}
+	return commitErr
+}
+
+// AbortBlock 丢弃区块缓冲的交易
+func (sp *SinkPipeline) AbortBlock(network string, number uint64) {
+	for _, entry := range sp.sinks {
+		if committer, ok := entry.sink.(BlockCommitter); ok {
+			committer.AbortBlock(network, number)
+		}
+	}
+}
+
+// SinkNames 获取已启用的输出端名称
+func (sp *SinkPipeline) SinkNames() []string {
+	names := make([]string, 0, len(sp.sinks))
+	for _, entry := range sp.sinks {
+		names = append(names, entry.sink.Name())
+	}
+	return names
+}
+
+// Replay 将死信重新发布到原输出端（不重试，也不再次写入死信队列）
+func (sp *SinkPipeline) Replay(entry *models.DeadLetter) error {
+	var target Sink
+	for _, e := range sp.sinks {
+		if e.sink.Name() == entry.Sink {
+			target = e.sink
+			break
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("sink %s is not enabled", entry.Sink)
+	}
+
+	var err error
+	switch entry.Kind {
+	case "block":
+		var block models.Block
+		if err = json.Unmarshal(entry.Payload, &block); err == nil {
+			err = target.PublishBlock(&block)
+		}
+	case "transaction":
+		var tx models.Transaction
+		if err = json.Unmarshal(entry.Payload, &tx); err == nil {
+			err = target.PublishTransaction(&tx)
+		}
+	case "alert":
+		var alert models.RiskAlert
+		if err = json.Unmarshal(entry.Payload, &alert); err == nil {
+			err = target.PublishAlert(&alert)
+		}
+	case "event":
+		var event models.Event
+		if err = json.Unmarshal(entry.Payload, &event); err == nil {
+			err = target.PublishEvent(&event)
+		}
+	case "header":
+		var header models.BlockHeader
+		if err = json.Unmarshal(entry.Payload, &header); err == nil {
+			err = target.PublishHeader(&header)
+		}
+	case "gas_stats":
+		var stats models.GasStats
+		if err = json.Unmarshal(entry.Payload, &stats); err == nil {
+			err = target.PublishGasStats(&stats)
+		}
+	case "withdrawal":
+		var withdrawal models.Withdrawal
+		if err = json.Unmarshal(entry.Payload, &withdrawal); err == nil {
+			err = target.PublishWithdrawal(&withdrawal)
+		}
+	case "bridge_transfer":
+		var transfer models.BridgeTransfer
+		if err = json.Unmarshal(entry.Payload, &transfer); err == nil {
+			err = target.PublishBridgeTransfer(&transfer)
+		}
+	case "enriched_block":
+		var enriched models.EnrichedBlock
+		if err = json.Unmarshal(entry.Payload, &enriched); err == nil {
+			err = target.PublishEnrichedBlock(&enriched)
+		}
+	case "line_protocol":
+		writer, ok := target.(lineProtocolWriter)
+		if !ok {
+			return fmt.Errorf("sink %s does not accept line protocol", entry.Sink)
+		}
+		var batch string
+		if err = json.Unmarshal(entry.Payload, &batch); err == nil {
+			err = writer.WriteLineProtocol(batch)
+		}
+	default:
+		return fmt.Errorf("unknown dead letter kind: %s", entry.Kind)
+	}
+
+	return err
+}
+
+// deadLetter 将重试耗尽的数据写入死信队列
+func (sp *SinkPipeline) deadLetter(sinkName, kind, network string, payload interface{}, attempts int, publishErr error) {
+	if sp.deadLetters == nil {
+		return
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		logger.Errorf("Failed to marshal %s for dead letter queue: %v", kind, err)
+		return
+	}
+
+	entry := &models.DeadLetter{
+		Sink:     sinkName,
+		Kind:     kind,
+		Network:  network,
+		Payload:  data,
+		Error:    publishErr.Error(),
+		Attempts: attempts,
+		FailedAt: time.Now(),
+	}
+	if err := sp.deadLetters.Push(entry); err != nil {
+		logger.Errorf("Failed to write %s to dead letter queue: %v", kind, err)
+		return
+	}
+
+	sp.metricsManager.RecordDeadLetter(sinkName, kind)
+}
+
+// HandleKafkaDelivery 记录Kafka消息的写出延迟，异步写出失败的消息写入死信队列；
+// 同步写出的错误已返回给publish，由其按错误策略处理
+func (sp *SinkPipeline) HandleKafkaDelivery(report *publisher.DeliveryReport) {
+	mode := "sync"
+	if report.Async {
+		mode = "async"
+	}
+	sp.metricsManager.RecordKafkaDelivery(report.Topic, mode, report.Latency, report.Err == nil)
+	if report.Err == nil || !report.Async {
+		return
+	}
+
+	sinkErr := &faults.SinkError{Sink: "kafka", Kind: report.Kind, Network: report.Network, Block: payloadBlock(report.Payload), Err: report.Err}
+	faults.Log(sinkErr, report.Network, 0, fmt.Sprintf("Failed to deliver to Kafka topic %s", report.Topic))
+	sp.metricsManager.IncrementError(report.Network, fmt.Sprintf("sink_kafka_%s_error", report.Kind))
+	sp.metricsManager.RecordError(sinkErr, report.Network, 0)
+	sp.deadLetter("kafka", report.Kind, report.Network, report.Payload, 1, report.Err)
+}
+
+// publish 依次调用各输出端，按各自的错误策略处理失败
+func (sp *SinkPipeline) publish(kind, network string, payload interface{}, fn func(sink Sink) error) error {
+	if sp.alertsOnly && kind != "alert" {
+		return nil
+	}
+
+	var failErr error
+
+	for _, entry := range sp.sinks {
+		name := entry.sink.Name()
+		startTime := time.Now()
+
+		err := fn(entry.sink)
+		attempts := 1
+		for err != nil && attempts <= entry.maxRetries {
+			if !sp.waitRetry(entry.retryBackoff * time.Duration(attempts)) {
+				break
+			}
+			logger.Debugf("Retrying %s publish to sink %s (attempt %d/%d)", kind, name, attempts, entry.maxRetries)
+			err = fn(entry.sink)
+			attempts++
+		}
+
+		sp.metricsManager.RecordSinkPublish(name, kind, time.Since(startTime), err == nil)
+		if sp.stats != nil {
+			sp.stats.Record(network, SinkStageName(name), time.Since(startTime), err)
+		}
+		if err == nil {
+			continue
+		}
+
+		sinkErr := &faults.SinkError{Sink: name, Kind: kind, Network: network, Block: payloadBlock(payload), Err: err}
+		faults.Log(sinkErr, network, 0, "Failed to publish")
+		sp.metricsManager.IncrementError(network, fmt.Sprintf("sink_%s_%s_error", name, kind))
+		sp.metricsManager.RecordError(sinkErr, network, 0)
+		sp.deadLetter(name, kind, network, payload, attempts, err)
+
+		if entry.onError == SinkErrorPolicyFail && failErr == nil {
+			failErr = sinkErr
+		}
+	}
+
+	return failErr
+}
+
+// waitRetry 等待重试间隔，管道关闭时立即返回false，失败的数据直接写入死信队列
+func (sp *SinkPipeline) waitRetry(backoff time.Duration) bool {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-sp.stopChan:
+		return false
+	}
+}
+
+// Close 停止等待中及之后的重试，关闭时调用，避免故障的输出端拖慢处理中区块的排空
+func (sp *SinkPipeline) Close() {
+	sp.stopOnce.Do(func() { close(sp.stopChan) })
+}
+
+// HandleInfluxWriteFailure 记录InfluxDB异步批量写入失败，放弃的批次以行协议写入死信队列；
+// 批次可能包含多个网络的数据，不区分网络
+func (sp *SinkPipeline) HandleInfluxWriteFailure(failure *database.InfluxWriteFailure) {
+	if !failure.Final {
+		sp.metricsManager.RecordInfluxWriteFailure("retry")
+		return
+	}
+	sp.metricsManager.RecordInfluxWriteFailure("dropped")
+
+	sinkErr := &faults.SinkError{Sink: "influxdb", Kind: "line_protocol", Err: failure.Err}
+	sp.metricsManager.RecordError(sinkErr, "", 0)
+	sp.deadLetter("influxdb", "line_protocol", "", failure.Batch, failure.Attempts, failure.Err)
+}
+
+// payloadBlock 发布数据所属的区块号，用作错误上下文，无法确定时为0
+func payloadBlock(payload interface{}) uint64 {
+	switch p := payload.(type) {
+	case *models.Block:
+		return p.Number
+	case *models.Transaction:
+		return p.BlockNumber
+	case *models.Event:
+		return p.BlockNumber
+	case *models.BlockHeader:
+		return p.Number
+	case *models.GasStats:
+		return p.BlockNumber
+	case *models.Withdrawal:
+		return p.BlockNumber
+	case *models.EnrichedBlock:
+		if p.Block != nil {
+			return p.Block.Number
+		}
+	}
+	return 0
+}
@@ -0,0 +1,886 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/models"
+	"web3-data-collector/internal/publisher"
+	"web3-data-collector/internal/siem"
+	"web3-data-collector/internal/stream"
+	"web3-data-collector/internal/warehouse"
+	"web3-data-collector/internal/webhook"
+)
+
+// kafkaSink Kafka输出端
+type kafkaSink struct {
+	publisher *publisher.KafkaPublisher
+}
+
+// NewKafkaSink 创建Kafka输出端
+func NewKafkaSink(publisher *publisher.KafkaPublisher) Sink {
+	return &kafkaSink{publisher: publisher}
+}
+
+func (ks *kafkaSink) Name() string { return "kafka" }
+
+func (ks *kafkaSink) Ping(ctx context.Context) error { return ks.publisher.Ping(ctx) }
+
+func (ks *kafkaSink) PublishBlock(block *models.Block) error {
+	return ks.publisher.PublishBlock(block)
+}
+
+func (ks *kafkaSink) PublishTransaction(tx *models.Transaction) error {
+	return ks.publisher.PublishTransaction(tx)
+}
+
+func (ks *kafkaSink) PublishAlert(alert *models.RiskAlert) error {
+	return ks.publisher.PublishAlert(alert)
+}
+
+func (ks *kafkaSink) PublishEvent(event *models.Event) error {
+	return ks.publisher.PublishEvent(event)
+}
+
+func (ks *kafkaSink) PublishHeader(header *models.BlockHeader) error {
+	return ks.publisher.PublishHeader(header)
+}
+
+func (ks *kafkaSink) PublishGasStats(stats *models.GasStats) error {
+	return ks.publisher.PublishGasStats(stats)
+}
+
+func (ks *kafkaSink) PublishWithdrawal(withdrawal *models.Withdrawal) error {
+	return ks.publisher.PublishWithdrawal(withdrawal)
+}
+
+func (ks *kafkaSink) PublishBridgeTransfer(transfer *models.BridgeTransfer) error {
+	return ks.publisher.PublishBridgeTransfer(transfer)
+}
+
+func (ks *kafkaSink) PublishEnrichedBlock(enriched *models.EnrichedBlock) error {
+	return ks.publisher.PublishEnrichedBlock(enriched)
+}
+
+func (ks *kafkaSink) BeginBlock(network string, number uint64, committed bool) {
+	ks.publisher.BeginBlock(network, number, committed)
+}
+
+func (ks *kafkaSink) CommitBlock(network string, number uint64) error {
+	return ks.publisher.CommitBlock(network, number)
+}
+
+func (ks *kafkaSink) AbortBlock(network string, number uint64) {
+	ks.publisher.AbortBlock(network, number)
+}
+
+// streamSink 实时订阅输出端
+type streamSink struct {
+	hub *stream.Hub
+}
+
+// NewStreamSink 创建实时订阅输出端
+func NewStreamSink(hub *stream.Hub) Sink {
+	return &streamSink{hub: hub}
+}
+
+func (ss *streamSink) Name() string { return "stream" }
+
+// QueueDepth 订阅者缓冲区中尚未读取的消息数
+func (ss *streamSink) QueueDepth() int64 { return ss.hub.QueueDepth() }
+
+func (ss *streamSink) PublishBlock(block *models.Block) error {
+	ss.hub.PublishBlock(block)
+	return nil
+}
+
+func (ss *streamSink) PublishTransaction(tx *models.Transaction) error {
+	ss.hub.PublishTransaction(tx)
+	return nil
+}
+
+func (ss *streamSink) PublishAlert(alert *models.RiskAlert) error {
+	ss.hub.PublishAlert(alert)
+	return nil
+}
+
+// PublishEvent 实时订阅暂不支持合约事件
+func (ss *streamSink) PublishEvent(event *models.Event) error {
+	return nil
+}
+
+// PublishHeader 实时订阅按完整区块推送，无需区块头摘要
+func (ss *streamSink) PublishHeader(header *models.BlockHeader) error {
+	return nil
+}
+
+// PublishGasStats 实时订阅暂不支持gas统计
+func (ss *streamSink) PublishGasStats(stats *models.GasStats) error {
+	return nil
+}
+
+// PublishWithdrawal 实时订阅暂不支持提款
+func (ss *streamSink) PublishWithdrawal(withdrawal *models.Withdrawal) error {
+	return nil
+}
+
+// PublishBridgeTransfer 实时订阅暂不支持跨链桥记录
+func (ss *streamSink) PublishBridgeTransfer(transfer *models.BridgeTransfer) error {
+	return nil
+}
+
+func (ss *streamSink) PublishEnrichedBlock(enriched *models.EnrichedBlock) error {
+	return nil
+}
+
+// influxSink InfluxDB时序指标输出端
+type influxSink struct {
+	client          *database.InfluxDBClient
+	currencies      *CurrencyRegistry
+	blocks          *fieldSelector
+	transactions    *fieldSelector
+	alerts          *fieldSelector
+	blockAggregates *fieldSelector
+	gasStats        *fieldSelector
+	withdrawals     *fieldSelector
+	bridgeTransfers *fieldSelector
+}
+
+// NewInfluxSink 创建InfluxDB输出端
+func NewInfluxSink(client *database.InfluxDBClient, currencies *CurrencyRegistry) Sink {
+	measurements := client.Measurements()
+
+	return &influxSink{
+		client:          client,
+		currencies:      currencies,
+		blocks:          newFieldSelector(measurements.Blocks),
+		transactions:    newFieldSelector(measurements.Transactions),
+		alerts:          newFieldSelector(measurements.Alerts),
+		blockAggregates: newFieldSelector(measurements.BlockAggregates),
+		gasStats:        newFieldSelector(measurements.GasStats),
+		withdrawals:     newFieldSelector(measurements.Withdrawals),
+		bridgeTransfers: newFieldSelector(measurements.BridgeTransfers),
+	}
+}
+
+func (is *influxSink) Name() string { return "influxdb" }
+
+func (is *influxSink) Ping(ctx context.Context) error { return is.client.Ping(ctx) }
+
+// QueueDepth 批量写入器中等待写出的批次数
+func (is *influxSink) QueueDepth() int64 { return is.client.QueueDepth() }
+
+// PublishBlock 存储区块指标及区块内交易汇总到InfluxDB
+func (is *influxSink) PublishBlock(block *models.Block) error {
+	tags := map[string]string{
+		"network": block.Network,
+		"miner":   block.Miner,
+	}
+
+	if is.blocks.enabled {
+		point := map[string]interface{}{
+			"number":     block.Number,
+			"tx_count":   block.TxCount,
+			"gas_used":   block.GasUsed,
+			"gas_limit":  block.GasLimit,
+			"size":       block.Size,
+			"difficulty": block.Difficulty.String(),
+		}
+
+		if block.BaseFeePerGas != nil {
+			point["base_fee"] = block.BaseFeePerGas.String()
+		}
+
+		if err := is.write("blocks", is.blocks, tags, point, block.Timestamp); err != nil {
+			return err
+		}
+	}
+
+	if is.blockAggregates.enabled {
+		point := is.aggregateTransactions(block)
+		if err := is.write("block_aggregates", is.blockAggregates, tags, point, block.Timestamp); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PublishTransaction 存储交易指标到InfluxDB
+func (is *influxSink) PublishTransaction(tx *models.Transaction) error {
+	if !is.transactions.enabled {
+		return nil
+	}
+
+	point := map[string]interface{}{
+		"hash":             tx.Hash,
+		"block_number":     tx.BlockNumber,
+		"value":            tx.Value.String(),
+		"gas":              tx.Gas,
+		"gas_price":        tx.GasPrice.String(),
+		"gas_used":         tx.GasUsed,
+		"is_contract":      tx.IsContractCall,
+		"is_token":         tx.IsTokenTransfer,
+		"transaction_type": tx.TransactionType,
+		"input_size":       inputDataSize(tx.InputData),
+	}
+
+	// 按网络原生币精度换算后的金额，便于展示与聚合
+	valueNative, _ := is.currencies.Get(tx.Network).ToUnits(tx.Value).Float64()
+	point["value_native"] = valueNative
+	if tx.USDValue > 0 {
+		point["value_usd"] = tx.USDValue
+	}
+	if tx.SampleRate > 0 {
+		point["sample_rate"] = tx.SampleRate
+	}
+
+	if tx.MaxFeePerGas != nil {
+		point["max_fee_per_gas"] = tx.MaxFeePerGas.String()
+	}
+
+	if tx.MaxPriorityFeePerGas != nil {
+		point["max_priority_fee_per_gas"] = tx.MaxPriorityFeePerGas.String()
+	}
+
+	// L2交易的L1数据费用及总费用，需获取回执
+	if tx.L2 != nil && tx.L2.L1Fee != nil {
+		point["l1_fee"] = tx.L2.L1Fee.String()
+	}
+	if tx.L2 != nil && tx.L2.TotalFee != nil {
+		point["total_fee"] = tx.L2.TotalFee.String()
+	}
+
+	// 原始input数据体积较大，仅在显式配置时写入
+	if is.transactions.includes("input_data") && tx.InputData != "" {
+		point["input_data"] = tx.InputData
+	}
+
+	tags := map[string]string{
+		"network":      tx.Network,
+		"from_address": tx.FromAddress,
+		"to_address":   tx.ToAddress,
+	}
+
+	return is.write("transactions", is.transactions, tags, point, tx.Timestamp)
+}
+
+// PublishAlert 存储告警指标到InfluxDB
+func (is *influxSink) PublishAlert(alert *models.RiskAlert) error {
+	if !is.alerts.enabled {
+		return nil
+	}
+
+	point := map[string]interface{}{
+		"id":               alert.ID,
+		"title":            alert.Title,
+		"address":          alert.Address,
+		"risk_score":       alert.RiskScore,
+		"transaction_hash": alert.TransactionHash,
+	}
+
+	tags := map[string]string{
+		"network": alert.Network,
+		"level":   alert.Level,
+		"type":    alert.Type,
+	}
+
+	return is.write("alerts", is.alerts, tags, point, alert.Timestamp)
+}
+
+// PublishEvent 合约事件不写入时序库
+func (is *influxSink) PublishEvent(event *models.Event) error {
+	return nil
+}
+
+func (is *influxSink) PublishHeader(header *models.BlockHeader) error {
+	return nil
+}
+
+// PublishGasStats 存储区块gas统计，价格为wei
+func (is *influxSink) PublishGasStats(stats *models.GasStats) error {
+	if !is.gasStats.enabled {
+		return nil
+	}
+
+	point := map[string]interface{}{
+		"number":      stats.BlockNumber,
+		"gas_used":    stats.GasUsed,
+		"gas_limit":   stats.GasLimit,
+		"utilization": stats.Utilization,
+		"tx_count":    stats.TxCount,
+	}
+	if stats.BaseFeePerGas != nil {
+		point["base_fee"] = weiFloat(stats.BaseFeePerGas)
+		point["next_base_fee"] = weiFloat(stats.NextBaseFeePerGas)
+	}
+	if fees := stats.PriorityFees; fees != nil {
+		point["priority_fee_p10"] = weiFloat(fees.P10)
+		point["priority_fee_p25"] = weiFloat(fees.P25)
+		point["priority_fee_p50"] = weiFloat(fees.P50)
+		point["priority_fee_p75"] = weiFloat(fees.P75)
+		point["priority_fee_p90"] = weiFloat(fees.P90)
+	}
+
+	tags := map[string]string{
+		"network": stats.Network,
+	}
+
+	return is.write("gas_stats", is.gasStats, tags, point, stats.Timestamp)
+}
+
+// PublishWithdrawal 存储信标链提款，金额为wei
+func (is *influxSink) PublishWithdrawal(withdrawal *models.Withdrawal) error {
+	if !is.withdrawals.enabled {
+		return nil
+	}
+
+	point := map[string]interface{}{
+		"block_number":    withdrawal.BlockNumber,
+		"index":           withdrawal.Index,
+		"validator_index": withdrawal.ValidatorIndex,
+		"amount":          weiFloat(withdrawal.Amount),
+	}
+
+	tags := map[string]string{
+		"network": withdrawal.Network,
+		"address": withdrawal.Address,
+	}
+
+	return is.write("withdrawals", is.withdrawals, tags, point, withdrawal.Timestamp)
+}
+
+// PublishBridgeTransfer 存储跨链桥存入及到账，金额为源链代币最小单位
+func (is *influxSink) PublishBridgeTransfer(transfer *models.BridgeTransfer) error {
+	if !is.bridgeTransfers.enabled {
+		return nil
+	}
+
+	point := map[string]interface{}{
+		"block_number": transfer.BlockNumber,
+		"message_id":   transfer.MessageID,
+		"linked":       transfer.Linked,
+		"sender":       transfer.Sender,
+		"recipient":    transfer.Recipient,
+	}
+	if transfer.Amount != nil {
+		point["amount"] = weiFloat(transfer.Amount)
+	}
+
+	tags := map[string]string{
+		"network":           transfer.Network,
+		"protocol":          transfer.Protocol,
+		"direction":         transfer.Direction,
+		"source_chain":      transfer.SourceChain,
+		"destination_chain": transfer.DestinationChain,
+	}
+	if transfer.Token != "" {
+		tags["token"] = transfer.Token
+	}
+
+	return is.write("bridge_transfers", is.bridgeTransfers, tags, point, transfer.Timestamp)
+}
+
+// weiFloat 转为浮点数以便在时序库中聚合
+func weiFloat(value *big.Int) float64 {
+	f, _ := new(big.Float).SetInt(value).Float64()
+	return f
+}
+
+// PublishEnrichedBlock 记录区块从发现到处理完成的延迟
+func (is *influxSink) PublishEnrichedBlock(enriched *models.EnrichedBlock) error {
+	point := map[string]interface{}{
+		"number":      enriched.Block.Number,
+		"latency_ms":  enriched.LatencyMs,
+		"alert_count": len(enriched.Alerts),
+		"event_count": len(enriched.Events),
+	}
+
+	tags := map[string]string{
+		"network": enriched.Block.Network,
+	}
+
+	return is.client.WritePoint("block_processing", tags, point, enriched.ProcessedAt)
+}
+
+// aggregateTransactions 汇总区块内的交易指标
+func (is *influxSink) aggregateTransactions(block *models.Block) map[string]interface{} {
+	totalValue := big.NewInt(0)
+	totalGasPrice := big.NewInt(0)
+	var totalGasUsed, totalInputSize uint64
+	var contractCalls, tokenTransfers int
+
+	for i := range block.Transactions {
+		tx := &block.Transactions[i]
+		if tx.Value != nil {
+			totalValue.Add(totalValue, tx.Value)
+		}
+		if tx.GasPrice != nil {
+			totalGasPrice.Add(totalGasPrice, tx.GasPrice)
+		}
+		totalGasUsed += tx.GasUsed
+		totalInputSize += uint64(inputDataSize(tx.InputData))
+		if tx.IsContractCall {
+			contractCalls++
+		}
+		if tx.IsTokenTransfer {
+			tokenTransfers++
+		}
+	}
+
+	txCount := len(block.Transactions)
+	avgGasPrice := big.NewInt(0)
+	if txCount > 0 {
+		avgGasPrice.Div(totalGasPrice, big.NewInt(int64(txCount)))
+	}
+
+	totalValueNative, _ := is.currencies.Get(block.Network).ToUnits(totalValue).Float64()
+
+	return map[string]interface{}{
+		"number":               block.Number,
+		"tx_count":             txCount,
+		"total_value":          totalValue.String(),
+		"total_value_native":   totalValueNative,
+		"avg_gas_price":        avgGasPrice.String(),
+		"total_gas_used":       totalGasUsed,
+		"total_input_size":     totalInputSize,
+		"contract_call_count":  contractCalls,
+		"token_transfer_count": tokenTransfers,
+	}
+}
+
+// WriteLineProtocol 重放写入失败的批次
+func (is *influxSink) WriteLineProtocol(batch string) error {
+	return is.client.WriteLineProtocol(batch)
+}
+
+// write 按字段选择写入数据点，字段全部被过滤时跳过
+func (is *influxSink) write(measurement string, selector *fieldSelector, tags map[string]string, point map[string]interface{}, timestamp time.Time) error {
+	selector.apply(point)
+	if len(point) == 0 {
+		return nil
+	}
+
+	return is.client.WritePoint(measurement, tags, point, timestamp)
+}
+
+// fieldSelector measurement字段选择器
+type fieldSelector struct {
+	enabled bool
+	include map[string]bool
+	exclude map[string]bool
+}
+
+// newFieldSelector 根据配置创建字段选择器
+func newFieldSelector(cfg config.MeasurementConfig) *fieldSelector {
+	selector := &fieldSelector{
+		enabled: cfg.Enabled,
+		include: make(map[string]bool),
+		exclude: make(map[string]bool),
+	}
+
+	for _, field := range cfg.Fields {
+		selector.include[field] = true
+	}
+	for _, field := range cfg.ExcludeFields {
+		selector.exclude[field] = true
+	}
+
+	return selector
+}
+
+// includes 判断字段是否被显式选中
+func (fs *fieldSelector) includes(field string) bool {
+	return fs.include[field] && !fs.exclude[field]
+}
+
+// apply 移除未选中的字段
+func (fs *fieldSelector) apply(point map[string]interface{}) {
+	for field := range point {
+		if fs.exclude[field] || (len(fs.include) > 0 && !fs.include[field]) {
+			delete(point, field)
+		}
+	}
+}
+
+// inputDataSize 计算十六进制input数据的字节数
+func inputDataSize(inputData string) int {
+	return len(strings.TrimPrefix(inputData, "0x")) / 2
+}
+
+// siemSink SIEM告警转发输出端，只转发告警
+type siemSink struct {
+	forwarder *siem.Forwarder
+}
+
+// NewSIEMSink 创建SIEM输出端
+func NewSIEMSink(forwarder *siem.Forwarder) Sink {
+	return &siemSink{forwarder: forwarder}
+}
+
+func (ss *siemSink) Name() string { return "siem" }
+
+func (ss *siemSink) PublishBlock(block *models.Block) error {
+	return nil
+}
+
+func (ss *siemSink) PublishTransaction(tx *models.Transaction) error {
+	return nil
+}
+
+func (ss *siemSink) PublishAlert(alert *models.RiskAlert) error {
+	return ss.forwarder.Export(alert)
+}
+
+func (ss *siemSink) PublishEvent(event *models.Event) error {
+	return nil
+}
+
+func (ss *siemSink) PublishHeader(header *models.BlockHeader) error {
+	return nil
+}
+
+func (ss *siemSink) PublishGasStats(stats *models.GasStats) error {
+	return nil
+}
+
+func (ss *siemSink) PublishWithdrawal(withdrawal *models.Withdrawal) error {
+	return nil
+}
+
+func (ss *siemSink) PublishBridgeTransfer(transfer *models.BridgeTransfer) error {
+	return nil
+}
+
+func (ss *siemSink) PublishEnrichedBlock(enriched *models.EnrichedBlock) error {
+	return nil
+}
+
+// archiveSink 冷归档输出端，只归档区块及交易
+type archiveSink struct {
+	archiver *publisher.Archiver
+}
+
+// NewArchiveSink 创建冷归档输出端
+func NewArchiveSink(archiver *publisher.Archiver) Sink {
+	return &archiveSink{archiver: archiver}
+}
+
+func (as *archiveSink) Name() string { return "archive" }
+
+func (as *archiveSink) PublishBlock(block *models.Block) error {
+	return as.archiver.ArchiveBlock(block)
+}
+
+func (as *archiveSink) PublishTransaction(tx *models.Transaction) error {
+	return as.archiver.ArchiveTransaction(tx)
+}
+
+func (as *archiveSink) PublishAlert(alert *models.RiskAlert) error {
+	return nil
+}
+
+func (as *archiveSink) PublishEvent(event *models.Event) error {
+	return nil
+}
+
+func (as *archiveSink) PublishHeader(header *models.BlockHeader) error {
+	return nil
+}
+
+func (as *archiveSink) PublishGasStats(stats *models.GasStats) error {
+	return nil
+}
+
+func (as *archiveSink) PublishWithdrawal(withdrawal *models.Withdrawal) error {
+	return nil
+}
+
+func (as *archiveSink) PublishBridgeTransfer(transfer *models.BridgeTransfer) error {
+	return nil
+}
+
+func (as *archiveSink) PublishEnrichedBlock(enriched *models.EnrichedBlock) error {
+	return nil
+}
+
+// webhookSink Webhook推送输出端，按各地址的过滤条件推送区块、交易及告警
+type webhookSink struct {
+	dispatcher *webhook.Dispatcher
+}
+
+// NewWebhookSink 创建Webhook输出端
+func NewWebhookSink(dispatcher *webhook.Dispatcher) Sink {
+	return &webhookSink{dispatcher: dispatcher}
+}
+
+func (ws *webhookSink) Name() string { return "webhook" }
+
+func (ws *webhookSink) PublishBlock(block *models.Block) error {
+	return ws.dispatcher.PublishBlock(block)
+}
+
+func (ws *webhookSink) PublishTransaction(tx *models.Transaction) error {
+	return ws.dispatcher.PublishTransaction(tx)
+}
+
+func (ws *webhookSink) PublishAlert(alert *models.RiskAlert) error {
+	return ws.dispatcher.PublishAlert(alert)
+}
+
+func (ws *webhookSink) PublishEvent(event *models.Event) error {
+	return nil
+}
+
+func (ws *webhookSink) PublishHeader(header *models.BlockHeader) error {
+	return nil
+}
+
+func (ws *webhookSink) PublishGasStats(stats *models.GasStats) error {
+	return nil
+}
+
+func (ws *webhookSink) PublishWithdrawal(withdrawal *models.Withdrawal) error {
+	return nil
+}
+
+func (ws *webhookSink) PublishBridgeTransfer(transfer *models.BridgeTransfer) error {
+	return nil
+}
+
+func (ws *webhookSink) PublishEnrichedBlock(enriched *models.EnrichedBlock) error {
+	return nil
+}
+
+// redisSink Redis状态输出端（最新区块、地址统计、高风险交易）
+type redisSink struct {
+	client        *database.RedisClient
+	trackChanges  bool          // 记录统计有变化的地址，供数仓增量导出
+	statsTTL      time.Duration // 地址统计的过期时间，每次活动刷新，为0时永久保留
+	trackActivity bool          // 记录地址最近活动时间，供不活跃统计汇总清理
+}
+
+// NewRedisSink 创建Redis输出端
+func NewRedisSink(client *database.RedisClient, trackChanges bool, addressStats config.AddressStatsConfig) Sink {
+	return &redisSink{
+		client:        client,
+		trackChanges:  trackChanges,
+		statsTTL:      warehouse.AddressStatsTTL(addressStats),
+		trackActivity: addressStats.Rollup.Enabled,
+	}
+}
+
+func (rs *redisSink) Name() string { return "redis" }
+
+func (rs *redisSink) Ping(ctx context.Context) error { return rs.client.Ping() }
+
+// PublishBlock 更新最新区块信息到Redis
+func (rs *redisSink) PublishBlock(block *models.Block) error {
+	key := fmt.Sprintf("latest_block:%s", block.Network)
+	data := map[string]interface{}{
+		"number":    block.Number,
+		"hash":      block.Hash,
+		"timestamp": block.Timestamp.Unix(),
+		"tx_count":  block.TxCount,
+	}
+
+	return rs.client.HMSet(key, data)
+}
+
+// PublishTransaction 更新地址统计信息
+func (rs *redisSink) PublishTransaction(tx *models.Transaction) error {
+	// 更新发送方地址统计
+	if err := rs.updateSingleAddressStats(tx.FromAddress, tx, true); err != nil {
+		return err
+	}
+
+	// 更新接收方地址统计
+	if tx.ToAddress != "" {
+		if err := rs.updateSingleAddressStats(tx.ToAddress, tx, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PublishAlert 记录高风险交易
+func (rs *redisSink) PublishAlert(alert *models.RiskAlert) error {
+	// 运维告警不关联交易，无需记录
+	if alert.TransactionHash == "" {
+		return nil
+	}
+
+	key := fmt.Sprintf("high_risk_tx:%s", alert.Network)
+
+	record := map[string]interface{}{
+		"hash":         alert.TransactionHash,
+		"from_address": alert.Address,
+		"to_address":   alert.Metadata["to_address"],
+		"value":        alert.Metadata["value"],
+		"risk_score":   alert.RiskScore,
+		"risk_type":    alert.Type,
+		"timestamp":    alert.Timestamp.Unix(),
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	// 使用交易时间戳作为分数
+	return rs.client.ZAdd(key, float64(alert.Timestamp.Unix()), string(data))
+}
+
+// PublishEvent 合约事件无需更新Redis状态
+func (rs *redisSink) PublishEvent(event *models.Event) error {
+	return nil
+}
+
+// PublishHeader 记录最新观测到的链头
+func (rs *redisSink) PublishHeader(header *models.BlockHeader) error {
+	key := fmt.Sprintf("latest_header:%s", header.Network)
+	data := map[string]interface{}{
+		"number":      header.Number,
+		"hash":        header.Hash,
+		"timestamp":   header.Timestamp.Unix(),
+		"observed_at": header.ObservedAt.UnixMilli(),
+	}
+
+	return rs.client.HMSet(key, data)
+}
+
+// PublishGasStats 费用估算由处理器维护，无需写入Redis
+func (rs *redisSink) PublishGasStats(stats *models.GasStats) error {
+	return nil
+}
+
+// PublishWithdrawal 提款计入接收地址的统计
+func (rs *redisSink) PublishWithdrawal(withdrawal *models.Withdrawal) error {
+	key := warehouse.AddressStatsKey(withdrawal.Network, withdrawal.Address)
+
+	stats, err := rs.client.HGetAll(key)
+	if err != nil {
+		stats = make(map[string]string)
+	}
+
+	// 提款金额计入接收金额，提款次数单独统计，不计入接收交易数
+	if err := addValueInMap(stats, "received_volume", withdrawal.Amount); err != nil {
+		return err
+	}
+	if err := incrementCounterInMap(stats, "withdrawal_count"); err != nil {
+		return err
+	}
+	if err := addValueInMap(stats, "withdrawal_volume", withdrawal.Amount); err != nil {
+		return err
+	}
+
+	return rs.saveAddressStats(withdrawal.Network, withdrawal.Address, stats, withdrawal.Timestamp)
+}
+
+// PublishBridgeTransfer 跨链关联由处理器维护，无需写入Redis
+func (rs *redisSink) PublishBridgeTransfer(transfer *models.BridgeTransfer) error {
+	return nil
+}
+
+func (rs *redisSink) PublishEnrichedBlock(enriched *models.EnrichedBlock) error {
+	return nil
+}
+
+// updateSingleAddressStats 更新单个地址统计
+func (rs *redisSink) updateSingleAddressStats(address string, tx *models.Transaction, isSender bool) error {
+	key := warehouse.AddressStatsKey(tx.Network, address)
+
+	// 获取当前统计
+	stats, err := rs.client.HGetAll(key)
+	if err != nil {
+		// 如果不存在，创建新的统计
+		stats = make(map[string]string)
+	}
+
+	// 更新统计信息
+	if isSender {
+		// 更新发送统计
+		if err := incrementCounterInMap(stats, "sent_count"); err != nil {
+			return err
+		}
+		if err := addValueInMap(stats, "sent_volume", tx.Value); err != nil {
+			return err
+		}
+	} else {
+		// 更新接收统计
+		if err := incrementCounterInMap(stats, "received_count"); err != nil {
+			return err
+		}
+		if err := addValueInMap(stats, "received_volume", tx.Value); err != nil {
+			return err
+		}
+	}
+
+	return rs.saveAddressStats(tx.Network, address, stats, tx.Timestamp)
+}
+
+// saveAddressStats 更新活动时间后保存地址统计
+func (rs *redisSink) saveAddressStats(network, address string, stats map[string]string, activity time.Time) error {
+	// 更新最后活动时间
+	stats["last_activity"] = fmt.Sprintf("%d", activity.Unix())
+
+	// 设置首次见到时间（如果不存在）
+	if _, exists := stats["first_seen"]; !exists {
+		stats["first_seen"] = fmt.Sprintf("%d", activity.Unix())
+	}
+
+	// 保存到Redis
+	key := warehouse.AddressStatsKey(network, address)
+	if err := rs.client.HMSetString(key, stats); err != nil {
+		return err
+	}
+
+	// 按处理时间刷新保留期，回填的历史交易不会使统计立即过期
+	if rs.statsTTL > 0 {
+		if err := rs.client.Expire(key, rs.statsTTL); err != nil {
+			return err
+		}
+	}
+	if rs.trackActivity {
+		if err := rs.client.ZAdd(warehouse.AddressStatsActivityKey, float64(time.Now().Unix()), network+":"+address); err != nil {
+			return err
+		}
+	}
+
+	if rs.trackChanges {
+		return rs.client.SAdd(warehouse.AddressStatsChangedKey, network+":"+address)
+	}
+	return nil
+}
+
+// incrementCounterInMap 在map中递增计数器
+func incrementCounterInMap(stats map[string]string, key string) error {
+	currentValue := int64(0)
+	if val, exists := stats[key]; exists {
+		if parsed, err := parseInt64(val); err == nil {
+			currentValue = parsed
+		}
+	}
+	stats[key] = fmt.Sprintf("%d", currentValue+1)
+	return nil
+}
+
+// addValueInMap 在map中累加数值
+func addValueInMap(stats map[string]string, key string, value *big.Int) error {
+	currentValue := big.NewInt(0)
+	if val, exists := stats[key]; exists {
+		if parsed, ok := currentValue.SetString(val, 10); ok {
+			currentValue = parsed
+		}
+	}
+	currentValue = currentValue.Add(currentValue, value)
+	stats[key] = currentValue.String()
+	return nil
+}
@@ -0,0 +1,244 @@
+package processor
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/metrics"
+	"web3-data-collector/internal/models"
+)
+
+// 未配置燃烧率告警规则时使用的默认规则（快速/慢速双窗口）
+var defaultBurnRateAlerts = []config.BurnRateAlertConfig{
+	{Window: "5m", Threshold: 14.4, Level: "HIGH"},
+	{Window: "30m", Threshold: 6, Level: "MEDIUM"},
+}
+
+// minBurnRateSamples 计算窗口内样本过少时不告警，避免个别慢区块误报
+const minBurnRateSamples = 10
+
+// SLOTracker 按网络跟踪区块处理延迟SLO
+type SLOTracker struct {
+	defaults       sloObjective
+	overrides      map[string]sloObjective
+	window         time.Duration
+	cooldown       time.Duration
+	alerts         []burnRateAlert
+	networks       map[string]*sloState
+	metricsManager *metrics.Manager
+	mu             sync.Mutex
+}
+
+// sloObjective 处理延迟目标
+type sloObjective struct {
+	target    float64
+	threshold time.Duration
+}
+
+// burnRateAlert 燃烧率告警规则
+type burnRateAlert struct {
+	label     string
+	window    time.Duration
+	threshold float64
+	level     string
+}
+
+// sloState 单个网络的采样与告警状态
+type sloState struct {
+	samples   []sloSample
+	lastAlert map[string]time.Time
+}
+
+// sloSample 单个区块的处理结果
+type sloSample struct {
+	at   time.Time
+	good bool
+}
+
+// NewSLOTracker 根据配置创建SLO跟踪器，未启用时返回nil
+func NewSLOTracker(cfg config.SLOConfig, metricsManager *metrics.Manager) (*SLOTracker, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	defaults, err := newSLOObjective(cfg.Target, cfg.LatencyThreshold, sloObjective{target: 0.95, threshold: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	tracker := &SLOTracker{
+		defaults:       defaults,
+		overrides:      make(map[string]sloObjective),
+		window:         time.Hour,
+		cooldown:       15 * time.Minute,
+		networks:       make(map[string]*sloState),
+		metricsManager: metricsManager,
+	}
+
+	if cfg.Window != "" {
+		if tracker.window, err = time.ParseDuration(cfg.Window); err != nil {
+			return nil, fmt.Errorf("invalid slo window: %w", err)
+		}
+	}
+	if cfg.AlertCooldown != "" {
+		if tracker.cooldown, err = time.ParseDuration(cfg.AlertCooldown); err != nil {
+			return nil, fmt.Errorf("invalid slo alert_cooldown: %w", err)
+		}
+	}
+
+	for network, override := range cfg.Networks {
+		objective, err := newSLOObjective(override.Target, override.LatencyThreshold, defaults)
+		if err != nil {
+			return nil, fmt.Errorf("network %s: %w", network, err)
+		}
+		tracker.overrides[network] = objective
+	}
+
+	rules := cfg.BurnRateAlerts
+	if len(rules) == 0 {
+		rules = defaultBurnRateAlerts
+	}
+	for _, rule := range rules {
+		window, err := time.ParseDuration(rule.Window)
+		if err != nil {
+			return nil, fmt.Errorf("invalid burn rate window %q: %w", rule.Window, err)
+		}
+		if window > tracker.window {
+			// 采样只保留统计窗口内的数据
+			tracker.window = window
+		}
+		tracker.alerts = append(tracker.alerts, burnRateAlert{
+			label:     rule.Window,
+			window:    window,
+			threshold: rule.Threshold,
+			level:     rule.Level,
+		})
+	}
+
+	logger.Infof("Processing SLO enabled: %.2f%% of blocks within %v", defaults.target*100, defaults.threshold)
+	return tracker, nil
+}
+
+// newSLOObjective 解析目标配置，零值沿用fallback
+func newSLOObjective(target float64, threshold string, fallback sloObjective) (sloObjective, error) {
+	objective := fallback
+
+	if target != 0 {
+		if target <= 0 || target >= 1 {
+			return objective, fmt.Errorf("slo target must be between 0 and 1, got %v", target)
+		}
+		objective.target = target
+	}
+
+	if threshold != "" {
+		parsed, err := time.ParseDuration(threshold)
+		if err != nil {
+			return objective, fmt.Errorf("invalid slo latency_threshold: %w", err)
+		}
+		objective.threshold = parsed
+	}
+
+	return objective, nil
+}
+
+// Record 记录区块处理延迟并计算燃烧率，返回需要发送的运维告警
+func (t *SLOTracker) Record(network string, latency time.Duration, now time.Time) []*models.RiskAlert {
+	objective := t.objective(network)
+	good := latency <= objective.threshold
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, exists := t.networks[network]
+	if !exists {
+		state = &sloState{lastAlert: make(map[string]time.Time)}
+		t.networks[network] = state
+	}
+
+	state.samples = append(state.samples, sloSample{at: now, good: good})
+	state.prune(now.Add(-t.window))
+
+	t.metricsManager.RecordSLOBlock(network, good)
+
+	// 允许的错误比例即错误预算
+	budget := 1 - objective.target
+
+	total, bad := state.count(now.Add(-t.window))
+	compliance := float64(total-bad) / float64(total)
+	t.metricsManager.SetSLOCompliance(network, compliance)
+	t.metricsManager.SetSLOErrorBudgetRemaining(network, 1-(float64(bad)/float64(total))/budget)
+
+	var alerts []*models.RiskAlert
+	for _, rule := range t.alerts {
+		total, bad := state.count(now.Add(-rule.window))
+		burnRate := (float64(bad) / float64(total)) / budget
+		t.metricsManager.SetSLOBurnRate(network, rule.label, burnRate)
+
+		if total < minBurnRateSamples || burnRate < rule.threshold {
+			continue
+		}
+		if last, ok := state.lastAlert[rule.label]; ok && now.Sub(last) < t.cooldown {
+			continue
+		}
+		state.lastAlert[rule.label] = now
+
+		alerts = append(alerts, t.newBurnRateAlert(network, objective, rule, burnRate, compliance, now))
+	}
+
+	return alerts
+}
+
+// objective 获取网络的SLO目标
+func (t *SLOTracker) objective(network string) sloObjective {
+	if objective, exists := t.overrides[network]; exists {
+		return objective
+	}
+	return t.defaults
+}
+
+// newBurnRateAlert 创建错误预算消耗过快的运维告警
+func (t *SLOTracker) newBurnRateAlert(network string, objective sloObjective, rule burnRateAlert, burnRate, compliance float64, now time.Time) *models.RiskAlert {
+	return &models.RiskAlert{
+		ID:    models.OpsAlertID("SLO_BURN_RATE", now, network, rule.label),
+		Type:  "SLO_BURN_RATE",
+		Level: rule.level,
+		Title: fmt.Sprintf("Processing SLO budget burning fast on %s", network),
+		Description: fmt.Sprintf("Error budget burn rate %.1fx over %s exceeds %.1fx (target %.2f%% of blocks within %v)",
+			burnRate, rule.label, rule.threshold, objective.target*100, objective.threshold),
+		Network:     network,
+		RiskFactors: []string{"processing_latency"},
+		Metadata: map[string]interface{}{
+			"burn_rate":         burnRate,
+			"burn_rate_window":  rule.label,
+			"threshold":         rule.threshold,
+			"compliance":        compliance,
+			"target":            objective.target,
+			"latency_threshold": objective.threshold.String(),
+		},
+		Timestamp: now,
+		Status:    "ACTIVE",
+	}
+}
+
+// prune 清理早于给定时间的采样
+func (s *sloState) prune(before time.Time) {
+	i := 0
+	for i < len(s.samples) && s.samples[i].at.Before(before) {
+		i++
+	}
+	s.samples = s.samples[i:]
+}
+
+// count 统计给定时间之后的采样总数与未达标数
+func (s *sloState) count(since time.Time) (int, int) {
+	total, bad := 0, 0
+	for i := len(s.samples) - 1; i >= 0 && !s.samples[i].at.Before(since); i-- {
+		total++
+		if !s.samples[i].good {
+			bad++
+		}
+	}
+	return total, bad
+}
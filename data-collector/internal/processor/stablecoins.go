@@ -0,0 +1,370 @@
+package processor
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/models"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// stablecoinKeyPrefix 稳定币供应量变化在Redis中的键前缀：
+// stablecoin_supply:{网络}:{代币} 为累计值，stablecoin_supply:{网络}:{代币}:{日期} 为按天统计，
+// stablecoin_supply:seen:{记录ID} 用于去重
+const stablecoinKeyPrefix = "stablecoin_supply"
+
+// 稳定币供应量变化类型
+const (
+	StablecoinMint = "mint"
+	StablecoinBurn = "burn"
+)
+
+// zeroAddress 铸造的转出方及销毁的接收方
+var zeroAddress = common.Address{}.Hex()
+
+// StablecoinSupplyChange 稳定币的一次铸造或销毁
+type StablecoinSupplyChange struct {
+	ID              string // 见 models.TransferID，与对应的日志事件序号相同
+	Network         string
+	Token           string
+	Symbol          string
+	Kind            string // mint/burn
+	Account         string // 铸造的接收方或销毁的转出方，USDT发行/赎回为交易发起方
+	Amount          *big.Int
+	TransactionHash string
+	BlockNumber     uint64
+	Timestamp       time.Time
+}
+
+// StablecoinSupplyTotals 稳定币一段时间内的供应量变化，金额为最小单位的十进制字符串
+type StablecoinSupplyTotals struct {
+	Network   string `json:"network"`
+	Token     string `json:"token"`
+	Symbol    string `json:"symbol"`
+	Decimals  uint8  `json:"decimals"`
+	Date      string `json:"date,omitempty"` // 按天统计时的日期（UTC）
+	Mints     int64  `json:"mints"`
+	Burns     int64  `json:"burns"`
+	Minted    string `json:"minted"`
+	Burned    string `json:"burned"`
+	NetChange string `json:"net_change"` // 铸造减销毁
+}
+
+// stablecoin 监控的稳定币合约
+type stablecoin struct {
+	network   string
+	token     string
+	currency  *NativeCurrency // 代币精度及符号，用于阈值换算及展示
+	threshold *big.Int        // 以最小单位表示，为nil时不告警
+}
+
+// StablecoinMonitor 跟踪配置的稳定币的铸造与销毁，累计供应量变化并判断是否超过告警阈值
+type StablecoinMonitor struct {
+	client    *database.RedisClient
+	tokens    map[string]*stablecoin // 网络:小写合约地址 -> 稳定币
+	retention time.Duration
+}
+
+// NewStablecoinMonitor 根据配置创建稳定币监控，未启用时返回nil
+func NewStablecoinMonitor(cfg config.StablecoinConfig, redisClient *database.RedisClient) (*StablecoinMonitor, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	retention := 365 * 24 * time.Hour
+	if cfg.Retention != "" {
+		parsed, err := time.ParseDuration(cfg.Retention)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stablecoins retention: %w", err)
+		}
+		retention = parsed
+	}
+
+	tokens := make(map[string]*stablecoin, len(cfg.Tokens))
+	for _, tokenCfg := range cfg.Tokens {
+		coin := &stablecoin{
+			network:  tokenCfg.Network,
+			token:    common.HexToAddress(tokenCfg.Address).Hex(),
+			currency: newNativeCurrency(config.NativeCurrencyConfig{Symbol: tokenCfg.Symbol, Decimals: tokenCfg.Decimals}),
+		}
+		if tokenCfg.AlertThreshold != "" {
+			coin.threshold = coin.currency.parseUnits(tokenCfg.AlertThreshold)
+		}
+		tokens[stablecoinIndex(tokenCfg.Network, tokenCfg.Address)] = coin
+	}
+
+	return &StablecoinMonitor{
+		client:    redisClient,
+		tokens:    tokens,
+		retention: retention,
+	}, nil
+}
+
+// Tracks 判断代币是否为网络上监控的稳定币
+func (m *StablecoinMonitor) Tracks(network, token string) bool {
+	_, exists := m.tokens[stablecoinIndex(network, token)]
+	return exists
+}
+
+// FromTransfer 监控的稳定币从零地址转入为铸造、转出到零地址为销毁，其他转账返回nil
+func (m *StablecoinMonitor) FromTransfer(transfer *TokenTransfer) *StablecoinSupplyChange {
+	coin, exists := m.tokens[stablecoinIndex(transfer.Network, transfer.Token)]
+	if !exists || transfer.Amount == nil || transfer.Amount.Sign() == 0 {
+		return nil
+	}
+
+	change := &StablecoinSupplyChange{
+		ID:              transfer.ID,
+		Network:         transfer.Network,
+		Token:           coin.token,
+		Symbol:          coin.currency.Symbol,
+		Amount:          transfer.Amount,
+		TransactionHash: transfer.TransactionHash,
+		BlockNumber:     transfer.BlockNumber,
+		Timestamp:       transfer.Timestamp,
+	}
+	switch {
+	case transfer.From == zeroAddress && transfer.To != zeroAddress:
+		change.Kind = StablecoinMint
+		change.Account = transfer.To
+	case transfer.To == zeroAddress && transfer.From != zeroAddress:
+		change.Kind = StablecoinBurn
+		change.Account = transfer.From
+	default:
+		return nil
+	}
+	return change
+}
+
+// Resolve 补全直接构造的供应量变化（如USDT的Issue/Redeem）的代币信息，代币未监控时返回false
+func (m *StablecoinMonitor) Resolve(change *StablecoinSupplyChange) bool {
+	coin, exists := m.tokens[stablecoinIndex(change.Network, change.Token)]
+	if !exists {
+		return false
+	}
+	change.Token = coin.token
+	change.Symbol = coin.currency.Symbol
+	return true
+}
+
+// ExceedsThreshold 判断单笔铸造/销毁是否达到告警阈值
+func (m *StablecoinMonitor) ExceedsThreshold(change *StablecoinSupplyChange) bool {
+	coin, exists := m.tokens[stablecoinIndex(change.Network, change.Token)]
+	return exists && coin.threshold != nil && change.Amount.Cmp(coin.threshold) >= 0
+}
+
+// Format 按代币精度格式化金额，如 "1000000 USDT"
+func (m *StablecoinMonitor) Format(change *StablecoinSupplyChange) string {
+	coin, exists := m.tokens[stablecoinIndex(change.Network, change.Token)]
+	if !exists {
+		return change.Amount.String()
+	}
+	return coin.currency.Format(change.Amount)
+}
+
+// Units 按代币精度换算金额
+func (m *StablecoinMonitor) Units(change *StablecoinSupplyChange) float64 {
+	coin, exists := m.tokens[stablecoinIndex(change.Network, change.Token)]
+	if !exists {
+		return 0
+	}
+	units, _ := coin.currency.ToUnits(change.Amount).Float64()
+	return units
+}
+
+// Record 将供应量变化累加到累计值及当天统计，同一日志事件只计入一次
+func (m *StablecoinMonitor) Record(change *StablecoinSupplyChange) (bool, error) {
+	first, err := m.client.SetNX(fmt.Sprintf("%s:seen:%s", stablecoinKeyPrefix, change.ID), 1, supplyDedupTTL)
+	if err != nil || !first {
+		return false, err
+	}
+
+	if err := m.add(stablecoinTotalsKey(change.Network, change.Token), change, 0); err != nil {
+		return false, err
+	}
+	date := change.Timestamp.UTC().Format(tokenFlowDateLayout)
+	if err := m.add(stablecoinDailyKey(change.Network, change.Token, date), change, m.retention); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// add 以读改写方式合并金额，expiration为0时不过期
+func (m *StablecoinMonitor) add(key string, change *StablecoinSupplyChange, expiration time.Duration) error {
+	current, err := m.client.HGetAll(key)
+	if err != nil {
+		return err
+	}
+
+	amountField, countField := "minted", "mints"
+	if change.Kind == StablecoinBurn {
+		amountField, countField = "burned", "burns"
+	}
+	amount, _ := new(big.Int).SetString(current[amountField], 10)
+	if amount == nil {
+		amount = new(big.Int)
+	}
+	count, _ := parseInt64(current[countField])
+
+	fields := map[string]interface{}{
+		amountField: amount.Add(amount, change.Amount).String(),
+		countField:  count + 1,
+	}
+	if err := m.client.HMSet(key, fields); err != nil {
+		return err
+	}
+	if expiration > 0 {
+		return m.client.Expire(key, expiration)
+	}
+	return nil
+}
+
+// Totals 获取网络上每个监控的稳定币自开始统计以来的累计值，按符号排序
+func (m *StablecoinMonitor) Totals(network string) ([]*StablecoinSupplyTotals, error) {
+	totals := []*StablecoinSupplyTotals{}
+	for _, coin := range m.networkTokens(network) {
+		values, err := m.client.HGetAll(stablecoinTotalsKey(network, coin.token))
+		if err != nil {
+			return nil, err
+		}
+		totals = append(totals, newStablecoinSupplyTotals(coin, "", values))
+	}
+	return totals, nil
+}
+
+// Daily 获取网络上监控的稳定币在[start, end]日期范围内的每日统计，没有数据的日期不返回
+func (m *StablecoinMonitor) Daily(network string, start, end time.Time) ([]*StablecoinSupplyTotals, error) {
+	days := []*StablecoinSupplyTotals{}
+	for day := start.UTC().Truncate(24 * time.Hour); !day.After(end); day = day.Add(24 * time.Hour) {
+		date := day.Format(tokenFlowDateLayout)
+		for _, coin := range m.networkTokens(network) {
+			values, err := m.client.HGetAll(stablecoinDailyKey(network, coin.token, date))
+			if err != nil {
+				return nil, err
+			}
+			if len(values) == 0 {
+				continue
+			}
+			days = append(days, newStablecoinSupplyTotals(coin, date, values))
+		}
+	}
+	return days, nil
+}
+
+// networkTokens 网络上监控的稳定币，按符号排序
+func (m *StablecoinMonitor) networkTokens(network string) []*stablecoin {
+	coins := []*stablecoin{}
+	for _, coin := range m.tokens {
+		if coin.network == network {
+			coins = append(coins, coin)
+		}
+	}
+	sort.Slice(coins, func(i, j int) bool {
+		return coins[i].currency.Symbol < coins[j].currency.Symbol
+	})
+	return coins
+}
+
+// newStablecoinSupplyTotals 从统计字段构造结果并计算净变化
+func newStablecoinSupplyTotals(coin *stablecoin, date string, values map[string]string) *StablecoinSupplyTotals {
+	minted, ok := new(big.Int).SetString(values["minted"], 10)
+	if !ok {
+		minted = new(big.Int)
+	}
+	burned, ok := new(big.Int).SetString(values["burned"], 10)
+	if !ok {
+		burned = new(big.Int)
+	}
+	mints, _ := parseInt64(values["mints"])
+	burns, _ := parseInt64(values["burns"])
+
+	return &StablecoinSupplyTotals{
+		Network:   coin.network,
+		Token:     coin.token,
+		Symbol:    coin.currency.Symbol,
+		Decimals:  coin.currency.Decimals,
+		Date:      date,
+		Mints:     mints,
+		Burns:     burns,
+		Minted:    minted.String(),
+		Burned:    burned.String(),
+		NetChange: new(big.Int).Sub(minted, burned).String(),
+	}
+}
+
+func stablecoinIndex(network, token string) string {
+	return network + ":" + strings.ToLower(token)
+}
+
+func stablecoinTotalsKey(network, token string) string {
+	return fmt.Sprintf("%s:%s:%s", stablecoinKeyPrefix, network, token)
+}
+
+func stablecoinDailyKey(network, token, date string) string {
+	return fmt.Sprintf("%s:%s:%s:%s", stablecoinKeyPrefix, network, token, date)
+}
+
+// ProcessStablecoinSupplyChanges 记录区块内的稳定币铸造/销毁并更新指标，单笔达到阈值时发布告警，返回生成的告警
+func (dp *DataProcessor) ProcessStablecoinSupplyChanges(changes []*StablecoinSupplyChange) []*models.RiskAlert {
+	var alerts []*models.RiskAlert
+	for _, change := range changes {
+		recorded, err := dp.stablecoins.Record(change)
+		if err != nil {
+			logger.Errorf("Failed to record %s %s %s: %v", change.Symbol, change.Kind, change.ID, err)
+			continue
+		}
+		// 重复处理的区块不再计入指标及告警
+		if !recorded {
+			continue
+		}
+		dp.metricsManager.RecordStablecoinSupplyChange(change.Network, change.Symbol, change.Kind, dp.stablecoins.Units(change))
+
+		if !dp.stablecoins.ExceedsThreshold(change) {
+			continue
+		}
+		alert := dp.createStablecoinAlert(change)
+		logger.Warnf("%s: %s", alert.Title, alert.Description)
+		if err := dp.PublishOpsAlert(alert); err != nil {
+			logger.Errorf("Failed to publish stablecoin %s alert for %s: %v", change.Kind, change.TransactionHash, err)
+			continue
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts
+}
+
+// createStablecoinAlert 创建稳定币大额铸造/销毁告警，地址为铸造接收方或销毁方
+func (dp *DataProcessor) createStablecoinAlert(change *StablecoinSupplyChange) *models.RiskAlert {
+	alertType, title, action := "STABLECOIN_MINT", "稳定币大额铸造", "铸造"
+	if change.Kind == StablecoinBurn {
+		alertType, title, action = "STABLECOIN_BURN", "稳定币大额销毁", "销毁"
+	}
+
+	return &models.RiskAlert{
+		ID:              models.AlertID(alertType, change.ID),
+		Type:            alertType,
+		Level:           "HIGH",
+		Title:           title,
+		Description:     fmt.Sprintf("%s 地址 %s %s", action, change.Account, dp.stablecoins.Format(change)),
+		TransactionHash: change.TransactionHash,
+		Address:         change.Account,
+		Network:         change.Network,
+		RiskScore:       0.5,
+		RiskFactors:     []string{"stablecoin_" + change.Kind},
+		Metadata: map[string]interface{}{
+			"block_number": change.BlockNumber,
+			"token":        change.Token,
+			"symbol":       change.Symbol,
+			"kind":         change.Kind,
+			"amount":       change.Amount.String(),
+		},
+		Timestamp: change.Timestamp,
+		Status:    "ACTIVE",
+	}
+}
@@ -0,0 +1,338 @@
+package processor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/metrics"
+	"web3-data-collector/internal/models"
+)
+
+// 处理阶段名称，用于panic指标及隔离记录
+const (
+	StageBlock       = "block"
+	StageTransaction = "transaction"
+	StageEvent       = "event"
+	StageMEV         = "mev"
+)
+
+// maxTrackedFailures 失败计数表的最大条数，超出时清空，避免不再重试的数据长期占用内存
+const maxTrackedFailures = 10000
+
+// attemptBackoff Attempt原地重试的退避间隔（按次数递增）
+const attemptBackoff = 100 * time.Millisecond
+
+// PanicError 处理阶段panic被恢复后返回的错误
+type PanicError struct {
+	Stage   string
+	Network string
+	Value   interface{}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic in %s stage for %s: %v", e.Stage, e.Network, e.Value)
+}
+
+// QuarantinedError 数据连续处理失败达到上限被隔离后返回的错误
+type QuarantinedError struct {
+	Stage    string
+	Network  string
+	Key      string
+	Failures int
+	Err      error
+}
+
+func (e *QuarantinedError) Error() string {
+	return fmt.Sprintf("quarantined %s %s for %s after %d failures: %v", e.Stage, e.Key, e.Network, e.Failures, e.Err)
+}
+
+func (e *QuarantinedError) Unwrap() error {
+	return e.Err
+}
+
+// IsQuarantined 判断错误是否表示数据已被隔离（panic或连续失败），调用方不应再重试
+func IsQuarantined(err error) bool {
+	var panicErr *PanicError
+	var quarantinedErr *QuarantinedError
+	return errors.As(err, &panicErr) || errors.As(err, &quarantinedErr)
+}
+
+// ResubmitFunc 重新处理被隔离的数据
+type ResubmitFunc func(network string, payload json.RawMessage) error
+
+// Supervisor 隔离各处理阶段及网络协程的panic与反复失败的数据，保证其余流程继续运行
+type Supervisor struct {
+	client         *database.RedisClient
+	stream         string
+	maxLen         int64
+	maxFailures    int
+	enabled        bool
+	metricsManager *metrics.Manager
+	failures       map[string]int
+	resubmitters   map[string]ResubmitFunc
+	mu             sync.Mutex
+}
+
+// NewSupervisor 创建处理阶段监督器，隔离存储未启用时只恢复panic并记录指标
+func NewSupervisor(cfg config.QuarantineConfig, redisClient *database.RedisClient, metricsManager *metrics.Manager) *Supervisor {
+	stream := cfg.Stream
+	if stream == "" {
+		stream = "quarantine"
+	}
+	maxFailures := cfg.MaxFailures
+	if maxFailures <= 0 {
+		maxFailures = 3
+	}
+
+	return &Supervisor{
+		client:         redisClient,
+		stream:         stream,
+		maxLen:         cfg.MaxLen,
+		maxFailures:    maxFailures,
+		enabled:        cfg.Enabled,
+		metricsManager: metricsManager,
+		failures:       make(map[string]int),
+		resubmitters:   make(map[string]ResubmitFunc),
+	}
+}
+
+// Guard 执行处理阶段：panic时立即隔离payload并返回PanicError；
+// 返回错误时累计该数据的失败次数，达到上限后隔离并返回QuarantinedError
+func (s *Supervisor) Guard(stage, network, key string, payload interface{}, fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.clearFailures(stage, network, key)
+			s.record(&models.QuarantineEntry{
+				Stage:   stage,
+				Network: network,
+				Key:     key,
+				Panic:   fmt.Sprint(r),
+				Stack:   string(debug.Stack()),
+			}, payload)
+			err = &PanicError{Stage: stage, Network: network, Value: r}
+		}
+	}()
+
+	if err = fn(); err == nil {
+		s.clearFailures(stage, network, key)
+		return nil
+	}
+
+	failures := s.addFailure(stage, network, key)
+	if failures < s.maxFailures {
+		return err
+	}
+
+	s.clearFailures(stage, network, key)
+	s.record(&models.QuarantineEntry{
+		Stage:    stage,
+		Network:  network,
+		Key:      key,
+		Error:    err.Error(),
+		Failures: failures,
+	}, payload)
+	return &QuarantinedError{Stage: stage, Network: network, Key: key, Failures: failures, Err: err}
+}
+
+// Attempt 用于调用方不会重试的数据：在原地重试直到成功或被隔离
+func (s *Supervisor) Attempt(stage, network, key string, payload interface{}, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := s.Guard(stage, network, key, payload, fn)
+		if err == nil || IsQuarantined(err) {
+			return err
+		}
+		logger.Debugf("Retrying %s %s for %s (attempt %d): %v", stage, key, network, attempt, err)
+		time.Sleep(attemptBackoff * time.Duration(attempt))
+	}
+}
+
+// Recover 在协程中以defer调用，恢复panic并记录，协程随后正常退出
+func (s *Supervisor) Recover(stage, network string) {
+	if r := recover(); r != nil {
+		s.record(&models.QuarantineEntry{
+			Stage:   stage,
+			Network: network,
+			Panic:   fmt.Sprint(r),
+			Stack:   string(debug.Stack()),
+		}, nil)
+	}
+}
+
+// RegisterResubmitter 注册处理阶段的重新提交函数
+func (s *Supervisor) RegisterResubmitter(stage string, fn ResubmitFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resubmitters[stage] = fn
+}
+
+// List 按隔离时间顺序列出至多limit条隔离数据
+func (s *Supervisor) List(limit int) ([]*models.QuarantineEntry, error) {
+	if !s.enabled || s.client == nil {
+		return nil, fmt.Errorf("quarantine is not enabled")
+	}
+
+	messages, err := s.client.XRange(s.stream, int64(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quarantine: %w", err)
+	}
+
+	entries := make([]*models.QuarantineEntry, 0, len(messages))
+	for _, message := range messages {
+		raw, _ := message.Values["entry"].(string)
+		entry, err := decodeQuarantineEntry(message.ID, raw)
+		if err != nil {
+			logger.Errorf("Skipping undecodable quarantine entry %s: %v", message.ID, err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// Resubmit 重新处理隔离数据，成功后从隔离存储移除，失败时保留
+func (s *Supervisor) Resubmit(id string) (*models.QuarantineEntry, error) {
+	entry, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	resubmit := s.resubmitters[entry.Stage]
+	s.mu.Unlock()
+	if resubmit == nil {
+		return entry, fmt.Errorf("stage %s does not support resubmission", entry.Stage)
+	}
+	if len(entry.Payload) == 0 {
+		return entry, fmt.Errorf("quarantine entry %s has no payload", id)
+	}
+
+	if err := runRecovered(func() error { return resubmit(entry.Network, entry.Payload) }); err != nil {
+		return entry, fmt.Errorf("resubmission failed: %w", err)
+	}
+
+	if err := s.client.XDel(s.stream, id); err != nil {
+		return entry, fmt.Errorf("resubmitted but failed to remove quarantine entry: %w", err)
+	}
+
+	logger.Infof("Resubmitted quarantined %s %s for %s", entry.Stage, entry.Key, entry.Network)
+	return entry, nil
+}
+
+// Delete 丢弃隔离数据
+func (s *Supervisor) Delete(id string) error {
+	if _, err := s.get(id); err != nil {
+		return err
+	}
+	return s.client.XDel(s.stream, id)
+}
+
+// get 按ID读取隔离数据
+func (s *Supervisor) get(id string) (*models.QuarantineEntry, error) {
+	if !s.enabled || s.client == nil {
+		return nil, fmt.Errorf("quarantine is not enabled")
+	}
+
+	message, err := s.client.XGet(s.stream, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quarantine entry %s: %w", id, err)
+	}
+	if message == nil {
+		return nil, fmt.Errorf("quarantine entry %s not found", id)
+	}
+
+	raw, _ := message.Values["entry"].(string)
+	return decodeQuarantineEntry(message.ID, raw)
+}
+
+// addFailure 累计数据的失败次数并返回当前次数
+func (s *Supervisor) addFailure(stage, network, key string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.failures) >= maxTrackedFailures {
+		logger.Warnf("Failure tracker exceeded %d records, resetting", maxTrackedFailures)
+		s.failures = make(map[string]int)
+	}
+
+	id := failureKey(stage, network, key)
+	s.failures[id]++
+	return s.failures[id]
+}
+
+func (s *Supervisor) clearFailures(stage, network, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.failures, failureKey(stage, network, key))
+}
+
+func failureKey(stage, network, key string) string {
+	return stage + "/" + network + "/" + key
+}
+
+// record 记录指标并将数据写入隔离存储
+func (s *Supervisor) record(entry *models.QuarantineEntry, payload interface{}) {
+	if entry.Panic != "" {
+		logger.Errorf("Recovered panic in %s stage for %s: %s\n%s", entry.Stage, entry.Network, entry.Panic, entry.Stack)
+		s.metricsManager.RecordStagePanic(entry.Stage, entry.Network)
+	} else {
+		logger.Errorf("Quarantining %s %s for %s after %d failures: %s", entry.Stage, entry.Key, entry.Network, entry.Failures, entry.Error)
+	}
+
+	if !s.enabled || s.client == nil {
+		return
+	}
+
+	entry.QuarantinedAt = time.Now()
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			// 无法序列化的数据也记录错误上下文，便于排查
+			logger.Errorf("Failed to encode quarantined %s payload: %v", entry.Stage, err)
+		} else {
+			entry.Payload = data
+		}
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		logger.Errorf("Failed to encode quarantine entry: %v", err)
+		return
+	}
+
+	if _, err := s.client.XAdd(s.stream, s.maxLen, map[string]interface{}{
+		"stage":   entry.Stage,
+		"network": entry.Network,
+		"entry":   string(data),
+	}); err != nil {
+		logger.Errorf("Failed to quarantine %s payload for %s: %v", entry.Stage, entry.Network, err)
+		return
+	}
+	s.metricsManager.RecordQuarantined(entry.Stage, entry.Network)
+}
+
+// decodeQuarantineEntry 解析隔离记录
+func decodeQuarantineEntry(id, raw string) (*models.QuarantineEntry, error) {
+	var entry models.QuarantineEntry
+	if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+		return nil, fmt.Errorf("invalid quarantine entry %s: %w", id, err)
+	}
+	entry.ID = id
+	return &entry, nil
+}
+
+// runRecovered 执行fn并将panic转换为错误
+func runRecovered(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn()
+}
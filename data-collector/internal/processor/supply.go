@@ -0,0 +1,241 @@
+package processor
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/models"
+)
+
+// supplyKeyPrefix 供应量统计在Redis中的键前缀：
+// supply:{网络} 为累计值，supply:{网络}:{日期} 为按天统计，supply:block:{网络}:{区块号} 用于去重
+const supplyKeyPrefix = "supply"
+
+// supplyDedupTTL 区块去重标记的保留时长，覆盖重连补块及重组回放
+const supplyDedupTTL = 24 * time.Hour
+
+// supplyFields 统计的金额字段，均为最小单位的十进制字符串
+var supplyFields = []string{"issuance", "burned", "tips"}
+
+// SupplyTotals 一段时间内的供应量变化
+type SupplyTotals struct {
+	Network   string `json:"network"`
+	Date      string `json:"date,omitempty"` // 按天统计时的日期（UTC）
+	Blocks    int64  `json:"blocks"`
+	Issuance  string `json:"issuance"`
+	Burned    string `json:"burned"`
+	Tips      string `json:"tips"`
+	NetChange string `json:"net_change"` // 发行减销毁
+}
+
+// SupplyTracker 统计每个区块的发行量、EIP-1559销毁的基础费用及小费
+type SupplyTracker struct {
+	client     *database.RedisClient
+	currencies *CurrencyRegistry
+	retention  time.Duration
+}
+
+// NewSupplyTracker 根据配置创建供应量统计，未启用时返回nil
+func NewSupplyTracker(cfg config.SupplyConfig, redisClient *database.RedisClient, currencies *CurrencyRegistry) (*SupplyTracker, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	retention := 365 * 24 * time.Hour
+	if cfg.Retention != "" {
+		parsed, err := time.ParseDuration(cfg.Retention)
+		if err != nil {
+			return nil, fmt.Errorf("invalid supply retention: %w", err)
+		}
+		retention = parsed
+	}
+
+	return &SupplyTracker{
+		client:     redisClient,
+		currencies: currencies,
+		retention:  retention,
+	}, nil
+}
+
+// Compute 计算区块对供应量的影响，交易的GasUsed及EffectiveGasPrice需已从回执填充
+func (st *SupplyTracker) Compute(block *models.Block) *models.BlockSupply {
+	supply := &models.BlockSupply{
+		Network:     block.Network,
+		BlockNumber: block.Number,
+		Timestamp:   block.Timestamp,
+		GasUsed:     block.GasUsed,
+		Issuance:    new(big.Int).Set(st.currencies.Get(block.Network).BlockReward),
+		Burned:      new(big.Int),
+		Tips:        new(big.Int),
+	}
+
+	baseFee := block.BaseFeePerGas
+	if baseFee != nil {
+		supply.BaseFeePerGas = new(big.Int).Set(baseFee)
+		supply.Burned.Mul(baseFee, new(big.Int).SetUint64(block.GasUsed))
+	}
+
+	for i := range block.Transactions {
+		tx := &block.Transactions[i]
+		if tx.L2 != nil && tx.L2.Type == models.L2Optimism && tx.L2.L1Fee != nil {
+			if supply.L1Fees == nil {
+				supply.L1Fees = new(big.Int)
+			}
+			supply.L1Fees.Add(supply.L1Fees, tx.L2.L1Fee)
+		}
+		price := effectiveGasPrice(tx, baseFee)
+		if price == nil || tx.GasUsed == 0 {
+			continue
+		}
+		// 伦敦升级前没有基础费用，手续费全部归出块者
+		tip := new(big.Int).Set(price)
+		if baseFee != nil {
+			tip.Sub(tip, baseFee)
+		}
+		if tip.Sign() <= 0 {
+			continue
+		}
+		supply.Tips.Add(supply.Tips, tip.Mul(tip, new(big.Int).SetUint64(tx.GasUsed)))
+	}
+
+	supply.NetChange = new(big.Int).Sub(supply.Issuance, supply.Burned)
+	return supply
+}
+
+// effectiveGasPrice 交易实际支付的gas单价，回执缺失时按EIP-1559规则推算
+func effectiveGasPrice(tx *models.Transaction, baseFee *big.Int) *big.Int {
+	if tx.EffectiveGasPrice != nil {
+		return tx.EffectiveGasPrice
+	}
+	if baseFee != nil && tx.MaxFeePerGas != nil && tx.MaxPriorityFeePerGas != nil {
+		price := new(big.Int).Add(baseFee, tx.MaxPriorityFeePerGas)
+		if price.Cmp(tx.MaxFeePerGas) > 0 {
+			price.Set(tx.MaxFeePerGas)
+		}
+		return price
+	}
+	return tx.GasPrice
+}
+
+// Record 将区块的供应量变化累加到累计值及当天统计，同一区块只计入一次
+func (st *SupplyTracker) Record(supply *models.BlockSupply) (bool, error) {
+	first, err := st.client.SetNX(fmt.Sprintf("%s:block:%s:%d", supplyKeyPrefix, supply.Network, supply.BlockNumber), 1, supplyDedupTTL)
+	if err != nil || !first {
+		return false, err
+	}
+
+	if err := st.add(supplyTotalsKey(supply.Network), supply, 0); err != nil {
+		return false, err
+	}
+	date := supply.Timestamp.UTC().Format(tokenFlowDateLayout)
+	if err := st.add(supplyDailyKey(supply.Network, date), supply, st.retention); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// add 以读改写方式合并金额，expiration为0时不过期
+func (st *SupplyTracker) add(key string, supply *models.BlockSupply, expiration time.Duration) error {
+	current, err := st.client.HGetAll(key)
+	if err != nil {
+		return err
+	}
+
+	amounts := map[string]*big.Int{"issuance": supply.Issuance, "burned": supply.Burned, "tips": supply.Tips}
+	fields := make(map[string]interface{}, len(amounts)+1)
+	for field, amount := range amounts {
+		value, _ := new(big.Int).SetString(current[field], 10)
+		if value == nil {
+			value = new(big.Int)
+		}
+		fields[field] = value.Add(value, amount).String()
+	}
+	blocks, _ := parseInt64(current["blocks"])
+	fields["blocks"] = blocks + 1
+
+	if err := st.client.HMSet(key, fields); err != nil {
+		return err
+	}
+	if expiration > 0 {
+		return st.client.Expire(key, expiration)
+	}
+	return nil
+}
+
+// Totals 获取网络自开始统计以来的累计值
+func (st *SupplyTracker) Totals(network string) (*SupplyTotals, error) {
+	values, err := st.client.HGetAll(supplyTotalsKey(network))
+	if err != nil {
+		return nil, err
+	}
+	return newSupplyTotals(network, "", values), nil
+}
+
+// Daily 获取[start, end]日期范围内的每日统计，没有数据的日期不返回
+func (st *SupplyTracker) Daily(network string, start, end time.Time) ([]*SupplyTotals, error) {
+	days := []*SupplyTotals{}
+	for day := start.UTC().Truncate(24 * time.Hour); !day.After(end); day = day.Add(24 * time.Hour) {
+		date := day.Format(tokenFlowDateLayout)
+		values, err := st.client.HGetAll(supplyDailyKey(network, date))
+		if err != nil {
+			return nil, err
+		}
+		if len(values) == 0 {
+			continue
+		}
+		days = append(days, newSupplyTotals(network, date, values))
+	}
+	return days, nil
+}
+
+// newSupplyTotals 从统计字段构造结果并计算净变化
+func newSupplyTotals(network, date string, values map[string]string) *SupplyTotals {
+	amounts := make(map[string]*big.Int, len(supplyFields))
+	for _, field := range supplyFields {
+		value, ok := new(big.Int).SetString(values[field], 10)
+		if !ok {
+			value = new(big.Int)
+		}
+		amounts[field] = value
+	}
+	blocks, _ := parseInt64(values["blocks"])
+
+	return &SupplyTotals{
+		Network:   network,
+		Date:      date,
+		Blocks:    blocks,
+		Issuance:  amounts["issuance"].String(),
+		Burned:    amounts["burned"].String(),
+		Tips:      amounts["tips"].String(),
+		NetChange: new(big.Int).Sub(amounts["issuance"], amounts["burned"]).String(),
+	}
+}
+
+func supplyTotalsKey(network string) string {
+	return fmt.Sprintf("%s:%s", supplyKeyPrefix, network)
+}
+
+func supplyDailyKey(network, date string) string {
+	return fmt.Sprintf("%s:%s:%s", supplyKeyPrefix, network, date)
+}
+
+// RecordBlockSupply 统计区块的供应量变化并更新指标，区块已统计过时只返回计算结果
+func (dp *DataProcessor) RecordBlockSupply(block *models.Block) (*models.BlockSupply, error) {
+	supply := dp.supply.Compute(block)
+	recorded, err := dp.supply.Record(supply)
+	if err != nil {
+		return supply, err
+	}
+
+	if recorded && dp.metricsManager != nil {
+		currency := dp.currencies.Get(block.Network)
+		for kind, amount := range map[string]*big.Int{"issued": supply.Issuance, "burned": supply.Burned, "tips": supply.Tips} {
+			units, _ := currency.ToUnits(amount).Float64()
+			dp.metricsManager.RecordNativeSupply(block.Network, kind, units)
+		}
+	}
+	return supply, nil
+}
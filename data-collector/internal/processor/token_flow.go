@@ -0,0 +1,226 @@
+package processor
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+)
+
+// 实体类别，未标注的地址归为unknown
+const (
+	EntityExchange = "exchange"
+	EntityDeFi     = "defi"
+	EntityBridge   = "bridge"
+	EntityUnknown  = "unknown"
+)
+
+// tokenFlowKeyPrefix 按天汇总在Redis中的键前缀，按 网络:代币:日期 存储，
+// 字段为 来源类别>目标类别（转账量）及 来源类别>目标类别:count（转账次数）
+const tokenFlowKeyPrefix = "token_flow"
+
+// tokenFlowDateLayout 汇总日期格式（UTC）
+const tokenFlowDateLayout = "2006-01-02"
+
+// CategoryFlow 两个实体类别之间的转账汇总，金额为代币最小单位
+type CategoryFlow struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Volume    string `json:"volume"`
+	Transfers int64  `json:"transfers"`
+}
+
+// TokenFlowDay 单个代币一天的流向汇总
+type TokenFlowDay struct {
+	Network  string          `json:"network"`
+	Token    string          `json:"token"`
+	Date     string          `json:"date"`
+	Flows    []*CategoryFlow `json:"flows"`     // 有向流量
+	NetFlows []*CategoryFlow `json:"net_flows"` // 不同类别之间的净流量，方向为净流入的一方
+}
+
+// flowTotal 汇总中的一个方向
+type flowTotal struct {
+	volume    *big.Int
+	transfers int64
+}
+
+// TokenFlowAggregator 按代币、按天汇总实体类别之间的ERC-20转账
+type TokenFlowAggregator struct {
+	client    *database.RedisClient
+	labels    map[string]string // 小写地址 -> 类别
+	retention time.Duration
+}
+
+// NewTokenFlowAggregator 根据配置创建流向汇总，未启用时返回nil
+func NewTokenFlowAggregator(cfg config.TokenFlowConfig, redisClient *database.RedisClient) (*TokenFlowAggregator, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	retention := 90 * 24 * time.Hour
+	if cfg.Retention != "" {
+		parsed, err := time.ParseDuration(cfg.Retention)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token_flows retention: %w", err)
+		}
+		retention = parsed
+	}
+
+	labels := make(map[string]string, len(cfg.Entities))
+	for _, entity := range cfg.Entities {
+		labels[strings.ToLower(entity.Address)] = entity.Category
+	}
+
+	return &TokenFlowAggregator{
+		client:    redisClient,
+		labels:    labels,
+		retention: retention,
+	}, nil
+}
+
+// Category 获取地址的实体类别
+func (a *TokenFlowAggregator) Category(address string) string {
+	if category, exists := a.labels[strings.ToLower(address)]; exists {
+		return category
+	}
+	return EntityUnknown
+}
+
+// RecordBlock 将一个区块内的转账累加到按天汇总，同一代币同一天只读写一次
+func (a *TokenFlowAggregator) RecordBlock(transfers []*TokenTransfer) error {
+	pending := make(map[string]map[string]*flowTotal)
+	for _, transfer := range transfers {
+		if transfer.Amount == nil || transfer.Amount.Sign() == 0 {
+			continue
+		}
+
+		key := tokenFlowKey(transfer.Network, transfer.Token, transfer.Timestamp.UTC().Format(tokenFlowDateLayout))
+		if pending[key] == nil {
+			pending[key] = make(map[string]*flowTotal)
+		}
+
+		pair := a.Category(transfer.From) + ">" + a.Category(transfer.To)
+		total, exists := pending[key][pair]
+		if !exists {
+			total = &flowTotal{volume: new(big.Int)}
+			pending[key][pair] = total
+		}
+		total.volume.Add(total.volume, transfer.Amount)
+		total.transfers++
+	}
+
+	for key, pairs := range pending {
+		if err := a.add(key, pairs); err != nil {
+			return fmt.Errorf("failed to update %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// add 将增量合并到已有汇总，转账量超出int64范围，因此读出后以十进制字符串写回
+func (a *TokenFlowAggregator) add(key string, pairs map[string]*flowTotal) error {
+	current, err := a.client.HGetAll(key)
+	if err != nil {
+		return err
+	}
+
+	fields := make(map[string]interface{}, len(pairs)*2)
+	for pair, total := range pairs {
+		volume, _ := new(big.Int).SetString(current[pair], 10)
+		if volume == nil {
+			volume = new(big.Int)
+		}
+		transfers, _ := strconv.ParseInt(current[pair+":count"], 10, 64)
+
+		fields[pair] = volume.Add(volume, total.volume).String()
+		fields[pair+":count"] = transfers + total.transfers
+	}
+
+	if err := a.client.HMSet(key, fields); err != nil {
+		return err
+	}
+	return a.client.Expire(key, a.retention)
+}
+
+// Query 获取代币在[start, end]日期范围内的每日流向，没有转账的日期不返回
+func (a *TokenFlowAggregator) Query(network, token string, start, end time.Time) ([]*TokenFlowDay, error) {
+	var days []*TokenFlowDay
+	for day := start.UTC().Truncate(24 * time.Hour); !day.After(end); day = day.Add(24 * time.Hour) {
+		date := day.Format(tokenFlowDateLayout)
+		values, err := a.client.HGetAll(tokenFlowKey(network, token, date))
+		if err != nil {
+			return nil, err
+		}
+		if len(values) == 0 {
+			continue
+		}
+		days = append(days, newTokenFlowDay(network, token, date, values))
+	}
+	return days, nil
+}
+
+// newTokenFlowDay 从汇总字段构造有向流量并计算类别之间的净流量
+func newTokenFlowDay(network, token, date string, values map[string]string) *TokenFlowDay {
+	day := &TokenFlowDay{Network: network, Token: token, Date: date, Flows: []*CategoryFlow{}, NetFlows: []*CategoryFlow{}}
+
+	totals := make(map[string]*flowTotal)
+	for field, value := range values {
+		if strings.HasSuffix(field, ":count") {
+			continue
+		}
+		from, to, ok := strings.Cut(field, ">")
+		if !ok {
+			continue
+		}
+		volume, ok := new(big.Int).SetString(value, 10)
+		if !ok {
+			continue
+		}
+		transfers, _ := strconv.ParseInt(values[field+":count"], 10, 64)
+
+		totals[field] = &flowTotal{volume: volume, transfers: transfers}
+		day.Flows = append(day.Flows, &CategoryFlow{From: from, To: to, Volume: value, Transfers: transfers})
+	}
+	sort.Slice(day.Flows, func(i, j int) bool {
+		if day.Flows[i].From != day.Flows[j].From {
+			return day.Flows[i].From < day.Flows[j].From
+		}
+		return day.Flows[i].To < day.Flows[j].To
+	})
+
+	// 每对类别只计算一次，同类别之间的转账没有净流量
+	seen := make(map[string]bool)
+	for _, flow := range day.Flows {
+		if flow.From == flow.To || seen[flow.To+">"+flow.From] {
+			continue
+		}
+		seen[flow.From+">"+flow.To] = true
+
+		net := new(big.Int).Set(totals[flow.From+">"+flow.To].volume)
+		transfers := flow.Transfers
+		if reverse, exists := totals[flow.To+">"+flow.From]; exists {
+			net.Sub(net, reverse.volume)
+			transfers += reverse.transfers
+		}
+
+		netFlow := &CategoryFlow{From: flow.From, To: flow.To, Transfers: transfers}
+		if net.Sign() < 0 {
+			netFlow.From, netFlow.To = flow.To, flow.From
+			net.Neg(net)
+		}
+		netFlow.Volume = net.String()
+		day.NetFlows = append(day.NetFlows, netFlow)
+	}
+
+	return day
+}
+
+func tokenFlowKey(network, token, date string) string {
+	return fmt.Sprintf("%s:%s:%s:%s", tokenFlowKeyPrefix, network, strings.ToLower(token), date)
+}
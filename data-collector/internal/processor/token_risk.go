@@ -0,0 +1,407 @@
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/faults"
+	"web3-data-collector/internal/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// tokenRiskKeyPrefix 代币风险记录在Redis中的键前缀：token_risk:{network}:{token} 为TokenRiskProfile的JSON，
+// token_risk:pair:{network}:{pair} 为交易对中被分析代币的地址及位置（{token}:{index}）
+const tokenRiskKeyPrefix = "token_risk"
+
+// tokenRiskWeights 各风险特征计入风险分的权重
+var tokenRiskWeights = map[string]float64{
+	models.TokenRiskOwnerActive:     0.2,
+	models.TokenRiskMint:            0.3,
+	models.TokenRiskTradingControl:  0.4,
+	models.TokenRiskBlacklist:       0.3,
+	models.TokenRiskFeeControl:      0.2,
+	models.TokenRiskLiquidityPulled: 1.0,
+}
+
+// ownerControlledTokenRisks 需要owner权限才能使用的特征，所有权放弃后不计入风险分
+var ownerControlledTokenRisks = map[string]bool{
+	models.TokenRiskMint:           true,
+	models.TokenRiskTradingControl: true,
+	models.TokenRiskBlacklist:      true,
+	models.TokenRiskFeeControl:     true,
+}
+
+// 代币合约的函数选择器
+var (
+	erc20RequiredSelectors = tokenSelectors("transfer(address,uint256)", "balanceOf(address)", "totalSupply()")
+	tokenOwnerSelectors    = tokenSelectors("owner()", "getOwner()")
+	tokenFeatureSelectors  = map[string][][4]byte{
+		models.TokenRiskMint: tokenSelectors(
+			"mint(address,uint256)", "mint(uint256)", "mintTo(address,uint256)", "issue(uint256)",
+		),
+		models.TokenRiskTradingControl: tokenSelectors(
+			"enableTrading()", "openTrading()", "setTradingEnabled(bool)", "setTrading(bool)",
+			"setSwapEnabled(bool)", "pause()",
+		),
+		models.TokenRiskBlacklist: tokenSelectors(
+			"blacklist(address)", "addToBlacklist(address)", "setBlacklist(address,bool)",
+			"addBots(address[])", "setBots(address[],bool)", "blockBots(address[])",
+		),
+		models.TokenRiskFeeControl: tokenSelectors(
+			"setFee(uint256)", "setTaxFee(uint256)", "setFees(uint256,uint256)", "setTaxes(uint256,uint256)",
+			"setMaxTxAmount(uint256)", "updateFees(uint256,uint256)",
+		),
+	}
+)
+
+// tokenRiskFeatureOrder 特征的输出顺序
+var tokenRiskFeatureOrder = []string{
+	models.TokenRiskMint,
+	models.TokenRiskTradingControl,
+	models.TokenRiskBlacklist,
+	models.TokenRiskFeeControl,
+}
+
+// TokenCode 代币合约运行时字节码的分析结果
+type TokenCode struct {
+	IsToken  bool     // 包含ERC-20的transfer、balanceOf及totalSupply
+	HasOwner bool     // 包含owner()或getOwner()
+	Features []string // 可被owner操纵的函数对应的风险特征
+}
+
+// AnalyzeTokenCode 扫描字节码中PUSH4的函数选择器，识别ERC-20代币及增发、开关交易、黑名单、改税等函数；
+// 代理合约只能看到代理本身的选择器
+func AnalyzeTokenCode(code []byte) *TokenCode {
+	selectors := make(map[[4]byte]bool)
+	for i := 0; i < len(code); i++ {
+		op := code[i]
+		if op < 0x60 || op > 0x7f {
+			continue
+		}
+		size := int(op - 0x5f)
+		if op == 0x63 && i+4 < len(code) {
+			var selector [4]byte
+			copy(selector[:], code[i+1:i+5])
+			selectors[selector] = true
+		}
+		i += size
+	}
+
+	result := &TokenCode{IsToken: true}
+	for _, selector := range erc20RequiredSelectors {
+		if !selectors[selector] {
+			result.IsToken = false
+		}
+	}
+	for _, selector := range tokenOwnerSelectors {
+		result.HasOwner = result.HasOwner || selectors[selector]
+	}
+	for _, feature := range tokenRiskFeatureOrder {
+		for _, selector := range tokenFeatureSelectors[feature] {
+			if selectors[selector] {
+				result.Features = append(result.Features, feature)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// TokenInteraction 地址与代币合约的交互：代币转账或直接调用代币合约
+type TokenInteraction struct {
+	ID              string // 转账为 models.TransferID，调用为交易ID
+	Network         string
+	Token           string
+	Kind            string // transfer / call
+	From            string
+	To              string // 调用时为空
+	Amount          *big.Int
+	TransactionHash string
+	BlockNumber     uint64
+	Timestamp       time.Time
+}
+
+// TokenRiskMonitor 分析新部署的代币合约的跑路/貔貅特征，跟踪所有权变更及流动性撤出
+type TokenRiskMonitor struct {
+	client    *database.RedisClient
+	threshold float64
+	pullRatio float64
+	retention time.Duration
+}
+
+// NewTokenRiskMonitor 根据配置创建代币风险检测，未启用时返回nil
+func NewTokenRiskMonitor(cfg config.TokenRiskConfig, redisClient *database.RedisClient) (*TokenRiskMonitor, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	retention := 90 * 24 * time.Hour
+	if cfg.Retention != "" {
+		parsed, err := time.ParseDuration(cfg.Retention)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token_risk retention: %w", err)
+		}
+		retention = parsed
+	}
+
+	monitor := &TokenRiskMonitor{
+		client:    redisClient,
+		threshold: cfg.Threshold,
+		pullRatio: cfg.LiquidityPull,
+		retention: retention,
+	}
+	if monitor.threshold <= 0 {
+		monitor.threshold = 0.6
+	}
+	if monitor.pullRatio <= 0 {
+		monitor.pullRatio = 0.8
+	}
+	return monitor, nil
+}
+
+// PullRatio 单笔撤出交易对中另一资产的比例达到该值时视为撤池
+func (m *TokenRiskMonitor) PullRatio() float64 {
+	return m.pullRatio
+}
+
+// Profile 获取代币的分析结果，未分析过时返回false
+func (m *TokenRiskMonitor) Profile(network, token string) (*models.TokenRiskProfile, bool, error) {
+	value, exists, err := m.client.GetIfExists(tokenRiskKey(network, token))
+	if err != nil || !exists {
+		return nil, false, err
+	}
+
+	var profile models.TokenRiskProfile
+	if err := json.Unmarshal([]byte(value), &profile); err != nil {
+		return nil, false, fmt.Errorf("invalid token risk profile %s: %w", token, err)
+	}
+	return &profile, true, nil
+}
+
+// Record 保存代币字节码及owner的分析结果，owner查询失败（为空）时按未放弃所有权处理
+func (m *TokenRiskMonitor) Record(profile *models.TokenRiskProfile, code *TokenCode) error {
+	profile.Token = common.HexToAddress(profile.Token).Hex()
+	profile.Flags = append([]string{}, code.Features...)
+	profile.OwnerRenounced = !code.HasOwner || (profile.Owner != "" && isZeroAddress(profile.Owner))
+	if !profile.OwnerRenounced {
+		profile.Flags = append([]string{models.TokenRiskOwnerActive}, profile.Flags...)
+	}
+	profile.AnalyzedAt = time.Now()
+	return m.save(profile)
+}
+
+// UpdateOwner 记录已分析代币的所有权变更，新owner为零地址时视为放弃所有权；代币未分析过时返回false
+func (m *TokenRiskMonitor) UpdateOwner(network, token, owner string) (bool, error) {
+	profile, exists, err := m.Profile(network, token)
+	if err != nil || !exists {
+		return false, err
+	}
+
+	profile.Owner = owner
+	profile.OwnerRenounced = isZeroAddress(owner)
+	profile.Flags = removeString(profile.Flags, models.TokenRiskOwnerActive)
+	if !profile.OwnerRenounced {
+		profile.Flags = append([]string{models.TokenRiskOwnerActive}, profile.Flags...)
+	}
+	return true, m.save(profile)
+}
+
+// RecordPair 记录已分析代币的交易对，index为代币在交易对中的位置（0或1）
+func (m *TokenRiskMonitor) RecordPair(network, pair, token string, index int) error {
+	return m.client.Set(tokenRiskPairKey(network, pair), fmt.Sprintf("%s:%d", common.HexToAddress(token).Hex(), index), m.retention)
+}
+
+// PairToken 获取交易对中被分析的代币及其位置，交易对未记录时返回false
+func (m *TokenRiskMonitor) PairToken(network, pair string) (string, int, bool, error) {
+	value, exists, err := m.client.GetIfExists(tokenRiskPairKey(network, pair))
+	if err != nil || !exists {
+		return "", 0, false, err
+	}
+
+	separator := strings.LastIndex(value, ":")
+	if separator < 0 {
+		return "", 0, false, fmt.Errorf("invalid token risk pair %s: %s", pair, value)
+	}
+	index, err := strconv.Atoi(value[separator+1:])
+	if err != nil {
+		return "", 0, false, fmt.Errorf("invalid token risk pair %s: %s", pair, value)
+	}
+	return value[:separator], index, true, nil
+}
+
+// RecordLiquidityPull 记录代币交易对的大比例流动性撤出，返回更新后的分析结果；代币未分析过时返回nil
+func (m *TokenRiskMonitor) RecordLiquidityPull(network, token, pair, txHash string, ratio float64, at time.Time) (*models.TokenRiskProfile, error) {
+	profile, exists, err := m.Profile(network, token)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	if !containsString(profile.Flags, models.TokenRiskLiquidityPulled) {
+		profile.Flags = append(profile.Flags, models.TokenRiskLiquidityPulled)
+	}
+	profile.LiquidityPair = pair
+	profile.LiquidityPullTx = txHash
+	profile.LiquidityPullRatio = ratio
+	profile.LiquidityPulledAt = &at
+	return profile, m.save(profile)
+}
+
+// save 计算风险分并保存，每次更新刷新保留时长
+func (m *TokenRiskMonitor) save(profile *models.TokenRiskProfile) error {
+	score := 0.0
+	for _, flag := range profile.Flags {
+		if profile.OwnerRenounced && ownerControlledTokenRisks[flag] {
+			continue
+		}
+		score += tokenRiskWeights[flag]
+	}
+	profile.Score = math.Min(score, 1)
+	profile.Flagged = score >= m.threshold
+	profile.UpdatedAt = time.Now()
+
+	data, err := json.Marshal(profile)
+	if err != nil {
+		return err
+	}
+	return m.client.Set(tokenRiskKey(profile.Network, profile.Token), data, m.retention)
+}
+
+// ProcessTokenInteractions 关注地址与可疑代币交互时发布TOKEN_RISK告警，profiles为交互涉及代币的分析结果
+func (dp *DataProcessor) ProcessTokenInteractions(interactions []*TokenInteraction, profiles map[string]*models.TokenRiskProfile) []*models.RiskAlert {
+	if dp.watchlists == nil {
+		return nil
+	}
+
+	var alerts []*models.RiskAlert
+	for _, interaction := range interactions {
+		profile, exists := profiles[interaction.Token]
+		if !exists || !profile.Flagged {
+			continue
+		}
+
+		parties := map[string]string{"from": interaction.From}
+		if interaction.Kind == "transfer" {
+			parties = map[string]string{"token_from": interaction.From, "token_to": interaction.To}
+		}
+		hits, err := dp.watchlists.Match(interaction.Network, parties)
+		if err != nil {
+			faults.Log(err, interaction.Network, interaction.BlockNumber, fmt.Sprintf("Failed to match watchlists for token interaction %s", interaction.ID))
+			dp.metricsManager.RecordError(err, interaction.Network, interaction.BlockNumber)
+			continue
+		}
+		if len(hits) == 0 {
+			continue
+		}
+
+		alert := dp.createTokenRiskAlert(interaction, profile, hits)
+		logger.Warnf("%s: %s", alert.Title, alert.Description)
+		if err := dp.PublishOpsAlert(alert); err != nil {
+			faults.Log(err, interaction.Network, interaction.BlockNumber, fmt.Sprintf("Failed to publish token risk alert for %s", interaction.TransactionHash))
+			continue
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts
+}
+
+// createTokenRiskAlert 创建可疑代币交互告警，地址为第一个命中的关注地址；已撤池的代币为CRITICAL
+func (dp *DataProcessor) createTokenRiskAlert(interaction *TokenInteraction, profile *models.TokenRiskProfile, hits []WatchHit) *models.RiskAlert {
+	level := "HIGH"
+	if containsString(profile.Flags, models.TokenRiskLiquidityPulled) {
+		level = "CRITICAL"
+	}
+
+	watched := make([]map[string]string, 0, len(hits))
+	for _, hit := range hits {
+		watched = append(watched, map[string]string{
+			"address":   hit.Address.Address,
+			"label":     hit.Address.Label,
+			"role":      hit.Role,
+			"watchlist": hit.Watchlist.Name,
+		})
+	}
+
+	alert := &models.RiskAlert{
+		ID:              models.AlertID("TOKEN_RISK", interaction.ID),
+		Type:            "TOKEN_RISK",
+		Level:           level,
+		Title:           "关注地址与可疑代币交互",
+		Description:     fmt.Sprintf("关注地址 %s 与风险分 %.2f 的代币 %s 交互（%s）", hits[0].Address.Address, profile.Score, profile.Token, strings.Join(profile.Flags, "、")),
+		TransactionHash: interaction.TransactionHash,
+		Address:         hits[0].Address.Address,
+		Network:         interaction.Network,
+		RiskScore:       profile.Score,
+		RiskFactors:     append([]string{"token_risk"}, profile.Flags...),
+		Metadata: map[string]interface{}{
+			"block_number":     interaction.BlockNumber,
+			"token":            profile.Token,
+			"interaction":      interaction.Kind,
+			"from_address":     interaction.From,
+			"token_risk_score": profile.Score,
+			"owner_renounced":  profile.OwnerRenounced,
+			"watched":          watched,
+		},
+		Timestamp: interaction.Timestamp,
+		Status:    "ACTIVE",
+	}
+
+	if interaction.To != "" {
+		alert.Metadata["to_address"] = interaction.To
+	}
+	if interaction.Amount != nil {
+		alert.Metadata["amount"] = interaction.Amount.String()
+	}
+	if profile.Creator != "" {
+		alert.Metadata["token_creator"] = profile.Creator
+	}
+	if profile.LiquidityPullTx != "" {
+		alert.Metadata["liquidity_pull_tx"] = profile.LiquidityPullTx
+		alert.Metadata["liquidity_pull_ratio"] = profile.LiquidityPullRatio
+	}
+
+	return alert
+}
+
+// tokenSelectors 计算函数签名的选择器
+func tokenSelectors(signatures ...string) [][4]byte {
+	selectors := make([][4]byte, 0, len(signatures))
+	for _, signature := range signatures {
+		var selector [4]byte
+		copy(selector[:], crypto.Keccak256([]byte(signature))[:4])
+		selectors = append(selectors, selector)
+	}
+	return selectors
+}
+
+func tokenRiskKey(network, token string) string {
+	return fmt.Sprintf("%s:%s:%s", tokenRiskKeyPrefix, network, common.HexToAddress(token).Hex())
+}
+
+func tokenRiskPairKey(network, pair string) string {
+	return fmt.Sprintf("%s:pair:%s:%s", tokenRiskKeyPrefix, network, common.HexToAddress(pair).Hex())
+}
+
+// isZeroAddress owner为空或零地址
+func isZeroAddress(address string) bool {
+	return address == "" || common.HexToAddress(address) == (common.Address{})
+}
+
+// removeString 移除切片中的指定值
+func removeString(values []string, value string) []string {
+	result := values[:0]
+	for _, v := range values {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,53 @@
+package processor
+
+import (
+	"fmt"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/models"
+)
+
+// seenTransactionKeyPrefix 最近处理过的交易在Redis中的键前缀，按 网络:交易哈希:区块哈希 存储
+const seenTransactionKeyPrefix = "seen_tx"
+
+// TransactionDedup 最近处理过的交易记录，用于抑制WebSocket与轮询重叠及重放区块造成的重复发布。
+// 与记录ID一致，区块哈希不同（重组）的同一交易视为新记录；记录保存在Redis，可在多实例间共享
+type TransactionDedup struct {
+	client *database.RedisClient
+	ttl    time.Duration
+}
+
+// NewTransactionDedup 根据配置创建重复交易记录，未启用时返回nil
+func NewTransactionDedup(cfg config.TxDedupConfig, redisClient *database.RedisClient) (*TransactionDedup, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	ttl := time.Hour
+	if cfg.TTL != "" {
+		parsed, err := time.ParseDuration(cfg.TTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tx_dedup ttl: %w", err)
+		}
+		ttl = parsed
+	}
+
+	return &TransactionDedup{client: redisClient, ttl: ttl}, nil
+}
+
+// Mark 记录交易，最近已记录过时返回false
+func (d *TransactionDedup) Mark(tx *models.Transaction) (bool, error) {
+	return d.client.SetNX(d.redisKey(tx), tx.BlockNumber, d.ttl)
+}
+
+// Forget 移除交易记录，处理失败时调用以允许重试
+func (d *TransactionDedup) Forget(tx *models.Transaction) error {
+	_, err := d.client.DeleteIfExists(d.redisKey(tx))
+	return err
+}
+
+func (d *TransactionDedup) redisKey(tx *models.Transaction) string {
+	return fmt.Sprintf("%s:%s:%s:%s", seenTransactionKeyPrefix, tx.Network, tx.Hash, tx.BlockHash)
+}
@@ -0,0 +1,352 @@
+package processor
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/models"
+)
+
+// velocityKeyPrefix 地址转出统计在Redis中的键前缀，按 网络:地址 存储
+const velocityKeyPrefix = "velocity"
+
+// velocityBaselineAlpha 每个窗口计入历史平均（指数移动平均）的权重
+const velocityBaselineAlpha = 0.1
+
+// VelocityObservation 地址在滑动窗口内的转出情况
+type VelocityObservation struct {
+	Network              string   `json:"network"`
+	Address              string   `json:"address"`
+	Window               string   `json:"window"`
+	Transactions         float64  `json:"transactions"`          // 滑动窗口内的估算笔数
+	Volume               *big.Int `json:"volume"`                // 滑动窗口内的估算转出金额
+	BaselineTransactions float64  `json:"baseline_transactions"` // 历史每窗口平均笔数
+	BaselineVolume       float64  `json:"baseline_volume"`       // 历史每窗口平均转出金额
+	Burst                bool     `json:"burst"`                 // 笔数突增
+	VolumeBurst          bool     `json:"volume_burst"`          // 金额突增
+}
+
+// DrainCandidate 窗口内转出金额达到阈值、需要检查余额是否被转空的地址
+type DrainCandidate struct {
+	Network         string
+	Address         string
+	TransactionHash string // 窗口内最后一笔转出
+	TransactionID   string
+	BlockNumber     uint64
+	WindowStart     time.Time
+	Transactions    int64
+	Volume          *big.Int
+	Timestamp       time.Time
+}
+
+// velocityState 地址在Redis中的统计，使用当前与上一个固定窗口近似滑动窗口
+type velocityState struct {
+	bucket          int64 // 当前窗口开始时间
+	count           int64
+	volume          *big.Int
+	prevCount       int64
+	prevVolume      *big.Int
+	avgCount        float64
+	avgVolume       float64
+	windows         int64 // 已计入平均的窗口数
+	alerted         int64 // 最近一次突增告警所在窗口
+	drained         int64 // 最近一次余额清空告警所在窗口
+	lastTransaction string
+}
+
+// VelocityTracker 在Redis中按滑动窗口统计地址的转出笔数与金额，与地址自身的历史平均比较
+type VelocityTracker struct {
+	client          *database.RedisClient
+	currencies      *CurrencyRegistry
+	clocks          *ClockRegistry
+	window          time.Duration
+	multiplier      float64
+	minTransactions float64
+	minHistory      int64
+	ttl             time.Duration
+	drainMinValue   string
+
+	mu         sync.Mutex
+	candidates map[string]map[string]*DrainCandidate // 网络 -> 地址 -> 待检查余额的地址
+}
+
+// NewVelocityTracker 根据配置创建转出频率跟踪器，未启用时返回nil
+func NewVelocityTracker(cfg config.VelocityConfig, redisClient *database.RedisClient, currencies *CurrencyRegistry, clocks *ClockRegistry) (*VelocityTracker, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	tracker := &VelocityTracker{
+		client:          redisClient,
+		currencies:      currencies,
+		clocks:          clocks,
+		window:          10 * time.Minute,
+		multiplier:      cfg.Multiplier,
+		minTransactions: float64(cfg.MinTransactions),
+		minHistory:      int64(cfg.MinHistory),
+		ttl:             30 * 24 * time.Hour,
+		drainMinValue:   cfg.DrainMinValue,
+		candidates:      make(map[string]map[string]*DrainCandidate),
+	}
+
+	if cfg.Window != "" {
+		window, err := time.ParseDuration(cfg.Window)
+		if err != nil || window < time.Second {
+			return nil, fmt.Errorf("invalid velocity window: %q", cfg.Window)
+		}
+		tracker.window = window
+	}
+	if cfg.HistoryTTL != "" {
+		ttl, err := time.ParseDuration(cfg.HistoryTTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid velocity history_ttl: %w", err)
+		}
+		tracker.ttl = ttl
+	}
+	if tracker.multiplier <= 1 {
+		tracker.multiplier = 5
+	}
+
+	return tracker, nil
+}
+
+// Observe 记录交易发起方的一笔转出，返回滑动窗口内的统计；重复处理的交易返回nil
+func (vt *VelocityTracker) Observe(tx *models.Transaction) (*VelocityObservation, error) {
+	if tx.FromAddress == "" {
+		return nil, nil
+	}
+
+	key := velocityKey(tx.Network, tx.FromAddress)
+	current, err := vt.client.HGetAll(key)
+	if err != nil {
+		return nil, err
+	}
+	if current["last_transaction"] == tx.Hash {
+		return nil, nil
+	}
+
+	// 按规则时间（默认为接收时间）划分窗口，避免被操纵的区块时间把交易分散到不同窗口
+	at := vt.clocks.RuleTime(tx)
+	state := parseVelocityState(current)
+	windowSeconds := int64(vt.window / time.Second)
+	bucket := at.Truncate(vt.window).Unix()
+	vt.roll(state, bucket, windowSeconds)
+
+	state.count++
+	if tx.Value != nil {
+		state.volume.Add(state.volume, tx.Value)
+	}
+	state.lastTransaction = tx.Hash
+
+	// 上一个窗口按未过去的比例计入，近似以当前交易为终点的滑动窗口
+	remaining := 1 - float64(at.Unix()-state.bucket)/float64(windowSeconds)
+	remaining = math.Max(0, math.Min(1, remaining))
+	prevVolume, _ := new(big.Float).Mul(new(big.Float).SetInt(state.prevVolume), big.NewFloat(remaining)).Int(nil)
+
+	observation := &VelocityObservation{
+		Network:              tx.Network,
+		Address:              tx.FromAddress,
+		Window:               vt.window.String(),
+		Transactions:         float64(state.count) + float64(state.prevCount)*remaining,
+		Volume:               new(big.Int).Add(state.volume, prevVolume),
+		BaselineTransactions: state.avgCount,
+		BaselineVolume:       state.avgVolume,
+	}
+
+	drainThreshold := vt.currencies.Get(tx.Network).parseUnits(vt.drainMinValue)
+	if drainThreshold == nil {
+		drainThreshold = new(big.Int)
+	}
+
+	// 历史不足时只积累基线，同一窗口只告警一次
+	if state.windows >= vt.minHistory && state.alerted != state.bucket {
+		volume, _ := new(big.Float).SetInt(observation.Volume).Float64()
+		observation.Burst = observation.Transactions >= vt.minTransactions &&
+			observation.Transactions >= vt.multiplier*state.avgCount
+		observation.VolumeBurst = drainThreshold.Sign() > 0 && observation.Volume.Cmp(drainThreshold) >= 0 &&
+			volume >= vt.multiplier*state.avgVolume
+		if observation.Burst || observation.VolumeBurst {
+			state.alerted = state.bucket
+		}
+	}
+
+	if drainThreshold.Sign() > 0 && observation.Volume.Cmp(drainThreshold) >= 0 && state.drained != state.bucket {
+		vt.addCandidate(&DrainCandidate{
+			Network:         tx.Network,
+			Address:         tx.FromAddress,
+			TransactionHash: tx.Hash,
+			TransactionID:   tx.ID,
+			BlockNumber:     tx.BlockNumber,
+			WindowStart:     time.Unix(state.bucket, 0),
+			Transactions:    state.count + state.prevCount,
+			Volume:          observation.Volume,
+			Timestamp:       tx.Timestamp,
+		})
+	}
+
+	if err := vt.client.HMSet(key, state.fields()); err != nil {
+		return nil, err
+	}
+	if err := vt.client.Expire(key, vt.ttl); err != nil {
+		return nil, err
+	}
+	return observation, nil
+}
+
+// roll 交易进入新窗口时将已结束的窗口计入历史平均，其间没有交易的窗口按0计入
+func (vt *VelocityTracker) roll(state *velocityState, bucket, windowSeconds int64) {
+	if state.bucket == 0 {
+		state.bucket = bucket
+		return
+	}
+	// 乱序到达的较早交易计入当前窗口
+	if bucket <= state.bucket {
+		return
+	}
+
+	elapsed := (bucket - state.bucket) / windowSeconds
+	volume, _ := new(big.Float).SetInt(state.volume).Float64()
+	state.avgCount += velocityBaselineAlpha * (float64(state.count) - state.avgCount)
+	state.avgVolume += velocityBaselineAlpha * (volume - state.avgVolume)
+	if elapsed > 1 {
+		decay := math.Pow(1-velocityBaselineAlpha, float64(elapsed-1))
+		state.avgCount *= decay
+		state.avgVolume *= decay
+	}
+	state.windows += elapsed
+
+	if elapsed == 1 {
+		state.prevCount, state.prevVolume = state.count, state.volume
+	} else {
+		state.prevCount, state.prevVolume = 0, new(big.Int)
+	}
+	state.bucket = bucket
+	state.count, state.volume = 0, new(big.Int)
+}
+
+func (vt *VelocityTracker) addCandidate(candidate *DrainCandidate) {
+	vt.mu.Lock()
+	defer vt.mu.Unlock()
+
+	if vt.candidates[candidate.Network] == nil {
+		vt.candidates[candidate.Network] = make(map[string]*DrainCandidate)
+	}
+	vt.candidates[candidate.Network][strings.ToLower(candidate.Address)] = candidate
+}
+
+// TakeDrainCandidates 取出网络中待检查余额的地址，每个地址只保留最后一笔转出
+func (vt *VelocityTracker) TakeDrainCandidates(network string) []*DrainCandidate {
+	vt.mu.Lock()
+	pending := vt.candidates[network]
+	delete(vt.candidates, network)
+	vt.mu.Unlock()
+
+	candidates := make([]*DrainCandidate, 0, len(pending))
+	for _, candidate := range pending {
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+// MarkDrained 记录地址在本窗口已发出余额清空告警
+func (vt *VelocityTracker) MarkDrained(candidate *DrainCandidate) error {
+	return vt.client.HSet(velocityKey(candidate.Network, candidate.Address), "drained", candidate.WindowStart.Unix())
+}
+
+func parseVelocityState(values map[string]string) *velocityState {
+	state := &velocityState{
+		volume:          parseBigInt(values["volume"]),
+		prevVolume:      parseBigInt(values["prev_volume"]),
+		lastTransaction: values["last_transaction"],
+	}
+	state.bucket, _ = strconv.ParseInt(values["bucket"], 10, 64)
+	state.count, _ = strconv.ParseInt(values["count"], 10, 64)
+	state.prevCount, _ = strconv.ParseInt(values["prev_count"], 10, 64)
+	state.avgCount, _ = strconv.ParseFloat(values["avg_count"], 64)
+	state.avgVolume, _ = strconv.ParseFloat(values["avg_volume"], 64)
+	state.windows, _ = strconv.ParseInt(values["windows"], 10, 64)
+	state.alerted, _ = strconv.ParseInt(values["alerted"], 10, 64)
+	state.drained, _ = strconv.ParseInt(values["drained"], 10, 64)
+	return state
+}
+
+func (s *velocityState) fields() map[string]interface{} {
+	return map[string]interface{}{
+		"bucket":           s.bucket,
+		"count":            s.count,
+		"volume":           s.volume.String(),
+		"prev_count":       s.prevCount,
+		"prev_volume":      s.prevVolume.String(),
+		"avg_count":        s.avgCount,
+		"avg_volume":       s.avgVolume,
+		"windows":          s.windows,
+		"alerted":          s.alerted,
+		"last_transaction": s.lastTransaction,
+	}
+}
+
+func parseBigInt(value string) *big.Int {
+	if parsed, ok := new(big.Int).SetString(value, 10); ok {
+		return parsed
+	}
+	return new(big.Int)
+}
+
+func velocityKey(network, address string) string {
+	return fmt.Sprintf("%s:%s:%s", velocityKeyPrefix, network, strings.ToLower(address))
+}
+
+// ProcessBalanceDrain 为短时间内被转空余额的地址生成并发布告警
+func (dp *DataProcessor) ProcessBalanceDrain(candidate *DrainCandidate, balance *big.Int) (*models.RiskAlert, error) {
+	alert := dp.createBalanceDrainAlert(candidate, balance)
+	logger.Warnf("%s: %s", alert.Title, alert.Description)
+	if err := dp.PublishOpsAlert(alert); err != nil {
+		return nil, err
+	}
+	if err := dp.velocity.MarkDrained(candidate); err != nil {
+		logger.Errorf("Failed to mark %s as drained: %v", candidate.Address, err)
+	}
+	return alert, nil
+}
+
+// createBalanceDrainAlert 创建余额清空告警，交易哈希为窗口内最后一笔转出
+func (dp *DataProcessor) createBalanceDrainAlert(candidate *DrainCandidate, balance *big.Int) *models.RiskAlert {
+	currency := dp.currencies.Get(candidate.Network)
+
+	alert := &models.RiskAlert{
+		ID:              models.AlertID("BALANCE_DRAIN", candidate.TransactionID),
+		Type:            "BALANCE_DRAIN",
+		Level:           "HIGH",
+		Title:           "余额清空",
+		Description:     fmt.Sprintf("地址 %s 在 %v 内转出 %s 后余额已被清空", candidate.Address, dp.velocity.window, currency.Format(candidate.Volume)),
+		TransactionHash: candidate.TransactionHash,
+		Address:         candidate.Address,
+		Network:         candidate.Network,
+		RiskScore:       0.8,
+		RiskFactors:     []string{"balance_drained"},
+		Metadata: map[string]interface{}{
+			"block_number":      candidate.BlockNumber,
+			"window_start":      candidate.WindowStart.Unix(),
+			"transactions":      candidate.Transactions,
+			"volume":            candidate.Volume.String(),
+			"remaining_balance": balance.String(),
+			"native_symbol":     currency.Symbol,
+			"value_display":     currency.Format(candidate.Volume),
+		},
+		Timestamp: candidate.Timestamp,
+		Status:    "ACTIVE",
+	}
+
+	if usdValue, priced := currency.ToUSD(candidate.Volume); priced {
+		alert.Metadata["value_usd"] = usdValue
+	}
+
+	return alert
+}
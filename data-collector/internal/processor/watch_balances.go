@@ -0,0 +1,267 @@
+package processor
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/models"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// watchBalanceKeyPrefix 关注地址余额在Redis中的键前缀：watch_balance:{网络}:{地址}:{资产} 为回撤窗口内的余额记录
+// （有序集合，成员为 Unix时间:余额，分数为Unix时间），watch_balance:alerted:{网络}:{地址}:{资产}:{最高点时间} 为告警去重标记
+const watchBalanceKeyPrefix = "watch_balance"
+
+// WatchBalanceNative 原生币余额的资产标识，代币为代币合约地址
+const WatchBalanceNative = "native"
+
+// BalanceSample 关注地址某项资产在某个区块的余额
+type BalanceSample struct {
+	Network     string
+	Address     string
+	Asset       string // native 或代币合约地址
+	Balance     *big.Int
+	BlockNumber uint64
+	Timestamp   time.Time
+}
+
+// BalanceDrawdown 余额相对回撤窗口内最高点的下降
+type BalanceDrawdown struct {
+	Sample  *BalanceSample
+	Symbol  string
+	Peak    *big.Int
+	PeakAt  time.Time
+	Percent float64
+}
+
+// WatchBalanceTracker 记录关注地址的余额历史，检测窗口内的余额回撤
+type WatchBalanceTracker struct {
+	client          *database.RedisClient
+	influx          *database.InfluxDBClient // 告警专用部署为nil，不写入余额历史
+	currencies      *CurrencyRegistry
+	tokens          map[string]*NativeCurrency // 网络:代币地址 -> 代币精度及符号
+	tokenList       map[string][]common.Address
+	interval        time.Duration
+	window          time.Duration
+	drawdownPercent float64
+}
+
+// NewWatchBalanceTracker 根据配置创建关注地址余额跟踪，未启用时返回nil
+func NewWatchBalanceTracker(cfg config.WatchBalanceConfig, redisClient *database.RedisClient, influxClient *database.InfluxDBClient, currencies *CurrencyRegistry) (*WatchBalanceTracker, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	interval, err := time.ParseDuration(cfg.Interval)
+	if err != nil || interval <= 0 {
+		return nil, fmt.Errorf("invalid watch balance interval: %q", cfg.Interval)
+	}
+	window, err := time.ParseDuration(cfg.DrawdownWindow)
+	if err != nil || window <= 0 {
+		return nil, fmt.Errorf("invalid watch balance drawdown_window: %q", cfg.DrawdownWindow)
+	}
+
+	tracker := &WatchBalanceTracker{
+		client:          redisClient,
+		influx:          influxClient,
+		currencies:      currencies,
+		tokens:          make(map[string]*NativeCurrency),
+		tokenList:       make(map[string][]common.Address),
+		interval:        interval,
+		window:          window,
+		drawdownPercent: cfg.DrawdownPercent,
+	}
+	for _, token := range cfg.Tokens {
+		address := common.HexToAddress(token.Address)
+		tracker.tokens[token.Network+":"+address.Hex()] = newNativeCurrency(config.NativeCurrencyConfig{Symbol: token.Symbol, Decimals: token.Decimals})
+		tracker.tokenList[token.Network] = append(tracker.tokenList[token.Network], address)
+	}
+
+	return tracker, nil
+}
+
+// Interval 定期查询余额的间隔
+func (t *WatchBalanceTracker) Interval() time.Duration {
+	return t.interval
+}
+
+// Tokens 网络上查询余额的代币合约
+func (t *WatchBalanceTracker) Tokens(network string) []common.Address {
+	return t.tokenList[network]
+}
+
+// currency 资产的精度及符号
+func (t *WatchBalanceTracker) currency(network, asset string) *NativeCurrency {
+	if asset == WatchBalanceNative {
+		return t.currencies.Get(network)
+	}
+	if currency, exists := t.tokens[network+":"+asset]; exists {
+		return currency
+	}
+	return t.currencies.Get(network)
+}
+
+// Record 写入余额历史，余额相对窗口内最高点的回撤达到配置比例且该最高点未告警过时返回回撤
+func (t *WatchBalanceTracker) Record(sample *BalanceSample) (*BalanceDrawdown, error) {
+	currency := t.currency(sample.Network, sample.Asset)
+	if t.influx != nil {
+		units, _ := currency.ToUnits(sample.Balance).Float64()
+		tags := map[string]string{
+			"network": sample.Network,
+			"address": sample.Address,
+			"asset":   sample.Asset,
+			"symbol":  currency.Symbol,
+		}
+		fields := map[string]interface{}{
+			"balance":       sample.Balance.String(),
+			"balance_units": units,
+			"block_number":  sample.BlockNumber,
+		}
+		if err := t.influx.WritePoint("watched_balances", tags, fields, sample.Timestamp); err != nil {
+			logger.Warnf("Failed to write balance of %s on %s: %v", sample.Address, sample.Network, err)
+		}
+	}
+
+	key := watchBalanceKey(sample.Network, sample.Address, sample.Asset)
+	now := sample.Timestamp.Unix()
+	if err := t.client.ZAdd(key, float64(now), fmt.Sprintf("%d:%s", now, sample.Balance.String())); err != nil {
+		return nil, err
+	}
+	if err := t.client.ZRemRangeByScore(key, "-inf", strconv.FormatInt(now-int64(t.window.Seconds()), 10)); err != nil {
+		return nil, err
+	}
+	if err := t.client.Expire(key, t.window); err != nil {
+		return nil, err
+	}
+
+	history, err := t.client.ZRangeByScore(key, "-inf", "+inf")
+	if err != nil {
+		return nil, err
+	}
+	peak, peakAt := new(big.Int), int64(0)
+	for _, member := range history {
+		at, value, ok := strings.Cut(member, ":")
+		if !ok {
+			continue
+		}
+		balance, valid := new(big.Int).SetString(value, 10)
+		if !valid || balance.Cmp(peak) <= 0 {
+			continue
+		}
+		peak = balance
+		peakAt, _ = strconv.ParseInt(at, 10, 64)
+	}
+	if peak.Sign() == 0 || sample.Balance.Cmp(peak) >= 0 {
+		return nil, nil
+	}
+
+	drop := new(big.Float).SetInt(new(big.Int).Sub(peak, sample.Balance))
+	percent, _ := new(big.Float).Quo(drop.Mul(drop, big.NewFloat(100)), new(big.Float).SetInt(peak)).Float64()
+	if percent < t.drawdownPercent {
+		return nil, nil
+	}
+
+	// 同一最高点只告警一次，余额回升创新高后再次回撤时重新告警
+	first, err := t.client.SetNX(fmt.Sprintf("%s:alerted:%s:%s:%s:%d", watchBalanceKeyPrefix, sample.Network, sample.Address, sample.Asset, peakAt), 1, t.window)
+	if err != nil || !first {
+		return nil, err
+	}
+
+	return &BalanceDrawdown{
+		Sample:  sample,
+		Symbol:  currency.Symbol,
+		Peak:    peak,
+		PeakAt:  time.Unix(peakAt, 0),
+		Percent: percent,
+	}, nil
+}
+
+// Format 按资产精度格式化余额，如 "1.5 ETH"
+func (t *WatchBalanceTracker) Format(network, asset string, amount *big.Int) string {
+	return t.currency(network, asset).Format(amount)
+}
+
+func watchBalanceKey(network, address, asset string) string {
+	return fmt.Sprintf("%s:%s:%s:%s", watchBalanceKeyPrefix, network, address, asset)
+}
+
+// ProcessWatchedBalances 记录关注地址的余额，回撤达到比例时按地址所在关注列表中最高的告警级别发布告警
+func (dp *DataProcessor) ProcessWatchedBalances(samples []*BalanceSample) []*models.RiskAlert {
+	var alerts []*models.RiskAlert
+	for _, sample := range samples {
+		drawdown, err := dp.watchBalances.Record(sample)
+		if err != nil {
+			logger.Errorf("Failed to record balance of %s on %s: %v", sample.Address, sample.Network, err)
+			continue
+		}
+		if drawdown == nil {
+			continue
+		}
+
+		hits, err := dp.watchlists.Match(sample.Network, map[string]string{"from": sample.Address})
+		if err != nil {
+			logger.Errorf("Failed to match watchlists for %s: %v", sample.Address, err)
+			continue
+		}
+		if len(hits) == 0 {
+			continue
+		}
+
+		alert := dp.createBalanceDrawdownAlert(drawdown, hits)
+		logger.Warnf("%s: %s", alert.Title, alert.Description)
+		if err := dp.PublishOpsAlert(alert); err != nil {
+			logger.Errorf("Failed to publish balance drawdown alert for %s: %v", sample.Address, err)
+			continue
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts
+}
+
+// createBalanceDrawdownAlert 创建关注地址余额回撤告警
+func (dp *DataProcessor) createBalanceDrawdownAlert(drawdown *BalanceDrawdown, hits []WatchHit) *models.RiskAlert {
+	sample := drawdown.Sample
+	level := hits[0].Address.Level
+	watchlists := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		if watchAlertScores[hit.Address.Level] > watchAlertScores[level] {
+			level = hit.Address.Level
+		}
+		watchlists = append(watchlists, hit.Watchlist.Name)
+	}
+
+	name := sample.Address
+	if label := hits[0].Address.Label; label != "" {
+		name = fmt.Sprintf("%s（%s）", label, sample.Address)
+	}
+
+	return &models.RiskAlert{
+		ID:          models.AlertID("WATCH_DRAWDOWN", fmt.Sprintf("%s:%s:%s:%d", sample.Network, sample.Address, sample.Asset, drawdown.PeakAt.Unix())),
+		Type:        "WATCH_DRAWDOWN",
+		Level:       level,
+		Title:       "关注地址余额回撤",
+		Description: fmt.Sprintf("关注地址 %s 的 %s 余额从 %s 降至 %s，回撤 %.1f%%", name, drawdown.Symbol, dp.watchBalances.Format(sample.Network, sample.Asset, drawdown.Peak), dp.watchBalances.Format(sample.Network, sample.Asset, sample.Balance), drawdown.Percent),
+		Address:     sample.Address,
+		Network:     sample.Network,
+		RiskScore:   watchAlertScores[level],
+		RiskFactors: []string{"watchlist", "balance_drawdown"},
+		Metadata: map[string]interface{}{
+			"asset":            sample.Asset,
+			"symbol":           drawdown.Symbol,
+			"balance":          sample.Balance.String(),
+			"peak_balance":     drawdown.Peak.String(),
+			"peak_at":          drawdown.PeakAt,
+			"drawdown_percent": drawdown.Percent,
+			"block_number":     sample.BlockNumber,
+			"watchlists":       watchlists,
+		},
+		Timestamp: sample.Timestamp,
+		Status:    "ACTIVE",
+	}
+}
@@ -0,0 +1,59 @@
+package processor
+
+import (
+	"time"
+
+	"web3-data-collector/internal/models"
+)
+
+// watchAlertScores 关注组告警级别对应的风险分
+var watchAlertScores = map[string]float64{
+	"LOW":      0.3,
+	"MEDIUM":   0.5,
+	"HIGH":     0.8,
+	"CRITICAL": 0.95,
+}
+
+// WatchMatch 事件匹配的关注组规则，标题及描述已按模板渲染，Level为空时不告警
+type WatchMatch struct {
+	Group       string
+	Event       string
+	Level       string
+	Title       string
+	Description string
+}
+
+// ProcessWatchEvent 发布关注组匹配的事件，事件首次发布时按规则生成CONTRACT_WATCH告警
+func (dp *DataProcessor) ProcessWatchEvent(event *models.Event, match *WatchMatch) (*models.RiskAlert, error) {
+	published, err := dp.publishEvent(event)
+	if err != nil || !published || match.Level == "" {
+		return nil, err
+	}
+
+	alert := &models.RiskAlert{
+		ID:              models.AlertID("CONTRACT_WATCH", event.ID),
+		Type:            "CONTRACT_WATCH",
+		Level:           match.Level,
+		Title:           match.Title,
+		Description:     match.Description,
+		TransactionHash: event.TransactionHash,
+		Address:         event.ContractAddress,
+		Network:         event.Network,
+		RiskScore:       watchAlertScores[match.Level],
+		RiskFactors:     []string{"watch_group"},
+		Metadata: map[string]interface{}{
+			"group":        match.Group,
+			"event":        match.Event,
+			"block_number": event.BlockNumber,
+			"log_index":    event.LogIndex,
+			"args":         event.DecodedData,
+		},
+		Timestamp: time.Now(),
+		Status:    "ACTIVE",
+	}
+	if err := dp.PublishOpsAlert(alert); err != nil {
+		return nil, err
+	}
+
+	return alert, nil
+}
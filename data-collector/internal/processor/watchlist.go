@@ -0,0 +1,449 @@
+package processor
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/models"
+)
+
+// watchlistsKey 地址关注列表在Redis中的哈希，字段为关注列表ID，值为models.Watchlist的JSON
+const watchlistsKey = "watchlists"
+
+// watchlistCacheTTL 关注地址索引的本地缓存时间，其他实例的变更最迟在该时间后生效
+const watchlistCacheTTL = 10 * time.Second
+
+// defaultWatchLevel 关注地址未设置告警级别时使用的级别
+const defaultWatchLevel = "MEDIUM"
+
+// WatchHit 交易或代币转账涉及的关注地址
+type WatchHit struct {
+	Watchlist *models.Watchlist
+	Address   models.WatchedAddress
+	Role      string // from / to / token_from / token_to
+}
+
+// watchEntry 关注地址索引中的条目
+type watchEntry struct {
+	list    *models.Watchlist
+	address models.WatchedAddress
+}
+
+// Watchlists 用户注册的地址关注列表，保存在Redis，匹配时使用本地索引
+type Watchlists struct {
+	client       *database.RedisClient
+	maxAddresses int
+	index        map[string][]watchEntry    // 规范化地址 -> 关注条目
+	sets         map[string]map[string]bool // 网络 -> 关注地址集合，随索引重建
+	loadedAt     time.Time
+	mu           sync.Mutex
+}
+
+// NewWatchlists 根据配置创建地址关注列表，未启用时返回nil
+func NewWatchlists(cfg config.WatchlistConfig, redisClient *database.RedisClient) (*Watchlists, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	maxAddresses := cfg.MaxAddresses
+	if maxAddresses <= 0 {
+		maxAddresses = 1000
+	}
+
+	return &Watchlists{client: redisClient, maxAddresses: maxAddresses}, nil
+}
+
+// List 获取关注列表，owner为空时返回全部
+func (w *Watchlists) List(owner string) ([]models.Watchlist, error) {
+	lists, err := w.load()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.Watchlist, 0, len(lists))
+	for _, list := range lists {
+		if owner == "" || list.Owner == owner {
+			result = append(result, *list)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+// Get 获取关注列表
+func (w *Watchlists) Get(id string) (*models.Watchlist, bool, error) {
+	values, err := w.client.HGetAll(watchlistsKey)
+	if err != nil {
+		return nil, false, err
+	}
+	value, exists := values[id]
+	if !exists {
+		return nil, false, nil
+	}
+
+	var list models.Watchlist
+	if err := json.Unmarshal([]byte(value), &list); err != nil {
+		return nil, false, fmt.Errorf("invalid watchlist %s: %w", id, err)
+	}
+	return &list, true, nil
+}
+
+// Create 校验并保存新的关注列表
+func (w *Watchlists) Create(list *models.Watchlist) (*models.Watchlist, error) {
+	if err := w.normalize(list); err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	list.ID = hex.EncodeToString(id)
+	list.CreatedAt = now
+	list.UpdatedAt = now
+
+	if err := w.save(list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Update 替换关注列表的名称、网络及地址，保留创建者和创建时间
+func (w *Watchlists) Update(id string, list *models.Watchlist) (*models.Watchlist, bool, error) {
+	existing, exists, err := w.Get(id)
+	if err != nil || !exists {
+		return nil, exists, err
+	}
+	if err := w.normalize(list); err != nil {
+		return nil, true, err
+	}
+
+	list.ID = existing.ID
+	list.Owner = existing.Owner
+	list.CreatedAt = existing.CreatedAt
+	list.UpdatedAt = time.Now()
+
+	if err := w.save(list); err != nil {
+		return nil, true, err
+	}
+	return list, true, nil
+}
+
+// Delete 删除关注列表
+func (w *Watchlists) Delete(id string) (bool, error) {
+	_, exists, err := w.Get(id)
+	if err != nil || !exists {
+		return false, err
+	}
+	if err := w.client.HDel(watchlistsKey, id); err != nil {
+		return false, err
+	}
+	w.invalidate()
+	return true, nil
+}
+
+// Match 查找网络上涉及的关注地址，parties为角色到地址的映射
+func (w *Watchlists) Match(network string, parties map[string]string) ([]WatchHit, error) {
+	index, err := w.currentIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	var hits []WatchHit
+	for _, role := range []string{"from", "to", "token_from", "token_to"} {
+		address, exists := parties[role]
+		if !exists || address == "" {
+			continue
+		}
+		for _, entry := range index[normalizeWatchAddress(address)] {
+			if len(entry.list.Networks) > 0 && !containsString(entry.list.Networks, network) {
+				continue
+			}
+			hits = append(hits, WatchHit{Watchlist: entry.list, Address: entry.address, Role: role})
+		}
+	}
+	return hits, nil
+}
+
+// AddressSet 网络上的全部关注地址，供过滤表达式的watchlist变量使用，调用方不得修改
+func (w *Watchlists) AddressSet(network string) (map[string]bool, error) {
+	index, err := w.currentIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if set, exists := w.sets[network]; exists {
+		return set, nil
+	}
+	set := make(map[string]bool)
+	for address, entries := range index {
+		for _, entry := range entries {
+			if len(entry.list.Networks) == 0 || containsString(entry.list.Networks, network) {
+				set[address] = true
+				break
+			}
+		}
+	}
+	// 索引在此期间失效时不缓存
+	if w.sets != nil {
+		w.sets[network] = set
+	}
+	return set, nil
+}
+
+// normalize 校验关注列表并规范化地址，同一列表中的重复地址只保留第一个
+func (w *Watchlists) normalize(list *models.Watchlist) error {
+	list.Name = strings.TrimSpace(list.Name)
+	if list.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(list.Addresses) == 0 {
+		return fmt.Errorf("at least one address is required")
+	}
+	if len(list.Addresses) > w.maxAddresses {
+		return fmt.Errorf("watchlist has %d addresses, at most %d allowed", len(list.Addresses), w.maxAddresses)
+	}
+
+	seen := make(map[string]bool, len(list.Addresses))
+	addresses := make([]models.WatchedAddress, 0, len(list.Addresses))
+	for _, watched := range list.Addresses {
+		watched.Address = normalizeWatchAddress(strings.TrimSpace(watched.Address))
+		if watched.Address == "" {
+			return fmt.Errorf("address is required")
+		}
+		watched.Level = strings.ToUpper(watched.Level)
+		if watched.Level == "" {
+			watched.Level = defaultWatchLevel
+		}
+		if _, valid := watchAlertScores[watched.Level]; !valid {
+			return fmt.Errorf("invalid level %q for address %s", watched.Level, watched.Address)
+		}
+		if seen[watched.Address] {
+			continue
+		}
+		seen[watched.Address] = true
+		addresses = append(addresses, watched)
+	}
+	list.Addresses = addresses
+	return nil
+}
+
+func (w *Watchlists) save(list *models.Watchlist) error {
+	data, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	if err := w.client.HSet(watchlistsKey, list.ID, string(data)); err != nil {
+		return err
+	}
+	w.invalidate()
+	return nil
+}
+
+// load 从Redis加载全部关注列表
+func (w *Watchlists) load() ([]*models.Watchlist, error) {
+	values, err := w.client.HGetAll(watchlistsKey)
+	if err != nil {
+		return nil, err
+	}
+
+	lists := make([]*models.Watchlist, 0, len(values))
+	for id, value := range values {
+		var list models.Watchlist
+		if err := json.Unmarshal([]byte(value), &list); err != nil {
+			logger.Warnf("Skipping invalid watchlist %s: %v", id, err)
+			continue
+		}
+		lists = append(lists, &list)
+	}
+	return lists, nil
+}
+
+// currentIndex 获取关注地址索引，缓存过期时从Redis重新加载
+func (w *Watchlists) currentIndex() (map[string][]watchEntry, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.index != nil && time.Since(w.loadedAt) <= watchlistCacheTTL {
+		return w.index, nil
+	}
+
+	lists, err := w.load()
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string][]watchEntry)
+	for _, list := range lists {
+		for _, watched := range list.Addresses {
+			index[watched.Address] = append(index[watched.Address], watchEntry{list: list, address: watched})
+		}
+	}
+	w.index = index
+	w.sets = make(map[string]map[string]bool)
+	w.loadedAt = time.Now()
+	return index, nil
+}
+
+func (w *Watchlists) invalidate() {
+	w.mu.Lock()
+	w.index = nil
+	w.sets = nil
+	w.mu.Unlock()
+}
+
+// normalizeWatchAddress EVM地址转为小写，Solana等base58地址区分大小写，保持原样
+func normalizeWatchAddress(address string) string {
+	if strings.HasPrefix(address, "0x") || strings.HasPrefix(address, "0X") {
+		return strings.ToLower(address)
+	}
+	return address
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// processWatchedTransaction 交易的发送方或接收方在关注列表中时发布WATCHLIST告警，不受过滤规则影响
+func (dp *DataProcessor) processWatchedTransaction(tx *models.Transaction) []*models.RiskAlert {
+	if dp.watchlists == nil {
+		return nil
+	}
+
+	hits, err := dp.watchlists.Match(tx.Network, map[string]string{
+		"from": tx.FromAddress,
+		"to":   tx.ToAddress,
+	})
+	if err != nil {
+		logger.Errorf("Failed to match watchlists for transaction %s: %v", tx.Hash, err)
+		return nil
+	}
+
+	metadata := map[string]interface{}{
+		"block_number": tx.BlockNumber,
+		"from_address": tx.FromAddress,
+		"to_address":   tx.ToAddress,
+	}
+	if tx.Value != nil {
+		metadata["value"] = tx.Value.String()
+	}
+	return dp.publishWatchAlerts(hits, tx.ID, tx.Network, tx.Hash, tx.Timestamp, metadata)
+}
+
+// ProcessWatchedTransfer 代币转账的转出方或接收方在关注列表中时发布WATCHLIST告警
+func (dp *DataProcessor) ProcessWatchedTransfer(transfer *TokenTransfer) []*models.RiskAlert {
+	if dp.watchlists == nil {
+		return nil
+	}
+
+	hits, err := dp.watchlists.Match(transfer.Network, map[string]string{
+		"token_from": transfer.From,
+		"token_to":   transfer.To,
+	})
+	if err != nil {
+		logger.Errorf("Failed to match watchlists for transfer %s: %v", transfer.ID, err)
+		return nil
+	}
+
+	metadata := map[string]interface{}{
+		"block_number": transfer.BlockNumber,
+		"token":        transfer.Token,
+		"from_address": transfer.From,
+		"to_address":   transfer.To,
+		"amount":       transfer.Amount.String(),
+	}
+	return dp.publishWatchAlerts(hits, transfer.ID, transfer.Network, transfer.TransactionHash, transfer.Timestamp, metadata)
+}
+
+// publishWatchAlerts 每个命中的关注列表发布一条告警，级别取命中地址中最高的
+func (dp *DataProcessor) publishWatchAlerts(hits []WatchHit, sourceID, network, txHash string, timestamp time.Time, metadata map[string]interface{}) []*models.RiskAlert {
+	var order []string
+	grouped := make(map[string][]WatchHit)
+	for _, hit := range hits {
+		if _, exists := grouped[hit.Watchlist.ID]; !exists {
+			order = append(order, hit.Watchlist.ID)
+		}
+		grouped[hit.Watchlist.ID] = append(grouped[hit.Watchlist.ID], hit)
+	}
+
+	var alerts []*models.RiskAlert
+	for _, id := range order {
+		alert := dp.createWatchlistAlert(grouped[id], sourceID, network, txHash, timestamp, metadata)
+		logger.Infof("%s: %s", alert.Title, alert.Description)
+		if err := dp.PublishOpsAlert(alert); err != nil {
+			logger.Errorf("Failed to publish watchlist alert for %s: %v", sourceID, err)
+			continue
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts
+}
+
+// createWatchlistAlert 创建关注地址告警，地址为第一个命中的关注地址
+func (dp *DataProcessor) createWatchlistAlert(hits []WatchHit, sourceID, network, txHash string, timestamp time.Time, metadata map[string]interface{}) *models.RiskAlert {
+	list := hits[0].Watchlist
+
+	level := hits[0].Address.Level
+	matched := make([]map[string]string, 0, len(hits))
+	names := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		if watchAlertScores[hit.Address.Level] > watchAlertScores[level] {
+			level = hit.Address.Level
+		}
+		matched = append(matched, map[string]string{
+			"address": hit.Address.Address,
+			"label":   hit.Address.Label,
+			"role":    hit.Role,
+		})
+		name := hit.Address.Address
+		if hit.Address.Label != "" {
+			name = fmt.Sprintf("%s（%s）", hit.Address.Label, hit.Address.Address)
+		}
+		names = append(names, name)
+	}
+
+	alertMetadata := map[string]interface{}{
+		"watchlist_id": list.ID,
+		"watchlist":    list.Name,
+		"owner":        list.Owner,
+		"matched":      matched,
+	}
+	for key, value := range metadata {
+		alertMetadata[key] = value
+	}
+
+	return &models.RiskAlert{
+		ID:              models.AlertID("WATCHLIST", sourceID+":"+list.ID),
+		Type:            "WATCHLIST",
+		Level:           level,
+		Title:           "关注地址活动",
+		Description:     fmt.Sprintf("关注列表 %s 中的地址 %s 出现新的链上活动", list.Name, strings.Join(names, "、")),
+		TransactionHash: txHash,
+		Address:         hits[0].Address.Address,
+		Network:         network,
+		RiskScore:       watchAlertScores[level],
+		RiskFactors:     []string{"watchlist"},
+		Metadata:        alertMetadata,
+		Timestamp:       timestamp,
+		Status:          "ACTIVE",
+	}
+}
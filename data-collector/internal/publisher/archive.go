@@ -0,0 +1,355 @@
+package publisher
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/models"
+)
+
+// 归档的数据类型，同时作为对象键中的目录名
+const (
+	archiveBlocks       = "blocks"
+	archiveTransactions = "transactions"
+)
+
+// archiveUploadTimeout 单个文件的上传超时
+const archiveUploadTimeout = 2 * time.Minute
+
+// archiveBlockColumns 区块文件的列，raw为不含交易列表的完整区块JSON
+var archiveBlockColumns = []parquetColumn{
+	{"network", parquetString},
+	{"number", parquetInt64},
+	{"hash", parquetString},
+	{"parent_hash", parquetString},
+	{"timestamp", parquetTimestamp},
+	{"miner", parquetString},
+	{"gas_limit", parquetInt64},
+	{"gas_used", parquetInt64},
+	{"tx_count", parquetInt64},
+	{"base_fee_per_gas", parquetString},
+	{"raw", parquetString},
+}
+
+// archiveTransactionColumns 交易文件的列，raw为完整交易JSON
+var archiveTransactionColumns = []parquetColumn{
+	{"network", parquetString},
+	{"hash", parquetString},
+	{"block_number", parquetInt64},
+	{"transaction_index", parquetInt64},
+	{"from_address", parquetString},
+	{"to_address", parquetString},
+	{"value", parquetString},
+	{"gas", parquetInt64},
+	{"gas_price", parquetString},
+	{"gas_used", parquetInt64},
+	{"nonce", parquetInt64},
+	{"method_name", parquetString},
+	{"status", parquetInt64},
+	{"timestamp", parquetTimestamp},
+	{"raw", parquetString},
+}
+
+// archiveStore 对象存储的读写，回放时列出并下载归档文件
+type archiveStore interface {
+	upload(ctx context.Context, key, contentType string, data []byte) error
+	list(ctx context.Context, prefix string) ([]string, error)
+	download(ctx context.Context, key string) ([]byte, error)
+}
+
+// archiveRecord 一条待归档的记录，values与列定义一一对应（最后的raw列除外）
+type archiveRecord struct {
+	values []interface{}
+	raw    []byte
+}
+
+// archiveBucket 同一数据类型、网络及小时的待上传记录
+type archiveBucket struct {
+	kind    string
+	network string
+	hour    time.Time
+	records []archiveRecord
+}
+
+// Archiver 将原始区块及交易按区块时间的小时分组，小时结束后写成Parquet或gzip JSON文件上传到S3/GCS
+type Archiver struct {
+	store      archiveStore
+	prefix     string
+	format     string
+	maxRecords int
+	interval   time.Duration
+
+	buckets  map[string]*archiveBucket
+	mu       sync.Mutex
+	flushMu  sync.Mutex    // 上传串行执行，避免同一批记录重复上传
+	flushNow chan struct{} // 有文件达到max_records时通知后台上传
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewArchiver 根据配置创建冷归档并启动后台上传，未启用时返回nil
+func NewArchiver(cfg config.ArchiveConfig) (*Archiver, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	interval, err := time.ParseDuration(cfg.FlushInterval)
+	if err != nil || interval <= 0 {
+		return nil, fmt.Errorf("invalid archive flush_interval: %q", cfg.FlushInterval)
+	}
+
+	store, err := newArchiveStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	archiver := &Archiver{
+		store:      store,
+		prefix:     strings.Trim(cfg.Prefix, "/"),
+		format:     cfg.Format,
+		maxRecords: cfg.MaxRecords,
+		interval:   interval,
+		buckets:    make(map[string]*archiveBucket),
+		flushNow:   make(chan struct{}, 1),
+		stopChan:   make(chan struct{}),
+	}
+
+	archiver.wg.Add(1)
+	go archiver.run()
+
+	logger.Infof("Archiving raw blocks and transactions to %s bucket %s (format: %s)", cfg.Backend, cfg.Bucket, cfg.Format)
+	return archiver, nil
+}
+
+// ArchiveBlock 加入区块，交易列表单独归档，区块记录中不包含
+func (a *Archiver) ArchiveBlock(block *models.Block) error {
+	summary := *block
+	summary.Transactions = nil
+	raw, err := json.Marshal(&summary)
+	if err != nil {
+		return fmt.Errorf("failed to marshal block: %w", err)
+	}
+
+	a.add(archiveBlocks, block.Network, block.Timestamp, archiveRecord{
+		values: []interface{}{
+			block.Network,
+			int64(block.Number),
+			block.Hash,
+			block.ParentHash,
+			block.Timestamp.UnixMilli(),
+			block.Miner,
+			int64(block.GasLimit),
+			int64(block.GasUsed),
+			int64(block.TxCount),
+			bigString(block.BaseFeePerGas),
+		},
+		raw: raw,
+	})
+	return nil
+}
+
+// ArchiveTransaction 加入交易
+func (a *Archiver) ArchiveTransaction(tx *models.Transaction) error {
+	raw, err := json.Marshal(tx)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transaction: %w", err)
+	}
+
+	a.add(archiveTransactions, tx.Network, tx.Timestamp, archiveRecord{
+		values: []interface{}{
+			tx.Network,
+			tx.Hash,
+			int64(tx.BlockNumber),
+			int64(tx.TransactionIndex),
+			tx.FromAddress,
+			tx.ToAddress,
+			bigString(tx.Value),
+			int64(tx.Gas),
+			bigString(tx.GasPrice),
+			int64(tx.GasUsed),
+			int64(tx.Nonce),
+			tx.MethodName,
+			int64(tx.Status),
+			tx.Timestamp.UnixMilli(),
+		},
+		raw: raw,
+	})
+	return nil
+}
+
+// Close 停止后台上传并上传全部未上传的记录
+func (a *Archiver) Close() {
+	close(a.stopChan)
+	a.wg.Wait()
+	a.flush(true)
+}
+
+// add 按数据类型、网络及区块时间所在的小时加入记录
+func (a *Archiver) add(kind, network string, timestamp time.Time, record archiveRecord) {
+	hour := timestamp.UTC().Truncate(time.Hour)
+	key := fmt.Sprintf("%s|%s|%d", kind, network, hour.Unix())
+
+	a.mu.Lock()
+	bucket, exists := a.buckets[key]
+	if !exists {
+		bucket = &archiveBucket{kind: kind, network: network, hour: hour}
+		a.buckets[key] = bucket
+	}
+	bucket.records = append(bucket.records, record)
+	full := len(bucket.records) >= a.maxRecords
+	a.mu.Unlock()
+
+	if full {
+		select {
+		case a.flushNow <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run 定期上传已结束小时的记录，有文件达到max_records时立即上传
+func (a *Archiver) run() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.stopChan:
+			return
+		case <-ticker.C:
+			a.flush(false)
+		case <-a.flushNow:
+			a.flush(false)
+		}
+	}
+}
+
+// flush 上传已结束小时或达到max_records的记录，all为true时上传全部；上传失败的记录放回，下次重试
+func (a *Archiver) flush(all bool) {
+	a.flushMu.Lock()
+	defer a.flushMu.Unlock()
+
+	now := time.Now()
+	var due []*archiveBucket
+	a.mu.Lock()
+	for key, bucket := range a.buckets {
+		if all || !bucket.hour.Add(time.Hour).After(now) || len(bucket.records) >= a.maxRecords {
+			due = append(due, bucket)
+			delete(a.buckets, key)
+		}
+	}
+	a.mu.Unlock()
+
+	for _, bucket := range due {
+		if err := a.upload(bucket, now); err != nil {
+			logger.Errorf("Failed to archive %d %s of %s for %s: %v", len(bucket.records), bucket.kind, bucket.network, bucket.hour.Format("2006-01-02T15"), err)
+			a.restore(bucket)
+		}
+	}
+}
+
+// restore 将上传失败的记录放回，排在上传期间新加入的记录之前
+func (a *Archiver) restore(failed *archiveBucket) {
+	key := fmt.Sprintf("%s|%s|%d", failed.kind, failed.network, failed.hour.Unix())
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if bucket, exists := a.buckets[key]; exists {
+		failed.records = append(failed.records, bucket.records...)
+	}
+	a.buckets[key] = failed
+}
+
+// upload 编码并上传一个文件，对象键按 {prefix}/{类型}/network=/date=/hour= 分区，文件名带上传时间避免覆盖
+func (a *Archiver) upload(bucket *archiveBucket, now time.Time) error {
+	columns := archiveBlockColumns
+	if bucket.kind == archiveTransactions {
+		columns = archiveTransactionColumns
+	}
+
+	var data []byte
+	var err error
+	contentType, extension := "application/vnd.apache.parquet", "parquet"
+	if a.format == "json" {
+		contentType, extension = "application/x-ndjson", "json.gz"
+		data, err = encodeJSONLines(bucket.records)
+	} else {
+		rows := make([][]interface{}, 0, len(bucket.records))
+		for _, record := range bucket.records {
+			rows = append(rows, append(record.values, string(record.raw)))
+		}
+		data, err = encodeParquet(columns, rows)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode archive file: %w", err)
+	}
+
+	key := fmt.Sprintf("%s%s-%s-%d.%s", archivePartition(a.prefix, bucket.kind, bucket.network, bucket.hour),
+		bucket.kind, bucket.hour.Format("2006010215"), now.UnixNano(), extension)
+
+	ctx, cancel := context.WithTimeout(context.Background(), archiveUploadTimeout)
+	defer cancel()
+	if err := a.store.upload(ctx, key, contentType, data); err != nil {
+		return err
+	}
+
+	logger.Infof("Archived %d %s of %s to %s (%d bytes)", len(bucket.records), bucket.kind, bucket.network, key, len(data))
+	return nil
+}
+
+// archivePartition 数据类型、网络及小时对应的对象键前缀，以/结尾
+func archivePartition(prefix, kind, network string, hour time.Time) string {
+	partition := fmt.Sprintf("%s/network=%s/date=%s/hour=%s/", kind, network, hour.Format("2006-01-02"), hour.Format("15"))
+	if prefix != "" {
+		partition = prefix + "/" + partition
+	}
+	return partition
+}
+
+// newArchiveStore 按配置的后端创建对象存储客户端
+func newArchiveStore(cfg config.ArchiveConfig) (archiveStore, error) {
+	switch cfg.Backend {
+	case "s3":
+		return newS3Store(cfg.S3, cfg.Bucket)
+	case "gcs":
+		return newGCSStore(cfg.GCS, cfg.Bucket)
+	default:
+		return nil, fmt.Errorf("unknown archive backend: %s", cfg.Backend)
+	}
+}
+
+// encodeJSONLines 每行一条记录的JSON，gzip压缩
+func encodeJSONLines(records []archiveRecord) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	for _, record := range records {
+		if _, err := zw.Write(record.raw); err != nil {
+			return nil, err
+		}
+		if _, err := zw.Write([]byte{'\n'}); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// bigString 大整数的十进制字符串，nil为空字符串
+func bigString(value *big.Int) string {
+	if value == nil {
+		return ""
+	}
+	return value.String()
+}
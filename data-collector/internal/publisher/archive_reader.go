@@ -0,0 +1,177 @@
+package publisher
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/models"
+)
+
+// archiveMaxLineSize gzip JSON文件中单行记录的上限
+const archiveMaxLineSize = 64 << 20
+
+// ArchiveReader 按小时读取Archiver上传的区块及交易文件，用于回放
+type ArchiveReader struct {
+	store  archiveStore
+	prefix string
+}
+
+// NewArchiveReader 根据归档配置创建读取器，不要求归档已启用，只需配置桶及凭证
+func NewArchiveReader(cfg config.ArchiveConfig) (*ArchiveReader, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("archive bucket is not configured")
+	}
+
+	store, err := newArchiveStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ArchiveReader{
+		store:  store,
+		prefix: strings.Trim(cfg.Prefix, "/"),
+	}, nil
+}
+
+// ReadBlocks 按区块号顺序回调[from, to)内的区块，交易按序号放回所属区块；
+// 只有交易文件时以交易构造区块，同一区块或交易出现在多个文件中时只回调一次
+func (r *ArchiveReader) ReadBlocks(ctx context.Context, network string, from, to time.Time, handle func(*models.Block) error) error {
+	for hour := from.UTC().Truncate(time.Hour); hour.Before(to); hour = hour.Add(time.Hour) {
+		blocks := make(map[uint64]*models.Block)
+		err := r.readRecords(ctx, archivePartition(r.prefix, archiveBlocks, network, hour), func(raw []byte) error {
+			var block models.Block
+			if err := json.Unmarshal(raw, &block); err != nil {
+				return fmt.Errorf("invalid archived block: %w", err)
+			}
+			block.Transactions = nil
+			blocks[block.Number] = &block
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		seen := make(map[string]bool)
+		err = r.readRecords(ctx, archivePartition(r.prefix, archiveTransactions, network, hour), func(raw []byte) error {
+			var tx models.Transaction
+			if err := json.Unmarshal(raw, &tx); err != nil {
+				return fmt.Errorf("invalid archived transaction: %w", err)
+			}
+			if seen[tx.Hash] {
+				return nil
+			}
+			seen[tx.Hash] = true
+
+			block, exists := blocks[tx.BlockNumber]
+			if !exists {
+				block = &models.Block{
+					Number:    tx.BlockNumber,
+					Hash:      tx.BlockHash,
+					Timestamp: tx.Timestamp,
+					Network:   tx.Network,
+				}
+				blocks[tx.BlockNumber] = block
+			}
+			block.Transactions = append(block.Transactions, tx)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		numbers := make([]uint64, 0, len(blocks))
+		for number := range blocks {
+			numbers = append(numbers, number)
+		}
+		sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+
+		for _, number := range numbers {
+			block := blocks[number]
+			if block.Timestamp.Before(from) || !block.Timestamp.Before(to) {
+				continue
+			}
+			sort.Slice(block.Transactions, func(i, j int) bool {
+				return block.Transactions[i].TransactionIndex < block.Transactions[j].TransactionIndex
+			})
+			if block.TxCount < len(block.Transactions) {
+				block.TxCount = len(block.Transactions)
+			}
+			if err := handle(block); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readRecords 下载前缀下的全部文件，逐条回调完整记录的JSON
+func (r *ArchiveReader) readRecords(ctx context.Context, prefix string, handle func(raw []byte) error) error {
+	keys, err := r.store.list(ctx, prefix)
+	if err != nil {
+		return fmt.Errorf("failed to list %s: %w", prefix, err)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		data, err := r.store.download(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to download %s: %w", key, err)
+		}
+
+		var records [][]byte
+		switch {
+		case strings.HasSuffix(key, ".parquet"):
+			values, err := decodeParquetStrings(data, "raw")
+			if err != nil {
+				return fmt.Errorf("failed to decode %s: %w", key, err)
+			}
+			for _, value := range values {
+				records = append(records, []byte(value))
+			}
+		case strings.HasSuffix(key, ".json.gz"):
+			if records, err = decodeJSONLines(data); err != nil {
+				return fmt.Errorf("failed to decode %s: %w", key, err)
+			}
+		default:
+			logger.Warnf("Skipping unknown archive file %s", key)
+			continue
+		}
+
+		logger.Debugf("Read %d records from %s", len(records), key)
+		for _, record := range records {
+			if err := handle(record); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// decodeJSONLines 解压gzip并按行拆分记录，跳过空行
+func decodeJSONLines(data []byte) ([][]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var records [][]byte
+	scanner := bufio.NewScanner(zr)
+	scanner.Buffer(make([]byte, 0, 64*1024), archiveMaxLineSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		records = append(records, append([]byte(nil), line...))
+	}
+	return records, scanner.Err()
+}
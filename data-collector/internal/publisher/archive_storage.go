@@ -0,0 +1,360 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"web3-data-collector/internal/config"
+)
+
+// gcsScope GCS写入对象所需的授权范围
+const gcsScope = "https://www.googleapis.com/auth/devstorage.read_write"
+
+// s3Store 通过PutObject、ListObjectsV2及GetObject读写S3或兼容服务，请求使用SigV4签名
+type s3Store struct {
+	http        *http.Client
+	baseURL     string // 对象键之前的部分，路径风格时包含桶名
+	pathPrefix  string // 签名用的路径前缀，路径风格时为 /{桶名}
+	region      string
+	credentials awsCredentials
+}
+
+func newS3Store(cfg config.ArchiveS3Config, bucket string) (*s3Store, error) {
+	credentials, ok := resolveAWSCredentials(cfg.AccessKeyID, cfg.SecretAccessKey, cfg.SessionToken)
+	if !ok {
+		return nil, fmt.Errorf("s3 credentials are not configured")
+	}
+
+	endpoint := strings.TrimRight(cfg.Endpoint, "/")
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+	}
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint: %q", cfg.Endpoint)
+	}
+
+	store := &s3Store{
+		http:        &http.Client{Timeout: archiveUploadTimeout},
+		region:      cfg.Region,
+		credentials: credentials,
+	}
+	if cfg.PathStyle {
+		store.baseURL = endpoint + "/" + awsEscape(bucket)
+		store.pathPrefix = "/" + awsEscape(bucket)
+	} else {
+		store.baseURL = fmt.Sprintf("%s://%s.%s", parsed.Scheme, bucket, parsed.Host)
+	}
+	return store, nil
+}
+
+func (s *s3Store) upload(ctx context.Context, key, contentType string, data []byte) error {
+	path := s3ObjectPath(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	s.sign(req, s.pathPrefix+path, "", data, time.Now().UTC())
+
+	_, err = s.do(req, key)
+	return err
+}
+
+// s3ListResult ListObjectsV2响应中用到的字段
+type s3ListResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// list 列出前缀下的全部对象键，按continuation-token翻页
+func (s *s3Store) list(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	token := ""
+	for {
+		params := map[string]string{"list-type": "2", "prefix": prefix}
+		if token != "" {
+			params["continuation-token"] = token
+		}
+		query := awsCanonicalQuery(params)
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"/?"+query, nil)
+		if err != nil {
+			return nil, err
+		}
+		s.sign(req, s.pathPrefix+"/", query, nil, time.Now().UTC())
+
+		body, err := s.do(req, prefix)
+		if err != nil {
+			return nil, err
+		}
+		var result s3ListResult
+		if err := xml.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse s3 listing: %w", err)
+		}
+		for _, object := range result.Contents {
+			keys = append(keys, object.Key)
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return keys, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+func (s *s3Store) download(ctx context.Context, key string) ([]byte, error) {
+	path := s3ObjectPath(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.sign(req, s.pathPrefix+path, "", nil, time.Now().UTC())
+	return s.do(req, key)
+}
+
+// do 发送请求并读取响应，非200时返回错误
+func (s *s3Store) do(req *http.Request, key string) ([]byte, error) {
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("s3 returned %d for %s: %s", resp.StatusCode, key, bytes.TrimSpace(message))
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// s3ObjectPath 对象键按片段编码后的请求路径
+func s3ObjectPath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = awsEscape(segment)
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// awsCanonicalQuery 按参数名排序并编码的查询字符串，请求与签名使用同一字符串
+func awsCanonicalQuery(params map[string]string) string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, awsEscape(name)+"="+awsEscape(params[name]))
+	}
+	return strings.Join(pairs, "&")
+}
+
+// sign 按AWS Signature Version 4签名请求，S3要求附加x-amz-content-sha256头
+func (s *s3Store) sign(req *http.Request, canonicalURI, canonicalQuery string, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.credentials.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.credentials.sessionToken)
+	}
+
+	// 签名的头须按名称排序，没有请求体时不签content-type
+	var signedHeaders []string
+	if req.Header.Get("Content-Type") != "" {
+		signedHeaders = append(signedHeaders, "content-type")
+	}
+	signedHeaders = append(signedHeaders, "host", "x-amz-content-sha256", "x-amz-date")
+	if s.credentials.sessionToken != "" {
+		signedHeaders = append(signedHeaders, "x-amz-security-token")
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, name := range signedHeaders {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.URL.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI,
+		canonicalQuery,
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", date, s.region)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.credentials.secretAccessKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.credentials.accessKeyID, scope, strings.Join(signedHeaders, ";"), signature))
+}
+
+// awsEscape 按SigV4规范编码路径片段，只保留字母、数字及 -_.~
+func awsEscape(segment string) string {
+	var b strings.Builder
+	for i := 0; i < len(segment); i++ {
+		c := segment[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// gcsStore 通过JSON API读写GCS，写入使用简单上传
+type gcsStore struct {
+	http     *http.Client
+	endpoint string
+	bucket   string
+	tokens   *googleTokenSource // 连接模拟器时为nil
+}
+
+func newGCSStore(cfg config.ArchiveGCSConfig, bucket string) (*gcsStore, error) {
+	endpoint := strings.TrimRight(cfg.Endpoint, "/")
+	if endpoint == "" {
+		endpoint = "https://storage.googleapis.com"
+	}
+
+	var tokens *googleTokenSource
+	if host := os.Getenv("STORAGE_EMULATOR_HOST"); host != "" {
+		endpoint = "http://" + strings.TrimPrefix(host, "http://")
+	} else {
+		credentialsFile := cfg.CredentialsFile
+		if credentialsFile == "" {
+			credentialsFile = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+		}
+		var err error
+		if tokens, err = newGoogleTokenSource(credentialsFile, gcsScope); err != nil {
+			return nil, err
+		}
+	}
+
+	return &gcsStore{
+		http:     &http.Client{Timeout: archiveUploadTimeout},
+		endpoint: endpoint,
+		bucket:   url.PathEscape(bucket),
+		tokens:   tokens,
+	}, nil
+}
+
+func (s *gcsStore) upload(ctx context.Context, key, contentType string, data []byte) error {
+	query := url.Values{"uploadType": {"media"}, "name": {key}}
+	target := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?%s", s.endpoint, s.bucket, query.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	_, err = s.do(ctx, req, key)
+	return err
+}
+
+// gcsListResult 对象列表响应中用到的字段
+type gcsListResult struct {
+	Items []struct {
+		Name string `json:"name"`
+	} `json:"items"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// list 列出前缀下的全部对象名，按pageToken翻页
+func (s *gcsStore) list(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+	token := ""
+	for {
+		query := url.Values{"prefix": {prefix}}
+		if token != "" {
+			query.Set("pageToken", token)
+		}
+		target := fmt.Sprintf("%s/storage/v1/b/%s/o?%s", s.endpoint, s.bucket, query.Encode())
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := s.do(ctx, req, prefix)
+		if err != nil {
+			return nil, err
+		}
+		var result gcsListResult
+		if err := json.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse gcs listing: %w", err)
+		}
+		for _, item := range result.Items {
+			names = append(names, item.Name)
+		}
+		if result.NextPageToken == "" {
+			return names, nil
+		}
+		token = result.NextPageToken
+	}
+}
+
+func (s *gcsStore) download(ctx context.Context, key string) ([]byte, error) {
+	target := fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media", s.endpoint, s.bucket, url.PathEscape(key))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.do(ctx, req, key)
+}
+
+// do 附加访问令牌后发送请求并读取响应，非200时返回错误
+func (s *gcsStore) do(ctx context.Context, req *http.Request, key string) ([]byte, error) {
+	if s.tokens != nil {
+		token, err := s.tokens.token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get access token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("gcs returned %d for %s: %s", resp.StatusCode, key, bytes.TrimSpace(message))
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,208 @@
+package publisher
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+
+	"web3-data-collector/internal/grpcapi/collectorpb"
+)
+
+// Avro schema与collector.proto中的消息字段一一对应，时间为毫秒时间戳
+const avroTransactionSchema = `{
+  "type": "record", "name": "Transaction", "namespace": "web3.collector.v1",
+  "fields": [
+    {"name": "hash", "type": "string"},
+    {"name": "block_number", "type": "long"},
+    {"name": "block_hash", "type": "string"},
+    {"name": "transaction_index", "type": "long"},
+    {"name": "from_address", "type": "string"},
+    {"name": "to_address", "type": "string"},
+    {"name": "value", "type": "string"},
+    {"name": "gas", "type": "long"},
+    {"name": "gas_price", "type": "string"},
+    {"name": "gas_used", "type": "long"},
+    {"name": "nonce", "type": "long"},
+    {"name": "input_data", "type": "string"},
+    {"name": "timestamp", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+    {"name": "network", "type": "string"},
+    {"name": "status", "type": "long"},
+    {"name": "contract_address", "type": "string"},
+    {"name": "is_contract_call", "type": "boolean"},
+    {"name": "is_token_transfer", "type": "boolean"},
+    {"name": "transaction_type", "type": "long"}
+  ]
+}`
+
+var avroBlockSchema = `{
+  "type": "record", "name": "Block", "namespace": "web3.collector.v1",
+  "fields": [
+    {"name": "number", "type": "long"},
+    {"name": "hash", "type": "string"},
+    {"name": "parent_hash", "type": "string"},
+    {"name": "timestamp", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+    {"name": "difficulty", "type": "string"},
+    {"name": "gas_limit", "type": "long"},
+    {"name": "gas_used", "type": "long"},
+    {"name": "miner", "type": "string"},
+    {"name": "network", "type": "string"},
+    {"name": "tx_count", "type": "int"},
+    {"name": "size", "type": "long"},
+    {"name": "base_fee_per_gas", "type": "string"},
+    {"name": "transactions", "type": {"type": "array", "items": ` + avroTransactionSchema + `}}
+  ]
+}`
+
+const avroAlertSchema = `{
+  "type": "record", "name": "Alert", "namespace": "web3.collector.v1",
+  "fields": [
+    {"name": "id", "type": "string"},
+    {"name": "type", "type": "string"},
+    {"name": "level", "type": "string"},
+    {"name": "title", "type": "string"},
+    {"name": "description", "type": "string"},
+    {"name": "transaction_hash", "type": "string"},
+    {"name": "address", "type": "string"},
+    {"name": "network", "type": "string"},
+    {"name": "risk_score", "type": "double"},
+    {"name": "risk_factors", "type": {"type": "array", "items": "string"}},
+    {"name": "timestamp", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+    {"name": "status", "type": "string"},
+    {"name": "metadata", "type": {"type": "map", "values": "string"}}
+  ]
+}`
+
+// avroWriter Avro二进制编码
+type avroWriter struct {
+	buf []byte
+}
+
+// long int与long均为zigzag变长编码
+func (w *avroWriter) long(value int64) {
+	w.buf = binary.AppendVarint(w.buf, value)
+}
+
+func (w *avroWriter) uint(value uint64) {
+	if value > math.MaxInt64 {
+		value = math.MaxInt64
+	}
+	w.long(int64(value))
+}
+
+func (w *avroWriter) string(value string) {
+	w.long(int64(len(value)))
+	w.buf = append(w.buf, value...)
+}
+
+func (w *avroWriter) boolean(value bool) {
+	if value {
+		w.buf = append(w.buf, 1)
+	} else {
+		w.buf = append(w.buf, 0)
+	}
+}
+
+func (w *avroWriter) double(value float64) {
+	w.buf = binary.LittleEndian.AppendUint64(w.buf, math.Float64bits(value))
+}
+
+func (w *avroWriter) transaction(tx *collectorpb.Transaction) {
+	w.string(tx.Hash)
+	w.uint(tx.BlockNumber)
+	w.string(tx.BlockHash)
+	w.uint(uint64(tx.TransactionIndex))
+	w.string(tx.FromAddress)
+	w.string(tx.ToAddress)
+	w.string(tx.Value)
+	w.uint(tx.Gas)
+	w.string(tx.GasPrice)
+	w.uint(tx.GasUsed)
+	w.uint(tx.Nonce)
+	w.string(tx.InputData)
+	w.long(tx.Timestamp.AsTime().UnixMilli())
+	w.string(tx.Network)
+	w.uint(tx.Status)
+	w.string(tx.ContractAddress)
+	w.boolean(tx.IsContractCall)
+	w.boolean(tx.IsTokenTransfer)
+	w.uint(uint64(tx.TransactionType))
+}
+
+func (w *avroWriter) block(block *collectorpb.Block) {
+	w.uint(block.Number)
+	w.string(block.Hash)
+	w.string(block.ParentHash)
+	w.long(block.Timestamp.AsTime().UnixMilli())
+	w.string(block.Difficulty)
+	w.uint(block.GasLimit)
+	w.uint(block.GasUsed)
+	w.string(block.Miner)
+	w.string(block.Network)
+	w.long(int64(block.TxCount))
+	w.uint(block.Size)
+	w.string(block.BaseFeePerGas)
+
+	// 数组按块编码：元素个数 + 元素，以0结束
+	if len(block.Transactions) > 0 {
+		w.long(int64(len(block.Transactions)))
+		for _, tx := range block.Transactions {
+			w.transaction(tx)
+		}
+	}
+	w.long(0)
+}
+
+func (w *avroWriter) alert(alert *collectorpb.Alert) {
+	w.string(alert.Id)
+	w.string(alert.Type)
+	w.string(alert.Level)
+	w.string(alert.Title)
+	w.string(alert.Description)
+	w.string(alert.TransactionHash)
+	w.string(alert.Address)
+	w.string(alert.Network)
+	w.double(alert.RiskScore)
+
+	if len(alert.RiskFactors) > 0 {
+		w.long(int64(len(alert.RiskFactors)))
+		for _, factor := range alert.RiskFactors {
+			w.string(factor)
+		}
+	}
+	w.long(0)
+
+	w.long(alert.Timestamp.AsTime().UnixMilli())
+	w.string(alert.Status)
+
+	// 按键排序，相同告警编码结果稳定
+	if len(alert.Metadata) > 0 {
+		keys := make([]string, 0, len(alert.Metadata))
+		for key := range alert.Metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		w.long(int64(len(keys)))
+		for _, key := range keys {
+			w.string(key)
+			w.string(alert.Metadata[key])
+		}
+	}
+	w.long(0)
+}
+
+// avroEncode 按消息类型进行Avro编码
+func avroEncode(message interface{}) ([]byte, error) {
+	w := &avroWriter{}
+	switch value := message.(type) {
+	case *collectorpb.Transaction:
+		w.transaction(value)
+	case *collectorpb.Block:
+		w.block(value)
+	case *collectorpb.Alert:
+		w.alert(value)
+	default:
+		return nil, fmt.Errorf("unsupported avro message type %T", message)
+	}
+	return w.buf, nil
+}
@@ -0,0 +1,205 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// 消息后端
+const (
+	BackendKafka   = "kafka"
+	BackendKinesis = "kinesis"
+	BackendPubSub  = "pubsub"
+)
+
+// cloudFlushTimeout 异步写出一批消息的超时
+const cloudFlushTimeout = 30 * time.Second
+
+// messageWriter 单个主题的写入器，kinesis/pubsub后端实现与kafka.Writer相同的方法
+type messageWriter interface {
+	WriteMessages(ctx context.Context, messages ...kafka.Message) error
+	Stats() kafka.WriterStats
+	Close() error
+}
+
+// recordSender 将一批消息写入kinesis stream或pubsub topic，超出单次请求上限时自行拆分
+type recordSender interface {
+	send(ctx context.Context, messages []kafka.Message) error
+}
+
+// newSenderFunc 按stream名/topic ID创建recordSender
+type newSenderFunc func(topic string) recordSender
+
+// senderFactory 按配置的后端创建recordSender的构造函数，kafka后端返回nil
+func (kp *KafkaPublisher) senderFactory() (newSenderFunc, error) {
+	switch kp.config.Backend {
+	case BackendKinesis:
+		return newKinesisSenders(kp.config.Kinesis, kp.config.Producer.MaxAttempts)
+	case BackendPubSub:
+		return newPubSubSenders(kp.config.PubSub, kp.config.Producer.MaxAttempts)
+	case "", BackendKafka:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown backend: %s", kp.config.Backend)
+	}
+}
+
+// cloudWriter kinesis/pubsub的写入器；异步模式与kafka写入器一致，按批量或超时写出，失败记录日志并经completion回调
+type cloudWriter struct {
+	topic        string
+	sender       recordSender
+	async        bool
+	batchSize    int
+	batchTimeout time.Duration
+	completion   func(messages []kafka.Message, err error) // 与kafka.Writer的Completion相同，写出后回调
+
+	buffer   []kafka.Message
+	timer    *time.Timer
+	inflight sync.WaitGroup
+	mu       sync.Mutex
+
+	writes   int64
+	messages int64
+	bytes    int64
+	errors   int64
+}
+
+func newCloudWriter(topic string, sender recordSender, async bool, batchSize int, batchTimeout time.Duration) *cloudWriter {
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	return &cloudWriter{
+		topic:        topic,
+		sender:       sender,
+		async:        async,
+		batchSize:    batchSize,
+		batchTimeout: batchTimeout,
+	}
+}
+
+// WriteMessages 同步模式下写出后返回，异步模式下加入缓冲即返回
+func (w *cloudWriter) WriteMessages(ctx context.Context, messages ...kafka.Message) error {
+	if !w.async {
+		return w.write(ctx, messages)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buffer = append(w.buffer, messages...)
+	if len(w.buffer) >= w.batchSize {
+		w.flushLocked()
+	} else if w.timer == nil {
+		w.timer = time.AfterFunc(w.batchTimeout, func() {
+			w.mu.Lock()
+			defer w.mu.Unlock()
+			w.flushLocked()
+		})
+	}
+	return nil
+}
+
+// flushLocked 取出缓冲的消息在后台写出，调用方须持有锁
+func (w *cloudWriter) flushLocked() {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	if len(w.buffer) == 0 {
+		return
+	}
+
+	batch := w.buffer
+	w.buffer = nil
+	w.inflight.Add(1)
+	go func() {
+		defer w.inflight.Done()
+
+		ctx, cancel := context.WithTimeout(context.Background(), cloudFlushTimeout)
+		defer cancel()
+		if err := w.write(ctx, batch); err != nil {
+			logger.Errorf("Failed to write %d messages to %s: %v", len(batch), w.topic, err)
+		}
+	}()
+}
+
+func (w *cloudWriter) write(ctx context.Context, messages []kafka.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	atomic.AddInt64(&w.writes, 1)
+	err := w.sender.send(ctx, messages)
+	if w.completion != nil {
+		w.completion(messages, err)
+	}
+	if err != nil {
+		atomic.AddInt64(&w.errors, 1)
+		return err
+	}
+
+	var size int64
+	for _, message := range messages {
+		size += int64(len(message.Value))
+	}
+	atomic.AddInt64(&w.messages, int64(len(messages)))
+	atomic.AddInt64(&w.bytes, size)
+	return nil
+}
+
+// Stats 写入统计，只填写次数、消息数、字节数及错误数
+func (w *cloudWriter) Stats() kafka.WriterStats {
+	return kafka.WriterStats{
+		Writes:   atomic.LoadInt64(&w.writes),
+		Messages: atomic.LoadInt64(&w.messages),
+		Bytes:    atomic.LoadInt64(&w.bytes),
+		Errors:   atomic.LoadInt64(&w.errors),
+		Topic:    w.topic,
+	}
+}
+
+// Close 写出剩余的缓冲并等待进行中的写出完成
+func (w *cloudWriter) Close() error {
+	w.mu.Lock()
+	w.flushLocked()
+	w.mu.Unlock()
+
+	w.inflight.Wait()
+	return nil
+}
+
+// messageAttributes 消息头转换为属性，消息键以key属性保存
+func messageAttributes(message kafka.Message) map[string]string {
+	attributes := make(map[string]string, len(message.Headers)+1)
+	for _, header := range message.Headers {
+		attributes[header.Key] = string(header.Value)
+	}
+	if len(message.Key) > 0 {
+		attributes["key"] = string(message.Key)
+	}
+	return attributes
+}
+
+// retryDelay 第attempt次重试前的等待时间
+func retryDelay(attempt int) time.Duration {
+	delay := time.Duration(attempt) * 200 * time.Millisecond
+	if delay > 5*time.Second {
+		delay = 5 * time.Second
+	}
+	return delay
+}
+
+// sleepContext 等待d或ctx结束
+func sleepContext(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
@@ -0,0 +1,76 @@
+package publisher
+
+import (
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// DeliveryReport 单条消息的写出结果，写入器收到broker确认或写出失败后回调
+type DeliveryReport struct {
+	Topic   string      // 写入器名称，如 transactions
+	Kind    string      // 数据类型，与输出管道的数据类型相同，如 transaction
+	Network string      // 数据所属网络
+	Payload interface{} // 发布的原始数据，写出失败时可写入死信队列
+	Latency time.Duration
+	Async   bool // 异步写出，错误未返回给发布方
+	Err     error
+}
+
+// DeliveryHandler 处理消息的写出结果，在写入器的后台goroutine中调用，不应长时间阻塞
+type DeliveryHandler func(report *DeliveryReport)
+
+// deliveryContext 随消息传递给写入器回调的发布信息
+type deliveryContext struct {
+	kind        string
+	network     string
+	payload     interface{}
+	publishedAt time.Time
+}
+
+// SetDeliveryHandler 设置写出结果的处理函数，需在开始发布前调用
+func (kp *KafkaPublisher) SetDeliveryHandler(handler DeliveryHandler) {
+	kp.deliveryHandler = handler
+}
+
+// Sync 是否同步写出，每条消息等待broker确认后返回
+func (kp *KafkaPublisher) Sync() bool {
+	return kp.config.Producer.Sync
+}
+
+// track 记录消息的数据类型及发布时间，写出后回调时据此计算延迟
+func track(message *kafka.Message, kind, network string, payload interface{}) {
+	message.WriterData = &deliveryContext{
+		kind:        kind,
+		network:     network,
+		payload:     payload,
+		publishedAt: time.Now(),
+	}
+}
+
+// completion 写入器的写出回调，将各消息的结果交给DeliveryHandler，未经track的消息（如健康检查）忽略
+func (kp *KafkaPublisher) completion(name string, async bool) func(messages []kafka.Message, err error) {
+	return func(messages []kafka.Message, err error) {
+		handler := kp.deliveryHandler
+		if handler == nil {
+			return
+		}
+
+		now := time.Now()
+		for _, message := range messages {
+			delivery, ok := message.WriterData.(*deliveryContext)
+			if !ok {
+				continue
+			}
+			handler(&DeliveryReport{
+				Topic:   name,
+				Kind:    delivery.kind,
+				Network: delivery.network,
+				Payload: delivery.payload,
+				Latency: now.Sub(delivery.publishedAt),
+				Async:   async,
+				Err:     err,
+			})
+		}
+	}
+}
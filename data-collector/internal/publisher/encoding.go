@@ -0,0 +1,125 @@
+package publisher
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"web3-data-collector/internal/grpcapi/collectorpb"
+	"web3-data-collector/internal/models"
+
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/proto"
+)
+
+// 消息编码格式
+const (
+	EncodingJSON     = "json"
+	EncodingAvro     = "avro"
+	EncodingProtobuf = "protobuf"
+)
+
+// EncodableTopics 支持avro/protobuf编码的主题，其余主题固定为JSON
+var EncodableTopics = map[string]bool{
+	"transactions": true,
+	"blocks":       true,
+	"alerts":       true,
+}
+
+// protobufMessageIndexes 各主题消息在collector.proto中的定义顺序
+var protobufMessageIndexes = map[string]int{
+	"transactions": 0,
+	"blocks":       1,
+	"alerts":       2,
+}
+
+var avroSchemas = map[string]string{
+	"transactions": avroTransactionSchema,
+	"blocks":       avroBlockSchema,
+	"alerts":       avroAlertSchema,
+}
+
+// topicEncoder 主题的编码方式及注册得到的schema ID
+type topicEncoder struct {
+	encoding     string
+	schemaID     uint32
+	messageIndex []byte
+}
+
+// createEncoders 为配置了avro/protobuf的主题在schema registry注册schema，subject为 主题名-value
+func (kp *KafkaPublisher) createEncoders() error {
+	var registry *schemaRegistry
+	for name, encoding := range kp.config.Encoding {
+		if encoding == "" || encoding == EncodingJSON {
+			continue
+		}
+		topic, exists := kp.topics[name]
+		if !exists {
+			continue
+		}
+		if registry == nil {
+			registry = newSchemaRegistry(kp.config.SchemaRegistry)
+		}
+
+		encoder := &topicEncoder{encoding: encoding}
+		subject := topic + "-value"
+
+		var err error
+		switch encoding {
+		case EncodingProtobuf:
+			encoder.schemaID, err = registry.register(subject, "PROTOBUF", collectorpb.ProtoSchema)
+			encoder.messageIndex = protobufMessageIndex(protobufMessageIndexes[name])
+		case EncodingAvro:
+			encoder.schemaID, err = registry.register(subject, "AVRO", avroSchemas[name])
+		default:
+			return fmt.Errorf("unsupported encoding %q for %s", encoding, name)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to register %s schema for %s: %w", encoding, subject, err)
+		}
+
+		kp.encoders[name] = encoder
+		logger.Infof("Encoding %s messages as %s (schema id: %d)", topic, encoding, encoder.schemaID)
+	}
+	return nil
+}
+
+// encode 按主题配置的格式序列化消息，未配置时为JSON
+func (kp *KafkaPublisher) encode(name string, value interface{}) ([]byte, error) {
+	encoder, exists := kp.encoders[name]
+	if !exists {
+		return json.Marshal(value)
+	}
+
+	var message proto.Message
+	switch v := value.(type) {
+	case *models.Transaction:
+		message = collectorpb.FromTransaction(v)
+	case *models.Block:
+		message = collectorpb.FromBlock(v)
+	case *models.RiskAlert:
+		message = collectorpb.FromAlert(v)
+	default:
+		return nil, fmt.Errorf("unsupported message type %T", value)
+	}
+
+	var payload []byte
+	var err error
+	if encoder.encoding == EncodingProtobuf {
+		payload, err = proto.Marshal(message)
+	} else {
+		payload, err = avroEncode(message)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return confluentFrame(encoder.schemaID, encoder.messageIndex, payload), nil
+}
+
+// contentType 标记消息的编码格式，便于消费端选择反序列化方式
+func (kp *KafkaPublisher) contentType(message *kafka.Message, name string) {
+	encoding := EncodingJSON
+	if encoder, exists := kp.encoders[name]; exists {
+		encoding = encoder.encoding
+	}
+	message.Headers = append(message.Headers, kafka.Header{Key: "content_type", Value: []byte(encoding)})
+}
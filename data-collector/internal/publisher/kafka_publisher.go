@@ -4,21 +4,45 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/logging"
 	"web3-data-collector/internal/models"
 
 	"github.com/segmentio/kafka-go"
-	"github.com/sirupsen/logrus"
 )
 
-// KafkaPublisher Kafka消息发布器
+// logger publisher模块日志，级别可通过logging.modules.publisher单独设置
+var logger = logging.Module("publisher")
+
+// KafkaPublisher Kafka消息发布器，也可按配置写入Kinesis或Pub/Sub，消息内容与头相同
 type KafkaPublisher struct {
 	config      config.KafkaConfig
-	writers     map[string]*kafka.Writer
+	writers     map[string]messageWriter
+	topics      map[string]string // 各写入器的主题名
 	batchSize   int
 	batchTimeout time.Duration
+	pending     map[pendingKey]*pendingBlock // 事务模式下正在处理的区块
+	txn         *txnProducer                // 事务模式下写出区块交易消息
+	pendingMu   sync.Mutex
+	encoders    map[string]*topicEncoder // 使用avro/protobuf编码的主题
+	compression kafka.Compression
+	maxMessageBytes int
+	deliveryHandler DeliveryHandler // 写出结果回调，未设置时只记录日志
+}
+
+// pendingKey 缓冲区块的键，同一网络的不同区块（如重新提交的区块与最新区块）可同时处理
+type pendingKey struct {
+	network string
+	number  uint64
+}
+
+// pendingBlock 事务模式下缓冲的区块交易消息
+type pendingBlock struct {
+	committed bool // 区块此前已提交，消息直接丢弃
+	messages  []kafka.Message
 }
 
 // NewKafkaPublisher 创建新的Kafka发布器
@@ -28,43 +52,113 @@ func NewKafkaPublisher(config config.KafkaConfig) (*KafkaPublisher, error) {
 		batchTimeout = 1 * time.Second
 	}
 
+	compression, err := compressionCodec(config.Producer.Compression)
+	if err != nil {
+		return nil, err
+	}
+	maxMessageBytes := config.Producer.MaxMessageBytes
+	if maxMessageBytes <= 0 {
+		maxMessageBytes = defaultMaxMessageBytes
+	}
+
 	publisher := &KafkaPublisher{
-		config:       config,
-		writers:      make(map[string]*kafka.Writer),
-		batchSize:    config.Producer.BatchSize,
-		batchTimeout: batchTimeout,
+		config:          config,
+		writers:         make(map[string]messageWriter),
+		topics:          make(map[string]string),
+		batchSize:       config.Producer.BatchSize,
+		batchTimeout:    batchTimeout,
+		pending:         make(map[pendingKey]*pendingBlock),
+		encoders:        make(map[string]*topicEncoder),
+		compression:     compression,
+		maxMessageBytes: maxMessageBytes,
 	}
 
 	// 创建各主题的写入器
 	if err := publisher.createWriters(); err != nil {
 		return nil, fmt.Errorf("failed to create Kafka writers: %w", err)
 	}
+	if config.Producer.Transactional && config.Topics.Transactions != "" {
+		publisher.txn = newTxnProducer(config.Brokers, config.Topics.Transactions, config.Producer.TransactionalID, compression)
+	}
+
+	if err := publisher.createEncoders(); err != nil {
+		return nil, fmt.Errorf("failed to create Kafka encoders: %w", err)
+	}
 
 	return publisher, nil
 }
 
-// createWriters 创建Kafka写入器
+// createWriters 按配置的后端创建各主题的写入器
 func (kp *KafkaPublisher) createWriters() error {
 	topics := map[string]string{
-		"transactions": kp.config.Topics.Transactions,
-		"blocks":       kp.config.Topics.Blocks,
-		"alerts":       kp.config.Topics.Alerts,
+		"transactions":     kp.config.Topics.Transactions,
+		"blocks":           kp.config.Topics.Blocks,
+		"alerts":           kp.config.Topics.Alerts,
+		"events":           kp.config.Topics.Events,
+		"headers":          kp.config.Topics.Headers,
+		"gas_stats":        kp.config.Topics.GasStats,
+		"withdrawals":      kp.config.Topics.Withdrawals,
+		"bridge_transfers": kp.config.Topics.BridgeTransfers,
+		"enriched":         kp.config.Topics.EnrichedBlocks,
+		"dead_letter":      kp.config.Topics.DeadLetter,
+	}
+
+	// kinesis/pubsub后端的写入器构造函数，kafka后端为nil
+	newSender, err := kp.senderFactory()
+	if err != nil {
+		return err
+	}
+
+	// kafka-go的Writer不支持broker端的幂等生产者，以全副本确认加确定性message_id代替；
+	// 事务模式下区块交易消息经txnProducer以幂等事务写出
+	requiredAcks := kafka.RequireOne
+	if kp.config.Producer.Idempotent {
+		requiredAcks = kafka.RequireAll
 	}
 
 	for name, topic := range topics {
-		writer := &kafka.Writer{
+		if topic == "" {
+			continue
+		}
+
+		batchSize, batchTimeout, async := kp.batchSize, kp.batchTimeout, true
+
+		// 区块头摘要要求低延迟，死信需逐条确认写入，均不等待批量
+		if name == "headers" || name == "dead_letter" {
+			batchSize = 1
+			batchTimeout = time.Millisecond
+		}
+
+		// 同步模式下逐条等待确认，不等待凑满批量
+		if kp.Sync() {
+			async = false
+			batchTimeout = time.Millisecond
+		}
+
+		kp.topics[name] = topic
+		if newSender != nil {
+			writer := newCloudWriter(topic, newSender(topic), async, batchSize, batchTimeout)
+			writer.completion = kp.completion(name, async)
+			kp.writers[name] = writer
+			logger.Infof("Created %s writer for topic: %s", kp.config.Backend, topic)
+			continue
+		}
+
+		kp.writers[name] = &kafka.Writer{
 			Addr:         kafka.TCP(kp.config.Brokers...),
 			Topic:        topic,
 			Balancer:     &kafka.LeastBytes{},
-			BatchSize:    kp.batchSize,
-			BatchTimeout: kp.batchTimeout,
-			RequiredAcks: kafka.RequireOne,
-			Async:        true,
-			ErrorLogger:  kafka.LoggerFunc(logrus.Errorf),
+			BatchSize:    batchSize,
+			BatchTimeout: batchTimeout,
+			BatchBytes:   int64(kp.maxMessageBytes),
+			Compression:  kp.compression,
+			MaxAttempts:  kp.config.Producer.MaxAttempts,
+			RequiredAcks: requiredAcks,
+			Async:        async,
+			Completion:   kp.completion(name, async),
+			ErrorLogger:  kafka.LoggerFunc(logger.Errorf),
 		}
-
-		kp.writers[name] = writer
-		logrus.Infof("Created Kafka writer for topic: %s", topic)
+		logger.Infof("Created Kafka writer for topic: %s", topic)
 	}
 
 	return nil
@@ -78,7 +172,7 @@ func (kp *KafkaPublisher) PublishTransaction(tx *models.Transaction) error {
 	}
 
 	// 序列化交易数据
-	data, err := json.Marshal(tx)
+	data, err := kp.encode("transactions", tx)
 	if err != nil {
 		return fmt.Errorf("failed to marshal transaction: %w", err)
 	}
@@ -89,12 +183,22 @@ func (kp *KafkaPublisher) PublishTransaction(tx *models.Transaction) error {
 		Value: data,
 		Headers: []kafka.Header{
 			{Key: "network", Value: []byte(tx.Network)},
+			{Key: "record_id", Value: []byte(tx.ID)},
 			{Key: "block_number", Value: []byte(fmt.Sprintf("%d", tx.BlockNumber))},
 			{Key: "timestamp", Value: []byte(fmt.Sprintf("%d", tx.Timestamp.Unix()))},
 			{Key: "message_type", Value: []byte("transaction")},
 		},
 		Time: tx.Timestamp,
 	}
+	kp.decorate(&message, recordMessageID(tx.ID, "transaction", tx.Network, tx.Hash))
+	labelFinality(&message, tx.Finality)
+	kp.contentType(&message, "transactions")
+	track(&message, "transaction", tx.Network, tx)
+
+	// 事务模式下缓冲到区块提交时写出
+	if kp.bufferTransaction(tx, message) {
+		return nil
+	}
 
 	// 发送消息
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -104,10 +208,128 @@ func (kp *KafkaPublisher) PublishTransaction(tx *models.Transaction) error {
 		return fmt.Errorf("failed to write transaction message: %w", err)
 	}
 
-	logrus.Debugf("Published transaction %s to Kafka", tx.Hash)
+	logger.Debugf("Published transaction %s to Kafka", tx.Hash)
+	return nil
+}
+
+// Transactional 是否按区块提交交易消息
+func (kp *KafkaPublisher) Transactional() bool {
+	return kp.txn != nil
+}
+
+// BeginBlock 开始缓冲区块的交易消息，committed为true时区块已提交过，消息直接丢弃
+func (kp *KafkaPublisher) BeginBlock(network string, number uint64, committed bool) {
+	if !kp.Transactional() {
+		return
+	}
+
+	kp.pendingMu.Lock()
+	defer kp.pendingMu.Unlock()
+	kp.pending[pendingKey{network, number}] = &pendingBlock{committed: committed}
+}
+
+// CommitBlock 在一个Kafka事务内写出区块缓冲的交易消息，失败时事务中止，消费端看不到其中任何消息
+func (kp *KafkaPublisher) CommitBlock(network string, number uint64) error {
+	block := kp.takePending(network, number)
+	if block == nil || block.committed || len(block.messages) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := kp.txn.commit(ctx, block.messages); err != nil {
+		return fmt.Errorf("failed to commit block %d of %s: %w", number, network, err)
+	}
+
+	logger.Debugf("Committed %d transactions of block %d for %s to Kafka", len(block.messages), number, network)
 	return nil
 }
 
+// AbortBlock 丢弃区块缓冲的交易消息
+func (kp *KafkaPublisher) AbortBlock(network string, number uint64) {
+	kp.takePending(network, number)
+}
+
+// bufferTransaction 交易属于正在处理的区块时缓冲消息并返回true
+func (kp *KafkaPublisher) bufferTransaction(tx *models.Transaction, message kafka.Message) bool {
+	kp.pendingMu.Lock()
+	defer kp.pendingMu.Unlock()
+
+	block, exists := kp.pending[pendingKey{tx.Network, tx.BlockNumber}]
+	if !exists {
+		return false
+	}
+	if !block.committed {
+		block.messages = append(block.messages, message)
+	}
+	return true
+}
+
+// takePending 取出并移除网络指定区块的缓冲
+func (kp *KafkaPublisher) takePending(network string, number uint64) *pendingBlock {
+	kp.pendingMu.Lock()
+	defer kp.pendingMu.Unlock()
+
+	key := pendingKey{network, number}
+	block, exists := kp.pending[key]
+	if !exists {
+		return nil
+	}
+	delete(kp.pending, key)
+	return block
+}
+
+// messageID 消息的幂等键，只由数据内容决定，同一数据重复写入或由不同区域写入时相同
+func messageID(kind string, parts ...string) string {
+	id := kind
+	for _, part := range parts {
+		id += ":" + part
+	}
+	return id
+}
+
+// recordMessageID 优先使用记录ID作为幂等键，未分配ID的记录按内容生成
+func recordMessageID(recordID, kind string, parts ...string) string {
+	if recordID != "" {
+		return recordID
+	}
+	return messageID(kind, parts...)
+}
+
+// alertMessageID 告警ID由触发记录派生，未分配ID时改用告警类型及关联的交易/地址
+func alertMessageID(alert *models.RiskAlert) string {
+	return recordMessageID(alert.ID, "alert", alert.Network, alert.Type, alert.TransactionHash, alert.Address, fmt.Sprint(alert.Timestamp.Unix()))
+}
+
+// labelFinality 附加区块确认状态头（latest/safe/finalized，Solana为确认级别），
+// 消费端可据此只取不会因链重组撤回的数据
+func labelFinality(message *kafka.Message, finality string) {
+	if finality != "" {
+		message.Headers = append(message.Headers, kafka.Header{Key: "finality", Value: []byte(finality)})
+	}
+}
+
+// decorate 附加幂等键及区域头，id为空时只附加区域头
+func (kp *KafkaPublisher) decorate(message *kafka.Message, id string) {
+	if id != "" {
+		message.Headers = append(message.Headers, kafka.Header{Key: "message_id", Value: []byte(id)})
+	}
+
+	region := kp.config.Region
+	if region.Name == "" {
+		return
+	}
+	message.Headers = append(message.Headers, kafka.Header{Key: "source_region", Value: []byte(region.Name)})
+	if region.PrimaryRegion != "" {
+		role := "secondary"
+		if region.Name == region.PrimaryRegion {
+			role = "primary"
+		}
+		message.Headers = append(message.Headers, kafka.Header{Key: "region_role", Value: []byte(role)})
+	}
+}
+
 // PublishBlock 发布区块数据
 func (kp *KafkaPublisher) PublishBlock(block *models.Block) error {
 	writer, exists := kp.writers["blocks"]
@@ -115,11 +337,17 @@ func (kp *KafkaPublisher) PublishBlock(block *models.Block) error {
 		return fmt.Errorf("block writer not found")
 	}
 
-	// 序列化区块数据
-	data, err := json.Marshal(block)
+	// 序列化区块数据，配置不含交易或超出消息上限时去掉交易列表
+	data, err := kp.encode("blocks", block)
 	if err != nil {
 		return fmt.Errorf("failed to marshal block: %w", err)
 	}
+	omitted := kp.config.Producer.OmitBlockTransactions || len(data) > kp.maxMessageBytes
+	if omitted && len(block.Transactions) > 0 {
+		if data, err = kp.encode("blocks", withoutTransactions(block)); err != nil {
+			return fmt.Errorf("failed to marshal block: %w", err)
+		}
+	}
 
 	// 创建消息
 	message := kafka.Message{
@@ -127,6 +355,7 @@ func (kp *KafkaPublisher) PublishBlock(block *models.Block) error {
 		Value: data,
 		Headers: []kafka.Header{
 			{Key: "network", Value: []byte(block.Network)},
+			{Key: "record_id", Value: []byte(block.ID)},
 			{Key: "block_number", Value: []byte(fmt.Sprintf("%d", block.Number))},
 			{Key: "timestamp", Value: []byte(fmt.Sprintf("%d", block.Timestamp.Unix()))},
 			{Key: "message_type", Value: []byte("block")},
@@ -134,6 +363,13 @@ func (kp *KafkaPublisher) PublishBlock(block *models.Block) error {
 		},
 		Time: block.Timestamp,
 	}
+	kp.decorate(&message, recordMessageID(block.ID, "block", block.Network, block.Hash))
+	labelFinality(&message, block.Finality)
+	kp.contentType(&message, "blocks")
+	track(&message, "block", block.Network, block)
+	if omitted {
+		labelTransactionsOmitted(&message)
+	}
 
 	// 发送消息
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -143,7 +379,7 @@ func (kp *KafkaPublisher) PublishBlock(block *models.Block) error {
 		return fmt.Errorf("failed to write block message: %w", err)
 	}
 
-	logrus.Debugf("Published block %d to Kafka", block.Number)
+	logger.Debugf("Published block %d to Kafka", block.Number)
 	return nil
 }
 
@@ -155,7 +391,7 @@ func (kp *KafkaPublisher) PublishAlert(alert *models.RiskAlert) error {
 	}
 
 	// 序列化告警数据
-	data, err := json.Marshal(alert)
+	data, err := kp.encode("alerts", alert)
 	if err != nil {
 		return fmt.Errorf("failed to marshal alert: %w", err)
 	}
@@ -168,12 +404,16 @@ func (kp *KafkaPublisher) PublishAlert(alert *models.RiskAlert) error {
 			{Key: "alert_type", Value: []byte(alert.Type)},
 			{Key: "alert_level", Value: []byte(alert.Level)},
 			{Key: "network", Value: []byte(alert.Network)},
+			{Key: "record_id", Value: []byte(alert.ID)},
 			{Key: "timestamp", Value: []byte(fmt.Sprintf("%d", alert.Timestamp.Unix()))},
 			{Key: "message_type", Value: []byte("alert")},
 			{Key: "risk_score", Value: []byte(fmt.Sprintf("%.2f", alert.RiskScore))},
 		},
 		Time: alert.Timestamp,
 	}
+	kp.decorate(&message, alertMessageID(alert))
+	kp.contentType(&message, "alerts")
+	track(&message, "alert", alert.Network, alert)
 
 	// 发送消息
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -183,11 +423,315 @@ func (kp *KafkaPublisher) PublishAlert(alert *models.RiskAlert) error {
 		return fmt.Errorf("failed to write alert message: %w", err)
 	}
 
-	logrus.Infof("Published alert %s (Level: %s) to Kafka", alert.ID, alert.Level)
+	logger.Infof("Published alert %s (Level: %s) to Kafka", alert.ID, alert.Level)
+	return nil
+}
+
+// PublishEvent 发布合约事件数据
+func (kp *KafkaPublisher) PublishEvent(event *models.Event) error {
+	writer, exists := kp.writers["events"]
+	if !exists {
+		return fmt.Errorf("event writer not found")
+	}
+
+	// 序列化事件数据
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	messageType := "event"
+	if event.Removed {
+		messageType = "event_retraction"
+	}
+
+	// 创建消息
+	message := kafka.Message{
+		Key:   []byte(fmt.Sprintf("%s:%d", event.TransactionHash, event.LogIndex)),
+		Value: data,
+		Headers: []kafka.Header{
+			{Key: "network", Value: []byte(event.Network)},
+			{Key: "record_id", Value: []byte(event.ID)},
+			{Key: "block_number", Value: []byte(fmt.Sprintf("%d", event.BlockNumber))},
+			{Key: "contract_address", Value: []byte(event.ContractAddress)},
+			{Key: "timestamp", Value: []byte(fmt.Sprintf("%d", event.Timestamp.Unix()))},
+			{Key: "message_type", Value: []byte(messageType)},
+		},
+		Time: event.Timestamp,
+	}
+	// 撤回消息与原事件的记录ID相同，幂等键需区分
+	id := recordMessageID(event.ID, "event", event.Network, event.TransactionHash, fmt.Sprint(event.LogIndex))
+	if event.Removed {
+		id = messageID("retraction", id)
+	}
+	kp.decorate(&message, id)
+	labelFinality(&message, event.Finality)
+	track(&message, "event", event.Network, event)
+
+	// 发送消息
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := writer.WriteMessages(ctx, message); err != nil {
+		return fmt.Errorf("failed to write event message: %w", err)
+	}
+
+	logger.Debugf("Published event %s:%d to Kafka", event.TransactionHash, event.LogIndex)
+	return nil
+}
+
+// PublishHeader 发布区块头摘要
+func (kp *KafkaPublisher) PublishHeader(header *models.BlockHeader) error {
+	writer, exists := kp.writers["headers"]
+	if !exists {
+		return fmt.Errorf("header writer not found")
+	}
+
+	data, err := json.Marshal(header)
+	if err != nil {
+		return fmt.Errorf("failed to marshal header: %w", err)
+	}
+
+	message := kafka.Message{
+		Key:   []byte(fmt.Sprintf("%d", header.Number)),
+		Value: data,
+		Headers: []kafka.Header{
+			{Key: "network", Value: []byte(header.Network)},
+			{Key: "block_number", Value: []byte(fmt.Sprintf("%d", header.Number))},
+			{Key: "timestamp", Value: []byte(fmt.Sprintf("%d", header.Timestamp.Unix()))},
+			{Key: "message_type", Value: []byte("header")},
+		},
+		Time: header.ObservedAt,
+	}
+	kp.decorate(&message, messageID("header", header.Network, header.Hash))
+	track(&message, "header", header.Network, header)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := writer.WriteMessages(ctx, message); err != nil {
+		return fmt.Errorf("failed to write header message: %w", err)
+	}
+
+	logger.Debugf("Published header %d to Kafka", header.Number)
 	return nil
 }
 
-// PublishBatch 批量发布消息
+// PublishGasStats 发布区块gas统计
+func (kp *KafkaPublisher) PublishGasStats(stats *models.GasStats) error {
+	writer, exists := kp.writers["gas_stats"]
+	if !exists {
+		return fmt.Errorf("gas stats writer not found")
+	}
+
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return fmt.Errorf("failed to marshal gas stats: %w", err)
+	}
+
+	message := kafka.Message{
+		Key:   []byte(fmt.Sprintf("%d", stats.BlockNumber)),
+		Value: data,
+		Headers: []kafka.Header{
+			{Key: "network", Value: []byte(stats.Network)},
+			{Key: "record_id", Value: []byte(stats.ID)},
+			{Key: "block_number", Value: []byte(fmt.Sprintf("%d", stats.BlockNumber))},
+			{Key: "timestamp", Value: []byte(fmt.Sprintf("%d", stats.Timestamp.Unix()))},
+			{Key: "message_type", Value: []byte("gas_stats")},
+		},
+		Time: stats.Timestamp,
+	}
+	kp.decorate(&message, stats.ID)
+	track(&message, "gas_stats", stats.Network, stats)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := writer.WriteMessages(ctx, message); err != nil {
+		return fmt.Errorf("failed to write gas stats message: %w", err)
+	}
+
+	logger.Debugf("Published gas stats of block %d to Kafka", stats.BlockNumber)
+	return nil
+}
+
+// PublishWithdrawal 发布信标链提款，按提款地址分区
+func (kp *KafkaPublisher) PublishWithdrawal(withdrawal *models.Withdrawal) error {
+	writer, exists := kp.writers["withdrawals"]
+	if !exists {
+		return fmt.Errorf("withdrawal writer not found")
+	}
+
+	data, err := json.Marshal(withdrawal)
+	if err != nil {
+		return fmt.Errorf("failed to marshal withdrawal: %w", err)
+	}
+
+	message := kafka.Message{
+		Key:   []byte(withdrawal.Address),
+		Value: data,
+		Headers: []kafka.Header{
+			{Key: "network", Value: []byte(withdrawal.Network)},
+			{Key: "record_id", Value: []byte(withdrawal.ID)},
+			{Key: "block_number", Value: []byte(fmt.Sprintf("%d", withdrawal.BlockNumber))},
+			{Key: "timestamp", Value: []byte(fmt.Sprintf("%d", withdrawal.Timestamp.Unix()))},
+			{Key: "message_type", Value: []byte("withdrawal")},
+		},
+		Time: withdrawal.Timestamp,
+	}
+	kp.decorate(&message, withdrawal.ID)
+	labelFinality(&message, withdrawal.Finality)
+	track(&message, "withdrawal", withdrawal.Network, withdrawal)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := writer.WriteMessages(ctx, message); err != nil {
+		return fmt.Errorf("failed to write withdrawal message: %w", err)
+	}
+
+	logger.Debugf("Published withdrawal %d of block %d to Kafka", withdrawal.Index, withdrawal.BlockNumber)
+	return nil
+}
+
+// PublishBridgeTransfer 发布跨链桥存入或到账，按消息ID分区，同一跨链消息的两端记录有序
+func (kp *KafkaPublisher) PublishBridgeTransfer(transfer *models.BridgeTransfer) error {
+	writer, exists := kp.writers["bridge_transfers"]
+	if !exists {
+		return fmt.Errorf("bridge transfer writer not found")
+	}
+
+	data, err := json.Marshal(transfer)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bridge transfer: %w", err)
+	}
+
+	message := kafka.Message{
+		Key:   []byte(transfer.MessageID),
+		Value: data,
+		Headers: []kafka.Header{
+			{Key: "network", Value: []byte(transfer.Network)},
+			{Key: "record_id", Value: []byte(transfer.ID)},
+			{Key: "block_number", Value: []byte(fmt.Sprintf("%d", transfer.BlockNumber))},
+			{Key: "timestamp", Value: []byte(fmt.Sprintf("%d", transfer.Timestamp.Unix()))},
+			{Key: "message_type", Value: []byte("bridge_transfer")},
+		},
+		Time: transfer.Timestamp,
+	}
+	kp.decorate(&message, transfer.ID)
+	track(&message, "bridge_transfer", transfer.Network, transfer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := writer.WriteMessages(ctx, message); err != nil {
+		return fmt.Errorf("failed to write bridge transfer message: %w", err)
+	}
+
+	logger.Debugf("Published bridge %s %s to Kafka", transfer.Direction, transfer.MessageID)
+	return nil
+}
+
+// PublishEnrichedBlock 发布处理完成的完整区块
+func (kp *KafkaPublisher) PublishEnrichedBlock(enriched *models.EnrichedBlock) error {
+	writer, exists := kp.writers["enriched"]
+	if !exists {
+		return fmt.Errorf("enriched block writer not found")
+	}
+
+	data, err := json.Marshal(enriched)
+	if err != nil {
+		return fmt.Errorf("failed to marshal enriched block: %w", err)
+	}
+
+	// 超出消息上限时去掉区块的交易列表，告警、事件等处理结果保持完整
+	block := enriched.Block
+	omitted := len(data) > kp.maxMessageBytes && len(block.Transactions) > 0
+	if omitted {
+		summary := *enriched
+		summary.Block = withoutTransactions(block)
+		if data, err = json.Marshal(&summary); err != nil {
+			return fmt.Errorf("failed to marshal enriched block: %w", err)
+		}
+	}
+	message := kafka.Message{
+		Key:   []byte(fmt.Sprintf("%d", block.Number)),
+		Value: data,
+		Headers: []kafka.Header{
+			{Key: "network", Value: []byte(block.Network)},
+			{Key: "record_id", Value: []byte(block.ID)},
+			{Key: "block_number", Value: []byte(fmt.Sprintf("%d", block.Number))},
+			{Key: "timestamp", Value: []byte(fmt.Sprintf("%d", block.Timestamp.Unix()))},
+			{Key: "message_type", Value: []byte("enriched_block")},
+			{Key: "alert_count", Value: []byte(fmt.Sprintf("%d", len(enriched.Alerts)))},
+		},
+		Time: enriched.ProcessedAt,
+	}
+	kp.decorate(&message, messageID("enriched_block", block.Network, block.Hash))
+	labelFinality(&message, block.Finality)
+	track(&message, "enriched_block", block.Network, enriched)
+	if omitted {
+		labelTransactionsOmitted(&message)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := writer.WriteMessages(ctx, message); err != nil {
+		return fmt.Errorf("failed to write enriched block message: %w", err)
+	}
+
+	logger.Debugf("Published enriched block %d to Kafka", block.Number)
+	return nil
+}
+
+// PublishDeadLetter 发布死信
+func (kp *KafkaPublisher) PublishDeadLetter(entry *models.DeadLetter) error {
+	writer, exists := kp.writers["dead_letter"]
+	if !exists {
+		return fmt.Errorf("dead letter writer not found")
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter: %w", err)
+	}
+
+	message := kafka.Message{
+		Key:   []byte(entry.Sink),
+		Value: data,
+		Headers: []kafka.Header{
+			{Key: "network", Value: []byte(entry.Network)},
+			{Key: "sink", Value: []byte(entry.Sink)},
+			{Key: "kind", Value: []byte(entry.Kind)},
+			{Key: "message_type", Value: []byte("dead_letter")},
+		},
+		Time: entry.FailedAt,
+	}
+	kp.decorate(&message, "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := writer.WriteMessages(ctx, message); err != nil {
+		return fmt.Errorf("failed to write dead letter message: %w", err)
+	}
+
+	return nil
+}
+
+// NewDeadLetterReader 创建死信主题的消费者，用于重放
+func (kp *KafkaPublisher) NewDeadLetterReader(groupID string) *kafka.Reader {
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:  kp.config.Brokers,
+		Topic:    kp.config.Topics.DeadLetter,
+		GroupID:  groupID,
+		MinBytes: 1,
+		MaxBytes: 10e6,
+	})
+}
+
+// PublishBatch 批量发布消息，总大小超出消息上限时拆分为多次写入
 func (kp *KafkaPublisher) PublishBatch(topicName string, messages []kafka.Message) error {
 	writer, exists := kp.writers[topicName]
 	if !exists {
@@ -197,11 +741,13 @@ func (kp *KafkaPublisher) PublishBatch(topicName string, messages []kafka.Messag
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	if err := writer.WriteMessages(ctx, messages...); err != nil {
-		return fmt.Errorf("failed to write batch messages: %w", err)
+	for _, batch := range kp.splitBatch(topicName, messages) {
+		if err := writer.WriteMessages(ctx, batch...); err != nil {
+			return fmt.Errorf("failed to write batch messages: %w", err)
+		}
 	}
 
-	logrus.Debugf("Published %d messages to topic %s", len(messages), topicName)
+	logger.Debugf("Published %d messages to topic %s", len(messages), topicName)
 	return nil
 }
 
@@ -210,9 +756,9 @@ func (kp *KafkaPublisher) PublishTransactionBatch(transactions []*models.Transac
 	messages := make([]kafka.Message, 0, len(transactions))
 
 	for _, tx := range transactions {
-		data, err := json.Marshal(tx)
+		data, err := kp.encode("transactions", tx)
 		if err != nil {
-			logrus.Errorf("Failed to marshal transaction %s: %v", tx.Hash, err)
+			logger.Errorf("Failed to marshal transaction %s: %v", tx.Hash, err)
 			continue
 		}
 
@@ -221,12 +767,17 @@ func (kp *KafkaPublisher) PublishTransactionBatch(transactions []*models.Transac
 			Value: data,
 			Headers: []kafka.Header{
 				{Key: "network", Value: []byte(tx.Network)},
+				{Key: "record_id", Value: []byte(tx.ID)},
 				{Key: "block_number", Value: []byte(fmt.Sprintf("%d", tx.BlockNumber))},
 				{Key: "timestamp", Value: []byte(fmt.Sprintf("%d", tx.Timestamp.Unix()))},
 				{Key: "message_type", Value: []byte("transaction")},
 			},
 			Time: tx.Timestamp,
 		}
+		kp.decorate(&message, recordMessageID(tx.ID, "transaction", tx.Network, tx.Hash))
+		labelFinality(&message, tx.Finality)
+		kp.contentType(&message, "transactions")
+		track(&message, "transaction", tx.Network, tx)
 
 		messages = append(messages, message)
 	}
@@ -245,7 +796,7 @@ func (kp *KafkaPublisher) GetStats() map[string]interface{} {
 			"messages":    writerStats.Messages,
 			"bytes":       writerStats.Bytes,
 			"errors":      writerStats.Errors,
-			"batch_time":  writerStats.BatchTime.String(),
+			"batch_time":  writerStats.BatchTime.Avg.String(),
 			"batch_size":  writerStats.BatchSize,
 		}
 	}
@@ -255,12 +806,9 @@ func (kp *KafkaPublisher) GetStats() map[string]interface{} {
 
 // Flush 刷新所有写入器的缓冲区
 func (kp *KafkaPublisher) Flush() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
 	for name, writer := range kp.writers {
 		if err := writer.Close(); err != nil {
-			logrus.Errorf("Error flushing writer %s: %v", name, err)
+			logger.Errorf("Error flushing writer %s: %v", name, err)
 			return err
 		}
 	}
@@ -274,12 +822,12 @@ func (kp *KafkaPublisher) Close() error {
 
 	for name, writer := range kp.writers {
 		if err := writer.Close(); err != nil {
-			logrus.Errorf("Error closing writer %s: %v", name, err)
+			logger.Errorf("Error closing writer %s: %v", name, err)
 			lastErr = err
 		}
 	}
 
-	logrus.Info("Kafka publisher closed")
+	logger.Info("Kafka publisher closed")
 	return lastErr
 }
 
@@ -287,10 +835,31 @@ func (kp *KafkaPublisher) Close() error {
 func (kp *KafkaPublisher) CreateTopicIfNotExists(topicName string, numPartitions int, replicationFactor int) error {
 	// 这里可以添加创建主题的逻辑
 	// 在生产环境中，通常由管理员预先创建主题
-	logrus.Infof("Topic creation for %s would be handled by Kafka admin", topicName)
+	logger.Infof("Topic creation for %s would be handled by Kafka admin", topicName)
 	return nil
 }
 
+// Ping 检查能否连接到任一broker，不写入消息；kinesis/pubsub后端不检查
+func (kp *KafkaPublisher) Ping(ctx context.Context) error {
+	if kp.config.Backend != "" && kp.config.Backend != BackendKafka {
+		return nil
+	}
+	if len(kp.config.Brokers) == 0 {
+		return fmt.Errorf("no kafka brokers configured")
+	}
+
+	var lastErr error
+	for _, broker := range kp.config.Brokers {
+		conn, err := kafka.DialContext(ctx, "tcp", broker)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+		lastErr = err
+	}
+	return fmt.Errorf("no kafka broker reachable: %w", lastErr)
+}
+
 // HealthCheck 健康检查
 func (kp *KafkaPublisher) HealthCheck() error {
 	// 检查所有写入器的连接状态
@@ -0,0 +1,283 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/protocol"
+)
+
+const (
+	// kafkaTxnTimeout 事务超时，超过该时间未结束的事务由broker中止
+	kafkaTxnTimeout = time.Minute
+
+	// recordBatchTransactional v2记录批次属性中的事务标志位
+	recordBatchTransactional = 0x10
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// txnProducer 以Kafka事务写出区块的交易消息。kafka-go的Writer不支持幂等及事务生产者，
+// 这里直接使用协议接口：消息带生产者ID、epoch及分区序列号，broker据此丢弃重试造成的重复；
+// 一个区块的消息在同一事务内提交，read_committed的消费端看不到写了一半的区块。
+// 同一transactional_id的新实例初始化时broker中止旧实例未完成的事务
+type txnProducer struct {
+	client          *kafka.Client
+	topic           string
+	transactionalID string
+	compression     kafka.Compression
+	balancer        kafka.Balancer
+
+	mu         sync.Mutex
+	session    *kafka.ProducerSession // 为nil时在下次提交前重新初始化
+	partitions []int
+	sequences  map[int]int32 // 各分区下一条消息的序列号
+}
+
+func newTxnProducer(brokers []string, topic, transactionalID string, compression kafka.Compression) *txnProducer {
+	return &txnProducer{
+		client: &kafka.Client{
+			Addr:    kafka.TCP(brokers...),
+			Timeout: 30 * time.Second,
+		},
+		topic:           topic,
+		transactionalID: transactionalID,
+		compression:     compression,
+		balancer:        &kafka.Hash{},
+	}
+}
+
+// commit 在一个事务内写出消息，失败时中止事务，下次提交前重新初始化生产者
+func (p *txnProducer) commit(ctx context.Context, messages []kafka.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.session == nil {
+		if err := p.init(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := p.send(ctx, p.partition(messages)); err != nil {
+		p.abort()
+		return err
+	}
+	return nil
+}
+
+// init 获取生产者ID及epoch并加载主题分区，序列号从0开始
+func (p *txnProducer) init(ctx context.Context) error {
+	resp, err := p.client.InitProducerID(ctx, &kafka.InitProducerIDRequest{
+		TransactionalID:      p.transactionalID,
+		TransactionTimeoutMs: int(kafkaTxnTimeout.Milliseconds()),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to init transactional producer: %w", err)
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("failed to init transactional producer: %w", resp.Error)
+	}
+
+	meta, err := p.client.Metadata(ctx, &kafka.MetadataRequest{Topics: []string{p.topic}})
+	if err != nil {
+		return fmt.Errorf("failed to load partitions of %s: %w", p.topic, err)
+	}
+	var partitions []int
+	for _, topic := range meta.Topics {
+		if topic.Name != p.topic {
+			continue
+		}
+		if topic.Error != nil {
+			return fmt.Errorf("failed to load partitions of %s: %w", p.topic, topic.Error)
+		}
+		for _, partition := range topic.Partitions {
+			partitions = append(partitions, partition.ID)
+		}
+	}
+	if len(partitions) == 0 {
+		return fmt.Errorf("topic %s has no partitions", p.topic)
+	}
+	sort.Ints(partitions)
+
+	p.session = resp.Producer
+	p.partitions = partitions
+	p.sequences = make(map[int]int32, len(partitions))
+	logger.Infof("Initialized transactional producer %s (id %d, epoch %d)", p.transactionalID, p.session.ProducerID, p.session.ProducerEpoch)
+	return nil
+}
+
+// partition 按消息键分区，与Writer的Hash分区一致
+func (p *txnProducer) partition(messages []kafka.Message) map[int][]kafka.Message {
+	batches := make(map[int][]kafka.Message)
+	for _, message := range messages {
+		partition := p.balancer.Balance(message, p.partitions...)
+		batches[partition] = append(batches[partition], message)
+	}
+	return batches
+}
+
+// send 将分区加入事务、逐分区写出记录批次后提交事务
+func (p *txnProducer) send(ctx context.Context, batches map[int][]kafka.Message) error {
+	partitions := make([]kafka.AddPartitionToTxn, 0, len(batches))
+	for partition := range batches {
+		partitions = append(partitions, kafka.AddPartitionToTxn{Partition: partition})
+	}
+	added, err := p.client.AddPartitionsToTxn(ctx, &kafka.AddPartitionsToTxnRequest{
+		TransactionalID: p.transactionalID,
+		ProducerID:      p.session.ProducerID,
+		ProducerEpoch:   p.session.ProducerEpoch,
+		Topics:          map[string][]kafka.AddPartitionToTxn{p.topic: partitions},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add partitions to transaction: %w", err)
+	}
+	for _, partition := range added.Topics[p.topic] {
+		if partition.Error != nil {
+			return fmt.Errorf("failed to add partition %d to transaction: %w", partition.Partition, partition.Error)
+		}
+	}
+
+	for partition, messages := range batches {
+		batch, err := encodeRecordBatch(messages, p.session, p.sequences[partition], p.compression)
+		if err != nil {
+			return fmt.Errorf("failed to encode record batch: %w", err)
+		}
+		resp, err := p.client.RawProduce(ctx, &kafka.RawProduceRequest{
+			Topic:           p.topic,
+			Partition:       partition,
+			RequiredAcks:    kafka.RequireAll,
+			TransactionalID: p.transactionalID,
+			RawRecords:      protocol.RawRecordSet{Reader: bytes.NewReader(batch)},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to produce to partition %d: %w", partition, err)
+		}
+		if resp.Error != nil {
+			return fmt.Errorf("failed to produce to partition %d: %w", partition, resp.Error)
+		}
+		p.sequences[partition] += int32(len(messages))
+	}
+
+	ended, err := p.client.EndTxn(ctx, &kafka.EndTxnRequest{
+		TransactionalID: p.transactionalID,
+		ProducerID:      p.session.ProducerID,
+		ProducerEpoch:   p.session.ProducerEpoch,
+		Committed:       true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	if ended.Error != nil {
+		return fmt.Errorf("failed to commit transaction: %w", ended.Error)
+	}
+	return nil
+}
+
+// abort 尽力中止当前事务并丢弃会话，重新初始化时broker也会中止未结束的事务
+func (p *txnProducer) abort() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resp, err := p.client.EndTxn(ctx, &kafka.EndTxnRequest{
+		TransactionalID: p.transactionalID,
+		ProducerID:      p.session.ProducerID,
+		ProducerEpoch:   p.session.ProducerEpoch,
+		Committed:       false,
+	})
+	if err == nil {
+		err = resp.Error
+	}
+	if err != nil {
+		logger.Warnf("Failed to abort transaction of %s: %v", p.transactionalID, err)
+	}
+	p.session = nil
+}
+
+// encodeRecordBatch 编码RawProduce请求的v2事务记录批次。kafka-go的编码器固定写入-1作为生产者ID及序列号，无法用于事务
+func encodeRecordBatch(messages []kafka.Message, session *kafka.ProducerSession, baseSequence int32, compression kafka.Compression) ([]byte, error) {
+	now := time.Now()
+	timestamps := make([]int64, len(messages))
+	for i, message := range messages {
+		ts := message.Time
+		if ts.IsZero() {
+			ts = now
+		}
+		timestamps[i] = ts.UnixMilli()
+	}
+	firstTimestamp, maxTimestamp := timestamps[0], timestamps[0]
+	for _, ts := range timestamps {
+		if ts > maxTimestamp {
+			maxTimestamp = ts
+		}
+	}
+
+	var records bytes.Buffer
+	var w io.Writer = &records
+	var compressor io.WriteCloser
+	if compression != 0 {
+		compressor = compression.Codec().NewWriter(&records)
+		w = compressor
+	}
+	for i, message := range messages {
+		record := []byte{0} // 记录属性，未使用
+		record = binary.AppendVarint(record, timestamps[i]-firstTimestamp)
+		record = binary.AppendVarint(record, int64(i))
+		record = appendVarBytes(record, message.Key)
+		record = appendVarBytes(record, message.Value)
+		record = binary.AppendVarint(record, int64(len(message.Headers)))
+		for _, header := range message.Headers {
+			record = appendVarBytes(record, []byte(header.Key))
+			record = appendVarBytes(record, header.Value)
+		}
+		if _, err := w.Write(binary.AppendVarint(nil, int64(len(record)))); err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	if compressor != nil {
+		if err := compressor.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	// CRC覆盖属性字段至批次末尾
+	var body []byte
+	body = binary.BigEndian.AppendUint16(body, uint16(int16(compression)&0x7|recordBatchTransactional))
+	body = binary.BigEndian.AppendUint32(body, uint32(len(messages)-1)) // lastOffsetDelta
+	body = binary.BigEndian.AppendUint64(body, uint64(firstTimestamp))
+	body = binary.BigEndian.AppendUint64(body, uint64(maxTimestamp))
+	body = binary.BigEndian.AppendUint64(body, uint64(session.ProducerID))
+	body = binary.BigEndian.AppendUint16(body, uint16(session.ProducerEpoch))
+	body = binary.BigEndian.AppendUint32(body, uint32(baseSequence))
+	body = binary.BigEndian.AppendUint32(body, uint32(len(messages)))
+	body = append(body, records.Bytes()...)
+
+	// 请求中的记录集以int32长度开头
+	var batch []byte
+	batch = binary.BigEndian.AppendUint32(batch, uint32(8+4+4+1+4+len(body))) // 记录集长度
+	batch = binary.BigEndian.AppendUint64(batch, 0)                           // baseOffset，由broker分配
+	batch = binary.BigEndian.AppendUint32(batch, uint32(4+1+4+len(body)))     // batchLength
+	batch = binary.BigEndian.AppendUint32(batch, 0xffffffff)                  // partitionLeaderEpoch
+	batch = append(batch, 2)                                                  // magic
+	batch = binary.BigEndian.AppendUint32(batch, crc32.Checksum(body, castagnoli))
+	return append(batch, body...), nil
+}
+
+// appendVarBytes 写入变长长度前缀的字节，nil写为-1
+func appendVarBytes(b, value []byte) []byte {
+	if value == nil {
+		return binary.AppendVarint(b, -1)
+	}
+	b = binary.AppendVarint(b, int64(len(value)))
+	return append(b, value...)
+}
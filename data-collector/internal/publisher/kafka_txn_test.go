@@ -0,0 +1,117 @@
+package publisher
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/protocol"
+)
+
+func testRecordBatchMessages() []kafka.Message {
+	first := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []kafka.Message{
+		{
+			Key:     []byte("block-1"),
+			Value:   []byte(`{"n":1}`),
+			Time:    first,
+			Headers: []kafka.Header{{Key: "network", Value: []byte("ethereum")}},
+		},
+		{
+			Value: []byte("b"),
+			Time:  first.Add(250 * time.Millisecond),
+		},
+	}
+}
+
+// 期望字节按Kafka协议的v2记录批次格式独立编码，CRC-32C覆盖属性字段至批次末尾
+func TestEncodeRecordBatch(t *testing.T) {
+	session := &kafka.ProducerSession{ProducerID: 4096, ProducerEpoch: 3}
+
+	batch, err := encodeRecordBatch(testRecordBatchMessages(), session, 7, 0)
+	if err != nil {
+		t.Fatalf("encodeRecordBatch: %v", err)
+	}
+
+	want := "0000006c" + // 记录集长度
+		"0000000000000000" + // baseOffset
+		"00000060" + // batchLength
+		"ffffffff" + // partitionLeaderEpoch
+		"02" + // magic
+		"7f2da528" + // crc
+		"0010" + // 属性：事务，无压缩
+		"00000001" + // lastOffsetDelta
+		"0000018cc251f400" + // firstTimestamp
+		"0000018cc251f4fa" + // maxTimestamp
+		"0000000000001000" + // producerId
+		"0003" + // producerEpoch
+		"00000007" + // baseSequence
+		"00000002" + // 记录数
+		"4a0000000e626c6f636b2d310e7b226e223a317d020e6e6574776f726b10657468657265756d" +
+		"1000f4030201026200"
+	if got := hex.EncodeToString(batch); got != want {
+		t.Fatalf("record batch mismatch\n got: %s\nwant: %s", got, want)
+	}
+}
+
+// 压缩后的字节依赖压缩实现，这里用kafka-go的解码器校验CRC及批次字段
+func TestEncodeRecordBatchCompressed(t *testing.T) {
+	session := &kafka.ProducerSession{ProducerID: 4096, ProducerEpoch: 3}
+	messages := testRecordBatchMessages()
+
+	batch, err := encodeRecordBatch(messages, session, 7, kafka.Gzip)
+	if err != nil {
+		t.Fatalf("encodeRecordBatch: %v", err)
+	}
+
+	var rs protocol.RecordSet
+	if _, err := rs.ReadFrom(bytes.NewBuffer(batch)); err != nil {
+		t.Fatalf("decode record batch: %v", err)
+	}
+	stream, ok := rs.Records.(*protocol.RecordStream)
+	if !ok || len(stream.Records) != 1 {
+		t.Fatalf("expected a single record batch, got %T", rs.Records)
+	}
+	decoded, ok := stream.Records[0].(*protocol.RecordBatch)
+	if !ok {
+		t.Fatalf("expected *protocol.RecordBatch, got %T", stream.Records[0])
+	}
+
+	if rs.Version != 2 {
+		t.Errorf("magic = %d, want 2", rs.Version)
+	}
+	if !decoded.Attributes.Transactional() || decoded.Attributes.Control() {
+		t.Errorf("attributes = %#x, want transactional data batch", int16(decoded.Attributes))
+	}
+	if decoded.Attributes.Compression() != kafka.Gzip {
+		t.Errorf("compression = %d, want gzip", decoded.Attributes.Compression())
+	}
+	if decoded.ProducerID != 4096 || decoded.ProducerEpoch != 3 || decoded.BaseSequence != 7 {
+		t.Errorf("producer = %d/%d/%d, want 4096/3/7", decoded.ProducerID, decoded.ProducerEpoch, decoded.BaseSequence)
+	}
+
+	for i, message := range messages {
+		record, err := decoded.ReadRecord()
+		if err != nil {
+			t.Fatalf("record %d: %v", i, err)
+		}
+		if record.Offset != int64(i) || !record.Time.Equal(message.Time) {
+			t.Errorf("record %d: offset %d time %v, want %d %v", i, record.Offset, record.Time, i, message.Time)
+		}
+		value, err := protocol.ReadAll(record.Value)
+		if err != nil {
+			t.Fatalf("record %d value: %v", i, err)
+		}
+		if !bytes.Equal(value, message.Value) {
+			t.Errorf("record %d value = %q, want %q", i, value, message.Value)
+		}
+		if (record.Key == nil) != (message.Key == nil) {
+			t.Errorf("record %d key presence mismatch", i)
+		}
+		if len(record.Headers) != len(message.Headers) {
+			t.Errorf("record %d has %d headers, want %d", i, len(record.Headers), len(message.Headers))
+		}
+	}
+}
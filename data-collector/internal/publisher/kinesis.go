@@ -0,0 +1,286 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"web3-data-collector/internal/config"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// PutRecords单次请求的上限
+const (
+	kinesisMaxRecords      = 500
+	kinesisMaxRequestBytes = 5 << 20
+	kinesisMaxKeyLength    = 256
+)
+
+// awsCredentials AWS访问凭证
+type awsCredentials struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+}
+
+// resolveAWSCredentials 使用配置的凭证，未配置时读取AWS_ACCESS_KEY_ID等环境变量，仍缺少时返回false
+func resolveAWSCredentials(accessKeyID, secretAccessKey, sessionToken string) (awsCredentials, bool) {
+	credentials := awsCredentials{
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		sessionToken:    sessionToken,
+	}
+	if credentials.accessKeyID == "" {
+		credentials = awsCredentials{
+			accessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			secretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			sessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+	}
+	return credentials, credentials.accessKeyID != "" && credentials.secretAccessKey != ""
+}
+
+// kinesisRecord Kinesis记录没有属性，消息头与数据按Pub/Sub消息的结构封装，消费端可使用相同的解码方式
+type kinesisRecord struct {
+	Data       []byte            `json:"data"` // JSON中为base64
+	Attributes map[string]string `json:"attributes"`
+}
+
+// kinesisEntry PutRecords请求中的记录
+type kinesisEntry struct {
+	Data         []byte `json:"Data"`
+	PartitionKey string `json:"PartitionKey"`
+}
+
+// kinesisSender 通过PutRecords API写入Kinesis Data Streams，请求使用SigV4签名
+type kinesisSender struct {
+	http        *http.Client
+	endpoint    string
+	host        string
+	region      string
+	stream      string
+	credentials awsCredentials
+	maxAttempts int
+}
+
+// newKinesisSenders 校验凭证并返回按stream名创建kinesisSender的构造函数
+func newKinesisSenders(cfg config.KinesisConfig, maxAttempts int) (newSenderFunc, error) {
+	credentials, ok := resolveAWSCredentials(cfg.AccessKeyID, cfg.SecretAccessKey, cfg.SessionToken)
+	if !ok {
+		return nil, fmt.Errorf("kinesis credentials are not configured")
+	}
+
+	endpoint := strings.TrimRight(cfg.Endpoint, "/")
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://kinesis.%s.amazonaws.com", cfg.Region)
+	}
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kinesis endpoint: %w", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	return func(stream string) recordSender {
+		return &kinesisSender{
+			http:        client,
+			endpoint:    endpoint + "/",
+			host:        parsed.Host,
+			region:      cfg.Region,
+			stream:      stream,
+			credentials: credentials,
+			maxAttempts: maxAttempts,
+		}
+	}, nil
+}
+
+// send 按请求上限拆分写入，部分记录失败时只重试失败的记录
+func (s *kinesisSender) send(ctx context.Context, messages []kafka.Message) error {
+	var batch []kinesisEntry
+	var size int
+	for _, message := range messages {
+		entry, err := newKinesisEntry(message)
+		if err != nil {
+			return err
+		}
+		entrySize := len(entry.Data) + len(entry.PartitionKey)
+		if len(batch) == kinesisMaxRecords || (len(batch) > 0 && size+entrySize > kinesisMaxRequestBytes) {
+			if err := s.putRecords(ctx, batch); err != nil {
+				return err
+			}
+			batch, size = nil, 0
+		}
+		batch = append(batch, entry)
+		size += entrySize
+	}
+	return s.putRecords(ctx, batch)
+}
+
+// newKinesisEntry 转换为PutRecords的记录，分区键为消息键，消息键为空时使用message_id头
+func newKinesisEntry(message kafka.Message) (kinesisEntry, error) {
+	attributes := messageAttributes(message)
+	data, err := json.Marshal(kinesisRecord{Data: message.Value, Attributes: attributes})
+	if err != nil {
+		return kinesisEntry{}, err
+	}
+
+	partitionKey := string(message.Key)
+	if partitionKey == "" {
+		partitionKey = attributes["message_id"]
+	}
+	if partitionKey == "" {
+		partitionKey = "default"
+	}
+	if len(partitionKey) > kinesisMaxKeyLength {
+		partitionKey = partitionKey[:kinesisMaxKeyLength]
+	}
+	return kinesisEntry{Data: data, PartitionKey: partitionKey}, nil
+}
+
+func (s *kinesisSender) putRecords(ctx context.Context, records []kinesisEntry) error {
+	for attempt := 1; len(records) > 0; attempt++ {
+		failed, err := s.putRecordsOnce(ctx, records)
+		if err == nil && len(failed) == 0 {
+			return nil
+		}
+		if attempt >= s.maxAttempts {
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("%d records were rejected by stream %s", len(failed), s.stream)
+		}
+		if err == nil {
+			records = failed
+		}
+		if err := sleepContext(ctx, retryDelay(attempt)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// putRecordsOnce 发送一次PutRecords请求，返回被拒绝（限流等）的记录
+func (s *kinesisSender) putRecordsOnce(ctx context.Context, records []kinesisEntry) ([]kinesisEntry, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"StreamName": s.stream,
+		"Records":    records,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "Kinesis_20131202.PutRecords")
+	s.sign(req, body, time.Now().UTC())
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("kinesis returned %d for %s: %s", resp.StatusCode, s.stream, bytes.TrimSpace(message))
+	}
+
+	var result struct {
+		FailedRecordCount int `json:"FailedRecordCount"`
+		Records           []struct {
+			ErrorCode string `json:"ErrorCode"`
+		} `json:"Records"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid kinesis response: %w", err)
+	}
+	if result.FailedRecordCount == 0 {
+		return nil, nil
+	}
+
+	var failed []kinesisEntry
+	for i, record := range result.Records {
+		if record.ErrorCode != "" && i < len(records) {
+			failed = append(failed, records[i])
+		}
+	}
+	return failed, nil
+}
+
+// sign 按AWS Signature Version 4签名请求
+func (s *kinesisSender) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if s.credentials.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.credentials.sessionToken)
+	}
+
+	// 签名的头须按名称排序
+	signedHeaders := []string{"content-type", "host", "x-amz-date"}
+	if s.credentials.sessionToken != "" {
+		signedHeaders = append(signedHeaders, "x-amz-security-token")
+	}
+	signedHeaders = append(signedHeaders, "x-amz-target")
+
+	var canonicalHeaders strings.Builder
+	for _, name := range signedHeaders {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = s.host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		"/",
+		"",
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/kinesis/aws4_request", date, s.region)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.credentials.secretAccessKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "kinesis")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.credentials.accessKeyID, scope, strings.Join(signedHeaders, ";"), signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
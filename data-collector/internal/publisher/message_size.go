@@ -0,0 +1,76 @@
+package publisher
+
+import (
+	"fmt"
+
+	"web3-data-collector/internal/models"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// defaultMaxMessageBytes 未配置max_message_bytes时的上限，与broker默认的message.max.bytes一致
+const defaultMaxMessageBytes = 1048576
+
+// compressionCodec 配置的压缩算法对应的kafka-go编码，none或为空时不压缩
+func compressionCodec(name string) (kafka.Compression, error) {
+	switch name {
+	case "", "none":
+		return 0, nil
+	case "gzip":
+		return kafka.Gzip, nil
+	case "snappy":
+		return kafka.Snappy, nil
+	case "lz4":
+		return kafka.Lz4, nil
+	case "zstd":
+		return kafka.Zstd, nil
+	default:
+		return 0, fmt.Errorf("unsupported compression: %s", name)
+	}
+}
+
+// messageSize 消息压缩前的字节数，包括键、值及头
+func messageSize(message kafka.Message) int {
+	size := len(message.Key) + len(message.Value)
+	for _, header := range message.Headers {
+		size += len(header.Key) + len(header.Value)
+	}
+	return size
+}
+
+// splitBatch 按字节上限将批量拆分为多次写入，单条超出上限的消息无法写出，记录日志后丢弃
+func (kp *KafkaPublisher) splitBatch(topicName string, messages []kafka.Message) [][]kafka.Message {
+	var batches [][]kafka.Message
+	var current []kafka.Message
+	currentSize := 0
+
+	for _, message := range messages {
+		size := messageSize(message)
+		if size > kp.maxMessageBytes {
+			logger.Errorf("Dropped message %s of %d bytes for topic %s: exceeds max_message_bytes %d", message.Key, size, topicName, kp.maxMessageBytes)
+			continue
+		}
+		if currentSize+size > kp.maxMessageBytes && len(current) > 0 {
+			batches = append(batches, current)
+			current, currentSize = nil, 0
+		}
+		current = append(current, message)
+		currentSize += size
+	}
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+	return batches
+}
+
+// withoutTransactions 不含交易列表的区块副本，交易数仍见tx_count
+func withoutTransactions(block *models.Block) *models.Block {
+	summary := *block
+	summary.Transactions = nil
+	return &summary
+}
+
+// labelTransactionsOmitted 标记区块消息未包含交易列表，消费端需从transactions主题获取交易
+func labelTransactionsOmitted(message *kafka.Message) {
+	message.Headers = append(message.Headers, kafka.Header{Key: "transactions_omitted", Value: []byte("true")})
+}
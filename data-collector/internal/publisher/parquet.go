@@ -0,0 +1,555 @@
+package publisher
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// parquetMagic Parquet文件的首尾标识
+const parquetMagic = "PAR1"
+
+// 归档使用的Parquet列类型，均为REQUIRED列
+const (
+	parquetInt64     = iota // INT64
+	parquetString           // BYTE_ARRAY，UTF8
+	parquetTimestamp        // INT64，TIMESTAMP_MILLIS
+)
+
+// Parquet格式定义中的枚举值
+const (
+	parquetTypeInt64        = 2
+	parquetTypeByteArray    = 6
+	parquetConvertedUTF8    = 0
+	parquetConvertedMillis  = 9
+	parquetRequired         = 0
+	parquetEncodingPlain    = 0
+	parquetEncodingRLE      = 3
+	parquetCodecGzip        = 2
+	parquetPageTypeData     = 0
+	parquetFormatVersion    = 1
+	parquetCreatedBy        = "web3-data-collector"
+	parquetMaxInlineListLen = 15
+)
+
+// thrift compact协议的字段类型
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// parquetColumn 列名及类型
+type parquetColumn struct {
+	name string
+	kind int
+}
+
+// encodeParquet 将行写成只有一个行组的Parquet文件，每列一个PLAIN编码、gzip压缩的数据页；
+// rows中每行的值与columns一一对应，INT64列为int64，字符串列为string
+func encodeParquet(columns []parquetColumn, rows [][]interface{}) ([]byte, error) {
+	var file bytes.Buffer
+	file.WriteString(parquetMagic)
+
+	type chunk struct {
+		offset       int64
+		uncompressed int64
+		compressed   int64
+	}
+	chunks := make([]chunk, len(columns))
+	var totalSize int64
+
+	for i, column := range columns {
+		var page bytes.Buffer
+		for _, row := range rows {
+			if err := writePlainValue(&page, column, row[i]); err != nil {
+				return nil, err
+			}
+		}
+
+		var compressed bytes.Buffer
+		zw := gzip.NewWriter(&compressed)
+		if _, err := zw.Write(page.Bytes()); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+
+		header := &thriftWriter{}
+		header.i32(1, parquetPageTypeData)
+		header.i32(2, int32(page.Len()))
+		header.i32(3, int32(compressed.Len()))
+		header.structBegin(5)
+		header.i32(1, int32(len(rows)))
+		header.i32(2, parquetEncodingPlain)
+		header.i32(3, parquetEncodingRLE)
+		header.i32(4, parquetEncodingRLE)
+		header.structEnd()
+		header.stop()
+
+		chunks[i] = chunk{
+			offset:       int64(file.Len()),
+			uncompressed: int64(header.buf.Len() + page.Len()),
+			compressed:   int64(header.buf.Len() + compressed.Len()),
+		}
+		totalSize += chunks[i].uncompressed
+		file.Write(header.buf.Bytes())
+		file.Write(compressed.Bytes())
+	}
+
+	meta := &thriftWriter{}
+	meta.i32(1, parquetFormatVersion)
+
+	// 根节点之后为各列
+	meta.listBegin(2, thriftStruct, len(columns)+1)
+	meta.elementBegin()
+	meta.binary(4, "schema")
+	meta.i32(5, int32(len(columns)))
+	meta.elementEnd()
+	for _, column := range columns {
+		meta.elementBegin()
+		physical, converted := parquetTypes(column.kind)
+		meta.i32(1, physical)
+		meta.i32(3, parquetRequired)
+		meta.binary(4, column.name)
+		if converted >= 0 {
+			meta.i32(6, converted)
+		}
+		meta.elementEnd()
+	}
+
+	meta.i64(3, int64(len(rows)))
+
+	meta.listBegin(4, thriftStruct, 1)
+	meta.elementBegin()
+	meta.listBegin(1, thriftStruct, len(columns))
+	for i, column := range columns {
+		physical, _ := parquetTypes(column.kind)
+		meta.elementBegin()
+		meta.i64(2, chunks[i].offset)
+		meta.structBegin(3)
+		meta.i32(1, physical)
+		meta.listBegin(2, thriftI32, 1)
+		meta.listI32(parquetEncodingPlain)
+		meta.listBegin(3, thriftBinary, 1)
+		meta.listBinary(column.name)
+		meta.i32(4, parquetCodecGzip)
+		meta.i64(5, int64(len(rows)))
+		meta.i64(6, chunks[i].uncompressed)
+		meta.i64(7, chunks[i].compressed)
+		meta.i64(9, chunks[i].offset)
+		meta.structEnd()
+		meta.elementEnd()
+	}
+	meta.i64(2, totalSize)
+	meta.i64(3, int64(len(rows)))
+	meta.elementEnd()
+
+	meta.binary(6, parquetCreatedBy)
+	meta.stop()
+
+	file.Write(meta.buf.Bytes())
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(meta.buf.Len()))
+	file.Write(length[:])
+	file.WriteString(parquetMagic)
+	return file.Bytes(), nil
+}
+
+// parquetTypes 列的物理类型及逻辑类型，无逻辑类型时为-1
+func parquetTypes(kind int) (int32, int32) {
+	switch kind {
+	case parquetString:
+		return parquetTypeByteArray, parquetConvertedUTF8
+	case parquetTimestamp:
+		return parquetTypeInt64, parquetConvertedMillis
+	default:
+		return parquetTypeInt64, -1
+	}
+}
+
+// writePlainValue 按PLAIN编码写入一个值：INT64为8字节小端，BYTE_ARRAY为4字节小端长度加内容
+func writePlainValue(buf *bytes.Buffer, column parquetColumn, value interface{}) error {
+	switch column.kind {
+	case parquetString:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("parquet column %s: expected string, got %T", column.name, value)
+		}
+		var length [4]byte
+		binary.LittleEndian.PutUint32(length[:], uint32(len(s)))
+		buf.Write(length[:])
+		buf.WriteString(s)
+	default:
+		n, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("parquet column %s: expected int64, got %T", column.name, value)
+		}
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], uint64(n))
+		buf.Write(b[:])
+	}
+	return nil
+}
+
+// thriftWriter thrift compact协议编码，只实现Parquet元数据用到的类型
+type thriftWriter struct {
+	buf   bytes.Buffer
+	last  int16   // 当前结构体上一个字段的ID
+	stack []int16 // 外层结构体的last
+}
+
+func (w *thriftWriter) fieldHeader(id int16, fieldType byte) {
+	if delta := id - w.last; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		w.buf.WriteByte(fieldType)
+		w.varint(int64(id))
+	}
+	w.last = id
+}
+
+// varint zigzag编码的变长整数
+func (w *thriftWriter) varint(n int64) {
+	w.uvarint(uint64((n << 1) ^ (n >> 63)))
+}
+
+func (w *thriftWriter) uvarint(n uint64) {
+	var b [binary.MaxVarintLen64]byte
+	w.buf.Write(b[:binary.PutUvarint(b[:], n)])
+}
+
+func (w *thriftWriter) i32(id int16, n int32) {
+	w.fieldHeader(id, thriftI32)
+	w.varint(int64(n))
+}
+
+func (w *thriftWriter) i64(id int16, n int64) {
+	w.fieldHeader(id, thriftI64)
+	w.varint(n)
+}
+
+func (w *thriftWriter) binary(id int16, s string) {
+	w.fieldHeader(id, thriftBinary)
+	w.listBinary(s)
+}
+
+// structBegin 开始结构体类型的字段，字段ID在结构体内重新计算
+func (w *thriftWriter) structBegin(id int16) {
+	w.fieldHeader(id, thriftStruct)
+	w.elementBegin()
+}
+
+func (w *thriftWriter) structEnd() {
+	w.elementEnd()
+}
+
+// elementBegin 开始列表中的结构体元素
+func (w *thriftWriter) elementBegin() {
+	w.stack = append(w.stack, w.last)
+	w.last = 0
+}
+
+func (w *thriftWriter) elementEnd() {
+	w.stop()
+	w.last = w.stack[len(w.stack)-1]
+	w.stack = w.stack[:len(w.stack)-1]
+}
+
+func (w *thriftWriter) listBegin(id int16, elementType byte, size int) {
+	w.fieldHeader(id, thriftList)
+	if size < parquetMaxInlineListLen {
+		w.buf.WriteByte(byte(size)<<4 | elementType)
+		return
+	}
+	w.buf.WriteByte(0xF0 | elementType)
+	w.uvarint(uint64(size))
+}
+
+func (w *thriftWriter) listI32(n int32) {
+	w.varint(int64(n))
+}
+
+func (w *thriftWriter) listBinary(s string) {
+	w.uvarint(uint64(len(s)))
+	w.buf.WriteString(s)
+}
+
+// stop 结构体结束标记
+func (w *thriftWriter) stop() {
+	w.buf.WriteByte(0)
+}
+
+// decodeParquetStrings 读取encodeParquet写出的文件中一个字符串列的全部值，
+// 只支持REQUIRED、PLAIN编码、不压缩或gzip压缩的数据页
+func decodeParquetStrings(data []byte, column string) ([]string, error) {
+	if len(data) < 12 || string(data[:4]) != parquetMagic || string(data[len(data)-4:]) != parquetMagic {
+		return nil, fmt.Errorf("not a parquet file")
+	}
+	metaLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	if metaLen <= 0 || metaLen > len(data)-12 {
+		return nil, fmt.Errorf("invalid parquet footer length: %d", metaLen)
+	}
+	meta, err := (&thriftReader{buf: data[len(data)-8-metaLen : len(data)-8]}).readStruct()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read parquet metadata: %w", err)
+	}
+
+	found := false
+	for _, element := range thriftListOf(meta[2]) {
+		schema := thriftStructOf(element)
+		if string(thriftBytesOf(schema[4])) != column {
+			continue
+		}
+		if thriftIntOf(schema[1]) != parquetTypeByteArray || thriftIntOf(schema[3]) != parquetRequired {
+			return nil, fmt.Errorf("parquet column %s is not a required byte array", column)
+		}
+		found = true
+	}
+	if !found {
+		return nil, fmt.Errorf("parquet column %s not found", column)
+	}
+
+	var values []string
+	for _, group := range thriftListOf(meta[4]) {
+		for _, chunk := range thriftListOf(thriftStructOf(group)[1]) {
+			chunkMeta := thriftStructOf(thriftStructOf(chunk)[3])
+			path := thriftListOf(chunkMeta[3])
+			if len(path) != 1 || string(thriftBytesOf(path[0])) != column {
+				continue
+			}
+			chunkValues, err := decodeParquetChunk(data, chunkMeta)
+			if err != nil {
+				return nil, fmt.Errorf("parquet column %s: %w", column, err)
+			}
+			values = append(values, chunkValues...)
+		}
+	}
+	return values, nil
+}
+
+// decodeParquetChunk 依次读取列块中的数据页
+func decodeParquetChunk(data []byte, chunkMeta map[int16]interface{}) ([]string, error) {
+	codec := thriftIntOf(chunkMeta[4])
+	if codec != 0 && codec != parquetCodecGzip {
+		return nil, fmt.Errorf("unsupported codec: %d", codec)
+	}
+	remaining := thriftIntOf(chunkMeta[5])
+	offset := thriftIntOf(chunkMeta[9])
+
+	var values []string
+	for remaining > 0 {
+		if offset < 0 || offset >= int64(len(data)) {
+			return nil, fmt.Errorf("page offset %d out of range", offset)
+		}
+		reader := &thriftReader{buf: data[offset:]}
+		header, err := reader.readStruct()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read page header: %w", err)
+		}
+		if thriftIntOf(header[1]) != parquetPageTypeData {
+			return nil, fmt.Errorf("unsupported page type: %d", thriftIntOf(header[1]))
+		}
+		pageHeader := thriftStructOf(header[5])
+		if thriftIntOf(pageHeader[2]) != parquetEncodingPlain {
+			return nil, fmt.Errorf("unsupported encoding: %d", thriftIntOf(pageHeader[2]))
+		}
+
+		start := offset + int64(reader.pos)
+		end := start + thriftIntOf(header[3])
+		if end > int64(len(data)) {
+			return nil, fmt.Errorf("page exceeds file size")
+		}
+		page := data[start:end]
+		if codec == parquetCodecGzip {
+			zr, err := gzip.NewReader(bytes.NewReader(page))
+			if err != nil {
+				return nil, err
+			}
+			if page, err = io.ReadAll(zr); err != nil {
+				return nil, err
+			}
+		}
+
+		count := thriftIntOf(pageHeader[1])
+		for i := int64(0); i < count; i++ {
+			if len(page) < 4 {
+				return nil, fmt.Errorf("truncated page")
+			}
+			length := int(binary.LittleEndian.Uint32(page))
+			if length > len(page)-4 {
+				return nil, fmt.Errorf("truncated value")
+			}
+			values = append(values, string(page[4:4+length]))
+			page = page[4+length:]
+		}
+		remaining -= count
+		offset = end
+	}
+	return values, nil
+}
+
+// thriftReader thrift compact协议解码，结构体解码为字段ID到值的映射，
+// 整数为int64，binary为[]byte，列表为[]interface{}
+type thriftReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *thriftReader) readByte() (byte, error) {
+	if r.pos >= len(r.buf) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	b := r.buf[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *thriftReader) uvarint() (uint64, error) {
+	n, size := binary.Uvarint(r.buf[r.pos:])
+	if size <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	r.pos += size
+	return n, nil
+}
+
+func (r *thriftReader) varint() (int64, error) {
+	n, err := r.uvarint()
+	return int64(n>>1) ^ -int64(n&1), err
+}
+
+func (r *thriftReader) readStruct() (map[int16]interface{}, error) {
+	fields := make(map[int16]interface{})
+	var last int16
+	for {
+		header, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		if header == 0 {
+			return fields, nil
+		}
+
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			n, err := r.varint()
+			if err != nil {
+				return nil, err
+			}
+			id = int16(n)
+		}
+		last = id
+
+		// 布尔字段的值编码在类型中
+		fieldType := header & 0x0F
+		switch fieldType {
+		case 1:
+			fields[id] = int64(1)
+			continue
+		case 2:
+			fields[id] = int64(0)
+			continue
+		}
+		if fields[id], err = r.readValue(fieldType); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (r *thriftReader) readValue(valueType byte) (interface{}, error) {
+	switch valueType {
+	case 1, 2, 3:
+		b, err := r.readByte()
+		return int64(b), err
+	case 4, thriftI32, thriftI64:
+		return r.varint()
+	case 7:
+		if r.pos+8 > len(r.buf) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		r.pos += 8
+		return nil, nil
+	case thriftBinary:
+		n, err := r.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		if n > uint64(len(r.buf)-r.pos) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		value := r.buf[r.pos : r.pos+int(n)]
+		r.pos += int(n)
+		return value, nil
+	case thriftList, 10:
+		header, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		size := uint64(header >> 4)
+		if size == 0x0F {
+			if size, err = r.uvarint(); err != nil {
+				return nil, err
+			}
+		}
+		if size > uint64(len(r.buf)-r.pos) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		list := make([]interface{}, 0, size)
+		for i := uint64(0); i < size; i++ {
+			element, err := r.readValue(header & 0x0F)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, element)
+		}
+		return list, nil
+	case 11:
+		size, err := r.uvarint()
+		if err != nil || size == 0 {
+			return nil, err
+		}
+		types, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		for i := uint64(0); i < size; i++ {
+			if _, err := r.readValue(types >> 4); err != nil {
+				return nil, err
+			}
+			if _, err := r.readValue(types & 0x0F); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	case thriftStruct:
+		return r.readStruct()
+	default:
+		return nil, fmt.Errorf("unknown thrift type: %d", valueType)
+	}
+}
+
+func thriftIntOf(value interface{}) int64 {
+	n, _ := value.(int64)
+	return n
+}
+
+func thriftBytesOf(value interface{}) []byte {
+	b, _ := value.([]byte)
+	return b
+}
+
+func thriftListOf(value interface{}) []interface{} {
+	list, _ := value.([]interface{})
+	return list
+}
+
+func thriftStructOf(value interface{}) map[int16]interface{} {
+	fields, _ := value.(map[int16]interface{})
+	return fields
+}
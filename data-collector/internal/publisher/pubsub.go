@@ -0,0 +1,295 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// publish单次请求的上限
+const (
+	pubsubMaxMessages     = 1000
+	pubsubMaxRequestBytes = 9 << 20
+)
+
+// Google访问令牌
+const (
+	pubsubScope          = "https://www.googleapis.com/auth/pubsub"
+	googleMetadataToken  = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+	googleTokenLifetime  = time.Hour
+	googleTokenRefreshIn = time.Minute // 令牌过期前提前刷新的时间
+)
+
+// pubsubMessage publish请求中的消息，消息头作为属性
+type pubsubMessage struct {
+	Data       []byte            `json:"data"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// pubsubSender 通过REST API发布到Pub/Sub topic
+type pubsubSender struct {
+	http        *http.Client
+	url         string
+	topic       string
+	tokens      *googleTokenSource // 连接模拟器时为nil
+	maxAttempts int
+}
+
+// newPubSubSenders 加载凭证并返回按topic ID创建pubsubSender的构造函数
+func newPubSubSenders(cfg config.PubSubConfig, maxAttempts int) (newSenderFunc, error) {
+	endpoint := strings.TrimRight(cfg.Endpoint, "/")
+	var tokens *googleTokenSource
+	if host := os.Getenv("PUBSUB_EMULATOR_HOST"); host != "" {
+		endpoint = "http://" + host
+	} else {
+		credentialsFile := cfg.CredentialsFile
+		if credentialsFile == "" {
+			credentialsFile = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+		}
+		var err error
+		if tokens, err = newGoogleTokenSource(credentialsFile, pubsubScope); err != nil {
+			return nil, err
+		}
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	return func(topic string) recordSender {
+		return &pubsubSender{
+			http:        client,
+			url:         fmt.Sprintf("%s/v1/projects/%s/topics/%s:publish", endpoint, url.PathEscape(cfg.ProjectID), url.PathEscape(topic)),
+			topic:       topic,
+			tokens:      tokens,
+			maxAttempts: maxAttempts,
+		}
+	}, nil
+}
+
+// send 按请求上限拆分发布
+func (s *pubsubSender) send(ctx context.Context, messages []kafka.Message) error {
+	var batch []pubsubMessage
+	var size int
+	for _, message := range messages {
+		entry := pubsubMessage{Data: message.Value, Attributes: messageAttributes(message)}
+		entrySize := len(entry.Data)
+		for name, value := range entry.Attributes {
+			entrySize += len(name) + len(value)
+		}
+		if len(batch) == pubsubMaxMessages || (len(batch) > 0 && size+entrySize > pubsubMaxRequestBytes) {
+			if err := s.publish(ctx, batch); err != nil {
+				return err
+			}
+			batch, size = nil, 0
+		}
+		batch = append(batch, entry)
+		size += entrySize
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	return s.publish(ctx, batch)
+}
+
+// publish 发布一批消息，限流及服务端错误时重试
+func (s *pubsubSender) publish(ctx context.Context, messages []pubsubMessage) error {
+	body, err := json.Marshal(map[string]interface{}{"messages": messages})
+	if err != nil {
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		retryable, err := s.publishOnce(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retryable || attempt >= s.maxAttempts {
+			return err
+		}
+		if err := sleepContext(ctx, retryDelay(attempt)); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *pubsubSender) publishOnce(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.tokens != nil {
+		token, err := s.tokens.token(ctx)
+		if err != nil {
+			return true, fmt.Errorf("failed to get access token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+		return retryable, fmt.Errorf("pubsub returned %d for %s: %s", resp.StatusCode, s.topic, bytes.TrimSpace(message))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return false, nil
+}
+
+// serviceAccountKey 服务账号密钥文件中使用的字段
+type serviceAccountKey struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// googleTokenSource 获取并缓存Google访问令牌，配置了服务账号密钥时以JWT换取，否则从GCE元数据服务获取
+type googleTokenSource struct {
+	http       *http.Client
+	email      string
+	tokenURI   string
+	privateKey *rsa.PrivateKey
+	scope      string
+
+	accessToken string
+	expiry      time.Time
+	mu          sync.Mutex
+}
+
+func newGoogleTokenSource(credentialsFile, scope string) (*googleTokenSource, error) {
+	source := &googleTokenSource{http: &http.Client{Timeout: 10 * time.Second}, scope: scope}
+	if credentialsFile == "" {
+		return source, nil
+	}
+
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read google credentials: %w", err)
+	}
+	var key serviceAccountKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("invalid google credentials: %w", err)
+	}
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("invalid google credentials: private_key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		if parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+			return nil, fmt.Errorf("invalid google credentials: %w", err)
+		}
+	}
+	privateKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("invalid google credentials: private_key is not an RSA key")
+	}
+
+	source.email = key.ClientEmail
+	source.tokenURI = key.TokenURI
+	if source.tokenURI == "" {
+		source.tokenURI = "https://oauth2.googleapis.com/token"
+	}
+	source.privateKey = privateKey
+	return source, nil
+}
+
+// token 返回未过期的访问令牌
+func (s *googleTokenSource) token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.accessToken != "" && time.Until(s.expiry) > googleTokenRefreshIn {
+		return s.accessToken, nil
+	}
+
+	var req *http.Request
+	var err error
+	if s.privateKey != nil {
+		req, err = s.jwtRequest(ctx)
+	} else {
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, googleMetadataToken, nil)
+		if err == nil {
+			req.Header.Set("Metadata-Flavor", "Google")
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("token endpoint returned %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("invalid token response: %w", err)
+	}
+
+	s.accessToken = result.AccessToken
+	s.expiry = time.Now().Add(time.Duration(result.ExpiresIn) * time.Second)
+	return s.accessToken, nil
+}
+
+// jwtRequest 以服务账号签名的JWT换取访问令牌的请求
+func (s *googleTokenSource) jwtRequest(ctx context.Context) (*http.Request, error) {
+	now := time.Now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   s.email,
+		"scope": s.scope,
+		"aud":   s.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(googleTokenLifetime).Unix(),
+	})
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign token request: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req, nil
+}
@@ -0,0 +1,93 @@
+package publisher
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"web3-data-collector/internal/config"
+)
+
+// confluentMagicByte Confluent线格式的首字节，其后为4字节大端schema ID
+const confluentMagicByte = 0
+
+// schemaRegistry Confluent Schema Registry客户端
+type schemaRegistry struct {
+	url      string
+	username string
+	password string
+	http     *http.Client
+}
+
+func newSchemaRegistry(cfg config.SchemaRegistryConfig) *schemaRegistry {
+	return &schemaRegistry{
+		url:      strings.TrimRight(cfg.URL, "/"),
+		username: cfg.Username,
+		password: cfg.Password,
+		http:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// register 注册subject的schema并返回schema ID，相同schema重复注册时返回已有ID
+func (r *schemaRegistry) register(subject, schemaType, schema string) (uint32, error) {
+	request := map[string]string{"schema": schema}
+	// AVRO为默认类型，不填写schemaType以兼容旧版本
+	if schemaType != "AVRO" {
+		request["schemaType"] = schemaType
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/subjects/%s/versions", r.url, url.PathEscape(subject)), bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	if r.username != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return 0, fmt.Errorf("schema registry returned %d for %s: %s", resp.StatusCode, subject, bytes.TrimSpace(message))
+	}
+
+	var result struct {
+		ID uint32 `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("invalid schema registry response: %w", err)
+	}
+	return result.ID, nil
+}
+
+// confluentFrame 按Confluent线格式封装：magic byte + schema ID + 消息索引（仅protobuf）+ 数据
+func confluentFrame(schemaID uint32, messageIndex []byte, payload []byte) []byte {
+	frame := make([]byte, 0, 5+len(messageIndex)+len(payload))
+	frame = append(frame, confluentMagicByte)
+	frame = binary.BigEndian.AppendUint32(frame, schemaID)
+	frame = append(frame, messageIndex...)
+	return append(frame, payload...)
+}
+
+// protobufMessageIndex 消息在proto文件中的位置，第一个消息简写为单个0
+func protobufMessageIndex(index int) []byte {
+	if index == 0 {
+		return []byte{0}
+	}
+	return binary.AppendVarint(binary.AppendVarint(nil, 1), int64(index))
+}
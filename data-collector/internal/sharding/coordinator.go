@@ -0,0 +1,282 @@
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Assignee 按分配结果启停网络的收集器
+type Assignee interface {
+	// ShardNetworks 参与分配的网络
+	ShardNetworks() []string
+	// AssignNetworks 启动新分配的网络，停止不再分配的网络
+	AssignNetworks(networks []string)
+}
+
+// Status 分片状态
+type Status struct {
+	NodeID   string   `json:"node_id"`
+	Members  []string `json:"members"`  // 存活的实例
+	Networks []string `json:"networks"` // 本实例处理的网络
+}
+
+// Coordinator 通过Redis协调多个实例分担网络：各实例定期写入心跳，
+// 按存活实例列表以最高随机权重（rendezvous hashing）确定每个网络的归属，实例增减时只迁移受影响的网络；
+// 网络另有带有效期的锁，原实例停止网络并释放锁后新实例才接管，避免同一网络被两个实例同时处理
+type Coordinator struct {
+	client   *database.RedisClient
+	prefix   string
+	nodeID   string
+	lease    time.Duration
+	interval time.Duration
+
+	held      map[string]bool
+	members   []string
+	renewedAt time.Time
+	closed    bool
+	mu        sync.RWMutex
+	runMu     sync.Mutex // 串行化重新分配与释放
+}
+
+// NewCoordinator 根据配置创建分片协调器，未启用时返回nil
+func NewCoordinator(cfg config.ShardingConfig, client *database.RedisClient) (*Coordinator, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	lease, err := time.ParseDuration(cfg.LeaseDuration)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lease_duration: %w", err)
+	}
+	interval, err := time.ParseDuration(cfg.HeartbeatInterval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid heartbeat_interval: %w", err)
+	}
+
+	nodeID := cfg.NodeID
+	if nodeID == "" {
+		hostname, _ := os.Hostname()
+		nodeID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	return &Coordinator{
+		client:   client,
+		prefix:   cfg.KeyPrefix,
+		nodeID:   nodeID,
+		lease:    lease,
+		interval: interval,
+		held:     make(map[string]bool),
+	}, nil
+}
+
+// Run 按间隔发送心跳并重新分配网络，直到ctx结束
+func (c *Coordinator) Run(ctx context.Context, assignee Assignee) {
+	logrus.Infof("Network sharding started as %s", c.nodeID)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.rebalance(assignee)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// rebalance 续期已持有的网络锁，释放不再归属本实例的网络，获取新归属的网络
+func (c *Coordinator) rebalance(assignee Assignee) {
+	c.runMu.Lock()
+	defer c.runMu.Unlock()
+
+	if c.closed {
+		return
+	}
+
+	now := time.Now()
+	members, err := c.heartbeat(now)
+	if err != nil {
+		logrus.Warnf("Failed to send shard heartbeat: %v", err)
+
+		c.mu.RLock()
+		expiring := len(c.held) > 0 && time.Since(c.renewedAt) >= c.lease-c.interval
+		c.mu.RUnlock()
+		// 无法续期时在锁过期前一个间隔放弃全部网络，避免接管的实例与本实例同时处理
+		if expiring {
+			logrus.Errorf("Shard locks may expire, releasing all networks")
+			c.mu.Lock()
+			c.held = make(map[string]bool)
+			c.mu.Unlock()
+			assignee.AssignNetworks(nil)
+		}
+		return
+	}
+
+	desired := make(map[string]bool)
+	for _, network := range assignee.ShardNetworks() {
+		if owner(network, members) == c.nodeID {
+			desired[network] = true
+		}
+	}
+
+	c.mu.RLock()
+	held := make(map[string]bool, len(c.held))
+	for network := range c.held {
+		held[network] = true
+	}
+	c.mu.RUnlock()
+
+	var released []string
+	for network := range held {
+		if !desired[network] {
+			delete(held, network)
+			released = append(released, network)
+			continue
+		}
+
+		renewed, err := c.client.ExpireIfValue(c.lockKey(network), c.nodeID, c.lease)
+		if err != nil {
+			logrus.Warnf("Failed to renew shard lock for %s: %v", network, err)
+			continue
+		}
+		if !renewed {
+			logrus.Errorf("Shard lock for %s is no longer held by %s", network, c.nodeID)
+			delete(held, network)
+		}
+	}
+
+	for network := range desired {
+		if held[network] {
+			continue
+		}
+		// 未获取到时锁仍由原实例持有，待其停止网络并释放后接管
+		acquired, err := c.client.SetNX(c.lockKey(network), c.nodeID, c.lease)
+		if err != nil {
+			logrus.Warnf("Failed to acquire shard lock for %s: %v", network, err)
+			continue
+		}
+		if acquired {
+			held[network] = true
+		}
+	}
+
+	c.mu.Lock()
+	changed := len(held) != len(c.held)
+	for network := range held {
+		changed = changed || !c.held[network]
+	}
+	c.held = held
+	c.members = members
+	c.renewedAt = now
+	c.mu.Unlock()
+
+	if changed {
+		networks := sortedKeys(held)
+		logrus.Infof("Shard assignment changed: %d networks on %s (%d members)", len(networks), c.nodeID, len(members))
+		assignee.AssignNetworks(networks)
+	}
+
+	// 网络停止后再释放锁
+	for _, network := range released {
+		if _, err := c.client.DeleteIfValue(c.lockKey(network), c.nodeID); err != nil {
+			logrus.Warnf("Failed to release shard lock for %s: %v", network, err)
+		}
+	}
+}
+
+// heartbeat 写入本实例心跳，清理过期实例并返回存活实例
+func (c *Coordinator) heartbeat(now time.Time) ([]string, error) {
+	key := c.prefix + ":members"
+	if err := c.client.ZAdd(key, float64(now.UnixMilli()), c.nodeID); err != nil {
+		return nil, err
+	}
+	expired := strconv.FormatInt(now.Add(-c.lease).UnixMilli(), 10)
+	if err := c.client.ZRemRangeByScore(key, "-inf", "("+expired); err != nil {
+		return nil, err
+	}
+
+	members, err := c.client.ZRange(key, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(members)
+	return members, nil
+}
+
+// Release 释放本实例持有的网络锁并移除心跳，须在网络停止后调用；之后不再重新分配
+func (c *Coordinator) Release() {
+	c.runMu.Lock()
+	defer c.runMu.Unlock()
+
+	c.closed = true
+
+	c.mu.Lock()
+	held := c.held
+	c.held = make(map[string]bool)
+	c.mu.Unlock()
+
+	for network := range held {
+		if _, err := c.client.DeleteIfValue(c.lockKey(network), c.nodeID); err != nil {
+			logrus.Warnf("Failed to release shard lock for %s: %v", network, err)
+		}
+	}
+	if err := c.client.ZRem(c.prefix+":members", c.nodeID); err != nil {
+		logrus.Warnf("Failed to remove shard member %s: %v", c.nodeID, err)
+	}
+	logrus.Infof("Released %d shard locks", len(held))
+}
+
+// Status 获取分片状态
+func (c *Coordinator) Status() *Status {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return &Status{
+		NodeID:   c.nodeID,
+		Members:  append([]string{}, c.members...),
+		Networks: sortedKeys(c.held),
+	}
+}
+
+func (c *Coordinator) lockKey(network string) string {
+	return c.prefix + ":lock:" + network
+}
+
+// owner 按最高随机权重选出网络归属的实例，各实例在成员列表相同时得到相同结果
+func owner(network string, members []string) string {
+	var best string
+	var bestWeight uint64
+	for _, member := range members {
+		h := fnv.New64a()
+		h.Write([]byte(member))
+		h.Write([]byte{0})
+		h.Write([]byte(network))
+		if weight := h.Sum64(); best == "" || weight > bestWeight {
+			best, bestWeight = member, weight
+		}
+	}
+	return best
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,175 @@
+package siem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 转发的来源标识及默认超时
+const (
+	productName    = "web3-data-collector"
+	defaultTimeout = 10 * time.Second
+)
+
+// levelRanks 告警级别高低，用于min_level过滤
+var levelRanks = map[string]int{
+	"LOW":      1,
+	"MEDIUM":   2,
+	"HIGH":     3,
+	"CRITICAL": 4,
+}
+
+// exporter 单个SIEM系统的告警写入，fields为按字段映射处理后的告警字段
+type exporter interface {
+	export(ctx context.Context, alert *models.RiskAlert, fields map[string]interface{}) error
+	close() error
+}
+
+// target 转发目标及其过滤、映射配置
+type target struct {
+	name     string
+	exporter exporter
+	minRank  int
+	mapping  map[string]string
+	timeout  time.Duration
+}
+
+// Forwarder 将告警按字段映射转发到已配置的SIEM系统
+type Forwarder struct {
+	targets []*target
+}
+
+// NewForwarder 根据配置创建告警转发，未启用时返回nil
+func NewForwarder(cfg config.SIEMConfig) (*Forwarder, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	forwarder := &Forwarder{}
+	for _, exporterCfg := range cfg.Exporters {
+		name := exporterCfg.Name
+		if name == "" {
+			name = exporterCfg.Type
+		}
+
+		timeout := defaultTimeout
+		if exporterCfg.Timeout != "" {
+			parsed, err := time.ParseDuration(exporterCfg.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timeout for siem exporter %s: %w", name, err)
+			}
+			timeout = parsed
+		}
+
+		var exp exporter
+		mapping := exporterCfg.FieldMapping
+		switch exporterCfg.Type {
+		case "splunk_hec":
+			exp = newSplunkExporter(exporterCfg, timeout)
+		case "elastic":
+			exp = newElasticExporter(exporterCfg, timeout)
+		case "syslog":
+			exp = newSyslogExporter(exporterCfg, timeout)
+			if exporterCfg.Format != "json" {
+				mapping = cefFieldMapping(mapping)
+			}
+		default:
+			return nil, fmt.Errorf("unknown siem exporter type: %s", exporterCfg.Type)
+		}
+
+		forwarder.targets = append(forwarder.targets, &target{
+			name:     name,
+			exporter: exp,
+			minRank:  levelRanks[exporterCfg.MinLevel],
+			mapping:  mapping,
+			timeout:  timeout,
+		})
+		logrus.Infof("SIEM exporter %s enabled (type: %s, min_level: %s)", name, exporterCfg.Type, exporterCfg.MinLevel)
+	}
+
+	return forwarder, nil
+}
+
+// Export 转发告警到所有目标，各目标独立写入，失败的目标合并为一个错误返回
+func (f *Forwarder) Export(alert *models.RiskAlert) error {
+	var errs []error
+	for _, t := range f.targets {
+		if levelRanks[alert.Level] < t.minRank {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), t.timeout)
+		err := t.exporter.export(ctx, alert, t.fields(alert))
+		cancel()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", t.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close 关闭各目标的连接
+func (f *Forwarder) Close() {
+	for _, t := range f.targets {
+		if err := t.exporter.close(); err != nil {
+			logrus.Warnf("Failed to close siem exporter %s: %v", t.name, err)
+		}
+	}
+}
+
+// fields 按字段映射重命名告警字段，映射为空字符串的字段被丢弃
+func (t *target) fields(alert *models.RiskAlert) map[string]interface{} {
+	fields := alertFields(alert)
+	if len(t.mapping) == 0 {
+		return fields
+	}
+
+	mapped := make(map[string]interface{}, len(fields))
+	for name, value := range fields {
+		if renamed, ok := t.mapping[name]; ok {
+			if renamed == "" {
+				continue
+			}
+			name = renamed
+		}
+		mapped[name] = value
+	}
+	return mapped
+}
+
+// alertFields 展开为扁平字段，metadata各项以 metadata. 为前缀，空字符串字段省略
+func alertFields(alert *models.RiskAlert) map[string]interface{} {
+	fields := map[string]interface{}{
+		"id":         alert.ID,
+		"type":       alert.Type,
+		"level":      alert.Level,
+		"network":    alert.Network,
+		"risk_score": alert.RiskScore,
+		"timestamp":  alert.Timestamp.UTC().Format(time.RFC3339),
+	}
+	for name, value := range map[string]string{
+		"title":            alert.Title,
+		"description":      alert.Description,
+		"transaction_hash": alert.TransactionHash,
+		"address":          alert.Address,
+		"status":           alert.Status,
+	} {
+		if value != "" {
+			fields[name] = value
+		}
+	}
+	if len(alert.RiskFactors) > 0 {
+		fields["risk_factors"] = alert.RiskFactors
+	}
+	for name, value := range alert.Metadata {
+		fields["metadata."+name] = value
+	}
+	return fields
+}
@@ -0,0 +1,155 @@
+package siem
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/models"
+)
+
+// defaultSplunkSourceType 未配置sourcetype时按JSON解析
+const defaultSplunkSourceType = "_json"
+
+// splunkExporter 通过Splunk HTTP Event Collector写入告警
+type splunkExporter struct {
+	url        string
+	token      string
+	index      string
+	sourceType string
+	host       string
+	http       *http.Client
+}
+
+func newSplunkExporter(cfg config.SIEMExporterConfig, timeout time.Duration) *splunkExporter {
+	sourceType := cfg.SourceType
+	if sourceType == "" {
+		sourceType = defaultSplunkSourceType
+	}
+	host, _ := os.Hostname()
+
+	return &splunkExporter{
+		url:        cfg.URL,
+		token:      cfg.Token,
+		index:      cfg.Index,
+		sourceType: sourceType,
+		host:       host,
+		http:       &http.Client{Timeout: timeout},
+	}
+}
+
+func (se *splunkExporter) export(ctx context.Context, alert *models.RiskAlert, fields map[string]interface{}) error {
+	payload := map[string]interface{}{
+		"time":       float64(alert.Timestamp.UnixMilli()) / 1000,
+		"source":     productName,
+		"sourcetype": se.sourceType,
+		"event":      fields,
+	}
+	if se.host != "" {
+		payload["host"] = se.host
+	}
+	if se.index != "" {
+		payload["index"] = se.index
+	}
+
+	headers := map[string]string{}
+	if se.token != "" {
+		headers["Authorization"] = "Splunk " + se.token
+	}
+	return sendJSON(ctx, se.http, http.MethodPost, se.url, headers, payload)
+}
+
+func (se *splunkExporter) close() error {
+	se.http.CloseIdleConnections()
+	return nil
+}
+
+// elasticExporter 以告警ID为文档ID写入Elasticsearch索引，重复转发时覆盖同一文档
+type elasticExporter struct {
+	url      string
+	index    string
+	token    string
+	username string
+	password string
+	http     *http.Client
+}
+
+func newElasticExporter(cfg config.SIEMExporterConfig, timeout time.Duration) *elasticExporter {
+	return &elasticExporter{
+		url:      strings.TrimSuffix(cfg.URL, "/"),
+		index:    cfg.Index,
+		token:    cfg.Token,
+		username: cfg.Username,
+		password: cfg.Password,
+		http:     &http.Client{Timeout: timeout},
+	}
+}
+
+func (ee *elasticExporter) export(ctx context.Context, alert *models.RiskAlert, fields map[string]interface{}) error {
+	document := make(map[string]interface{}, len(fields)+1)
+	for name, value := range fields {
+		document[name] = value
+	}
+	document["@timestamp"] = alert.Timestamp.UTC().Format(time.RFC3339Nano)
+
+	headers := map[string]string{}
+	switch {
+	case ee.token != "":
+		headers["Authorization"] = "ApiKey " + ee.token
+	case ee.username != "":
+		headers["Authorization"] = basicAuth(ee.username, ee.password)
+	}
+
+	endpoint := fmt.Sprintf("%s/%s/_doc/%s", ee.url, url.PathEscape(ee.index), url.PathEscape(alert.ID))
+	return sendJSON(ctx, ee.http, http.MethodPut, endpoint, headers, document)
+}
+
+func (ee *elasticExporter) close() error {
+	ee.http.CloseIdleConnections()
+	return nil
+}
+
+// basicAuth 生成HTTP基本认证头
+func basicAuth(username, password string) string {
+	req := &http.Request{Header: http.Header{}}
+	req.SetBasicAuth(username, password)
+	return req.Header.Get("Authorization")
+}
+
+// sendJSON 发送JSON请求，非2xx响应视为失败
+func sendJSON(ctx context.Context, client *http.Client, method, endpoint string, headers map[string]string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s returned %d: %s", endpoint, resp.StatusCode, bytes.TrimSpace(message))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
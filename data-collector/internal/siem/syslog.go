@@ -0,0 +1,225 @@
+package siem
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/models"
+)
+
+// syslog设施local0，CEF版本信息
+const (
+	syslogFacilityLocal0 = 16
+	cefVersion           = "1.0"
+)
+
+// syslogSeverities 告警级别对应的syslog严重性
+var syslogSeverities = map[string]int{
+	"CRITICAL": 2,
+	"HIGH":     3,
+	"MEDIUM":   4,
+	"LOW":      5,
+}
+
+// cefSeverities 告警级别对应的CEF严重性（0-10）
+var cefSeverities = map[string]int{
+	"CRITICAL": 10,
+	"HIGH":     8,
+	"MEDIUM":   5,
+	"LOW":      3,
+}
+
+// defaultCEFMapping CEF格式的默认字段映射，类型、级别、标题已在CEF头中，时间在syslog头中
+var defaultCEFMapping = map[string]string{
+	"id":               "externalId",
+	"description":      "msg",
+	"transaction_hash": "cs1",
+	"network":          "cs2",
+	"address":          "cs3",
+	"risk_score":       "cfp1",
+	"type":             "",
+	"level":            "",
+	"title":            "",
+	"timestamp":        "",
+}
+
+// cefLabels 自定义扩展字段的标签
+var cefLabels = map[string]string{
+	"cs1":  "transaction_hash",
+	"cs2":  "network",
+	"cs3":  "address",
+	"cfp1": "risk_score",
+}
+
+// cefFieldMapping 在用户映射基础上补充CEF默认映射
+func cefFieldMapping(custom map[string]string) map[string]string {
+	mapping := make(map[string]string, len(defaultCEFMapping)+len(custom))
+	for name, renamed := range defaultCEFMapping {
+		mapping[name] = renamed
+	}
+	for name, renamed := range custom {
+		mapping[name] = renamed
+	}
+	return mapping
+}
+
+// syslogExporter 以RFC 5424格式发送syslog消息，消息体为CEF或JSON；TCP连接按换行分隔消息
+type syslogExporter struct {
+	network string
+	address string
+	format  string
+	host    string
+	timeout time.Duration
+	conn    net.Conn
+	mu      sync.Mutex
+}
+
+func newSyslogExporter(cfg config.SIEMExporterConfig, timeout time.Duration) *syslogExporter {
+	network, address, _ := strings.Cut(cfg.Address, "://")
+	format := cfg.Format
+	if format == "" {
+		format = "cef"
+	}
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "-"
+	}
+
+	return &syslogExporter{
+		network: network,
+		address: address,
+		format:  format,
+		host:    host,
+		timeout: timeout,
+	}
+}
+
+func (se *syslogExporter) export(ctx context.Context, alert *models.RiskAlert, fields map[string]interface{}) error {
+	var message string
+	if se.format == "json" {
+		body, err := json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+		message = string(body)
+	} else {
+		message = formatCEF(alert, fields)
+	}
+
+	severity, ok := syslogSeverities[alert.Level]
+	if !ok {
+		severity = 6
+	}
+	line := fmt.Sprintf("<%d>1 %s %s %s - %s - %s",
+		syslogFacilityLocal0*8+severity,
+		alert.Timestamp.UTC().Format(time.RFC3339Nano),
+		se.host, productName, syslogToken(alert.Type), message)
+	if se.network == "tcp" {
+		line += "\n"
+	}
+
+	se.mu.Lock()
+	defer se.mu.Unlock()
+
+	if se.conn == nil {
+		dialer := net.Dialer{Timeout: se.timeout}
+		conn, err := dialer.DialContext(ctx, se.network, se.address)
+		if err != nil {
+			return err
+		}
+		se.conn = conn
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(se.timeout)
+	}
+	se.conn.SetWriteDeadline(deadline)
+	if _, err := se.conn.Write([]byte(line)); err != nil {
+		// 连接断开后下次重新建立
+		se.conn.Close()
+		se.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (se *syslogExporter) close() error {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+
+	if se.conn == nil {
+		return nil
+	}
+	err := se.conn.Close()
+	se.conn = nil
+	return err
+}
+
+// formatCEF 生成CEF消息，扩展字段按名称排序
+func formatCEF(alert *models.RiskAlert, fields map[string]interface{}) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	extensions := make([]string, 0, len(names)*2)
+	for _, name := range names {
+		extensions = append(extensions, name+"="+cefExtensionEscaper.Replace(cefValue(fields[name])))
+		if label, ok := cefLabels[name]; ok {
+			if _, exists := fields[name+"Label"]; !exists {
+				extensions = append(extensions, name+"Label="+label)
+			}
+		}
+	}
+
+	return fmt.Sprintf("CEF:0|%s|%s|%s|%s|%s|%d|%s",
+		cefHeaderEscaper.Replace(productName),
+		cefHeaderEscaper.Replace(productName),
+		cefVersion,
+		cefHeaderEscaper.Replace(alert.Type),
+		cefHeaderEscaper.Replace(alert.Title),
+		cefSeverities[alert.Level],
+		strings.Join(extensions, " "))
+}
+
+// CEF头及扩展字段的转义规则
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ")
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+)
+
+// cefValue 格式化扩展字段值，列表以逗号分隔，复杂类型序列化为JSON
+func cefValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, ",")
+	case float64, float32, int, int64, uint64, uint, bool:
+		return fmt.Sprint(v)
+	default:
+		body, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(body)
+	}
+}
+
+// syslogToken 将告警类型转为syslog MSGID，不允许空白
+func syslogToken(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return strings.Join(strings.Fields(value), "_")
+}
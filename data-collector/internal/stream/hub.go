@@ -0,0 +1,270 @@
+package stream
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"web3-data-collector/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Hub 实时数据分发中心，向订阅者推送区块、交易与告警
+type Hub struct {
+	bufferSize   int
+	nextID       uint64
+	blockSubs    map[uint64]*blockSubscriber
+	txSubs       map[uint64]*txSubscriber
+	alertSubs    map[uint64]*alertSubscriber
+	latestBlocks map[string]*models.Block
+	dropped      uint64
+	closed       bool
+	mu           sync.RWMutex
+}
+
+type blockSubscriber struct {
+	networks map[string]bool
+	ch       chan *models.Block
+}
+
+type txSubscriber struct {
+	networks map[string]bool
+	ch       chan *models.Transaction
+}
+
+type alertSubscriber struct {
+	networks map[string]bool
+	ch       chan *models.RiskAlert
+}
+
+// NewHub 创建新的分发中心
+func NewHub(bufferSize int) *Hub {
+	if bufferSize <= 0 {
+		bufferSize = 256
+	}
+
+	return &Hub{
+		bufferSize:   bufferSize,
+		blockSubs:    make(map[uint64]*blockSubscriber),
+		txSubs:       make(map[uint64]*txSubscriber),
+		alertSubs:    make(map[uint64]*alertSubscriber),
+		latestBlocks: make(map[string]*models.Block),
+	}
+}
+
+// SubscribeBlocks 订阅区块，networks为空时订阅全部网络；返回取消订阅函数
+func (h *Hub) SubscribeBlocks(networks []string) (<-chan *models.Block, func()) {
+	sub := &blockSubscriber{
+		networks: toNetworkSet(networks),
+		ch:       make(chan *models.Block, h.bufferSize),
+	}
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		close(sub.ch)
+		return sub.ch, func() {}
+	}
+	h.nextID++
+	id := h.nextID
+	h.blockSubs[id] = sub
+	h.mu.Unlock()
+
+	return sub.ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, exists := h.blockSubs[id]; exists {
+			delete(h.blockSubs, id)
+			close(sub.ch)
+		}
+	}
+}
+
+// SubscribeTransactions 订阅交易，networks为空时订阅全部网络；返回取消订阅函数
+func (h *Hub) SubscribeTransactions(networks []string) (<-chan *models.Transaction, func()) {
+	sub := &txSubscriber{
+		networks: toNetworkSet(networks),
+		ch:       make(chan *models.Transaction, h.bufferSize),
+	}
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		close(sub.ch)
+		return sub.ch, func() {}
+	}
+	h.nextID++
+	id := h.nextID
+	h.txSubs[id] = sub
+	h.mu.Unlock()
+
+	return sub.ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, exists := h.txSubs[id]; exists {
+			delete(h.txSubs, id)
+			close(sub.ch)
+		}
+	}
+}
+
+// SubscribeAlerts 订阅告警，networks为空时订阅全部网络；返回取消订阅函数
+func (h *Hub) SubscribeAlerts(networks []string) (<-chan *models.RiskAlert, func()) {
+	sub := &alertSubscriber{
+		networks: toNetworkSet(networks),
+		ch:       make(chan *models.RiskAlert, h.bufferSize),
+	}
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		close(sub.ch)
+		return sub.ch, func() {}
+	}
+	h.nextID++
+	id := h.nextID
+	h.alertSubs[id] = sub
+	h.mu.Unlock()
+
+	return sub.ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, exists := h.alertSubs[id]; exists {
+			delete(h.alertSubs, id)
+			close(sub.ch)
+		}
+	}
+}
+
+// PublishBlock 分发区块，订阅者处理过慢时丢弃消息而不阻塞处理流程
+func (h *Hub) PublishBlock(block *models.Block) {
+	h.mu.Lock()
+	h.latestBlocks[block.Network] = block
+	h.mu.Unlock()
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, sub := range h.blockSubs {
+		if !matchNetwork(sub.networks, block.Network) {
+			continue
+		}
+		select {
+		case sub.ch <- block:
+		default:
+			h.recordDrop("block", block.Network)
+		}
+	}
+}
+
+// PublishTransaction 分发交易
+func (h *Hub) PublishTransaction(tx *models.Transaction) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, sub := range h.txSubs {
+		if !matchNetwork(sub.networks, tx.Network) {
+			continue
+		}
+		select {
+		case sub.ch <- tx:
+		default:
+			h.recordDrop("transaction", tx.Network)
+		}
+	}
+}
+
+// PublishAlert 分发告警
+func (h *Hub) PublishAlert(alert *models.RiskAlert) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, sub := range h.alertSubs {
+		if !matchNetwork(sub.networks, alert.Network) {
+			continue
+		}
+		select {
+		case sub.ch <- alert:
+		default:
+			h.recordDrop("alert", alert.Network)
+		}
+	}
+}
+
+// Close 关闭分发中心，结束所有订阅者的通道，使流式订阅返回；之后的订阅立即结束
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+	h.closed = true
+
+	for id, sub := range h.blockSubs {
+		delete(h.blockSubs, id)
+		close(sub.ch)
+	}
+	for id, sub := range h.txSubs {
+		delete(h.txSubs, id)
+		close(sub.ch)
+	}
+	for id, sub := range h.alertSubs {
+		delete(h.alertSubs, id)
+		close(sub.ch)
+	}
+}
+
+// LatestBlock 获取网络最近分发的区块
+func (h *Hub) LatestBlock(network string) (*models.Block, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	block, exists := h.latestBlocks[network]
+	return block, exists
+}
+
+// DroppedCount 获取因订阅者过慢而丢弃的消息数
+func (h *Hub) DroppedCount() uint64 {
+	return atomic.LoadUint64(&h.dropped)
+}
+
+// QueueDepth 获取所有订阅者缓冲区中尚未读取的消息数
+func (h *Hub) QueueDepth() int64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var depth int64
+	for _, sub := range h.blockSubs {
+		depth += int64(len(sub.ch))
+	}
+	for _, sub := range h.txSubs {
+		depth += int64(len(sub.ch))
+	}
+	for _, sub := range h.alertSubs {
+		depth += int64(len(sub.ch))
+	}
+	return depth
+}
+
+// recordDrop 记录丢弃的消息
+func (h *Hub) recordDrop(kind, network string) {
+	atomic.AddUint64(&h.dropped, 1)
+	logrus.Debugf("Stream subscriber too slow, dropped %s for %s", kind, network)
+}
+
+// toNetworkSet 转换网络列表为集合
+func toNetworkSet(networks []string) map[string]bool {
+	set := make(map[string]bool, len(networks))
+	for _, network := range networks {
+		if network != "" {
+			set[network] = true
+		}
+	}
+	return set
+}
+
+// matchNetwork 检查网络是否在订阅范围内
+func matchNetwork(networks map[string]bool, network string) bool {
+	return len(networks) == 0 || networks[network]
+}
@@ -0,0 +1,209 @@
+package warehouse
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/metrics"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AddressStatsChangedKey 上次导出后统计有变化的地址集合，成员为 网络:地址
+const AddressStatsChangedKey = "address_stats:changed"
+
+// AddressStatsKey 地址统计在Redis中的键，字段结构见 processor.RedisLayouts
+func AddressStatsKey(network, address string) string {
+	return fmt.Sprintf("address_stats:%s:%s", network, address)
+}
+
+const addressStatsDataset = "address_stats"
+
+// addressStatsDDL 按(network, address)去重，保留exported_at最新的一行
+const addressStatsDDL = `CREATE TABLE IF NOT EXISTS %s (
+	network         LowCardinality(String),
+	address         String,
+	sent_count      UInt64,
+	sent_volume     UInt256,
+	received_count  UInt64,
+	received_volume UInt256,
+	first_seen      DateTime,
+	last_activity   DateTime,
+	exported_at     DateTime
+) ENGINE = ReplacingMergeTree(exported_at)
+ORDER BY (network, address)`
+
+// addressStatsRow 导出的地址统计行，金额为最小单位的十进制字符串
+type addressStatsRow struct {
+	Network        string `json:"network"`
+	Address        string `json:"address"`
+	SentCount      int64  `json:"sent_count"`
+	SentVolume     string `json:"sent_volume"`
+	ReceivedCount  int64  `json:"received_count"`
+	ReceivedVolume string `json:"received_volume"`
+	FirstSeen      int64  `json:"first_seen"`
+	LastActivity   int64  `json:"last_activity"`
+	ExportedAt     int64  `json:"exported_at"`
+}
+
+// AddressStatsExporter 定期将有变化的地址统计增量导出到ClickHouse
+type AddressStatsExporter struct {
+	client         *database.RedisClient
+	clickhouse     *clickHouseClient
+	table          string
+	interval       time.Duration
+	batchSize      int64
+	tableReady     bool
+	metricsManager *metrics.Manager
+}
+
+// NewAddressStatsExporter 创建地址统计导出任务，未启用时返回nil
+func NewAddressStatsExporter(cfg config.WarehouseConfig, redisClient *database.RedisClient, metricsManager *metrics.Manager) (*AddressStatsExporter, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	exporter := &AddressStatsExporter{
+		client:         redisClient,
+		clickhouse:     newClickHouseClient(cfg),
+		table:          cfg.Table,
+		interval:       time.Minute,
+		batchSize:      int64(cfg.BatchSize),
+		metricsManager: metricsManager,
+	}
+
+	if cfg.Interval != "" {
+		interval, err := time.ParseDuration(cfg.Interval)
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("invalid warehouse interval: %q", cfg.Interval)
+		}
+		exporter.interval = interval
+	}
+	if exporter.batchSize <= 0 {
+		exporter.batchSize = 1000
+	}
+
+	logrus.Infof("Address stats export to %s.%s enabled (interval: %v)", cfg.Database, cfg.Table, exporter.interval)
+	return exporter, nil
+}
+
+// Run 按周期导出，直到ctx结束
+func (e *AddressStatsExporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.export(ctx)
+		}
+	}
+}
+
+// export 分批取出有变化的地址并写入，写入失败时放回集合等待下次导出
+func (e *AddressStatsExporter) export(ctx context.Context) {
+	if !e.tableReady {
+		if err := e.clickhouse.exec(ctx, fmt.Sprintf(addressStatsDDL, e.table)); err != nil {
+			logrus.Errorf("Failed to create warehouse table %s: %v", e.table, err)
+			return
+		}
+		e.tableReady = true
+	}
+
+	for ctx.Err() == nil {
+		members, err := e.client.SPopN(AddressStatsChangedKey, e.batchSize)
+		if err != nil {
+			logrus.Errorf("Failed to get changed address stats: %v", err)
+			return
+		}
+		if len(members) == 0 {
+			return
+		}
+
+		now := time.Now().Unix()
+		rows := make([]interface{}, 0, len(members))
+		for _, member := range members {
+			row, err := e.loadRow(member, now)
+			if err != nil {
+				logrus.Warnf("Skipping address stats %s: %v", member, err)
+				continue
+			}
+			if row != nil {
+				rows = append(rows, row)
+			}
+		}
+
+		if len(rows) > 0 {
+			if err := e.clickhouse.insertJSON(ctx, e.table, rows); err != nil {
+				logrus.Errorf("Failed to export %d address stats to warehouse: %v", len(rows), err)
+				e.metricsManager.RecordWarehouseRows(addressStatsDataset, "failed", len(rows))
+				e.requeue(members)
+				return
+			}
+			e.metricsManager.RecordWarehouseRows(addressStatsDataset, "exported", len(rows))
+			logrus.Debugf("Exported %d address stats to warehouse", len(rows))
+		}
+
+		if int64(len(members)) < e.batchSize {
+			return
+		}
+	}
+}
+
+// loadRow 读取地址当前的统计，统计已过期时返回nil
+func (e *AddressStatsExporter) loadRow(member string, exportedAt int64) (*addressStatsRow, error) {
+	network, address, ok := strings.Cut(member, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid member")
+	}
+
+	stats, err := e.client.HGetAll(AddressStatsKey(network, address))
+	if err != nil {
+		return nil, err
+	}
+	if len(stats) == 0 {
+		return nil, nil
+	}
+
+	return &addressStatsRow{
+		Network:        network,
+		Address:        address,
+		SentCount:      parseInt(stats["sent_count"]),
+		SentVolume:     volume(stats["sent_volume"]),
+		ReceivedCount:  parseInt(stats["received_count"]),
+		ReceivedVolume: volume(stats["received_volume"]),
+		FirstSeen:      parseInt(stats["first_seen"]),
+		LastActivity:   parseInt(stats["last_activity"]),
+		ExportedAt:     exportedAt,
+	}, nil
+}
+
+// requeue 将未导出的地址放回集合
+func (e *AddressStatsExporter) requeue(members []string) {
+	values := make([]interface{}, len(members))
+	for i, member := range members {
+		values[i] = member
+	}
+	if err := e.client.SAdd(AddressStatsChangedKey, values...); err != nil {
+		logrus.Errorf("Failed to requeue %d address stats, they will be exported on their next change: %v", len(members), err)
+	}
+}
+
+func parseInt(value string) int64 {
+	parsed, _ := strconv.ParseInt(value, 10, 64)
+	return parsed
+}
+
+func volume(value string) string {
+	if value == "" {
+		return "0"
+	}
+	return value
+}
@@ -0,0 +1,182 @@
+package warehouse
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/metrics"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AddressStatsActivityKey 地址最近一次活动时间的有序集合，成员为 网络:地址，分数为写入时的Unix时间
+const AddressStatsActivityKey = "address_stats:activity"
+
+const addressStatsRollupDataset = "address_stats_rollup"
+
+// AddressStatsTTL 地址统计键的过期时间，未配置保留时长时为0。
+// 启用汇总时额外保留两个检查周期，到期自动删除只作为清理任务停止时的兜底
+func AddressStatsTTL(cfg config.AddressStatsConfig) time.Duration {
+	if cfg.Retention == "" {
+		return 0
+	}
+	retention, err := time.ParseDuration(cfg.Retention)
+	if err != nil || retention <= 0 {
+		return 0
+	}
+	if cfg.Rollup.Enabled {
+		if interval, err := time.ParseDuration(cfg.Rollup.Interval); err == nil && interval > 0 {
+			retention += 2 * interval
+		}
+	}
+	return retention
+}
+
+// AddressStatsPruner 定期将超过保留时长未活动的地址统计汇总到InfluxDB后从Redis删除
+type AddressStatsPruner struct {
+	client         *database.RedisClient
+	influx         *database.InfluxDBClient
+	retention      time.Duration
+	interval       time.Duration
+	batchSize      int64
+	metricsManager *metrics.Manager
+}
+
+// NewAddressStatsPruner 创建不活跃地址统计的汇总清理任务，未启用汇总时返回nil
+func NewAddressStatsPruner(cfg config.AddressStatsConfig, redisClient *database.RedisClient, influxClient *database.InfluxDBClient, metricsManager *metrics.Manager) (*AddressStatsPruner, error) {
+	if !cfg.Rollup.Enabled {
+		return nil, nil
+	}
+	if influxClient == nil {
+		return nil, fmt.Errorf("address stats rollup requires InfluxDB")
+	}
+
+	retention, err := time.ParseDuration(cfg.Retention)
+	if err != nil || retention <= 0 {
+		return nil, fmt.Errorf("invalid address stats retention: %q", cfg.Retention)
+	}
+	interval, err := time.ParseDuration(cfg.Rollup.Interval)
+	if err != nil || interval <= 0 {
+		return nil, fmt.Errorf("invalid address stats rollup interval: %q", cfg.Rollup.Interval)
+	}
+
+	pruner := &AddressStatsPruner{
+		client:         redisClient,
+		influx:         influxClient,
+		retention:      retention,
+		interval:       interval,
+		batchSize:      int64(cfg.Rollup.BatchSize),
+		metricsManager: metricsManager,
+	}
+	if pruner.batchSize <= 0 {
+		pruner.batchSize = 1000
+	}
+
+	logrus.Infof("Address stats rollup enabled (retention: %v, interval: %v)", retention, interval)
+	return pruner, nil
+}
+
+// Run 按周期清理，直到ctx结束
+func (p *AddressStatsPruner) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.prune(ctx)
+		}
+	}
+}
+
+// prune 分批取出超过保留时长未活动的地址，汇总后删除
+func (p *AddressStatsPruner) prune(ctx context.Context) {
+	cutoff := time.Now().Add(-p.retention).Unix()
+	max := strconv.FormatInt(cutoff, 10)
+
+	rolledUp, pruned := 0, 0
+	defer func() {
+		if rolledUp > 0 {
+			p.metricsManager.RecordWarehouseRows(addressStatsRollupDataset, "exported", rolledUp)
+		}
+		if pruned > 0 {
+			logrus.Debugf("Rolled up and pruned %d inactive address stats", pruned)
+		}
+	}()
+
+	for ctx.Err() == nil {
+		members, err := p.client.ZRangeByScoreN(AddressStatsActivityKey, "-inf", max, p.batchSize)
+		if err != nil {
+			logrus.Errorf("Failed to get inactive address stats: %v", err)
+			return
+		}
+		if len(members) == 0 {
+			return
+		}
+
+		for _, member := range members {
+			written, err := p.rollup(member, cutoff)
+			if err != nil {
+				// 留在集合中等待下个周期重试
+				logrus.Errorf("Failed to roll up address stats %s: %v", member, err)
+				return
+			}
+			if written {
+				rolledUp++
+			}
+			pruned++
+		}
+
+		if int64(len(members)) < p.batchSize {
+			return
+		}
+	}
+}
+
+// rollup 将地址统计写入InfluxDB后删除，期间地址有新活动时保留统计，返回是否写入
+func (p *AddressStatsPruner) rollup(member string, cutoff int64) (bool, error) {
+	network, address, ok := strings.Cut(member, ":")
+	if !ok {
+		return false, p.client.ZRem(AddressStatsActivityKey, member)
+	}
+
+	key := AddressStatsKey(network, address)
+	stats, err := p.client.HGetAll(key)
+	if err != nil {
+		return false, err
+	}
+
+	written := false
+	if len(stats) > 0 {
+		fields := map[string]interface{}{
+			"sent_count":      parseInt(stats["sent_count"]),
+			"sent_volume":     volume(stats["sent_volume"]),
+			"received_count":  parseInt(stats["received_count"]),
+			"received_volume": volume(stats["received_volume"]),
+			"first_seen":      parseInt(stats["first_seen"]),
+			"last_activity":   parseInt(stats["last_activity"]),
+		}
+		if count, exists := stats["withdrawal_count"]; exists {
+			fields["withdrawal_count"] = parseInt(count)
+			fields["withdrawal_volume"] = volume(stats["withdrawal_volume"])
+		}
+		tags := map[string]string{
+			"network": network,
+			"address": address,
+		}
+		if err := p.influx.WritePoint("address_stats", tags, fields, time.Now()); err != nil {
+			return false, err
+		}
+		written = true
+	}
+
+	_, err = p.client.DeleteIfScore(AddressStatsActivityKey, member, float64(cutoff), key)
+	return written, err
+}
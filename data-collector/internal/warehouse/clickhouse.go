@@ -0,0 +1,78 @@
+package warehouse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"web3-data-collector/internal/config"
+)
+
+// clickHouseClient 通过HTTP接口执行ClickHouse语句
+type clickHouseClient struct {
+	url      string
+	database string
+	username string
+	password string
+	http     *http.Client
+}
+
+func newClickHouseClient(cfg config.WarehouseConfig) *clickHouseClient {
+	return &clickHouseClient{
+		url:      cfg.URL,
+		database: cfg.Database,
+		username: cfg.Username,
+		password: cfg.Password,
+		http:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// exec 执行不带数据的语句
+func (c *clickHouseClient) exec(ctx context.Context, query string) error {
+	return c.post(ctx, query, nil)
+}
+
+// insertJSON 以JSONEachRow格式批量写入
+func (c *clickHouseClient) insertJSON(ctx context.Context, table string, rows []interface{}) error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, row := range rows {
+		if err := encoder.Encode(row); err != nil {
+			return err
+		}
+	}
+	return c.post(ctx, fmt.Sprintf("INSERT INTO %s FORMAT JSONEachRow", table), &body)
+}
+
+func (c *clickHouseClient) post(ctx context.Context, query string, body io.Reader) error {
+	params := url.Values{"query": {query}}
+	if c.database != "" {
+		params.Set("database", c.database)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+"/?"+params.Encode(), body)
+	if err != nil {
+		return err
+	}
+	if c.username != "" {
+		req.Header.Set("X-ClickHouse-User", c.username)
+		req.Header.Set("X-ClickHouse-Key", c.password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("clickhouse returned %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package watchdog
+
+import (
+	"context"
+	"hash/fnv"
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/metrics"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 降载级别，高级别包含低级别的全部措施
+const (
+	LevelNormal         = 0
+	LevelShedEnrichment = 1 // 跳过低优先级的富化与分析
+	LevelSampling       = 2 // 交易抽样发布
+	LevelPauseIngest    = 3 // 暂停历史日志回填
+)
+
+// recoverMargin 降低级别前需低于阈值的比例，避免在阈值附近反复切换
+const recoverMargin = 0.05
+
+var levelNames = []string{"normal", "shed_enrichment", "sampling", "pause_ingest"}
+
+// MemoryStatus 内存使用及降载状态
+type MemoryStatus struct {
+	HeapBytes   uint64  `json:"heap_bytes"`
+	BudgetBytes uint64  `json:"budget_bytes"`
+	Usage       float64 `json:"usage"`
+	Level       int     `json:"level"`
+	LevelName   string  `json:"level_name"`
+}
+
+// MemoryWatchdog 按堆内存占预算的比例逐级降载
+type MemoryWatchdog struct {
+	budget         uint64
+	interval       time.Duration
+	thresholds     []float64 // 依次为进入各降载级别的比例
+	sampleRate     float64
+	level          int32
+	heapBytes      uint64
+	metricsManager *metrics.Manager
+}
+
+// NewMemoryWatchdog 创建内存看门狗，未启用时返回nil
+func NewMemoryWatchdog(cfg config.MemoryConfig, metricsManager *metrics.Manager) *MemoryWatchdog {
+	if !cfg.Enabled || cfg.BudgetMB <= 0 {
+		return nil
+	}
+
+	watchdog := &MemoryWatchdog{
+		budget:         uint64(cfg.BudgetMB) * 1024 * 1024,
+		interval:       5 * time.Second,
+		thresholds:     []float64{cfg.ShedEnrichment, cfg.Sampling, cfg.PauseIngest},
+		sampleRate:     cfg.SampleRate,
+		metricsManager: metricsManager,
+	}
+
+	if cfg.CheckInterval != "" {
+		if interval, err := time.ParseDuration(cfg.CheckInterval); err == nil && interval > 0 {
+			watchdog.interval = interval
+		} else {
+			logrus.Warnf("Invalid memory check_interval %q, using %v", cfg.CheckInterval, watchdog.interval)
+		}
+	}
+	if watchdog.sampleRate <= 0 || watchdog.sampleRate > 1 {
+		watchdog.sampleRate = 0.25
+	}
+
+	// 接近预算时由GC更积极地回收
+	debug.SetMemoryLimit(int64(watchdog.budget))
+
+	logrus.Infof("Memory watchdog enabled (budget: %d MB, thresholds: %v)", cfg.BudgetMB, watchdog.thresholds)
+	return watchdog
+}
+
+// Run 定期检查堆内存并调整降载级别，直到ctx结束
+func (w *MemoryWatchdog) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// check 采样堆内存并更新级别
+func (w *MemoryWatchdog) check() {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	atomic.StoreUint64(&w.heapBytes, stats.HeapAlloc)
+
+	usage := float64(stats.HeapAlloc) / float64(w.budget)
+	current := w.Level()
+	next := w.levelFor(usage, current)
+
+	if next != current {
+		atomic.StoreInt32(&w.level, int32(next))
+		if next > current {
+			logrus.Warnf("Heap usage %.0f%% of budget, load shedding raised to %s", usage*100, levelNames[next])
+		} else {
+			logrus.Infof("Heap usage %.0f%% of budget, load shedding lowered to %s", usage*100, levelNames[next])
+		}
+		if next == LevelPauseIngest {
+			debug.FreeOSMemory()
+		}
+	}
+
+	w.metricsManager.SetMemoryUsage(stats.HeapAlloc, next)
+}
+
+// levelFor 根据使用比例计算级别，降低级别需低于阈值recoverMargin
+func (w *MemoryWatchdog) levelFor(usage float64, current int) int {
+	level := LevelNormal
+	for i, threshold := range w.thresholds {
+		if threshold <= 0 {
+			continue
+		}
+		enter := threshold
+		if i+1 <= current {
+			enter = threshold - recoverMargin
+		}
+		if usage >= enter {
+			level = i + 1
+		}
+	}
+	return level
+}
+
+// Level 获取当前降载级别
+func (w *MemoryWatchdog) Level() int {
+	return int(atomic.LoadInt32(&w.level))
+}
+
+// Shedding 判断是否已达到指定降载级别
+func (w *MemoryWatchdog) Shedding(level int) bool {
+	return w.Level() >= level
+}
+
+// Sample 抽样阶段按key的哈希决定是否保留，相同key结果稳定；未抽样时总是保留
+func (w *MemoryWatchdog) Sample(key string) bool {
+	if !w.Shedding(LevelSampling) {
+		return true
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+	return float64(hash.Sum32()%10000) < w.sampleRate*10000
+}
+
+// Status 获取内存使用及降载状态
+func (w *MemoryWatchdog) Status() *MemoryStatus {
+	heapBytes := atomic.LoadUint64(&w.heapBytes)
+	level := w.Level()
+	return &MemoryStatus{
+		HeapBytes:   heapBytes,
+		BudgetBytes: w.budget,
+		Usage:       float64(heapBytes) / float64(w.budget),
+		Level:       level,
+		LevelName:   levelNames[level],
+	}
+}
@@ -0,0 +1,271 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"web3-data-collector/internal/config"
+	"web3-data-collector/internal/metrics"
+	"web3-data-collector/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 推送的事件类型
+const (
+	EventBlock       = "block"
+	EventTransaction = "transaction"
+	EventAlert       = "alert"
+)
+
+// 单个地址的默认推送参数
+const (
+	defaultTimeout        = 10 * time.Second
+	defaultMaxRetries     = 5
+	defaultInitialBackoff = time.Second
+	defaultMaxBackoff     = time.Minute
+	defaultQueueSize      = 1000
+)
+
+// closeTimeout 关闭时等待队列推送完成的最长时间，超时后放弃剩余的推送
+const closeTimeout = 30 * time.Second
+
+// levelRanks 告警级别高低，用于min_level过滤
+var levelRanks = map[string]int{
+	"LOW":      1,
+	"MEDIUM":   2,
+	"HIGH":     3,
+	"CRITICAL": 4,
+}
+
+// Payload 推送的请求体
+type Payload struct {
+	ID        string      `json:"id"` // 推送ID，重试时不变，接收方可据此去重
+	Event     string      `json:"event"`
+	Network   string      `json:"network"`
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+// Dispatcher 将区块、交易及告警按各地址的过滤条件异步推送，每个地址独立排队和重试
+type Dispatcher struct {
+	endpoints      []*endpoint
+	metricsManager *metrics.Manager
+	ctx            context.Context
+	cancel         context.CancelFunc
+	workers        sync.WaitGroup
+	closeOnce      sync.Once
+}
+
+// NewDispatcher 根据配置创建Webhook推送，未启用时返回nil
+func NewDispatcher(cfg config.WebhookConfig, metricsManager *metrics.Manager) (*Dispatcher, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	dispatcher := &Dispatcher{
+		metricsManager: metricsManager,
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+	for _, endpointCfg := range cfg.Endpoints {
+		ep, err := newEndpoint(endpointCfg)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		dispatcher.endpoints = append(dispatcher.endpoints, ep)
+		logrus.Infof("Webhook endpoint %s enabled (events: %s)", ep.name, strings.Join(endpointCfg.Events, ","))
+	}
+
+	for _, ep := range dispatcher.endpoints {
+		dispatcher.workers.Add(1)
+		go dispatcher.run(ep)
+	}
+
+	return dispatcher, nil
+}
+
+// PublishBlock 推送区块摘要，不含交易列表
+func (d *Dispatcher) PublishBlock(block *models.Block) error {
+	summary := *block
+	summary.Transactions = nil
+	return d.publish(EventBlock, block.Network, block.ID, block.Timestamp, &summary, func(f *filter) bool {
+		return f.matchBlock(block)
+	})
+}
+
+// PublishTransaction 推送交易
+func (d *Dispatcher) PublishTransaction(tx *models.Transaction) error {
+	return d.publish(EventTransaction, tx.Network, tx.ID, tx.Timestamp, tx, func(f *filter) bool {
+		return f.matchTransaction(tx)
+	})
+}
+
+// PublishAlert 推送告警
+func (d *Dispatcher) PublishAlert(alert *models.RiskAlert) error {
+	return d.publish(EventAlert, alert.Network, alert.ID, alert.Timestamp, alert, func(f *filter) bool {
+		return f.matchAlert(alert)
+	})
+}
+
+// publish 序列化一次后加入各匹配地址的队列，队列满时丢弃并记录
+func (d *Dispatcher) publish(event, network, recordID string, timestamp time.Time, data interface{}, match func(*filter) bool) error {
+	id := fmt.Sprintf("%s:%s", event, recordID)
+	var body []byte
+	for _, ep := range d.endpoints {
+		if !ep.filter.matchEvent(event, network) || !match(ep.filter) {
+			continue
+		}
+
+		if body == nil {
+			payload := Payload{
+				ID:        id,
+				Event:     event,
+				Network:   network,
+				Timestamp: timestamp,
+				Data:      data,
+			}
+			var err error
+			if body, err = json.Marshal(payload); err != nil {
+				return fmt.Errorf("failed to marshal webhook payload: %w", err)
+			}
+		}
+
+		delivery := &delivery{id: id, event: event, body: body}
+		select {
+		case ep.queue <- delivery:
+			d.metricsManager.SetWebhookQueueDepth(ep.name, len(ep.queue))
+		default:
+			d.metricsManager.RecordWebhookDelivery(ep.name, event, "dropped", 0, 0)
+			logrus.Warnf("Webhook queue for %s is full, dropping %s", ep.name, delivery.id)
+		}
+	}
+	return nil
+}
+
+// run 按顺序推送地址队列中的数据，直到队列关闭
+func (d *Dispatcher) run(ep *endpoint) {
+	defer d.workers.Done()
+
+	for delivery := range ep.queue {
+		d.metricsManager.SetWebhookQueueDepth(ep.name, len(ep.queue))
+
+		start := time.Now()
+		attempts, err := ep.deliver(d.ctx, delivery)
+		result := "success"
+		if err != nil {
+			result = "failed"
+			logrus.Errorf("Webhook delivery %s to %s failed after %d attempts: %v", delivery.id, ep.name, attempts, err)
+		}
+		d.metricsManager.RecordWebhookDelivery(ep.name, delivery.event, result, attempts, time.Since(start))
+	}
+}
+
+// Close 停止接收新数据并等待队列推送完成，超过closeTimeout时中止剩余推送
+func (d *Dispatcher) Close() {
+	d.closeOnce.Do(func() {
+		for _, ep := range d.endpoints {
+			close(ep.queue)
+		}
+
+		done := make(chan struct{})
+		go func() {
+			d.workers.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(closeTimeout):
+			logrus.Warn("Timed out waiting for webhook deliveries, aborting the rest")
+			d.cancel()
+			<-done
+		}
+		d.cancel()
+
+		for _, ep := range d.endpoints {
+			ep.http.CloseIdleConnections()
+		}
+	})
+}
+
+// filter 地址的过滤条件，为空的条件不限
+type filter struct {
+	events     map[string]bool
+	networks   map[string]bool
+	addresses  map[string]bool
+	minValue   *big.Int
+	minRank    int
+	alertTypes map[string]bool
+}
+
+func newFilter(cfg config.WebhookEndpointConfig) (*filter, error) {
+	f := &filter{
+		events:     toSet(cfg.Events, false),
+		networks:   toSet(cfg.Networks, false),
+		addresses:  toSet(cfg.Addresses, true),
+		minRank:    levelRanks[cfg.MinLevel],
+		alertTypes: toSet(cfg.AlertTypes, false),
+	}
+	if cfg.MinValueWei != "" {
+		minValue, ok := new(big.Int).SetString(cfg.MinValueWei, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid min_value_wei: %s", cfg.MinValueWei)
+		}
+		f.minValue = minValue
+	}
+	return f, nil
+}
+
+func (f *filter) matchEvent(event, network string) bool {
+	if len(f.events) > 0 && !f.events[event] {
+		return false
+	}
+	return len(f.networks) == 0 || f.networks[network]
+}
+
+// matchBlock 配置了地址或金额条件时只推送交易及告警
+func (f *filter) matchBlock(block *models.Block) bool {
+	return len(f.addresses) == 0 && f.minValue == nil
+}
+
+func (f *filter) matchTransaction(tx *models.Transaction) bool {
+	if len(f.addresses) > 0 && !f.addresses[strings.ToLower(tx.FromAddress)] && !f.addresses[strings.ToLower(tx.ToAddress)] {
+		return false
+	}
+	return f.minValue == nil || (tx.Value != nil && tx.Value.Cmp(f.minValue) >= 0)
+}
+
+func (f *filter) matchAlert(alert *models.RiskAlert) bool {
+	if levelRanks[alert.Level] < f.minRank {
+		return false
+	}
+	if len(f.alertTypes) > 0 && !f.alertTypes[alert.Type] {
+		return false
+	}
+	if len(f.addresses) > 0 {
+		to, _ := alert.Metadata["to_address"].(string)
+		if !f.addresses[strings.ToLower(alert.Address)] && !f.addresses[strings.ToLower(to)] {
+			return false
+		}
+	}
+	return true
+}
+
+func toSet(values []string, lower bool) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		if lower {
+			value = strings.ToLower(value)
+		}
+		set[value] = true
+	}
+	return set
+}
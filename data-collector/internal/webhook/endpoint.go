@@ -0,0 +1,195 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"web3-data-collector/internal/config"
+)
+
+// userAgent 推送请求的User-Agent
+const userAgent = "web3-data-collector-webhook"
+
+// 推送请求头
+const (
+	HeaderID        = "X-Webhook-ID"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// delivery 待推送的数据
+type delivery struct {
+	id    string
+	event string
+	body  []byte
+}
+
+// endpoint 单个Webhook地址
+type endpoint struct {
+	name           string
+	url            string
+	secret         []byte
+	headers        map[string]string
+	filter         *filter
+	http           *http.Client
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	queue          chan *delivery
+}
+
+func newEndpoint(cfg config.WebhookEndpointConfig) (*endpoint, error) {
+	name := cfg.Name
+	if name == "" {
+		name = cfg.URL
+	}
+
+	f, err := newFilter(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("webhook endpoint %s: %w", name, err)
+	}
+
+	timeout, initialBackoff, maxBackoff := defaultTimeout, defaultInitialBackoff, defaultMaxBackoff
+	for _, option := range []struct {
+		name  string
+		value string
+		out   *time.Duration
+	}{
+		{"timeout", cfg.Timeout, &timeout},
+		{"initial_backoff", cfg.InitialBackoff, &initialBackoff},
+		{"max_backoff", cfg.MaxBackoff, &maxBackoff},
+	} {
+		if option.value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(option.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s for webhook endpoint %s: %w", option.name, name, err)
+		}
+		*option.out = parsed
+	}
+
+	maxRetries := cfg.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = defaultMaxRetries
+	}
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+
+	return &endpoint{
+		name:           name,
+		url:            cfg.URL,
+		secret:         []byte(cfg.Secret),
+		headers:        cfg.Headers,
+		filter:         f,
+		http:           &http.Client{Timeout: timeout},
+		maxRetries:     maxRetries,
+		initialBackoff: initialBackoff,
+		maxBackoff:     maxBackoff,
+		queue:          make(chan *delivery, queueSize),
+	}, nil
+}
+
+// deliver 推送并按指数退避重试可重试的失败，返回请求次数
+func (ep *endpoint) deliver(ctx context.Context, d *delivery) (int, error) {
+	for attempt := 1; ; attempt++ {
+		retryable, retryAfter, err := ep.post(ctx, d)
+		if err == nil {
+			return attempt, nil
+		}
+		if !retryable || attempt > ep.maxRetries {
+			return attempt, err
+		}
+
+		delay := ep.backoff(attempt)
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+		select {
+		case <-ctx.Done():
+			return attempt, fmt.Errorf("%w (aborted: %v)", err, ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+// backoff 第attempt次失败后的等待时间：initial_backoff * 2^(attempt-1)，不超过max_backoff
+func (ep *endpoint) backoff(attempt int) time.Duration {
+	delay := ep.initialBackoff
+	for i := 1; i < attempt && delay < ep.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > ep.maxBackoff {
+		delay = ep.maxBackoff
+	}
+	return delay
+}
+
+// post 发送一次请求，每次请求使用当前时间重新签名；返回是否可重试及429/503响应的Retry-After
+func (ep *endpoint) post(ctx context.Context, d *delivery) (bool, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.url, bytes.NewReader(d.body))
+	if err != nil {
+		return false, 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	for name, value := range ep.headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set(HeaderID, d.id)
+	req.Header.Set(HeaderEvent, d.event)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if len(ep.secret) > 0 {
+		req.Header.Set(HeaderSignature, "sha256="+Sign(ep.secret, timestamp, d.body))
+	}
+
+	resp, err := ep.http.Do(req)
+	if err != nil {
+		return true, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return false, 0, nil
+	}
+
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	err = fmt.Errorf("%s returned %d: %s", ep.name, resp.StatusCode, bytes.TrimSpace(message))
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		retryAfter, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		wait := time.Duration(retryAfter) * time.Second
+		if wait > ep.maxBackoff {
+			wait = ep.maxBackoff
+		}
+		return true, wait, err
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode >= http.StatusInternalServerError:
+		return true, 0, err
+	default:
+		return false, 0, err
+	}
+}
+
+// Sign 计算推送签名：HMAC-SHA256(secret, timestamp + "." + body)的十六进制，
+// 接收方以相同方式计算后与X-Webhook-Signature头（去掉sha256=前缀）比较，并拒绝时间戳过旧的请求
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
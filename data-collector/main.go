@@ -2,47 +2,107 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"reflect"
+	"strings"
 	"syscall"
 	"time"
 
 	"web3-data-collector/internal/api"
+	"web3-data-collector/internal/buildinfo"
 	"web3-data-collector/internal/collector"
 	"web3-data-collector/internal/config"
 	"web3-data-collector/internal/database"
+	"web3-data-collector/internal/devtool"
+	"web3-data-collector/internal/ens"
+	"web3-data-collector/internal/grpcapi"
+	"web3-data-collector/internal/leader"
+	"web3-data-collector/internal/logging"
 	"web3-data-collector/internal/metrics"
+	"web3-data-collector/internal/models"
+	"web3-data-collector/internal/pricing"
 	"web3-data-collector/internal/processor"
 	"web3-data-collector/internal/publisher"
+	"web3-data-collector/internal/sharding"
+	"web3-data-collector/internal/siem"
+	"web3-data-collector/internal/stream"
+	"web3-data-collector/internal/warehouse"
+	"web3-data-collector/internal/watchdog"
+	"web3-data-collector/internal/webhook"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
 
 func main() {
+	mode := flag.String("mode", "collector", "运行模式: collector | traffic（测试网合成交易生成器） | migrate（升级Redis键布局后退出） | replay（回放历史数据后退出）")
+	var replay replayOptions
+	flag.StringVar(&replay.source, "source", "archive", "replay模式的数据来源: archive（S3/GCS归档文件） | kafka（交易主题）")
+	flag.StringVar(&replay.network, "network", "", "replay模式只回放该网络，kafka来源可为空表示全部网络")
+	flag.StringVar(&replay.from, "from", "", "replay模式的开始时间（RFC3339，含）")
+	flag.StringVar(&replay.to, "to", "", "replay模式的结束时间（RFC3339，不含），默认为当前时间")
+	flag.StringVar(&replay.publish, "publish", "alerts", "replay模式的发布范围: alerts（只发布告警） | all（按配置发布全部数据）")
+	validateOnly := flag.Bool("validate-config", false, "只加载并校验配置（含解析密钥引用），输出全部问题后退出，配置有误时退出码为1")
+	flag.Parse()
+
 	// 加载配置
 	cfg, err := config.Load("config.yml")
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// 启动前一次性报告全部配置问题，不逐个失败
+	if errs := cfg.Validate(); len(errs) > 0 {
+		fmt.Fprintln(os.Stderr, config.FormatValidationErrors(errs))
+		os.Exit(1)
+	}
+	if *validateOnly {
+		fmt.Println("configuration is valid")
+		return
+	}
+
 	// 初始化日志
-	initLogger(cfg.Logging.Level, cfg.Logging.Format)
+	initLogger(cfg.Logging)
 
-	logrus.Info("Starting Web3 Data Collector...")
+	if *mode == "traffic" {
+		runTrafficGenerator(cfg)
+		return
+	}
+	if *mode == "migrate" {
+		runRedisMigrations(cfg)
+		return
+	}
+	if *mode == "replay" {
+		runReplay(cfg, replay)
+		return
+	}
+
+	build := buildinfo.Get()
+	logrus.Infof("Starting Web3 Data Collector %s (commit %s, built %s)...", build.Version, build.Commit, build.BuildTime)
 
 	// 初始化指标收集
 	metricsManager := metrics.NewManager()
-
-	// 初始化数据库连接
-	influxClient, err := database.NewInfluxDBClient(cfg.InfluxDB)
+	errorBudgetWindow, err := time.ParseDuration(cfg.Metrics.ErrorBudget.Window)
 	if err != nil {
-		logrus.Fatalf("Failed to connect to InfluxDB: %v", err)
+		logrus.Fatalf("Invalid metrics.error_budget.window: %v", err)
+	}
+	metricsManager.SetErrorBudgets(errorBudgetWindow, cfg.Metrics.ErrorBudget.Budgets)
+
+	// 初始化数据库连接，告警专用部署不连接InfluxDB
+	var influxClient *database.InfluxDBClient
+	if cfg.DataProcessing.Profile == processor.ProfileAlertsOnly {
+		logrus.Info("Running in alerts_only profile, InfluxDB and data publishing are disabled")
+	} else {
+		influxClient, err = database.NewInfluxDBClient(cfg.InfluxDB)
+		if err != nil {
+			logrus.Fatalf("Failed to connect to InfluxDB: %v", err)
+		}
 	}
-	defer influxClient.Close()
 
 	redisClient, err := database.NewRedisClient(cfg.Redis)
 	if err != nil {
@@ -50,41 +110,188 @@ func main() {
 	}
 	defer redisClient.Close()
 
+	// 检查Redis键布局版本，按配置自动升级
+	if err := migrateRedis(context.Background(), redisClient, cfg.Redis.AutoMigrate); err != nil {
+		logrus.Fatalf("Failed to migrate Redis: %v", err)
+	}
+
 	// 初始化消息发布器
 	kafkaPublisher, err := publisher.NewKafkaPublisher(cfg.Kafka)
 	if err != nil {
 		logrus.Fatalf("Failed to create Kafka publisher: %v", err)
 	}
-	defer kafkaPublisher.Close()
+
+	// 初始化实时数据分发中心
+	streamHub := stream.NewHub(cfg.GRPC.StreamBufferSize)
+
+	// 初始化价格服务（未启用时为nil）
+	priceService := pricing.NewService(cfg.Pricing, cfg.Blockchain.Networks, redisClient)
+
+	// 初始化ENS解析器（未启用时为nil）
+	ensResolver, err := ens.NewResolver(cfg.ENS, redisClient)
+	if err != nil {
+		logrus.Fatalf("Failed to create ENS resolver: %v", err)
+	}
+	if ensResolver != nil {
+		defer ensResolver.Close()
+	}
+
+	// 初始化SIEM告警转发（未启用时为nil）
+	siemForwarder, err := siem.NewForwarder(cfg.DataProcessing.SIEM)
+	if err != nil {
+		logrus.Fatalf("Failed to create SIEM forwarder: %v", err)
+	}
+	if siemForwarder != nil {
+		defer siemForwarder.Close()
+	}
+
+	// 初始化Webhook推送（未启用时为nil）
+	webhookDispatcher, err := webhook.NewDispatcher(cfg.DataProcessing.Webhooks, metricsManager)
+	if err != nil {
+		logrus.Fatalf("Failed to create webhook dispatcher: %v", err)
+	}
+
+	// 初始化冷归档（未启用时为nil）
+	archiver, err := publisher.NewArchiver(cfg.DataProcessing.Archive)
+	if err != nil {
+		logrus.Fatalf("Failed to create archiver: %v", err)
+	}
+
+	// 初始化内存看门狗（未启用时为nil）
+	memoryWatchdog := watchdog.NewMemoryWatchdog(cfg.Memory, metricsManager)
 
 	// 初始化数据处理器
-	dataProcessor := processor.NewDataProcessor(
+	dataProcessor, err := processor.NewDataProcessor(
 		cfg.DataProcessing,
+		cfg.Blockchain.Networks,
 		kafkaPublisher,
 		influxClient,
 		redisClient,
 		metricsManager,
+		streamHub,
+		priceService,
+		ensResolver,
+		siemForwarder,
+		webhookDispatcher,
+		archiver,
+		memoryWatchdog,
 	)
+	if err != nil {
+		logrus.Fatalf("Failed to create data processor: %v", err)
+	}
+
+	// 初始化地址统计导出任务（未启用时为nil）
+	addressStatsExporter, err := warehouse.NewAddressStatsExporter(cfg.DataProcessing.Warehouse, redisClient, metricsManager)
+	if err != nil {
+		logrus.Fatalf("Failed to create address stats exporter: %v", err)
+	}
+	addressStatsPruner, err := warehouse.NewAddressStatsPruner(cfg.DataProcessing.AddressStats, redisClient, influxClient, metricsManager)
+	if err != nil {
+		logrus.Fatalf("Failed to create address stats pruner: %v", err)
+	}
+
+	// 初始化InfluxDB降采样及保留时长维护（未启用时为nil）
+	var influxMaintainer *database.InfluxMaintainer
+	if influxClient != nil {
+		influxMaintainer, err = database.NewInfluxMaintainer(cfg.InfluxDB, influxClient)
+		if err != nil {
+			logrus.Fatalf("Failed to create InfluxDB maintainer: %v", err)
+		}
+	}
+
+	// 初始化主实例选举（未启用时为nil），启用时只有主实例启动收集器
+	elector, err := leader.NewElector(cfg.LeaderElection, redisClient, metricsManager)
+	if err != nil {
+		logrus.Fatalf("Failed to create leader elector: %v", err)
+	}
+
+	// 初始化网络分片协调器（未启用时为nil）
+	shardCoordinator, err := sharding.NewCoordinator(cfg.Blockchain.Sharding, redisClient)
+	if err != nil {
+		logrus.Fatalf("Failed to create shard coordinator: %v", err)
+	}
 
 	// 初始化区块链收集器
 	blockchainCollector := collector.NewBlockchainCollector(
 		cfg.Blockchain,
 		dataProcessor,
 		metricsManager,
+		shardCoordinator,
 	)
 
 	// 启动收集器
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	go metricsManager.Run(ctx)
+	if memoryWatchdog != nil {
+		go memoryWatchdog.Run(ctx)
+	}
+	if addressStatsExporter != nil {
+		go addressStatsExporter.Run(ctx)
+	}
+	if addressStatsPruner != nil {
+		go addressStatsPruner.Run(ctx)
+	}
+	if influxMaintainer != nil {
+		go influxMaintainer.Run(ctx)
+	}
+	if aggregator := dataProcessor.AlertAggregator(); aggregator != nil {
+		go aggregator.Run(ctx)
+	}
+	if scamFeeds := dataProcessor.ScamFeeds(); scamFeeds != nil {
+		go scamFeeds.Run(ctx)
+	}
+
+	var leadershipLost <-chan struct{}
+	if elector != nil {
+		leadershipLost = elector.Lost()
+		go elector.Run(ctx)
+	}
+
 	go func() {
+		if elector != nil {
+			logrus.Info("Running as standby, waiting for leadership")
+			select {
+			case <-elector.Elected():
+			case <-ctx.Done():
+				return
+			}
+		}
 		if err := blockchainCollector.Start(ctx); err != nil {
 			logrus.Errorf("Blockchain collector error: %v", err)
 		}
 	}()
 
+	// 配置热加载
+	reloader := config.NewReloader("config.yml", cfg)
+	reloader.OnReload(func(previous, next *config.Config) error {
+		// 日志配置未变化时保留通过管理接口设置的级别
+		if reflect.DeepEqual(previous.Logging, next.Logging) {
+			return nil
+		}
+		return logging.Configure(next.Logging.Level, next.Logging.Modules)
+	})
+	reloader.OnReload(func(previous, next *config.Config) error {
+		dataProcessor.ApplyConfig(next.DataProcessing, next.Blockchain.Networks)
+		return nil
+	})
+	reloader.OnReload(func(previous, next *config.Config) error {
+		return blockchainCollector.ApplyNetworks(next.Blockchain.Networks)
+	})
+
+	if cfg.Server.WatchConfig {
+		go func() {
+			if err := reloader.Watch(ctx); err != nil {
+				logrus.Errorf("Config watcher error: %v", err)
+			}
+		}()
+	}
+
 	// 初始化并启动HTTP服务器
-	router := setupRouter(cfg, metricsManager, blockchainCollector)
+	// HTTP API与gRPC服务使用同一认证器
+	auth := api.NewAuthenticator(cfg.Server.Auth, redisClient)
+	router := setupRouter(cfg, metricsManager, blockchainCollector, dataProcessor, influxClient, redisClient, reloader, elector, auth)
 	
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", cfg.Server.Port),
@@ -99,30 +306,280 @@ func main() {
 		}
 	}()
 
+	// 启动gRPC服务器
+	var grpcServer *grpcapi.Server
+	if cfg.GRPC.Enabled {
+		grpcServer = grpcapi.NewServer(cfg.GRPC, blockchainCollector, streamHub, auth)
+		go func() {
+			if err := grpcServer.Start(); err != nil {
+				logrus.Fatalf("Failed to start gRPC server: %v", err)
+			}
+		}()
+	}
+
 	// 等待中断信号
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	select {
+	case <-quit:
+	case <-leadershipLost:
+		// 收集器不支持重新启动，退出后由编排系统重新拉起为备实例
+		logrus.Warn("Leadership lost, shutting down")
+	}
 
 	logrus.Info("Shutting down server...")
 
-	// 优雅关闭
-	ctx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	// 优雅关闭：先停止接收新区块并等待处理中的区块完成，再写出缓冲数据，Redis最后关闭
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer shutdownCancel()
+
+	// 输出端不再重试，处理中的区块发布失败时直接写入死信队列，排空不被故障的输出端拖住
+	dataProcessor.Close()
+	if err := blockchainCollector.Stop(shutdownCtx); err != nil {
+		logrus.Errorf("Blockchain collector did not drain: %v", err)
+	}
+	if elector != nil {
+		elector.Release()
+	}
+	cancel()
 
-	if err := server.Shutdown(ctx); err != nil {
+	if err := server.Shutdown(shutdownCtx); err != nil {
 		logrus.Errorf("Server forced to shutdown: %v", err)
 	}
 
+	// 先写出缓冲数据，再停止gRPC服务，避免流式订阅阻塞关闭时丢失数据
+	if err := kafkaPublisher.Close(); err != nil {
+		logrus.Errorf("Failed to flush Kafka writers: %v", err)
+	}
+	if webhookDispatcher != nil {
+		webhookDispatcher.Close()
+	}
+	if archiver != nil {
+		archiver.Close()
+	}
+	if influxClient != nil {
+		influxClient.Close()
+	}
+
+	if grpcServer != nil {
+		grpcServer.Stop(shutdownCtx)
+	}
+
 	logrus.Info("Server exited")
 }
 
-func setupRouter(cfg *config.Config, metricsManager *metrics.Manager, collector *collector.BlockchainCollector) *gin.Engine {
+// runTrafficGenerator 在测试网发送合成交易，用于演示和压测告警与过滤
+func runTrafficGenerator(cfg *config.Config) {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	generator, err := devtool.NewTrafficGenerator(ctx, cfg.Devtool.Traffic, cfg.Blockchain.Networks)
+	if err != nil {
+		logrus.Fatalf("Failed to start traffic generator: %v", err)
+	}
+
+	if err := generator.Run(ctx); err != nil {
+		logrus.Fatalf("Traffic generator error: %v", err)
+	}
+}
+
+// runRedisMigrations 升级Redis键布局后退出，用于关闭auto_migrate时在发布新版本前手动执行
+func runRedisMigrations(cfg *config.Config) {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	redisClient, err := database.NewRedisClient(cfg.Redis)
+	if err != nil {
+		logrus.Fatalf("Failed to connect to Redis: %v", err)
+	}
+	defer redisClient.Close()
+
+	if err := migrateRedis(ctx, redisClient, true); err != nil {
+		logrus.Fatalf("Failed to migrate Redis: %v", err)
+	}
+	logrus.Info("Redis migrations completed")
+}
+
+// replayOptions replay模式的命令行参数
+type replayOptions struct {
+	source  string
+	network string
+	from    string
+	to      string
+	publish string
+}
+
+// runReplay 将归档文件或Kafka交易主题中的历史数据重新经过过滤、风险检测及输出端后退出，
+// 用于以新增的风险规则评估历史数据；回放不重复归档，也不向Webhook和SIEM推送历史告警
+func runReplay(cfg *config.Config, opts replayOptions) {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	from, err := time.Parse(time.RFC3339, opts.from)
+	if err != nil {
+		logrus.Fatalf("Invalid -from %q: %v", opts.from, err)
+	}
+	to := time.Now()
+	if opts.to != "" {
+		if to, err = time.Parse(time.RFC3339, opts.to); err != nil {
+			logrus.Fatalf("Invalid -to %q: %v", opts.to, err)
+		}
+	}
+	if !from.Before(to) {
+		logrus.Fatalf("-from must be before -to")
+	}
+	if opts.source == "archive" && opts.network == "" {
+		logrus.Fatalf("-network is required when replaying from archive")
+	}
+
+	switch opts.publish {
+	case "alerts":
+		cfg.DataProcessing.Profile = processor.ProfileAlertsOnly
+	case "all":
+	default:
+		logrus.Fatalf("Invalid -publish %q, must be alerts or all", opts.publish)
+	}
+	// 历史交易已处理过，去重会将其全部过滤；Kafka事务模式的检查点只适用于实时区块
+	cfg.DataProcessing.TxDedup.Enabled = false
+	cfg.Kafka.Producer.Transactional = false
+	// 聚合窗口及限速按当前时间计算，回放的历史告警逐条发布
+	cfg.DataProcessing.AlertAggregation.Enabled = false
+
+	metricsManager := metrics.NewManager()
+
+	var influxClient *database.InfluxDBClient
+	if cfg.DataProcessing.Profile != processor.ProfileAlertsOnly {
+		influxClient, err = database.NewInfluxDBClient(cfg.InfluxDB)
+		if err != nil {
+			logrus.Fatalf("Failed to connect to InfluxDB: %v", err)
+		}
+		defer influxClient.Close()
+	}
+
+	redisClient, err := database.NewRedisClient(cfg.Redis)
+	if err != nil {
+		logrus.Fatalf("Failed to connect to Redis: %v", err)
+	}
+	defer redisClient.Close()
+
+	if err := migrateRedis(ctx, redisClient, cfg.Redis.AutoMigrate); err != nil {
+		logrus.Fatalf("Failed to migrate Redis: %v", err)
+	}
+
+	kafkaPublisher, err := publisher.NewKafkaPublisher(cfg.Kafka)
+	if err != nil {
+		logrus.Fatalf("Failed to create Kafka publisher: %v", err)
+	}
+	defer func() {
+		if err := kafkaPublisher.Close(); err != nil {
+			logrus.Errorf("Failed to flush Kafka writers: %v", err)
+		}
+	}()
+
+	dataProcessor, err := processor.NewDataProcessor(
+		cfg.DataProcessing,
+		cfg.Blockchain.Networks,
+		kafkaPublisher,
+		influxClient,
+		redisClient,
+		metricsManager,
+		stream.NewHub(cfg.GRPC.StreamBufferSize),
+		pricing.NewService(cfg.Pricing, cfg.Blockchain.Networks, redisClient),
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+	if err != nil {
+		logrus.Fatalf("Failed to create data processor: %v", err)
+	}
+
+	logrus.Infof("Replaying %s from %s to %s (publish: %s)", opts.source, from.Format(time.RFC3339), to.Format(time.RFC3339), opts.publish)
+
+	var blocks, transactions, alerts int
+	switch opts.source {
+	case "archive":
+		reader, err := publisher.NewArchiveReader(cfg.DataProcessing.Archive)
+		if err != nil {
+			logrus.Fatalf("Failed to create archive reader: %v", err)
+		}
+		err = reader.ReadBlocks(ctx, opts.network, from, to, func(block *models.Block) error {
+			enriched, err := dataProcessor.ProcessBlock(ctx, block)
+			if err != nil {
+				return fmt.Errorf("block %d: %w", block.Number, err)
+			}
+			blocks++
+			transactions += len(block.Transactions)
+			alerts += len(enriched.Alerts)
+			return ctx.Err()
+		})
+	case "kafka":
+		err = kafkaPublisher.ReplayTransactions(ctx, opts.network, from, to, func(tx *models.Transaction) error {
+			alert, err := dataProcessor.ReplayTransaction(tx)
+			if err != nil {
+				return fmt.Errorf("transaction %s: %w", tx.Hash, err)
+			}
+			transactions++
+			if alert != nil {
+				alerts++
+			}
+			return ctx.Err()
+		})
+	default:
+		logrus.Fatalf("Invalid -source %q, must be archive or kafka", opts.source)
+	}
+	if err != nil {
+		logrus.Errorf("Replay stopped: %v", err)
+	}
+
+	logrus.Infof("Replay finished: %d blocks, %d transactions, %d alerts", blocks, transactions, alerts)
+}
+
+// migrateRedis 检查各Redis键布局的版本，apply为false时存在待升级布局则返回错误
+func migrateRedis(ctx context.Context, redisClient *database.RedisClient, apply bool) error {
+	migrator, err := database.NewSchemaMigrator(redisClient, append(processor.RedisLayouts(), api.RedisLayouts()...)...)
+	if err != nil {
+		return err
+	}
+
+	if !apply {
+		pending, err := migrator.Pending()
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			names := make([]string, len(pending))
+			for i, status := range pending {
+				names[i] = fmt.Sprintf("%s (%d -> %d)", status.Name, status.Stored, status.Current)
+			}
+			return fmt.Errorf("redis layouts need migration, run with -mode migrate: %s", strings.Join(names, ", "))
+		}
+	}
+
+	return migrator.Migrate(ctx)
+}
+
+func setupRouter(
+	cfg *config.Config,
+	metricsManager *metrics.Manager,
+	collector *collector.BlockchainCollector,
+	dataProcessor *processor.DataProcessor,
+	influxClient *database.InfluxDBClient,
+	redisClient *database.RedisClient,
+	reloader *config.Reloader,
+	elector *leader.Elector,
+	auth *api.Authenticator,
+) *gin.Engine {
 	if cfg.Server.Mode == "release" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
 	router := gin.Default()
+	// gin默认信任全部代理，客户端可伪造X-Forwarded-For绕过按IP限流；未配置时不信任任何代理
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logrus.Fatalf("Invalid server.trusted_proxies: %v", err)
+	}
 
 	// 健康检查
 	router.GET("/health", func(c *gin.Context) {
@@ -139,21 +596,21 @@ func setupRouter(cfg *config.Config, metricsManager *metrics.Manager, collector
 
 	// API路由
 	apiGroup := router.Group("/api/v1")
-	api.SetupRoutes(apiGroup, collector, metricsManager)
+	api.SetupRoutes(apiGroup, collector, dataProcessor, metricsManager, influxClient, redisClient, reloader, elector, auth)
+	api.SetupOpenAPI(router, apiGroup, cfg.Server.OpenAPI, cfg.Server.Auth.Enabled)
 
 	return router
 }
 
-func initLogger(level, format string) {
-	// 设置日志级别
-	logLevel, err := logrus.ParseLevel(level)
-	if err != nil {
-		logLevel = logrus.InfoLevel
+func initLogger(cfg config.LoggingConfig) {
+	// 设置默认及各模块的日志级别，配置已校验
+	if err := logging.Configure(cfg.Level, cfg.Modules); err != nil {
+		logrus.Warnf("Invalid logging config, using info: %v", err)
+		logging.SetDefaultLevel(logrus.InfoLevel)
 	}
-	logrus.SetLevel(logLevel)
 
 	// 设置日志格式
-	if format == "json" {
+	if cfg.Format == "json" {
 		logrus.SetFormatter(&logrus.JSONFormatter{
 			TimestampFormat: time.RFC3339,
 		})
@@ -33,7 +33,10 @@ echo "3. 初始化数据库..."
 echo "4. 编译Go数据采集服务..."
 cd ../data-collector
 go mod tidy
-go build -o bin/data-collector main.go
+BUILDINFO=web3-data-collector/internal/buildinfo
+go build -ldflags "-X $BUILDINFO.Version=$(git describe --tags --always 2>/dev/null || echo dev) \
+  -X $BUILDINFO.Commit=$(git rev-parse --short HEAD 2>/dev/null) \
+  -X $BUILDINFO.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" -o bin/data-collector main.go
 
 echo "5. 启动Go数据采集服务..."
 nohup ./bin/data-collector > logs/data-collector.log 2>&1 &